@@ -2,29 +2,72 @@ package dto
 
 // LoginRequest 登录请求
 type LoginRequest struct {
-	Phone      string `json:"phone" binding:"required"`
-	Password   string `json:"password" binding:"required"`
-	TenantCode string `json:"tenant_code"` // 租户代码（可选，为空则查询系统库）
-	IP         string `json:"ip"`          // 登录IP
+	Phone       string `json:"phone" binding:"required"`
+	Password    string `json:"password" binding:"required"`
+	TenantCode  string `json:"tenant_code"`  // 租户代码（可选，为空则查询系统库）
+	Device      string `json:"device"`       // 设备名称（可选，用于会话列表展示）
+	CaptchaID   string `json:"captcha_id"`   // 验证码ID（失败次数较多时必填）
+	CaptchaCode string `json:"captcha_code"` // 验证码
+	IP          string `json:"ip"`           // 登录IP
+	UserAgent   string `json:"-"`            // 由 transport 从请求头填充
 }
 
 // LoginResponse 登录响应
 type LoginResponse struct {
-	Token           string              `json:"token"`
-	UserID          string              `json:"user_id"`
-	TenantID        string              `json:"tenant_id"` // 租户ID
-	Phone           string              `json:"phone"`
-	Nickname        string              `json:"nickname"`
-	Avatar          string              `json:"avatar"`
-	Role            []string            `json:"role"`
-	MenuPermissions map[string][]string `json:"menu_permissions,omitempty"` // 用户的菜单权限映射：{"admin": ["read", "create"], "finance": ["read"]}
-	ExpiresAt       int64               `json:"expires_at"`
+	Token            string              `json:"token"`
+	UserID           string              `json:"user_id"`
+	TenantID         string              `json:"tenant_id"` // 租户ID
+	Phone            string              `json:"phone"`
+	Nickname         string              `json:"nickname"`
+	Avatar           string              `json:"avatar"`
+	Role             []string            `json:"role"`
+	MenuPermissions  map[string][]string `json:"menu_permissions,omitempty"`   // 用户的菜单权限映射：{"admin": ["read", "create"], "finance": ["read"]}
+	ExpiresAt        int64               `json:"expires_at"`                   // 访问令牌过期时间
+	RefreshToken     string              `json:"refresh_token,omitempty"`      // 刷新令牌（会话存储未启用时为空）
+	RefreshExpiresAt int64               `json:"refresh_expires_at,omitempty"` // 刷新令牌过期时间
+	SessionID        string              `json:"session_id,omitempty"`
+
+	// 需要两步验证时只返回以下字段（不签发令牌），客户端使用 challenge_token 调用 /auth/login/2fa
+	TwoFactorRequired  bool                    `json:"two_factor_required,omitempty"`
+	ChallengeToken     string                  `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt int64                   `json:"challenge_expires_at,omitempty"`
+	TwoFactorSetup     *TwoFactorSetupResponse `json:"two_factor_setup,omitempty"` // 租户强制两步验证但尚未绑定时返回绑定信息
+	RecoveryCodes      []string                `json:"recovery_codes,omitempty"`   // 登录时完成绑定生成的恢复码（只返回一次）
+}
+
+// TwoFactorLoginRequest 登录第二步：提交验证器App中的验证码或恢复码
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+	IP             string `json:"-"` // 由 transport 填充
+	UserAgent      string `json:"-"` // 由 transport 填充
+}
+
+// SSOStartRequest 发起单点登录请求
+type SSOStartRequest struct {
+	TenantCode string `uri:"tenant_code" binding:"required"`
+	Device     string `form:"device"` // 设备名称（可选，用于会话列表展示）
+}
+
+// SSOStartResponse 发起单点登录响应，前端跳转到 authorize_url
+type SSOStartResponse struct {
+	AuthorizeURL string `json:"authorize_url"`
+	State        string `json:"state"`
+	ExpiresAt    int64  `json:"expires_at"`
+}
+
+// SSOCallbackRequest 单点登录回调：前端回调页把身份提供方返回的 state 和 code 提交给认证服务
+type SSOCallbackRequest struct {
+	State     string `json:"state" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+	IP        string `json:"-"` // 由 transport 填充
+	UserAgent string `json:"-"` // 由 transport 填充
 }
 
 // RegisterRequest 注册请求
 type RegisterRequest struct {
 	Phone    string `json:"phone" binding:"required"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"` // 长度和复杂度由密码策略校验
 	Nickname string `json:"nickname" binding:"required"`
 	Email    string `json:"email" binding:"omitempty,email"`
 }
@@ -37,15 +80,118 @@ type RegisterResponse struct {
 	Message  string `json:"message"`
 }
 
-// RefreshTokenRequest 刷新Token请求
+// RefreshTokenRequest 刷新Token请求（刷新令牌只能使用一次，每次刷新都会返回新的刷新令牌）
 type RefreshTokenRequest struct {
-	Token string `json:"token" binding:"required"`
+	RefreshToken string `json:"refresh_token" binding:"required"`
+	IP           string `json:"-"` // 由 transport 填充
 }
 
 // RefreshTokenResponse 刷新Token响应
 type RefreshTokenResponse struct {
-	Token     string `json:"token"`
-	ExpiresAt int64  `json:"expires_at"`
+	Token            string `json:"token"`
+	ExpiresAt        int64  `json:"expires_at"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresAt int64  `json:"refresh_expires_at"`
+}
+
+// SessionItem 登录会话
+type SessionItem struct {
+	ID         string `json:"id"`
+	Device     string `json:"device"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  int64  `json:"created_at"`
+	LastSeenAt int64  `json:"last_seen_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+	Current    bool   `json:"current"` // 是否为当前会话
+}
+
+// SessionListResponse 会话列表响应
+type SessionListResponse struct {
+	Sessions []SessionItem `json:"sessions"`
+	Total    int           `json:"total"`
+}
+
+// StartImpersonationRequest 申请代管租户（系统超管）
+type StartImpersonationRequest struct {
+	TenantCode string `json:"tenant_code" binding:"required"` // 被代管的租户代码
+	Reason     string `json:"reason" binding:"required"`      // 申请原因（租户管理员可见）
+	Minutes    int    `json:"minutes"`                        // 有效期（分钟），为空使用默认值，超过上限按上限
+	ReadOnly   bool   `json:"read_only"`                      // 只读代管
+	IP         string `json:"-"`                              // 由 transport 填充
+	UserAgent  string `json:"-"`                              // 由 transport 填充
+}
+
+// ImpersonationTokenResponse 代管令牌（不能刷新，过期后需重新申请）
+type ImpersonationTokenResponse struct {
+	ID         string `json:"id"`
+	Token      string `json:"token"`
+	ExpiresAt  int64  `json:"expires_at"`
+	TenantCode string `json:"tenant_code"`
+	ReadOnly   bool   `json:"read_only"`
+}
+
+// ImpersonationListRequest 代管记录列表请求
+type ImpersonationListRequest struct {
+	TenantCode string `form:"tenant_code"` // 租户代码（仅系统超管可指定，租户管理员固定为本租户）
+	AdminID    string `form:"admin_id"`    // 发起代管的超管ID
+	Page       int64  `form:"page"`
+	PageSize   int64  `form:"page_size"`
+}
+
+// ImpersonationItem 代管记录
+type ImpersonationItem struct {
+	ID         string `json:"id"`
+	AdminID    string `json:"admin_id"`
+	AdminName  string `json:"admin_name"`
+	TenantCode string `json:"tenant_code"`
+	Reason     string `json:"reason"`
+	ReadOnly   bool   `json:"read_only"`
+	IP         string `json:"ip"`
+	CreatedAt  int64  `json:"created_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+	EndedAt    int64  `json:"ended_at"`
+	EndedBy    string `json:"ended_by"`
+	Active     bool   `json:"active"` // 代管令牌是否仍然有效
+}
+
+// ImpersonationListResponse 代管记录列表响应
+type ImpersonationListResponse struct {
+	List     []ImpersonationItem `json:"list"`
+	Total    int64               `json:"total"`
+	Page     int64               `json:"page"`
+	PageSize int64               `json:"page_size"`
+}
+
+// TwoFactorStatusResponse 两步验证状态
+type TwoFactorStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"` // 租户是否强制启用
+	EnabledAt              int64 `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int   `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetupResponse 两步验证绑定信息（前端将 provisioning_uri 渲染为二维码）
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeRequest 提交验证码（确认绑定、重新生成恢复码）
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableRequest 关闭两步验证请求
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // 验证码或恢复码
+}
+
+// RecoveryCodesResponse 恢复码（只返回一次，请提示用户妥善保存）
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Message       string   `json:"message"`
 }
 
 // TenantItem 租户列表项
@@ -64,7 +210,7 @@ type GetTenantListResponse struct {
 // ChangePasswordRequest 修改密码请求
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required"` // 长度和复杂度由密码策略校验
 }
 
 // ChangePasswordResponse 修改密码响应
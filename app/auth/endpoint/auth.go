@@ -88,3 +88,178 @@ func MakeGetTenantListEndpoint(svc services.IAuthService) endpoint.Endpoint {
 		return svc.GetTenantList()
 	}
 }
+
+// LogoutRequest 注销请求
+type LogoutRequest struct {
+	SessionID string
+	JTI       string
+}
+
+// MakeLogoutEndpoint 创建注销端点
+func MakeLogoutEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(LogoutRequest)
+		if err := svc.Logout(ctx, req.SessionID, req.JTI); err != nil {
+			return nil, err
+		}
+		return map[string]string{"message": "已退出登录"}, nil
+	}
+}
+
+// SessionRequest 会话请求
+type SessionRequest struct {
+	TenantCode       string
+	UserID           string
+	CurrentSessionID string
+	SessionID        string
+}
+
+// MakeListSessionsEndpoint 创建会话列表端点
+func MakeListSessionsEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SessionRequest)
+		return svc.ListSessions(ctx, req.TenantCode, req.UserID, req.CurrentSessionID)
+	}
+}
+
+// MakeRevokeSessionEndpoint 创建注销会话端点
+func MakeRevokeSessionEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SessionRequest)
+		if err := svc.RevokeSession(ctx, req.TenantCode, req.UserID, req.SessionID); err != nil {
+			return nil, err
+		}
+		return map[string]string{"message": "会话已注销"}, nil
+	}
+}
+
+// MakeGetCaptchaEndpoint 创建获取验证码端点
+func MakeGetCaptchaEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return svc.GetCaptcha(ctx)
+	}
+}
+
+// MakeLoginTwoFactorEndpoint 创建登录第二步（两步验证）端点
+func MakeLoginTwoFactorEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.TwoFactorLoginRequest)
+		return svc.LoginTwoFactor(req)
+	}
+}
+
+// MakeStartSSOEndpoint 创建发起单点登录端点
+func MakeStartSSOEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.SSOStartRequest)
+		return svc.StartSSO(ctx, req)
+	}
+}
+
+// MakeLoginSSOEndpoint 创建单点登录回调端点
+func MakeLoginSSOEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.SSOCallbackRequest)
+		return svc.LoginSSO(req)
+	}
+}
+
+// TwoFactorRequest 两步验证管理请求（用户身份来自令牌）
+type TwoFactorRequest struct {
+	TenantCode string
+	UserID     string
+	Code       string
+	Password   string
+}
+
+// MakeGetTwoFactorStatusEndpoint 创建两步验证状态端点
+func MakeGetTwoFactorStatusEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(TwoFactorRequest)
+		return svc.GetTwoFactorStatus(ctx, req.TenantCode, req.UserID)
+	}
+}
+
+// MakeSetupTwoFactorEndpoint 创建获取两步验证密钥端点
+func MakeSetupTwoFactorEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(TwoFactorRequest)
+		return svc.SetupTwoFactor(ctx, req.TenantCode, req.UserID)
+	}
+}
+
+// MakeEnableTwoFactorEndpoint 创建启用两步验证端点
+func MakeEnableTwoFactorEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(TwoFactorRequest)
+		return svc.EnableTwoFactor(ctx, req.TenantCode, req.UserID, dto.TwoFactorCodeRequest{Code: req.Code})
+	}
+}
+
+// MakeDisableTwoFactorEndpoint 创建关闭两步验证端点
+func MakeDisableTwoFactorEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(TwoFactorRequest)
+		err := svc.DisableTwoFactor(ctx, req.TenantCode, req.UserID, dto.TwoFactorDisableRequest{
+			Password: req.Password,
+			Code:     req.Code,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"message": "两步验证已关闭"}, nil
+	}
+}
+
+// MakeRegenerateRecoveryCodesEndpoint 创建重新生成恢复码端点
+func MakeRegenerateRecoveryCodesEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(TwoFactorRequest)
+		return svc.RegenerateRecoveryCodes(ctx, req.TenantCode, req.UserID, dto.TwoFactorCodeRequest{Code: req.Code})
+	}
+}
+
+// MakeGetJWKSEndpoint 创建获取签名公钥端点
+func MakeGetJWKSEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return svc.GetJWKS()
+	}
+}
+
+// ImpersonationRequest 代管请求（调用者身份来自令牌）
+type ImpersonationRequest struct {
+	TenantCode             string
+	UserID                 string
+	MFA                    bool
+	CurrentImpersonationID string
+	ID                     string
+	Start                  dto.StartImpersonationRequest
+	List                   dto.ImpersonationListRequest
+}
+
+// MakeStartImpersonationEndpoint 创建申请代管租户端点
+func MakeStartImpersonationEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ImpersonationRequest)
+		return svc.StartImpersonation(ctx, req.TenantCode, req.UserID, req.MFA, req.Start)
+	}
+}
+
+// MakeEndImpersonationEndpoint 创建结束代管端点
+func MakeEndImpersonationEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ImpersonationRequest)
+		if err := svc.EndImpersonation(ctx, req.UserID, req.CurrentImpersonationID, req.ID); err != nil {
+			return nil, err
+		}
+		return map[string]string{"message": "代管已结束"}, nil
+	}
+}
+
+// MakeListImpersonationsEndpoint 创建代管记录列表端点
+func MakeListImpersonationsEndpoint(svc services.IAuthService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ImpersonationRequest)
+		return svc.ListImpersonations(ctx, req.TenantCode, req.List)
+	}
+}
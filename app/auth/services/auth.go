@@ -5,13 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"mule-cloud/app/auth/dto"
+	"mule-cloud/core/captcha"
+	"mule-cloud/core/config"
 	tenantCtx "mule-cloud/core/context"
 	"mule-cloud/core/httpclient"
 	jwtPkg "mule-cloud/core/jwt"
-	"mule-cloud/core/logger"
+	"mule-cloud/core/password"
+	"mule-cloud/core/security"
+	"mule-cloud/core/session"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
-	"mule-cloud/util"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -24,6 +27,12 @@ var (
 	ErrUserExists      = errors.New("用户已存在")
 	ErrUserDisabled    = errors.New("用户已被禁用")
 	ErrInvalidToken    = errors.New("token无效")
+
+	ErrTwoFactorInvalid    = errors.New("两步验证码错误")
+	ErrTwoFactorNotEnabled = errors.New("未启用两步验证")
+	ErrTwoFactorEnabled    = errors.New("两步验证已启用")
+	ErrTwoFactorNotSetup   = errors.New("请先获取两步验证密钥")
+	ErrTwoFactorRequired   = errors.New("租户要求启用两步验证，不能关闭")
 )
 
 // IAuthService 认证服务接口
@@ -31,26 +40,50 @@ type IAuthService interface {
 	Login(req dto.LoginRequest) (*dto.LoginResponse, error)
 	Register(req dto.RegisterRequest) (*dto.RegisterResponse, error)
 	RefreshToken(req dto.RefreshTokenRequest) (*dto.RefreshTokenResponse, error)
+	Logout(ctx context.Context, sessionID, jti string) error
+	ListSessions(ctx context.Context, tenantCode, userID, currentSessionID string) (*dto.SessionListResponse, error)
+	RevokeSession(ctx context.Context, tenantCode, userID, sessionID string) error
+	GetCaptcha(ctx context.Context) (*captcha.Challenge, error)
+	GetJWKS() (*jwtPkg.JWKS, error)
+	LoginTwoFactor(req dto.TwoFactorLoginRequest) (*dto.LoginResponse, error)
+	StartSSO(ctx context.Context, req dto.SSOStartRequest) (*dto.SSOStartResponse, error)
+	LoginSSO(req dto.SSOCallbackRequest) (*dto.LoginResponse, error)
+	GetTwoFactorStatus(ctx context.Context, tenantCode, userID string) (*dto.TwoFactorStatusResponse, error)
+	SetupTwoFactor(ctx context.Context, tenantCode, userID string) (*dto.TwoFactorSetupResponse, error)
+	EnableTwoFactor(ctx context.Context, tenantCode, userID string, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
+	DisableTwoFactor(ctx context.Context, tenantCode, userID string, req dto.TwoFactorDisableRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, tenantCode, userID string, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
 	GetProfile(ctx context.Context, userID string) (*dto.GetProfileResponse, error)
 	UpdateProfile(ctx context.Context, userID string, req dto.UpdateProfileRequest) (*dto.UpdateProfileResponse, error)
 	ChangePassword(ctx context.Context, userID string, req dto.ChangePasswordRequest) (*dto.ChangePasswordResponse, error)
 	ValidateToken(token string) (*jwtPkg.Claims, error)
 	GetUserRoutes(ctx context.Context, userID string) (*dto.GetUserRoutesResponse, error)
 	GetTenantList() (*dto.GetTenantListResponse, error)
+	StartImpersonation(ctx context.Context, tenantCode, userID string, mfa bool, req dto.StartImpersonationRequest) (*dto.ImpersonationTokenResponse, error)
+	EndImpersonation(ctx context.Context, userID, currentImpersonationID, id string) error
+	ListImpersonations(ctx context.Context, tenantCode string, req dto.ImpersonationListRequest) (*dto.ImpersonationListResponse, error)
 }
 
 // AuthService 认证服务实现
 type AuthService struct {
-	repo       repository.AdminRepository
-	tenantRepo repository.TenantRepository
-	roleRepo   repository.RoleRepository
-	menuRepo   *repository.MenuRepository
-	jwtManager *jwtPkg.JWTManager
-	httpClient *httpclient.ServiceClient
+	repo         repository.AdminRepository
+	tenantRepo   repository.TenantRepository
+	roleRepo     repository.RoleRepository
+	menuRepo     *repository.MenuRepository
+	jwtManager   *jwtPkg.JWTManager
+	httpClient   *httpclient.ServiceClient
+	refreshTTL   time.Duration // 刷新令牌有效期
+	guard        *security.LoginGuard
+	issuer       string        // 两步验证发行方
+	challengeTTL time.Duration // 登录第二步有效期
+
+	impersonationRepo    *repository.ImpersonationRepository
+	impersonationDefault int // 代管令牌默认有效期（分钟）
+	impersonationMax     int // 代管令牌最长有效期（分钟）
 }
 
 // NewAuthService 创建认证服务
-func NewAuthService(jwtManager *jwtPkg.JWTManager) IAuthService {
+func NewAuthService(jwtManager *jwtPkg.JWTManager, refreshTTL time.Duration, guard *security.LoginGuard, twoFactor *config.TwoFactorConfig, impersonation *config.ImpersonationConfig) IAuthService {
 	repo := repository.NewAdminRepository()
 	tenantRepo := repository.NewTenantRepository()
 	roleRepo := repository.NewRoleRepository()
@@ -60,16 +93,44 @@ func NewAuthService(jwtManager *jwtPkg.JWTManager) IAuthService {
 	client, err := httpclient.NewServiceClient("localhost:8500")
 	if err != nil {
 		// 如果 Consul 不可用，记录日志但不阻止服务启动
-		logger.Warn("无法连接 Consul，服务间调用将使用默认配置", zap.Error(err))
+		log.Warn("无法连接 Consul，服务间调用将使用默认配置", zap.Error(err))
+	}
+
+	issuer, challengeTTL := "Mule Cloud", 5*time.Minute
+	if twoFactor != nil {
+		if twoFactor.Issuer != "" {
+			issuer = twoFactor.Issuer
+		}
+		if twoFactor.ChallengeMinutes > 0 {
+			challengeTTL = time.Duration(twoFactor.ChallengeMinutes) * time.Minute
+		}
+	}
+
+	impersonationDefault, impersonationMax := 30, 120
+	if impersonation != nil {
+		if impersonation.DefaultMinutes > 0 {
+			impersonationDefault = impersonation.DefaultMinutes
+		}
+		if impersonation.MaxMinutes > 0 {
+			impersonationMax = impersonation.MaxMinutes
+		}
 	}
 
 	return &AuthService{
-		repo:       repo,
-		tenantRepo: tenantRepo,
-		roleRepo:   roleRepo,
-		menuRepo:   menuRepo,
-		jwtManager: jwtManager,
-		httpClient: client,
+		repo:         repo,
+		tenantRepo:   tenantRepo,
+		roleRepo:     roleRepo,
+		menuRepo:     menuRepo,
+		jwtManager:   jwtManager,
+		httpClient:   client,
+		refreshTTL:   refreshTTL,
+		guard:        guard,
+		issuer:       issuer,
+		challengeTTL: challengeTTL,
+
+		impersonationRepo:    repository.NewImpersonationRepository(),
+		impersonationDefault: impersonationDefault,
+		impersonationMax:     impersonationMax,
 	}
 }
 
@@ -80,25 +141,34 @@ func (s *AuthService) Login(req dto.LoginRequest) (*dto.LoginResponse, error) {
 
 	var tenantID string
 	var tenantCode string // ✅ 新增：租户代码（用于数据库连接）
+	var require2FA bool   // 租户是否强制两步验证
 	var admin *models.Admin
 	var err error
 
 	// 1. 如果提供了租户代码，先查询租户
 	if req.TenantCode != "" {
-		logger.Info("租户登录",
+		log.Info("租户登录",
 			zap.String("phone", req.Phone),
 			zap.String("tenant_code", req.TenantCode))
 
 		// 查询租户信息
 		tenant, err := s.tenantRepo.GetByCode(ctx, req.TenantCode)
 		if err != nil || tenant == nil {
-			logger.Warn("租户不存在", zap.String("tenant_code", req.TenantCode))
+			log.Warn("租户不存在", zap.String("tenant_code", req.TenantCode))
+			// 租户不存在时事件记录到系统库，避免按请求中的租户代码创建数据库
+			s.loginFailed(ctx, req, req.TenantCode, "system", "", "租户不存在")
 			return nil, fmt.Errorf("租户不存在或已禁用")
 		}
 
+		// 租户强制单点登录时不允许手机号+密码登录
+		if tenant.SSO.Enabled && tenant.SSO.Enforce {
+			return nil, ErrSSORequired
+		}
+
 		tenantID = tenant.ID
 		tenantCode = tenant.Code // ✅ 保存租户代码
-		logger.Info("找到租户",
+		require2FA = tenant.Require2FA
+		log.Info("找到租户",
 			zap.String("id", tenant.ID),
 			zap.String("code", tenant.Code),
 			zap.String("name", tenant.Name))
@@ -109,57 +179,115 @@ func (s *AuthService) Login(req dto.LoginRequest) (*dto.LoginResponse, error) {
 		// 在租户库中查询用户
 		admin, err = s.repo.GetByPhone(ctx, req.Phone)
 		if err != nil {
-			logger.Error("查询租户用户失败", zap.Error(err))
+			log.Error("查询租户用户失败", zap.Error(err))
 			return nil, fmt.Errorf("查询用户失败: %w", err)
 		}
 	} else {
 		// 2. 未提供租户代码，查询系统库（系统超管）
-		logger.Info("系统管理员登录", zap.String("phone", req.Phone))
+		log.Info("系统管理员登录", zap.String("phone", req.Phone))
 		tenantCode = "system"                   // ✅ 设置默认租户代码，用于系统管理员的文件存储等
 		ctx = tenantCtx.WithTenantCode(ctx, "") // 空=系统库（用于查询admin表）
 		admin, err = s.repo.GetByPhone(ctx, req.Phone)
 		if err != nil {
-			logger.Error("查询系统用户失败", zap.Error(err))
+			log.Error("查询系统用户失败", zap.Error(err))
 			return nil, fmt.Errorf("查询用户失败: %w", err)
 		}
-		logger.Debug("查询结果", zap.Bool("admin_found", admin != nil))
+		log.Debug("查询结果", zap.Bool("admin_found", admin != nil))
+	}
+
+	// 登录防护：锁定、递增等待、验证码
+	if err := s.checkLoginGuard(ctx, req, tenantCode); err != nil {
+		return nil, err
 	}
 
 	if admin == nil {
-		logger.Warn("用户不存在", zap.String("phone", req.Phone))
+		log.Warn("用户不存在", zap.String("phone", req.Phone))
+		s.loginFailed(ctx, req, tenantCode, tenantCode, "", ErrUserNotFound.Error())
 		return nil, ErrUserNotFound
 	}
 
-	logger.Info("找到用户",
+	log.Info("找到用户",
 		zap.String("id", admin.ID),
 		zap.String("nickname", admin.Nickname),
 		zap.Strings("roles", admin.Roles))
 
 	// 验证密码
-	hashedPassword := hashPassword(req.Password)
-	if admin.Password != hashedPassword {
+	ok, needsRehash := password.Verify(req.Password, admin.Password)
+	if !ok {
+		s.loginFailed(ctx, req, tenantCode, tenantCode, admin.ID, ErrInvalidPassword.Error())
 		return nil, ErrInvalidPassword
 	}
 
 	// 检查用户状态
 	if admin.Status != 1 {
+		security.RecordEvent(tenantCode, s.loginEvent(req, security.EventLoginFailed, admin.ID, ErrUserDisabled.Error()))
 		return nil, ErrUserDisabled
 	}
 
-	// 生成JWT Token（同时包含 tenant_id 和 tenant_code）
-	token, err := s.jwtManager.GenerateToken(admin.ID, admin.Nickname, tenantID, tenantCode, admin.Roles)
+	// 旧格式（MD5）或参数已变更的哈希，密码校验通过后用当前算法重新哈希
+	if needsRehash {
+		if hash, err := password.Hash(req.Password); err != nil {
+			log.Warn("密码重新哈希失败", zap.String("user_id", admin.ID), zap.Error(err))
+		} else if err := s.repo.Update(ctx, admin.ID, bson.M{"password": hash}); err != nil {
+			log.Warn("保存重新哈希的密码失败", zap.String("user_id", admin.ID), zap.Error(err))
+		}
+	}
+
+	// 已启用两步验证或租户强制要求时，先返回挑战令牌，验证通过后再签发令牌
+	if admin.TwoFactor.Enabled || require2FA {
+		return s.startTwoFactor(ctx, req, admin, tenantID, tenantCode)
+	}
+
+	return s.completeLogin(ctx, req, admin, tenantID, tenantCode, false)
+}
+
+// completeLogin 创建登录会话并签发访问令牌，更新登录信息
+func (s *AuthService) completeLogin(ctx context.Context, req dto.LoginRequest, admin *models.Admin, tenantID, tenantCode string, mfa bool) (*dto.LoginResponse, error) {
+	// 创建登录会话并签发访问令牌（同时包含 tenant_id 和 tenant_code）
+	sess := &session.Session{
+		UserID:     admin.ID,
+		Username:   admin.Nickname,
+		TenantID:   tenantID,
+		TenantCode: tenantCode,
+		Roles:      admin.Roles,
+		MFA:        mfa,
+		Device:     req.Device,
+		UserAgent:  req.UserAgent,
+		IP:         req.IP,
+	}
+	if session.Enabled() {
+		sess.ID = session.NewID()
+	} else {
+		log.Ctx(ctx).Warn("Redis未启用，登录不创建会话，无法刷新和注销令牌", zap.String("user_id", admin.ID))
+	}
+	token, claims, err := s.jwtManager.IssueToken(&jwtPkg.Claims{
+		UserID:     admin.ID,
+		Username:   admin.Nickname,
+		TenantID:   tenantID,
+		TenantCode: tenantCode,
+		Roles:      admin.Roles,
+		SessionID:  sess.ID,
+		MFA:        mfa,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("生成token失败: %w", err)
 	}
 
-	// 计算过期时间
-	expiresAt := time.Now().Add(24 * time.Hour).Unix()
+	var refreshToken string
+	if sess.ID != "" {
+		sess.AccessJTI = claims.ID
+		sess.AccessExpiresAt = claims.ExpiresAt.Unix()
+		refreshToken, err = session.Create(ctx, sess, s.refreshTTL)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// 获取用户的菜单权限
 	menuPermissions, err := s.getUserMenuPermissions(ctx, admin.ID, tenantID)
 	if err != nil {
 		// 如果获取失败，记录日志但不中断登录
-		logger.Warn("获取用户菜单权限失败", zap.Error(err))
+		log.Ctx(ctx).Warn("获取用户菜单权限失败", zap.Error(err))
 	}
 
 	extend := models.Extend{
@@ -173,19 +301,94 @@ func (s *AuthService) Login(req dto.LoginRequest) (*dto.LoginResponse, error) {
 		return nil, fmt.Errorf("更新用户扩展字段失败: %w", err)
 	}
 
+	s.guard.Succeed(ctx, tenantCode, req.Phone)
+	security.RecordEvent(tenantCode, s.loginEvent(req, security.EventLoginSuccess, admin.ID, ""))
+
 	return &dto.LoginResponse{
-		Token:           token,
-		UserID:          admin.ID,
-		Phone:           admin.Phone,
-		Nickname:        admin.Nickname,
-		Avatar:          admin.Avatar,
-		Role:            admin.Roles,
-		TenantID:        tenantID,
-		MenuPermissions: menuPermissions,
-		ExpiresAt:       expiresAt,
+		Token:            token,
+		UserID:           admin.ID,
+		Phone:            admin.Phone,
+		Nickname:         admin.Nickname,
+		Avatar:           admin.Avatar,
+		Role:             admin.Roles,
+		TenantID:         tenantID,
+		MenuPermissions:  menuPermissions,
+		ExpiresAt:        claims.ExpiresAt.Unix(),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: sess.ExpiresAt,
+		SessionID:        sess.ID,
 	}, nil
 }
 
+// checkLoginGuard 登录前检查账号/IP是否被锁定，失败次数较多时要求验证码
+func (s *AuthService) checkLoginGuard(ctx context.Context, req dto.LoginRequest, tenantCode string) error {
+	state, err := s.guard.Check(ctx, tenantCode, req.Phone, req.IP)
+	if err != nil {
+		security.RecordEvent(tenantCode, s.loginEvent(req, security.EventLoginBlocked, "", err.Error()))
+		return err
+	}
+	if !state.CaptchaRequired {
+		return nil
+	}
+	if req.CaptchaID == "" {
+		security.RecordEvent(tenantCode, s.loginEvent(req, security.EventCaptchaFailed, "", security.ErrCaptchaRequired.Error()))
+		return security.ErrCaptchaRequired
+	}
+	if !captcha.Verify(ctx, req.CaptchaID, req.CaptchaCode) {
+		security.RecordEvent(tenantCode, s.loginEvent(req, security.EventCaptchaFailed, "", security.ErrCaptchaInvalid.Error()))
+		return security.ErrCaptchaInvalid
+	}
+	return nil
+}
+
+// loginFailed 记录登录失败次数和安全事件
+// guardTenant 用于失败计数，eventTenant 决定事件写入哪个库
+func (s *AuthService) loginFailed(ctx context.Context, req dto.LoginRequest, guardTenant, eventTenant, userID, reason string) {
+	security.RecordEvent(eventTenant, s.loginEvent(req, security.EventLoginFailed, userID, reason))
+
+	result, err := s.guard.Fail(ctx, guardTenant, req.Phone, req.IP)
+	if err != nil {
+		log.Ctx(ctx).Warn("记录登录失败次数失败", zap.String("phone", req.Phone), zap.Error(err))
+		return
+	}
+	if result.AccountLocked {
+		log.Ctx(ctx).Warn("账号登录失败次数过多已锁定", zap.String("phone", req.Phone), zap.String("tenant_code", guardTenant))
+		security.RecordEvent(eventTenant, s.loginEvent(req, security.EventAccountLocked, userID,
+			fmt.Sprintf("连续失败%d次", result.Failures)))
+	}
+	if result.IPLocked {
+		log.Ctx(ctx).Warn("IP登录失败次数过多已锁定", zap.String("ip", req.IP))
+		security.RecordEvent(eventTenant, s.loginEvent(req, security.EventIPLocked, userID, "IP "+req.IP))
+	}
+}
+
+// loginEvent 构造登录相关的安全事件
+func (s *AuthService) loginEvent(req dto.LoginRequest, eventType, userID, detail string) security.Event {
+	return security.Event{
+		Type:      eventType,
+		UserID:    userID,
+		Username:  req.Phone,
+		Path:      "/auth/login",
+		IP:        req.IP,
+		UserAgent: req.UserAgent,
+		Detail:    detail,
+	}
+}
+
+// GetCaptcha 获取登录图形验证码
+func (s *AuthService) GetCaptcha(ctx context.Context) (*captcha.Challenge, error) {
+	return captcha.Generate(ctx)
+}
+
+// GetJWKS 获取令牌签名公钥（网关和其他服务据此验证令牌）
+func (s *AuthService) GetJWKS() (*jwtPkg.JWKS, error) {
+	set, ok := s.jwtManager.JWKS()
+	if !ok {
+		return nil, errors.New("未启用非对称签名（jwt.keys），没有可发布的公钥")
+	}
+	return set, nil
+}
+
 // Register 注册
 func (s *AuthService) Register(req dto.RegisterRequest) (*dto.RegisterResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -200,11 +403,19 @@ func (s *AuthService) Register(req dto.RegisterRequest) (*dto.RegisterResponse,
 		return nil, ErrUserExists
 	}
 
+	if err := password.Validate(req.Password); err != nil {
+		return nil, err
+	}
+	hash, err := password.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建新用户
 	now := time.Now().Unix()
 	admin := &models.Admin{
 		Phone:     req.Phone,
-		Password:  hashPassword(req.Password),
+		Password:  hash,
 		Nickname:  req.Nickname,
 		Email:     req.Email,
 		Status:    1,                // 默认启用
@@ -228,22 +439,109 @@ func (s *AuthService) Register(req dto.RegisterRequest) (*dto.RegisterResponse,
 	}, nil
 }
 
-// RefreshToken 刷新Token
+// RefreshToken 使用刷新令牌换取新的访问令牌（刷新令牌同时轮换）
 func (s *AuthService) RefreshToken(req dto.RefreshTokenRequest) (*dto.RefreshTokenResponse, error) {
-	// 刷新token
-	newToken, err := s.jwtManager.RefreshToken(req.Token)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sess, refreshToken, err := session.Rotate(ctx, req.RefreshToken, req.IP, s.refreshTTL)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, err
 	}
 
-	expiresAt := time.Now().Add(24 * time.Hour).Unix()
+	// 重新读取用户，禁用或删除的用户不能继续刷新，角色变更在刷新后生效
+	admin, err := s.repo.Get(adminCtx(ctx, sess.TenantCode), sess.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	if admin == nil || admin.Status != 1 {
+		_ = session.Revoke(ctx, sess.ID)
+		if admin == nil {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrUserDisabled
+	}
+
+	token, claims, err := s.jwtManager.IssueToken(&jwtPkg.Claims{
+		UserID:     admin.ID,
+		Username:   admin.Nickname,
+		TenantID:   sess.TenantID,
+		TenantCode: sess.TenantCode,
+		Roles:      admin.Roles,
+		SessionID:  sess.ID,
+		MFA:        sess.MFA, // 两步验证状态在会话内保持
+	})
+	if err != nil {
+		return nil, fmt.Errorf("生成token失败: %w", err)
+	}
+	if err := session.SetAccess(ctx, sess.ID, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
 
 	return &dto.RefreshTokenResponse{
-		Token:     newToken,
-		ExpiresAt: expiresAt,
+		Token:            token,
+		ExpiresAt:        claims.ExpiresAt.Unix(),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: sess.ExpiresAt,
 	}, nil
 }
 
+// Logout 注销当前会话；没有会话的旧令牌只吊销令牌本身
+func (s *AuthService) Logout(ctx context.Context, sessionID, jti string) error {
+	if sessionID != "" {
+		err := session.Revoke(ctx, sessionID)
+		if err == nil || !errors.Is(err, session.ErrNotFound) {
+			return err
+		}
+	}
+	return session.Deny(ctx, jti, time.Now().Add(s.jwtManager.TokenDuration()))
+}
+
+// ListSessions 获取用户的登录会话
+func (s *AuthService) ListSessions(ctx context.Context, tenantCode, userID, currentSessionID string) (*dto.SessionListResponse, error) {
+	sessions, err := session.List(ctx, tenantCode, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]dto.SessionItem, 0, len(sessions))
+	for _, sess := range sessions {
+		items = append(items, dto.SessionItem{
+			ID:         sess.ID,
+			Device:     sess.Device,
+			UserAgent:  sess.UserAgent,
+			IP:         sess.IP,
+			CreatedAt:  sess.CreatedAt,
+			LastSeenAt: sess.LastSeenAt,
+			ExpiresAt:  sess.ExpiresAt,
+			Current:    sess.ID == currentSessionID,
+		})
+	}
+	return &dto.SessionListResponse{Sessions: items, Total: len(items)}, nil
+}
+
+// RevokeSession 注销用户自己的某个会话
+func (s *AuthService) RevokeSession(ctx context.Context, tenantCode, userID, sessionID string) error {
+	sess, err := session.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if sess.UserID != userID || !sameTenant(sess.TenantCode, tenantCode) {
+		return session.ErrNotFound
+	}
+	return session.Revoke(ctx, sessionID)
+}
+
+// sameTenant 系统库用户的租户代码可能为空或 system
+func sameTenant(a, b string) bool {
+	if a == "" {
+		a = "system"
+	}
+	if b == "" {
+		b = "system"
+	}
+	return a == b
+}
+
 // GetProfile 获取个人信息
 func (s *AuthService) GetProfile(ctx context.Context, userID string) (*dto.GetProfileResponse, error) {
 	// ✅ 特殊处理：如果 tenant_code 是 "system"，转换为空字符串（查询系统库）
@@ -273,7 +571,7 @@ func (s *AuthService) GetProfile(ctx context.Context, userID string) (*dto.GetPr
 	menuPermissions, err := s.getUserMenuPermissions(ctx, admin.ID, tenantID)
 	if err != nil {
 		// 如果获取失败，记录日志但不中断
-		fmt.Printf("警告: 获取用户菜单权限失败: %v\n", err)
+		log.Ctx(ctx).Warn("获取用户菜单权限失败", zap.Error(err))
 	}
 
 	return &dto.GetProfileResponse{
@@ -351,14 +649,27 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID string, req dto
 	}
 
 	// 验证旧密码
-	if admin.Password != hashPassword(req.OldPassword) {
+	if ok, _ := password.Verify(req.OldPassword, admin.Password); !ok {
 		return nil, ErrInvalidPassword
 	}
 
+	// 校验密码策略和历史密码
+	if err := password.Validate(req.NewPassword); err != nil {
+		return nil, err
+	}
+	if err := password.CheckReuse(req.NewPassword, admin.Password, admin.PasswordHistory); err != nil {
+		return nil, err
+	}
+	hash, err := password.Hash(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
 	// 更新密码
 	update := bson.M{
-		"password":   hashPassword(req.NewPassword),
-		"updated_at": time.Now().Unix(),
+		"password":         hash,
+		"password_history": password.PushHistory(admin.PasswordHistory, admin.Password),
+		"updated_at":       time.Now().Unix(),
 	}
 
 	err = s.repo.Update(ctx, admin.ID, update)
@@ -428,14 +739,14 @@ func (s *AuthService) GetUserRoutes(ctx context.Context, userID string) (*dto.Ge
 		if role == "super" {
 			// ✅ 如果超管切换到了特定租户，返回该租户的菜单
 			if userTenantCode != "" && userTenantCode != "system" {
-				logger.Info("超管切换租户，返回租户菜单",
+				log.Ctx(ctx).Info("超管切换租户，返回租户菜单",
 					zap.String("user_id", userID),
 					zap.String("tenant_code", userTenantCode))
 
 				// 获取租户信息
 				tenant, err := s.tenantRepo.GetByCode(context.Background(), userTenantCode)
 				if err != nil || tenant == nil {
-					logger.Error("获取租户信息失败", zap.Error(err))
+					log.Ctx(ctx).Error("获取租户信息失败", zap.Error(err))
 					return nil, fmt.Errorf("获取租户信息失败")
 				}
 
@@ -492,7 +803,7 @@ func (s *AuthService) GetUserRoutes(ctx context.Context, userID string) (*dto.Ge
 					}
 				}
 
-				logger.Info("超管切换租户后的菜单",
+				log.Ctx(ctx).Info("超管切换租户后的菜单",
 					zap.String("tenant_code", userTenantCode),
 					zap.Strings("tenant_menus", tenant.Menus),
 					zap.Int("original_count", len(tenant.Menus)),
@@ -504,7 +815,7 @@ func (s *AuthService) GetUserRoutes(ctx context.Context, userID string) (*dto.Ge
 			}
 
 			// ✅ 超管未切换租户，返回所有菜单
-			logger.Info("超管未切换租户，返回所有菜单", zap.String("user_id", userID))
+			log.Ctx(ctx).Info("超管未切换租户，返回所有菜单", zap.String("user_id", userID))
 			menus, err := s.fetchAllMenusFromSystem()
 			if err != nil {
 				return nil, fmt.Errorf("获取菜单失败: %w", err)
@@ -531,21 +842,21 @@ func (s *AuthService) GetUserRoutes(ctx context.Context, userID string) (*dto.Ge
 			role, err := s.roleRepo.Get(ctx, roleID)
 			if err == nil && role != nil && role.Code == "tenant_admin" {
 				// 租户管理员：返回租户拥有的所有菜单
-				logger.Info("租户管理员登录，返回租户所有菜单",
+				log.Ctx(ctx).Info("租户管理员登录，返回租户所有菜单",
 					zap.String("user_id", userID),
 					zap.String("tenant_code", userTenantCode))
 
 				// ✅ 使用 userTenantCode 查询租户信息
 				tenant, err := s.tenantRepo.GetByCode(context.Background(), userTenantCode)
 				if err != nil || tenant == nil {
-					logger.Error("获取租户信息失败", zap.Error(err))
+					log.Ctx(ctx).Error("获取租户信息失败", zap.Error(err))
 					break
 				}
 
 				// 获取所有菜单
 				allMenus, err := s.fetchAllMenusFromSystem()
 				if err != nil {
-					logger.Error("获取所有菜单失败", zap.Error(err))
+					log.Ctx(ctx).Error("获取所有菜单失败", zap.Error(err))
 					break
 				}
 
@@ -596,7 +907,7 @@ func (s *AuthService) GetUserRoutes(ctx context.Context, userID string) (*dto.Ge
 					}
 				}
 
-				logger.Info("租户管理员菜单",
+				log.Ctx(ctx).Info("租户管理员菜单",
 					zap.String("tenant_code", userTenantCode),
 					zap.Strings("tenant_menus", tenant.Menus),
 					zap.Int("original_count", len(tenant.Menus)),
@@ -614,19 +925,20 @@ func (s *AuthService) GetUserRoutes(ctx context.Context, userID string) (*dto.Ge
 		menus, err := s.fetchRoleMenusFromSystem(ctx, roleID) // ✅ 传递 ctx，保留租户信息
 		if err != nil {
 			// 忽略单个角色的错误，继续处理其他角色
-			fmt.Printf("警告: 获取角色 %s 菜单失败: %v\n", roleID, err)
+			log.Ctx(ctx).Warn("获取角色菜单失败", zap.String("role_id", roleID), zap.Error(err))
 			continue
 		}
 
-		// ✅ 调试日志：查看角色分配的菜单
-		fmt.Printf("[调试] 角色 %s 的菜单数量: %d\n", roleID, len(menus))
-		for i, menu := range menus {
-			if i < 10 { // 只打印前10个，避免日志过多
-				fmt.Printf("[调试]   %d. %s (%s)\n", i+1, menu.Name, menu.Title)
+		// 调试日志：查看角色分配的菜单（只输出前10个，避免日志过多）
+		if ce := log.Ctx(ctx).Check(zap.DebugLevel, "角色菜单"); ce != nil {
+			names := make([]string, 0, 10)
+			for i, menu := range menus {
+				if i == 10 {
+					break
+				}
+				names = append(names, menu.Name)
 			}
-		}
-		if len(menus) > 10 {
-			fmt.Printf("[调试]   ... 还有 %d 个菜单\n", len(menus)-10)
+			ce.Write(zap.String("role_id", roleID), zap.Int("count", len(menus)), zap.Strings("menus", names))
 		}
 
 		for _, menu := range menus {
@@ -758,11 +1070,11 @@ func (s *AuthService) getUserMenuPermissions(ctx context.Context, userID, tenant
 		// 直接从数据库获取角色信息（会自动根据context中的租户ID查询）
 		role, err := s.roleRepo.Get(ctx, roleID)
 		if err != nil {
-			fmt.Printf("警告: 获取角色 %s 信息失败: %v\n", roleID, err)
+			log.Ctx(ctx).Warn("获取角色信息失败", zap.String("role_id", roleID), zap.Error(err))
 			continue
 		}
 		if role == nil {
-			fmt.Printf("警告: 角色 %s 不存在\n", roleID)
+			log.Ctx(ctx).Warn("角色不存在", zap.String("role_id", roleID))
 			continue
 		}
 
@@ -827,7 +1139,3 @@ func (s *AuthService) GetTenantList() (*dto.GetTenantListResponse, error) {
 		Total:   len(tenantItems),
 	}, nil
 }
-
-func hashPassword(password string) string {
-	return util.ToolsUtil.Md5(password + "mule-zdm")
-}
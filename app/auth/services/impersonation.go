@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mule-cloud/app/auth/dto"
+	tenantCtx "mule-cloud/core/context"
+	jwtPkg "mule-cloud/core/jwt"
+	"mule-cloud/core/security"
+	"mule-cloud/core/session"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrImpersonationForbidden = errors.New("只有系统超管可以代管租户")
+	ErrImpersonationMFA       = errors.New("代管租户需要先启用两步验证并重新登录")
+	ErrImpersonationNotFound  = errors.New("代管记录不存在或已结束")
+	ErrImpersonationListDeny  = errors.New("只有系统超管和租户管理员可以查看代管记录")
+)
+
+const impersonationPath = "/auth/impersonations"
+
+// StartImpersonation 系统超管申请代管租户，签发限时的代管令牌
+// 代管令牌属于目标租户，不能刷新；代管期间的请求都会在系统库和租户库记录操作日志
+func (s *AuthService) StartImpersonation(ctx context.Context, tenantCode, userID string, mfa bool, req dto.StartImpersonationRequest) (*dto.ImpersonationTokenResponse, error) {
+	if !sameTenant(tenantCode, "system") || !hasSuperRole(tenantCtx.GetRoles(ctx)) {
+		return nil, ErrImpersonationForbidden
+	}
+	if !mfa {
+		return nil, ErrImpersonationMFA
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("请填写代管原因")
+	}
+
+	admin, err := s.getSelf(ctx, tenantCode, userID)
+	if err != nil {
+		return nil, err
+	}
+	systemCtx := tenantCtx.WithTenantCode(ctx, "")
+	tenant, err := s.tenantRepo.GetByCode(systemCtx, req.TenantCode)
+	if err != nil {
+		return nil, fmt.Errorf("查询租户失败: %w", err)
+	}
+	if tenant == nil || sameTenant(tenant.Code, "system") {
+		return nil, fmt.Errorf("租户不存在")
+	}
+
+	ttl := s.impersonationTTL(req.Minutes)
+	now := time.Now()
+	imp := &models.Impersonation{
+		AdminID:    admin.ID,
+		AdminName:  admin.Nickname,
+		TenantID:   tenant.ID,
+		TenantCode: tenant.Code,
+		Reason:     reason,
+		ReadOnly:   req.ReadOnly,
+		IP:         req.IP,
+		ExpiresAt:  now.Add(ttl).Unix(),
+		CreatedAt:  now.Unix(),
+	}
+	if err := s.impersonationRepo.Create(systemCtx, imp); err != nil {
+		return nil, fmt.Errorf("创建代管记录失败: %w", err)
+	}
+
+	token, claims, err := s.jwtManager.IssueTokenWithTTL(&jwtPkg.Claims{
+		UserID:          admin.ID,
+		Username:        admin.Nickname,
+		TenantID:        tenant.ID,
+		TenantCode:      tenant.Code,
+		Roles:           admin.Roles,
+		MFA:             true,
+		ImpersonationID: imp.ID,
+		ReadOnly:        req.ReadOnly,
+	}, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("生成代管令牌失败: %w", err)
+	}
+	if err := s.impersonationRepo.Update(systemCtx, imp.ID, bson.M{
+		"token_id":   claims.ID,
+		"expires_at": claims.ExpiresAt.Unix(),
+	}); err != nil {
+		return nil, fmt.Errorf("更新代管记录失败: %w", err)
+	}
+
+	log.Ctx(ctx).Info("系统超管开始代管租户",
+		zap.String("impersonation_id", imp.ID),
+		zap.String("admin_id", admin.ID),
+		zap.String("tenant_code", tenant.Code),
+		zap.Bool("read_only", req.ReadOnly),
+		zap.String("reason", reason))
+	s.recordImpersonationEvent(imp, security.EventImpersonationStarted, req.IP, req.UserAgent)
+
+	return &dto.ImpersonationTokenResponse{
+		ID:         imp.ID,
+		Token:      token,
+		ExpiresAt:  claims.ExpiresAt.Unix(),
+		TenantCode: tenant.Code,
+		ReadOnly:   req.ReadOnly,
+	}, nil
+}
+
+// EndImpersonation 提前结束代管并吊销代管令牌（系统超管，可以在系统令牌或代管令牌下调用）
+func (s *AuthService) EndImpersonation(ctx context.Context, userID, currentImpersonationID, id string) error {
+	if !hasSuperRole(tenantCtx.GetRoles(ctx)) {
+		return ErrImpersonationForbidden
+	}
+	systemCtx := tenantCtx.WithTenantCode(ctx, "")
+	imp, err := s.impersonationRepo.Get(systemCtx, id)
+	if err != nil {
+		return fmt.Errorf("查询代管记录失败: %w", err)
+	}
+	now := time.Now()
+	if imp == nil || !imp.Active(now.Unix()) {
+		return ErrImpersonationNotFound
+	}
+	// 代管令牌只能结束自己的代管会话
+	if currentImpersonationID != "" && currentImpersonationID != imp.ID {
+		return ErrImpersonationForbidden
+	}
+
+	if err := s.impersonationRepo.End(systemCtx, imp.ID, userID, now.Unix()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrImpersonationNotFound
+		}
+		return err
+	}
+	if imp.TokenID != "" {
+		if err := session.Deny(ctx, imp.TokenID, time.Unix(imp.ExpiresAt, 0)); err != nil {
+			return err
+		}
+	}
+	s.recordImpersonationEvent(imp, security.EventImpersonationEnded, "", "")
+	return nil
+}
+
+// ListImpersonations 代管记录：系统超管查看全部，租户管理员只能查看本租户被谁代管过
+func (s *AuthService) ListImpersonations(ctx context.Context, tenantCode string, req dto.ImpersonationListRequest) (*dto.ImpersonationListResponse, error) {
+	filter := bson.M{}
+	switch {
+	case sameTenant(tenantCode, "system") && hasSuperRole(tenantCtx.GetRoles(ctx)):
+		if req.TenantCode != "" {
+			filter["tenant_code"] = req.TenantCode
+		}
+	case !sameTenant(tenantCode, "system") && s.isTenantAdmin(ctx, tenantCode):
+		filter["tenant_code"] = tenantCode
+	default:
+		return nil, ErrImpersonationListDeny
+	}
+	if req.AdminID != "" {
+		filter["admin_id"] = req.AdminID
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	list, total, err := s.impersonationRepo.List(tenantCtx.WithTenantCode(ctx, ""), filter, req.Page, req.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("查询代管记录失败: %w", err)
+	}
+	now := time.Now().Unix()
+	items := make([]dto.ImpersonationItem, 0, len(list))
+	for _, imp := range list {
+		items = append(items, dto.ImpersonationItem{
+			ID:         imp.ID,
+			AdminID:    imp.AdminID,
+			AdminName:  imp.AdminName,
+			TenantCode: imp.TenantCode,
+			Reason:     imp.Reason,
+			ReadOnly:   imp.ReadOnly,
+			IP:         imp.IP,
+			CreatedAt:  imp.CreatedAt,
+			ExpiresAt:  imp.ExpiresAt,
+			EndedAt:    imp.EndedAt,
+			EndedBy:    imp.EndedBy,
+			Active:     imp.Active(now),
+		})
+	}
+	return &dto.ImpersonationListResponse{List: items, Total: total, Page: req.Page, PageSize: req.PageSize}, nil
+}
+
+// impersonationTTL 代管令牌有效期（未指定使用默认值，超过上限按上限）
+func (s *AuthService) impersonationTTL(minutes int) time.Duration {
+	if minutes <= 0 {
+		minutes = s.impersonationDefault
+	}
+	if minutes > s.impersonationMax {
+		minutes = s.impersonationMax
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// isTenantAdmin 当前用户是否为租户管理员（角色代码 tenant_admin）
+func (s *AuthService) isTenantAdmin(ctx context.Context, tenantCode string) bool {
+	roleCtx := tenantCtx.WithTenantCode(ctx, tenantCode)
+	for _, roleID := range tenantCtx.GetRoles(ctx) {
+		role, err := s.roleRepo.Get(roleCtx, roleID)
+		if err == nil && role != nil && role.Code == "tenant_admin" {
+			return true
+		}
+	}
+	return false
+}
+
+// recordImpersonationEvent 代管开始和结束写入租户库的安全事件，租户管理员在操作日志中可见
+func (s *AuthService) recordImpersonationEvent(imp *models.Impersonation, eventType, ip, userAgent string) {
+	mode := "读写"
+	if imp.ReadOnly {
+		mode = "只读"
+	}
+	security.RecordEvent(imp.TenantCode, security.Event{
+		Type:      eventType,
+		UserID:    imp.AdminID,
+		Username:  imp.AdminName,
+		Path:      impersonationPath,
+		IP:        ip,
+		UserAgent: userAgent,
+		Detail:    fmt.Sprintf("代管会话 %s（%s）：%s", imp.ID, mode, imp.Reason),
+	})
+}
+
+func hasSuperRole(roles []string) bool {
+	for _, role := range roles {
+		if role == "super" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,6 @@
+package services
+
+import "mule-cloud/core/logger"
+
+// log 认证服务的模块日志（级别可通过 /log-levels 单独调整）
+var log = logger.For("auth")
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mule-cloud/app/auth/dto"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/oidc"
+	"mule-cloud/core/security"
+	"mule-cloud/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.uber.org/zap"
+)
+
+// ssoStateTTL 跳转到身份提供方后完成登录的时限
+const ssoStateTTL = 10 * time.Minute
+
+var (
+	ErrSSONotEnabled = errors.New("该租户未启用单点登录")
+	ErrSSORequired   = errors.New("该租户已启用单点登录，请通过企业身份提供方登录")
+	ErrSSONoAccount  = errors.New("未找到对应的管理员账号，请联系租户管理员开通")
+	ErrSSONoRole     = errors.New("企业身份未分配任何角色，请联系租户管理员")
+	ErrSSOConflict   = errors.New("该管理员已关联其他企业身份")
+)
+
+// StartSSO 发起单点登录：保存 state、nonce 和 PKCE 后返回身份提供方授权地址
+func (s *AuthService) StartSSO(ctx context.Context, req dto.SSOStartRequest) (*dto.SSOStartResponse, error) {
+	tenant, err := s.ssoTenant(ctx, req.TenantCode)
+	if err != nil {
+		return nil, err
+	}
+	client, err := oidc.NewClient(ctx, ssoConfig(&tenant.SSO))
+	if err != nil {
+		log.Ctx(ctx).Warn("连接身份提供方失败", zap.String("tenant_code", tenant.Code), zap.Error(err))
+		return nil, fmt.Errorf("连接身份提供方失败: %w", err)
+	}
+
+	state, err := oidc.RandomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.RandomToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	st := &security.SSOState{
+		TenantCode:   tenant.Code,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		Device:       req.Device,
+	}
+	if err := security.SaveSSOState(ctx, state, st, ssoStateTTL); err != nil {
+		return nil, err
+	}
+
+	return &dto.SSOStartResponse{
+		AuthorizeURL: client.AuthCodeURL(state, nonce, challenge),
+		State:        state,
+		ExpiresAt:    st.ExpiresAt,
+	}, nil
+}
+
+// LoginSSO 单点登录回调：校验授权码和 ID Token，关联或创建管理员后签发令牌
+func (s *AuthService) LoginSSO(req dto.SSOCallbackRequest) (*dto.LoginResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	st, err := security.ConsumeSSOState(ctx, req.State)
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := s.ssoTenant(ctx, st.TenantCode)
+	if err != nil {
+		return nil, err
+	}
+	loginReq := dto.LoginRequest{
+		TenantCode: tenant.Code,
+		Device:     st.Device,
+		IP:         req.IP,
+		UserAgent:  req.UserAgent,
+	}
+
+	client, err := oidc.NewClient(ctx, ssoConfig(&tenant.SSO))
+	if err != nil {
+		return nil, s.ssoFailed(tenant.Code, loginReq, "", fmt.Errorf("连接身份提供方失败: %w", err))
+	}
+	token, err := client.Exchange(ctx, req.Code, st.CodeVerifier)
+	if err != nil {
+		return nil, s.ssoFailed(tenant.Code, loginReq, "", err)
+	}
+	claims, err := client.VerifyIDToken(token.IDToken, st.Nonce)
+	if err != nil {
+		return nil, s.ssoFailed(tenant.Code, loginReq, "", err)
+	}
+	// 角色等声明可能只在用户信息端点返回
+	if info, err := client.UserInfo(ctx, token.AccessToken); err != nil {
+		log.Warn("获取身份提供方用户信息失败", zap.String("tenant_code", tenant.Code), zap.Error(err))
+	} else {
+		claims.Merge(info)
+	}
+
+	ctx = tenantCtx.WithTenantCode(ctx, tenant.Code)
+	admin, err := s.ssoAdmin(ctx, tenant, claims, loginReq)
+	if err != nil {
+		return nil, s.ssoFailed(tenant.Code, loginReq, claims.String("sub"), err)
+	}
+
+	loginReq.Phone = admin.Phone
+	if loginReq.Phone == "" {
+		loginReq.Phone = admin.Email
+	}
+	if admin.Status != 1 {
+		security.RecordEvent(tenant.Code, s.loginEvent(loginReq, security.EventLoginFailed, admin.ID, ErrUserDisabled.Error()))
+		return nil, ErrUserDisabled
+	}
+
+	// 身份提供方已完成多因素认证时不再要求本系统的两步验证
+	mfa := claims.MFA()
+	if (admin.TwoFactor.Enabled || tenant.Require2FA) && !mfa {
+		return s.startTwoFactor(ctx, loginReq, admin, tenant.ID, tenant.Code)
+	}
+	return s.completeLogin(ctx, loginReq, admin, tenant.ID, tenant.Code, mfa)
+}
+
+// ssoTenant 获取启用了单点登录的租户
+func (s *AuthService) ssoTenant(ctx context.Context, tenantCode string) (*models.Tenant, error) {
+	tenant, err := s.tenantRepo.GetByCode(ctx, tenantCode)
+	if err != nil {
+		return nil, fmt.Errorf("查询租户失败: %w", err)
+	}
+	if tenant == nil || tenant.Status != 1 {
+		return nil, fmt.Errorf("租户不存在或已禁用")
+	}
+	if !tenant.SSO.Enabled {
+		return nil, ErrSSONotEnabled
+	}
+	return tenant, nil
+}
+
+// ssoAdmin 按 issuer+sub 查找已关联的管理员；首次登录时按已验证的邮箱/手机号关联已有账号，或按配置自动创建
+func (s *AuthService) ssoAdmin(ctx context.Context, tenant *models.Tenant, claims oidc.Claims, req dto.LoginRequest) (*models.Admin, error) {
+	cfg := &tenant.SSO
+	issuer, subject := claims.String("iss"), claims.String("sub")
+	email, emailVerified := ssoClaim(claims, cfg.ClaimMapping.Email, "email", "email_verified")
+	phone, phoneVerified := ssoClaim(claims, cfg.ClaimMapping.Phone, "phone_number", "phone_number_verified")
+	roles, managed := ssoRoles(cfg, claims.Strings(orDefault(cfg.ClaimMapping.Roles, "groups")))
+
+	admin, err := s.repo.FindOne(ctx, bson.M{"sso.issuer": issuer, "sso.subject": subject, "is_deleted": 0})
+	if err != nil {
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	if admin == nil {
+		if emailVerified && email != "" {
+			admin, err = s.repo.GetByEmail(ctx, email)
+		}
+		if err == nil && admin == nil && phoneVerified && phone != "" {
+			admin, err = s.repo.GetByPhone(ctx, phone)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查询用户失败: %w", err)
+		}
+
+		link := &models.AdminSSO{Issuer: issuer, Subject: subject, LinkedAt: time.Now().Unix()}
+		if admin != nil {
+			if admin.SSO != nil {
+				return nil, ErrSSOConflict
+			}
+			if err := s.repo.Update(ctx, admin.ID, bson.M{"sso": link}); err != nil {
+				return nil, fmt.Errorf("关联企业身份失败: %w", err)
+			}
+			admin.SSO = link
+			security.RecordEvent(tenant.Code, s.ssoEvent(req, security.EventSSOLinked, admin.ID, subject))
+		} else {
+			if !cfg.AutoProvision {
+				return nil, ErrSSONoAccount
+			}
+			if len(roles) == 0 {
+				return nil, ErrSSONoRole
+			}
+			nickname := claims.String(orDefault(cfg.ClaimMapping.Nickname, "name"))
+			if nickname == "" {
+				nickname = email
+			}
+			now := time.Now().Unix()
+			admin = &models.Admin{
+				Phone:     phone,
+				Nickname:  nickname,
+				Email:     email,
+				Roles:     roles,
+				Status:    1,
+				IsDeleted: 0,
+				SSO:       link,
+				CreatedBy: "sso",
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if err := s.repo.Create(ctx, admin); err != nil {
+				return nil, fmt.Errorf("创建管理员失败: %w", err)
+			}
+			security.RecordEvent(tenant.Code, s.ssoEvent(req, security.EventSSOProvisioned, admin.ID, subject))
+			return admin, nil
+		}
+	}
+
+	// 配置了角色映射时由身份提供方管理角色，每次登录同步
+	if managed {
+		if len(roles) == 0 {
+			return nil, ErrSSONoRole
+		}
+		if !sameRoles(admin.Roles, roles) {
+			if err := s.repo.Update(ctx, admin.ID, bson.M{"role": roles, "updated_at": time.Now().Unix()}); err != nil {
+				return nil, fmt.Errorf("同步角色失败: %w", err)
+			}
+			admin.Roles = roles
+		}
+	}
+	return admin, nil
+}
+
+// ssoFailed 记录单点登录失败事件并返回原错误
+func (s *AuthService) ssoFailed(tenantCode string, req dto.LoginRequest, subject string, err error) error {
+	log.Warn("单点登录失败", zap.String("tenant_code", tenantCode), zap.String("subject", subject), zap.Error(err))
+	security.RecordEvent(tenantCode, s.ssoEvent(req, security.EventSSOFailed, "", err.Error()))
+	return err
+}
+
+func (s *AuthService) ssoEvent(req dto.LoginRequest, eventType, userID, detail string) security.Event {
+	e := s.loginEvent(req, eventType, userID, detail)
+	e.Path = "/auth/sso/callback"
+	return e
+}
+
+// ssoConfig 租户配置转换为 OIDC 客户端配置
+func ssoConfig(cfg *models.TenantSSO) oidc.Config {
+	return oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+	}
+}
+
+// ssoClaim 读取映射后的声明；使用标准声明时以 *_verified 判断是否可用于关联已有账号，自定义映射视为可信
+func ssoClaim(claims oidc.Claims, mapped, standard, verifiedClaim string) (string, bool) {
+	if mapped != "" {
+		return claims.String(mapped), true
+	}
+	return claims.String(standard), claims.Bool(verifiedClaim)
+}
+
+// ssoRoles 按映射计算角色，未命中时使用默认角色；managed 表示角色由身份提供方管理
+func ssoRoles(cfg *models.TenantSSO, values []string) (roles []string, managed bool) {
+	if len(cfg.RoleMappings) == 0 {
+		return cfg.DefaultRoles, false
+	}
+	seen := make(map[string]bool)
+	for _, m := range cfg.RoleMappings {
+		for _, v := range values {
+			if v != m.Value {
+				continue
+			}
+			for _, role := range m.Roles {
+				if !seen[role] {
+					seen[role] = true
+					roles = append(roles, role)
+				}
+			}
+		}
+	}
+	if len(roles) == 0 {
+		roles = cfg.DefaultRoles
+	}
+	return roles, true
+}
+
+func sameRoles(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, r := range a {
+		set[r] = true
+	}
+	for _, r := range b {
+		if !set[r] {
+			return false
+		}
+	}
+	return true
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mule-cloud/app/auth/dto"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/password"
+	"mule-cloud/core/security"
+	"mule-cloud/core/totp"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.uber.org/zap"
+)
+
+// startTwoFactor 密码校验通过后创建两步验证挑战
+// 租户强制两步验证但用户尚未绑定时，同时生成密钥，用户在第二步提交验证码即完成绑定
+func (s *AuthService) startTwoFactor(ctx context.Context, req dto.LoginRequest, admin *models.Admin, tenantID, tenantCode string) (*dto.LoginResponse, error) {
+	challenge := &security.Challenge{
+		UserID:     admin.ID,
+		Phone:      admin.Phone,
+		TenantID:   tenantID,
+		TenantCode: tenantCode,
+		Device:     req.Device,
+		UserAgent:  req.UserAgent,
+		IP:         req.IP,
+	}
+
+	var setup *dto.TwoFactorSetupResponse
+	if !admin.TwoFactor.Enabled {
+		secret, err := totp.GenerateSecret()
+		if err != nil {
+			return nil, err
+		}
+		challenge.PendingSecret = secret
+		setup = s.setupResponse(admin.Phone, secret)
+	}
+
+	token, err := security.CreateChallenge(ctx, challenge, s.challengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.LoginResponse{
+		UserID:             admin.ID,
+		TenantID:           tenantID,
+		Phone:              admin.Phone,
+		Nickname:           admin.Nickname,
+		Avatar:             admin.Avatar,
+		TwoFactorRequired:  true,
+		ChallengeToken:     token,
+		ChallengeExpiresAt: challenge.ExpiresAt,
+		TwoFactorSetup:     setup,
+	}, nil
+}
+
+// LoginTwoFactor 登录第二步：校验验证码（或恢复码）后签发令牌
+func (s *AuthService) LoginTwoFactor(req dto.TwoFactorLoginRequest) (*dto.LoginResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	challenge, err := security.GetChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	userCtx := adminCtx(ctx, challenge.TenantCode)
+	admin, err := s.repo.Get(userCtx, challenge.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	if admin == nil {
+		return nil, ErrUserNotFound
+	}
+	if admin.Status != 1 {
+		return nil, ErrUserDisabled
+	}
+
+	loginReq := dto.LoginRequest{
+		Phone:     challenge.Phone,
+		Device:    challenge.Device,
+		IP:        req.IP,
+		UserAgent: req.UserAgent,
+	}
+
+	var cond, update bson.M
+	var recoveryCodes []string
+	var usedRecovery bool
+	now := time.Now()
+	if challenge.PendingSecret != "" {
+		// 首次绑定：验证码正确即启用两步验证
+		if step, ok := totp.Validate(challenge.PendingSecret, req.Code, now, 0); ok {
+			var hashes []string
+			recoveryCodes, hashes, err = totp.GenerateRecoveryCodes()
+			if err != nil {
+				return nil, err
+			}
+			// 并发登录时只有一个请求能完成绑定
+			cond = bson.M{"two_factor.enabled": bson.M{"$ne": true}}
+			update = bson.M{"$set": bson.M{"two_factor": models.TwoFactor{
+				Enabled:       true,
+				Secret:        challenge.PendingSecret,
+				RecoveryCodes: hashes,
+				LastStep:      step,
+				EnabledAt:     now.Unix(),
+			}}}
+		}
+	} else {
+		cond, update, usedRecovery = verifySecondFactor(admin.TwoFactor, req.Code, now)
+	}
+
+	if update == nil {
+		return nil, s.twoFactorFailed(ctx, req, challenge)
+	}
+
+	// 挑战只能使用一次，并发提交时只有一个请求能完成登录
+	if ok, err := security.ConsumeChallenge(ctx, req.ChallengeToken); err != nil || !ok {
+		return nil, security.ErrChallengeInvalid
+	}
+	// 条件更新：同一个验证码（时间步）或恢复码在并发的登录中只能用一次
+	setUpdatedAt(update, now.Unix())
+	if err := s.repo.UpdateIf(userCtx, admin.ID, cond, update); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, ErrTwoFactorInvalid
+		}
+		return nil, fmt.Errorf("更新两步验证状态失败: %w", err)
+	}
+
+	switch {
+	case recoveryCodes != nil:
+		security.RecordEvent(challenge.TenantCode, s.loginEvent(loginReq, security.EventTwoFactorEnabled, admin.ID, "登录时绑定"))
+	case usedRecovery:
+		security.RecordEvent(challenge.TenantCode, s.loginEvent(loginReq, security.EventRecoveryCodeUsed, admin.ID,
+			fmt.Sprintf("剩余%d个恢复码", len(admin.TwoFactor.RecoveryCodes)-1)))
+	}
+
+	resp, err := s.completeLogin(userCtx, loginReq, admin, challenge.TenantID, challenge.TenantCode, true)
+	if err != nil {
+		return nil, err
+	}
+	resp.RecoveryCodes = recoveryCodes
+	return resp, nil
+}
+
+// twoFactorFailed 记录验证码错误：挑战错误次数加一，并计入登录失败次数
+func (s *AuthService) twoFactorFailed(ctx context.Context, req dto.TwoFactorLoginRequest, challenge *security.Challenge) error {
+	loginReq := dto.LoginRequest{Phone: challenge.Phone, IP: req.IP, UserAgent: req.UserAgent}
+	security.RecordEvent(challenge.TenantCode, s.loginEvent(loginReq, security.EventTwoFactorFailed, challenge.UserID, ErrTwoFactorInvalid.Error()))
+	if _, err := s.guard.Fail(ctx, challenge.TenantCode, challenge.Phone, req.IP); err != nil {
+		log.Ctx(ctx).Warn("记录登录失败次数失败", zap.String("phone", challenge.Phone), zap.Error(err))
+	}
+
+	remaining, err := security.ChallengeFailed(ctx, req.ChallengeToken, challenge)
+	if err != nil {
+		return err
+	}
+	if remaining == 0 {
+		return security.ErrChallengeInvalid
+	}
+	return fmt.Errorf("%w，还可尝试%d次", ErrTwoFactorInvalid, remaining)
+}
+
+// GetTwoFactorStatus 获取当前用户的两步验证状态
+func (s *AuthService) GetTwoFactorStatus(ctx context.Context, tenantCode, userID string) (*dto.TwoFactorStatusResponse, error) {
+	admin, err := s.getSelf(ctx, tenantCode, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TwoFactorStatusResponse{
+		Enabled:                admin.TwoFactor.Enabled,
+		Required:               s.tenantRequires2FA(ctx, tenantCode),
+		EnabledAt:              admin.TwoFactor.EnabledAt,
+		RecoveryCodesRemaining: len(admin.TwoFactor.RecoveryCodes),
+	}, nil
+}
+
+// SetupTwoFactor 生成新的密钥（需调用 EnableTwoFactor 提交验证码后才生效）
+func (s *AuthService) SetupTwoFactor(ctx context.Context, tenantCode, userID string) (*dto.TwoFactorSetupResponse, error) {
+	admin, err := s.getSelf(ctx, tenantCode, userID)
+	if err != nil {
+		return nil, err
+	}
+	if admin.TwoFactor.Enabled {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(adminCtx(ctx, tenantCode), admin.ID, bson.M{"two_factor.pending_secret": secret}); err != nil {
+		return nil, fmt.Errorf("保存两步验证密钥失败: %w", err)
+	}
+	return s.setupResponse(admin.Phone, secret), nil
+}
+
+// EnableTwoFactor 提交验证器App中的验证码确认绑定，返回恢复码
+func (s *AuthService) EnableTwoFactor(ctx context.Context, tenantCode, userID string, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	admin, err := s.getSelf(ctx, tenantCode, userID)
+	if err != nil {
+		return nil, err
+	}
+	if admin.TwoFactor.Enabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	if admin.TwoFactor.PendingSecret == "" {
+		return nil, ErrTwoFactorNotSetup
+	}
+
+	now := time.Now()
+	step, ok := totp.Validate(admin.TwoFactor.PendingSecret, req.Code, now, 0)
+	if !ok {
+		return nil, ErrTwoFactorInvalid
+	}
+	codes, hashes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	update := bson.M{
+		"two_factor": models.TwoFactor{
+			Enabled:       true,
+			Secret:        admin.TwoFactor.PendingSecret,
+			RecoveryCodes: hashes,
+			LastStep:      step,
+			EnabledAt:     now.Unix(),
+		},
+		"updated_at": now.Unix(),
+	}
+	if err := s.repo.Update(adminCtx(ctx, tenantCode), admin.ID, update); err != nil {
+		return nil, fmt.Errorf("启用两步验证失败: %w", err)
+	}
+
+	s.recordTwoFactorEvent(tenantCode, admin, security.EventTwoFactorEnabled, "")
+	return &dto.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+		Message:       "两步验证已启用，请妥善保存恢复码，每个恢复码只能使用一次",
+	}, nil
+}
+
+// DisableTwoFactor 关闭两步验证（需要密码和验证码，租户强制时不能关闭）
+func (s *AuthService) DisableTwoFactor(ctx context.Context, tenantCode, userID string, req dto.TwoFactorDisableRequest) error {
+	admin, err := s.getSelf(ctx, tenantCode, userID)
+	if err != nil {
+		return err
+	}
+	if !admin.TwoFactor.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if s.tenantRequires2FA(ctx, tenantCode) {
+		return ErrTwoFactorRequired
+	}
+	if ok, _ := password.Verify(req.Password, admin.Password); !ok {
+		return ErrInvalidPassword
+	}
+	if _, update, _ := verifySecondFactor(admin.TwoFactor, req.Code, time.Now()); update == nil {
+		return ErrTwoFactorInvalid
+	}
+
+	update := bson.M{"two_factor": models.TwoFactor{}, "updated_at": time.Now().Unix()}
+	if err := s.repo.Update(adminCtx(ctx, tenantCode), admin.ID, update); err != nil {
+		return fmt.Errorf("关闭两步验证失败: %w", err)
+	}
+	s.recordTwoFactorEvent(tenantCode, admin, security.EventTwoFactorDisabled, "")
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码（原有恢复码全部失效），需要验证器App中的验证码
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, tenantCode, userID string, req dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	admin, err := s.getSelf(ctx, tenantCode, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin.TwoFactor.Enabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	step, ok := totp.Validate(admin.TwoFactor.Secret, req.Code, time.Now(), admin.TwoFactor.LastStep)
+	if !ok {
+		return nil, ErrTwoFactorInvalid
+	}
+
+	codes, hashes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	update := bson.M{
+		"two_factor.recovery_codes": hashes,
+		"two_factor.last_step":      step,
+		"updated_at":                time.Now().Unix(),
+	}
+	if err := s.repo.Update(adminCtx(ctx, tenantCode), admin.ID, update); err != nil {
+		return nil, fmt.Errorf("生成恢复码失败: %w", err)
+	}
+	return &dto.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+		Message:       "已生成新的恢复码，原有恢复码已失效",
+	}, nil
+}
+
+// verifySecondFactor 校验验证码或恢复码，成功时返回更新条件和更新内容
+//
+// 验证码要求库中的时间步仍小于本次时间步，恢复码要求仍未被使用（$pull 移除），
+// 与读取用户后的并发登录冲突时更新不到记录，验证失败
+func verifySecondFactor(tf models.TwoFactor, code string, now time.Time) (bson.M, bson.M, bool) {
+	if step, ok := totp.Validate(tf.Secret, code, now, tf.LastStep); ok {
+		cond := bson.M{"$or": bson.A{
+			bson.M{"two_factor.last_step": bson.M{"$lt": step}},
+			bson.M{"two_factor.last_step": bson.M{"$exists": false}},
+		}}
+		return cond, bson.M{"$set": bson.M{"two_factor.last_step": step}}, false
+	}
+	if i := totp.MatchRecoveryCode(code, tf.RecoveryCodes); i >= 0 {
+		hash := tf.RecoveryCodes[i]
+		cond := bson.M{"two_factor.recovery_codes": hash}
+		return cond, bson.M{"$pull": bson.M{"two_factor.recovery_codes": hash}}, true
+	}
+	return nil, nil, false
+}
+
+// setUpdatedAt 在更新文档的 $set 中加入更新时间
+func setUpdatedAt(update bson.M, now int64) {
+	set, ok := update["$set"].(bson.M)
+	if !ok {
+		set = bson.M{}
+		update["$set"] = set
+	}
+	set["updated_at"] = now
+}
+
+// getSelf 按令牌中的原始租户查询当前用户（不受超管切换租户影响）
+func (s *AuthService) getSelf(ctx context.Context, tenantCode, userID string) (*models.Admin, error) {
+	admin, err := s.repo.Get(adminCtx(ctx, tenantCode), userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	if admin == nil {
+		return nil, ErrUserNotFound
+	}
+	return admin, nil
+}
+
+// tenantRequires2FA 租户是否强制两步验证（系统库用户不受租户设置约束）
+func (s *AuthService) tenantRequires2FA(ctx context.Context, tenantCode string) bool {
+	if tenantCode == "" || tenantCode == "system" {
+		return false
+	}
+	tenant, err := s.tenantRepo.GetByCode(tenantCtx.WithTenantCode(ctx, ""), tenantCode)
+	if err != nil || tenant == nil {
+		return false
+	}
+	return tenant.Require2FA
+}
+
+func (s *AuthService) setupResponse(account, secret string) *dto.TwoFactorSetupResponse {
+	return &dto.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, account, secret),
+	}
+}
+
+func (s *AuthService) recordTwoFactorEvent(tenantCode string, admin *models.Admin, eventType, detail string) {
+	security.RecordEvent(tenantCode, security.Event{
+		Type:     eventType,
+		UserID:   admin.ID,
+		Username: admin.Phone,
+		Path:     "/auth/2fa",
+		Detail:   detail,
+	})
+}
+
+// adminCtx 用户所在库的 context：系统库用户的租户代码为 system，查询时使用空字符串
+func adminCtx(ctx context.Context, tenantCode string) context.Context {
+	if tenantCode == "system" {
+		tenantCode = ""
+	}
+	return tenantCtx.WithTenantCode(ctx, tenantCode)
+}
@@ -1,11 +1,16 @@
 package transport
 
 import (
+	"errors"
+	"math"
 	"mule-cloud/app/auth/dto"
 	"mule-cloud/app/auth/endpoint"
 	"mule-cloud/app/auth/services"
 	"mule-cloud/core/binding"
 	"mule-cloud/core/response"
+	"mule-cloud/core/security"
+	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,11 +24,21 @@ func LoginHandler(svc services.IAuthService) gin.HandlerFunc {
 			return
 		}
 		req.IP = c.ClientIP()
+		req.UserAgent = c.GetHeader("User-Agent")
 
 		ep := endpoint.MakeLoginEndpoint(svc)
 		resp, err := ep(c.Request.Context(), req)
 		if err != nil {
-			response.Error(c, err.Error())
+			var blocked *security.BlockedError
+			switch {
+			case errors.As(err, &blocked):
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+				response.ErrorWithCode(c, response.CodeTooManyRequests, err.Error())
+			case errors.Is(err, security.ErrCaptchaRequired), errors.Is(err, security.ErrCaptchaInvalid):
+				response.ErrorWithCode(c, response.CodeCaptchaRequired, err.Error())
+			default:
+				response.Error(c, err.Error())
+			}
 			return
 		}
 
@@ -59,6 +74,7 @@ func RefreshTokenHandler(svc services.IAuthService) gin.HandlerFunc {
 			response.Error(c, "参数错误: "+err.Error())
 			return
 		}
+		req.IP = c.ClientIP()
 
 		ep := endpoint.MakeRefreshTokenEndpoint(svc)
 		resp, err := ep(c.Request.Context(), req)
@@ -193,3 +209,336 @@ func GetTenantListHandler(svc services.IAuthService) gin.HandlerFunc {
 		response.Success(c, resp)
 	}
 }
+
+// LogoutHandler 退出登录处理器（注销当前会话）
+func LogoutHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ep := endpoint.MakeLogoutEndpoint(svc)
+		resp, err := ep(c.Request.Context(), endpoint.LogoutRequest{
+			SessionID: c.GetString("session_id"),
+			JTI:       c.GetString("jti"),
+		})
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ListSessionsHandler 获取当前用户的登录会话
+func ListSessionsHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ep := endpoint.MakeListSessionsEndpoint(svc)
+		resp, err := ep(c.Request.Context(), endpoint.SessionRequest{
+			TenantCode:       c.GetString("tenant_code"),
+			UserID:           c.GetString("user_id"),
+			CurrentSessionID: c.GetString("session_id"),
+		})
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// RevokeSessionHandler 注销当前用户的某个会话（如在其他设备上的登录）
+func RevokeSessionHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ep := endpoint.MakeRevokeSessionEndpoint(svc)
+		resp, err := ep(c.Request.Context(), endpoint.SessionRequest{
+			TenantCode: c.GetString("tenant_code"),
+			UserID:     c.GetString("user_id"),
+			SessionID:  c.Param("id"),
+		})
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetCaptchaHandler 获取登录图形验证码
+func GetCaptchaHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ep := endpoint.MakeGetCaptchaEndpoint(svc)
+		resp, err := ep(c.Request.Context(), nil)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// LoginTwoFactorHandler 登录第二步：提交两步验证码
+func LoginTwoFactorHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.TwoFactorLoginRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+		req.IP = c.ClientIP()
+		req.UserAgent = c.GetHeader("User-Agent")
+
+		ep := endpoint.MakeLoginTwoFactorEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// StartSSOHandler 发起单点登录，返回身份提供方授权地址
+func StartSSOHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.SSOStartRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.MakeStartSSOEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// LoginSSOHandler 单点登录回调（前端回调页提交 state 和 code）
+func LoginSSOHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.SSOCallbackRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+		req.IP = c.ClientIP()
+		req.UserAgent = c.GetHeader("User-Agent")
+
+		ep := endpoint.MakeLoginSSOEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetTwoFactorStatusHandler 获取当前用户的两步验证状态
+func GetTwoFactorStatusHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ep := endpoint.MakeGetTwoFactorStatusEndpoint(svc)
+		resp, err := ep(c.Request.Context(), twoFactorRequest(c))
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// SetupTwoFactorHandler 生成两步验证密钥和绑定地址
+func SetupTwoFactorHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ep := endpoint.MakeSetupTwoFactorEndpoint(svc)
+		resp, err := ep(c.Request.Context(), twoFactorRequest(c))
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// EnableTwoFactorHandler 提交验证码确认绑定
+func EnableTwoFactorHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.TwoFactorCodeRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		r := twoFactorRequest(c)
+		r.Code = req.Code
+		ep := endpoint.MakeEnableTwoFactorEndpoint(svc)
+		resp, err := ep(c.Request.Context(), r)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// DisableTwoFactorHandler 关闭两步验证
+func DisableTwoFactorHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.TwoFactorDisableRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		r := twoFactorRequest(c)
+		r.Code = req.Code
+		r.Password = req.Password
+		ep := endpoint.MakeDisableTwoFactorEndpoint(svc)
+		resp, err := ep(c.Request.Context(), r)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// RegenerateRecoveryCodesHandler 重新生成恢复码
+func RegenerateRecoveryCodesHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.TwoFactorCodeRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		r := twoFactorRequest(c)
+		r.Code = req.Code
+		ep := endpoint.MakeRegenerateRecoveryCodesEndpoint(svc)
+		resp, err := ep(c.Request.Context(), r)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// StartImpersonationHandler 系统超管申请代管租户（返回限时的代管令牌）
+func StartImpersonationHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.StartImpersonationRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+		req.IP = c.ClientIP()
+		req.UserAgent = c.GetHeader("User-Agent")
+
+		r := impersonationRequest(c)
+		r.Start = req
+		ep := endpoint.MakeStartImpersonationEndpoint(svc)
+		resp, err := ep(c.Request.Context(), r)
+		if err != nil {
+			impersonationError(c, err)
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// EndImpersonationHandler 提前结束代管（吊销代管令牌）
+func EndImpersonationHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := impersonationRequest(c)
+		r.ID = c.Param("id")
+		ep := endpoint.MakeEndImpersonationEndpoint(svc)
+		resp, err := ep(c.Request.Context(), r)
+		if err != nil {
+			impersonationError(c, err)
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ListImpersonationsHandler 代管记录（租户管理员查看本租户被谁代管过）
+func ListImpersonationsHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ImpersonationListRequest
+		if err := c.ShouldBindQuery(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		r := impersonationRequest(c)
+		r.List = req
+		ep := endpoint.MakeListImpersonationsEndpoint(svc)
+		resp, err := ep(c.Request.Context(), r)
+		if err != nil {
+			impersonationError(c, err)
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// impersonationRequest 调用者身份（租户代码取令牌中的原始值）
+func impersonationRequest(c *gin.Context) endpoint.ImpersonationRequest {
+	return endpoint.ImpersonationRequest{
+		TenantCode:             c.GetString("tenant_code"),
+		UserID:                 c.GetString("user_id"),
+		MFA:                    c.GetBool("mfa"),
+		CurrentImpersonationID: c.GetString("impersonation_id"),
+	}
+}
+
+func impersonationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrImpersonationForbidden), errors.Is(err, services.ErrImpersonationMFA),
+		errors.Is(err, services.ErrImpersonationListDeny):
+		response.ErrorWithCode(c, response.CodeForbidden, err.Error())
+	case errors.Is(err, services.ErrImpersonationNotFound):
+		response.ErrorWithCode(c, response.CodeNotFound, err.Error())
+	default:
+		response.Error(c, err.Error())
+	}
+}
+
+// twoFactorRequest 当前用户身份（租户代码取令牌中的原始值，不受超管切换租户影响）
+func twoFactorRequest(c *gin.Context) endpoint.TwoFactorRequest {
+	return endpoint.TwoFactorRequest{
+		TenantCode: c.GetString("tenant_code"),
+		UserID:     c.GetString("user_id"),
+	}
+}
+
+// JWKSHandler 发布令牌签名公钥（JWKS 标准格式，不使用统一响应包装）
+func JWKSHandler(svc services.IAuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ep := endpoint.MakeGetJWKSEndpoint(svc)
+		resp, err := ep(c.Request.Context(), nil)
+		if err != nil {
+			response.ErrorWithCode(c, response.CodeNotFound, err.Error())
+			return
+		}
+
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, resp)
+	}
+}
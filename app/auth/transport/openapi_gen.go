@@ -0,0 +1,38 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package transport
+
+import (
+	"mule-cloud/app/auth/dto"
+	"mule-cloud/core/captcha"
+	"mule-cloud/core/jwt"
+	"mule-cloud/core/openapi"
+)
+
+// Operations 处理器的接口描述（cmd/openapi-gen 生成，服务的 /openapi.json 使用）
+var Operations = openapi.Operations{
+	"mule-cloud/app/auth/transport.LoginHandler":                   {Summary: "登录", Tag: "auth", Request: dto.LoginRequest{}, Response: dto.LoginResponse{}},
+	"mule-cloud/app/auth/transport.RegisterHandler":                {Summary: "注册", Tag: "auth", Request: dto.RegisterRequest{}, Response: dto.RegisterResponse{}},
+	"mule-cloud/app/auth/transport.RefreshTokenHandler":            {Summary: "刷新Token", Tag: "auth", Request: dto.RefreshTokenRequest{}, Response: dto.RefreshTokenResponse{}},
+	"mule-cloud/app/auth/transport.GetProfileHandler":              {Summary: "获取个人信息", Tag: "auth", Response: dto.GetProfileResponse{}},
+	"mule-cloud/app/auth/transport.UpdateProfileHandler":           {Summary: "更新个人信息", Tag: "auth", Request: dto.UpdateProfileRequest{}, Response: dto.UpdateProfileResponse{}},
+	"mule-cloud/app/auth/transport.ChangePasswordHandler":          {Summary: "修改密码", Tag: "auth", Request: dto.ChangePasswordRequest{}, Response: dto.ChangePasswordResponse{}},
+	"mule-cloud/app/auth/transport.GetUserRoutesHandler":           {Summary: "获取用户路由", Tag: "auth", Response: dto.GetUserRoutesResponse{}},
+	"mule-cloud/app/auth/transport.GetTenantListHandler":           {Summary: "获取租户列表（用于登录页面选择租户）", Tag: "auth", Response: dto.GetTenantListResponse{}},
+	"mule-cloud/app/auth/transport.LogoutHandler":                  {Summary: "退出登录（注销当前会话）", Tag: "auth", Response: map[string]string{}},
+	"mule-cloud/app/auth/transport.ListSessionsHandler":            {Summary: "获取当前用户的登录会话", Tag: "auth", Response: dto.SessionListResponse{}},
+	"mule-cloud/app/auth/transport.RevokeSessionHandler":           {Summary: "注销当前用户的某个会话（如在其他设备上的登录）", Tag: "auth", Response: map[string]string{}},
+	"mule-cloud/app/auth/transport.GetCaptchaHandler":              {Summary: "获取登录图形验证码", Tag: "auth", Response: captcha.Challenge{}},
+	"mule-cloud/app/auth/transport.LoginTwoFactorHandler":          {Summary: "登录第二步：提交两步验证码", Tag: "auth", Request: dto.TwoFactorLoginRequest{}, Response: dto.LoginResponse{}},
+	"mule-cloud/app/auth/transport.StartSSOHandler":                {Summary: "发起单点登录，返回身份提供方授权地址", Tag: "auth", Request: dto.SSOStartRequest{}, Response: dto.SSOStartResponse{}},
+	"mule-cloud/app/auth/transport.LoginSSOHandler":                {Summary: "单点登录回调（前端回调页提交 state 和 code）", Tag: "auth", Request: dto.SSOCallbackRequest{}, Response: dto.LoginResponse{}},
+	"mule-cloud/app/auth/transport.GetTwoFactorStatusHandler":      {Summary: "获取当前用户的两步验证状态", Tag: "auth", Response: dto.TwoFactorStatusResponse{}},
+	"mule-cloud/app/auth/transport.SetupTwoFactorHandler":          {Summary: "生成两步验证密钥和绑定地址", Tag: "auth", Response: dto.TwoFactorSetupResponse{}},
+	"mule-cloud/app/auth/transport.EnableTwoFactorHandler":         {Summary: "提交验证码确认绑定", Tag: "auth", Request: dto.TwoFactorCodeRequest{}, Response: dto.RecoveryCodesResponse{}},
+	"mule-cloud/app/auth/transport.DisableTwoFactorHandler":        {Summary: "关闭两步验证", Tag: "auth", Request: dto.TwoFactorDisableRequest{}, Response: map[string]string{}},
+	"mule-cloud/app/auth/transport.RegenerateRecoveryCodesHandler": {Summary: "重新生成恢复码", Tag: "auth", Request: dto.TwoFactorCodeRequest{}, Response: dto.RecoveryCodesResponse{}},
+	"mule-cloud/app/auth/transport.StartImpersonationHandler":      {Summary: "系统超管申请代管租户（返回限时的代管令牌）", Tag: "auth", Request: dto.StartImpersonationRequest{}, Response: dto.ImpersonationTokenResponse{}},
+	"mule-cloud/app/auth/transport.EndImpersonationHandler":        {Summary: "提前结束代管（吊销代管令牌）", Tag: "auth", Response: map[string]string{}},
+	"mule-cloud/app/auth/transport.ListImpersonationsHandler":      {Summary: "代管记录（租户管理员查看本租户被谁代管过）", Tag: "auth", Request: dto.ImpersonationListRequest{}, Bind: openapi.FromQuery, Response: dto.ImpersonationListResponse{}},
+	"mule-cloud/app/auth/transport.JWKSHandler":                    {Summary: "发布令牌签名公钥（JWKS 标准格式，不使用统一响应包装）", Tag: "auth", Response: jwt.JWKS{}, Raw: true},
+}
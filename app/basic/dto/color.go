@@ -4,31 +4,42 @@ import "mule-cloud/internal/models"
 
 // ColorRequest 颜色请求
 type ColorListRequest struct {
-	ID    string `uri:"id" form:"id"`
-	Value string `form:"value"`
+	ID     string `uri:"id" form:"id"`
+	Value  string `form:"value"`
+	Code   string `form:"code"`
+	Status *int   `form:"status"`
 
 	Page     int64 `form:"page"`
 	PageSize int64 `form:"page_size"`
 }
 
 type ColorCreateRequest struct {
-	Value  string `json:"value"`
-	Remark string `json:"remark"`
+	Code      string `json:"code"`
+	Value     string `json:"value" binding:"required"`
+	Remark    string `json:"remark"`
+	Status    *int   `json:"status"`
+	Hex       string `json:"hex"`        // 色值，如 #1E90FF
+	PantoneNo string `json:"pantone_no"` // 潘通色号
 }
 
+// ColorUpdateRequest 更新颜色请求（扩展字段未传时保持不变，兼容旧接口只传 value/remark）
 type ColorUpdateRequest struct {
-	ID     string `uri:"id"`
-	Value  string `json:"value"`
-	Remark string `json:"remark"`
+	ID        string  `uri:"id"`
+	Code      *string `json:"code"`
+	Value     string  `json:"value" binding:"required"`
+	Remark    string  `json:"remark"`
+	Status    *int    `json:"status"`
+	Hex       *string `json:"hex"`
+	PantoneNo *string `json:"pantone_no"`
 }
 
 // ColorResponse 颜色响应
 type ColorResponse struct {
-	Color *models.Basic `json:"color"`
+	Color *models.Color `json:"color"`
 }
 
 // ColorListResponse 颜色列表响应
 type ColorListResponse struct {
-	Colors []models.Basic `json:"colors"`
-	Total  int64          `json:"total"`
+	Colors []*models.Color `json:"colors"`
+	Total  int64           `json:"total"`
 }
@@ -4,31 +4,54 @@ import "mule-cloud/internal/models"
 
 // CustomerRequest 客户请求
 type CustomerListRequest struct {
-	ID    string `uri:"id" form:"id"`
-	Value string `form:"value"`
+	ID     string `uri:"id" form:"id"`
+	Value  string `form:"value"`
+	Code   string `form:"code"`
+	Status *int   `form:"status"`
 
 	Page     int64 `form:"page"`
 	PageSize int64 `form:"page_size"`
 }
 
 type CustomerCreateRequest struct {
-	Value  string `json:"value"`
-	Remark string `json:"remark"`
+	Code        string                 `json:"code"`
+	Value       string                 `json:"value" binding:"required"`
+	Remark      string                 `json:"remark"`
+	Status      *int                   `json:"status"`
+	ShortName   string                 `json:"short_name"`
+	Address     string                 `json:"address"`
+	Contacts    []models.Contact       `json:"contacts"`
+	TaxNo       string                 `json:"tax_no"`
+	BankName    string                 `json:"bank_name"`
+	BankAccount string                 `json:"bank_account"`
+	SalesmanID  string                 `json:"salesman_id"`
+	PriceList   []models.PriceListItem `json:"price_list"`
 }
 
+// CustomerUpdateRequest 更新客户请求（扩展字段未传时保持不变，兼容旧接口只传 value/remark）
 type CustomerUpdateRequest struct {
-	ID     string `uri:"id"`
-	Value  string `json:"value"`
-	Remark string `json:"remark"`
+	ID          string                  `uri:"id"`
+	Code        *string                 `json:"code"`
+	Value       string                  `json:"value" binding:"required"`
+	Remark      string                  `json:"remark"`
+	Status      *int                    `json:"status"`
+	ShortName   *string                 `json:"short_name"`
+	Address     *string                 `json:"address"`
+	Contacts    *[]models.Contact       `json:"contacts"`
+	TaxNo       *string                 `json:"tax_no"`
+	BankName    *string                 `json:"bank_name"`
+	BankAccount *string                 `json:"bank_account"`
+	SalesmanID  *string                 `json:"salesman_id"`
+	PriceList   *[]models.PriceListItem `json:"price_list"`
 }
 
 // CustomerResponse 客户响应
 type CustomerResponse struct {
-	Customer *models.Basic `json:"customer"`
+	Customer *models.Customer `json:"customer"`
 }
 
 // CustomerListResponse 客户列表响应
 type CustomerListResponse struct {
-	Customers []models.Basic `json:"customers"`
-	Total     int64          `json:"total"`
+	Customers []*models.Customer `json:"customers"`
+	Total     int64              `json:"total"`
 }
@@ -4,31 +4,40 @@ import "mule-cloud/internal/models"
 
 // OrderTypeRequest 订单类型请求
 type OrderTypeListRequest struct {
-	ID    string `uri:"id" form:"id"`
-	Value string `form:"value"`
+	ID     string `uri:"id" form:"id"`
+	Value  string `form:"value"`
+	Code   string `form:"code"`
+	Status *int   `form:"status"`
 
 	Page     int64 `form:"page"`
 	PageSize int64 `form:"page_size"`
 }
 
 type OrderTypeCreateRequest struct {
-	Value  string `json:"value"`
+	Code   string `json:"code"`
+	Value  string `json:"value" binding:"required"`
 	Remark string `json:"remark"`
+	Status *int   `json:"status"`
+	Prefix string `json:"prefix"` // 合同号前缀
 }
 
+// OrderTypeUpdateRequest 更新订单类型请求（扩展字段未传时保持不变，兼容旧接口只传 value/remark）
 type OrderTypeUpdateRequest struct {
-	ID     string `uri:"id"`
-	Value  string `json:"value"`
-	Remark string `json:"remark"`
+	ID     string  `uri:"id"`
+	Code   *string `json:"code"`
+	Value  string  `json:"value" binding:"required"`
+	Remark string  `json:"remark"`
+	Status *int    `json:"status"`
+	Prefix *string `json:"prefix"`
 }
 
 // OrderTypeResponse 订单类型响应
 type OrderTypeResponse struct {
-	OrderType *models.Basic `json:"order_type"`
+	OrderType *models.OrderType `json:"order_type"`
 }
 
 // OrderTypeListResponse 订单类型列表响应
 type OrderTypeListResponse struct {
-	OrderTypes []models.Basic `json:"order_types"`
-	Total      int64          `json:"total"`
+	OrderTypes []*models.OrderType `json:"order_types"`
+	Total      int64               `json:"total"`
 }
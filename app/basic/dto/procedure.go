@@ -4,31 +4,61 @@ import "mule-cloud/internal/models"
 
 // ProcedureRequest 工序请求
 type ProcedureListRequest struct {
-	ID    string `uri:"id" form:"id"`
-	Value string `form:"value"`
+	ID          string `uri:"id" form:"id"`
+	Value       string `form:"value"`
+	Code        string `form:"code"`
+	Status      *int   `form:"status"`
+	Category    string `form:"category"`
+	MachineType string `form:"machine_type"`
 
 	Page     int64 `form:"page"`
 	PageSize int64 `form:"page_size"`
 }
 
 type ProcedureCreateRequest struct {
-	Value  string `json:"value"`
-	Remark string `json:"remark"`
+	Code        string  `json:"code"`
+	Value       string  `json:"value" binding:"required"`
+	Remark      string  `json:"remark"`
+	Status      *int    `json:"status"`
+	Category    string  `json:"category"`     // 工序分类
+	SAM         float64 `json:"sam"`          // 标准工时（分钟/件）
+	MachineType string  `json:"machine_type"` // 机器类型
+	BaseRate    float64 `json:"base_rate"`    // 工序费率（元/分钟），0 使用租户统一费率
+	UnitPrice   float64 `json:"unit_price"`   // 默认工价（设置了标准工时时自动计算）
 }
 
+// ProcedureUpdateRequest 更新工序请求（扩展字段未传时保持不变，兼容旧接口只传 value/remark）
 type ProcedureUpdateRequest struct {
-	ID     string `uri:"id"`
-	Value  string `json:"value"`
-	Remark string `json:"remark"`
+	ID          string   `uri:"id"`
+	Code        *string  `json:"code"`
+	Value       string   `json:"value" binding:"required"`
+	Remark      string   `json:"remark"`
+	Status      *int     `json:"status"`
+	Category    *string  `json:"category"`
+	SAM         *float64 `json:"sam"`
+	MachineType *string  `json:"machine_type"`
+	BaseRate    *float64 `json:"base_rate"`
+	UnitPrice   *float64 `json:"unit_price"`
 }
 
 // ProcedureResponse 工序响应
 type ProcedureResponse struct {
-	Procedure *models.Basic `json:"procedure"`
+	Procedure *models.Procedure `json:"procedure"`
 }
 
 // ProcedureListResponse 工序列表响应
 type ProcedureListResponse struct {
-	Procedures []models.Basic `json:"procedures"`
-	Total      int64          `json:"total"`
+	Procedures []*models.Procedure `json:"procedures"`
+	Total      int64               `json:"total"`
+}
+
+// PricingUpdateRequest 更新工价设置请求
+type PricingUpdateRequest struct {
+	MinuteRate float64 `json:"minute_rate"` // 统一费率（元/分钟）
+}
+
+// PricingResponse 工价设置响应
+type PricingResponse struct {
+	Pricing  *models.PricingSetting `json:"pricing"`
+	Repriced int                    `json:"repriced"` // 重新计算工价的工序数
 }
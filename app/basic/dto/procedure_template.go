@@ -0,0 +1,47 @@
+package dto
+
+import "mule-cloud/internal/models"
+
+// ProcedureTemplateListRequest 工序模板列表请求
+type ProcedureTemplateListRequest struct {
+	ID       string `uri:"id" form:"id"`
+	Value    string `form:"value"`
+	Code     string `form:"code"`
+	Status   *int   `form:"status"`
+	Category string `form:"category"`
+
+	Page     int64 `form:"page"`
+	PageSize int64 `form:"page_size"`
+}
+
+// ProcedureTemplateCreateRequest 创建工序模板请求
+type ProcedureTemplateCreateRequest struct {
+	Code     string                         `json:"code"`
+	Value    string                         `json:"value" binding:"required"` // 模板名称
+	Remark   string                         `json:"remark"`
+	Status   *int                           `json:"status"`
+	Category string                         `json:"category"` // 适用款式分类
+	Items    []models.ProcedureTemplateItem `json:"items"`    // 工序清单
+}
+
+// ProcedureTemplateUpdateRequest 更新工序模板请求
+type ProcedureTemplateUpdateRequest struct {
+	ID       string                          `uri:"id"`
+	Code     *string                         `json:"code"`
+	Value    string                          `json:"value" binding:"required"`
+	Remark   string                          `json:"remark"`
+	Status   *int                            `json:"status"`
+	Category *string                         `json:"category"`
+	Items    *[]models.ProcedureTemplateItem `json:"items"`
+}
+
+// ProcedureTemplateResponse 工序模板响应
+type ProcedureTemplateResponse struct {
+	ProcedureTemplate *models.ProcedureTemplate `json:"procedure_template"`
+}
+
+// ProcedureTemplateListResponse 工序模板列表响应
+type ProcedureTemplateListResponse struct {
+	ProcedureTemplates []*models.ProcedureTemplate `json:"procedure_templates"`
+	Total              int64                       `json:"total"`
+}
@@ -4,31 +4,46 @@ import "mule-cloud/internal/models"
 
 // SalesmanRequest 业务员请求
 type SalesmanListRequest struct {
-	ID    string `uri:"id" form:"id"`
-	Value string `form:"value"`
+	ID     string `uri:"id" form:"id"`
+	Value  string `form:"value"`
+	Code   string `form:"code"`
+	Status *int   `form:"status"`
 
 	Page     int64 `form:"page"`
 	PageSize int64 `form:"page_size"`
 }
 
 type SalesmanCreateRequest struct {
-	Value  string `json:"value"`
-	Remark string `json:"remark"`
+	Code           string  `json:"code"`
+	Value          string  `json:"value" binding:"required"`
+	Remark         string  `json:"remark"`
+	Status         *int    `json:"status"`
+	Phone          string  `json:"phone"`           // 电话
+	Email          string  `json:"email"`           // 邮箱
+	AdminID        string  `json:"admin_id"`        // 关联的后台账号ID
+	CommissionRate float64 `json:"commission_rate"` // 提成比例（%）
 }
 
+// SalesmanUpdateRequest 更新业务员请求（扩展字段未传时保持不变，兼容旧接口只传 value/remark）
 type SalesmanUpdateRequest struct {
-	ID     string `uri:"id"`
-	Value  string `json:"value"`
-	Remark string `json:"remark"`
+	ID             string   `uri:"id"`
+	Code           *string  `json:"code"`
+	Value          string   `json:"value" binding:"required"`
+	Remark         string   `json:"remark"`
+	Status         *int     `json:"status"`
+	Phone          *string  `json:"phone"`
+	Email          *string  `json:"email"`
+	AdminID        *string  `json:"admin_id"`
+	CommissionRate *float64 `json:"commission_rate"`
 }
 
 // SalesmanResponse 业务员响应
 type SalesmanResponse struct {
-	Salesman *models.Basic `json:"salesman"`
+	Salesman *models.Salesman `json:"salesman"`
 }
 
 // SalesmanListResponse 业务员列表响应
 type SalesmanListResponse struct {
-	Salesmans []models.Basic `json:"salesmans"`
-	Total     int64          `json:"total"`
+	Salesmans []*models.Salesman `json:"salesmans"`
+	Total     int64              `json:"total"`
 }
@@ -4,34 +4,47 @@ import "mule-cloud/internal/models"
 
 // SizeRequest 尺寸请求
 type SizeGetRequest struct {
-	ID string `uri:"id" binding"required"`
+	ID string `uri:"id" binding:"required"`
 }
 
 type SizeListRequest struct {
-	Value    string `form:"value"`
-	ID       string `uri:"id" form:"id"`
-	Page     int64  `form:"page"`
-	PageSize int64  `form:"page_size"`
+	ID     string `uri:"id" form:"id"`
+	Value  string `form:"value"`
+	Code   string `form:"code"`
+	Status *int   `form:"status"`
+	Group  string `form:"group"`
+
+	Page     int64 `form:"page"`
+	PageSize int64 `form:"page_size"`
 }
 
 type SizeCreateRequest struct {
-	Value  string `json:"value" binding"required"`
+	Code   string `json:"code"`
+	Value  string `json:"value" binding:"required"`
 	Remark string `json:"remark"`
+	Status *int   `json:"status"`
+	Group  string `json:"group"` // 尺寸组，如 成人/童装
+	Sort   int    `json:"sort"`  // 排序（从小到大）
 }
 
+// SizeUpdateRequest 更新尺寸请求（扩展字段未传时保持不变，兼容旧接口只传 value/remark）
 type SizeUpdateRequest struct {
-	ID     string `uri:"id"`
-	Value  string `json:"value" binding"required"`
-	Remark string `json:"remark"`
+	ID     string  `uri:"id"`
+	Code   *string `json:"code"`
+	Value  string  `json:"value" binding:"required"`
+	Remark string  `json:"remark"`
+	Status *int    `json:"status"`
+	Group  *string `json:"group"`
+	Sort   *int    `json:"sort"`
 }
 
 // SizeResponse 尺寸响应
 type SizeResponse struct {
-	Size *models.Basic `json:"size"`
+	Size *models.Size `json:"size"`
 }
 
 // SizeListResponse 尺寸列表响应
 type SizeListResponse struct {
-	Sizes []*models.Basic `json:"sizes"`
-	Total int64           `json:"total"`
+	Sizes []*models.Size `json:"sizes"`
+	Total int64          `json:"total"`
 }
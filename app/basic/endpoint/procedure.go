@@ -82,3 +82,41 @@ func DeleteProcedureEndpoint(svc services.IProcedureService) endpoint.Endpoint {
 		return map[string]string{"message": "删除成功"}, nil
 	}
 }
+
+// GetPricingEndpoint 获取工价设置端点
+func GetPricingEndpoint(svc services.IProcedureService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		pricing, err := svc.GetPricing(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return dto.PricingResponse{Pricing: pricing}, nil
+	}
+}
+
+// UpdatePricingEndpoint 更新工价设置端点
+func UpdatePricingEndpoint(svc services.IProcedureService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.PricingUpdateRequest)
+		pricing, repriced, err := svc.UpdatePricing(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return dto.PricingResponse{Pricing: pricing, Repriced: repriced}, nil
+	}
+}
+
+// RepriceProceduresEndpoint 重算工序库工价端点
+func RepriceProceduresEndpoint(svc services.IProcedureService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		repriced, err := svc.Reprice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pricing, err := svc.GetPricing(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return dto.PricingResponse{Pricing: pricing, Repriced: repriced}, nil
+	}
+}
@@ -0,0 +1,84 @@
+package endpoint
+
+import (
+	"context"
+	"mule-cloud/app/basic/dto"
+	"mule-cloud/app/basic/services"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// GetProcedureTemplateEndpoint 获取工序模板端点
+func GetProcedureTemplateEndpoint(svc services.IProcedureTemplateService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ProcedureTemplateListRequest)
+		template, err := svc.Get(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+		return dto.ProcedureTemplateResponse{ProcedureTemplate: template}, nil
+	}
+}
+
+// GetAllProcedureTemplatesEndpoint 获取所有工序模板端点（不分页）
+func GetAllProcedureTemplatesEndpoint(svc services.IProcedureTemplateService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ProcedureTemplateListRequest)
+		templates, err := svc.GetAll(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return dto.ProcedureTemplateListResponse{ProcedureTemplates: templates, Total: int64(len(templates))}, nil
+	}
+}
+
+// ListProcedureTemplatesEndpoint 工序模板列表端点（分页）
+func ListProcedureTemplatesEndpoint(svc services.IProcedureTemplateService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ProcedureTemplateListRequest)
+		templates, total, err := svc.List(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return dto.ProcedureTemplateListResponse{
+			ProcedureTemplates: templates,
+			Total:              total,
+		}, nil
+	}
+}
+
+// CreateProcedureTemplateEndpoint 创建工序模板端点
+func CreateProcedureTemplateEndpoint(svc services.IProcedureTemplateService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ProcedureTemplateCreateRequest)
+		template, err := svc.Create(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return dto.ProcedureTemplateResponse{ProcedureTemplate: template}, nil
+	}
+}
+
+// UpdateProcedureTemplateEndpoint 更新工序模板端点
+func UpdateProcedureTemplateEndpoint(svc services.IProcedureTemplateService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ProcedureTemplateUpdateRequest)
+		template, err := svc.Update(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return dto.ProcedureTemplateResponse{ProcedureTemplate: template}, nil
+	}
+}
+
+// DeleteProcedureTemplateEndpoint 删除工序模板端点
+func DeleteProcedureTemplateEndpoint(svc services.IProcedureTemplateService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ProcedureTemplateListRequest)
+		err := svc.Delete(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"message": "删除成功"}, nil
+	}
+}
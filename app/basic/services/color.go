@@ -2,152 +2,126 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"mule-cloud/app/basic/dto"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
-	"time"
-
-	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"regexp"
+	"strings"
 )
 
 // IColorService 颜色服务接口
 type IColorService interface {
-	Get(ctx context.Context, id string) (*models.Basic, error)
-	GetAll(ctx context.Context, req dto.ColorListRequest) ([]models.Basic, error)
-	List(ctx context.Context, req dto.ColorListRequest) ([]models.Basic, int64, error)
-	Create(ctx context.Context, req dto.ColorCreateRequest) (*models.Basic, error)
-	Update(ctx context.Context, req dto.ColorUpdateRequest) (*models.Basic, error)
+	Get(ctx context.Context, id string) (*models.Color, error)
+	GetAll(ctx context.Context, req dto.ColorListRequest) ([]*models.Color, error)
+	List(ctx context.Context, req dto.ColorListRequest) ([]*models.Color, int64, error)
+	Create(ctx context.Context, req dto.ColorCreateRequest) (*models.Color, error)
+	Update(ctx context.Context, req dto.ColorUpdateRequest) (*models.Color, error)
 	Delete(ctx context.Context, id string) error
 }
 
 // ColorService 颜色服务实现
 type ColorService struct {
-	repo repository.BasicRepository
+	repo repository.MasterDataRepository[models.Color]
 }
 
 // NewColorService 创建颜色服务
 func NewColorService() IColorService {
-	repo := repository.NewBasicRepository()
-	return &ColorService{repo: repo}
+	return &ColorService{repo: repository.NewColorRepository()}
 }
 
 // Get 获取颜色
-func (s *ColorService) Get(ctx context.Context, id string) (*models.Basic, error) {
-	return s.repo.Get(ctx, id)
-}
-
-// List 列表（分页查询）
-func (s *ColorService) List(ctx context.Context, req dto.ColorListRequest) ([]models.Basic, int64, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "color", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
-	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
-	}
-
-	// 获取总数
-	total, err := s.repo.Count(ctx, filter)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	// 分页查询
-	offset := (req.Page - 1) * req.PageSize
-	opts := options.Find().
-		SetSkip(offset).
-		SetLimit(req.PageSize).
-		SetSort(bson.M{"created_at": -1})
-
-	// 使用 GetCollectionWithContext 获取原始集合以使用 options（支持租户隔离）
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
+func (s *ColorService) Get(ctx context.Context, id string) (*models.Color, error) {
+	color, err := s.repo.Get(ctx, id)
 	if err != nil {
-		return nil, 0, err
-	}
-	defer cursor.Close(ctx)
-
-	colors := []models.Basic{}
-	err = cursor.All(ctx, &colors)
-	if err != nil {
-		return nil, 0, err
+		return nil, notFound("颜色", err)
 	}
+	return color, nil
+}
 
-	return colors, total, nil
+// List 列表（分页查询）
+func (s *ColorService) List(ctx context.Context, req dto.ColorListRequest) ([]*models.Color, int64, error) {
+	page, pageSize := pageOf(req.Page, req.PageSize)
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	return s.repo.List(ctx, filter, page, pageSize, byCreatedDesc)
 }
 
 // GetAll 获取所有颜色（不分页）
-func (s *ColorService) GetAll(ctx context.Context, req dto.ColorListRequest) ([]models.Basic, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "color", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
+func (s *ColorService) GetAll(ctx context.Context, req dto.ColorListRequest) ([]*models.Color, error) {
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	colors, _, err := s.repo.List(ctx, filter, 0, 0, byCreatedDesc)
+	return colors, err
+}
+
+// Create 创建颜色
+func (s *ColorService) Create(ctx context.Context, req dto.ColorCreateRequest) (*models.Color, error) {
+	if err := validateMasterData("颜色", req.Value, req.Status); err != nil {
+		return nil, err
 	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
+	if err := checkUnique(ctx, s.repo, "颜色", "", req.Value, &req.Code); err != nil {
+		return nil, err
 	}
-
-	// 使用 GetCollection 获取原始集合以使用排序选项
-	opts := options.Find().SetSort(bson.M{"created_at": -1})
-	collection := s.repo.GetCollection()
-	cursor, err := collection.Find(ctx, filter, opts)
+	hex, err := normalizeHex(req.Hex)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	colors := []models.Basic{}
-	err = cursor.All(ctx, &colors)
-	if err != nil {
-		return nil, err
+	color := &models.Color{
+		MasterData: newMasterData(ctx, "color", req.Code, req.Value, req.Remark, req.Status),
+		Hex:        hex,
+		PantoneNo:  strings.TrimSpace(req.PantoneNo),
 	}
 
-	return colors, nil
+	if err := s.repo.Create(ctx, color); err != nil {
+		return nil, writeErr("颜色", err)
+	}
+	return color, nil
 }
 
-// Create 创建颜色
-func (s *ColorService) Create(ctx context.Context, req dto.ColorCreateRequest) (*models.Basic, error) {
-	now := time.Now().Unix()
-
-	basic := &models.Basic{
-		Name:      "color",
-		Value:     req.Value,
-		Remark:    req.Remark,
-		CreatedAt: now,
-		UpdatedAt: now,
+// Update 更新颜色
+func (s *ColorService) Update(ctx context.Context, req dto.ColorUpdateRequest) (*models.Color, error) {
+	if err := validateMasterData("颜色", req.Value, req.Status); err != nil {
+		return nil, err
 	}
-
-	err := s.repo.Create(ctx, basic)
-	if err != nil {
+	if err := checkUnique(ctx, s.repo, "颜色", req.ID, req.Value, req.Code); err != nil {
 		return nil, err
 	}
 
-	return basic, nil
-}
-
-// Update 更新颜色
-func (s *ColorService) Update(ctx context.Context, req dto.ColorUpdateRequest) (*models.Basic, error) {
-	// 更新字段
-	update := bson.M{
-		"value":      req.Value,
-		"remark":     req.Remark,
-		"updated_at": time.Now().Unix(),
+	update := masterDataUpdate(ctx, req.Code, req.Value, req.Remark, req.Status)
+	if req.Hex != nil {
+		hex, err := normalizeHex(*req.Hex)
+		if err != nil {
+			return nil, err
+		}
+		update["hex"] = hex
+	}
+	if req.PantoneNo != nil {
+		update["pantone_no"] = strings.TrimSpace(*req.PantoneNo)
 	}
 
-	err := s.repo.Update(ctx, req.ID, update)
-	if err != nil {
-		return nil, err
+	if err := s.repo.Update(ctx, req.ID, update); err != nil {
+		return nil, writeErr("颜色", err)
 	}
 
 	// 返回更新后的数据
-	return s.repo.Get(ctx, req.ID)
+	return s.Get(ctx, req.ID)
 }
 
 // Delete 删除颜色
 func (s *ColorService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	return notFound("颜色", s.repo.Delete(ctx, id))
+}
+
+var hexPattern = regexp.MustCompile(`^#[0-9A-F]{6}$`)
+
+// normalizeHex 校验色值（#RRGGBB）
+func normalizeHex(hex string) (string, error) {
+	hex = strings.ToUpper(strings.TrimSpace(hex))
+	if hex != "" && !strings.HasPrefix(hex, "#") {
+		hex = "#" + hex
+	}
+	if hex != "" && !hexPattern.MatchString(hex) {
+		return "", fmt.Errorf("色值格式不正确，应为 #RRGGBB")
+	}
+	return hex, nil
 }
@@ -2,150 +2,250 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"mule-cloud/app/basic/dto"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
-	"time"
-
-	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"net/mail"
+	"regexp"
+	"strings"
 )
 
 // ICustomerService 客户服务接口
 type ICustomerService interface {
-	Get(ctx context.Context, id string) (*models.Basic, error)
-	GetAll(ctx context.Context, req dto.CustomerListRequest) ([]models.Basic, error)
-	List(ctx context.Context, req dto.CustomerListRequest) ([]models.Basic, int64, error)
-	Create(ctx context.Context, req dto.CustomerCreateRequest) (*models.Basic, error)
-	Update(ctx context.Context, req dto.CustomerUpdateRequest) (*models.Basic, error)
+	Get(ctx context.Context, id string) (*models.Customer, error)
+	GetAll(ctx context.Context, req dto.CustomerListRequest) ([]*models.Customer, error)
+	List(ctx context.Context, req dto.CustomerListRequest) ([]*models.Customer, int64, error)
+	Create(ctx context.Context, req dto.CustomerCreateRequest) (*models.Customer, error)
+	Update(ctx context.Context, req dto.CustomerUpdateRequest) (*models.Customer, error)
 	Delete(ctx context.Context, id string) error
 }
 
 // CustomerService 客户服务实现
 type CustomerService struct {
-	repo repository.BasicRepository
+	repo         repository.MasterDataRepository[models.Customer]
+	salesmanRepo repository.MasterDataRepository[models.Salesman]
 }
 
 // NewCustomerService 创建客户服务
 func NewCustomerService() ICustomerService {
-	repo := repository.NewBasicRepository()
-	return &CustomerService{repo: repo}
+	return &CustomerService{
+		repo:         repository.NewCustomerRepository(),
+		salesmanRepo: repository.NewSalesmanRepository(),
+	}
 }
 
+var (
+	taxNoPattern = regexp.MustCompile(`^[0-9A-Z]{15}$|^[0-9A-Z]{18}$|^[0-9A-Z]{20}$`)
+	phonePattern = regexp.MustCompile(`^[0-9+\-() ]{5,20}$`)
+)
+
 // Get 获取客户
-func (s *CustomerService) Get(ctx context.Context, id string) (*models.Basic, error) {
-	return s.repo.Get(ctx, id)
+func (s *CustomerService) Get(ctx context.Context, id string) (*models.Customer, error) {
+	customer, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, notFound("客户", err)
+	}
+	return customer, nil
 }
 
 // List 列表（分页查询）
-func (s *CustomerService) List(ctx context.Context, req dto.CustomerListRequest) ([]models.Basic, int64, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "customer", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
-	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
-	}
-	// 获取总数
-	total, err := s.repo.Count(ctx, filter)
-	if err != nil {
-		return nil, 0, err
-	}
+func (s *CustomerService) List(ctx context.Context, req dto.CustomerListRequest) ([]*models.Customer, int64, error) {
+	page, pageSize := pageOf(req.Page, req.PageSize)
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	return s.repo.List(ctx, filter, page, pageSize, byCreatedDesc)
+}
 
-	// 分页查询
-	offset := (req.Page - 1) * req.PageSize
-	opts := options.Find().
-		SetSkip(offset).
-		SetLimit(req.PageSize).
-		SetSort(bson.M{"created_at": -1})
+// GetAll 获取所有客户（不分页）
+func (s *CustomerService) GetAll(ctx context.Context, req dto.CustomerListRequest) ([]*models.Customer, error) {
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	customers, _, err := s.repo.List(ctx, filter, 0, 0, byCreatedDesc)
+	return customers, err
+}
 
-	// 使用 GetCollection 获取原始集合以使用 options
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
-	if err != nil {
-		return nil, 0, err
+// Create 创建客户
+func (s *CustomerService) Create(ctx context.Context, req dto.CustomerCreateRequest) (*models.Customer, error) {
+	if err := validateMasterData("客户", req.Value, req.Status); err != nil {
+		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	customers := []models.Basic{}
-	err = cursor.All(ctx, &customers)
-	if err != nil {
-		return nil, 0, err
+	if err := checkUnique(ctx, s.repo, "客户", "", req.Value, &req.Code); err != nil {
+		return nil, err
 	}
 
-	return customers, total, nil
-}
-
-// GetAll 获取所有客户（不分页）
-func (s *CustomerService) GetAll(ctx context.Context, req dto.CustomerListRequest) ([]models.Basic, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "customer", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
-	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
-	}
-	// 使用 GetCollection 获取原始集合以使用排序选项
-	opts := options.Find().SetSort(bson.M{"created_at": -1})
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
+	taxNo, err := normalizeTaxNo(req.TaxNo)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	customers := []models.Basic{}
-	err = cursor.All(ctx, &customers)
+	contacts, err := normalizeContacts(req.Contacts)
 	if err != nil {
 		return nil, err
 	}
-
-	return customers, nil
-}
-
-// Create 创建客户
-func (s *CustomerService) Create(ctx context.Context, req dto.CustomerCreateRequest) (*models.Basic, error) {
-	now := time.Now().Unix()
-
-	basic := &models.Basic{
-		Name:      "customer",
-		Value:     req.Value,
-		Remark:    req.Remark,
-		CreatedAt: now,
-		UpdatedAt: now,
+	priceList, err := normalizePriceList(req.PriceList)
+	if err != nil {
+		return nil, err
 	}
-
-	err := s.repo.Create(ctx, basic)
+	salesmanName, err := s.salesmanName(ctx, req.SalesmanID)
 	if err != nil {
 		return nil, err
 	}
 
-	return basic, nil
+	customer := &models.Customer{
+		MasterData:   newMasterData(ctx, "customer", req.Code, req.Value, req.Remark, req.Status),
+		ShortName:    strings.TrimSpace(req.ShortName),
+		Address:      strings.TrimSpace(req.Address),
+		Contacts:     contacts,
+		TaxNo:        taxNo,
+		BankName:     strings.TrimSpace(req.BankName),
+		BankAccount:  strings.TrimSpace(req.BankAccount),
+		SalesmanID:   req.SalesmanID,
+		SalesmanName: salesmanName,
+		PriceList:    priceList,
+	}
+
+	if err := s.repo.Create(ctx, customer); err != nil {
+		return nil, writeErr("客户", err)
+	}
+	return customer, nil
 }
 
 // Update 更新客户
-func (s *CustomerService) Update(ctx context.Context, req dto.CustomerUpdateRequest) (*models.Basic, error) {
-	// 更新字段
-	update := bson.M{
-		"value":      req.Value,
-		"remark":     req.Remark,
-		"updated_at": time.Now().Unix(),
+func (s *CustomerService) Update(ctx context.Context, req dto.CustomerUpdateRequest) (*models.Customer, error) {
+	if err := validateMasterData("客户", req.Value, req.Status); err != nil {
+		return nil, err
 	}
-
-	err := s.repo.Update(ctx, req.ID, update)
-	if err != nil {
+	if err := checkUnique(ctx, s.repo, "客户", req.ID, req.Value, req.Code); err != nil {
 		return nil, err
 	}
 
+	update := masterDataUpdate(ctx, req.Code, req.Value, req.Remark, req.Status)
+	if req.ShortName != nil {
+		update["short_name"] = strings.TrimSpace(*req.ShortName)
+	}
+	if req.Address != nil {
+		update["address"] = strings.TrimSpace(*req.Address)
+	}
+	if req.Contacts != nil {
+		contacts, err := normalizeContacts(*req.Contacts)
+		if err != nil {
+			return nil, err
+		}
+		update["contacts"] = contacts
+	}
+	if req.TaxNo != nil {
+		taxNo, err := normalizeTaxNo(*req.TaxNo)
+		if err != nil {
+			return nil, err
+		}
+		update["tax_no"] = taxNo
+	}
+	if req.BankName != nil {
+		update["bank_name"] = strings.TrimSpace(*req.BankName)
+	}
+	if req.BankAccount != nil {
+		update["bank_account"] = strings.TrimSpace(*req.BankAccount)
+	}
+	if req.SalesmanID != nil {
+		salesmanName, err := s.salesmanName(ctx, *req.SalesmanID)
+		if err != nil {
+			return nil, err
+		}
+		update["salesman_id"] = *req.SalesmanID
+		update["salesman_name"] = salesmanName
+	}
+	if req.PriceList != nil {
+		priceList, err := normalizePriceList(*req.PriceList)
+		if err != nil {
+			return nil, err
+		}
+		update["price_list"] = priceList
+	}
+
+	if err := s.repo.Update(ctx, req.ID, update); err != nil {
+		return nil, writeErr("客户", err)
+	}
+
 	// 返回更新后的数据
-	return s.repo.Get(ctx, req.ID)
+	return s.Get(ctx, req.ID)
 }
 
 // Delete 删除客户
 func (s *CustomerService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	return notFound("客户", s.repo.Delete(ctx, id))
+}
+
+// salesmanName 校验默认业务员并返回名称
+func (s *CustomerService) salesmanName(ctx context.Context, salesmanID string) (string, error) {
+	if salesmanID == "" {
+		return "", nil
+	}
+	salesman, err := s.salesmanRepo.Get(ctx, salesmanID)
+	if err != nil {
+		return "", notFound("业务员", err)
+	}
+	return salesman.Value, nil
+}
+
+// normalizeTaxNo 校验税号（统一社会信用代码18位，兼容旧税号15/20位）
+func normalizeTaxNo(taxNo string) (string, error) {
+	taxNo = strings.ToUpper(strings.TrimSpace(taxNo))
+	if taxNo != "" && !taxNoPattern.MatchString(taxNo) {
+		return "", fmt.Errorf("税号格式不正确")
+	}
+	return taxNo, nil
+}
+
+// normalizeContacts 校验联系人（未指定主要联系人时默认第一个）
+func normalizeContacts(contacts []models.Contact) ([]models.Contact, error) {
+	result := make([]models.Contact, 0, len(contacts))
+	primary := 0
+	for i, contact := range contacts {
+		contact.Name = strings.TrimSpace(contact.Name)
+		contact.Phone = strings.TrimSpace(contact.Phone)
+		contact.Email = strings.TrimSpace(contact.Email)
+		if contact.Name == "" {
+			return nil, fmt.Errorf("第%d个联系人姓名不能为空", i+1)
+		}
+		if contact.Phone != "" && !phonePattern.MatchString(contact.Phone) {
+			return nil, fmt.Errorf("联系人[%s]电话格式不正确", contact.Name)
+		}
+		if contact.Email != "" {
+			if _, err := mail.ParseAddress(contact.Email); err != nil {
+				return nil, fmt.Errorf("联系人[%s]邮箱格式不正确", contact.Name)
+			}
+		}
+		if contact.IsPrimary {
+			primary++
+		}
+		result = append(result, contact)
+	}
+	if primary > 1 {
+		return nil, fmt.Errorf("只能有一个主要联系人")
+	}
+	if primary == 0 && len(result) > 0 {
+		result[0].IsPrimary = true
+	}
+	return result, nil
+}
+
+// normalizePriceList 校验价目表（同一款式只能有一个价格）
+func normalizePriceList(items []models.PriceListItem) ([]models.PriceListItem, error) {
+	result := make([]models.PriceListItem, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		item.StyleID = strings.TrimSpace(item.StyleID)
+		item.StyleNo = strings.TrimSpace(item.StyleNo)
+		if item.StyleID == "" && item.StyleNo == "" {
+			return nil, fmt.Errorf("价目表第%d行缺少款式", i+1)
+		}
+		if item.UnitPrice < 0 {
+			return nil, fmt.Errorf("价目表第%d行单价不能为负数", i+1)
+		}
+		key := item.StyleID + "|" + item.StyleNo
+		if seen[key] {
+			return nil, fmt.Errorf("价目表中款式[%s]重复", item.StyleNo)
+		}
+		seen[key] = true
+		result = append(result, item)
+	}
+	return result, nil
 }
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corecontext "mule-cloud/core/context"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// newMasterData 构造基础资料公共字段（默认启用），name 为资料类型
+func newMasterData(ctx context.Context, name, code, value, remark string, status *int) models.MasterData {
+	now := time.Now().Unix()
+	username := corecontext.GetUsername(ctx)
+	data := models.MasterData{
+		ID:        bson.NewObjectID().Hex(),
+		Name:      name,
+		Code:      strings.TrimSpace(code),
+		Value:     strings.TrimSpace(value),
+		Remark:    remark,
+		Status:    1,
+		CreatedBy: username,
+		UpdatedBy: username,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if status != nil {
+		data.Status = *status
+	}
+	return data
+}
+
+// masterDataUpdate 构造公共字段的更新内容（value/remark 与旧接口一致总是更新，其余字段传了才更新）
+func masterDataUpdate(ctx context.Context, code *string, value, remark string, status *int) bson.M {
+	update := bson.M{
+		"value":      strings.TrimSpace(value),
+		"remark":     remark,
+		"updated_by": corecontext.GetUsername(ctx),
+	}
+	if code != nil {
+		update["code"] = strings.TrimSpace(*code)
+	}
+	if status != nil {
+		update["status"] = *status
+	}
+	return update
+}
+
+// validateMasterData 校验公共字段
+func validateMasterData(label, value string, status *int) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s名称不能为空", label)
+	}
+	if len([]rune(strings.TrimSpace(value))) > 100 {
+		return fmt.Errorf("%s名称不能超过100个字符", label)
+	}
+	if status != nil && *status != 0 && *status != 1 {
+		return fmt.Errorf("无效的状态: %d", *status)
+	}
+	return nil
+}
+
+// checkUnique 校验同类基础资料的名称/编码不重复
+func checkUnique[T any](ctx context.Context, repo repository.MasterDataRepository[T], label, excludeID, value string, code *string) error {
+	filter := bson.M{"value": strings.TrimSpace(value)}
+	if excludeID != "" {
+		filter["_id"] = bson.M{"$ne": excludeID}
+	}
+	count, err := repo.Count(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%s[%s]已存在", label, strings.TrimSpace(value))
+	}
+
+	if code == nil || strings.TrimSpace(*code) == "" {
+		return nil
+	}
+	filter = bson.M{"code": strings.TrimSpace(*code)}
+	if excludeID != "" {
+		filter["_id"] = bson.M{"$ne": excludeID}
+	}
+	count, err = repo.Count(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%s编码[%s]已存在", label, strings.TrimSpace(*code))
+	}
+	return nil
+}
+
+// writeErr 写入错误：编码重复（唯一索引冲突，并发创建时服务层查重拦不住）或记录不存在
+func writeErr(label string, err error) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		return fmt.Errorf("%s编码已存在", label)
+	}
+	return notFound(label, err)
+}
+
+// masterDataFilter 构造列表查询条件（名称、编码模糊搜索）
+func masterDataFilter(id, value, code string, status *int) bson.M {
+	filter := bson.M{}
+	if value != "" {
+		filter["value"] = bson.M{"$regex": value, "$options": "i"}
+	}
+	if code != "" {
+		filter["code"] = bson.M{"$regex": code, "$options": "i"}
+	}
+	if id != "" {
+		filter["_id"] = id
+	}
+	if status != nil {
+		filter["status"] = *status
+	}
+	return filter
+}
+
+// pageOf 分页参数默认值
+func pageOf(page, pageSize int64) (int64, int64) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
+
+// notFound 统一的记录不存在错误
+func notFound(label string, err error) error {
+	if err == repository.ErrNotFound {
+		return fmt.Errorf("%s不存在", label)
+	}
+	return err
+}
+
+// byCreatedDesc 按创建时间倒序
+var byCreatedDesc = bson.D{{Key: "created_at", Value: -1}}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// masterDataName 基础资料类型（旧 basic 集合的 name 字段）及其集合
+type masterDataName struct {
+	name       string
+	collection string
+}
+
+// basicMigrations 旧 basic 集合 name 字段 -> 新集合
+var basicMigrations = []masterDataName{
+	{"customer", models.Customer{}.TableName()},
+	{"salesman", models.Salesman{}.TableName()},
+	{"color", models.Color{}.TableName()},
+	{"size", models.Size{}.TableName()},
+	{"order_type", models.OrderType{}.TableName()},
+	{"procedure", models.Procedure{}.TableName()},
+}
+
+// masterDataNames 全部基础资料集合（工序模板是新增类型，不在旧 basic 集合中）
+var masterDataNames = append(append([]masterDataName{}, basicMigrations...),
+	masterDataName{"procedure_template", models.ProcedureTemplate{}.TableName()},
+)
+
+// MigrateBasicData 将旧 basic 集合中的基础数据迁移到各自的独立集合
+//
+// 遍历系统库和所有租户库，ID 保持不变（ObjectID 转为十六进制字符串），
+// 订单等处保存的 customer_id / salesman_id 等引用无需修改。
+// 已迁移的记录打上 migrated_at 标记，重复执行是安全的。
+// 返回每个库（租户编码，系统库为 system）迁移的记录数。
+func MigrateBasicData(ctx context.Context) (map[string]int, error) {
+	dbManager := database.GetDatabaseManager()
+
+	tenantCodes := []string{"system"}
+	tenants, err := repository.NewTenantRepository().Find(ctx, bson.M{"is_deleted": 0})
+	if err != nil {
+		return nil, fmt.Errorf("查询租户列表失败: %w", err)
+	}
+	for _, tenant := range tenants {
+		if tenant.Code != "" {
+			tenantCodes = append(tenantCodes, tenant.Code)
+		}
+	}
+
+	result := make(map[string]int, len(tenantCodes))
+	for _, code := range tenantCodes {
+		db := dbManager.GetDatabase(code)
+		count, err := migrateBasicDatabase(ctx, db)
+		if err != nil {
+			return result, fmt.Errorf("迁移租户[%s]基础数据失败: %w", code, err)
+		}
+		if err := backfillMasterDataName(ctx, db); err != nil {
+			return result, fmt.Errorf("补齐租户[%s]基础资料类型失败: %w", code, err)
+		}
+		if count > 0 {
+			result[code] = count
+		}
+	}
+	return result, nil
+}
+
+// migrateBasicDatabase 迁移单个库的基础数据
+func migrateBasicDatabase(ctx context.Context, db *mongo.Database) (int, error) {
+	basic := db.Collection(models.Basic{}.TableName())
+	total := 0
+
+	for _, m := range basicMigrations {
+		cursor, err := basic.Find(ctx, bson.M{"name": m.name, "migrated_at": bson.M{"$exists": false}})
+		if err != nil {
+			return total, err
+		}
+		var rows []bson.M
+		if err := cursor.All(ctx, &rows); err != nil {
+			return total, err
+		}
+
+		target := db.Collection(m.collection)
+		for _, row := range rows {
+			id := basicRowID(row["_id"])
+			if id == "" {
+				continue
+			}
+
+			// 旧接口从未维护 status（始终为0），迁移后统一视为启用
+			doc := bson.M{
+				"name":       m.name,
+				"code":       "",
+				"value":      row["value"],
+				"remark":     row["remark"],
+				"is_common":  row["is_common"] == true,
+				"status":     1,
+				"is_deleted": intOf(row["is_deleted"]),
+				"created_by": row["created_by"],
+				"updated_by": row["updated_by"],
+				"created_at": row["created_at"],
+				"updated_at": row["updated_at"],
+				"deleted_at": row["deleted_at"],
+			}
+			_, err := target.UpdateOne(ctx,
+				bson.M{"_id": id},
+				bson.M{"$setOnInsert": doc},
+				options.UpdateOne().SetUpsert(true),
+			)
+			if err != nil {
+				return total, err
+			}
+
+			_, err = basic.UpdateOne(ctx,
+				bson.M{"_id": row["_id"]},
+				bson.M{"$set": bson.M{"migrated_at": time.Now().Unix()}},
+			)
+			if err != nil {
+				return total, err
+			}
+			total++
+		}
+	}
+	return total, nil
+}
+
+// backfillMasterDataName 补齐缺少 name/is_common 的基础资料（早期迁移和创建的记录没有这两个字段）
+func backfillMasterDataName(ctx context.Context, db *mongo.Database) error {
+	for _, m := range masterDataNames {
+		_, err := db.Collection(m.collection).UpdateMany(ctx,
+			bson.M{"name": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"name": m.name, "is_common": false}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// basicRowID 旧数据的 _id 可能是 ObjectID 或字符串，统一转为字符串
+func basicRowID(id interface{}) string {
+	switch v := id.(type) {
+	case bson.ObjectID:
+		return v.Hex()
+	case string:
+		return v
+	default:
+		return ""
+	}
+}
+
+// intOf 兼容 int32/int64/double（mongo shell 写入）/缺省的数值字段
+func intOf(v interface{}) int {
+	switch n := v.(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case int:
+		return n
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
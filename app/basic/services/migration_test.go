@@ -0,0 +1,97 @@
+package services
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TestBasicRowID 测试旧数据 _id 转为字符串（新集合沿用原ID，订单等处的引用无需修改）
+func TestBasicRowID(t *testing.T) {
+	oid := bson.NewObjectID()
+	tests := []struct {
+		name string
+		id   interface{}
+		want string
+	}{
+		{"object id", oid, oid.Hex()},
+		{"string", "68f0c2a1b2c3d4e5f6a7b8c9", "68f0c2a1b2c3d4e5f6a7b8c9"},
+		{"missing", nil, ""},
+		{"unsupported", int64(42), ""},
+	}
+	for _, tt := range tests {
+		if got := basicRowID(tt.id); got != tt.want {
+			t.Errorf("%s: basicRowID() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestIntOf 测试旧数据数值字段的兼容转换
+func TestIntOf(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want int
+	}{
+		{"int32", int32(1), 1},
+		{"int64", int64(1), 1},
+		{"int", 1, 1},
+		{"double from mongo shell", float64(1), 1},
+		{"missing", nil, 0},
+		{"string", "1", 0},
+	}
+	for _, tt := range tests {
+		if got := intOf(tt.v); got != tt.want {
+			t.Errorf("%s: intOf(%v) = %d, want %d", tt.name, tt.v, got, tt.want)
+		}
+	}
+}
+
+// TestBasicMigrationsCoverTypes 测试旧 basic 的每种 name 都迁移到不同的独立集合
+func TestBasicMigrationsCoverTypes(t *testing.T) {
+	names := map[string]bool{}
+	collections := map[string]bool{}
+	for _, m := range basicMigrations {
+		if names[m.name] || collections[m.collection] {
+			t.Errorf("duplicate migration %s -> %s", m.name, m.collection)
+		}
+		names[m.name] = true
+		collections[m.collection] = true
+		if m.collection == "basic" {
+			t.Errorf("%s migrates back into the basic collection", m.name)
+		}
+	}
+	for _, name := range []string{"customer", "salesman", "color", "size", "order_type", "procedure"} {
+		if !names[name] {
+			t.Errorf("basic rows named %q are not migrated", name)
+		}
+	}
+}
+
+// TestValidateMasterData 测试基础资料公共字段校验
+func TestValidateMasterData(t *testing.T) {
+	status := func(v int) *int { return &v }
+	long := make([]rune, 101)
+	for i := range long {
+		long[i] = '红'
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		status  *int
+		wantErr bool
+	}{
+		{"ok", "大红", nil, false},
+		{"blank", "  ", nil, true},
+		{"100 chars", string(long[:100]), nil, false},
+		{"101 chars", string(long), nil, true},
+		{"disabled", "大红", status(0), false},
+		{"invalid status", "大红", status(2), true},
+	}
+	for _, tt := range tests {
+		if err := validateMasterData("颜色", tt.value, tt.status); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateMasterData() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -2,150 +2,119 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"mule-cloud/app/basic/dto"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
-	"time"
-
-	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"regexp"
+	"strings"
 )
 
 // IOrderTypeService 订单类型服务接口
 type IOrderTypeService interface {
-	Get(ctx context.Context, id string) (*models.Basic, error)
-	GetAll(ctx context.Context, req dto.OrderTypeListRequest) ([]models.Basic, error)
-	List(ctx context.Context, req dto.OrderTypeListRequest) ([]models.Basic, int64, error)
-	Create(ctx context.Context, req dto.OrderTypeCreateRequest) (*models.Basic, error)
-	Update(ctx context.Context, req dto.OrderTypeUpdateRequest) (*models.Basic, error)
+	Get(ctx context.Context, id string) (*models.OrderType, error)
+	GetAll(ctx context.Context, req dto.OrderTypeListRequest) ([]*models.OrderType, error)
+	List(ctx context.Context, req dto.OrderTypeListRequest) ([]*models.OrderType, int64, error)
+	Create(ctx context.Context, req dto.OrderTypeCreateRequest) (*models.OrderType, error)
+	Update(ctx context.Context, req dto.OrderTypeUpdateRequest) (*models.OrderType, error)
 	Delete(ctx context.Context, id string) error
 }
 
 // OrderTypeService 订单类型服务实现
 type OrderTypeService struct {
-	repo repository.BasicRepository
+	repo repository.MasterDataRepository[models.OrderType]
 }
 
 // NewOrderTypeService 创建订单类型服务
 func NewOrderTypeService() IOrderTypeService {
-	repo := repository.NewBasicRepository()
-	return &OrderTypeService{repo: repo}
+	return &OrderTypeService{repo: repository.NewOrderTypeRepository()}
 }
 
 // Get 获取订单类型
-func (s *OrderTypeService) Get(ctx context.Context, id string) (*models.Basic, error) {
-	return s.repo.Get(ctx, id)
-}
-
-// List 列表（分页查询）
-func (s *OrderTypeService) List(ctx context.Context, req dto.OrderTypeListRequest) ([]models.Basic, int64, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "order_type", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
-	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
-	}
-	// 获取总数
-	total, err := s.repo.Count(ctx, filter)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	// 分页查询
-	offset := (req.Page - 1) * req.PageSize
-	opts := options.Find().
-		SetSkip(offset).
-		SetLimit(req.PageSize).
-		SetSort(bson.M{"created_at": -1})
-
-	// 使用 GetCollection 获取原始集合以使用 options
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
+func (s *OrderTypeService) Get(ctx context.Context, id string) (*models.OrderType, error) {
+	orderType, err := s.repo.Get(ctx, id)
 	if err != nil {
-		return nil, 0, err
-	}
-	defer cursor.Close(ctx)
-
-	order_types := []models.Basic{}
-	err = cursor.All(ctx, &order_types)
-	if err != nil {
-		return nil, 0, err
+		return nil, notFound("订单类型", err)
 	}
+	return orderType, nil
+}
 
-	return order_types, total, nil
+// List 列表（分页查询）
+func (s *OrderTypeService) List(ctx context.Context, req dto.OrderTypeListRequest) ([]*models.OrderType, int64, error) {
+	page, pageSize := pageOf(req.Page, req.PageSize)
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	return s.repo.List(ctx, filter, page, pageSize, byCreatedDesc)
 }
 
 // GetAll 获取所有订单类型（不分页）
-func (s *OrderTypeService) GetAll(ctx context.Context, req dto.OrderTypeListRequest) ([]models.Basic, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "order_type", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
+func (s *OrderTypeService) GetAll(ctx context.Context, req dto.OrderTypeListRequest) ([]*models.OrderType, error) {
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	orderTypes, _, err := s.repo.List(ctx, filter, 0, 0, byCreatedDesc)
+	return orderTypes, err
+}
+
+// Create 创建订单类型
+func (s *OrderTypeService) Create(ctx context.Context, req dto.OrderTypeCreateRequest) (*models.OrderType, error) {
+	if err := validateMasterData("订单类型", req.Value, req.Status); err != nil {
+		return nil, err
 	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
+	if err := checkUnique(ctx, s.repo, "订单类型", "", req.Value, &req.Code); err != nil {
+		return nil, err
 	}
-	// 使用 GetCollection 获取原始集合以使用排序选项
-	opts := options.Find().SetSort(bson.M{"created_at": -1})
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
+	prefix, err := normalizePrefix(req.Prefix)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	order_types := []models.Basic{}
-	err = cursor.All(ctx, &order_types)
-	if err != nil {
-		return nil, err
+	orderType := &models.OrderType{
+		MasterData: newMasterData(ctx, "order_type", req.Code, req.Value, req.Remark, req.Status),
+		Prefix:     prefix,
 	}
 
-	return order_types, nil
+	if err := s.repo.Create(ctx, orderType); err != nil {
+		return nil, writeErr("订单类型", err)
+	}
+	return orderType, nil
 }
 
-// Create 创建订单类型
-func (s *OrderTypeService) Create(ctx context.Context, req dto.OrderTypeCreateRequest) (*models.Basic, error) {
-	now := time.Now().Unix()
-
-	basic := &models.Basic{
-		Name:      "order_type",
-		Value:     req.Value,
-		Remark:    req.Remark,
-		CreatedAt: now,
-		UpdatedAt: now,
+// Update 更新订单类型
+func (s *OrderTypeService) Update(ctx context.Context, req dto.OrderTypeUpdateRequest) (*models.OrderType, error) {
+	if err := validateMasterData("订单类型", req.Value, req.Status); err != nil {
+		return nil, err
 	}
-
-	err := s.repo.Create(ctx, basic)
-	if err != nil {
+	if err := checkUnique(ctx, s.repo, "订单类型", req.ID, req.Value, req.Code); err != nil {
 		return nil, err
 	}
 
-	return basic, nil
-}
-
-// Update 更新订单类型
-func (s *OrderTypeService) Update(ctx context.Context, req dto.OrderTypeUpdateRequest) (*models.Basic, error) {
-	// 更新字段
-	update := bson.M{
-		"value":      req.Value,
-		"remark":     req.Remark,
-		"updated_at": time.Now().Unix(),
+	update := masterDataUpdate(ctx, req.Code, req.Value, req.Remark, req.Status)
+	if req.Prefix != nil {
+		prefix, err := normalizePrefix(*req.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		update["prefix"] = prefix
 	}
 
-	err := s.repo.Update(ctx, req.ID, update)
-	if err != nil {
-		return nil, err
+	if err := s.repo.Update(ctx, req.ID, update); err != nil {
+		return nil, writeErr("订单类型", err)
 	}
 
 	// 返回更新后的数据
-	return s.repo.Get(ctx, req.ID)
+	return s.Get(ctx, req.ID)
 }
 
 // Delete 删除订单类型
 func (s *OrderTypeService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	return notFound("订单类型", s.repo.Delete(ctx, id))
+}
+
+var prefixPattern = regexp.MustCompile(`^[A-Z0-9-]{1,10}$`)
+
+// normalizePrefix 校验合同号前缀（字母、数字、横线，最多10位）
+func normalizePrefix(prefix string) (string, error) {
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	if prefix != "" && !prefixPattern.MatchString(prefix) {
+		return "", fmt.Errorf("合同号前缀只能包含字母、数字和横线，最多10位")
+	}
+	return prefix, nil
 }
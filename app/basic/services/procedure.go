@@ -2,150 +2,232 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"mule-cloud/app/basic/dto"
+	corecontext "mule-cloud/core/context"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // IProcedureService 工序服务接口
 type IProcedureService interface {
-	Get(ctx context.Context, id string) (*models.Basic, error)
-	GetAll(ctx context.Context, req dto.ProcedureListRequest) ([]models.Basic, error)
-	List(ctx context.Context, req dto.ProcedureListRequest) ([]models.Basic, int64, error)
-	Create(ctx context.Context, req dto.ProcedureCreateRequest) (*models.Basic, error)
-	Update(ctx context.Context, req dto.ProcedureUpdateRequest) (*models.Basic, error)
+	Get(ctx context.Context, id string) (*models.Procedure, error)
+	GetAll(ctx context.Context, req dto.ProcedureListRequest) ([]*models.Procedure, error)
+	List(ctx context.Context, req dto.ProcedureListRequest) ([]*models.Procedure, int64, error)
+	Create(ctx context.Context, req dto.ProcedureCreateRequest) (*models.Procedure, error)
+	Update(ctx context.Context, req dto.ProcedureUpdateRequest) (*models.Procedure, error)
 	Delete(ctx context.Context, id string) error
+	GetPricing(ctx context.Context) (*models.PricingSetting, error)
+	UpdatePricing(ctx context.Context, req dto.PricingUpdateRequest) (*models.PricingSetting, int, error)
+	Reprice(ctx context.Context) (int, error)
 }
 
 // ProcedureService 工序服务实现
 type ProcedureService struct {
-	repo repository.BasicRepository
+	repo        repository.MasterDataRepository[models.Procedure]
+	pricingRepo repository.PricingSettingRepository
 }
 
 // NewProcedureService 创建工序服务
 func NewProcedureService() IProcedureService {
-	repo := repository.NewBasicRepository()
-	return &ProcedureService{repo: repo}
+	return &ProcedureService{
+		repo:        repository.NewProcedureRepository(),
+		pricingRepo: repository.NewPricingSettingRepository(),
+	}
 }
 
 // Get 获取工序
-func (s *ProcedureService) Get(ctx context.Context, id string) (*models.Basic, error) {
-	return s.repo.Get(ctx, id)
+func (s *ProcedureService) Get(ctx context.Context, id string) (*models.Procedure, error) {
+	procedure, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, notFound("工序", err)
+	}
+	return procedure, nil
 }
 
 // List 列表（分页查询）
-func (s *ProcedureService) List(ctx context.Context, req dto.ProcedureListRequest) ([]models.Basic, int64, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "procedure", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
-	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
-	}
-	// 获取总数
-	total, err := s.repo.Count(ctx, filter)
-	if err != nil {
-		return nil, 0, err
+func (s *ProcedureService) List(ctx context.Context, req dto.ProcedureListRequest) ([]*models.Procedure, int64, error) {
+	page, pageSize := pageOf(req.Page, req.PageSize)
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	if req.Category != "" {
+		filter["category"] = req.Category
+	}
+	if req.MachineType != "" {
+		filter["machine_type"] = req.MachineType
 	}
+	return s.repo.List(ctx, filter, page, pageSize, byCreatedDesc)
+}
 
-	// 分页查询
-	offset := (req.Page - 1) * req.PageSize
-	opts := options.Find().
-		SetSkip(offset).
-		SetLimit(req.PageSize).
-		SetSort(bson.M{"created_at": -1})
+// GetAll 获取所有工序（不分页）
+func (s *ProcedureService) GetAll(ctx context.Context, req dto.ProcedureListRequest) ([]*models.Procedure, error) {
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	if req.Category != "" {
+		filter["category"] = req.Category
+	}
+	if req.MachineType != "" {
+		filter["machine_type"] = req.MachineType
+	}
+	procedures, _, err := s.repo.List(ctx, filter, 0, 0, byCreatedDesc)
+	return procedures, err
+}
 
-	// 使用 GetCollection 获取原始集合以使用 options
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
-	if err != nil {
-		return nil, 0, err
+// Create 创建工序
+func (s *ProcedureService) Create(ctx context.Context, req dto.ProcedureCreateRequest) (*models.Procedure, error) {
+	if err := validateMasterData("工序", req.Value, req.Status); err != nil {
+		return nil, err
+	}
+	if err := checkUnique(ctx, s.repo, "工序", "", req.Value, &req.Code); err != nil {
+		return nil, err
+	}
+	if err := validateProcedureTimes(req.SAM, req.BaseRate, req.UnitPrice); err != nil {
+		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	procedures := []models.Basic{}
-	err = cursor.All(ctx, &procedures)
-	if err != nil {
-		return nil, 0, err
+	procedure := &models.Procedure{
+		MasterData:  newMasterData(ctx, "procedure", req.Code, req.Value, req.Remark, req.Status),
+		Category:    strings.TrimSpace(req.Category),
+		SAM:         req.SAM,
+		MachineType: strings.TrimSpace(req.MachineType),
+		BaseRate:    req.BaseRate,
+		UnitPrice:   req.UnitPrice,
+	}
+	if procedure.SAM > 0 {
+		pricing, err := s.pricingRepo.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		procedure.UnitPrice = procedure.PriceAt(pricing.MinuteRate)
 	}
 
-	return procedures, total, nil
+	if err := s.repo.Create(ctx, procedure); err != nil {
+		return nil, writeErr("工序", err)
+	}
+	return procedure, nil
 }
 
-// GetAll 获取所有工序（不分页）
-func (s *ProcedureService) GetAll(ctx context.Context, req dto.ProcedureListRequest) ([]models.Basic, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "procedure", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
-	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
-	}
-	// 使用 GetCollection 获取原始集合以使用排序选项
-	opts := options.Find().SetSort(bson.M{"created_at": -1})
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
-	if err != nil {
+// Update 更新工序
+func (s *ProcedureService) Update(ctx context.Context, req dto.ProcedureUpdateRequest) (*models.Procedure, error) {
+	if err := validateMasterData("工序", req.Value, req.Status); err != nil {
+		return nil, err
+	}
+	if err := checkUnique(ctx, s.repo, "工序", req.ID, req.Value, req.Code); err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	procedures := []models.Basic{}
-	err = cursor.All(ctx, &procedures)
+	procedure, err := s.Get(ctx, req.ID)
 	if err != nil {
 		return nil, err
 	}
+	if req.SAM != nil {
+		procedure.SAM = *req.SAM
+	}
+	if req.BaseRate != nil {
+		procedure.BaseRate = *req.BaseRate
+	}
+	if req.UnitPrice != nil {
+		procedure.UnitPrice = *req.UnitPrice
+	}
+	if err := validateProcedureTimes(procedure.SAM, procedure.BaseRate, procedure.UnitPrice); err != nil {
+		return nil, err
+	}
+
+	update := masterDataUpdate(ctx, req.Code, req.Value, req.Remark, req.Status)
+	if req.Category != nil {
+		update["category"] = strings.TrimSpace(*req.Category)
+	}
+	if req.MachineType != nil {
+		update["machine_type"] = strings.TrimSpace(*req.MachineType)
+	}
+	update["sam"] = procedure.SAM
+	update["base_rate"] = procedure.BaseRate
+	update["unit_price"] = procedure.UnitPrice
+	if procedure.SAM > 0 {
+		pricing, err := s.pricingRepo.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		update["unit_price"] = procedure.PriceAt(pricing.MinuteRate)
+	}
+
+	if err := s.repo.Update(ctx, req.ID, update); err != nil {
+		return nil, writeErr("工序", err)
+	}
+
+	// 返回更新后的数据
+	return s.Get(ctx, req.ID)
+}
 
-	return procedures, nil
+// Delete 删除工序
+func (s *ProcedureService) Delete(ctx context.Context, id string) error {
+	return notFound("工序", s.repo.Delete(ctx, id))
 }
 
-// Create 创建工序
-func (s *ProcedureService) Create(ctx context.Context, req dto.ProcedureCreateRequest) (*models.Basic, error) {
-	now := time.Now().Unix()
+// GetPricing 获取租户工价设置
+func (s *ProcedureService) GetPricing(ctx context.Context) (*models.PricingSetting, error) {
+	return s.pricingRepo.Get(ctx)
+}
 
-	basic := &models.Basic{
-		Name:      "procedure",
-		Value:     req.Value,
-		Remark:    req.Remark,
-		CreatedAt: now,
-		UpdatedAt: now,
+// UpdatePricing 更新租户统一费率，并按新费率重算工序库工价
+func (s *ProcedureService) UpdatePricing(ctx context.Context, req dto.PricingUpdateRequest) (*models.PricingSetting, int, error) {
+	if req.MinuteRate < 0 {
+		return nil, 0, fmt.Errorf("费率不能为负数")
 	}
 
-	err := s.repo.Create(ctx, basic)
-	if err != nil {
-		return nil, err
+	pricing := &models.PricingSetting{
+		MinuteRate: req.MinuteRate,
+		UpdatedBy:  corecontext.GetUsername(ctx),
+		UpdatedAt:  time.Now().Unix(),
+	}
+	if err := s.pricingRepo.Save(ctx, pricing); err != nil {
+		return nil, 0, err
 	}
 
-	return basic, nil
+	repriced, err := s.Reprice(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pricing, repriced, nil
 }
 
-// Update 更新工序
-func (s *ProcedureService) Update(ctx context.Context, req dto.ProcedureUpdateRequest) (*models.Basic, error) {
-	// 更新字段
-	update := bson.M{
-		"value":      req.Value,
-		"remark":     req.Remark,
-		"updated_at": time.Now().Unix(),
+// Reprice 按当前费率重算工序库中设置了标准工时的工价，返回有变化的工序数
+func (s *ProcedureService) Reprice(ctx context.Context) (int, error) {
+	pricing, err := s.pricingRepo.Get(ctx)
+	if err != nil {
+		return 0, err
 	}
-
-	err := s.repo.Update(ctx, req.ID, update)
+	procedures, _, err := s.repo.List(ctx, bson.M{"sam": bson.M{"$gt": 0}}, 0, 0, byCreatedDesc)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	// 返回更新后的数据
-	return s.repo.Get(ctx, req.ID)
+	repriced := 0
+	for _, procedure := range procedures {
+		price := procedure.PriceAt(pricing.MinuteRate)
+		if price == procedure.UnitPrice {
+			continue
+		}
+		if err := s.repo.Update(ctx, procedure.ID, bson.M{"unit_price": price}); err != nil {
+			return repriced, err
+		}
+		repriced++
+	}
+	return repriced, nil
 }
 
-// Delete 删除工序
-func (s *ProcedureService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+// validateProcedureTimes 校验标准工时、费率和工价
+func validateProcedureTimes(sam, baseRate, unitPrice float64) error {
+	if sam < 0 {
+		return fmt.Errorf("标准工时不能为负数")
+	}
+	if baseRate < 0 {
+		return fmt.Errorf("工序费率不能为负数")
+	}
+	if unitPrice < 0 {
+		return fmt.Errorf("默认工价不能为负数")
+	}
+	return nil
 }
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mule-cloud/app/basic/dto"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+	"sort"
+	"strings"
+)
+
+// IProcedureTemplateService 工序模板服务接口
+type IProcedureTemplateService interface {
+	Get(ctx context.Context, id string) (*models.ProcedureTemplate, error)
+	GetAll(ctx context.Context, req dto.ProcedureTemplateListRequest) ([]*models.ProcedureTemplate, error)
+	List(ctx context.Context, req dto.ProcedureTemplateListRequest) ([]*models.ProcedureTemplate, int64, error)
+	Create(ctx context.Context, req dto.ProcedureTemplateCreateRequest) (*models.ProcedureTemplate, error)
+	Update(ctx context.Context, req dto.ProcedureTemplateUpdateRequest) (*models.ProcedureTemplate, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// ProcedureTemplateService 工序模板服务实现
+type ProcedureTemplateService struct {
+	repo          repository.MasterDataRepository[models.ProcedureTemplate]
+	procedureRepo repository.MasterDataRepository[models.Procedure]
+}
+
+// NewProcedureTemplateService 创建工序模板服务
+func NewProcedureTemplateService() IProcedureTemplateService {
+	return &ProcedureTemplateService{
+		repo:          repository.NewProcedureTemplateRepository(),
+		procedureRepo: repository.NewProcedureRepository(),
+	}
+}
+
+// Get 获取工序模板
+func (s *ProcedureTemplateService) Get(ctx context.Context, id string) (*models.ProcedureTemplate, error) {
+	template, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, notFound("工序模板", err)
+	}
+	return template, nil
+}
+
+// List 列表（分页查询）
+func (s *ProcedureTemplateService) List(ctx context.Context, req dto.ProcedureTemplateListRequest) ([]*models.ProcedureTemplate, int64, error) {
+	page, pageSize := pageOf(req.Page, req.PageSize)
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	if req.Category != "" {
+		filter["category"] = req.Category
+	}
+	return s.repo.List(ctx, filter, page, pageSize, byCreatedDesc)
+}
+
+// GetAll 获取所有工序模板（不分页）
+func (s *ProcedureTemplateService) GetAll(ctx context.Context, req dto.ProcedureTemplateListRequest) ([]*models.ProcedureTemplate, error) {
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	if req.Category != "" {
+		filter["category"] = req.Category
+	}
+	templates, _, err := s.repo.List(ctx, filter, 0, 0, byCreatedDesc)
+	return templates, err
+}
+
+// Create 创建工序模板
+func (s *ProcedureTemplateService) Create(ctx context.Context, req dto.ProcedureTemplateCreateRequest) (*models.ProcedureTemplate, error) {
+	if err := validateMasterData("工序模板", req.Value, req.Status); err != nil {
+		return nil, err
+	}
+	if err := checkUnique(ctx, s.repo, "工序模板", "", req.Value, &req.Code); err != nil {
+		return nil, err
+	}
+	items, err := s.normalizeItems(ctx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &models.ProcedureTemplate{
+		MasterData: newMasterData(ctx, "procedure_template", req.Code, req.Value, req.Remark, req.Status),
+		Category:   strings.TrimSpace(req.Category),
+		Items:      items,
+	}
+
+	if err := s.repo.Create(ctx, template); err != nil {
+		return nil, writeErr("工序模板", err)
+	}
+	return template, nil
+}
+
+// Update 更新工序模板
+func (s *ProcedureTemplateService) Update(ctx context.Context, req dto.ProcedureTemplateUpdateRequest) (*models.ProcedureTemplate, error) {
+	if err := validateMasterData("工序模板", req.Value, req.Status); err != nil {
+		return nil, err
+	}
+	if err := checkUnique(ctx, s.repo, "工序模板", req.ID, req.Value, req.Code); err != nil {
+		return nil, err
+	}
+
+	update := masterDataUpdate(ctx, req.Code, req.Value, req.Remark, req.Status)
+	if req.Category != nil {
+		update["category"] = strings.TrimSpace(*req.Category)
+	}
+	if req.Items != nil {
+		items, err := s.normalizeItems(ctx, *req.Items)
+		if err != nil {
+			return nil, err
+		}
+		update["items"] = items
+	}
+
+	if err := s.repo.Update(ctx, req.ID, update); err != nil {
+		return nil, writeErr("工序模板", err)
+	}
+
+	// 返回更新后的数据
+	return s.Get(ctx, req.ID)
+}
+
+// Delete 删除工序模板
+func (s *ProcedureTemplateService) Delete(ctx context.Context, id string) error {
+	return notFound("工序模板", s.repo.Delete(ctx, id))
+}
+
+// normalizeItems 校验模板工序：必须来自工序库、不能重复、有且只有一个最终工序，按顺序排列
+func (s *ProcedureTemplateService) normalizeItems(ctx context.Context, items []models.ProcedureTemplateItem) ([]models.ProcedureTemplateItem, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("工序列表不能为空")
+	}
+
+	result := make([]models.ProcedureTemplateItem, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	finalCount := 0
+	for i, item := range items {
+		if item.ProcedureID == "" {
+			return nil, fmt.Errorf("第%d个工序未从工序库选择", i+1)
+		}
+		if seen[item.ProcedureID] {
+			return nil, fmt.Errorf("工序[%s]重复", item.ProcedureName)
+		}
+		seen[item.ProcedureID] = true
+
+		procedure, err := s.procedureRepo.Get(ctx, item.ProcedureID)
+		if err != nil {
+			return nil, notFound(fmt.Sprintf("第%d个工序", i+1), err)
+		}
+		item.ProcedureName = procedure.Value
+		if item.Sequence <= 0 {
+			item.Sequence = i + 1
+		}
+		if item.IsSlowest {
+			finalCount++
+		}
+		result = append(result, item)
+	}
+	if finalCount != 1 {
+		return nil, fmt.Errorf("必须且只能选择一个最终工序，当前选择了%d个", finalCount)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Sequence < result[j].Sequence })
+	return result, nil
+}
@@ -2,150 +2,140 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"mule-cloud/app/basic/dto"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
-	"time"
-
-	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"net/mail"
+	"strings"
 )
 
 // ISalesmanService 业务员服务接口
 type ISalesmanService interface {
-	Get(ctx context.Context, id string) (*models.Basic, error)
-	GetAll(ctx context.Context, req dto.SalesmanListRequest) ([]models.Basic, error)
-	List(ctx context.Context, req dto.SalesmanListRequest) ([]models.Basic, int64, error)
-	Create(ctx context.Context, req dto.SalesmanCreateRequest) (*models.Basic, error)
-	Update(ctx context.Context, req dto.SalesmanUpdateRequest) (*models.Basic, error)
+	Get(ctx context.Context, id string) (*models.Salesman, error)
+	GetAll(ctx context.Context, req dto.SalesmanListRequest) ([]*models.Salesman, error)
+	List(ctx context.Context, req dto.SalesmanListRequest) ([]*models.Salesman, int64, error)
+	Create(ctx context.Context, req dto.SalesmanCreateRequest) (*models.Salesman, error)
+	Update(ctx context.Context, req dto.SalesmanUpdateRequest) (*models.Salesman, error)
 	Delete(ctx context.Context, id string) error
 }
 
 // SalesmanService 业务员服务实现
 type SalesmanService struct {
-	repo repository.BasicRepository
+	repo repository.MasterDataRepository[models.Salesman]
 }
 
 // NewSalesmanService 创建业务员服务
 func NewSalesmanService() ISalesmanService {
-	repo := repository.NewBasicRepository()
-	return &SalesmanService{repo: repo}
+	return &SalesmanService{repo: repository.NewSalesmanRepository()}
 }
 
 // Get 获取业务员
-func (s *SalesmanService) Get(ctx context.Context, id string) (*models.Basic, error) {
-	return s.repo.Get(ctx, id)
+func (s *SalesmanService) Get(ctx context.Context, id string) (*models.Salesman, error) {
+	salesman, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, notFound("业务员", err)
+	}
+	return salesman, nil
 }
 
 // List 列表（分页查询）
-func (s *SalesmanService) List(ctx context.Context, req dto.SalesmanListRequest) ([]models.Basic, int64, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "salesman", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
-	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
-	}
-	// 获取总数
-	total, err := s.repo.Count(ctx, filter)
-	if err != nil {
-		return nil, 0, err
-	}
+func (s *SalesmanService) List(ctx context.Context, req dto.SalesmanListRequest) ([]*models.Salesman, int64, error) {
+	page, pageSize := pageOf(req.Page, req.PageSize)
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	return s.repo.List(ctx, filter, page, pageSize, byCreatedDesc)
+}
 
-	// 分页查询
-	offset := (req.Page - 1) * req.PageSize
-	opts := options.Find().
-		SetSkip(offset).
-		SetLimit(req.PageSize).
-		SetSort(bson.M{"created_at": -1})
+// GetAll 获取所有业务员（不分页）
+func (s *SalesmanService) GetAll(ctx context.Context, req dto.SalesmanListRequest) ([]*models.Salesman, error) {
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	salesmans, _, err := s.repo.List(ctx, filter, 0, 0, byCreatedDesc)
+	return salesmans, err
+}
 
-	// 使用 GetCollection 获取原始集合以使用 options
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
-	if err != nil {
-		return nil, 0, err
+// Create 创建业务员
+func (s *SalesmanService) Create(ctx context.Context, req dto.SalesmanCreateRequest) (*models.Salesman, error) {
+	if err := validateMasterData("业务员", req.Value, req.Status); err != nil {
+		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	salesmans := []models.Basic{}
-	err = cursor.All(ctx, &salesmans)
-	if err != nil {
-		return nil, 0, err
+	if err := checkUnique(ctx, s.repo, "业务员", "", req.Value, &req.Code); err != nil {
+		return nil, err
 	}
-
-	return salesmans, total, nil
-}
-
-// GetAll 获取所有业务员（不分页）
-func (s *SalesmanService) GetAll(ctx context.Context, req dto.SalesmanListRequest) ([]models.Basic, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "salesman", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
-	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
-	}
-	// 使用 GetCollection 获取原始集合以使用排序选项
-	opts := options.Find().SetSort(bson.M{"created_at": -1})
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
-	if err != nil {
+	if err := validateSalesman(req.Phone, req.Email, req.CommissionRate); err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	salesmans := []models.Basic{}
-	err = cursor.All(ctx, &salesmans)
-	if err != nil {
-		return nil, err
+	salesman := &models.Salesman{
+		MasterData:     newMasterData(ctx, "salesman", req.Code, req.Value, req.Remark, req.Status),
+		Phone:          strings.TrimSpace(req.Phone),
+		Email:          strings.TrimSpace(req.Email),
+		AdminID:        req.AdminID,
+		CommissionRate: req.CommissionRate,
 	}
 
-	return salesmans, nil
+	if err := s.repo.Create(ctx, salesman); err != nil {
+		return nil, writeErr("业务员", err)
+	}
+	return salesman, nil
 }
 
-// Create 创建业务员
-func (s *SalesmanService) Create(ctx context.Context, req dto.SalesmanCreateRequest) (*models.Basic, error) {
-	now := time.Now().Unix()
-
-	basic := &models.Basic{
-		Name:      "salesman",
-		Value:     req.Value,
-		Remark:    req.Remark,
-		CreatedAt: now,
-		UpdatedAt: now,
+// Update 更新业务员
+func (s *SalesmanService) Update(ctx context.Context, req dto.SalesmanUpdateRequest) (*models.Salesman, error) {
+	if err := validateMasterData("业务员", req.Value, req.Status); err != nil {
+		return nil, err
 	}
-
-	err := s.repo.Create(ctx, basic)
-	if err != nil {
+	if err := checkUnique(ctx, s.repo, "业务员", req.ID, req.Value, req.Code); err != nil {
 		return nil, err
 	}
 
-	return basic, nil
-}
-
-// Update 更新业务员
-func (s *SalesmanService) Update(ctx context.Context, req dto.SalesmanUpdateRequest) (*models.Basic, error) {
-	// 更新字段
-	update := bson.M{
-		"value":      req.Value,
-		"remark":     req.Remark,
-		"updated_at": time.Now().Unix(),
+	update := masterDataUpdate(ctx, req.Code, req.Value, req.Remark, req.Status)
+	if req.Phone != nil {
+		if err := validateSalesman(*req.Phone, "", 0); err != nil {
+			return nil, err
+		}
+		update["phone"] = strings.TrimSpace(*req.Phone)
+	}
+	if req.Email != nil {
+		if err := validateSalesman("", *req.Email, 0); err != nil {
+			return nil, err
+		}
+		update["email"] = strings.TrimSpace(*req.Email)
+	}
+	if req.AdminID != nil {
+		update["admin_id"] = *req.AdminID
+	}
+	if req.CommissionRate != nil {
+		if err := validateSalesman("", "", *req.CommissionRate); err != nil {
+			return nil, err
+		}
+		update["commission_rate"] = *req.CommissionRate
 	}
 
-	err := s.repo.Update(ctx, req.ID, update)
-	if err != nil {
-		return nil, err
+	if err := s.repo.Update(ctx, req.ID, update); err != nil {
+		return nil, writeErr("业务员", err)
 	}
 
 	// 返回更新后的数据
-	return s.repo.Get(ctx, req.ID)
+	return s.Get(ctx, req.ID)
 }
 
 // Delete 删除业务员
 func (s *SalesmanService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	return notFound("业务员", s.repo.Delete(ctx, id))
+}
+
+// validateSalesman 校验业务员联系方式和提成比例
+func validateSalesman(phone, email string, commissionRate float64) error {
+	if phone = strings.TrimSpace(phone); phone != "" && !phonePattern.MatchString(phone) {
+		return fmt.Errorf("电话格式不正确")
+	}
+	if email = strings.TrimSpace(email); email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return fmt.Errorf("邮箱格式不正确")
+		}
+	}
+	if commissionRate < 0 || commissionRate > 100 {
+		return fmt.Errorf("提成比例必须在0-100之间")
+	}
+	return nil
 }
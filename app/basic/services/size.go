@@ -5,148 +5,110 @@ import (
 	"mule-cloud/app/basic/dto"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
-	"time"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // ISizeService 尺寸服务接口
 type ISizeService interface {
-	Get(ctx context.Context, id string) (*models.Basic, error)
-	GetAll(ctx context.Context, req dto.SizeListRequest) ([]*models.Basic, error)
-	List(ctx context.Context, req dto.SizeListRequest) ([]*models.Basic, int64, error)
-	Create(ctx context.Context, req dto.SizeCreateRequest) (*models.Basic, error)
-	Update(ctx context.Context, req dto.SizeUpdateRequest) (*models.Basic, error)
+	Get(ctx context.Context, id string) (*models.Size, error)
+	GetAll(ctx context.Context, req dto.SizeListRequest) ([]*models.Size, error)
+	List(ctx context.Context, req dto.SizeListRequest) ([]*models.Size, int64, error)
+	Create(ctx context.Context, req dto.SizeCreateRequest) (*models.Size, error)
+	Update(ctx context.Context, req dto.SizeUpdateRequest) (*models.Size, error)
 	Delete(ctx context.Context, id string) error
 }
 
 // SizeService 尺寸服务实现
 type SizeService struct {
-	repo repository.BasicRepository
+	repo repository.MasterDataRepository[models.Size]
 }
 
 // NewSizeService 创建尺寸服务
 func NewSizeService() ISizeService {
-	repo := repository.NewBasicRepository()
-	return &SizeService{repo: repo}
+	return &SizeService{repo: repository.NewSizeRepository()}
 }
 
 // Get 获取尺寸
-func (s *SizeService) Get(ctx context.Context, id string) (*models.Basic, error) {
-	return s.repo.Get(ctx, id)
-}
-
-// GetAll 获取所有尺寸（不分页）
-func (s *SizeService) GetAll(ctx context.Context, req dto.SizeListRequest) ([]*models.Basic, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "size", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
-	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
-	}
-
-	// 使用 GetCollection 获取原始集合以使用排序选项
-	opts := options.Find().SetSort(bson.M{"created_at": 1}) // 按创建时间正序
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
+func (s *SizeService) Get(ctx context.Context, id string) (*models.Size, error) {
+	size, err := s.repo.Get(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, notFound("尺寸", err)
 	}
-	defer cursor.Close(ctx)
+	return size, nil
+}
 
-	var sizes []*models.Basic
-	err = cursor.All(ctx, &sizes)
-	if err != nil {
-		return nil, err
+// List 列表（分页查询）
+func (s *SizeService) List(ctx context.Context, req dto.SizeListRequest) ([]*models.Size, int64, error) {
+	page, pageSize := pageOf(req.Page, req.PageSize)
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	if req.Group != "" {
+		filter["group"] = req.Group
 	}
-
-	return sizes, nil
+	return s.repo.List(ctx, filter, page, pageSize, bySizeOrder)
 }
 
-// List 列表（分页查询）
-func (s *SizeService) List(ctx context.Context, req dto.SizeListRequest) ([]*models.Basic, int64, error) {
-	// 构建过滤条件
-	filter := bson.M{"name": "size", "is_deleted": 0}
-	if req.Value != "" {
-		// 支持模糊搜索
-		filter["value"] = bson.M{"$regex": req.Value, "$options": "i"}
+// GetAll 获取所有尺寸（不分页）
+func (s *SizeService) GetAll(ctx context.Context, req dto.SizeListRequest) ([]*models.Size, error) {
+	filter := masterDataFilter(req.ID, req.Value, req.Code, req.Status)
+	if req.Group != "" {
+		filter["group"] = req.Group
 	}
-	if req.ID != "" {
-		filter["_id"] = req.ID
+	sizes, _, err := s.repo.List(ctx, filter, 0, 0, bySizeOrder)
+	return sizes, err
+}
+
+// Create 创建尺寸
+func (s *SizeService) Create(ctx context.Context, req dto.SizeCreateRequest) (*models.Size, error) {
+	if err := validateMasterData("尺寸", req.Value, req.Status); err != nil {
+		return nil, err
 	}
-	// 获取总数
-	total, err := s.repo.Count(ctx, filter)
-	if err != nil {
-		return nil, 0, err
+	if err := checkUnique(ctx, s.repo, "尺寸", "", req.Value, &req.Code); err != nil {
+		return nil, err
 	}
 
-	// 分页查询
-	offset := (req.Page - 1) * req.PageSize
-	opts := options.Find().
-		SetSkip(offset).
-		SetLimit(req.PageSize).
-		SetSort(bson.M{"created_at": 1})
-
-	// 使用 GetCollection 获取原始集合以使用 options
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
-	if err != nil {
-		return nil, 0, err
+	size := &models.Size{
+		MasterData: newMasterData(ctx, "size", req.Code, req.Value, req.Remark, req.Status),
+		Group:      strings.TrimSpace(req.Group),
+		Sort:       req.Sort,
 	}
-	defer cursor.Close(ctx)
 
-	var sizes []*models.Basic
-	err = cursor.All(ctx, &sizes)
-	if err != nil {
-		return nil, 0, err
+	if err := s.repo.Create(ctx, size); err != nil {
+		return nil, writeErr("尺寸", err)
 	}
-
-	return sizes, total, nil
+	return size, nil
 }
 
-// Create 创建尺寸
-func (s *SizeService) Create(ctx context.Context, req dto.SizeCreateRequest) (*models.Basic, error) {
-	now := time.Now().Unix()
-
-	basic := &models.Basic{
-		Name:      "size",
-		Value:     req.Value,
-		Remark:    req.Remark,
-		CreatedAt: now,
-		UpdatedAt: now,
+// Update 更新尺寸
+func (s *SizeService) Update(ctx context.Context, req dto.SizeUpdateRequest) (*models.Size, error) {
+	if err := validateMasterData("尺寸", req.Value, req.Status); err != nil {
+		return nil, err
 	}
-
-	err := s.repo.Create(ctx, basic)
-	if err != nil {
+	if err := checkUnique(ctx, s.repo, "尺寸", req.ID, req.Value, req.Code); err != nil {
 		return nil, err
 	}
 
-	return basic, nil
-}
-
-// Update 更新尺寸
-func (s *SizeService) Update(ctx context.Context, req dto.SizeUpdateRequest) (*models.Basic, error) {
-	// 更新字段
-	update := bson.M{
-		"value":      req.Value,
-		"remark":     req.Remark,
-		"updated_at": time.Now().Unix(),
+	update := masterDataUpdate(ctx, req.Code, req.Value, req.Remark, req.Status)
+	if req.Group != nil {
+		update["group"] = strings.TrimSpace(*req.Group)
+	}
+	if req.Sort != nil {
+		update["sort"] = *req.Sort
 	}
 
-	err := s.repo.Update(ctx, req.ID, update)
-	if err != nil {
-		return nil, err
+	if err := s.repo.Update(ctx, req.ID, update); err != nil {
+		return nil, writeErr("尺寸", err)
 	}
 
 	// 返回更新后的数据
-	return s.repo.Get(ctx, req.ID)
+	return s.Get(ctx, req.ID)
 }
 
 // Delete 删除尺寸
 func (s *SizeService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	return notFound("尺寸", s.repo.Delete(ctx, id))
 }
+
+// bySizeOrder 尺寸按排序号、创建时间正序
+var bySizeOrder = bson.D{{Key: "sort", Value: 1}, {Key: "created_at", Value: 1}}
@@ -0,0 +1,60 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package transport
+
+import (
+	"mule-cloud/app/basic/dto"
+	"mule-cloud/core/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operations 处理器的接口描述（cmd/openapi-gen 生成，服务的 /openapi.json 使用）
+var Operations = openapi.Operations{
+	"mule-cloud/app/basic/transport.GetColorHandler":                 {Summary: "获取颜色", Tag: "color", Request: dto.ColorListRequest{}, Response: dto.ColorResponse{}},
+	"mule-cloud/app/basic/transport.GetAllColorsHandler":             {Summary: "获取所有颜色（不分页）", Tag: "color", Request: dto.ColorListRequest{}, Response: dto.ColorListResponse{}},
+	"mule-cloud/app/basic/transport.ListColorsHandler":               {Summary: "颜色列表（分页）", Tag: "color", Request: dto.ColorListRequest{}, Response: dto.ColorListResponse{}},
+	"mule-cloud/app/basic/transport.CreateColorHandler":              {Summary: "创建颜色", Tag: "color", Request: dto.ColorCreateRequest{}, Response: dto.ColorResponse{}},
+	"mule-cloud/app/basic/transport.UpdateColorHandler":              {Summary: "更新颜色", Tag: "color", Request: dto.ColorUpdateRequest{}, Response: dto.ColorResponse{}},
+	"mule-cloud/app/basic/transport.DeleteColorHandler":              {Summary: "删除颜色", Tag: "color", Request: dto.ColorListRequest{}, Response: map[string]string{}},
+	"mule-cloud/app/basic/transport.HealthHandler":                   {Summary: "", Tag: "common", Response: gin.H{}, Raw: true},
+	"mule-cloud/app/basic/transport.GetCustomerHandler":              {Summary: "获取客户", Tag: "customer", Request: dto.CustomerListRequest{}, Response: dto.CustomerResponse{}},
+	"mule-cloud/app/basic/transport.GetAllCustomersHandler":          {Summary: "获取所有客户（不分页）", Tag: "customer", Request: dto.CustomerListRequest{}, Response: dto.CustomerListResponse{}},
+	"mule-cloud/app/basic/transport.ListCustomersHandler":            {Summary: "客户列表（分页）", Tag: "customer", Request: dto.CustomerListRequest{}, Response: dto.CustomerListResponse{}},
+	"mule-cloud/app/basic/transport.CreateCustomerHandler":           {Summary: "创建客户", Tag: "customer", Request: dto.CustomerCreateRequest{}, Response: dto.CustomerResponse{}},
+	"mule-cloud/app/basic/transport.UpdateCustomerHandler":           {Summary: "更新客户", Tag: "customer", Request: dto.CustomerUpdateRequest{}, Response: dto.CustomerResponse{}},
+	"mule-cloud/app/basic/transport.DeleteCustomerHandler":           {Summary: "删除客户", Tag: "customer", Request: dto.CustomerListRequest{}, Response: map[string]string{}},
+	"mule-cloud/app/basic/transport.GetOrderTypeHandler":             {Summary: "获取订单类型", Tag: "order_type", Request: dto.OrderTypeListRequest{}, Response: dto.OrderTypeResponse{}},
+	"mule-cloud/app/basic/transport.GetAllOrderTypesHandler":         {Summary: "获取所有订单类型（不分页）", Tag: "order_type", Request: dto.OrderTypeListRequest{}, Response: dto.OrderTypeListResponse{}},
+	"mule-cloud/app/basic/transport.ListOrderTypesHandler":           {Summary: "订单类型列表（分页）", Tag: "order_type", Request: dto.OrderTypeListRequest{}, Response: dto.OrderTypeListResponse{}},
+	"mule-cloud/app/basic/transport.CreateOrderTypeHandler":          {Summary: "创建订单类型", Tag: "order_type", Request: dto.OrderTypeCreateRequest{}, Response: dto.OrderTypeResponse{}},
+	"mule-cloud/app/basic/transport.UpdateOrderTypeHandler":          {Summary: "更新订单类型", Tag: "order_type", Request: dto.OrderTypeUpdateRequest{}, Response: dto.OrderTypeResponse{}},
+	"mule-cloud/app/basic/transport.DeleteOrderTypeHandler":          {Summary: "删除订单类型", Tag: "order_type", Request: dto.OrderTypeListRequest{}, Response: map[string]string{}},
+	"mule-cloud/app/basic/transport.GetProcedureHandler":             {Summary: "获取工序", Tag: "procedure", Request: dto.ProcedureListRequest{}, Response: dto.ProcedureResponse{}},
+	"mule-cloud/app/basic/transport.GetAllProceduresHandler":         {Summary: "获取所有工序（不分页）", Tag: "procedure", Request: dto.ProcedureListRequest{}, Response: dto.ProcedureListResponse{}},
+	"mule-cloud/app/basic/transport.ListProceduresHandler":           {Summary: "工序列表（分页）", Tag: "procedure", Request: dto.ProcedureListRequest{}, Response: dto.ProcedureListResponse{}},
+	"mule-cloud/app/basic/transport.CreateProcedureHandler":          {Summary: "创建工序", Tag: "procedure", Request: dto.ProcedureCreateRequest{}, Response: dto.ProcedureResponse{}},
+	"mule-cloud/app/basic/transport.UpdateProcedureHandler":          {Summary: "更新工序", Tag: "procedure", Request: dto.ProcedureUpdateRequest{}, Response: dto.ProcedureResponse{}},
+	"mule-cloud/app/basic/transport.DeleteProcedureHandler":          {Summary: "删除工序", Tag: "procedure", Request: dto.ProcedureListRequest{}, Response: map[string]string{}},
+	"mule-cloud/app/basic/transport.GetPricingHandler":               {Summary: "获取工价设置", Tag: "procedure", Response: dto.PricingResponse{}},
+	"mule-cloud/app/basic/transport.UpdatePricingHandler":            {Summary: "更新工价设置", Tag: "procedure", Request: dto.PricingUpdateRequest{}, Response: dto.PricingResponse{}},
+	"mule-cloud/app/basic/transport.RepriceProceduresHandler":        {Summary: "重算工序库工价", Tag: "procedure", Response: dto.PricingResponse{}},
+	"mule-cloud/app/basic/transport.GetProcedureTemplateHandler":     {Summary: "获取工序模板", Tag: "procedure_template", Request: dto.ProcedureTemplateListRequest{}, Response: dto.ProcedureTemplateResponse{}},
+	"mule-cloud/app/basic/transport.GetAllProcedureTemplatesHandler": {Summary: "获取所有工序模板（不分页）", Tag: "procedure_template", Request: dto.ProcedureTemplateListRequest{}, Response: dto.ProcedureTemplateListResponse{}},
+	"mule-cloud/app/basic/transport.ListProcedureTemplatesHandler":   {Summary: "工序模板列表（分页）", Tag: "procedure_template", Request: dto.ProcedureTemplateListRequest{}, Response: dto.ProcedureTemplateListResponse{}},
+	"mule-cloud/app/basic/transport.CreateProcedureTemplateHandler":  {Summary: "创建工序模板", Tag: "procedure_template", Request: dto.ProcedureTemplateCreateRequest{}, Response: dto.ProcedureTemplateResponse{}},
+	"mule-cloud/app/basic/transport.UpdateProcedureTemplateHandler":  {Summary: "更新工序模板", Tag: "procedure_template", Request: dto.ProcedureTemplateUpdateRequest{}, Response: dto.ProcedureTemplateResponse{}},
+	"mule-cloud/app/basic/transport.DeleteProcedureTemplateHandler":  {Summary: "删除工序模板", Tag: "procedure_template", Request: dto.ProcedureTemplateListRequest{}, Response: map[string]string{}},
+	"mule-cloud/app/basic/transport.GetSalesmanHandler":              {Summary: "获取业务员", Tag: "salesman", Request: dto.SalesmanListRequest{}, Response: dto.SalesmanResponse{}},
+	"mule-cloud/app/basic/transport.GetAllSalesmansHandler":          {Summary: "获取所有业务员（不分页）", Tag: "salesman", Request: dto.SalesmanListRequest{}, Response: dto.SalesmanListResponse{}},
+	"mule-cloud/app/basic/transport.ListSalesmansHandler":            {Summary: "业务员列表（分页）", Tag: "salesman", Request: dto.SalesmanListRequest{}, Response: dto.SalesmanListResponse{}},
+	"mule-cloud/app/basic/transport.CreateSalesmanHandler":           {Summary: "创建业务员", Tag: "salesman", Request: dto.SalesmanCreateRequest{}, Response: dto.SalesmanResponse{}},
+	"mule-cloud/app/basic/transport.UpdateSalesmanHandler":           {Summary: "更新业务员", Tag: "salesman", Request: dto.SalesmanUpdateRequest{}, Response: dto.SalesmanResponse{}},
+	"mule-cloud/app/basic/transport.DeleteSalesmanHandler":           {Summary: "删除业务员", Tag: "salesman", Request: dto.SalesmanListRequest{}, Response: map[string]string{}},
+	"mule-cloud/app/basic/transport.GetSizeHandler":                  {Summary: "获取尺寸", Tag: "size", Request: dto.SizeGetRequest{}, Response: dto.SizeResponse{}},
+	"mule-cloud/app/basic/transport.GetAllSizesHandler":              {Summary: "获取所有尺寸（不分页）", Tag: "size", Request: dto.SizeListRequest{}, Response: dto.SizeListResponse{}},
+	"mule-cloud/app/basic/transport.ListSizesHandler":                {Summary: "尺寸列表（分页）", Tag: "size", Request: dto.SizeListRequest{}, Response: dto.SizeListResponse{}},
+	"mule-cloud/app/basic/transport.CreateSizeHandler":               {Summary: "创建尺寸", Tag: "size", Request: dto.SizeCreateRequest{}, Response: dto.SizeResponse{}},
+	"mule-cloud/app/basic/transport.UpdateSizeHandler":               {Summary: "更新尺寸", Tag: "size", Request: dto.SizeUpdateRequest{}, Response: dto.SizeResponse{}},
+	"mule-cloud/app/basic/transport.DeleteSizeHandler":               {Summary: "删除尺寸", Tag: "size", Request: dto.SizeGetRequest{}, Response: map[string]string{}},
+}
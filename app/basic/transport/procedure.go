@@ -130,3 +130,51 @@ func DeleteProcedureHandler(svc services.IProcedureService) gin.HandlerFunc {
 		response.Success(c, resp)
 	}
 }
+
+// GetPricingHandler 获取工价设置处理器
+func GetPricingHandler(svc services.IProcedureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ep := endpoint.GetPricingEndpoint(svc)
+		resp, err := ep(c.Request.Context(), nil)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// UpdatePricingHandler 更新工价设置处理器
+func UpdatePricingHandler(svc services.IProcedureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.PricingUpdateRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.UpdatePricingEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// RepriceProceduresHandler 重算工序库工价处理器
+func RepriceProceduresHandler(svc services.IProcedureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ep := endpoint.RepriceProceduresEndpoint(svc)
+		resp, err := ep(c.Request.Context(), nil)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
@@ -0,0 +1,132 @@
+package transport
+
+import (
+	"mule-cloud/app/basic/dto"
+	"mule-cloud/app/basic/endpoint"
+	"mule-cloud/app/basic/services"
+	"mule-cloud/core/binding"
+	"mule-cloud/core/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProcedureTemplateHandler 获取工序模板处理器
+func GetProcedureTemplateHandler(svc services.IProcedureTemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ProcedureTemplateListRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.GetProcedureTemplateEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetAllProcedureTemplatesHandler 获取所有工序模板处理器（不分页）
+func GetAllProcedureTemplatesHandler(svc services.IProcedureTemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ProcedureTemplateListRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.GetAllProcedureTemplatesEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ListProcedureTemplatesHandler 工序模板列表处理器（分页）
+func ListProcedureTemplatesHandler(svc services.IProcedureTemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ProcedureTemplateListRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.ListProcedureTemplatesEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// CreateProcedureTemplateHandler 创建工序模板处理器
+func CreateProcedureTemplateHandler(svc services.IProcedureTemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ProcedureTemplateCreateRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.CreateProcedureTemplateEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// UpdateProcedureTemplateHandler 更新工序模板处理器
+func UpdateProcedureTemplateHandler(svc services.IProcedureTemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ProcedureTemplateUpdateRequest
+		// 先绑定 JSON body（包含 required 字段）
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.UpdateProcedureTemplateEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// DeleteProcedureTemplateHandler 删除工序模板处理器
+func DeleteProcedureTemplateHandler(svc services.IProcedureTemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ProcedureTemplateListRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.DeleteProcedureTemplateEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"mule-cloud/core/quota"
 	"mule-cloud/core/storage"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
@@ -69,6 +70,11 @@ func (s *fileService) Upload(ctx context.Context, tenantCode, uploadBy, business
 		return nil, fmt.Errorf("不支持的文件类型: %s", fileExt)
 	}
 
+	// 检查套餐的存储空间配额
+	if err := quota.Check(ctx, tenantCode, quota.StorageBytes, fileSize); err != nil {
+		return nil, err
+	}
+
 	// 生成存储键（路径）
 	// 格式: tenant/business_type/yyyy/mm/dd/uuid.ext
 	now := time.Now()
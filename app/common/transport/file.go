@@ -1,9 +1,12 @@
 package transport
 
 import (
+	"errors"
 	"mule-cloud/app/common/dto"
 	"mule-cloud/app/common/services"
 	"mule-cloud/core/context"
+	"mule-cloud/core/logger"
+	"mule-cloud/core/quota"
 	"mule-cloud/core/response"
 	"net/http"
 	"strconv"
@@ -16,14 +19,14 @@ import (
 // FileTransport 文件传输层
 type FileTransport struct {
 	fileService services.FileService
-	logger      *zap.Logger
+	log         *logger.Module
 }
 
 // NewFileTransport 创建文件传输层实例
-func NewFileTransport(fileService services.FileService, logger *zap.Logger) *FileTransport {
+func NewFileTransport(fileService services.FileService, log *logger.Module) *FileTransport {
 	return &FileTransport{
 		fileService: fileService,
-		logger:      logger,
+		log:         log,
 	}
 }
 
@@ -60,8 +63,12 @@ func (t *FileTransport) UploadHandler() gin.HandlerFunc {
 
 		// 调用服务上传文件
 		fileInfo, err := t.fileService.Upload(c.Request.Context(), tenantCode, uploadBy, req.BusinessType, fileHeader)
+		if errors.Is(err, quota.ErrQuotaExceeded) || errors.Is(err, quota.ErrSubscriptionExpired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
-			t.logger.Error("上传文件失败", zap.Error(err))
+			t.log.Ctx(c.Request.Context()).Error("上传文件失败", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "上传文件失败: " + err.Error()})
 			return
 		}
@@ -90,7 +97,7 @@ func (t *FileTransport) DownloadHandler() gin.HandlerFunc {
 		// 调用服务下载文件
 		reader, fileInfo, err := t.fileService.Download(c.Request.Context(), tenantCode, fileID)
 		if err != nil {
-			t.logger.Error("下载文件失败", zap.Error(err))
+			t.log.Ctx(c.Request.Context()).Error("下载文件失败", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "下载文件失败: " + err.Error()})
 			return
 		}
@@ -126,7 +133,7 @@ func (t *FileTransport) DeleteHandler() gin.HandlerFunc {
 		// 调用服务删除文件
 		err := t.fileService.Delete(c.Request.Context(), tenantCode, fileID)
 		if err != nil {
-			t.logger.Error("删除文件失败", zap.Error(err))
+			t.log.Ctx(c.Request.Context()).Error("删除文件失败", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "删除文件失败: " + err.Error()})
 			return
 		}
@@ -155,7 +162,7 @@ func (t *FileTransport) ListHandler() gin.HandlerFunc {
 		// 调用服务获取列表
 		files, total, err := t.fileService.List(c.Request.Context(), tenantCode, req.Page, req.PageSize, req.BusinessType)
 		if err != nil {
-			t.logger.Error("获取文件列表失败", zap.Error(err))
+			t.log.Ctx(c.Request.Context()).Error("获取文件列表失败", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文件列表失败: " + err.Error()})
 			return
 		}
@@ -205,7 +212,7 @@ func (t *FileTransport) GetPresignedURLHandler() gin.HandlerFunc {
 		// 调用服务获取预签名URL
 		url, err := t.fileService.GetPresignedURL(c.Request.Context(), tenantCode, fileID, req.ExpireSeconds)
 		if err != nil {
-			t.logger.Error("获取预签名URL失败", zap.Error(err))
+			t.log.Ctx(c.Request.Context()).Error("获取预签名URL失败", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取预签名URL失败: " + err.Error()})
 			return
 		}
@@ -0,0 +1,19 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package transport
+
+import (
+	"mule-cloud/app/common/dto"
+	"mule-cloud/core/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operations 处理器的接口描述（cmd/openapi-gen 生成，服务的 /openapi.json 使用）
+var Operations = openapi.Operations{
+	"mule-cloud/app/common/transport.(*FileTransport).UploadHandler":          {Summary: "上传文件", Tag: "file", Request: dto.UploadRequest{}, Bind: openapi.FromQuery | openapi.FromBody, Files: []string{"file"}, Response: dto.UploadResponse{}},
+	"mule-cloud/app/common/transport.(*FileTransport).DownloadHandler":        {Summary: "下载文件", Tag: "file", Binary: true},
+	"mule-cloud/app/common/transport.(*FileTransport).DeleteHandler":          {Summary: "删除文件", Tag: "file", Response: gin.H{}},
+	"mule-cloud/app/common/transport.(*FileTransport).ListHandler":            {Summary: "获取文件列表", Tag: "file", Request: dto.FileListRequest{}, Bind: openapi.FromQuery, Response: dto.FileListResponse{}},
+	"mule-cloud/app/common/transport.(*FileTransport).GetPresignedURLHandler": {Summary: "获取预签名URL", Tag: "file", Request: dto.PresignedURLRequest{}, Bind: openapi.FromQuery, Response: dto.PresignedURLResponse{}},
+}
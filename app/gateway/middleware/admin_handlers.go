@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"mule-cloud/core/response"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -9,12 +10,14 @@ import (
 // AdminHandlers 管理API处理器
 type AdminHandlers struct {
 	routeManager *DynamicRouteManager
+	resolve      InstanceResolver
 }
 
 // NewAdminHandlers 创建管理API处理器
-func NewAdminHandlers(routeManager *DynamicRouteManager) *AdminHandlers {
+func NewAdminHandlers(routeManager *DynamicRouteManager, resolve InstanceResolver) *AdminHandlers {
 	return &AdminHandlers{
 		routeManager: routeManager,
+		resolve:      resolve,
 	}
 }
 
@@ -54,10 +57,16 @@ func (h *AdminHandlers) GetRoute(c *gin.Context) {
 
 // AddRouteRequest 添加路由请求
 type AddRouteRequest struct {
-	Prefix      string   `json:"prefix" binding"required"`
-	ServiceName string   `json:"service_name" binding"required"`
-	RequireAuth bool     `json:"require_auth"`
-	RequireRole []string `json:"require_role"`
+	Prefix        string            `json:"prefix" binding"required"`
+	ServiceName   string            `json:"service_name" binding"required"`
+	GatewayPrefix string            `json:"gateway_prefix"`
+	RequireAuth   bool              `json:"require_auth"`
+	RequireRole   []string          `json:"require_role"`
+	Methods       []string          `json:"methods"`
+	Host          string            `json:"host"`
+	Headers       map[string]string `json:"headers"`
+	Rewrite       *RewriteRule      `json:"rewrite"`
+	Timeout       int               `json:"timeout"`
 }
 
 // AddRoute 添加路由配置
@@ -75,9 +84,19 @@ func (h *AdminHandlers) AddRoute(c *gin.Context) {
 	}
 
 	config := &RouteConfig{
-		ServiceName: req.ServiceName,
-		RequireAuth: req.RequireAuth,
-		RequireRole: req.RequireRole,
+		ServiceName:   req.ServiceName,
+		GatewayPrefix: req.GatewayPrefix,
+		RequireAuth:   req.RequireAuth,
+		RequireRole:   req.RequireRole,
+		Methods:       req.Methods,
+		Host:          req.Host,
+		Headers:       req.Headers,
+		Rewrite:       req.Rewrite,
+		Timeout:       req.Timeout,
+	}
+	if err := CompileRoute(req.Prefix, config); err != nil {
+		response.BadRequest(c, err.Error())
+		return
 	}
 
 	if err := h.routeManager.AddRoute(req.Prefix, config); err != nil {
@@ -111,6 +130,10 @@ func (h *AdminHandlers) UpdateRoute(c *gin.Context) {
 		response.BadRequest(c, "请求参数错误: "+err.Error())
 		return
 	}
+	if err := CompileRoute(prefix, &config); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 
 	if err := h.routeManager.UpdateRoute(prefix, &config); err != nil {
 		response.InternalError(c, "更新路由失败: "+err.Error())
@@ -149,6 +172,61 @@ func (h *AdminHandlers) DeleteRoute(c *gin.Context) {
 	})
 }
 
+// TestRouteRequest 路由测试请求
+type TestRouteRequest struct {
+	Method  string            `json:"method"` // 默认 GET
+	Path    string            `json:"path" binding:"required"`
+	Host    string            `json:"host"`
+	Headers map[string]string `json:"headers"`
+}
+
+// TestRouteResponse 路由测试结果
+type TestRouteResponse struct {
+	Matched       bool             `json:"matched"`
+	Route         *RouteMatch      `json:"route,omitempty"`
+	Upstreams     []string         `json:"upstreams,omitempty"`      // 上游服务的健康实例
+	UpstreamError string           `json:"upstream_error,omitempty"` // 上游不可用的原因
+	Candidates    []RouteCandidate `json:"candidates"`               // 前缀匹配的全部路由（按优先级）
+}
+
+// TestRoute 路由测试：给定请求会命中哪条路由、转发到哪个上游和路径
+// POST /gateway/admin/route-test
+func (h *AdminHandlers) TestRoute(c *gin.Context) {
+	var req TestRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	if req.Path[0] != '/' {
+		req.Path = "/" + req.Path
+	}
+
+	r, err := http.NewRequest(req.Method, req.Path, nil)
+	if err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+	r.Host = req.Host
+	for k, v := range req.Headers {
+		r.Header.Set(k, v)
+	}
+
+	match, candidates := h.routeManager.Table().Explain(r)
+	resp := TestRouteResponse{Matched: match != nil, Route: match, Candidates: candidates}
+	if match != nil && h.resolve != nil {
+		if upstreams, err := h.resolve(match.Config.ServiceName); err != nil {
+			resp.UpstreamError = err.Error()
+		} else {
+			resp.Upstreams = upstreams
+		}
+	}
+
+	response.Success(c, resp)
+}
+
 // ============================
 // Hystrix 配置管理 API
 // ============================
@@ -264,6 +342,55 @@ func (h *AdminHandlers) DeleteHystrixConfig(c *gin.Context) {
 	})
 }
 
+// ============================
+// 限流策略管理 API
+// ============================
+
+// ListRateLimits 获取所有限流策略
+// GET /gateway/admin/ratelimits
+func (h *AdminHandlers) ListRateLimits(c *gin.Context) {
+	response.Success(c, h.routeManager.GetAllRateLimits())
+}
+
+// SaveRateLimit 添加或更新限流策略（按 name 覆盖）
+// POST /gateway/admin/ratelimits
+func (h *AdminHandlers) SaveRateLimit(c *gin.Context) {
+	var policy RateLimitPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+	if err := policy.Validate(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.routeManager.AddRateLimit(&policy); err != nil {
+		response.InternalError(c, "保存限流策略失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "限流策略保存成功",
+		"policy":  policy,
+	})
+}
+
+// DeleteRateLimit 删除限流策略
+// DELETE /gateway/admin/ratelimits/:name
+func (h *AdminHandlers) DeleteRateLimit(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.routeManager.DeleteRateLimit(name); err != nil {
+		response.InternalError(c, "删除限流策略失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "限流策略删除成功",
+		"name":    name,
+	})
+}
+
 // ReloadConfig 重新加载所有配置
 // POST /gateway/admin/reload
 func (h *AdminHandlers) ReloadConfig(c *gin.Context) {
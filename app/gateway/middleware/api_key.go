@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"context"
+	"mule-cloud/core/apikey"
+	"mule-cloud/core/casbin"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/jwt"
+	"mule-cloud/core/response"
+	"mule-cloud/core/security"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// apiKeyCacheTTL 网关缓存API密钥的时间（修改或吊销最多延迟这么久生效）
+	apiKeyCacheTTL = 30 * time.Second
+	// apiKeyTouchInterval 同一密钥写入最近使用时间的最小间隔
+	apiKeyTouchInterval = time.Minute
+	// apiKeyCacheMax 缓存条目超过该数量时清理过期条目
+	apiKeyCacheMax = 10000
+)
+
+// APIKeyStore 按密钥哈希查找API密钥（带短时缓存），并异步记录最近使用时间
+type APIKeyStore struct {
+	repo *repository.APIKeyRepository
+
+	mu      sync.Mutex
+	cache   map[string]apiKeyEntry
+	touched map[string]time.Time
+}
+
+type apiKeyEntry struct {
+	key      *models.APIKey
+	loadedAt time.Time
+}
+
+// NewAPIKeyStore 创建API密钥存储（需要先初始化 DatabaseManager）
+func NewAPIKeyStore() *APIKeyStore {
+	return &APIKeyStore{
+		repo:    repository.NewAPIKeyRepository(),
+		cache:   make(map[string]apiKeyEntry),
+		touched: make(map[string]time.Time),
+	}
+}
+
+// Lookup 查找密钥，不存在返回 nil（不存在的密钥不缓存，避免随机密钥撑大缓存）
+func (s *APIKeyStore) Lookup(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	hash := apikey.Hash(rawKey)
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.cache[hash]
+	s.mu.Unlock()
+	if ok && now.Sub(entry.loadedAt) < apiKeyCacheTTL {
+		return entry.key, nil
+	}
+
+	key, err := s.repo.GetByHash(ctx, hash)
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if len(s.cache) >= apiKeyCacheMax {
+		for h, e := range s.cache {
+			if now.Sub(e.loadedAt) >= apiKeyCacheTTL {
+				delete(s.cache, h)
+			}
+		}
+	}
+	s.cache[hash] = apiKeyEntry{key: key, loadedAt: now}
+	s.mu.Unlock()
+	return key, nil
+}
+
+// Touch 异步记录最近使用时间和IP（同一密钥每分钟最多写一次）
+func (s *APIKeyStore) Touch(key *models.APIKey, ip string) {
+	now := time.Now()
+	s.mu.Lock()
+	if last, ok := s.touched[key.ID]; ok && now.Sub(last) < apiKeyTouchInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.touched[key.ID] = now
+	s.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.repo.TouchLastUsed(ctx, key.ID, ip, now.Unix()); err != nil {
+			log.Warn("记录API密钥使用时间失败", zap.String("api_key_id", key.ID), zap.Error(err))
+		}
+	}()
+}
+
+// APIKeyAuth API密钥认证中间件（Bearer 令牌之外的另一种认证方式）
+//
+// 支持 X-API-Key: <key> 或 Authorization: ApiKey <key>。
+// 校验通过后以密钥身份（user_id = apikey:<密钥ID>）转发请求，密钥本身不会转发给后端服务。
+// 密钥没有角色，要求角色的路由会拒绝密钥访问；权限按 Casbin 规则匹配 casbin.ParseResourceAndAction 的结果。
+func APIKeyAuth(store *APIKeyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey := extractAPIKey(c)
+		if rawKey == "" {
+			c.Next()
+			return
+		}
+		c.Request.Header.Del("X-API-Key")
+		c.Request.Header.Del("Authorization")
+
+		key, err := store.Lookup(c.Request.Context(), rawKey)
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error("查询API密钥失败", zap.Error(err))
+			response.ErrorWithCode(c, 503, "API密钥校验失败，请稍后重试")
+			c.Abort()
+			return
+		}
+		if key == nil || key.Status != 1 {
+			response.ErrorWithCode(c, 401, "API密钥无效或已吊销")
+			c.Abort()
+			return
+		}
+		if apikey.Expired(key, time.Now()) {
+			response.ErrorWithCode(c, 401, "API密钥已过期")
+			c.Abort()
+			return
+		}
+
+		// 网关引擎只采信 gateway.trusted_proxies 转发的来源IP，客户端伪造的 X-Forwarded-For 不生效
+		ip := c.ClientIP()
+		if !apikey.IPAllowed(key.AllowedIPs, ip) {
+			rejectAPIKey(c, key, ip, "IP不在白名单内")
+			response.ErrorWithCode(c, 403, "IP不在API密钥白名单中")
+			c.Abort()
+			return
+		}
+		resource, action := casbin.ParseResourceAndAction(c.Request.URL.Path, c.Request.Method)
+		if !apikey.Allowed(key.Permissions, resource, action) {
+			rejectAPIKey(c, key, ip, "无权访问 "+resource+" ("+action+")")
+			response.ErrorWithCode(c, 403, "API密钥无权访问该接口")
+			c.Abort()
+			return
+		}
+		store.Touch(key, ip)
+
+		userID := apikey.UserIDPrefix + key.ID
+		claims := &jwt.Claims{
+			UserID:     userID,
+			Username:   key.Name,
+			TenantID:   key.TenantID,
+			TenantCode: key.TenantCode,
+		}
+		c.Set("user_id", userID)
+		c.Set("username", key.Name)
+		c.Set("tenant_id", key.TenantID)
+		c.Set("tenant_code", key.TenantCode)
+		c.Set("roles", []string{})
+		c.Set("api_key_id", key.ID)
+		c.Set("claims", claims)
+
+		ctx := c.Request.Context()
+		ctx = tenantCtx.WithTenantCode(ctx, key.TenantCode)
+		ctx = tenantCtx.WithUserID(ctx, userID)
+		ctx = tenantCtx.WithUsername(ctx, key.Name)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// extractAPIKey 从请求头读取API密钥
+func extractAPIKey(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) == 2 && strings.EqualFold(parts[0], "ApiKey") {
+		return strings.TrimSpace(parts[1])
+	}
+	return ""
+}
+
+// rejectAPIKey 记录API密钥被拒绝的安全事件（写入密钥所属租户的操作日志）
+func rejectAPIKey(c *gin.Context, key *models.APIKey, ip, detail string) {
+	security.RecordEvent(key.TenantCode, security.Event{
+		Type:      security.EventAPIKeyRejected,
+		UserID:    apikey.UserIDPrefix + key.ID,
+		Username:  key.Name,
+		Path:      c.Request.URL.Path,
+		IP:        ip,
+		UserAgent: c.Request.UserAgent(),
+		Detail:    detail,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	tenantCtx "mule-cloud/core/context"
 	"mule-cloud/core/jwt"
 	"mule-cloud/core/response"
+	"mule-cloud/core/session"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -38,12 +39,31 @@ func JWTAuth(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		// 检查令牌是否已被注销
+		if session.IsDenied(c.Request.Context(), claims.ID) {
+			response.ErrorWithCode(c, 401, "token已失效，请重新登录")
+			c.Abort()
+			return
+		}
+
+		// 只读代管令牌只允许读操作
+		if claims.ReadOnly && !jwt.ReadOnlyAllowed(c.Request.Method) {
+			response.ErrorWithCode(c, 403, "只读代管会话不允许修改数据")
+			c.Abort()
+			return
+		}
+
 		// 将用户信息存入Gin Context（保持向下兼容）
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("tenant_id", claims.TenantID)     // 保留 ID（兼容）
 		c.Set("tenant_code", claims.TenantCode) // ✅ 新增：租户代码
 		c.Set("roles", claims.Roles)
+		c.Set("session_id", claims.SessionID)
+		c.Set("jti", claims.ID)
+		c.Set("mfa", claims.MFA)
+		c.Set("impersonation_id", claims.ImpersonationID)
+		c.Set("read_only", claims.ReadOnly)
 		c.Set("claims", claims)
 
 		// ✅ 将租户信息存入标准Context（使用 TenantCode）
@@ -91,12 +111,18 @@ func OptionalAuth(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) == 2 && parts[0] == "Bearer" {
 			claims, err := jwtManager.ValidateToken(parts[1])
-			if err == nil {
+			// 已注销的令牌按未登录处理
+			if err == nil && !session.IsDenied(c.Request.Context(), claims.ID) {
 				c.Set("user_id", claims.UserID)
 				c.Set("username", claims.Username)
 				c.Set("tenant_id", claims.TenantID)     // 保留 ID（兼容）
 				c.Set("tenant_code", claims.TenantCode) // ✅ 新增：租户代码
 				c.Set("roles", claims.Roles)
+				c.Set("session_id", claims.SessionID)
+				c.Set("jti", claims.ID)
+				c.Set("mfa", claims.MFA)
+				c.Set("impersonation_id", claims.ImpersonationID)
+				c.Set("read_only", claims.ReadOnly)
 				c.Set("claims", claims)
 
 				// ✅ 将租户信息存入标准Context（使用 TenantCode）
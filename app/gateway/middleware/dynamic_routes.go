@@ -3,30 +3,37 @@ package middleware
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/afex/hystrix-go/hystrix"
 	"github.com/hashicorp/consul/api"
+	"go.uber.org/zap"
 )
 
 const (
 	// Consul KV 路径前缀
 	RouteConfigPrefix   = "gateway/routes/"
 	HystrixConfigPrefix = "gateway/hystrix/"
+	RateLimitPrefix     = "gateway/ratelimits/"
 )
 
 // DynamicRouteManager 动态路由管理器
 type DynamicRouteManager struct {
 	consulClient *api.Client
 	routes       map[string]*RouteConfig
+	table        *RouteTable // 由 routes 编译的路由表，routes 变化时重建
 	routeLock    sync.RWMutex
 
 	hystrixConfigs map[string]*DynamicHystrixConfig
 	hystrixLock    sync.RWMutex
 
+	rateLimits    map[string]*RateLimitPolicy
+	rateLimitList []*RateLimitPolicy // 按名称排序，供限流中间件读取
+	rateLimitLock sync.RWMutex
+
 	stopChan chan bool
 }
 
@@ -36,6 +43,23 @@ type RouteConfig struct {
 	GatewayPrefix string   `json:"gateway_prefix"` // 网关前缀（如 /admin），转发时会去掉
 	RequireAuth   bool     `json:"require_auth"`
 	RequireRole   []string `json:"require_role"`
+
+	Methods []string          `json:"methods,omitempty"` // 只匹配这些请求方法（为空不限）
+	Host    string            `json:"host,omitempty"`    // 只匹配该 Host（支持 *.example.com）
+	Headers map[string]string `json:"headers,omitempty"` // 请求头必须等于给定值（* 表示存在即可）
+	Rewrite *RewriteRule      `json:"rewrite,omitempty"` // 转发路径重写
+	Timeout int               `json:"timeout,omitempty"` // 转发超时（毫秒），0 表示不限制
+}
+
+// RewriteRule 路径重写规则（作用于去掉网关前缀后的路径），正则和模板二选一
+//
+//	正则：{"regex": "^/v1/(.*)$", "replacement": "/order/$1"}
+//	模板：{"from": "/v1/orders/{id}/{rest*}", "to": "/order/orders/{id}/{rest}"}
+type RewriteRule struct {
+	Regex       string `json:"regex,omitempty"`
+	Replacement string `json:"replacement,omitempty"` // 支持 $1、${name}
+	From        string `json:"from,omitempty"`        // 路径模板，{name} 匹配一段，{name*} 匹配剩余全部
+	To          string `json:"to,omitempty"`
 }
 
 // DynamicHystrixConfig Hystrix配置（用于动态路由管理）
@@ -52,16 +76,21 @@ func NewDynamicRouteManager(consulClient *api.Client) *DynamicRouteManager {
 	manager := &DynamicRouteManager{
 		consulClient:   consulClient,
 		routes:         make(map[string]*RouteConfig),
+		table:          &RouteTable{},
 		hystrixConfigs: make(map[string]*DynamicHystrixConfig),
+		rateLimits:     make(map[string]*RateLimitPolicy),
 		stopChan:       make(chan bool),
 	}
 
 	// 初始加载配置
 	if err := manager.loadRoutes(); err != nil {
-		log.Printf("⚠️  初始加载路由配置失败: %v", err)
+		log.Warn("初始加载路由配置失败", zap.Error(err))
 	}
 	if err := manager.loadHystrixConfigs(); err != nil {
-		log.Printf("⚠️  初始加载Hystrix配置失败: %v", err)
+		log.Warn("初始加载Hystrix配置失败", zap.Error(err))
+	}
+	if err := manager.loadRateLimits(); err != nil {
+		log.Warn("初始加载限流策略失败", zap.Error(err))
 	}
 
 	// 启动配置监听
@@ -92,8 +121,19 @@ func (m *DynamicRouteManager) GetAllRoutes() map[string]*RouteConfig {
 	return routes
 }
 
+// Table 当前的路由表
+func (m *DynamicRouteManager) Table() *RouteTable {
+	m.routeLock.RLock()
+	defer m.routeLock.RUnlock()
+	return m.table
+}
+
 // AddRoute 添加路由配置
 func (m *DynamicRouteManager) AddRoute(prefix string, config *RouteConfig) error {
+	if err := CompileRoute(prefix, config); err != nil {
+		return err
+	}
+
 	// 保存到 Consul
 	key := RouteConfigPrefix + prefix
 	data, err := json.Marshal(config)
@@ -113,6 +153,7 @@ func (m *DynamicRouteManager) AddRoute(prefix string, config *RouteConfig) error
 	// 更新本地缓存
 	m.routeLock.Lock()
 	m.routes[prefix] = config
+	m.rebuildTable()
 	m.routeLock.Unlock()
 
 	return nil
@@ -134,6 +175,7 @@ func (m *DynamicRouteManager) DeleteRoute(prefix string) error {
 	// 从本地缓存删除
 	m.routeLock.Lock()
 	delete(m.routes, prefix)
+	m.rebuildTable()
 	m.routeLock.Unlock()
 
 	return nil
@@ -221,7 +263,7 @@ func (m *DynamicRouteManager) loadRoutes() error {
 	for _, pair := range pairs {
 		var config RouteConfig
 		if err := json.Unmarshal(pair.Value, &config); err != nil {
-			log.Printf("⚠️  解析路由配置失败 (key=%s): %v", pair.Key, err)
+			log.Warn("解析路由配置失败", zap.String("key", pair.Key), zap.Error(err))
 			continue
 		}
 
@@ -231,13 +273,28 @@ func (m *DynamicRouteManager) loadRoutes() error {
 		if !strings.HasPrefix(prefix, "/") {
 			prefix = "/" + prefix
 		}
+		if err := CompileRoute(prefix, &config); err != nil {
+			log.Warn("忽略无效的路由配置", zap.String("key", pair.Key), zap.Error(err))
+			continue
+		}
 		m.routes[prefix] = &config
 
 	}
+	m.rebuildTable()
 
 	return nil
 }
 
+// rebuildTable 重新编译路由表（调用方持有 routeLock，单条路由已在加入时校验）
+func (m *DynamicRouteManager) rebuildTable() {
+	table, err := CompileRoutes(m.routes)
+	if err != nil {
+		log.Error("编译路由表失败", zap.Error(err))
+		return
+	}
+	m.table = table
+}
+
 // loadHystrixConfigs 从Consul加载所有Hystrix配置
 func (m *DynamicRouteManager) loadHystrixConfigs() error {
 	pairs, _, err := m.consulClient.KV().List(HystrixConfigPrefix, nil)
@@ -254,7 +311,7 @@ func (m *DynamicRouteManager) loadHystrixConfigs() error {
 	for _, pair := range pairs {
 		var config DynamicHystrixConfig
 		if err := json.Unmarshal(pair.Value, &config); err != nil {
-			log.Printf("⚠️  解析Hystrix配置失败 (key=%s): %v", pair.Key, err)
+			log.Warn("解析Hystrix配置失败", zap.String("key", pair.Key), zap.Error(err))
 			continue
 		}
 
@@ -282,28 +339,131 @@ func (m *DynamicRouteManager) applyHystrixConfig(serviceName string, config *Dyn
 
 }
 
+// RateLimitPolicies 当前的限流策略（按名称排序，调用方不要修改）
+func (m *DynamicRouteManager) RateLimitPolicies() []*RateLimitPolicy {
+	m.rateLimitLock.RLock()
+	defer m.rateLimitLock.RUnlock()
+	return m.rateLimitList
+}
+
+// GetAllRateLimits 获取所有限流策略
+func (m *DynamicRouteManager) GetAllRateLimits() map[string]*RateLimitPolicy {
+	m.rateLimitLock.RLock()
+	defer m.rateLimitLock.RUnlock()
+
+	policies := make(map[string]*RateLimitPolicy, len(m.rateLimits))
+	for k, v := range m.rateLimits {
+		policies[k] = v
+	}
+	return policies
+}
+
+// AddRateLimit 添加或更新限流策略
+func (m *DynamicRouteManager) AddRateLimit(policy *RateLimitPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("序列化限流策略失败: %v", err)
+	}
+	kv := &api.KVPair{
+		Key:   RateLimitPrefix + policy.Name,
+		Value: data,
+	}
+	if _, err := m.consulClient.KV().Put(kv, nil); err != nil {
+		return fmt.Errorf("保存限流策略到Consul失败: %v", err)
+	}
+
+	m.rateLimitLock.Lock()
+	m.rateLimits[policy.Name] = policy
+	m.rebuildRateLimits()
+	m.rateLimitLock.Unlock()
+
+	return nil
+}
+
+// DeleteRateLimit 删除限流策略
+func (m *DynamicRouteManager) DeleteRateLimit(name string) error {
+	if _, err := m.consulClient.KV().Delete(RateLimitPrefix+name, nil); err != nil {
+		return fmt.Errorf("从Consul删除限流策略失败: %v", err)
+	}
+
+	m.rateLimitLock.Lock()
+	delete(m.rateLimits, name)
+	m.rebuildRateLimits()
+	m.rateLimitLock.Unlock()
+
+	return nil
+}
+
+// loadRateLimits 从Consul加载所有限流策略
+func (m *DynamicRouteManager) loadRateLimits() error {
+	pairs, _, err := m.consulClient.KV().List(RateLimitPrefix, nil)
+	if err != nil {
+		return fmt.Errorf("从Consul获取限流策略失败: %v", err)
+	}
+
+	m.rateLimitLock.Lock()
+	defer m.rateLimitLock.Unlock()
+
+	m.rateLimits = make(map[string]*RateLimitPolicy)
+	for _, pair := range pairs {
+		var policy RateLimitPolicy
+		if err := json.Unmarshal(pair.Value, &policy); err != nil {
+			log.Warn("解析限流策略失败", zap.String("key", pair.Key), zap.Error(err))
+			continue
+		}
+		policy.Name = pair.Key[len(RateLimitPrefix):]
+		if err := policy.Validate(); err != nil {
+			log.Warn("忽略无效的限流策略", zap.String("key", pair.Key), zap.Error(err))
+			continue
+		}
+		m.rateLimits[policy.Name] = &policy
+	}
+	m.rebuildRateLimits()
+
+	return nil
+}
+
+// rebuildRateLimits 重建排序后的策略列表（调用方持有 rateLimitLock）
+func (m *DynamicRouteManager) rebuildRateLimits() {
+	list := make([]*RateLimitPolicy, 0, len(m.rateLimits))
+	for _, policy := range m.rateLimits {
+		list = append(list, policy)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	m.rateLimitList = list
+}
+
 // watchConfigs 监听配置变化
 func (m *DynamicRouteManager) watchConfigs() {
 	ticker := time.NewTicker(10 * time.Second) // 每10秒检查一次
 	defer ticker.Stop()
 
-	log.Println("🔍 启动配置监听器...")
+	log.Info("启动配置监听器")
 
 	for {
 		select {
 		case <-ticker.C:
 			// 重新加载路由配置
 			if err := m.loadRoutes(); err != nil {
-				log.Printf("⚠️  重新加载路由配置失败: %v", err)
+				log.Warn("重新加载路由配置失败", zap.Error(err))
 			}
 
 			// 重新加载Hystrix配置
 			if err := m.loadHystrixConfigs(); err != nil {
-				log.Printf("⚠️  重新加载Hystrix配置失败: %v", err)
+				log.Warn("重新加载Hystrix配置失败", zap.Error(err))
+			}
+
+			// 重新加载限流策略
+			if err := m.loadRateLimits(); err != nil {
+				log.Warn("重新加载限流策略失败", zap.Error(err))
 			}
 
 		case <-m.stopChan:
-			log.Println("🛑 停止配置监听器")
+			log.Info("停止配置监听器")
 			return
 		}
 	}
@@ -4,13 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"log"
 	hystrixPkg "mule-cloud/core/hystrix"
+	"mule-cloud/core/metrics"
 	"net/http"
 	"time"
 
 	"github.com/afex/hystrix-go/hystrix"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // HystrixMiddleware Hystrix熔断器中间件
@@ -45,7 +46,8 @@ func HystrixMiddleware() gin.HandlerFunc {
 			// 降级函数（熔断时执行）
 			func(err error) error {
 				duration := time.Since(startTime)
-				log.Printf("[熔断触发] 服务: %s, 原因: %v, 耗时: %v", commandName, err, duration)
+				log.Ctx(LogContext(c)).Warn("熔断降级", zap.String("command", commandName), zap.Duration("duration", duration), zap.Error(err))
+				metrics.CircuitFallbacks.WithLabelValues(commandName).Inc()
 
 				// 返回降级响应
 				c.JSON(http.StatusServiceUnavailable, gin.H{
@@ -63,7 +65,7 @@ func HystrixMiddleware() gin.HandlerFunc {
 		)
 
 		if err != nil {
-			log.Printf("[熔断错误] 服务: %s, 错误: %v", commandName, err)
+			log.Ctx(LogContext(c)).Error("熔断执行失败", zap.String("command", commandName), zap.Error(err))
 		}
 	}
 }
@@ -186,7 +188,7 @@ func HystrixWrapper(commandName string, handler http.Handler, fallbackHandler ht
 		)
 
 		if err != nil {
-			log.Printf("[Hystrix错误] 命令: %s, 错误: %v", commandName, err)
+			log.Ctx(r.Context()).Error("熔断执行失败", zap.String("command", commandName), zap.Error(err))
 		}
 	})
 }
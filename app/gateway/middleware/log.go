@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 网关的模块日志（级别可通过 /gateway/log-levels 单独调整）
+var (
+	log         = logger.For("gateway")
+	upstreamLog = logger.For("gateway.upstream")
+	casbinLog   = logger.For("gateway.casbin")
+)
+
+// LogContext 请求上下文加上认证得到的租户和用户（网关认证只写入 gin.Context，日志从 context 读取）
+func LogContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if tenantCode := c.GetString("tenant_code"); tenantCode != "" {
+		ctx = tenantCtx.WithTenantCode(ctx, tenantCode)
+	}
+	if userID := c.GetString("user_id"); userID != "" {
+		ctx = tenantCtx.WithUserID(ctx, userID)
+	}
+	return ctx
+}
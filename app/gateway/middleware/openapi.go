@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"mule-cloud/core/openapi"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// openAPIFetchTimeout 获取单个服务文档的超时
+const openAPIFetchTimeout = 5 * time.Second
+
+// OpenAPIHandler 聚合各服务的 OpenAPI 文档
+//
+//	从路由表中每个服务的 /openapi.json 获取文档，服务路径换算为经网关访问的路径
+//	（加上网关前缀，模板重写规则反向替换），只保留网关实际会转发到该服务的路径；
+//	获取失败的服务写入 x-unavailable，不影响其他服务
+func OpenAPIHandler(info openapi.Info, table func() *RouteTable, upstreams *UpstreamManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		routes := table()
+		services := routes.Services()
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		docs := make(map[string]*openapi.Document, len(services))
+		unavailable := map[string]string{}
+		for _, service := range services {
+			wg.Add(1)
+			go func(service string) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(c.Request.Context(), openAPIFetchTimeout)
+				defer cancel()
+				doc, err := upstreams.FetchOpenAPI(ctx, service)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					log.Ctx(c.Request.Context()).Warn("获取服务文档失败", zap.String("upstream", service), zap.Error(err))
+					unavailable[service] = err.Error()
+					return
+				}
+				docs[service] = doc
+			}(service)
+		}
+		wg.Wait()
+
+		doc := AggregateOpenAPI(info, routes, docs)
+		if len(unavailable) > 0 {
+			doc.XUnavailable = unavailable
+		}
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// FetchOpenAPI 通过服务的连接池获取文档
+func (m *UpstreamManager) FetchOpenAPI(ctx context.Context, serviceName string) (*openapi.Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+serviceName+"/openapi.json", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.pool(serviceName).RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("服务返回 %d", resp.StatusCode)
+	}
+	var doc openapi.Document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析文档失败: %v", err)
+	}
+	return &doc, nil
+}
+
+// AggregateOpenAPI 合并各服务的文档，路径换算为网关路径
+//
+// 接口 ID 和分组加上服务名（如 orderservice.CreateStyle、orderservice/style），
+// 结构名本身带包路径（如 order.dto.StyleResponse），各服务共用的结构（如 models.Style）只保留一份
+func AggregateOpenAPI(info openapi.Info, table *RouteTable, docs map[string]*openapi.Document) *openapi.Document {
+	if info.Version == "" {
+		info.Version = "1.0.0"
+	}
+	result := &openapi.Document{
+		OpenAPI:    "3.0.3",
+		Info:       info,
+		Paths:      map[string]openapi.PathItem{},
+		Components: openapi.Components{Schemas: map[string]*openapi.Schema{}, SecuritySchemes: map[string]*openapi.SecurityScheme{}},
+	}
+
+	ids := map[string]bool{}
+	tags := map[string]bool{}
+	for _, service := range sortedKeys(docs) {
+		doc := docs[service]
+		for name, schema := range doc.Components.Schemas {
+			if _, ok := result.Components.Schemas[name]; !ok {
+				result.Components.Schemas[name] = schema
+			}
+		}
+		for name, scheme := range doc.Components.SecuritySchemes {
+			result.Components.SecuritySchemes[name] = scheme
+		}
+		if result.Security == nil {
+			result.Security = doc.Security
+		}
+
+		for _, path := range sortedKeys(doc.Paths) {
+			for _, method := range sortedKeys(doc.Paths[path]) {
+				for _, gatewayPath := range table.GatewayPaths(service, strings.ToUpper(method), path) {
+					op := *doc.Paths[path][method]
+					op.OperationID = uniqueID(service+"."+op.OperationID, ids)
+					op.Tags = make([]string, len(doc.Paths[path][method].Tags))
+					for i, tag := range doc.Paths[path][method].Tags {
+						op.Tags[i] = service + "/" + tag
+						tags[op.Tags[i]] = true
+					}
+					if result.Paths[gatewayPath] == nil {
+						result.Paths[gatewayPath] = openapi.PathItem{}
+					}
+					result.Paths[gatewayPath][method] = &op
+				}
+			}
+		}
+	}
+
+	for _, tag := range sortedKeys(tags) {
+		result.Tags = append(result.Tags, openapi.Tag{Name: tag})
+	}
+	return result
+}
+
+// Services 路由表中的全部服务（排序）
+func (t *RouteTable) Services() []string {
+	set := map[string]bool{}
+	for _, route := range t.routes {
+		set[route.config.ServiceName] = true
+	}
+	return sortedKeys(set)
+}
+
+// GatewayPaths 服务路径（OpenAPI 格式，如 /order/orders/{id}）经网关访问的路径
+//
+// 按路由反推出网关路径后再用 Match 验证：命中同一条路由且转发路径与服务路径一致才保留，
+// 所以被更长前缀的其他服务覆盖的路径、正则重写无法反推的路径都不会出现
+func (t *RouteTable) GatewayPaths(service, method, path string) []string {
+	var paths []string
+	for _, route := range t.routes {
+		if route.config.ServiceName != service || (route.methods != nil && !route.methods[method]) {
+			continue
+		}
+		target, ok := route.reverse(path)
+		if !ok {
+			continue
+		}
+		full := route.config.GatewayPrefix + target
+		if m := t.Match(route.sampleRequest(method, full)); m != nil && m.Config == route.config && m.TargetPath == path {
+			paths = append(paths, full)
+		}
+	}
+	return paths
+}
+
+// reverse 转发路径反推为去掉网关前缀前的路径（模板重写规则把 to 替换回 from）
+func (r *compiledRoute) reverse(path string) (string, bool) {
+	rw := r.config.Rewrite
+	if r.rewrite == nil {
+		return path, true
+	}
+	if rw.From == "" {
+		return "", false
+	}
+	// from 中 {name*} 匹配多段，反推时 to 中同名参数也按多段匹配
+	multi := map[string]bool{}
+	for _, m := range templateParam.FindAllStringSubmatch(rw.From, -1) {
+		if m[2] == "*" {
+			multi[m[1]] = true
+		}
+	}
+	to := templateParam.ReplaceAllStringFunc(rw.To, func(s string) string {
+		if name := strings.Trim(s, "{}*"); multi[name] {
+			return "{" + name + "*}"
+		}
+		return s
+	})
+	re, err := regexp.Compile(templatePattern(to))
+	if err != nil {
+		return "", false
+	}
+	if re.MatchString(path) {
+		return re.ReplaceAllString(path, templateParam.ReplaceAllString(rw.From, "$${$1}")), true
+	}
+	return path, !r.rewrite.MatchString(path)
+}
+
+// sampleRequest 满足路由的 Host、请求头条件的示例请求（验证反推结果）
+func (r *compiledRoute) sampleRequest(method, path string) *http.Request {
+	req := &http.Request{Method: method, URL: &url.URL{Path: path}, Header: http.Header{}}
+	req.Host = strings.Replace(r.config.Host, "*", "api", 1)
+	for name, value := range r.config.Headers {
+		if value == "*" {
+			value = "1"
+		}
+		req.Header.Set(name, value)
+	}
+	return req
+}
+
+// uniqueID 接口 ID 重复（同一路径经多个前缀访问）时加序号
+func uniqueID(id string, used map[string]bool) string {
+	unique := id
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", id, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
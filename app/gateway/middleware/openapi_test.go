@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"reflect"
+	"testing"
+
+	"mule-cloud/core/openapi"
+)
+
+func serviceDoc(paths ...string) *openapi.Document {
+	doc := &openapi.Document{
+		Paths: map[string]openapi.PathItem{},
+		Components: openapi.Components{Schemas: map[string]*openapi.Schema{
+			"models.Style": {Type: "object"},
+		}},
+	}
+	for _, path := range paths {
+		doc.Paths[path] = openapi.PathItem{"get": {OperationID: "Get", Tags: []string{"style"}, Responses: map[string]*openapi.Response{}}}
+	}
+	return doc
+}
+
+// TestGatewayPaths 服务路径换算为网关路径：加网关前缀、模板重写反推、被其他服务覆盖的路径去掉
+func TestGatewayPaths(t *testing.T) {
+	table, err := CompileRoutes(map[string]*RouteConfig{
+		"/order":         {ServiceName: "orderservice", GatewayPrefix: "/admin"},
+		"/order/cutting": {ServiceName: "productionservice", GatewayPrefix: "/admin"},
+		"/v1":            {ServiceName: "orderservice", Rewrite: &RewriteRule{Regex: `^/v1/(.*)$`, Replacement: "/order/$1"}},
+		"/v2":            {ServiceName: "orderservice", Rewrite: &RewriteRule{From: "/v2/orders/{id}/{rest*}", To: "/order/orders/{id}/{rest}"}},
+		"/upload":        {ServiceName: "commonservice", Methods: []string{"POST"}},
+	})
+	if err != nil {
+		t.Fatalf("CompileRoutes() error = %v", err)
+	}
+
+	cases := []struct {
+		service, method, path string
+		want                  []string
+	}{
+		{"orderservice", "GET", "/order/styles/{id}", []string{"/admin/order/styles/{id}"}},
+		{"orderservice", "GET", "/order/orders/{id}/items", []string{"/admin/order/orders/{id}/items", "/v2/orders/{id}/items"}},
+		// 被 productionservice 的更长前缀覆盖
+		{"orderservice", "GET", "/order/cutting/tasks", nil},
+		{"productionservice", "GET", "/order/cutting/tasks", []string{"/admin/order/cutting/tasks"}},
+		// 路由限定了方法
+		{"commonservice", "GET", "/upload/file", nil},
+		{"commonservice", "POST", "/upload/file", []string{"/upload/file"}},
+	}
+	for _, c := range cases {
+		got := table.GatewayPaths(c.service, c.method, c.path)
+		if len(got) > 1 && got[0] > got[1] {
+			got[0], got[1] = got[1], got[0]
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("GatewayPaths(%s %s %s) = %v, want %v", c.service, c.method, c.path, got, c.want)
+		}
+	}
+}
+
+// TestAggregateOpenAPI 合并后接口 ID 和分组带服务名，公共结构只保留一份
+func TestAggregateOpenAPI(t *testing.T) {
+	table, err := CompileRoutes(map[string]*RouteConfig{
+		"/order":  {ServiceName: "orderservice", GatewayPrefix: "/admin"},
+		"/basic":  {ServiceName: "basicservice", GatewayPrefix: "/admin"},
+		"/orders": {ServiceName: "orderservice", Rewrite: &RewriteRule{From: "/orders/{rest*}", To: "/order/{rest}"}},
+	})
+	if err != nil {
+		t.Fatalf("CompileRoutes() error = %v", err)
+	}
+	if got := table.Services(); !reflect.DeepEqual(got, []string{"basicservice", "orderservice"}) {
+		t.Errorf("Services() = %v", got)
+	}
+
+	basicDoc := serviceDoc("/basic/colors")
+	doc := AggregateOpenAPI(openapi.Info{Title: "test"}, table, map[string]*openapi.Document{
+		"orderservice": serviceDoc("/order/styles/{id}"),
+		"basicservice": basicDoc,
+	})
+
+	basic := doc.Paths["/admin/basic/colors"]["get"]
+	if basic == nil || basic.OperationID != "basicservice.Get" || !reflect.DeepEqual(basic.Tags, []string{"basicservice/style"}) {
+		t.Fatalf("basic = %+v, paths = %v", basic, doc.Paths)
+	}
+	// 同一接口经两个前缀访问，接口 ID 不重复
+	a, b := doc.Paths["/admin/order/styles/{id}"]["get"], doc.Paths["/orders/styles/{id}"]["get"]
+	if a == nil || b == nil {
+		t.Fatalf("paths = %v", doc.Paths)
+	}
+	if a.OperationID == b.OperationID {
+		t.Errorf("operationId 重复: %s", a.OperationID)
+	}
+	if len(doc.Components.Schemas) != 1 || len(doc.Tags) != 2 {
+		t.Errorf("schemas = %v, tags = %v", doc.Components.Schemas, doc.Tags)
+	}
+	// 服务的文档本身不被修改
+	if src := basicDoc.Paths["/basic/colors"]["get"]; src.OperationID != "Get" || src.Tags[0] != "style" {
+		t.Errorf("服务文档被修改: %+v", src)
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"mule-cloud/core/quota"
+	"mule-cloud/core/response"
+	"mule-cloud/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// EntitlementResolver 获取租户的套餐权益（quota.Resolve）
+type EntitlementResolver func(ctx context.Context, tenantCode string) (*quota.Entitlement, error)
+
+// APICallRecorder 记录一次API调用并返回本月累计次数（quota.RecordAPICall）
+type APICallRecorder func(ctx context.Context, tenantCode string) (int64, error)
+
+// PlanEnforcement 套餐限制中间件（放在认证之后）
+//
+// 套餐过期（超过宽限期）的租户只读：只放行 GET/HEAD/OPTIONS；宽限期内返回 X-Subscription-State: grace 提醒续费。
+// 每个租户请求计入本月API调用次数，超过套餐上限返回 403。查询失败时放行。
+func PlanEnforcement(resolve EntitlementResolver, record APICallRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantCode := c.GetString("tenant_code")
+		if tenantCode == "" || tenantCode == "system" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		entitlement, err := resolve(ctx, tenantCode)
+		if err != nil {
+			log.Ctx(LogContext(c)).Warn("查询租户套餐失败，放行请求", zap.Error(err))
+			c.Next()
+			return
+		}
+
+		switch entitlement.State {
+		case models.SubscriptionExpired:
+			if !isReadOnlyMethod(c.Request.Method) {
+				response.ErrorWithCode(c, http.StatusForbidden, quota.ErrSubscriptionExpired.Error())
+				c.Abort()
+				return
+			}
+			c.Header("X-Subscription-State", models.SubscriptionExpired)
+		case models.SubscriptionGrace:
+			c.Header("X-Subscription-State", models.SubscriptionGrace)
+		}
+
+		// 不限制调用次数的套餐也计数，用于用量看板
+		used, err := record(ctx, tenantCode)
+		if err != nil {
+			log.Ctx(LogContext(c)).Warn("记录API调用次数失败", zap.Error(err))
+		} else if limit := entitlement.Limit(quota.APICallsPerMonth); limit > 0 && used > limit {
+			response.ErrorWithCode(c, http.StatusForbidden, fmt.Sprintf("%s：本月API调用次数已达上限 %d", quota.ErrQuotaExceeded.Error(), limit))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mule-cloud/core/quota"
+	"mule-cloud/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestPlanEnforcement 过期租户只读，宽限期提示，超过本月API调用次数拒绝
+func TestPlanEnforcement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	entitlements := map[string]*quota.Entitlement{
+		"expired": {State: models.SubscriptionExpired, Plan: &models.Plan{}},
+		"grace":   {State: models.SubscriptionGrace, Plan: &models.Plan{}},
+		"metered": {State: models.SubscriptionActive, Plan: &models.Plan{Limits: models.PlanLimits{MaxAPICallsPerMonth: 2}}},
+	}
+	calls := map[string]int64{}
+	resolve := func(_ context.Context, tenant string) (*quota.Entitlement, error) {
+		if e, ok := entitlements[tenant]; ok {
+			return e, nil
+		}
+		return &quota.Entitlement{State: models.SubscriptionNone}, nil
+	}
+	record := func(_ context.Context, tenant string) (int64, error) {
+		calls[tenant]++
+		return calls[tenant], nil
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if tenant := c.GetHeader("X-Test-Tenant"); tenant != "" {
+			c.Set("tenant_code", tenant)
+		}
+	}, PlanEnforcement(resolve, record))
+	r.Any("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(method, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/order", nil)
+		req.Header.Set("X-Test-Tenant", tenant)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("GET", "expired"); w.Code != http.StatusOK {
+		t.Errorf("expired GET = %d, want 200", w.Code)
+	}
+	if w := do("POST", "expired"); w.Code != http.StatusForbidden {
+		t.Errorf("expired POST = %d, want 403", w.Code)
+	}
+	if w := do("POST", "grace"); w.Code != http.StatusOK || w.Header().Get("X-Subscription-State") != "grace" {
+		t.Errorf("grace POST = %d %v, want 200 with grace header", w.Code, w.Header())
+	}
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusForbidden} {
+		if w := do("GET", "metered"); w.Code != want {
+			t.Errorf("metered request %d = %d, want %d", i, w.Code, want)
+		}
+	}
+	// 未订阅的租户也计数（用量看板），匿名请求不计数
+	do("POST", "free")
+	do("POST", "")
+	if calls["free"] != 1 || calls[""] != 0 {
+		t.Errorf("calls = %v", calls)
+	}
+}
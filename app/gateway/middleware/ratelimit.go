@@ -1,116 +1,276 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"mule-cloud/core/response"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
-// RateLimiter 限流器
-type RateLimiter struct {
-	rate      int                    // 每秒允许的请求数
-	visitors  map[string]*visitor    // 访问者映射
-	mu        sync.Mutex             // 互斥锁
-	cleanupCh chan struct{}          // 清理信号
+// 限流维度
+const (
+	RateLimitByTenant = "tenant"  // 按租户
+	RateLimitByUser   = "user"    // 按用户
+	RateLimitByAPIKey = "api_key" // 按API密钥
+	RateLimitByIP     = "ip"      // 按客户端IP
+	RateLimitByRoute  = "route"   // 按路由（所有调用方共享）
+)
+
+// RateLimitPolicy 限流策略：window 秒内最多 limit 个请求（GCRA，请求均匀放行，允许 burst 个突发）
+//
+// 请求路径（含网关前缀）按路径段匹配 path、请求方法匹配 methods 时生效；
+// 匹配的策略全部检查，任何一条超限即拒绝。缺少维度值（如匿名请求的 user 策略）时跳过该策略。
+type RateLimitPolicy struct {
+	Name    string   `json:"name"`
+	Key     string   `json:"key"`               // tenant / user / api_key / ip / route
+	Path    string   `json:"path,omitempty"`    // 路径前缀，为空匹配全部
+	Methods []string `json:"methods,omitempty"` // 为空不限
+	Limit   int      `json:"limit"`             // 窗口内允许的请求数
+	Window  int      `json:"window"`            // 窗口（秒）
+	Burst   int      `json:"burst,omitempty"`   // 突发上限，默认等于 limit
 }
 
-type visitor struct {
-	limiter  chan struct{}
-	lastSeen time.Time
+// Validate 校验限流策略
+func (p *RateLimitPolicy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("限流策略缺少 name")
+	}
+	switch p.Key {
+	case RateLimitByTenant, RateLimitByUser, RateLimitByAPIKey, RateLimitByIP, RateLimitByRoute:
+	default:
+		return fmt.Errorf("限流策略 %s 的 key 无效: %s", p.Name, p.Key)
+	}
+	if p.Limit <= 0 || p.Window <= 0 || p.Burst < 0 {
+		return fmt.Errorf("限流策略 %s 的 limit、window 必须大于0", p.Name)
+	}
+	return nil
 }
 
-// NewRateLimiter 创建限流器
-func NewRateLimiter(requestsPerSecond int) *RateLimiter {
-	rl := &RateLimiter{
-		rate:      requestsPerSecond,
-		visitors:  make(map[string]*visitor),
-		cleanupCh: make(chan struct{}),
+func (p *RateLimitPolicy) burst() int {
+	if p.Burst > 0 {
+		return p.Burst
 	}
+	return p.Limit
+}
 
-	// 启动清理goroutine
-	go rl.cleanup()
+// matches 请求是否适用该策略
+func (p *RateLimitPolicy) matches(method, path string) bool {
+	if p.Path != "" && !hasPathPrefix(path, p.Path) {
+		return false
+	}
+	if len(p.Methods) == 0 {
+		return true
+	}
+	for _, m := range p.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
 
-	return rl
+// subject 请求在该策略维度上的值，没有时返回空
+func (p *RateLimitPolicy) subject(c *gin.Context) string {
+	switch p.Key {
+	case RateLimitByTenant:
+		return c.GetString("tenant_code")
+	case RateLimitByUser:
+		return c.GetString("user_id")
+	case RateLimitByAPIKey:
+		return c.GetString("api_key_id")
+	case RateLimitByIP:
+		// 只采信 gateway.trusted_proxies 转发的 X-Forwarded-For，轮换伪造的头无法绕过限流
+		return c.ClientIP()
+	case RateLimitByRoute:
+		return "*"
+	}
+	return ""
 }
 
-// getVisitor 获取或创建访问者
-func (rl *RateLimiter) getVisitor(ip string) *visitor {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
+// RateLimitResult 一次限流检查的结果
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // 被拒绝时多久后可以重试
+	ResetAfter time.Duration // 多久后恢复到满额
+}
 
-	v, exists := rl.visitors[ip]
-	if !exists {
-		limiter := make(chan struct{}, rl.rate)
-		v = &visitor{
-			limiter:  limiter,
-			lastSeen: time.Now(),
-		}
-		rl.visitors[ip] = v
+// RateLimitStore 限流状态存储（Redis 在多个网关副本间共享）
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, policy *RateLimitPolicy) (*RateLimitResult, error)
+}
 
-		// 启动令牌生成
-		go rl.generateTokens(v)
-	}
+// RateLimiter 限流器
+type RateLimiter struct {
+	store    RateLimitStore
+	policies func() []*RateLimitPolicy
+}
 
-	v.lastSeen = time.Now()
-	return v
+// NewRateLimiter 创建限流器，policies 返回当前生效的策略（动态配置变化后立即生效）
+func NewRateLimiter(store RateLimitStore, policies func() []*RateLimitPolicy) *RateLimiter {
+	return &RateLimiter{store: store, policies: policies}
 }
 
-// generateTokens 生成令牌
-func (rl *RateLimiter) generateTokens(v *visitor) {
-	ticker := time.NewTicker(time.Second / time.Duration(rl.rate))
-	defer ticker.Stop()
+// Middleware 限流中间件（放在认证之后，才能按租户、用户、API密钥限流）
+//
+// 响应头按剩余额度最少的策略返回 RateLimit-Limit / RateLimit-Remaining / RateLimit-Reset / RateLimit-Policy，
+// 超限返回 429 和 Retry-After。存储不可用时放行。
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tightest *RateLimitPolicy
+		var tightestResult *RateLimitResult
+		for _, policy := range rl.policies() {
+			if !policy.matches(c.Request.Method, c.Request.URL.Path) {
+				continue
+			}
+			subject := policy.subject(c)
+			if subject == "" {
+				continue
+			}
 
-	for range ticker.C {
-		select {
-		case v.limiter <- struct{}{}:
-		default:
-			// 令牌桶满了
+			key := "ratelimit:" + policy.Name + ":" + subject
+			result, err := rl.store.Allow(c.Request.Context(), key, policy)
+			if err != nil {
+				log.Ctx(LogContext(c)).Warn("限流检查失败，放行请求", zap.String("policy", policy.Name), zap.Error(err))
+				continue
+			}
+			if !result.Allowed {
+				setRateLimitHeaders(c, policy, result)
+				c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				response.ErrorWithCode(c, http.StatusTooManyRequests, "请求过于频繁，请稍后再试")
+				c.Abort()
+				return
+			}
+			if tightestResult == nil || result.Remaining < tightestResult.Remaining {
+				tightest, tightestResult = policy, result
+			}
 		}
+		if tightest != nil {
+			setRateLimitHeaders(c, tightest, tightestResult)
+		}
+		c.Next()
 	}
 }
 
-// cleanup 清理过期的访问者
-func (rl *RateLimiter) cleanup() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
+func setRateLimitHeaders(c *gin.Context, policy *RateLimitPolicy, result *RateLimitResult) {
+	c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, policy.Window))
+}
 
-	for {
-		select {
-		case <-ticker.C:
-			rl.mu.Lock()
-			for ip, v := range rl.visitors {
-				if time.Since(v.lastSeen) > 10*time.Minute {
-					delete(rl.visitors, ip)
-				}
-			}
-			rl.mu.Unlock()
-		case <-rl.cleanupCh:
-			return
-		}
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// gcraScript GCRA 限流（时间取 Redis 服务器时间，多个网关副本时钟不一致也不影响）
+//
+//	KEYS[1] 限流键  ARGV[1] 突发上限  ARGV[2] 窗口内请求数  ARGV[3] 窗口（毫秒）
+//	返回 {是否放行, 剩余额度, 重试等待（毫秒）, 恢复满额等待（毫秒）}
+var gcraScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local emission = tonumber(ARGV[3]) / tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+  tat = now
+end
+local new_tat = tat + emission
+local diff = now - (new_tat - emission * burst)
+local remaining = math.floor(diff / emission)
+if remaining < 0 then
+  return {0, 0, math.ceil(-diff), math.ceil(tat - now)}
+end
+
+local reset = math.ceil(new_tat - now)
+redis.call('SET', KEYS[1], string.format('%.3f', new_tat), 'PX', reset)
+return {1, remaining, 0, reset}
+`)
+
+// RedisRateLimitStore Redis 限流存储
+type RedisRateLimitStore struct {
+	client *redis.Client
+}
+
+// NewRedisRateLimitStore 创建 Redis 限流存储
+func NewRedisRateLimitStore(client *redis.Client) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client}
+}
+
+// Allow 检查并消耗一个额度
+func (s *RedisRateLimitStore) Allow(ctx context.Context, key string, policy *RateLimitPolicy) (*RateLimitResult, error) {
+	values, err := gcraScript.Run(ctx, s.client, []string{key},
+		policy.burst(), policy.Limit, policy.Window*1000).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("限流脚本返回值无效: %v", values)
 	}
+	return &RateLimitResult{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
 }
 
-// Middleware 限流中间件
-func (rl *RateLimiter) Middleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		v := rl.getVisitor(ip)
-
-		select {
-		case <-v.limiter:
-			// 获取到令牌，允许请求
-			c.Next()
-		case <-time.After(time.Second):
-			// 超时，拒绝请求
-			response.ErrorWithCode(c, 429, "请求过于频繁，请稍后再试")
-			c.Abort()
-		}
+// MemoryRateLimitStore 进程内限流存储（未启用 Redis 时使用，只限制单个网关副本）
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	tats      map[string]time.Time // 理论到达时间
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryRateLimitStore 创建进程内限流存储
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{tats: make(map[string]time.Time), now: time.Now}
+}
+
+// Allow 检查并消耗一个额度（与 gcraScript 相同的算法）
+func (s *MemoryRateLimitStore) Allow(_ context.Context, key string, policy *RateLimitPolicy) (*RateLimitResult, error) {
+	emission := time.Duration(policy.Window) * time.Second / time.Duration(policy.Limit)
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	tat, ok := s.tats[key]
+	if !ok || tat.Before(now) {
+		tat = now
+	}
+	newTat := tat.Add(emission)
+	diff := now.Sub(newTat.Add(-emission * time.Duration(policy.burst())))
+	remaining := int(math.Floor(float64(diff) / float64(emission)))
+	if remaining < 0 {
+		return &RateLimitResult{RetryAfter: -diff, ResetAfter: tat.Sub(now)}, nil
 	}
+	s.tats[key] = newTat
+	return &RateLimitResult{Allowed: true, Remaining: remaining, ResetAfter: newTat.Sub(now)}, nil
 }
 
-// Close 关闭限流器
-func (rl *RateLimiter) Close() {
-	close(rl.cleanupCh)
+// sweep 每分钟清理已恢复满额的键
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	for key, tat := range s.tats {
+		if tat.Before(now) {
+			delete(s.tats, key)
+		}
+	}
+	s.lastSweep = now
 }
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestMemoryRateLimitStore GCRA：突发用完后按 window/limit 的间隔恢复
+func TestMemoryRateLimitStore(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	policy := &RateLimitPolicy{Name: "login", Key: RateLimitByIP, Limit: 5, Window: 60, Burst: 2}
+
+	for i, want := range []int{1, 0} {
+		r, _ := store.Allow(context.Background(), "k", policy)
+		if !r.Allowed || r.Remaining != want {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i, r, want)
+		}
+	}
+	r, _ := store.Allow(context.Background(), "k", policy)
+	if r.Allowed || r.RetryAfter != 12*time.Second {
+		t.Fatalf("over burst = %+v, want denied, retry after 12s", r)
+	}
+
+	now = now.Add(12 * time.Second)
+	if r, _ := store.Allow(context.Background(), "k", policy); !r.Allowed {
+		t.Errorf("after emission interval = %+v, want allowed", r)
+	}
+	if r, _ := store.Allow(context.Background(), "other", policy); !r.Allowed || r.Remaining != 1 {
+		t.Errorf("other key = %+v, want independent bucket", r)
+	}
+}
+
+// TestRateLimiterMiddleware 按路径和维度匹配策略，返回标准限流响应头
+func TestRateLimiterMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policies := []*RateLimitPolicy{
+		{Name: "login", Key: RateLimitByIP, Path: "/auth/login", Methods: []string{"POST"}, Limit: 1, Window: 60},
+		{Name: "tenant", Key: RateLimitByTenant, Limit: 100, Window: 60},
+	}
+	limiter := NewRateLimiter(NewMemoryRateLimitStore(), func() []*RateLimitPolicy { return policies })
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if tenant := c.GetHeader("X-Test-Tenant"); tenant != "" {
+			c.Set("tenant_code", tenant)
+		}
+	}, limiter.Middleware())
+	r.Any("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(method, path, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-Test-Tenant", tenant)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("POST", "/auth/login", ""); w.Code != http.StatusOK || w.Header().Get("RateLimit-Policy") != "1;w=60" {
+		t.Fatalf("first login = %d %v", w.Code, w.Header())
+	}
+	w := do("POST", "/auth/login", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("second login = %d %v, want 429 with Retry-After", w.Code, w.Header())
+	}
+	// 其他路径、其他方法不受登录策略影响
+	if w := do("GET", "/auth/login", ""); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("GET login = %d %v, want no policy", w.Code, w.Header())
+	}
+	if w := do("GET", "/admin/order", "ace"); w.Code != http.StatusOK || w.Header().Get("RateLimit-Remaining") != "99" {
+		t.Errorf("tenant request = %d %v, want tenant policy headers", w.Code, w.Header())
+	}
+}
+
+// TestRateLimitByIPTrustedProxies 按IP限流只采信可信代理转发的来源IP，客户端伪造 X-Forwarded-For 无法绕过
+func TestRateLimitByIPTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policies := []*RateLimitPolicy{{Name: "login", Key: RateLimitByIP, Limit: 1, Window: 60}}
+
+	tests := []struct {
+		name    string
+		trusted []string
+		remote  string
+		want    int // 换一个 X-Forwarded-For 后第二次请求的状态码
+	}{
+		{"未配置可信代理", nil, "198.51.100.4:5000", http.StatusTooManyRequests},
+		{"非可信代理转发", []string{"10.0.0.0/8"}, "198.51.100.4:5000", http.StatusTooManyRequests},
+		{"可信代理转发", []string{"10.0.0.0/8"}, "10.0.0.2:5000", http.StatusOK},
+	}
+	for _, tt := range tests {
+		limiter := NewRateLimiter(NewMemoryRateLimitStore(), func() []*RateLimitPolicy { return policies })
+		r := gin.New()
+		if err := r.SetTrustedProxies(tt.trusted); err != nil {
+			t.Fatalf("SetTrustedProxies() error = %v", err)
+		}
+		r.Use(limiter.Middleware())
+		r.POST("/auth/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		var code int
+		for _, forwarded := range []string{"203.0.113.1", "203.0.113.2"} {
+			req := httptest.NewRequest("POST", "/auth/login", nil)
+			req.RemoteAddr = tt.remote
+			req.Header.Set("X-Forwarded-For", forwarded)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			code = w.Code
+		}
+		if code != tt.want {
+			t.Errorf("%s: second request = %d, want %d", tt.name, code, tt.want)
+		}
+	}
+}
+
+// TestRateLimitPolicyValidate 校验策略
+func TestRateLimitPolicyValidate(t *testing.T) {
+	valid := RateLimitPolicy{Name: "a", Key: RateLimitByUser, Limit: 1, Window: 1}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	for _, p := range []RateLimitPolicy{
+		{Key: RateLimitByUser, Limit: 1, Window: 1},
+		{Name: "a", Key: "session", Limit: 1, Window: 1},
+		{Name: "a", Key: RateLimitByUser, Window: 1},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", p)
+		}
+	}
+}
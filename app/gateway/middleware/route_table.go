@@ -0,0 +1,245 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// templateParam 路径模板参数：{name} 匹配一段，{name*} 匹配剩余全部
+var templateParam = regexp.MustCompile(`\{(\w+)(\*?)\}`)
+
+// RouteTable 编译后的路由表
+//
+// 按完整前缀（网关前缀 + 路由前缀）长度倒序排列，前缀相同时条件（方法、Host、请求头）多的优先，
+// 再按前缀字典序，保证重叠前缀（如 /admin/order 和 /admin/order/cutting）每次都命中同一条路由。
+// 前缀按路径段匹配：/admin/order 匹配 /admin/order 和 /admin/order/xxx，不匹配 /admin/orders。
+type RouteTable struct {
+	routes []*compiledRoute
+}
+
+type compiledRoute struct {
+	prefix     string
+	fullPrefix string
+	config     *RouteConfig
+	methods    map[string]bool
+	rewrite    *regexp.Regexp
+	replace    string
+}
+
+// RouteMatch 请求命中的路由
+type RouteMatch struct {
+	Prefix     string       `json:"prefix"`      // 路由前缀
+	FullPrefix string       `json:"full_prefix"` // 网关前缀 + 路由前缀
+	Config     *RouteConfig `json:"config"`
+	TargetPath string       `json:"target_path"` // 转发到服务的路径（去掉网关前缀并按规则重写）
+}
+
+// Timeout 路由的转发超时（未配置返回0）
+func (m *RouteMatch) Timeout() time.Duration {
+	return time.Duration(m.Config.Timeout) * time.Millisecond
+}
+
+// RouteCandidate 前缀匹配的路由及其是否命中（路由测试用）
+type RouteCandidate struct {
+	Prefix     string `json:"prefix"`
+	FullPrefix string `json:"full_prefix"`
+	Matched    bool   `json:"matched"`
+	Reason     string `json:"reason,omitempty"` // 未命中的原因
+}
+
+// CompileRoutes 编译路由表，重写规则无效时返回错误
+func CompileRoutes(routes map[string]*RouteConfig) (*RouteTable, error) {
+	table := &RouteTable{routes: make([]*compiledRoute, 0, len(routes))}
+	for prefix, config := range routes {
+		route, err := compileRoute(prefix, config)
+		if err != nil {
+			return nil, err
+		}
+		table.routes = append(table.routes, route)
+	}
+	table.sort()
+	return table, nil
+}
+
+// CompileRoute 校验单条路由配置（添加、更新路由时使用）
+func CompileRoute(prefix string, config *RouteConfig) error {
+	_, err := compileRoute(prefix, config)
+	return err
+}
+
+func compileRoute(prefix string, config *RouteConfig) (*compiledRoute, error) {
+	route := &compiledRoute{
+		prefix:     prefix,
+		fullPrefix: config.GatewayPrefix + prefix,
+		config:     config,
+	}
+	if len(config.Methods) > 0 {
+		route.methods = make(map[string]bool, len(config.Methods))
+		for _, method := range config.Methods {
+			route.methods[strings.ToUpper(method)] = true
+		}
+	}
+	if rw := config.Rewrite; rw != nil {
+		pattern, replace := rw.Regex, rw.Replacement
+		if rw.From != "" {
+			pattern, replace = templatePattern(rw.From), templateParam.ReplaceAllString(rw.To, "$${$1}")
+		}
+		if pattern == "" {
+			return nil, fmt.Errorf("路由 %s 的重写规则缺少 regex 或 from", prefix)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("路由 %s 的重写规则无效: %v", prefix, err)
+		}
+		route.rewrite, route.replace = re, replace
+	}
+	return route, nil
+}
+
+// templatePattern 把路径模板（如 /v1/orders/{id}/{rest*}）转换为整段匹配的正则
+func templatePattern(tpl string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range templateParam.FindAllStringSubmatchIndex(tpl, -1) {
+		b.WriteString(regexp.QuoteMeta(tpl[last:loc[0]]))
+		name := tpl[loc[2]:loc[3]]
+		if loc[5] > loc[4] {
+			b.WriteString("(?P<" + name + ">.*)")
+		} else {
+			b.WriteString("(?P<" + name + ">[^/]+)")
+		}
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(tpl[last:]))
+	b.WriteString("$")
+	return b.String()
+}
+
+func (t *RouteTable) sort() {
+	sort.SliceStable(t.routes, func(i, j int) bool {
+		a, b := t.routes[i], t.routes[j]
+		if len(a.fullPrefix) != len(b.fullPrefix) {
+			return len(a.fullPrefix) > len(b.fullPrefix)
+		}
+		if a.conditions() != b.conditions() {
+			return a.conditions() > b.conditions()
+		}
+		return a.fullPrefix+"|"+a.prefix < b.fullPrefix+"|"+b.prefix
+	})
+}
+
+// Match 查找请求命中的路由，没有命中返回 nil
+func (t *RouteTable) Match(r *http.Request) *RouteMatch {
+	for _, route := range t.routes {
+		if !hasPathPrefix(r.URL.Path, route.fullPrefix) {
+			continue
+		}
+		if route.mismatch(r) == "" {
+			return route.match(r.URL.Path)
+		}
+	}
+	return nil
+}
+
+// Explain 列出前缀匹配的全部路由（按优先级）以及命中结果，用于排查路由
+func (t *RouteTable) Explain(r *http.Request) (*RouteMatch, []RouteCandidate) {
+	var hit *RouteMatch
+	candidates := []RouteCandidate{}
+	for _, route := range t.routes {
+		if !hasPathPrefix(r.URL.Path, route.fullPrefix) {
+			continue
+		}
+		c := RouteCandidate{Prefix: route.prefix, FullPrefix: route.fullPrefix}
+		switch reason := route.mismatch(r); {
+		case reason != "":
+			c.Reason = reason
+		case hit != nil:
+			c.Reason = "优先级更高的路由已命中"
+		default:
+			c.Matched = true
+			hit = route.match(r.URL.Path)
+		}
+		candidates = append(candidates, c)
+	}
+	return hit, candidates
+}
+
+// Len 路由数量
+func (t *RouteTable) Len() int {
+	return len(t.routes)
+}
+
+func (r *compiledRoute) conditions() int {
+	n := len(r.config.Headers)
+	if len(r.methods) > 0 {
+		n++
+	}
+	if r.config.Host != "" {
+		n++
+	}
+	return n
+}
+
+// mismatch 方法、Host、请求头条件不满足的原因，全部满足返回空
+func (r *compiledRoute) mismatch(req *http.Request) string {
+	if r.methods != nil && !r.methods[req.Method] {
+		return "请求方法不匹配"
+	}
+	if r.config.Host != "" && !matchHost(r.config.Host, req.Host) {
+		return "Host 不匹配"
+	}
+	for name, want := range r.config.Headers {
+		got := req.Header.Get(name)
+		if got == "" || (want != "*" && got != want) {
+			return "请求头 " + name + " 不匹配"
+		}
+	}
+	return ""
+}
+
+// match 计算转发路径：去掉网关前缀，再按重写规则改写
+func (r *compiledRoute) match(path string) *RouteMatch {
+	target := path
+	if gp := r.config.GatewayPrefix; gp != "" && strings.HasPrefix(path, gp) {
+		target = strings.TrimPrefix(path, gp)
+		if target == "" {
+			target = "/"
+		}
+	}
+	if r.rewrite != nil && r.rewrite.MatchString(target) {
+		target = r.rewrite.ReplaceAllString(target, r.replace)
+	}
+	return &RouteMatch{
+		Prefix:     r.prefix,
+		FullPrefix: r.fullPrefix,
+		Config:     r.config,
+		TargetPath: target,
+	}
+}
+
+// hasPathPrefix 按路径段匹配前缀
+func hasPathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || strings.HasSuffix(prefix, "/") || path[len(prefix)] == '/'
+}
+
+// matchHost Host 匹配（忽略端口，支持 *.example.com）
+func matchHost(pattern, host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	pattern = strings.ToLower(pattern)
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return host == pattern
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRouteTableLongestPrefix 重叠前缀总是命中最长的一条，且按路径段匹配
+func TestRouteTableLongestPrefix(t *testing.T) {
+	table, err := CompileRoutes(map[string]*RouteConfig{
+		"/order":         {ServiceName: "order", GatewayPrefix: "/admin"},
+		"/order/cutting": {ServiceName: "production", GatewayPrefix: "/admin"},
+		"/admin":         {ServiceName: "fallback"},
+	})
+	if err != nil {
+		t.Fatalf("CompileRoutes() error = %v", err)
+	}
+
+	cases := map[string]string{
+		"/admin/order/cutting/1": "production",
+		"/admin/order/cutting":   "production",
+		"/admin/order/1":         "order",
+		"/admin/orders":          "fallback",
+		"/admin":                 "fallback",
+	}
+	for path, want := range cases {
+		// 多次匹配，防止依赖 map 遍历顺序
+		for i := 0; i < 20; i++ {
+			m := table.Match(httptest.NewRequest("GET", path, nil))
+			if m == nil || m.Config.ServiceName != want {
+				t.Fatalf("Match(%s) = %+v, want %s", path, m, want)
+			}
+		}
+	}
+	if m := table.Match(httptest.NewRequest("GET", "/adminx", nil)); m != nil {
+		t.Errorf("Match(/adminx) = %+v, want nil", m)
+	}
+	if m := table.Match(httptest.NewRequest("GET", "/admin/order/cutting/1", nil)); m.TargetPath != "/order/cutting/1" {
+		t.Errorf("TargetPath = %s, want /order/cutting/1", m.TargetPath)
+	}
+}
+
+// TestRouteTableConditions 相同前缀按方法、Host、请求头条件区分
+func TestRouteTableConditions(t *testing.T) {
+	table, err := CompileRoutes(map[string]*RouteConfig{
+		"/files": {ServiceName: "files"},
+	})
+	if err != nil {
+		t.Fatalf("CompileRoutes() error = %v", err)
+	}
+	table.routes = append(table.routes,
+		mustCompile(t, "/files", &RouteConfig{ServiceName: "upload", Methods: []string{"post"}}),
+		mustCompile(t, "/files", &RouteConfig{ServiceName: "canary", Headers: map[string]string{"X-Canary": "1"}, Host: "*.example.com"}),
+	)
+	table.sort()
+
+	req := httptest.NewRequest("POST", "/files/a", nil)
+	if m := table.Match(req); m.Config.ServiceName != "upload" {
+		t.Errorf("POST = %s, want upload", m.Config.ServiceName)
+	}
+	req = httptest.NewRequest("GET", "/files/a", nil)
+	req.Host = "api.example.com:8080"
+	req.Header.Set("X-Canary", "1")
+	if m := table.Match(req); m.Config.ServiceName != "canary" {
+		t.Errorf("canary = %s, want canary", m.Config.ServiceName)
+	}
+	req.Host = "other.com"
+	match, candidates := table.Explain(req)
+	if match.Config.ServiceName != "files" || len(candidates) != 3 || candidates[0].Reason == "" {
+		t.Errorf("Explain() = %+v %+v, want files with canary rejected", match, candidates)
+	}
+}
+
+// TestRouteTableRewrite 正则和模板重写
+func TestRouteTableRewrite(t *testing.T) {
+	table, err := CompileRoutes(map[string]*RouteConfig{
+		"/v1": {ServiceName: "order", GatewayPrefix: "/api", Rewrite: &RewriteRule{Regex: `^/v1/(.*)$`, Replacement: "/order/$1"}},
+		"/v2": {ServiceName: "order", Rewrite: &RewriteRule{From: "/v2/orders/{id}/{rest*}", To: "/order/orders/{id}/{rest}"}},
+	})
+	if err != nil {
+		t.Fatalf("CompileRoutes() error = %v", err)
+	}
+
+	cases := map[string]string{
+		"/api/v1/orders/1":        "/order/orders/1",
+		"/v2/orders/42/items/7":   "/order/orders/42/items/7",
+		"/v2/customers/42/detail": "/v2/customers/42/detail", // 模板不匹配时不重写
+	}
+	for path, want := range cases {
+		if m := table.Match(httptest.NewRequest("GET", path, nil)); m == nil || m.TargetPath != want {
+			t.Errorf("Match(%s) = %+v, want target %s", path, m, want)
+		}
+	}
+
+	if err := CompileRoute("/bad", &RouteConfig{Rewrite: &RewriteRule{Regex: "("}}); err == nil {
+		t.Error("CompileRoute() with invalid regex should fail")
+	}
+}
+
+func mustCompile(t *testing.T, prefix string, config *RouteConfig) *compiledRoute {
+	t.Helper()
+	route, err := compileRoute(prefix, config)
+	if err != nil {
+		t.Fatalf("compileRoute() error = %v", err)
+	}
+	return route
+}
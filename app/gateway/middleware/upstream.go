@@ -0,0 +1,460 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"mule-cloud/core/config"
+	"mule-cloud/core/metrics"
+	"mule-cloud/core/tracing"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 负载均衡策略
+const (
+	StrategyRoundRobin     = "round_robin"
+	StrategyLeastConn      = "least_conn"
+	StrategyConsistentHash = "consistent_hash" // 按租户代码（没有租户时按客户端IP）
+)
+
+// ErrNoInstance 服务没有可用实例
+var ErrNoInstance = errors.New("没有可用的服务实例")
+
+// InstanceResolver 查询服务的健康实例地址（如 http://10.0.0.1:8001）
+type InstanceResolver func(serviceName string) ([]string, error)
+
+type balanceKeyCtx struct{}
+
+// WithBalanceKey 设置一致性哈希的分流键（租户代码）
+func WithBalanceKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, balanceKeyCtx{}, key)
+}
+
+// UpstreamManager 每个服务一个连接池化的反向代理
+//
+//   - 健康实例从 Consul 查询后缓存 cache_ttl 秒，不再每个请求查询一次
+//   - 所有服务共用一个 http.Transport，复用到各实例的连接
+//   - 实例连续失败（连接错误或 502/503/504）max_failures 次后摘除 eject_seconds 秒，全部被摘除时仍然尝试
+//   - GET/HEAD/OPTIONS 失败时换一个实例重试
+type UpstreamManager struct {
+	resolve   InstanceResolver
+	cfg       config.LoadBalanceConfig
+	transport http.RoundTripper
+
+	mu    sync.Mutex
+	pools map[string]*UpstreamPool
+}
+
+// NewUpstreamManager 创建上游管理器
+func NewUpstreamManager(resolve InstanceResolver, cfg config.LoadBalanceConfig) *UpstreamManager {
+	if cfg.Strategy == "" {
+		cfg.Strategy = StrategyRoundRobin
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = 5
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = 1
+	}
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = 3
+	}
+	if cfg.EjectSeconds <= 0 {
+		cfg.EjectSeconds = 30
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = 32
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 0 // 不限制总数，按实例限制
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = 90 * time.Second
+
+	return &UpstreamManager{
+		resolve:   resolve,
+		cfg:       cfg,
+		transport: tracing.Transport(transport), // 每次转发（包括重试）一个客户端 span，注入 traceparent
+		pools:     make(map[string]*UpstreamPool),
+	}
+}
+
+// Proxy 服务的反向代理（按服务复用）
+func (m *UpstreamManager) Proxy(serviceName string) *httputil.ReverseProxy {
+	return m.pool(serviceName).proxy
+}
+
+// Instances 服务的健康实例（使用缓存）
+func (m *UpstreamManager) Instances(serviceName string) ([]string, error) {
+	instances, err := m.pool(serviceName).instances()
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		addrs = append(addrs, inst.addr)
+	}
+	return addrs, nil
+}
+
+// Stats 各服务实例的状态（监控用）
+func (m *UpstreamManager) Stats() map[string][]InstanceStats {
+	m.mu.Lock()
+	pools := make(map[string]*UpstreamPool, len(m.pools))
+	for name, p := range m.pools {
+		pools[name] = p
+	}
+	m.mu.Unlock()
+
+	stats := make(map[string][]InstanceStats, len(pools))
+	now := time.Now()
+	for name, p := range pools {
+		p.mu.Lock()
+		for _, inst := range p.list {
+			stats[name] = append(stats[name], inst.stats(now))
+		}
+		p.mu.Unlock()
+	}
+	return stats
+}
+
+func (m *UpstreamManager) pool(serviceName string) *UpstreamPool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.pools[serviceName]; ok {
+		return p
+	}
+
+	strategy := m.cfg.Strategy
+	if s, ok := m.cfg.Services[serviceName]; ok && s != "" {
+		strategy = s
+	}
+	p := &UpstreamPool{
+		service:  serviceName,
+		strategy: strategy,
+		manager:  m,
+		byAddr:   make(map[string]*instance),
+	}
+	p.proxy = &httputil.ReverseProxy{
+		Director: func(r *http.Request) {
+			// 实例在 RoundTrip 中选择，这里只设置协议并去掉客户端的 Host
+			r.URL.Scheme = "http"
+			r.URL.Host = serviceName
+			r.Host = ""
+			if _, ok := r.Header["User-Agent"]; !ok {
+				r.Header.Set("User-Agent", "")
+			}
+		},
+		Transport:    p,
+		ErrorHandler: proxyErrorHandler(serviceName),
+	}
+	m.pools[serviceName] = p
+	return p
+}
+
+// proxyErrorHandler 转发失败：超时返回 504，没有可用实例返回 503，其他返回 502
+func proxyErrorHandler(serviceName string) func(http.ResponseWriter, *http.Request, error) {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			status = http.StatusGatewayTimeout
+		case errors.Is(err, ErrNoInstance):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, context.Canceled):
+			return // 客户端已断开
+		}
+		upstreamLog.Ctx(r.Context()).Error("转发失败", zap.String("upstream", serviceName), zap.String("method", r.Method), zap.String("path", r.URL.Path), zap.Error(err))
+		w.WriteHeader(status)
+	}
+}
+
+// UpstreamPool 一个服务的实例池
+type UpstreamPool struct {
+	service  string
+	strategy string
+	manager  *UpstreamManager
+	proxy    *httputil.ReverseProxy
+
+	refresh   sync.Mutex // 同一时间只有一个请求查询 Consul（查询期间不持有 mu）
+	mu        sync.Mutex
+	list      []*instance          // 当前健康实例（按地址排序）
+	byAddr    map[string]*instance // 保留实例状态（刷新列表时不丢失失败计数）
+	fetchedAt time.Time
+	next      uint64 // 轮询计数
+}
+
+type instance struct {
+	addr         string
+	host         string
+	active       int64 // 进行中的请求数
+	failures     int   // 连续失败次数
+	ejectedUntil time.Time
+}
+
+// InstanceStats 实例状态
+type InstanceStats struct {
+	Addr     string `json:"addr"`
+	Active   int64  `json:"active"`
+	Failures int    `json:"failures"`
+	Ejected  bool   `json:"ejected"`
+}
+
+func (i *instance) stats(now time.Time) InstanceStats {
+	return InstanceStats{
+		Addr:     i.addr,
+		Active:   atomic.LoadInt64(&i.active),
+		Failures: i.failures,
+		Ejected:  now.Before(i.ejectedUntil),
+	}
+}
+
+// instances 健康实例列表，缓存过期时重新查询（查询失败时继续使用旧列表）
+//
+// 查询 Consul 时不持有 mu，避免阻塞选择实例和统计请求；已有旧列表时，其他请求不等待正在进行的查询。
+func (p *UpstreamPool) instances() ([]*instance, error) {
+	ttl := time.Duration(p.manager.cfg.CacheTTL) * time.Second
+	list, fresh := p.cached(ttl)
+	if fresh {
+		return list, nil
+	}
+
+	if !p.refresh.TryLock() {
+		if len(list) > 0 {
+			return list, nil
+		}
+		p.refresh.Lock()
+	}
+	defer p.refresh.Unlock()
+	// 等待期间其他请求可能已经刷新
+	if list, fresh = p.cached(ttl); fresh {
+		return list, nil
+	}
+
+	addrs, err := p.manager.resolve(p.service)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		if len(p.list) > 0 {
+			upstreamLog.Warn("刷新服务实例失败，继续使用缓存", zap.String("upstream", p.service), zap.Error(err))
+			p.fetchedAt = time.Now()
+			return p.list, nil
+		}
+		return nil, err
+	}
+	sort.Strings(addrs)
+
+	list = make([]*instance, 0, len(addrs))
+	byAddr := make(map[string]*instance, len(addrs))
+	for _, addr := range addrs {
+		inst, ok := p.byAddr[addr]
+		if !ok {
+			u, err := url.Parse(addr)
+			if err != nil {
+				upstreamLog.Warn("忽略无效的实例地址", zap.String("upstream", p.service), zap.String("instance", addr))
+				continue
+			}
+			inst = &instance{addr: addr, host: u.Host}
+		}
+		list = append(list, inst)
+		byAddr[addr] = inst
+	}
+	p.list, p.byAddr, p.fetchedAt = list, byAddr, time.Now()
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoInstance, p.service)
+	}
+	return list, nil
+}
+
+// cached 当前实例列表及其是否仍在缓存有效期内
+func (p *UpstreamPool) cached(ttl time.Duration) ([]*instance, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.list, time.Since(p.fetchedAt) < ttl && len(p.list) > 0
+}
+
+// pick 选择实例：跳过已尝试的实例，优先未被摘除的实例
+func (p *UpstreamPool) pick(key string, tried map[*instance]bool) (*instance, error) {
+	list, err := p.instances()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var healthy, fallback []*instance
+	for _, inst := range list {
+		if tried[inst] {
+			continue
+		}
+		fallback = append(fallback, inst)
+		if !now.Before(inst.ejectedUntil) {
+			healthy = append(healthy, inst)
+		}
+	}
+	candidates := healthy
+	if len(candidates) == 0 {
+		candidates = fallback // 全部被摘除时仍然尝试，避免整个服务不可用
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoInstance, p.service)
+	}
+
+	switch {
+	case p.strategy == StrategyLeastConn:
+		best := candidates[0]
+		for _, inst := range candidates[1:] {
+			if atomic.LoadInt64(&inst.active) < atomic.LoadInt64(&best.active) {
+				best = inst
+			}
+		}
+		return best, nil
+	case p.strategy == StrategyConsistentHash && key != "":
+		// 最高随机权重（rendezvous）哈希：实例增减时只影响落在该实例上的租户
+		var best *instance
+		var bestScore uint64
+		for _, inst := range candidates {
+			h := fnv.New64a()
+			h.Write([]byte(key))
+			h.Write([]byte(inst.addr))
+			if score := h.Sum64(); best == nil || score > bestScore {
+				best, bestScore = inst, score
+			}
+		}
+		return best, nil
+	default:
+		p.next++
+		return candidates[p.next%uint64(len(candidates))], nil
+	}
+}
+
+// report 记录实例的调用结果（被动健康检查）
+func (p *UpstreamPool) report(inst *instance, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ok {
+		inst.failures = 0
+		return
+	}
+	inst.failures++
+	if inst.failures >= p.manager.cfg.MaxFailures {
+		inst.ejectedUntil = time.Now().Add(time.Duration(p.manager.cfg.EjectSeconds) * time.Second)
+		inst.failures = 0
+		upstreamLog.Warn("摘除实例", zap.String("upstream", p.service), zap.String("instance", inst.addr), zap.Int("eject_seconds", p.manager.cfg.EjectSeconds))
+	}
+}
+
+// RoundTrip 选择实例转发，GET/HEAD/OPTIONS 失败时换实例重试
+func (p *UpstreamPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, _ := req.Context().Value(balanceKeyCtx{}).(string)
+	if key == "" {
+		key, _, _ = net.SplitHostPort(req.RemoteAddr)
+	}
+	retries := 0
+	if isSafeMethod(req.Method) && (req.Body == nil || req.Body == http.NoBody) {
+		retries = max(p.manager.cfg.Retries, 0)
+	}
+
+	tried := make(map[*instance]bool)
+	for attempt := 0; ; attempt++ {
+		inst, err := p.pick(key, tried)
+		if err != nil {
+			metrics.UpstreamErrors.WithLabelValues(p.service, "", "no_instance").Inc()
+			return nil, err
+		}
+		tried[inst] = true
+
+		out := req.Clone(req.Context())
+		out.URL.Host = inst.host
+		atomic.AddInt64(&inst.active, 1)
+		resp, err := p.manager.transport.RoundTrip(out)
+
+		failed := err != nil || isUpstreamFailure(resp.StatusCode)
+		// 客户端取消或路由超时不算实例故障
+		if err != nil && req.Context().Err() != nil {
+			atomic.AddInt64(&inst.active, -1)
+			if errors.Is(req.Context().Err(), context.DeadlineExceeded) {
+				metrics.UpstreamErrors.WithLabelValues(p.service, inst.addr, "timeout").Inc()
+			}
+			return nil, err
+		}
+		p.report(inst, !failed)
+		if failed {
+			metrics.UpstreamErrors.WithLabelValues(p.service, inst.addr, upstreamErrorReason(resp, err)).Inc()
+		}
+
+		if failed && attempt < retries && req.Context().Err() == nil {
+			atomic.AddInt64(&inst.active, -1)
+			if resp != nil {
+				io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+				resp.Body.Close()
+			}
+			upstreamLog.Ctx(req.Context()).Info("实例失败，换实例重试", zap.String("upstream", p.service), zap.String("instance", inst.addr), zap.String("method", req.Method), zap.String("path", req.URL.Path))
+			continue
+		}
+		if err != nil {
+			atomic.AddInt64(&inst.active, -1)
+			return nil, err
+		}
+		resp.Body = &activeBody{ReadCloser: resp.Body, inst: inst}
+		return resp, nil
+	}
+}
+
+// activeBody 响应体读完关闭后才结束计数（最少连接数按进行中的请求计算）
+type activeBody struct {
+	io.ReadCloser
+	inst *instance
+	once sync.Once
+}
+
+func (b *activeBody) Close() error {
+	b.once.Do(func() { atomic.AddInt64(&b.inst.active, -1) })
+	return b.ReadCloser.Close()
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func isUpstreamFailure(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// upstreamErrorReason 转发失败原因（指标标签）：连接错误、超时或上游返回的状态码
+func upstreamErrorReason(resp *http.Response, err error) string {
+	switch {
+	case err == nil:
+		return strconv.Itoa(resp.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "error"
+	}
+}
+
+// UpstreamStatsHandler 各服务实例的负载和摘除状态
+func UpstreamStatsHandler(m *UpstreamManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"code": 0,
+			"msg":  "获取成功",
+			"data": m.Stats(),
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mule-cloud/core/config"
+)
+
+// testBackend 记录请求次数的上游实例
+type testBackend struct {
+	server *httptest.Server
+	hits   int64
+}
+
+func newTestBackend(t *testing.T, status int) *testBackend {
+	t.Helper()
+	b := &testBackend{}
+	b.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&b.hits, 1)
+		w.WriteHeader(status)
+		w.Write([]byte(r.URL.Path))
+	}))
+	t.Cleanup(b.server.Close)
+	return b
+}
+
+func (b *testBackend) count() int64 { return atomic.LoadInt64(&b.hits) }
+
+func newTestManager(cfg config.LoadBalanceConfig, addrs ...string) (*UpstreamManager, *int64) {
+	var lookups int64
+	return NewUpstreamManager(func(string) ([]string, error) {
+		atomic.AddInt64(&lookups, 1)
+		return addrs, nil
+	}, cfg), &lookups
+}
+
+func serve(m *UpstreamManager, method, path string, ctx context.Context) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+	w := httptest.NewRecorder()
+	m.Proxy("order").ServeHTTP(w, req)
+	return w
+}
+
+// TestUpstreamRoundRobin 轮询分发到全部实例，实例列表缓存不会每次查询
+func TestUpstreamRoundRobin(t *testing.T) {
+	a, b := newTestBackend(t, http.StatusOK), newTestBackend(t, http.StatusOK)
+	m, lookups := newTestManager(config.LoadBalanceConfig{}, a.server.URL, b.server.URL)
+
+	for i := 0; i < 10; i++ {
+		if w := serve(m, "GET", "/order/1", nil); w.Code != http.StatusOK || w.Body.String() != "/order/1" {
+			t.Fatalf("ServeHTTP() = %d %s", w.Code, w.Body.String())
+		}
+	}
+	if a.count() != 5 || b.count() != 5 {
+		t.Errorf("hits = %d/%d, want 5/5", a.count(), b.count())
+	}
+	if *lookups != 1 {
+		t.Errorf("lookups = %d, want 1", *lookups)
+	}
+}
+
+// TestUpstreamRetryAndEject GET 失败换实例重试，连续失败后摘除；POST 不重试
+func TestUpstreamRetryAndEject(t *testing.T) {
+	bad, good := newTestBackend(t, http.StatusServiceUnavailable), newTestBackend(t, http.StatusOK)
+	m, _ := newTestManager(config.LoadBalanceConfig{MaxFailures: 2}, bad.server.URL, good.server.URL)
+
+	for i := 0; i < 4; i++ {
+		if w := serve(m, "GET", "/order/1", nil); w.Code != http.StatusOK {
+			t.Fatalf("GET = %d, want 200 after retry", w.Code)
+		}
+	}
+	// 第2次失败后摘除，之后只访问健康实例
+	if bad.count() != 2 || good.count() != 4 {
+		t.Errorf("hits = %d/%d, want 2/4", bad.count(), good.count())
+	}
+	stats := m.Stats()["order"]
+	if len(stats) != 2 || !stats[0].Ejected && !stats[1].Ejected {
+		t.Errorf("Stats() = %+v, want one ejected instance", stats)
+	}
+
+	// 不可重试的请求直接返回上游结果
+	m2, _ := newTestManager(config.LoadBalanceConfig{}, bad.server.URL)
+	if w := serve(m2, "POST", "/order", nil); w.Code != http.StatusServiceUnavailable || bad.count() != 3 {
+		t.Errorf("POST = %d (hits %d), want 503 without retry", w.Code, bad.count())
+	}
+}
+
+// TestUpstreamErrors 实例不可达返回 502，没有实例返回 503
+func TestUpstreamErrors(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+	m, _ := newTestManager(config.LoadBalanceConfig{Retries: -1}, dead.URL)
+	if w := serve(m, "GET", "/order/1", nil); w.Code != http.StatusBadGateway {
+		t.Errorf("dead instance = %d, want 502", w.Code)
+	}
+
+	empty, _ := newTestManager(config.LoadBalanceConfig{})
+	if w := serve(empty, "GET", "/order/1", nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("no instance = %d, want 503", w.Code)
+	}
+}
+
+// TestUpstreamConsistentHash 同一租户固定落在同一实例
+func TestUpstreamConsistentHash(t *testing.T) {
+	backends := []*testBackend{newTestBackend(t, http.StatusOK), newTestBackend(t, http.StatusOK), newTestBackend(t, http.StatusOK)}
+	addrs := make([]string, 0, len(backends))
+	for _, b := range backends {
+		addrs = append(addrs, b.server.URL)
+	}
+	m, _ := newTestManager(config.LoadBalanceConfig{Strategy: StrategyConsistentHash}, addrs...)
+
+	ctx := WithBalanceKey(context.Background(), "tenant-a")
+	for i := 0; i < 9; i++ {
+		serve(m, "GET", "/order/1", ctx)
+	}
+	var hit []string
+	for _, b := range backends {
+		if b.count() > 0 {
+			hit = append(hit, b.server.URL)
+		}
+	}
+	if len(hit) != 1 {
+		t.Errorf("tenant-a hit %s, want a single instance", strings.Join(hit, ","))
+	}
+}
+
+// TestUpstreamRefreshOutsideLock 查询 Consul 期间不阻塞转发和状态查询，已有旧列表时直接使用
+func TestUpstreamRefreshOutsideLock(t *testing.T) {
+	b := newTestBackend(t, http.StatusOK)
+	var lookups int64
+	started, release := make(chan struct{}), make(chan struct{})
+	m := NewUpstreamManager(func(string) ([]string, error) {
+		if atomic.AddInt64(&lookups, 1) == 2 {
+			close(started)
+			<-release
+		}
+		return []string{b.server.URL}, nil
+	}, config.LoadBalanceConfig{})
+
+	if w := serve(m, "GET", "/order/1", nil); w.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() = %d", w.Code)
+	}
+
+	// 缓存过期后第一个请求去查询（阻塞在 Consul），其他请求继续用旧列表
+	pool := m.pool("order")
+	pool.mu.Lock()
+	pool.fetchedAt = time.Time{}
+	pool.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Instances("order")
+	}()
+	<-started
+
+	if w := serve(m, "GET", "/order/2", nil); w.Code != http.StatusOK {
+		t.Errorf("ServeHTTP() during refresh = %d", w.Code)
+	}
+	if stats := m.Stats()["order"]; len(stats) != 1 {
+		t.Errorf("Stats() during refresh = %v", stats)
+	}
+	close(release)
+	<-done
+	if n := atomic.LoadInt64(&lookups); n != 2 {
+		t.Errorf("lookups = %d, want 2", n)
+	}
+}
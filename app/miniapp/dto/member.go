@@ -64,8 +64,14 @@ type GetProfileResponse struct {
 	Skills       []SkillInfo       `json:"skills"`
 	Certificates []CertificateInfo `json:"certificates"`
 
-	// 薪资信息（部分隐藏）
-	SalaryType string `json:"salary_type"` // 只返回薪资类型，不返回具体金额
+	// 薪资信息（按角色的敏感字段权限返回，无权限时银行卡号脱敏、金额不返回）
+	SalaryType      string   `json:"salary_type"`
+	BaseSalary      *float64 `json:"base_salary,omitempty"`
+	HourlyRate      *float64 `json:"hourly_rate,omitempty"`
+	PieceRate       *float64 `json:"piece_rate,omitempty"`
+	BankName        string   `json:"bank_name"`
+	BankAccount     string   `json:"bank_account"`
+	BankAccountName string   `json:"bank_account_name"`
 
 	// 状态
 	Status     string `json:"status"`
@@ -147,8 +153,8 @@ type UpdateContactInfoResponse struct {
 
 // ========== 辅助函数 ==========
 
-// BuildProfileResponse 构建档案响应（从模型转换）
-func BuildProfileResponse(member *models.TenantMember, maskSensitive bool) *GetProfileResponse {
+// BuildProfileResponse 构建档案响应（从模型转换），敏感字段按 access 脱敏或隐藏
+func BuildProfileResponse(member *models.TenantMember, access *models.FieldAccess) *GetProfileResponse {
 	resp := &GetProfileResponse{
 		// 基础关联
 		ID:      member.ID,
@@ -204,8 +210,11 @@ func BuildProfileResponse(member *models.TenantMember, maskSensitive bool) *GetP
 		WorkYears:       member.WorkYears,
 		WorkMonths:      member.WorkMonths,
 
-		// 薪资信息（只返回类型）
-		SalaryType: member.SalaryType,
+		// 薪资信息
+		SalaryType:      member.SalaryType,
+		BankName:        member.BankName,
+		BankAccount:     member.BankAccount,
+		BankAccountName: member.BankAccountName,
 
 		// 状态
 		Status:     member.Status,
@@ -219,10 +228,16 @@ func BuildProfileResponse(member *models.TenantMember, maskSensitive bool) *GetP
 	}
 
 	// 敏感信息脱敏
-	if maskSensitive {
-		if member.IDCardNo != "" {
-			resp.IDCardNo = member.MaskIDCardNo()
-		}
+	if !access.CanRead(models.MemberFieldIDCard) {
+		resp.IDCardNo = member.MaskIDCardNo()
+	}
+	if !access.CanRead(models.MemberFieldBankAccount) {
+		resp.BankAccount = member.MaskBankAccount()
+	}
+	if access.CanRead(models.MemberFieldSalary) {
+		resp.BaseSalary = &member.BaseSalary
+		resp.HourlyRate = &member.HourlyRate
+		resp.PieceRate = &member.PieceRate
 	}
 
 	// 转换技能列表
@@ -313,6 +328,15 @@ type UpdateMemberRequest struct {
 	// 工作相关
 	EmployedAt int64  `json:"employed_at"`
 	Status     string `json:"status"`
+
+	// 薪资与银行卡（敏感字段，需要角色的 write 权限；脱敏后的值视为未修改）
+	SalaryType      string   `json:"salary_type"`
+	BaseSalary      *float64 `json:"base_salary"`
+	HourlyRate      *float64 `json:"hourly_rate"`
+	PieceRate       *float64 `json:"piece_rate"`
+	BankName        string   `json:"bank_name"`
+	BankAccount     string   `json:"bank_account"`
+	BankAccountName string   `json:"bank_account_name"`
 }
 
 // ImportResult 导入结果
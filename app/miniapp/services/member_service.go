@@ -6,8 +6,9 @@ import (
 	"fmt"
 	"mule-cloud/app/miniapp/dto"
 	tenantCtx "mule-cloud/core/context"
-	"mule-cloud/core/logger"
+	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -17,6 +18,7 @@ var (
 	ErrMemberNotFound    = errors.New("员工不存在")
 	ErrIDCardNoImmutable = errors.New("身份证号已填写，如需修改请联系管理员")
 	ErrInvalidField      = errors.New("字段值无效")
+	ErrFieldForbidden    = errors.New("无权修改敏感字段")
 )
 
 // IMemberService 员工服务接口
@@ -71,14 +73,14 @@ func (s *MemberService) GetProfile(ctx context.Context, userID string) (*dto.Get
 	// 1. 通过UserID查询租户成员信息
 	member, err := s.memberRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		logger.Error("查询员工档案失败",
+		log.Ctx(ctx).Error("查询员工档案失败",
 			zap.String("user_id", userID),
 			zap.Error(err))
 		return nil, fmt.Errorf("查询员工档案失败: %w", err)
 	}
 
 	if member == nil {
-		logger.Warn("员工档案不存在", zap.String("user_id", userID))
+		log.Ctx(ctx).Warn("员工档案不存在", zap.String("user_id", userID))
 		return nil, ErrMemberNotFound
 	}
 
@@ -90,10 +92,10 @@ func (s *MemberService) GetProfile(ctx context.Context, userID string) (*dto.Get
 		member.WorkYears, member.WorkMonths = member.CalculateWorkYears()
 	}
 
-	// 3. 构建响应（敏感信息脱敏）
-	resp := dto.BuildProfileResponse(member, true)
+	// 3. 构建响应（本人查看时敏感信息脱敏）
+	resp := dto.BuildProfileResponse(member, &models.FieldAccess{})
 
-	logger.Info("获取员工档案成功",
+	log.Ctx(ctx).Info("获取员工档案成功",
 		zap.String("user_id", userID),
 		zap.String("member_id", member.ID))
 
@@ -105,7 +107,7 @@ func (s *MemberService) UpdateBasicInfo(ctx context.Context, userID string, req
 	// 1. 获取当前员工信息
 	member, err := s.memberRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		logger.Error("查询员工信息失败", zap.Error(err))
+		log.Ctx(ctx).Error("查询员工信息失败", zap.Error(err))
 		return fmt.Errorf("查询员工信息失败: %w", err)
 	}
 
@@ -135,7 +137,7 @@ func (s *MemberService) UpdateBasicInfo(ctx context.Context, userID string, req
 			// 首次填写
 			updateData["id_card_no"] = req.IDCardNo
 			updateData["id_card_type"] = "idcard" // 默认身份证
-			logger.Info("首次填写身份证号",
+			log.Ctx(ctx).Info("首次填写身份证号",
 				zap.String("user_id", userID),
 				zap.String("id_card_no_masked", maskIDCardNo(req.IDCardNo)))
 		} else if member.IDCardNo != req.IDCardNo {
@@ -189,7 +191,7 @@ func (s *MemberService) UpdateBasicInfo(ctx context.Context, userID string, req
 	// 6. 更新租户成员信息
 	err = s.memberRepo.Update(ctx, member.ID, updateData)
 	if err != nil {
-		logger.Error("更新员工基本信息失败",
+		log.Ctx(ctx).Error("更新员工基本信息失败",
 			zap.String("user_id", userID),
 			zap.Error(err))
 		return fmt.Errorf("更新员工基本信息失败: %w", err)
@@ -209,11 +211,11 @@ func (s *MemberService) UpdateBasicInfo(ctx context.Context, userID string, req
 
 	err = s.wechatUserRepo.Update(systemCtx, userID, wechatUserUpdate)
 	if err != nil {
-		logger.Warn("同步更新WechatUser失败", zap.Error(err))
+		log.Ctx(ctx).Warn("同步更新WechatUser失败", zap.Error(err))
 		// 不影响主流程，只记录日志
 	}
 
-	logger.Info("更新员工基本信息成功",
+	log.Ctx(ctx).Info("更新员工基本信息成功",
 		zap.String("user_id", userID),
 		zap.String("member_id", member.ID))
 
@@ -225,7 +227,7 @@ func (s *MemberService) UpdateContactInfo(ctx context.Context, userID string, re
 	// 1. 获取当前员工信息
 	member, err := s.memberRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		logger.Error("查询员工信息失败", zap.Error(err))
+		log.Ctx(ctx).Error("查询员工信息失败", zap.Error(err))
 		return fmt.Errorf("查询员工信息失败: %w", err)
 	}
 
@@ -265,7 +267,7 @@ func (s *MemberService) UpdateContactInfo(ctx context.Context, userID string, re
 	// 3. 更新租户成员信息
 	err = s.memberRepo.Update(ctx, member.ID, updateData)
 	if err != nil {
-		logger.Error("更新员工联系信息失败",
+		log.Ctx(ctx).Error("更新员工联系信息失败",
 			zap.String("user_id", userID),
 			zap.Error(err))
 		return fmt.Errorf("更新员工联系信息失败: %w", err)
@@ -281,12 +283,12 @@ func (s *MemberService) UpdateContactInfo(ctx context.Context, userID string, re
 
 		err = s.wechatUserRepo.Update(systemCtx, userID, wechatUserUpdate)
 		if err != nil {
-			logger.Warn("同步更新WechatUser手机号失败", zap.Error(err))
+			log.Ctx(ctx).Warn("同步更新WechatUser手机号失败", zap.Error(err))
 			// 不影响主流程，只记录日志
 		}
 	}
 
-	logger.Info("更新员工联系信息成功",
+	log.Ctx(ctx).Info("更新员工联系信息成功",
 		zap.String("user_id", userID),
 		zap.String("member_id", member.ID))
 
@@ -298,7 +300,7 @@ func (s *MemberService) UploadPhoto(ctx context.Context, userID string, req dto.
 	// 1. 获取当前员工信息
 	member, err := s.memberRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		logger.Error("查询员工信息失败", zap.Error(err))
+		log.Ctx(ctx).Error("查询员工信息失败", zap.Error(err))
 		return fmt.Errorf("查询员工信息失败: %w", err)
 	}
 
@@ -324,7 +326,7 @@ func (s *MemberService) UploadPhoto(ctx context.Context, userID string, req dto.
 	// 3. 更新租户成员信息
 	err = s.memberRepo.Update(ctx, member.ID, updateData)
 	if err != nil {
-		logger.Error("更新员工照片失败",
+		log.Ctx(ctx).Error("更新员工照片失败",
 			zap.String("user_id", userID),
 			zap.String("type", req.Type),
 			zap.Error(err))
@@ -341,12 +343,12 @@ func (s *MemberService) UploadPhoto(ctx context.Context, userID string, req dto.
 
 		err = s.wechatUserRepo.Update(systemCtx, userID, wechatUserUpdate)
 		if err != nil {
-			logger.Warn("同步更新WechatUser头像失败", zap.Error(err))
+			log.Ctx(ctx).Warn("同步更新WechatUser头像失败", zap.Error(err))
 			// 不影响主流程，只记录日志
 		}
 	}
 
-	logger.Info("更新员工照片成功",
+	log.Ctx(ctx).Info("更新员工照片成功",
 		zap.String("user_id", userID),
 		zap.String("member_id", member.ID),
 		zap.String("type", req.Type))
@@ -356,6 +358,11 @@ func (s *MemberService) UploadPhoto(ctx context.Context, userID string, req dto.
 
 // ========== 辅助函数 ==========
 
+// isMasked 是否为脱敏后的值（前端原样提交时不覆盖原值）
+func isMasked(value string) bool {
+	return strings.Contains(value, "*")
+}
+
 // maskIDCardNo 脱敏身份证号（用于日志）
 func maskIDCardNo(idCardNo string) string {
 	if len(idCardNo) < 8 {
@@ -427,18 +434,16 @@ func (s *MemberService) GetMemberList(ctx context.Context, req dto.GetMemberList
 		filter["status"] = req.Status
 	}
 
-	// 查询总数
-	total, err := s.memberRepo.Count(ctx, filter)
+	// 分页查询（仓库按数据范围过滤）
+	members, total, err := s.memberRepo.List(ctx, filter, req.Page, req.PageSize)
 	if err != nil {
-		logger.Error("查询员工总数失败", zap.Error(err))
-		return nil, fmt.Errorf("查询员工总数失败: %w", err)
+		log.Ctx(ctx).Error("查询员工列表失败", zap.Error(err))
+		return nil, fmt.Errorf("查询员工列表失败: %w", err)
 	}
 
-	// 分页查询
-	members, err := s.memberRepo.FindWithPage(ctx, filter, req.Page, req.PageSize)
+	access, err := repository.ResolveFieldAccess(ctx)
 	if err != nil {
-		logger.Error("查询员工列表失败", zap.Error(err))
-		return nil, fmt.Errorf("查询员工列表失败: %w", err)
+		return nil, fmt.Errorf("查询字段权限失败: %w", err)
 	}
 
 	// 转换为响应格式
@@ -451,7 +456,7 @@ func (s *MemberService) GetMemberList(ctx context.Context, req dto.GetMemberList
 		if member.EmployedAt > 0 {
 			member.WorkYears, member.WorkMonths = member.CalculateWorkYears()
 		}
-		list = append(list, dto.BuildProfileResponse(member, false)) // 管理员不脱敏
+		list = append(list, dto.BuildProfileResponse(member, access))
 	}
 
 	return &dto.GetMemberListResponse{
@@ -466,7 +471,7 @@ func (s *MemberService) GetMemberList(ctx context.Context, req dto.GetMemberList
 func (s *MemberService) GetMemberDetail(ctx context.Context, id string) (*dto.GetProfileResponse, error) {
 	member, err := s.memberRepo.Get(ctx, id)
 	if err != nil {
-		logger.Error("查询员工详情失败", zap.Error(err))
+		log.Ctx(ctx).Error("查询员工详情失败", zap.Error(err))
 		return nil, fmt.Errorf("查询员工详情失败: %w", err)
 	}
 
@@ -482,11 +487,20 @@ func (s *MemberService) GetMemberDetail(ctx context.Context, id string) (*dto.Ge
 		member.WorkYears, member.WorkMonths = member.CalculateWorkYears()
 	}
 
-	return dto.BuildProfileResponse(member, false), nil // 管理员不脱敏
+	access, err := repository.ResolveFieldAccess(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询字段权限失败: %w", err)
+	}
+	return dto.BuildProfileResponse(member, access), nil
 }
 
 // UpdateMember 更新员工信息（管理后台）
 func (s *MemberService) UpdateMember(ctx context.Context, id string, req dto.UpdateMemberRequest) error {
+	access, err := repository.ResolveFieldAccess(ctx)
+	if err != nil {
+		return fmt.Errorf("查询字段权限失败: %w", err)
+	}
+
 	// 构建更新数据
 	updateData := map[string]interface{}{
 		"updated_at": time.Now().Unix(),
@@ -502,7 +516,7 @@ func (s *MemberService) UpdateMember(ctx context.Context, id string, req dto.Upd
 	if req.Phone != "" {
 		updateData["phone"] = req.Phone
 	}
-	if req.IDCardNo != "" {
+	if req.IDCardNo != "" && !isMasked(req.IDCardNo) {
 		updateData["id_card_no"] = req.IDCardNo
 	}
 
@@ -531,13 +545,45 @@ func (s *MemberService) UpdateMember(ctx context.Context, id string, req dto.Upd
 		updateData["status"] = req.Status
 	}
 
-	err := s.memberRepo.Update(ctx, id, updateData)
+	// 薪资与银行卡
+	if req.SalaryType != "" {
+		updateData["salary_type"] = req.SalaryType
+	}
+	if req.BaseSalary != nil {
+		updateData["base_salary"] = *req.BaseSalary
+	}
+	if req.HourlyRate != nil {
+		updateData["hourly_rate"] = *req.HourlyRate
+	}
+	if req.PieceRate != nil {
+		updateData["piece_rate"] = *req.PieceRate
+	}
+	if req.BankName != "" {
+		updateData["bank_name"] = req.BankName
+	}
+	if req.BankAccount != "" && !isMasked(req.BankAccount) {
+		updateData["bank_account"] = req.BankAccount
+	}
+	if req.BankAccountName != "" {
+		updateData["bank_account_name"] = req.BankAccountName
+	}
+
+	// 敏感字段需要角色的 write 权限
+	for group, fields := range models.MemberSensitiveFields {
+		for _, field := range fields {
+			if _, ok := updateData[field]; ok && !access.CanWrite(group) {
+				return fmt.Errorf("%w: %s", ErrFieldForbidden, group)
+			}
+		}
+	}
+
+	err = s.memberRepo.Update(ctx, id, updateData)
 	if err != nil {
-		logger.Error("更新员工信息失败", zap.Error(err))
+		log.Ctx(ctx).Error("更新员工信息失败", zap.Error(err))
 		return fmt.Errorf("更新员工信息失败: %w", err)
 	}
 
-	logger.Info("更新员工信息成功", zap.String("id", id))
+	log.Ctx(ctx).Info("更新员工信息成功", zap.String("id", id))
 	return nil
 }
 
@@ -545,26 +591,35 @@ func (s *MemberService) UpdateMember(ctx context.Context, id string, req dto.Upd
 func (s *MemberService) DeleteMember(ctx context.Context, id string) error {
 	err := s.memberRepo.Delete(ctx, id)
 	if err != nil {
-		logger.Error("删除员工失败", zap.Error(err))
+		log.Ctx(ctx).Error("删除员工失败", zap.Error(err))
 		return fmt.Errorf("删除员工失败: %w", err)
 	}
 
-	logger.Info("删除员工成功", zap.String("id", id))
+	log.Ctx(ctx).Info("删除员工成功", zap.String("id", id))
 	return nil
 }
 
 // ExportMembers 导出员工数据
 func (s *MemberService) ExportMembers(ctx context.Context) ([]byte, error) {
-	// 查询所有员工
-	members, err := s.memberRepo.Find(ctx, map[string]interface{}{"is_deleted": 0})
+	// 查询数据范围内的员工
+	members, _, err := s.memberRepo.List(ctx, map[string]interface{}{"is_deleted": 0}, 0, 0)
 	if err != nil {
-		logger.Error("查询员工失败", zap.Error(err))
+		log.Ctx(ctx).Error("查询员工失败", zap.Error(err))
 		return nil, fmt.Errorf("查询员工失败: %w", err)
 	}
+	access, err := repository.ResolveFieldAccess(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询字段权限失败: %w", err)
+	}
+	showSalary := access.CanRead(models.MemberFieldSalary)
 
 	// 生成Excel（简化版本，实际需要引入Excel库）
-	// 这里返回CSV格式作为示例
-	csv := "工号,姓名,性别,手机号,部门,岗位,状态\n"
+	// 这里返回CSV格式作为示例，敏感字段与接口一致：无权限时脱敏，薪资列不导出
+	csv := "工号,姓名,性别,手机号,部门,岗位,状态,身份证号,开户行,银行卡号"
+	if showSalary {
+		csv += ",基本工资,时薪,计件单价"
+	}
+	csv += "\n"
 	for _, m := range members {
 		gender := "未知"
 		if m.Gender == 1 {
@@ -572,13 +627,39 @@ func (s *MemberService) ExportMembers(ctx context.Context) ([]byte, error) {
 		} else if m.Gender == 2 {
 			gender = "女"
 		}
-		csv += fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s\n",
-			m.JobNumber, m.Name, gender, m.Phone, m.Department, m.Position, m.Status)
+		row := dto.BuildProfileResponse(m, access)
+		csv += fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%s,%s,%s",
+			m.JobNumber, m.Name, gender, m.Phone, m.Department, m.Position, m.Status,
+			row.IDCardNo, row.BankName, row.BankAccount)
+		if showSalary {
+			csv += fmt.Sprintf(",%.2f,%.2f,%.2f", m.BaseSalary, m.HourlyRate, m.PieceRate)
+		}
+		csv += "\n"
 	}
 
 	return []byte(csv), nil
 }
 
+// EncryptMemberSensitiveFields 加密各租户员工档案中的明文或旧密钥密文（配置或轮换密钥后启动时执行）
+func EncryptMemberSensitiveFields(ctx context.Context) {
+	tenants, err := repository.NewTenantRepository().Find(ctx, map[string]interface{}{"is_deleted": 0})
+	if err != nil {
+		log.Ctx(ctx).Error("查询租户失败，跳过敏感字段加密", zap.Error(err))
+		return
+	}
+	memberRepo := repository.NewTenantMemberRepository()
+	for _, tenant := range tenants {
+		count, err := memberRepo.EncryptSensitiveFields(tenantCtx.WithTenantCode(ctx, tenant.Code))
+		if err != nil {
+			log.Ctx(ctx).Error("加密员工敏感字段失败", zap.String("tenant_code", tenant.Code), zap.Error(err))
+			continue
+		}
+		if count > 0 {
+			log.Ctx(ctx).Info("已加密员工敏感字段", zap.String("tenant_code", tenant.Code), zap.Int64("count", count))
+		}
+	}
+}
+
 // ImportMembers 导入员工数据
 func (s *MemberService) ImportMembers(ctx context.Context, data []byte) (*dto.ImportResult, error) {
 	// 简化版本：解析CSV
@@ -591,7 +672,7 @@ func (s *MemberService) ImportMembers(ctx context.Context, data []byte) (*dto.Im
 	}
 
 	// TODO: 实现导入逻辑
-	logger.Warn("导入功能待完善")
+	log.Ctx(ctx).Warn("导入功能待完善")
 
 	return result, nil
 }
@@ -10,7 +10,7 @@ import (
 	"mule-cloud/app/miniapp/dto"
 	tenantCtx "mule-cloud/core/context"
 	jwtPkg "mule-cloud/core/jwt"
-	"mule-cloud/core/logger"
+	"mule-cloud/core/quota"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
 	"net/http"
@@ -27,6 +27,7 @@ var (
 	ErrInvalidInviteCode = errors.New("无效的邀请码")
 	ErrAlreadyMember     = errors.New("已经是该租户成员")
 	ErrNoPermission      = errors.New("无权访问该租户")
+	ErrMemberQuota       = errors.New("该企业成员数已达到套餐上限，请联系企业管理员")
 )
 
 // IWechatService 微信服务接口
@@ -97,12 +98,12 @@ func (s *WechatService) WechatLogin(req dto.WechatLoginRequest) (*dto.WechatLogi
 	// 1. 调用微信接口，用code换取session_key和openid
 	wxSession, err := s.getWechatSession(req.Code)
 	if err != nil {
-		logger.Error("微信登录失败", zap.Error(err))
+		log.Error("微信登录失败", zap.Error(err))
 		return nil, fmt.Errorf("微信登录失败: %w", err)
 	}
 
 	if wxSession.ErrCode != 0 {
-		logger.Error("微信API返回错误",
+		log.Error("微信API返回错误",
 			zap.Int("errcode", wxSession.ErrCode),
 			zap.String("errmsg", wxSession.ErrMsg))
 		return nil, fmt.Errorf("微信API错误: %s", wxSession.ErrMsg)
@@ -121,12 +122,12 @@ func (s *WechatService) WechatLogin(req dto.WechatLoginRequest) (*dto.WechatLogi
 			Province:  req.Province,
 			City:      req.City,
 		}
-		logger.Info("使用明文用户信息", zap.String("nickname", req.Nickname))
+		log.Info("使用明文用户信息", zap.String("nickname", req.Nickname))
 	} else if req.EncryptedData != "" && req.IV != "" {
 		// 备用：解密加密的用户信息（旧版API）
 		wechatUserInfoData, err = s.decryptUserInfo(wxSession.SessionKey, req.EncryptedData, req.IV)
 		if err != nil {
-			logger.Warn("解密用户信息失败，继续使用基本信息", zap.Error(err))
+			log.Warn("解密用户信息失败，继续使用基本信息", zap.Error(err))
 		}
 	}
 
@@ -143,13 +144,13 @@ func (s *WechatService) WechatLogin(req dto.WechatLoginRequest) (*dto.WechatLogi
 	}
 
 	if err != nil {
-		logger.Error("查询用户失败", zap.Error(err))
+		log.Error("查询用户失败", zap.Error(err))
 		return nil, fmt.Errorf("查询用户失败: %w", err)
 	}
 
 	// 4. 如果用户不存在，创建新用户
 	if wechatUser == nil {
-		logger.Info("首次登录，创建新用户",
+		log.Info("首次登录，创建新用户",
 			zap.String("openid", wxSession.OpenID),
 			zap.String("unionid", wxSession.UnionID))
 
@@ -175,7 +176,7 @@ func (s *WechatService) WechatLogin(req dto.WechatLoginRequest) (*dto.WechatLogi
 
 		err = s.wechatUserRepo.Create(systemCtx, wechatUser)
 		if err != nil {
-			logger.Error("创建用户失败", zap.Error(err))
+			log.Error("创建用户失败", zap.Error(err))
 			return nil, fmt.Errorf("创建用户失败: %w", err)
 		}
 	} else {
@@ -197,7 +198,7 @@ func (s *WechatService) WechatLogin(req dto.WechatLoginRequest) (*dto.WechatLogi
 
 		err = s.wechatUserRepo.Update(systemCtx, wechatUser.ID, updateData)
 		if err != nil {
-			logger.Warn("更新用户信息失败", zap.Error(err))
+			log.Warn("更新用户信息失败", zap.Error(err))
 		} else {
 			// 更新内存中的用户信息
 			if wechatUserInfoData != nil {
@@ -214,7 +215,7 @@ func (s *WechatService) WechatLogin(req dto.WechatLoginRequest) (*dto.WechatLogi
 	// 4. 查询用户关联的租户（系统库）
 	tenantMaps, err := s.userTenantRepo.GetUserActiveTenants(systemCtx, wechatUser.ID)
 	if err != nil {
-		logger.Error("查询用户租户失败", zap.Error(err))
+		log.Error("查询用户租户失败", zap.Error(err))
 		return nil, fmt.Errorf("查询用户租户失败: %w", err)
 	}
 
@@ -223,7 +224,7 @@ func (s *WechatService) WechatLogin(req dto.WechatLoginRequest) (*dto.WechatLogi
 
 	// 没有关联任何租户，需要绑定
 	if len(tenantMaps) == 0 {
-		logger.Info("用户没有关联租户，需要绑定",
+		log.Info("用户没有关联租户，需要绑定",
 			zap.String("user_id", wechatUser.ID))
 		return &dto.WechatLoginResponse{
 			NeedBindTenant: true,
@@ -233,7 +234,7 @@ func (s *WechatService) WechatLogin(req dto.WechatLoginRequest) (*dto.WechatLogi
 
 	// 只有一个租户，直接登录
 	if len(tenantMaps) == 1 {
-		logger.Info("用户只有一个租户，直接登录",
+		log.Info("用户只有一个租户，直接登录",
 			zap.String("user_id", wechatUser.ID),
 			zap.String("tenant_id", tenantMaps[0].TenantID))
 
@@ -250,7 +251,7 @@ func (s *WechatService) WechatLogin(req dto.WechatLoginRequest) (*dto.WechatLogi
 	}
 
 	// 多个租户，需要用户选择
-	logger.Info("用户有多个租户，需要选择",
+	log.Info("用户有多个租户，需要选择",
 		zap.String("user_id", wechatUser.ID),
 		zap.Int("tenant_count", len(tenantMaps)))
 
@@ -258,7 +259,7 @@ func (s *WechatService) WechatLogin(req dto.WechatLoginRequest) (*dto.WechatLogi
 	for _, tm := range tenantMaps {
 		tenant, err := s.tenantRepo.Get(systemCtx, tm.TenantID)
 		if err != nil || tenant == nil {
-			logger.Warn("查询租户信息失败",
+			log.Warn("查询租户信息失败",
 				zap.String("tenant_id", tm.TenantID),
 				zap.Error(err))
 			continue
@@ -292,7 +293,7 @@ func (s *WechatService) BindTenant(req dto.BindTenantRequest) (*dto.BindTenantRe
 	// 这里暂时直接通过code查询租户
 	tenant, err := s.tenantRepo.GetByCode(systemCtx, req.InviteCode)
 	if err != nil || tenant == nil {
-		logger.Warn("无效的邀请码", zap.String("invite_code", req.InviteCode))
+		log.Warn("无效的邀请码", zap.String("invite_code", req.InviteCode))
 		return nil, ErrInvalidInviteCode
 	}
 
@@ -302,6 +303,15 @@ func (s *WechatService) BindTenant(req dto.BindTenantRequest) (*dto.BindTenantRe
 		return nil, ErrAlreadyMember
 	}
 
+	// 检查套餐的成员数配额
+	if err := quota.Check(ctx, tenant.Code, quota.Members, 1); err != nil {
+		if errors.Is(err, quota.ErrQuotaExceeded) {
+			log.Warn("成员数已达到套餐上限", zap.String("tenant_code", tenant.Code), zap.Error(err))
+			return nil, ErrMemberQuota
+		}
+		return nil, err
+	}
+
 	// 3. 获取用户信息
 	wechatUser, err := s.wechatUserRepo.Get(systemCtx, req.UserID)
 	if err != nil || wechatUser == nil {
@@ -326,7 +336,7 @@ func (s *WechatService) BindTenant(req dto.BindTenantRequest) (*dto.BindTenantRe
 
 	err = s.memberRepo.Create(tenantCtx, member)
 	if err != nil {
-		logger.Error("创建成员失败", zap.Error(err))
+		log.Error("创建成员失败", zap.Error(err))
 		return nil, fmt.Errorf("创建成员失败: %w", err)
 	}
 
@@ -348,14 +358,14 @@ func (s *WechatService) BindTenant(req dto.BindTenantRequest) (*dto.BindTenantRe
 	if err != nil {
 		// 回滚：删除成员记录
 		s.memberRepo.HardDelete(tenantCtx, member.ID)
-		logger.Error("创建关联失败", zap.Error(err))
+		log.Error("创建关联失败", zap.Error(err))
 		return nil, fmt.Errorf("创建关联失败: %w", err)
 	}
 
 	// 6. 更新用户的租户列表
 	err = s.wechatUserRepo.AddTenant(systemCtx, req.UserID, tenant.ID)
 	if err != nil {
-		logger.Warn("更新用户租户列表失败", zap.Error(err))
+		log.Warn("更新用户租户列表失败", zap.Error(err))
 	}
 
 	// 7. 生成Token
@@ -364,7 +374,7 @@ func (s *WechatService) BindTenant(req dto.BindTenantRequest) (*dto.BindTenantRe
 		return nil, err
 	}
 
-	logger.Info("绑定租户成功",
+	log.Info("绑定租户成功",
 		zap.String("user_id", req.UserID),
 		zap.String("tenant_id", tenant.ID),
 		zap.String("tenant_code", tenant.Code))
@@ -430,7 +440,7 @@ func (s *WechatService) SwitchTenant(userID string, req dto.SwitchTenantRequest)
 		return nil, err
 	}
 
-	logger.Info("切换租户成功",
+	log.Info("切换租户成功",
 		zap.String("user_id", userID),
 		zap.String("tenant_id", req.TenantID))
 
@@ -530,7 +540,7 @@ func (s *WechatService) UpdateUserInfo(userID string, req dto.UpdateUserInfoRequ
 		// 获取用户所有租户
 		tenantMaps, err := s.userTenantRepo.GetUserTenants(systemCtx, userID)
 		if err != nil {
-			logger.Warn("获取用户租户列表失败", zap.Error(err))
+			log.Warn("获取用户租户列表失败", zap.Error(err))
 		}
 
 		// 更新所有租户的成员信息
@@ -555,7 +565,7 @@ func (s *WechatService) UpdateUserInfo(userID string, req dto.UpdateUserInfoRequ
 			if tenantMap.MemberID != "" {
 				err = s.memberRepo.Update(tenantContext, tenantMap.MemberID, memberUpdate)
 				if err != nil {
-					logger.Warn("更新租户成员信息失败",
+					log.Warn("更新租户成员信息失败",
 						zap.String("tenant_code", tenantMap.TenantCode),
 						zap.String("member_id", tenantMap.MemberID),
 						zap.Error(err))
@@ -589,7 +599,7 @@ func (s *WechatService) generateTokenForTenant(user *models.WechatUser, tenantMa
 	if user.UnionID != "" {
 		member, err = s.memberRepo.GetByUnionID(tenantContext, user.UnionID)
 		if err != nil {
-			logger.Error("通过UnionID查询成员失败", zap.Error(err))
+			log.Error("通过UnionID查询成员失败", zap.Error(err))
 		}
 	}
 
@@ -597,13 +607,13 @@ func (s *WechatService) generateTokenForTenant(user *models.WechatUser, tenantMa
 	if member == nil {
 		member, err = s.memberRepo.GetByUserID(tenantContext, user.ID)
 		if err != nil {
-			logger.Error("通过UserID查询成员失败", zap.Error(err))
+			log.Error("通过UserID查询成员失败", zap.Error(err))
 		}
 	}
 
 	// 如果仍然找不到成员记录，可能是数据不一致，尝试自动修复
 	if member == nil {
-		logger.Warn("租户成员记录不存在，尝试自动创建",
+		log.Warn("租户成员记录不存在，尝试自动创建",
 			zap.String("tenant_code", tenantMap.TenantCode),
 			zap.String("user_id", user.ID),
 			zap.String("union_id", user.UnionID))
@@ -625,7 +635,7 @@ func (s *WechatService) generateTokenForTenant(user *models.WechatUser, tenantMa
 
 		err = s.memberRepo.Create(tenantContext, member)
 		if err != nil {
-			logger.Error("自动创建成员记录失败",
+			log.Error("自动创建成员记录失败",
 				zap.String("tenant_code", tenantMap.TenantCode),
 				zap.String("user_id", user.ID),
 				zap.Error(err))
@@ -638,10 +648,10 @@ func (s *WechatService) generateTokenForTenant(user *models.WechatUser, tenantMa
 			"updated_at": time.Now().Unix(),
 		})
 		if updateErr != nil {
-			logger.Warn("更新租户映射的member_id失败", zap.Error(updateErr))
+			log.Warn("更新租户映射的member_id失败", zap.Error(updateErr))
 		}
 
-		logger.Info("自动创建成员记录成功",
+		log.Info("自动创建成员记录成功",
 			zap.String("tenant_code", tenantMap.TenantCode),
 			zap.String("user_id", user.ID),
 			zap.String("member_id", member.ID))
@@ -729,7 +739,7 @@ func (s *WechatService) decryptUserInfo(sessionKey, encryptedData, iv string) (*
 	// 注意：这里需要实现微信的AES解密
 	// 由于这需要引入加密库，这里先返回nil
 	// TODO: 实现微信数据解密
-	logger.Warn("微信数据解密功能未实现")
+	log.Warn("微信数据解密功能未实现")
 	return nil, errors.New("微信数据解密功能未实现")
 }
 
@@ -0,0 +1,32 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package transport
+
+import (
+	"mule-cloud/app/miniapp/dto"
+	"mule-cloud/core/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operations 处理器的接口描述（cmd/openapi-gen 生成，服务的 /openapi.json 使用）
+var Operations = openapi.Operations{
+	"mule-cloud/app/miniapp/transport.GetProfileHandler":        {Summary: "获取个人档案（需要JWT认证）", Tag: "member", Response: dto.GetProfileResponse{}},
+	"mule-cloud/app/miniapp/transport.UpdateBasicInfoHandler":   {Summary: "更新基本信息（需要JWT认证）", Tag: "member", Request: dto.UpdateBasicInfoRequest{}, Response: dto.UpdateBasicInfoResponse{}},
+	"mule-cloud/app/miniapp/transport.UpdateContactInfoHandler": {Summary: "更新联系信息（需要JWT认证）", Tag: "member", Request: dto.UpdateContactInfoRequest{}, Response: dto.UpdateContactInfoResponse{}},
+	"mule-cloud/app/miniapp/transport.UploadPhotoHandler":       {Summary: "上传照片（需要JWT认证）", Tag: "member", Request: dto.UploadPhotoRequest{}, Response: dto.UploadPhotoResponse{}},
+	"mule-cloud/app/miniapp/transport.GetMemberListHandler":     {Summary: "获取员工列表", Tag: "member_admin", Request: dto.GetMemberListRequest{}, Response: dto.GetMemberListResponse{}},
+	"mule-cloud/app/miniapp/transport.GetMemberDetailHandler":   {Summary: "获取员工详情", Tag: "member_admin", Response: dto.GetProfileResponse{}},
+	"mule-cloud/app/miniapp/transport.UpdateMemberHandler":      {Summary: "更新员工信息", Tag: "member_admin", Request: dto.UpdateMemberRequest{}, Response: map[string]interface{}{}},
+	"mule-cloud/app/miniapp/transport.DeleteMemberHandler":      {Summary: "删除员工", Tag: "member_admin", Response: map[string]interface{}{}},
+	"mule-cloud/app/miniapp/transport.ExportMembersHandler":     {Summary: "导出员工数据", Tag: "member_admin", Binary: true},
+	"mule-cloud/app/miniapp/transport.ImportMembersHandler":     {Summary: "导入员工数据", Tag: "member_admin", Files: []string{"file"}, Response: dto.ImportResult{}},
+	"mule-cloud/app/miniapp/transport.WechatLoginHandler":       {Summary: "微信登录", Tag: "wechat", Request: dto.WechatLoginRequest{}, Response: dto.WechatLoginResponse{}},
+	"mule-cloud/app/miniapp/transport.BindTenantHandler":        {Summary: "绑定租户", Tag: "wechat", Request: dto.BindTenantRequest{}, Response: dto.BindTenantResponse{}},
+	"mule-cloud/app/miniapp/transport.SelectTenantHandler":      {Summary: "选择租户", Tag: "wechat", Request: dto.SelectTenantRequest{}, Response: dto.SelectTenantResponse{}},
+	"mule-cloud/app/miniapp/transport.SwitchTenantHandler":      {Summary: "切换租户（需要JWT认证）", Tag: "wechat", Request: dto.SwitchTenantRequest{}, Response: dto.SwitchTenantResponse{}},
+	"mule-cloud/app/miniapp/transport.GetUserInfoHandler":       {Summary: "获取用户信息（需要JWT认证）", Tag: "wechat", Response: dto.GetUserInfoResponse{}},
+	"mule-cloud/app/miniapp/transport.UpdateUserInfoHandler":    {Summary: "更新用户信息（需要JWT认证）", Tag: "wechat", Request: dto.UpdateUserInfoRequest{}, Response: dto.UpdateUserInfoResponse{}},
+	"mule-cloud/app/miniapp/transport.GetPhoneNumberHandler":    {Summary: "获取微信手机号（需要JWT认证）", Tag: "wechat", Request: dto.GetPhoneNumberRequest{}, Response: dto.GetPhoneNumberResponse{}},
+	"mule-cloud/app/miniapp/transport.UnbindPhoneHandler":       {Summary: "解绑手机号（需要JWT认证）", Tag: "wechat", Response: gin.H{}},
+}
@@ -48,6 +48,24 @@ type OrderProcedureRequest struct {
 	Procedures []models.OrderProcedure `json:"procedures"` // 工序清单
 }
 
+// OrderStandardMinutesRequest 设置订单工序标准工时请求
+type OrderStandardMinutesRequest struct {
+	ID    string                     `uri:"id"`
+	Items []OrderStandardMinutesItem `json:"items"` // 需要调整的工序
+}
+
+// OrderStandardMinutesItem 工序标准工时
+type OrderStandardMinutesItem struct {
+	Sequence int     `json:"sequence"` // 工序顺序
+	SAM      float64 `json:"sam"`      // 标准工时（分钟/件）
+}
+
+// OrderStandardMinutesResponse 设置订单工序标准工时响应
+type OrderStandardMinutesResponse struct {
+	Order          *models.Order `json:"order"`
+	UpdatedReports int64         `json:"updated_reports"` // 重算产出工时的上报记录数
+}
+
 // OrderUpdateRequest 更新订单请求
 type OrderUpdateRequest struct {
 	ID           string                  `uri:"id" binding:"required"`
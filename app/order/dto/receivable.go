@@ -0,0 +1,173 @@
+package dto
+
+import "mule-cloud/internal/models"
+
+// ==================== 客户应收账户 ====================
+
+// CustomerAccountSaveRequest 保存客户信用条款请求
+type CustomerAccountSaveRequest struct {
+	CustomerID     string  `uri:"customer_id" binding:"required"`
+	CustomerName   string  `json:"customer_name"`
+	CreditDays     int     `json:"credit_days"`     // 账期（天）
+	CreditLimit    float64 `json:"credit_limit"`    // 信用额度（0 表示不限）
+	OpeningBalance float64 `json:"opening_balance"` // 期初应收余额
+	Remark         string  `json:"remark"`
+}
+
+// CustomerAccountResponse 客户应收账户响应
+type CustomerAccountResponse struct {
+	Account *models.CustomerAccount `json:"account"`
+	Aging   *AgingRow               `json:"aging"` // 当前账龄及余额
+}
+
+// ==================== 发票 ====================
+
+// InvoiceCreateRequest 开票请求（按发货单或订单总额）
+type InvoiceCreateRequest struct {
+	SourceType  string `json:"source_type" binding:"required,oneof=shipment order"` // shipment-发货单 order-订单
+	SourceID    string `json:"source_id" binding:"required"`                        // 发货单ID或订单ID
+	InvoiceDate int64  `json:"invoice_date"`                                        // 开票日期（默认当前时间）
+	Remark      string `json:"remark"`
+	CreatedBy   string `json:"created_by"`
+}
+
+// InvoiceListRequest 发票列表请求
+type InvoiceListRequest struct {
+	Page       int    `json:"page" form:"page"`
+	PageSize   int    `json:"page_size" form:"page_size"`
+	InvoiceNo  string `json:"invoice_no" form:"invoice_no"`
+	CustomerID string `json:"customer_id" form:"customer_id"`
+	SalesmanID string `json:"salesman_id" form:"salesman_id"`
+	OrderID    string `json:"order_id" form:"order_id"`
+	Status     *int   `json:"status" form:"status"`
+	StartDate  int64  `json:"start_date" form:"start_date"` // 开票日期起
+	EndDate    int64  `json:"end_date" form:"end_date"`     // 开票日期止
+}
+
+// InvoiceListResponse 发票列表响应
+type InvoiceListResponse struct {
+	Invoices []*models.Invoice `json:"invoices"`
+	Total    int64             `json:"total"`
+}
+
+// InvoiceResponse 发票响应
+type InvoiceResponse struct {
+	Invoice         *models.Invoice `json:"invoice"`
+	OverCreditLimit bool            `json:"over_credit_limit"` // 开票后客户应收是否超出信用额度
+}
+
+// InvoiceVoidRequest 作废发票请求
+type InvoiceVoidRequest struct {
+	ID     string `uri:"id" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// ==================== 收款 ====================
+
+// ReceiptCreateRequest 登记收款请求（未指定核销明细时按到期日先后自动核销）
+type ReceiptCreateRequest struct {
+	CustomerID  string                     `json:"customer_id" binding:"required"`
+	Amount      float64                    `json:"amount" binding:"required,gt=0"`
+	Method      string                     `json:"method"`      // bank-银行转账 cash-现金 other-其他
+	Reference   string                     `json:"reference"`   // 银行流水号等
+	ReceivedAt  int64                      `json:"received_at"` // 收款日期（默认当前时间）
+	Allocations []models.ReceiptAllocation `json:"allocations"` // 指定核销的发票
+	Remark      string                     `json:"remark"`
+	CreatedBy   string                     `json:"created_by"`
+}
+
+// ReceiptListRequest 收款单列表请求
+type ReceiptListRequest struct {
+	Page       int    `json:"page" form:"page"`
+	PageSize   int    `json:"page_size" form:"page_size"`
+	CustomerID string `json:"customer_id" form:"customer_id"`
+	Status     *int   `json:"status" form:"status"`
+	StartDate  int64  `json:"start_date" form:"start_date"` // 收款日期起
+	EndDate    int64  `json:"end_date" form:"end_date"`     // 收款日期止
+}
+
+// ReceiptListResponse 收款单列表响应
+type ReceiptListResponse struct {
+	Receipts []*models.Receipt `json:"receipts"`
+	Total    int64             `json:"total"`
+}
+
+// ReceiptResponse 收款单响应
+type ReceiptResponse struct {
+	Receipt *models.Receipt `json:"receipt"`
+}
+
+// ReceiptVoidRequest 作废收款单请求
+type ReceiptVoidRequest struct {
+	ID     string `uri:"id" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// ==================== 账龄与对账单 ====================
+
+// AgingRequest 账龄分析请求
+type AgingRequest struct {
+	GroupBy    string `json:"group_by" form:"group_by"`       // customer-按客户（默认） salesman-按业务员
+	AsOf       int64  `json:"as_of" form:"as_of"`             // 统计截止时间（默认当前时间）
+	CustomerID string `json:"customer_id" form:"customer_id"` // 按客户过滤
+	SalesmanID string `json:"salesman_id" form:"salesman_id"` // 按业务员过滤
+}
+
+// AgingRow 账龄分析行
+type AgingRow struct {
+	GroupID        string  `json:"group_id"`        // 客户ID或业务员ID
+	GroupName      string  `json:"group_name"`      // 客户名称或业务员名称
+	InvoiceCount   int     `json:"invoice_count"`   // 未结清发票数
+	Current        float64 `json:"current"`         // 未到期
+	Days1To30      float64 `json:"days_1_30"`       // 逾期 1-30 天
+	Days31To60     float64 `json:"days_31_60"`      // 逾期 31-60 天
+	Days61To90     float64 `json:"days_61_90"`      // 逾期 61-90 天
+	Over90         float64 `json:"over_90"`         // 逾期 90 天以上
+	OpeningBalance float64 `json:"opening_balance"` // 期初余额（仅按客户统计）
+	Unapplied      float64 `json:"unapplied"`       // 未核销预收款（仅按客户统计）
+	Outstanding    float64 `json:"outstanding"`     // 应收余额
+	CreditLimit    float64 `json:"credit_limit"`    // 信用额度（仅按客户统计）
+	OverLimit      bool    `json:"over_limit"`      // 是否超出信用额度
+}
+
+// AgingResponse 账龄分析响应
+type AgingResponse struct {
+	GroupBy string     `json:"group_by"`
+	AsOf    int64      `json:"as_of"`
+	Rows    []AgingRow `json:"rows"`
+	Total   AgingRow   `json:"total"`
+}
+
+// StatementRequest 客户对账单请求
+type StatementRequest struct {
+	CustomerID string `uri:"customer_id" binding:"required"`
+	StartDate  int64  `form:"start_date"` // 期间起（默认本月1日）
+	EndDate    int64  `form:"end_date"`   // 期间止（默认当前时间）
+}
+
+// StatementLine 对账单明细行
+type StatementLine struct {
+	Date        int64   `json:"date"`
+	Type        string  `json:"type"` // invoice-发票 receipt-收款
+	DocNo       string  `json:"doc_no"`
+	Description string  `json:"description"`
+	Debit       float64 `json:"debit"`   // 应收增加
+	Credit      float64 `json:"credit"`  // 收款
+	Balance     float64 `json:"balance"` // 余额
+}
+
+// StatementResponse 客户对账单
+type StatementResponse struct {
+	CustomerID     string          `json:"customer_id"`
+	CustomerName   string          `json:"customer_name"`
+	CreditDays     int             `json:"credit_days"`
+	CreditLimit    float64         `json:"credit_limit"`
+	StartDate      int64           `json:"start_date"`
+	EndDate        int64           `json:"end_date"`
+	OpeningBalance float64         `json:"opening_balance"` // 期初余额
+	Lines          []StatementLine `json:"lines"`
+	TotalDebit     float64         `json:"total_debit"`
+	TotalCredit    float64         `json:"total_credit"`
+	ClosingBalance float64         `json:"closing_balance"` // 期末余额
+	Aging          AgingRow        `json:"aging"`           // 截至期末的账龄
+}
@@ -0,0 +1,142 @@
+package dto
+
+import "mule-cloud/internal/models"
+
+// CartonSpec 装箱规格（同一规格可一次装多箱）
+type CartonSpec struct {
+	PackType    string            `json:"pack_type" binding:"required,oneof=solid mixed"` // 装箱方式：solid-单色单码 mixed-混色混码
+	Items       []models.PackItem `json:"items" binding:"required,min=1"`                 // 每箱明细（混色混码即为配比）
+	Count       int               `json:"count"`                                          // 箱数（默认1）
+	GrossWeight float64           `json:"gross_weight"`                                   // 每箱毛重（kg）
+	NetWeight   float64           `json:"net_weight"`                                     // 每箱净重（kg）
+	Dimensions  string            `json:"dimensions"`                                     // 外箱尺寸
+}
+
+// PackCartonsRequest 装箱请求
+type PackCartonsRequest struct {
+	OrderID   string       `json:"order_id" binding:"required"`
+	Cartons   []CartonSpec `json:"cartons" binding:"required,min=1"`
+	CreatedBy string       `json:"created_by"`
+}
+
+// PackCartonsResponse 装箱响应
+type PackCartonsResponse struct {
+	Cartons []*models.PackingCarton `json:"cartons"`
+	Count   int                     `json:"count"`
+}
+
+// CartonListRequest 装箱列表请求
+type CartonListRequest struct {
+	Page       int    `json:"page" form:"page"`
+	PageSize   int    `json:"page_size" form:"page_size"`
+	OrderID    string `json:"order_id" form:"order_id"`
+	ContractNo string `json:"contract_no" form:"contract_no"`
+	Status     *int   `json:"status" form:"status"`
+}
+
+// CartonListResponse 装箱列表响应
+type CartonListResponse struct {
+	Cartons []*models.PackingCarton `json:"cartons"`
+	Total   int64                   `json:"total"`
+}
+
+// CartonResponse 装箱响应
+type CartonResponse struct {
+	Carton *models.PackingCarton `json:"carton"`
+}
+
+// CartonLabelRequest 箱唛打印请求
+type CartonLabelRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1"`
+}
+
+// CartonLabel 箱唛数据（条码内容由前端渲染为 Code128）
+type CartonLabel struct {
+	CartonID     string            `json:"carton_id"`
+	Barcode      string            `json:"barcode"`
+	ContractNo   string            `json:"contract_no"`
+	StyleNo      string            `json:"style_no"`
+	StyleName    string            `json:"style_name"`
+	CustomerName string            `json:"customer_name"`
+	CartonNo     int               `json:"carton_no"`
+	TotalCartons int               `json:"total_cartons"`
+	PackType     string            `json:"pack_type"`
+	Items        []models.PackItem `json:"items"`
+	TotalQty     int               `json:"total_qty"`
+	GrossWeight  float64           `json:"gross_weight"`
+	NetWeight    float64           `json:"net_weight"`
+	Dimensions   string            `json:"dimensions"`
+	PrintCount   int               `json:"print_count"`
+}
+
+// CartonLabelResponse 箱唛打印响应
+type CartonLabelResponse struct {
+	Labels []CartonLabel `json:"labels"`
+	Count  int           `json:"count"`
+}
+
+// PackingSummaryItem 装箱单汇总行（按颜色尺码）
+type PackingSummaryItem struct {
+	Color      string `json:"color"`
+	Size       string `json:"size"`
+	OrderQty   int    `json:"order_qty"`   // 订单数量
+	PackedQty  int    `json:"packed_qty"`  // 已装箱数量
+	ShippedQty int    `json:"shipped_qty"` // 已发货数量
+}
+
+// PackingListResponse 订单装箱单
+type PackingListResponse struct {
+	OrderID      string                  `json:"order_id"`
+	ContractNo   string                  `json:"contract_no"`
+	StyleNo      string                  `json:"style_no"`
+	StyleName    string                  `json:"style_name"`
+	CustomerName string                  `json:"customer_name"`
+	Cartons      []*models.PackingCarton `json:"cartons"`
+	Summary      []PackingSummaryItem    `json:"summary"`
+	TotalCartons int                     `json:"total_cartons"`
+	OrderQty     int                     `json:"order_qty"`
+	PackedQty    int                     `json:"packed_qty"`
+	ShippedQty   int                     `json:"shipped_qty"`
+	GrossWeight  float64                 `json:"gross_weight"`
+	NetWeight    float64                 `json:"net_weight"`
+}
+
+// ShipmentCreateRequest 创建发货单请求（支持分批发货）
+type ShipmentCreateRequest struct {
+	OrderID         string   `json:"order_id" binding:"required"`
+	CartonIDs       []string `json:"carton_ids" binding:"required,min=1"`
+	Carrier         string   `json:"carrier"`
+	TrackingNo      string   `json:"tracking_no"`
+	DeliveryAddress string   `json:"delivery_address"`
+	Remark          string   `json:"remark"`
+	CreatedBy       string   `json:"created_by"`
+}
+
+// ShipmentListRequest 发货单列表请求
+type ShipmentListRequest struct {
+	Page       int    `json:"page" form:"page"`
+	PageSize   int    `json:"page_size" form:"page_size"`
+	OrderID    string `json:"order_id" form:"order_id"`
+	CustomerID string `json:"customer_id" form:"customer_id"`
+	ContractNo string `json:"contract_no" form:"contract_no"`
+	Status     *int   `json:"status" form:"status"`
+}
+
+// ShipmentListResponse 发货单列表响应
+type ShipmentListResponse struct {
+	Shipments []*models.Shipment `json:"shipments"`
+	Total     int64              `json:"total"`
+}
+
+// ShipmentVoidRequest 作废发货单请求
+type ShipmentVoidRequest struct {
+	ID     string `uri:"id" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// ShipmentResponse 发货单响应
+type ShipmentResponse struct {
+	Shipment       *models.Shipment `json:"shipment"`
+	FullyShipped   bool             `json:"fully_shipped"`   // 订单是否已全部发货
+	OrderCompleted bool             `json:"order_completed"` // 是否已触发订单完成
+}
@@ -58,3 +58,22 @@ type StyleListResponse struct {
 	Styles []models.Style `json:"styles"`
 	Total  int64          `json:"total"`
 }
+
+// StyleApplyTemplateRequest 款式套用工序模板请求
+type StyleApplyTemplateRequest struct {
+	ID         string `uri:"id"`
+	TemplateID string `json:"template_id"` // 工序模板ID
+	Append     bool   `json:"append"`      // 追加到现有工序之后（默认替换）
+}
+
+// StyleRepriceRequest 款式工价重算请求
+type StyleRepriceRequest struct {
+	StyleIDs []string `json:"style_ids"` // 指定款式，为空时重算全部款式
+}
+
+// StyleRepriceResponse 款式工价重算响应
+type StyleRepriceResponse struct {
+	MinuteRate float64 `json:"minute_rate"` // 使用的统一费率
+	Styles     int     `json:"styles"`      // 工价有变化的款式数
+	Procedures int     `json:"procedures"`  // 工价有变化的工序数
+}
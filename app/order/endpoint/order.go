@@ -71,6 +71,14 @@ func UpdateOrderProcedureEndpoint(svc services.IOrderService) endpoint.Endpoint
 	}
 }
 
+// UpdateOrderStandardMinutesEndpoint 设置订单工序标准工时端点
+func UpdateOrderStandardMinutesEndpoint(svc services.IOrderService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.OrderStandardMinutesRequest)
+		return svc.UpdateStandardMinutes(ctx, req)
+	}
+}
+
 // UpdateOrderEndpoint 更新订单端点
 func UpdateOrderEndpoint(svc services.IOrderService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
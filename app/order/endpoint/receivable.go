@@ -0,0 +1,141 @@
+package endpoint
+
+import (
+	"context"
+
+	"mule-cloud/app/order/dto"
+	"mule-cloud/app/order/services"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// ==================== 客户应收账户 Endpoints ====================
+
+func GetCustomerAccountEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		customerID := request.(string)
+		return s.GetCustomerAccount(ctx, customerID)
+	}
+}
+
+func SaveCustomerAccountEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.CustomerAccountSaveRequest)
+		account, err := s.SaveCustomerAccount(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.CustomerAccountResponse{Account: account}, nil
+	}
+}
+
+// ==================== 发票 Endpoints ====================
+
+func CreateInvoiceEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.InvoiceCreateRequest)
+		return s.CreateInvoice(ctx, &req)
+	}
+}
+
+func ListInvoicesEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.InvoiceListRequest)
+		invoices, total, err := s.GetInvoiceList(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.InvoiceListResponse{Invoices: invoices, Total: total}, nil
+	}
+}
+
+func GetInvoiceEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		id := request.(string)
+		invoice, err := s.GetInvoiceByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.InvoiceResponse{Invoice: invoice}, nil
+	}
+}
+
+func VoidInvoiceEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.InvoiceVoidRequest)
+		invoice, err := s.VoidInvoice(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.InvoiceResponse{Invoice: invoice}, nil
+	}
+}
+
+// ==================== 收款 Endpoints ====================
+
+func CreateReceiptEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ReceiptCreateRequest)
+		receipt, err := s.CreateReceipt(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.ReceiptResponse{Receipt: receipt}, nil
+	}
+}
+
+func ListReceiptsEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ReceiptListRequest)
+		receipts, total, err := s.GetReceiptList(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.ReceiptListResponse{Receipts: receipts, Total: total}, nil
+	}
+}
+
+func GetReceiptEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		id := request.(string)
+		receipt, err := s.GetReceiptByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.ReceiptResponse{Receipt: receipt}, nil
+	}
+}
+
+func VoidReceiptEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ReceiptVoidRequest)
+		receipt, err := s.VoidReceipt(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.ReceiptResponse{Receipt: receipt}, nil
+	}
+}
+
+// ==================== 报表 Endpoints ====================
+
+func AgingReportEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.AgingRequest)
+		return s.GetAgingReport(ctx, &req)
+	}
+}
+
+func StatementEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.StatementRequest)
+		return s.GetStatement(ctx, &req)
+	}
+}
+
+func StatementPDFEndpoint(s services.IReceivableService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.StatementRequest)
+		return s.ExportStatementPDF(ctx, &req)
+	}
+}
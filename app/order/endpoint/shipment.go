@@ -0,0 +1,127 @@
+package endpoint
+
+import (
+	"context"
+
+	"mule-cloud/app/order/dto"
+	"mule-cloud/app/order/services"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// ==================== 装箱 Endpoints ====================
+
+func PackCartonsEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.PackCartonsRequest)
+		cartons, err := s.PackCartons(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.PackCartonsResponse{Cartons: cartons, Count: len(cartons)}, nil
+	}
+}
+
+func ListCartonsEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.CartonListRequest)
+		cartons, total, err := s.GetCartonList(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.CartonListResponse{Cartons: cartons, Total: total}, nil
+	}
+}
+
+func GetCartonEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		id := request.(string)
+		carton, err := s.GetCartonByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.CartonResponse{Carton: carton}, nil
+	}
+}
+
+func GetCartonByBarcodeEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		barcode := request.(string)
+		carton, err := s.GetCartonByBarcode(ctx, barcode)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.CartonResponse{Carton: carton}, nil
+	}
+}
+
+func DeleteCartonEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		id := request.(string)
+		err := s.DeleteCarton(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"message": "删除成功"}, nil
+	}
+}
+
+func PrintCartonLabelsEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.CartonLabelRequest)
+		labels, err := s.PrintCartonLabels(ctx, req.IDs)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.CartonLabelResponse{Labels: labels, Count: len(labels)}, nil
+	}
+}
+
+func GetPackingListEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		orderID := request.(string)
+		return s.GetPackingList(ctx, orderID)
+	}
+}
+
+// ==================== 发货 Endpoints ====================
+
+func CreateShipmentEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ShipmentCreateRequest)
+		return s.CreateShipment(ctx, &req)
+	}
+}
+
+func ListShipmentsEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ShipmentListRequest)
+		shipments, total, err := s.GetShipmentList(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.ShipmentListResponse{Shipments: shipments, Total: total}, nil
+	}
+}
+
+func GetShipmentEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		id := request.(string)
+		shipment, err := s.GetShipmentByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.ShipmentResponse{Shipment: shipment}, nil
+	}
+}
+
+func VoidShipmentEndpoint(s services.IShipmentService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.ShipmentVoidRequest)
+		shipment, err := s.VoidShipment(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.ShipmentResponse{Shipment: shipment}, nil
+	}
+}
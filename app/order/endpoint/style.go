@@ -82,3 +82,23 @@ func DeleteStyleEndpoint(svc services.IStyleService) endpoint.Endpoint {
 		return map[string]string{"message": "删除成功"}, nil
 	}
 }
+
+// ApplyStyleTemplateEndpoint 款式套用工序模板端点
+func ApplyStyleTemplateEndpoint(svc services.IStyleService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.StyleApplyTemplateRequest)
+		style, err := svc.ApplyTemplate(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return dto.StyleResponse{Style: style}, nil
+	}
+}
+
+// RepriceStylesEndpoint 款式工价重算端点
+func RepriceStylesEndpoint(svc services.IStyleService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.StyleRepriceRequest)
+		return svc.Reprice(ctx, req)
+	}
+}
@@ -8,11 +8,15 @@ import (
 	"time"
 
 	"mule-cloud/app/order/dto"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/metrics"
+	"mule-cloud/core/tracing"
 	"mule-cloud/core/workflow"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
 )
 
 // ICuttingService 裁剪服务接口
@@ -223,6 +227,7 @@ func (s *cuttingService) CreateCuttingBatch(ctx context.Context, req *dto.Cuttin
 	if err != nil {
 		return nil, err
 	}
+	metrics.BundlesCreated.WithLabelValues(tenantCtx.GetTenantCode(ctx)).Inc()
 
 	// 更新任务状态
 	task.CutPieces += totalPieces
@@ -388,6 +393,8 @@ func (s *cuttingService) BulkCreateCuttingBatch(ctx context.Context, req *dto.Cu
 		}
 	}
 
+	metrics.BundlesCreated.WithLabelValues(tenantCtx.GetTenantCode(ctx)).Add(float64(len(batches)))
+
 	// 更新任务统计
 	task.CutPieces += totalCutPieces
 
@@ -445,7 +452,7 @@ func (s *cuttingService) DeleteCuttingBatch(ctx context.Context, id string) erro
 	err = s.pieceRepo.DeleteByBundleNo(ctx, batch.BedNo, batch.BundleNo)
 	if err != nil {
 		// 记录错误但不中断流程
-		fmt.Printf("删除裁片监控记录失败: %v\n", err)
+		log.Ctx(ctx).Warn("删除裁片监控记录失败", zap.String("bundle_no", batch.BundleNo), zap.Error(err))
 	}
 
 	// 更新任务统计
@@ -564,7 +571,9 @@ func (s *cuttingService) UpdateCuttingPieceProgress(ctx context.Context, id stri
 
 	// 🔥 进度变化时，触发订单进度计算和工作流状态更新
 	if oldProgress != progress {
-		go s.updateOrderProgressAndWorkflow(context.Background(), piece.OrderID, piece.ContractNo)
+		// 异步任务不随请求取消，保留租户和调用链
+		bgCtx := tenantCtx.WithTenantCode(tracing.Detach(ctx), tenantCtx.GetTenantCode(ctx))
+		go s.updateOrderProgressAndWorkflow(bgCtx, piece.OrderID, piece.ContractNo)
 	}
 
 	return nil
@@ -575,7 +584,7 @@ func (s *cuttingService) updateOrderProgressAndWorkflow(ctx context.Context, ord
 	// 1. 计算订单总体进度
 	pieces, _, err := s.pieceRepo.List(ctx, 1, 10000, orderID, contractNo, "", "")
 	if err != nil || len(pieces) == 0 {
-		fmt.Printf("❌ 获取裁片列表失败: %v\n", err)
+		log.Ctx(ctx).Error("获取裁片列表失败", zap.String("order_id", orderID), zap.Error(err))
 		return
 	}
 
@@ -599,8 +608,13 @@ func (s *cuttingService) updateOrderProgressAndWorkflow(ctx context.Context, ord
 		orderProgress = totalWeightedProgress / float64(totalQuantity)
 	}
 
-	fmt.Printf("📊 订单进度计算: 订单=%s, 总件数=%d, 已完成=%d/%d, 进度=%.2f%%\n",
-		orderID, totalQuantity, completedCount, len(pieces), orderProgress*100)
+	log.Ctx(ctx).Info("订单进度计算",
+		zap.String("order_id", orderID),
+		zap.Int("total_quantity", totalQuantity),
+		zap.Int("completed_pieces", completedCount),
+		zap.Int("pieces", len(pieces)),
+		zap.Float64("progress", orderProgress),
+	)
 
 	// 2. 更新订单进度字段
 	err = s.orderRepo.Update(ctx, orderID, map[string]interface{}{
@@ -610,42 +624,20 @@ func (s *cuttingService) updateOrderProgressAndWorkflow(ctx context.Context, ord
 		},
 	})
 	if err != nil {
-		fmt.Printf("❌ 更新订单进度失败: %v\n", err)
+		log.Ctx(ctx).Error("更新订单进度失败", zap.String("order_id", orderID), zap.Error(err))
 		return
 	}
 
 	// 3. 获取订单当前状态
 	order, err := s.orderRepo.Get(ctx, orderID)
 	if err != nil {
-		fmt.Printf("❌ 获取订单失败: %v\n", err)
+		log.Ctx(ctx).Error("获取订单失败", zap.String("order_id", orderID), zap.Error(err))
 		return
 	}
 
-	// 4. 根据进度自动触发工作流状态转换
+	// 4. 进度达到100%只表示生产完成，订单完成由发货服务在全部发货后触发
 	currentStatus := workflow.OrderStatus(order.Status)
-
-	// 如果进度达到100%且当前状态是"生产中"，自动完成订单
 	if orderProgress >= 1.0 && currentStatus == workflow.StatusProduction {
-		fmt.Printf("✅ 订单 %s 进度已达100%%，自动触发完成事件\n", orderID)
-
-		err = s.workflow.TransitionToAdvanced(
-			ctx,
-			orderID,
-			workflow.EventComplete,
-			"system",  // 操作者：系统自动
-			"",        // 不需要特定角色
-			"所有裁片已完成", // 原因
-			map[string]interface{}{
-				"progress":        orderProgress,
-				"completed_count": completedCount,
-				"total_pieces":    len(pieces),
-			},
-		)
-
-		if err != nil {
-			fmt.Printf("❌ 自动完成订单失败: %v\n", err)
-		} else {
-			fmt.Printf("🎉 订单 %s 已自动完成！\n", orderID)
-		}
+		log.Ctx(ctx).Info("订单生产进度已达100%，等待装箱发货", zap.String("order_id", orderID), zap.Int("pieces", len(pieces)))
 	}
 }
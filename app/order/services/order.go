@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"mule-cloud/app/order/dto"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/metrics"
+	"mule-cloud/core/quota"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.uber.org/zap"
 )
 
 // IOrderService 订单服务接口
@@ -19,6 +22,7 @@ type IOrderService interface {
 	Create(ctx context.Context, req dto.OrderCreateRequest) (*models.Order, error)
 	UpdateStyle(ctx context.Context, req dto.OrderStyleRequest) (*models.Order, error)
 	UpdateProcedure(ctx context.Context, req dto.OrderProcedureRequest) (*models.Order, error)
+	UpdateStandardMinutes(ctx context.Context, req dto.OrderStandardMinutesRequest) (*dto.OrderStandardMinutesResponse, error)
 	Update(ctx context.Context, req dto.OrderUpdateRequest) (*models.Order, error)
 	Copy(ctx context.Context, id string, isRelated bool, relationType, relationRemark string) (*models.Order, error)
 	Delete(ctx context.Context, id string) error
@@ -35,6 +39,7 @@ type OrderService struct {
 	cuttingTaskRepo  repository.CuttingTaskRepository
 	cuttingBatchRepo repository.CuttingBatchRepository
 	cuttingPieceRepo repository.CuttingPieceRepository
+	reportRepo       repository.ProcedureReportRepository
 	workflowEngine   IWorkflowEngineService
 }
 
@@ -46,6 +51,7 @@ func NewOrderService() IOrderService {
 		cuttingTaskRepo:  repository.NewCuttingTaskRepository(),
 		cuttingBatchRepo: repository.NewCuttingBatchRepository(),
 		cuttingPieceRepo: repository.NewCuttingPieceRepository(),
+		reportRepo:       repository.NewProcedureReportRepository(),
 		workflowEngine:   NewWorkflowEngineService(),
 	}
 }
@@ -65,7 +71,7 @@ func (s *OrderService) List(ctx context.Context, req dto.OrderListRequest) ([]mo
 	}
 	if req.ContractNo != "" {
 		filter["contract_no"] = bson.M{"$regex": req.ContractNo, "$options": "i"}
-		fmt.Printf("🔍 添加合同号过滤: %v\n", filter["contract_no"])
+		log.Ctx(ctx).Debug("按合同号过滤订单", zap.String("contract_no", req.ContractNo))
 	}
 	if req.StyleNo != "" {
 		filter["style_no"] = bson.M{"$regex": req.StyleNo, "$options": "i"}
@@ -116,12 +122,6 @@ func (s *OrderService) List(ctx context.Context, req dto.OrderListRequest) ([]mo
 		}
 	}
 
-	// 获取总数
-	total, err := s.repo.Count(ctx, filter)
-	if err != nil {
-		return nil, 0, err
-	}
-
 	// 设置分页默认值
 	page := req.Page
 	if page <= 0 {
@@ -132,31 +132,16 @@ func (s *OrderService) List(ctx context.Context, req dto.OrderListRequest) ([]mo
 		pageSize = 10
 	}
 
-	// 分页查询
-	offset := int64((page - 1) * pageSize)
-	opts := options.Find().
-		SetSkip(offset).
-		SetLimit(int64(pageSize)).
-		SetSort(bson.M{"created_at": -1})
-
-	collection := s.repo.GetCollectionWithContext(ctx)
-	cursor, err := collection.Find(ctx, filter, opts)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer cursor.Close(ctx)
-
-	orders := []models.Order{}
-	err = cursor.All(ctx, &orders)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	return orders, total, nil
+	// 分页查询（仓库按数据范围过滤）
+	return s.repo.List(ctx, filter, int64(page), int64(pageSize))
 }
 
 // Create 创建订单（步骤1：基础信息）
 func (s *OrderService) Create(ctx context.Context, req dto.OrderCreateRequest) (*models.Order, error) {
+	// 检查套餐的每月订单数配额
+	if err := quota.Check(ctx, tenantCtx.GetTenantCode(ctx), quota.OrdersPerMonth, 1); err != nil {
+		return nil, err
+	}
 	now := time.Now().Unix()
 
 	order := &models.Order{
@@ -168,6 +153,7 @@ func (s *OrderService) Create(ctx context.Context, req dto.OrderCreateRequest) (
 		Remark:       req.Remark,
 		Status:       0, // 草稿状态
 		Progress:     0,
+		CreatedBy:    tenantCtx.GetUserID(ctx),
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
@@ -176,6 +162,7 @@ func (s *OrderService) Create(ctx context.Context, req dto.OrderCreateRequest) (
 	if err != nil {
 		return nil, err
 	}
+	metrics.OrdersCreated.WithLabelValues(tenantCtx.GetTenantCode(ctx)).Inc()
 
 	// 初始化工作流（使用默认的订单工作流）
 	_ = s.workflowEngine.InitOrderWorkflow(ctx, order.ID, "basic_order")
@@ -253,6 +240,52 @@ func (s *OrderService) UpdateProcedure(ctx context.Context, req dto.OrderProcedu
 	return s.repo.Get(ctx, req.ID)
 }
 
+// UpdateStandardMinutes 设置订单工序标准工时（不改变订单状态），并重算已上报记录的产出工时
+func (s *OrderService) UpdateStandardMinutes(ctx context.Context, req dto.OrderStandardMinutesRequest) (*dto.OrderStandardMinutesResponse, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("请选择要调整的工序")
+	}
+	order, err := s.repo.Get(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	bySeq := make(map[int]int, len(order.Procedures))
+	for i, p := range order.Procedures {
+		bySeq[p.Sequence] = i
+	}
+	for _, item := range req.Items {
+		i, ok := bySeq[item.Sequence]
+		if !ok {
+			return nil, fmt.Errorf("工序序号%d不存在", item.Sequence)
+		}
+		if item.SAM < 0 {
+			return nil, fmt.Errorf("工序[%s]标准工时不能为负数", order.Procedures[i].ProcedureName)
+		}
+		order.Procedures[i].SAM = item.SAM
+	}
+
+	update := bson.M{"procedures": order.Procedures, "updated_at": time.Now().Unix()}
+	if err := s.repo.Update(ctx, req.ID, update); err != nil {
+		return nil, err
+	}
+
+	var updated int64
+	for _, item := range req.Items {
+		n, err := s.reportRepo.UpdateSAM(ctx, req.ID, item.Sequence, item.SAM)
+		if err != nil {
+			return nil, err
+		}
+		updated += n
+	}
+
+	order, err = s.repo.Get(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.OrderStandardMinutesResponse{Order: order, UpdatedReports: updated}, nil
+}
+
 // Update 更新订单
 func (s *OrderService) Update(ctx context.Context, req dto.OrderUpdateRequest) (*models.Order, error) {
 	update := bson.M{"updated_at": time.Now().Unix()}
@@ -361,6 +394,9 @@ func (s *OrderService) Copy(ctx context.Context, id string, isRelated bool, rela
 	if err != nil {
 		return nil, err
 	}
+	if err := quota.Check(ctx, tenantCtx.GetTenantCode(ctx), quota.OrdersPerMonth, 1); err != nil {
+		return nil, err
+	}
 
 	now := time.Now().Unix()
 
@@ -424,6 +460,7 @@ func (s *OrderService) Copy(ctx context.Context, id string, isRelated bool, rela
 	if err != nil {
 		return nil, err
 	}
+	metrics.OrdersCreated.WithLabelValues(tenantCtx.GetTenantCode(ctx)).Inc()
 
 	return newOrder, nil
 }
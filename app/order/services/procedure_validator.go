@@ -16,6 +16,9 @@ func ValidateOrderProcedures(procedures []models.OrderProcedure) error {
 		if proc.IsSlowest {
 			finalCount++
 		}
+		if proc.SAM < 0 {
+			return fmt.Errorf("工序[%s]标准工时不能为负数", proc.ProcedureName)
+		}
 	}
 
 	if finalCount == 0 {
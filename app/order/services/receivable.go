@@ -0,0 +1,1088 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"mule-cloud/app/order/dto"
+	corecontext "mule-cloud/core/context"
+	"mule-cloud/core/pdf"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.uber.org/zap"
+)
+
+// IReceivableService 客户应收服务接口
+type IReceivableService interface {
+	// 信用条款
+	GetCustomerAccount(ctx context.Context, customerID string) (*dto.CustomerAccountResponse, error)
+	SaveCustomerAccount(ctx context.Context, req *dto.CustomerAccountSaveRequest) (*models.CustomerAccount, error)
+
+	// 发票
+	CreateInvoice(ctx context.Context, req *dto.InvoiceCreateRequest) (*dto.InvoiceResponse, error)
+	GetInvoiceList(ctx context.Context, req *dto.InvoiceListRequest) ([]*models.Invoice, int64, error)
+	GetInvoiceByID(ctx context.Context, id string) (*models.Invoice, error)
+	VoidInvoice(ctx context.Context, req *dto.InvoiceVoidRequest) (*models.Invoice, error)
+
+	// 收款
+	CreateReceipt(ctx context.Context, req *dto.ReceiptCreateRequest) (*models.Receipt, error)
+	GetReceiptList(ctx context.Context, req *dto.ReceiptListRequest) ([]*models.Receipt, int64, error)
+	GetReceiptByID(ctx context.Context, id string) (*models.Receipt, error)
+	VoidReceipt(ctx context.Context, req *dto.ReceiptVoidRequest) (*models.Receipt, error)
+
+	// 报表
+	GetAgingReport(ctx context.Context, req *dto.AgingRequest) (*dto.AgingResponse, error)
+	GetStatement(ctx context.Context, req *dto.StatementRequest) (*dto.StatementResponse, error)
+	ExportStatementPDF(ctx context.Context, req *dto.StatementRequest) ([]byte, error)
+}
+
+type receivableService struct {
+	accountRepo  repository.CustomerAccountRepository
+	invoiceRepo  repository.InvoiceRepository
+	receiptRepo  repository.ReceiptRepository
+	shipmentRepo repository.ShipmentRepository
+	orderRepo    repository.OrderRepository
+	counterRepo  repository.CounterRepository
+}
+
+// NewReceivableService 创建客户应收服务
+func NewReceivableService() IReceivableService {
+	return &receivableService{
+		accountRepo:  repository.NewCustomerAccountRepository(),
+		invoiceRepo:  repository.NewInvoiceRepository(),
+		receiptRepo:  repository.NewReceiptRepository(),
+		shipmentRepo: repository.NewShipmentRepository(),
+		orderRepo:    repository.NewOrderRepository(),
+		counterRepo:  repository.NewCounterRepository(),
+	}
+}
+
+const secondsPerDay = 86400
+
+// roundAmount 金额保留两位小数
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// invoiceStatusOf 根据已收金额计算发票状态
+func invoiceStatusOf(amount, paid float64) int {
+	switch {
+	case paid <= 0:
+		return models.InvoiceStatusOpen
+	case paid < amount:
+		return models.InvoiceStatusPartial
+	default:
+		return models.InvoiceStatusPaid
+	}
+}
+
+// GetCustomerAccount 获取客户信用条款及当前账龄
+func (s *receivableService) GetCustomerAccount(ctx context.Context, customerID string) (*dto.CustomerAccountResponse, error) {
+	account, err := s.accountRepo.GetByCustomer(ctx, customerID)
+	if err != nil {
+		if err != repository.ErrNotFound {
+			return nil, err
+		}
+		// 未设置信用条款的客户按现结处理
+		account = &models.CustomerAccount{CustomerID: customerID}
+	}
+
+	aging, err := s.GetAgingReport(ctx, &dto.AgingRequest{CustomerID: customerID})
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.CustomerAccountResponse{Account: account}
+	if len(aging.Rows) > 0 {
+		resp.Aging = &aging.Rows[0]
+	}
+	return resp, nil
+}
+
+// SaveCustomerAccount 保存客户信用条款
+func (s *receivableService) SaveCustomerAccount(ctx context.Context, req *dto.CustomerAccountSaveRequest) (*models.CustomerAccount, error) {
+	if req.CreditDays < 0 {
+		return nil, fmt.Errorf("账期不能为负数")
+	}
+	if req.CreditLimit < 0 {
+		return nil, fmt.Errorf("信用额度不能为负数")
+	}
+
+	username := corecontext.GetUsername(ctx)
+	now := time.Now().Unix()
+	account := &models.CustomerAccount{
+		ID:             bson.NewObjectID().Hex(),
+		CustomerID:     req.CustomerID,
+		CustomerName:   req.CustomerName,
+		CreditDays:     req.CreditDays,
+		CreditLimit:    roundAmount(req.CreditLimit),
+		OpeningBalance: roundAmount(req.OpeningBalance),
+		Remark:         req.Remark,
+		CreatedBy:      username,
+		UpdatedBy:      username,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return nil, err
+	}
+	return s.accountRepo.GetByCustomer(ctx, req.CustomerID)
+}
+
+// CreateInvoice 开票（按发货单开票与按订单总额开票互斥，避免重复应收）
+func (s *receivableService) CreateInvoice(ctx context.Context, req *dto.InvoiceCreateRequest) (*dto.InvoiceResponse, error) {
+	var (
+		invoice *models.Invoice
+		err     error
+	)
+	switch req.SourceType {
+	case models.InvoiceSourceShipment:
+		invoice, err = s.buildShipmentInvoice(ctx, req.SourceID)
+	case models.InvoiceSourceOrder:
+		invoice, err = s.buildOrderInvoice(ctx, req.SourceID)
+	default:
+		return nil, fmt.Errorf("无效的开票来源: %s", req.SourceType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Amount <= 0 {
+		return nil, fmt.Errorf("开票金额必须大于0，请先维护订单单价")
+	}
+
+	account, err := s.accountRepo.GetByCustomer(ctx, invoice.CustomerID)
+	if err != nil && err != repository.ErrNotFound {
+		return nil, err
+	}
+
+	invoiceDate := req.InvoiceDate
+	if invoiceDate <= 0 {
+		invoiceDate = time.Now().Unix()
+	}
+	dueDate := invoiceDate
+	if account != nil {
+		dueDate += int64(account.CreditDays) * secondsPerDay
+	}
+
+	invoiceNo, err := s.nextDocNo(ctx, s.invoiceRepo.Count, "invoice_no", "INV", invoiceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	createdBy := req.CreatedBy
+	if createdBy == "" {
+		createdBy = corecontext.GetUsername(ctx)
+	}
+
+	now := time.Now().Unix()
+	invoice.ID = bson.NewObjectID().Hex()
+	invoice.InvoiceNo = invoiceNo
+	invoice.InvoiceDate = invoiceDate
+	invoice.DueDate = dueDate
+	invoice.Status = models.InvoiceStatusOpen
+	invoice.Remark = req.Remark
+	invoice.CreatedBy = createdBy
+	invoice.CreatedAt = now
+	invoice.UpdatedAt = now
+
+	// 同一来源的重复开票由唯一索引拦截；按发货单和按订单开票互斥，创建后再检查一次，
+	// 并发开出互斥的发票时撤销本张
+	if err := s.invoiceRepo.Create(ctx, invoice); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("%s已开票", invoice.SourceNo)
+		}
+		return nil, err
+	}
+	if err := s.checkExclusiveInvoice(ctx, invoice); err != nil {
+		if purgeErr := s.invoiceRepo.Purge(ctx, invoice.ID); purgeErr != nil {
+			log.Ctx(ctx).Error("撤销冲突发票失败", zap.String("invoice_no", invoice.InvoiceNo), zap.Error(purgeErr))
+		}
+		return nil, err
+	}
+
+	resp := &dto.InvoiceResponse{Invoice: invoice}
+	if account != nil && account.CreditLimit > 0 {
+		aging, err := s.GetAgingReport(ctx, &dto.AgingRequest{CustomerID: invoice.CustomerID})
+		if err == nil && len(aging.Rows) > 0 {
+			resp.OverCreditLimit = aging.Rows[0].OverLimit
+		}
+	}
+	return resp, nil
+}
+
+// checkExclusiveInvoice 检查订单是否已有另一种开票方式的有效发票（按发货单与按订单开票互斥）
+func (s *receivableService) checkExclusiveInvoice(ctx context.Context, invoice *models.Invoice) error {
+	other := models.InvoiceSourceOrder
+	if invoice.SourceType == models.InvoiceSourceOrder {
+		other = models.InvoiceSourceShipment
+	}
+	count, err := s.invoiceRepo.Count(ctx, bson.M{
+		"is_deleted":  0,
+		"order_id":    invoice.OrderID,
+		"source_type": other,
+		"status":      bson.M{"$ne": models.InvoiceStatusVoid},
+	})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("订单%s已开票", invoice.ContractNo)
+	}
+	return nil
+}
+
+// buildShipmentInvoice 按发货单生成发票（单价取订单单价）
+func (s *receivableService) buildShipmentInvoice(ctx context.Context, shipmentID string) (*models.Invoice, error) {
+	shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("发货单不存在")
+		}
+		return nil, err
+	}
+	if shipment.Status != 1 {
+		return nil, fmt.Errorf("发货单已作废，不能开票")
+	}
+
+	count, err := s.invoiceRepo.Count(ctx, bson.M{
+		"is_deleted": 0,
+		"status":     bson.M{"$ne": models.InvoiceStatusVoid},
+		"$or": []bson.M{
+			{"source_type": models.InvoiceSourceShipment, "source_id": shipment.ID},
+			{"source_type": models.InvoiceSourceOrder, "order_id": shipment.OrderID},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("发货单%s或其订单已开票", shipment.ShipmentNo)
+	}
+
+	order, err := s.orderRepo.Get(ctx, shipment.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("订单不存在")
+	}
+
+	description := fmt.Sprintf("%s %s", order.StyleNo, order.StyleName)
+	lines := make([]models.InvoiceLine, 0, len(shipment.Items))
+	amount := 0.0
+	for _, item := range shipment.Items {
+		lineAmount := roundAmount(float64(item.Quantity) * order.UnitPrice)
+		lines = append(lines, models.InvoiceLine{
+			Description: description,
+			Color:       item.Color,
+			Size:        item.Size,
+			Quantity:    item.Quantity,
+			UnitPrice:   order.UnitPrice,
+			Amount:      lineAmount,
+		})
+		amount += lineAmount
+	}
+
+	return &models.Invoice{
+		SourceType:   models.InvoiceSourceShipment,
+		SourceID:     shipment.ID,
+		SourceNo:     shipment.ShipmentNo,
+		OrderID:      order.ID,
+		ContractNo:   order.ContractNo,
+		StyleNo:      order.StyleNo,
+		StyleName:    order.StyleName,
+		CustomerID:   order.CustomerID,
+		CustomerName: order.CustomerName,
+		SalesmanID:   order.SalesmanID,
+		SalesmanName: order.SalesmanName,
+		Lines:        lines,
+		Quantity:     shipment.TotalQty,
+		Amount:       roundAmount(amount),
+	}, nil
+}
+
+// buildOrderInvoice 按订单总额生成发票
+func (s *receivableService) buildOrderInvoice(ctx context.Context, orderID string) (*models.Invoice, error) {
+	order, err := s.orderRepo.Get(ctx, orderID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("订单不存在")
+		}
+		return nil, err
+	}
+	if order.Status == 0 || order.Status == 4 {
+		return nil, fmt.Errorf("草稿或已取消的订单不能开票")
+	}
+
+	count, err := s.invoiceRepo.Count(ctx, bson.M{
+		"is_deleted": 0,
+		"order_id":   order.ID,
+		"status":     bson.M{"$ne": models.InvoiceStatusVoid},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("订单%s已开票", order.ContractNo)
+	}
+
+	description := fmt.Sprintf("%s %s", order.StyleNo, order.StyleName)
+	lines := make([]models.InvoiceLine, 0, len(order.Items))
+	amount := 0.0
+	for _, item := range order.Items {
+		lineAmount := roundAmount(float64(item.Quantity) * order.UnitPrice)
+		lines = append(lines, models.InvoiceLine{
+			Description: description,
+			Color:       item.Color,
+			Size:        item.Size,
+			Quantity:    item.Quantity,
+			UnitPrice:   order.UnitPrice,
+			Amount:      lineAmount,
+		})
+		amount += lineAmount
+	}
+	if len(lines) == 0 {
+		amount = roundAmount(float64(order.Quantity) * order.UnitPrice)
+		lines = append(lines, models.InvoiceLine{
+			Description: description,
+			Quantity:    order.Quantity,
+			UnitPrice:   order.UnitPrice,
+			Amount:      amount,
+		})
+	}
+	// 订单维护了总金额时以订单总金额为准（可能包含折扣）
+	if order.TotalAmount > 0 {
+		amount = order.TotalAmount
+	}
+
+	return &models.Invoice{
+		SourceType:   models.InvoiceSourceOrder,
+		SourceID:     order.ID,
+		SourceNo:     order.ContractNo,
+		OrderID:      order.ID,
+		ContractNo:   order.ContractNo,
+		StyleNo:      order.StyleNo,
+		StyleName:    order.StyleName,
+		CustomerID:   order.CustomerID,
+		CustomerName: order.CustomerName,
+		SalesmanID:   order.SalesmanID,
+		SalesmanName: order.SalesmanName,
+		Lines:        lines,
+		Quantity:     order.Quantity,
+		Amount:       roundAmount(amount),
+	}, nil
+}
+
+// nextDocNo 生成单据号：前缀+日期+4位序号
+//
+// 序号由计数器原子递增，并发开票、收款不会重号；计数器从当天已有单据数量初始化
+func (s *receivableService) nextDocNo(ctx context.Context, count func(context.Context, bson.M) (int64, error), field, prefix string, date int64) (string, error) {
+	key := prefix + time.Unix(date, 0).Format("20060102")
+	n, err := count(ctx, bson.M{field: bson.M{"$regex": "^" + key}})
+	if err != nil {
+		return "", err
+	}
+	if err := s.counterRepo.EnsureAtLeast(ctx, key, n); err != nil {
+		return "", err
+	}
+	seq, err := s.counterRepo.Next(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%04d", key, seq), nil
+}
+
+// GetInvoiceList 获取发票列表
+func (s *receivableService) GetInvoiceList(ctx context.Context, req *dto.InvoiceListRequest) ([]*models.Invoice, int64, error) {
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	filter := bson.M{}
+	if req.InvoiceNo != "" {
+		filter["invoice_no"] = bson.M{"$regex": req.InvoiceNo, "$options": "i"}
+	}
+	if req.CustomerID != "" {
+		filter["customer_id"] = req.CustomerID
+	}
+	if req.SalesmanID != "" {
+		filter["salesman_id"] = req.SalesmanID
+	}
+	if req.OrderID != "" {
+		filter["order_id"] = req.OrderID
+	}
+	if req.Status != nil {
+		filter["status"] = *req.Status
+	}
+	if dateFilter := rangeFilter(req.StartDate, req.EndDate); dateFilter != nil {
+		filter["invoice_date"] = dateFilter
+	}
+
+	return s.invoiceRepo.List(ctx, filter, page, pageSize)
+}
+
+// rangeFilter 构造时间范围条件
+func rangeFilter(start, end int64) bson.M {
+	if start <= 0 && end <= 0 {
+		return nil
+	}
+	cond := bson.M{}
+	if start > 0 {
+		cond["$gte"] = start
+	}
+	if end > 0 {
+		cond["$lte"] = end
+	}
+	return cond
+}
+
+// GetInvoiceByID 根据ID获取发票
+func (s *receivableService) GetInvoiceByID(ctx context.Context, id string) (*models.Invoice, error) {
+	return s.invoiceRepo.GetByID(ctx, id)
+}
+
+// VoidInvoice 作废发票（已有收款核销的发票需先作废收款单）
+func (s *receivableService) VoidInvoice(ctx context.Context, req *dto.InvoiceVoidRequest) (*models.Invoice, error) {
+	invoice, err := s.invoiceRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == models.InvoiceStatusVoid {
+		return nil, fmt.Errorf("发票已作废")
+	}
+	if invoice.PaidAmount > 0 {
+		return nil, fmt.Errorf("发票已有收款核销，请先作废对应收款单")
+	}
+
+	// 按条件作废，避免与并发收款核销交错
+	if err := s.invoiceRepo.VoidUnpaid(ctx, invoice.ID, req.Reason); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("发票已作废或已有收款核销")
+		}
+		return nil, err
+	}
+	return s.invoiceRepo.GetByID(ctx, invoice.ID)
+}
+
+// CreateReceipt 登记收款并核销发票
+func (s *receivableService) CreateReceipt(ctx context.Context, req *dto.ReceiptCreateRequest) (*models.Receipt, error) {
+	amount := roundAmount(req.Amount)
+	if amount <= 0 {
+		return nil, fmt.Errorf("收款金额必须大于0")
+	}
+
+	openInvoices, err := s.invoiceRepo.Find(ctx, bson.M{
+		"customer_id": req.CustomerID,
+		"status":      bson.M{"$in": []int{models.InvoiceStatusOpen, models.InvoiceStatusPartial}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	customerName := ""
+	if account, err := s.accountRepo.GetByCustomer(ctx, req.CustomerID); err == nil {
+		customerName = account.CustomerName
+	}
+	if customerName == "" && len(openInvoices) > 0 {
+		customerName = openInvoices[0].CustomerName
+	}
+
+	// 计算核销明细
+	var allocations []models.ReceiptAllocation
+	if len(req.Allocations) > 0 {
+		allocations, err = s.manualAllocations(openInvoices, req.Allocations, amount)
+	} else {
+		allocations = autoAllocations(openInvoices, amount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	applied := 0.0
+	for _, alloc := range allocations {
+		applied += alloc.Amount
+	}
+
+	createdBy := req.CreatedBy
+	if createdBy == "" {
+		createdBy = corecontext.GetUsername(ctx)
+	}
+	receivedAt := req.ReceivedAt
+	if receivedAt <= 0 {
+		receivedAt = time.Now().Unix()
+	}
+	method := req.Method
+	if method == "" {
+		method = "bank"
+	}
+
+	receiptNo, err := s.nextDocNo(ctx, s.receiptRepo.Count, "receipt_no", "RC", receivedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先核销发票：每张发票按余额条件原子累加已收金额，余额已被其他收款核销时整体回滚
+	if err := s.applyAllocations(ctx, allocations, 1); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("发票余额已变化，请刷新后重试")
+		}
+		return nil, fmt.Errorf("核销发票失败: %v", err)
+	}
+
+	now := time.Now().Unix()
+	receipt := &models.Receipt{
+		ID:              bson.NewObjectID().Hex(),
+		ReceiptNo:       receiptNo,
+		CustomerID:      req.CustomerID,
+		CustomerName:    customerName,
+		Amount:          amount,
+		Method:          method,
+		Reference:       req.Reference,
+		ReceivedAt:      receivedAt,
+		Allocations:     allocations,
+		UnappliedAmount: roundAmount(amount - applied),
+		Status:          1, // 有效
+		Remark:          req.Remark,
+		CreatedBy:       createdBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.receiptRepo.Create(ctx, receipt); err != nil {
+		if rollbackErr := s.applyAllocations(ctx, allocations, -1); rollbackErr != nil {
+			log.Ctx(ctx).Error("创建收款单失败，回滚发票核销失败", zap.String("receipt_no", receiptNo), zap.Error(rollbackErr))
+		}
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// manualAllocations 校验指定的核销明细（同一发票的多行合并后再与未收余额比较）
+func (s *receivableService) manualAllocations(openInvoices []*models.Invoice, requested []models.ReceiptAllocation, amount float64) ([]models.ReceiptAllocation, error) {
+	byID := make(map[string]*models.Invoice, len(openInvoices))
+	for _, invoice := range openInvoices {
+		byID[invoice.ID] = invoice
+	}
+
+	allocations := make([]models.ReceiptAllocation, 0, len(requested))
+	index := make(map[string]int, len(requested))
+	total := 0.0
+	for _, alloc := range requested {
+		invoice, ok := byID[alloc.InvoiceID]
+		if !ok {
+			return nil, fmt.Errorf("发票%s不存在、已结清或不属于该客户", alloc.InvoiceID)
+		}
+		alloc.Amount = roundAmount(alloc.Amount)
+		if alloc.Amount <= 0 {
+			return nil, fmt.Errorf("发票%s的核销金额必须大于0", invoice.InvoiceNo)
+		}
+		i, ok := index[invoice.ID]
+		if !ok {
+			i = len(allocations)
+			index[invoice.ID] = i
+			allocations = append(allocations, models.ReceiptAllocation{InvoiceID: invoice.ID, InvoiceNo: invoice.InvoiceNo})
+		}
+		allocations[i].Amount = roundAmount(allocations[i].Amount + alloc.Amount)
+		if allocations[i].Amount > roundAmount(invoice.Balance()) {
+			return nil, fmt.Errorf("发票%s的核销金额%.2f超出未收余额%.2f", invoice.InvoiceNo, allocations[i].Amount, invoice.Balance())
+		}
+		total += alloc.Amount
+	}
+	if roundAmount(total) > amount {
+		return nil, fmt.Errorf("核销合计%.2f超出收款金额%.2f", total, amount)
+	}
+	return allocations, nil
+}
+
+// autoAllocations 按到期日先后自动核销
+func autoAllocations(openInvoices []*models.Invoice, amount float64) []models.ReceiptAllocation {
+	allocations := make([]models.ReceiptAllocation, 0)
+	remaining := amount
+	for _, invoice := range openInvoices {
+		if remaining <= 0 {
+			break
+		}
+		balance := roundAmount(invoice.Balance())
+		if balance <= 0 {
+			continue
+		}
+		applied := math.Min(balance, remaining)
+		allocations = append(allocations, models.ReceiptAllocation{
+			InvoiceID: invoice.ID,
+			InvoiceNo: invoice.InvoiceNo,
+			Amount:    applied,
+		})
+		remaining = roundAmount(remaining - applied)
+	}
+	return allocations
+}
+
+// applyAllocations 回写发票已收金额（sign=1 核销，sign=-1 反核销）
+//
+// 已收金额按条件原子累加，某张发票失败时撤销本次已调整的发票
+func (s *receivableService) applyAllocations(ctx context.Context, allocations []models.ReceiptAllocation, sign float64) error {
+	for i, alloc := range allocations {
+		if err := s.adjustPaid(ctx, alloc.InvoiceID, sign*alloc.Amount); err != nil {
+			for _, applied := range allocations[:i] {
+				if undoErr := s.adjustPaid(ctx, applied.InvoiceID, -sign*applied.Amount); undoErr != nil {
+					log.Ctx(ctx).Error("撤销发票核销失败", zap.String("invoice_id", applied.InvoiceID), zap.Error(undoErr))
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// adjustPaid 调整单张发票的已收金额并更新状态
+func (s *receivableService) adjustPaid(ctx context.Context, invoiceID string, amount float64) error {
+	invoice, err := s.invoiceRepo.ApplyPayment(ctx, invoiceID, amount)
+	if err != nil {
+		return err
+	}
+	if invoice.Status == models.InvoiceStatusVoid {
+		return nil
+	}
+	return s.invoiceRepo.SetStatusIfPaid(ctx, invoice.ID, invoice.PaidAmount, invoiceStatusOf(invoice.Amount, roundAmount(invoice.PaidAmount)))
+}
+
+// GetReceiptList 获取收款单列表
+func (s *receivableService) GetReceiptList(ctx context.Context, req *dto.ReceiptListRequest) ([]*models.Receipt, int64, error) {
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	filter := bson.M{}
+	if req.CustomerID != "" {
+		filter["customer_id"] = req.CustomerID
+	}
+	if req.Status != nil {
+		filter["status"] = *req.Status
+	}
+	if dateFilter := rangeFilter(req.StartDate, req.EndDate); dateFilter != nil {
+		filter["received_at"] = dateFilter
+	}
+
+	return s.receiptRepo.List(ctx, filter, page, pageSize)
+}
+
+// GetReceiptByID 根据ID获取收款单
+func (s *receivableService) GetReceiptByID(ctx context.Context, id string) (*models.Receipt, error) {
+	return s.receiptRepo.GetByID(ctx, id)
+}
+
+// VoidReceipt 作废收款单并反核销发票
+func (s *receivableService) VoidReceipt(ctx context.Context, req *dto.ReceiptVoidRequest) (*models.Receipt, error) {
+	receipt, err := s.receiptRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != 1 {
+		return nil, fmt.Errorf("收款单已作废")
+	}
+
+	// 按状态条件作废，并发作废同一收款单时只反核销一次
+	remark := receipt.Remark
+	if req.Reason != "" {
+		remark = "作废原因: " + req.Reason
+	}
+	err = s.receiptRepo.UpdateIfStatus(ctx, receipt.ID, 1, bson.M{
+		"status": 2,
+		"remark": remark,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("收款单已作废")
+		}
+		return nil, err
+	}
+
+	if err := s.applyAllocations(ctx, receipt.Allocations, -1); err != nil {
+		if restoreErr := s.receiptRepo.Update(ctx, receipt.ID, bson.M{"status": 1, "remark": receipt.Remark}); restoreErr != nil {
+			log.Ctx(ctx).Error("恢复收款单状态失败", zap.String("receipt_id", receipt.ID), zap.Error(restoreErr))
+		}
+		return nil, fmt.Errorf("反核销发票失败: %v", err)
+	}
+
+	return s.receiptRepo.GetByID(ctx, receipt.ID)
+}
+
+// addAging 按逾期天数归入账龄区间
+func addAging(row *dto.AgingRow, balance float64, dueDate, asOf int64) {
+	overdueDays := (asOf - dueDate) / secondsPerDay
+	switch {
+	case asOf <= dueDate:
+		row.Current += balance
+	case overdueDays <= 30:
+		row.Days1To30 += balance
+	case overdueDays <= 60:
+		row.Days31To60 += balance
+	case overdueDays <= 90:
+		row.Days61To90 += balance
+	default:
+		row.Over90 += balance
+	}
+	row.InvoiceCount++
+	row.Outstanding += balance
+}
+
+// roundAgingRow 金额统一保留两位小数
+func roundAgingRow(row *dto.AgingRow) {
+	row.Current = roundAmount(row.Current)
+	row.Days1To30 = roundAmount(row.Days1To30)
+	row.Days31To60 = roundAmount(row.Days31To60)
+	row.Days61To90 = roundAmount(row.Days61To90)
+	row.Over90 = roundAmount(row.Over90)
+	row.OpeningBalance = roundAmount(row.OpeningBalance)
+	row.Unapplied = roundAmount(row.Unapplied)
+	row.Outstanding = roundAmount(row.Outstanding)
+	row.OverLimit = row.CreditLimit > 0 && row.Outstanding > row.CreditLimit
+}
+
+// GetAgingReport 应收余额及账龄分析（按客户或业务员）
+//
+// 截止时间之后开具的发票和收到的款项不计入；截止时间之前的余额按当前核销结果计算。
+func (s *receivableService) GetAgingReport(ctx context.Context, req *dto.AgingRequest) (*dto.AgingResponse, error) {
+	groupBy := req.GroupBy
+	if groupBy == "" {
+		groupBy = "customer"
+	}
+	if groupBy != "customer" && groupBy != "salesman" {
+		return nil, fmt.Errorf("无效的分组方式: %s", groupBy)
+	}
+	asOf := req.AsOf
+	if asOf <= 0 {
+		asOf = time.Now().Unix()
+	}
+
+	invoiceFilter := bson.M{
+		"status":       bson.M{"$in": []int{models.InvoiceStatusOpen, models.InvoiceStatusPartial}},
+		"invoice_date": bson.M{"$lte": asOf},
+	}
+	if req.CustomerID != "" {
+		invoiceFilter["customer_id"] = req.CustomerID
+	}
+	if req.SalesmanID != "" {
+		invoiceFilter["salesman_id"] = req.SalesmanID
+	}
+	invoices, err := s.invoiceRepo.Find(ctx, invoiceFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[string]*dto.AgingRow)
+	order := make([]string, 0)
+	rowOf := func(id, name string) *dto.AgingRow {
+		row, ok := rows[id]
+		if !ok {
+			row = &dto.AgingRow{GroupID: id, GroupName: name}
+			rows[id] = row
+			order = append(order, id)
+		}
+		if row.GroupName == "" {
+			row.GroupName = name
+		}
+		return row
+	}
+
+	for _, invoice := range invoices {
+		balance := invoice.Balance()
+		if balance <= 0 {
+			continue
+		}
+		var row *dto.AgingRow
+		if groupBy == "salesman" {
+			row = rowOf(invoice.SalesmanID, invoice.SalesmanName)
+		} else {
+			row = rowOf(invoice.CustomerID, invoice.CustomerName)
+		}
+		addAging(row, balance, invoice.DueDate, asOf)
+	}
+
+	// 期初余额、预收款和信用额度只在客户维度有意义
+	if groupBy == "customer" && req.SalesmanID == "" {
+		accountFilter := bson.M{}
+		receiptFilter := bson.M{"status": 1, "unapplied_amount": bson.M{"$gt": 0}, "received_at": bson.M{"$lte": asOf}}
+		if req.CustomerID != "" {
+			accountFilter["customer_id"] = req.CustomerID
+			receiptFilter["customer_id"] = req.CustomerID
+		}
+
+		accounts, err := s.accountRepo.Find(ctx, accountFilter)
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range accounts {
+			if account.OpeningBalance == 0 && account.CreditLimit == 0 {
+				if _, ok := rows[account.CustomerID]; !ok {
+					continue
+				}
+			}
+			row := rowOf(account.CustomerID, account.CustomerName)
+			row.OpeningBalance += account.OpeningBalance
+			row.Outstanding += account.OpeningBalance
+			row.CreditLimit = account.CreditLimit
+		}
+
+		receipts, err := s.receiptRepo.Find(ctx, receiptFilter)
+		if err != nil {
+			return nil, err
+		}
+		for _, receipt := range receipts {
+			row := rowOf(receipt.CustomerID, receipt.CustomerName)
+			row.Unapplied += receipt.UnappliedAmount
+			row.Outstanding -= receipt.UnappliedAmount
+		}
+	}
+
+	resp := &dto.AgingResponse{GroupBy: groupBy, AsOf: asOf, Rows: make([]dto.AgingRow, 0, len(order))}
+	for _, id := range order {
+		row := rows[id]
+		roundAgingRow(row)
+		resp.Rows = append(resp.Rows, *row)
+
+		resp.Total.InvoiceCount += row.InvoiceCount
+		resp.Total.Current += row.Current
+		resp.Total.Days1To30 += row.Days1To30
+		resp.Total.Days31To60 += row.Days31To60
+		resp.Total.Days61To90 += row.Days61To90
+		resp.Total.Over90 += row.Over90
+		resp.Total.OpeningBalance += row.OpeningBalance
+		resp.Total.Unapplied += row.Unapplied
+		resp.Total.Outstanding += row.Outstanding
+	}
+	roundAgingRow(&resp.Total)
+
+	// 应收余额从高到低
+	sort.SliceStable(resp.Rows, func(i, j int) bool {
+		return resp.Rows[i].Outstanding > resp.Rows[j].Outstanding
+	})
+
+	return resp, nil
+}
+
+// GetStatement 客户对账单
+func (s *receivableService) GetStatement(ctx context.Context, req *dto.StatementRequest) (*dto.StatementResponse, error) {
+	now := time.Now()
+	startDate := req.StartDate
+	if startDate <= 0 {
+		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Unix()
+	}
+	endDate := req.EndDate
+	if endDate <= 0 {
+		endDate = now.Unix()
+	}
+	if endDate < startDate {
+		return nil, fmt.Errorf("结束日期不能早于开始日期")
+	}
+
+	resp := &dto.StatementResponse{
+		CustomerID: req.CustomerID,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Lines:      []dto.StatementLine{},
+	}
+
+	account, err := s.accountRepo.GetByCustomer(ctx, req.CustomerID)
+	if err != nil && err != repository.ErrNotFound {
+		return nil, err
+	}
+	if account != nil {
+		resp.CustomerName = account.CustomerName
+		resp.CreditDays = account.CreditDays
+		resp.CreditLimit = account.CreditLimit
+		resp.OpeningBalance = account.OpeningBalance
+	}
+
+	invoices, err := s.invoiceRepo.Find(ctx, bson.M{
+		"customer_id":  req.CustomerID,
+		"status":       bson.M{"$ne": models.InvoiceStatusVoid},
+		"invoice_date": bson.M{"$lte": endDate},
+	})
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := s.receiptRepo.Find(ctx, bson.M{
+		"customer_id": req.CustomerID,
+		"status":      1,
+		"received_at": bson.M{"$lte": endDate},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]dto.StatementLine, 0, len(invoices)+len(receipts))
+	for _, invoice := range invoices {
+		if resp.CustomerName == "" {
+			resp.CustomerName = invoice.CustomerName
+		}
+		if invoice.InvoiceDate < startDate {
+			resp.OpeningBalance += invoice.Amount
+			continue
+		}
+		lines = append(lines, dto.StatementLine{
+			Date:        invoice.InvoiceDate,
+			Type:        "invoice",
+			DocNo:       invoice.InvoiceNo,
+			Description: fmt.Sprintf("%s %s %d件", invoice.SourceNo, invoice.StyleNo, invoice.Quantity),
+			Debit:       invoice.Amount,
+		})
+	}
+	for _, receipt := range receipts {
+		if resp.CustomerName == "" {
+			resp.CustomerName = receipt.CustomerName
+		}
+		if receipt.ReceivedAt < startDate {
+			resp.OpeningBalance -= receipt.Amount
+			continue
+		}
+		lines = append(lines, dto.StatementLine{
+			Date:        receipt.ReceivedAt,
+			Type:        "receipt",
+			DocNo:       receipt.ReceiptNo,
+			Description: receiptMethodName(receipt.Method) + " " + receipt.Reference,
+			Credit:      receipt.Amount,
+		})
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Date < lines[j].Date
+	})
+
+	resp.OpeningBalance = roundAmount(resp.OpeningBalance)
+	balance := resp.OpeningBalance
+	for i := range lines {
+		balance = roundAmount(balance + lines[i].Debit - lines[i].Credit)
+		lines[i].Balance = balance
+		resp.TotalDebit += lines[i].Debit
+		resp.TotalCredit += lines[i].Credit
+	}
+	resp.Lines = lines
+	resp.TotalDebit = roundAmount(resp.TotalDebit)
+	resp.TotalCredit = roundAmount(resp.TotalCredit)
+	resp.ClosingBalance = balance
+
+	aging, err := s.GetAgingReport(ctx, &dto.AgingRequest{CustomerID: req.CustomerID, AsOf: endDate})
+	if err != nil {
+		return nil, err
+	}
+	if len(aging.Rows) > 0 {
+		resp.Aging = aging.Rows[0]
+	}
+
+	return resp, nil
+}
+
+// receiptMethodName 收款方式名称
+func receiptMethodName(method string) string {
+	switch method {
+	case "bank":
+		return "银行转账"
+	case "cash":
+		return "现金"
+	default:
+		return "其他"
+	}
+}
+
+// ExportStatementPDF 导出客户对账单 PDF
+func (s *receivableService) ExportStatementPDF(ctx context.Context, req *dto.StatementRequest) ([]byte, error) {
+	stmt, err := s.GetStatement(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	const (
+		left     = 40.0
+		right    = pdf.PageWidth - 40
+		rowH     = 18.0
+		pageBody = pdf.PageHeight - 60
+	)
+	date := func(ts int64) string { return time.Unix(ts, 0).Format("2006-01-02") }
+	money := func(v float64) string { return fmt.Sprintf("%.2f", v) }
+
+	doc := pdf.New()
+	y := 0.0
+	header := func() {
+		doc.AddPage()
+		doc.SetFontSize(16)
+		doc.Text(left, 50, "客户对账单")
+		doc.SetFontSize(10)
+		doc.Text(left, 75, "客户: "+stmt.CustomerName)
+		doc.Text(300, 75, fmt.Sprintf("期间: %s 至 %s", date(stmt.StartDate), date(stmt.EndDate)))
+		doc.Text(left, 92, fmt.Sprintf("账期: %d天", stmt.CreditDays))
+		doc.Text(300, 92, "信用额度: "+money(stmt.CreditLimit))
+
+		y = 115
+		doc.Line(left, y-12, right, y-12)
+		doc.Text(left, y, "日期")
+		doc.Text(110, y, "单号")
+		doc.Text(220, y, "摘要")
+		doc.TextRight(420, y, "应收")
+		doc.TextRight(490, y, "收款")
+		doc.TextRight(right, y, "余额")
+		doc.Line(left, y+6, right, y+6)
+		y += rowH
+	}
+	row := func(cols ...string) {
+		if y > pageBody {
+			header()
+		}
+		doc.Text(left, y, cols[0])
+		doc.Text(110, y, cols[1])
+		doc.Text(220, y, cols[2])
+		doc.TextRight(420, y, cols[3])
+		doc.TextRight(490, y, cols[4])
+		doc.TextRight(right, y, cols[5])
+		y += rowH
+	}
+
+	header()
+	row(date(stmt.StartDate), "", "期初余额", "", "", money(stmt.OpeningBalance))
+	for _, line := range stmt.Lines {
+		debit, credit := "", ""
+		if line.Debit != 0 {
+			debit = money(line.Debit)
+		}
+		if line.Credit != 0 {
+			credit = money(line.Credit)
+		}
+		row(date(line.Date), line.DocNo, line.Description, debit, credit, money(line.Balance))
+	}
+	doc.Line(left, y-12, right, y-12)
+	row(date(stmt.EndDate), "", "本期合计 / 期末余额", money(stmt.TotalDebit), money(stmt.TotalCredit), money(stmt.ClosingBalance))
+
+	// 账龄汇总
+	if y+3*rowH > pageBody {
+		header()
+	}
+	y += rowH / 2
+	doc.Text(left, y, "账龄分析")
+	y += rowH
+	aging := stmt.Aging
+	doc.Text(left, y, "未到期: "+money(aging.Current))
+	doc.Text(170, y, "1-30天: "+money(aging.Days1To30))
+	doc.Text(300, y, "31-60天: "+money(aging.Days31To60))
+	doc.Text(430, y, "61-90天: "+money(aging.Days61To90))
+	y += rowH
+	doc.Text(left, y, "90天以上: "+money(aging.Over90))
+	doc.Text(170, y, "预收款: "+money(aging.Unapplied))
+	doc.Text(300, y, "应收余额: "+money(aging.Outstanding))
+
+	doc.SetFontSize(8)
+	doc.Text(left, pdf.PageHeight-30, "打印时间: "+time.Now().Format("2006-01-02 15:04:05"))
+
+	return doc.Bytes(), nil
+}
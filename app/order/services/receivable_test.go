@@ -0,0 +1,145 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"mule-cloud/app/order/dto"
+	"mule-cloud/internal/models"
+)
+
+func openInvoice(id string, amount, paid float64) *models.Invoice {
+	return &models.Invoice{ID: id, InvoiceNo: "INV-" + id, Amount: amount, PaidAmount: paid, Status: invoiceStatusOf(amount, paid)}
+}
+
+// TestManualAllocations 测试指定核销明细的校验
+func TestManualAllocations(t *testing.T) {
+	invoices := []*models.Invoice{openInvoice("a", 100, 0), openInvoice("b", 200, 150)}
+	alloc := func(id string, amount float64) models.ReceiptAllocation {
+		return models.ReceiptAllocation{InvoiceID: id, Amount: amount}
+	}
+
+	tests := []struct {
+		name      string
+		requested []models.ReceiptAllocation
+		amount    float64
+		want      map[string]float64
+		wantErr   string
+	}{
+		{"within balance", []models.ReceiptAllocation{alloc("a", 60), alloc("b", 50)}, 110, map[string]float64{"a": 60, "b": 50}, ""},
+		{"duplicate invoice merged", []models.ReceiptAllocation{alloc("a", 30), alloc("a", 40.004)}, 100, map[string]float64{"a": 70}, ""},
+		{"duplicate invoice over balance", []models.ReceiptAllocation{alloc("a", 80), alloc("a", 80)}, 200, nil, "超出未收余额"},
+		{"over balance", []models.ReceiptAllocation{alloc("b", 50.01)}, 100, nil, "超出未收余额"},
+		{"over receipt amount", []models.ReceiptAllocation{alloc("a", 60), alloc("b", 50)}, 100, nil, "超出收款金额"},
+		{"unknown invoice", []models.ReceiptAllocation{alloc("c", 10)}, 100, nil, "不存在"},
+		{"non-positive amount", []models.ReceiptAllocation{alloc("a", 0)}, 100, nil, "必须大于0"},
+	}
+	s := &receivableService{}
+	for _, tt := range tests {
+		got, err := s.manualAllocations(invoices, tt.requested, tt.amount)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		amounts := make(map[string]float64, len(got))
+		for _, a := range got {
+			if _, dup := amounts[a.InvoiceID]; dup {
+				t.Errorf("%s: invoice %s allocated twice", tt.name, a.InvoiceID)
+			}
+			amounts[a.InvoiceID] = a.Amount
+		}
+		if !reflect.DeepEqual(amounts, tt.want) {
+			t.Errorf("%s: allocations = %v, want %v", tt.name, amounts, tt.want)
+		}
+	}
+}
+
+// TestAutoAllocations 测试按顺序自动核销
+func TestAutoAllocations(t *testing.T) {
+	invoices := []*models.Invoice{openInvoice("a", 100, 40), openInvoice("b", 50, 50), openInvoice("c", 80, 0)}
+
+	tests := []struct {
+		name   string
+		amount float64
+		want   []models.ReceiptAllocation
+	}{
+		{"partial first", 30, []models.ReceiptAllocation{{InvoiceID: "a", InvoiceNo: "INV-a", Amount: 30}}},
+		{"skips settled", 100, []models.ReceiptAllocation{
+			{InvoiceID: "a", InvoiceNo: "INV-a", Amount: 60},
+			{InvoiceID: "c", InvoiceNo: "INV-c", Amount: 40},
+		}},
+		{"leaves unapplied", 500, []models.ReceiptAllocation{
+			{InvoiceID: "a", InvoiceNo: "INV-a", Amount: 60},
+			{InvoiceID: "c", InvoiceNo: "INV-c", Amount: 80},
+		}},
+	}
+	for _, tt := range tests {
+		if got := autoAllocations(invoices, tt.amount); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: autoAllocations() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestInvoiceStatusOf 测试按已收金额计算发票状态
+func TestInvoiceStatusOf(t *testing.T) {
+	tests := []struct {
+		amount, paid float64
+		want         int
+	}{
+		{100, 0, models.InvoiceStatusOpen},
+		{100, 0.01, models.InvoiceStatusPartial},
+		{100, 100, models.InvoiceStatusPaid},
+		{100, 120, models.InvoiceStatusPaid},
+	}
+	for _, tt := range tests {
+		if got := invoiceStatusOf(tt.amount, tt.paid); got != tt.want {
+			t.Errorf("invoiceStatusOf(%v, %v) = %d, want %d", tt.amount, tt.paid, got, tt.want)
+		}
+	}
+}
+
+// TestAddAging 测试账龄区间划分
+func TestAddAging(t *testing.T) {
+	const due = int64(1_700_000_000)
+	days := func(n int64) int64 { return due + n*secondsPerDay }
+
+	row := &dto.AgingRow{}
+	addAging(row, 10, due, due)        // 到期当天
+	addAging(row, 20, due, days(1))    // 逾期1天
+	addAging(row, 30, due, days(30))   // 逾期30天
+	addAging(row, 40, due, days(31))   // 逾期31天
+	addAging(row, 50, due, days(60))   // 逾期60天
+	addAging(row, 60, due, days(90))   // 逾期90天
+	addAging(row, 70, due, days(91))   // 逾期91天
+	addAging(row, 80, due, days(-10))  // 未到期
+	addAging(row, 0.1, due, days(200)) // 逾期较久
+	addAging(row, 0.2, due, days(200))
+	roundAgingRow(row)
+
+	want := dto.AgingRow{
+		Current:      90,
+		Days1To30:    50,
+		Days31To60:   90,
+		Days61To90:   60,
+		Over90:       70.3,
+		InvoiceCount: 10,
+		Outstanding:  360.3,
+	}
+	if !reflect.DeepEqual(*row, want) {
+		t.Errorf("aging row = %+v, want %+v", *row, want)
+	}
+
+	limited := &dto.AgingRow{CreditLimit: 100}
+	addAging(limited, 100.01, due, due)
+	roundAgingRow(limited)
+	if !limited.OverLimit {
+		t.Errorf("outstanding %.2f over credit limit %.2f should be flagged", limited.Outstanding, limited.CreditLimit)
+	}
+}
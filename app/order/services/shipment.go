@@ -0,0 +1,731 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mule-cloud/app/order/dto"
+	corecontext "mule-cloud/core/context"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.uber.org/zap"
+)
+
+// IShipmentService 装箱发货服务接口
+type IShipmentService interface {
+	// 装箱管理
+	PackCartons(ctx context.Context, req *dto.PackCartonsRequest) ([]*models.PackingCarton, error)
+	GetCartonList(ctx context.Context, req *dto.CartonListRequest) ([]*models.PackingCarton, int64, error)
+	GetCartonByID(ctx context.Context, id string) (*models.PackingCarton, error)
+	GetCartonByBarcode(ctx context.Context, barcode string) (*models.PackingCarton, error)
+	DeleteCarton(ctx context.Context, id string) error
+	PrintCartonLabels(ctx context.Context, ids []string) ([]dto.CartonLabel, error)
+	GetPackingList(ctx context.Context, orderID string) (*dto.PackingListResponse, error)
+
+	// 发货管理
+	CreateShipment(ctx context.Context, req *dto.ShipmentCreateRequest) (*dto.ShipmentResponse, error)
+	GetShipmentList(ctx context.Context, req *dto.ShipmentListRequest) ([]*models.Shipment, int64, error)
+	GetShipmentByID(ctx context.Context, id string) (*models.Shipment, error)
+	VoidShipment(ctx context.Context, req *dto.ShipmentVoidRequest) (*models.Shipment, error)
+}
+
+type shipmentService struct {
+	cartonRepo     repository.PackingCartonRepository
+	shipmentRepo   repository.ShipmentRepository
+	orderRepo      repository.OrderRepository
+	invoiceRepo    repository.InvoiceRepository
+	counterRepo    repository.CounterRepository
+	workflowEngine IWorkflowEngineService
+}
+
+// NewShipmentService 创建装箱发货服务
+func NewShipmentService() IShipmentService {
+	return &shipmentService{
+		cartonRepo:     repository.NewPackingCartonRepository(),
+		shipmentRepo:   repository.NewShipmentRepository(),
+		orderRepo:      repository.NewOrderRepository(),
+		invoiceRepo:    repository.NewInvoiceRepository(),
+		counterRepo:    repository.NewCounterRepository(),
+		workflowEngine: NewWorkflowEngineService(),
+	}
+}
+
+// packKey 颜色+尺码组合键
+func packKey(color, size string) string {
+	return color + "|" + size
+}
+
+// packedCounterKey 订单某颜色尺码已装箱数量的计数器键
+func packedCounterKey(orderID, key string) string {
+	return "packed:" + orderID + ":" + key
+}
+
+// sumPacked 统计装箱中各颜色尺码的数量
+func sumPacked(cartons []*models.PackingCarton) map[string]int {
+	packed := make(map[string]int)
+	for _, carton := range cartons {
+		for _, item := range carton.Items {
+			packed[packKey(item.Color, item.Size)] += item.Quantity
+		}
+	}
+	return packed
+}
+
+// seedPacked 从已有装箱初始化已装箱数量计数器（计数器已存在时不变）
+func (s *shipmentService) seedPacked(ctx context.Context, orderID string, keys map[string]int, packed map[string]int) error {
+	for key := range keys {
+		if err := s.counterRepo.Seed(ctx, packedCounterKey(orderID, key), int64(packed[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reservePacked 原子占用装箱数量，任一颜色尺码超出订单数量时退回已占用的数量
+func (s *shipmentService) reservePacked(ctx context.Context, orderID string, qty, limits map[string]int) error {
+	reserved := make(map[string]int, len(qty))
+	for key, n := range qty {
+		ok, err := s.counterRepo.AddIfAtMost(ctx, packedCounterKey(orderID, key), int64(n), int64(limits[key]))
+		if err == nil && !ok {
+			color, size, _ := strings.Cut(key, "|")
+			err = fmt.Errorf("颜色[%s]尺码[%s]装箱数量超出订单数量%d", color, size, limits[key])
+		}
+		if err != nil {
+			s.releasePacked(ctx, orderID, reserved)
+			return err
+		}
+		reserved[key] = n
+	}
+	return nil
+}
+
+// releasePacked 退回已装箱数量（删除装箱或装箱失败时）
+func (s *shipmentService) releasePacked(ctx context.Context, orderID string, qty map[string]int) {
+	for key, n := range qty {
+		if _, err := s.counterRepo.Add(ctx, packedCounterKey(orderID, key), -int64(n)); err != nil {
+			log.Ctx(ctx).Error("退回已装箱数量失败",
+				zap.String("order_id", orderID),
+				zap.String("key", key),
+				zap.Int("quantity", n),
+				zap.Error(err))
+		}
+	}
+}
+
+// PackCartons 装箱（单色单码或混色混码按配比装箱）
+func (s *shipmentService) PackCartons(ctx context.Context, req *dto.PackCartonsRequest) ([]*models.PackingCarton, error) {
+	order, err := s.orderRepo.Get(ctx, req.OrderID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("订单不存在")
+		}
+		return nil, err
+	}
+
+	// 只有生产中的订单可以装箱（全部发货后订单从生产中流转为已完成）
+	if order.Status != 2 {
+		return nil, fmt.Errorf("订单未开始生产，不允许装箱")
+	}
+
+	// 订单各颜色尺码的数量上限
+	orderQty := make(map[string]int, len(order.Items))
+	for _, item := range order.Items {
+		orderQty[packKey(item.Color, item.Size)] += item.Quantity
+	}
+
+	// 已装箱数量
+	existing, err := s.cartonRepo.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	packedQty := sumPacked(existing)
+
+	// 校验装箱规格，统计本次各颜色尺码的装箱数量
+	packQty := make(map[string]int)
+	totalCartons := 0
+	for i := range req.Cartons {
+		spec := &req.Cartons[i]
+		if spec.Count <= 0 {
+			spec.Count = 1
+		}
+		if spec.PackType != models.PackTypeSolid && spec.PackType != models.PackTypeMixed {
+			return nil, fmt.Errorf("无效的装箱方式: %s", spec.PackType)
+		}
+		if len(spec.Items) == 0 {
+			return nil, fmt.Errorf("装箱明细不能为空")
+		}
+		if spec.PackType == models.PackTypeSolid && len(spec.Items) != 1 {
+			return nil, fmt.Errorf("单色单码装箱每箱只能包含一个颜色尺码")
+		}
+		totalCartons += spec.Count
+		for _, item := range spec.Items {
+			if item.Quantity <= 0 {
+				return nil, fmt.Errorf("颜色[%s]尺码[%s]的装箱数量必须大于0", item.Color, item.Size)
+			}
+			key := packKey(item.Color, item.Size)
+			limit, ok := orderQty[key]
+			if !ok {
+				return nil, fmt.Errorf("订单中不存在颜色[%s]尺码[%s]", item.Color, item.Size)
+			}
+			packQty[key] += item.Quantity * spec.Count
+			if packedQty[key]+packQty[key] > limit {
+				return nil, fmt.Errorf("颜色[%s]尺码[%s]装箱数量%d超出订单数量%d", item.Color, item.Size, packedQty[key]+packQty[key], limit)
+			}
+		}
+	}
+
+	// 按计数器原子占用装箱数量，并发装箱不会超出订单数量
+	if err := s.seedPacked(ctx, order.ID, packQty, packedQty); err != nil {
+		return nil, err
+	}
+	if err := s.reservePacked(ctx, order.ID, packQty, orderQty); err != nil {
+		return nil, err
+	}
+
+	// 箱号在订单内递增（计数器一次分配一段连续箱号；从已有最大箱号初始化，包含已删除的箱）
+	cartonNo, err := s.allocateCartonNos(ctx, order.ID, totalCartons)
+	if err != nil {
+		s.releasePacked(ctx, order.ID, packQty)
+		return nil, err
+	}
+
+	createdBy := req.CreatedBy
+	if createdBy == "" {
+		createdBy = corecontext.GetUsername(ctx)
+	}
+
+	now := time.Now().Unix()
+	cartons := make([]*models.PackingCarton, 0, totalCartons)
+	for _, spec := range req.Cartons {
+		totalQty := 0
+		for _, item := range spec.Items {
+			totalQty += item.Quantity
+		}
+
+		for i := 0; i < spec.Count; i++ {
+			cartonNo++
+			carton := &models.PackingCarton{
+				ID:           bson.NewObjectID().Hex(),
+				OrderID:      order.ID,
+				ContractNo:   order.ContractNo,
+				StyleNo:      order.StyleNo,
+				StyleName:    order.StyleName,
+				CustomerID:   order.CustomerID,
+				CustomerName: order.CustomerName,
+				CartonNo:     cartonNo,
+				Barcode:      fmt.Sprintf("%s-%04d", order.ContractNo, cartonNo),
+				PackType:     spec.PackType,
+				Items:        spec.Items,
+				TotalQty:     totalQty,
+				GrossWeight:  spec.GrossWeight,
+				NetWeight:    spec.NetWeight,
+				Dimensions:   spec.Dimensions,
+				Status:       0, // 已装箱
+				IsDeleted:    0,
+				CreatedBy:    createdBy,
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			}
+			cartons = append(cartons, carton)
+		}
+	}
+
+	// 创建失败时清理已插入的箱并退回装箱数量，不留下半批装箱
+	if err := s.cartonRepo.CreateMany(ctx, cartons); err != nil {
+		ids := make([]string, len(cartons))
+		for i, carton := range cartons {
+			ids[i] = carton.ID
+		}
+		if purgeErr := s.cartonRepo.Purge(ctx, ids); purgeErr != nil {
+			log.Ctx(ctx).Error("清理装箱失败", zap.String("order_id", order.ID), zap.Error(purgeErr))
+		}
+		s.releasePacked(ctx, order.ID, packQty)
+		return nil, fmt.Errorf("创建装箱失败: %w", err)
+	}
+
+	return cartons, nil
+}
+
+// allocateCartonNos 为订单分配 n 个连续箱号，返回第一个箱号的前一个号
+func (s *shipmentService) allocateCartonNos(ctx context.Context, orderID string, n int) (int, error) {
+	counterKey := "carton:" + orderID
+	maxNo, err := s.cartonRepo.MaxCartonNo(ctx, orderID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.counterRepo.EnsureAtLeast(ctx, counterKey, int64(maxNo)); err != nil {
+		return 0, err
+	}
+	last, err := s.counterRepo.Add(ctx, counterKey, int64(n))
+	if err != nil {
+		return 0, err
+	}
+	return int(last) - n, nil
+}
+
+// GetCartonList 获取装箱列表
+func (s *shipmentService) GetCartonList(ctx context.Context, req *dto.CartonListRequest) ([]*models.PackingCarton, int64, error) {
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return s.cartonRepo.List(ctx, page, pageSize, req.OrderID, req.ContractNo, req.Status)
+}
+
+// GetCartonByID 根据ID获取装箱
+func (s *shipmentService) GetCartonByID(ctx context.Context, id string) (*models.PackingCarton, error) {
+	return s.cartonRepo.GetByID(ctx, id)
+}
+
+// GetCartonByBarcode 扫描箱唛条码获取装箱
+func (s *shipmentService) GetCartonByBarcode(ctx context.Context, barcode string) (*models.PackingCarton, error) {
+	carton, err := s.cartonRepo.GetByBarcode(ctx, barcode)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("箱唛条码不存在")
+		}
+		return nil, err
+	}
+	return carton, nil
+}
+
+// DeleteCarton 删除装箱（已发货的箱不允许删除）
+func (s *shipmentService) DeleteCarton(ctx context.Context, id string) error {
+	carton, err := s.cartonRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if carton.Status == 1 {
+		return fmt.Errorf("第%d箱已发货，不允许删除", carton.CartonNo)
+	}
+
+	// 删除前从现有装箱初始化计数器，删除后退回该箱占用的数量
+	existing, err := s.cartonRepo.ListByOrder(ctx, carton.OrderID)
+	if err != nil {
+		return err
+	}
+	released := sumPacked([]*models.PackingCarton{carton})
+	if err := s.seedPacked(ctx, carton.OrderID, released, sumPacked(existing)); err != nil {
+		return err
+	}
+
+	if err := s.cartonRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return fmt.Errorf("第%d箱已发货或已删除", carton.CartonNo)
+		}
+		return err
+	}
+	s.releasePacked(ctx, carton.OrderID, released)
+	return nil
+}
+
+// PrintCartonLabels 打印箱唛
+func (s *shipmentService) PrintCartonLabels(ctx context.Context, ids []string) ([]dto.CartonLabel, error) {
+	cartons, err := s.cartonRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// 每个订单的总箱数（箱唛上打印 "第N箱/共M箱"）
+	totalByOrder := make(map[string]int)
+	now := time.Now().Unix()
+	labels := make([]dto.CartonLabel, 0, len(cartons))
+
+	for _, carton := range cartons {
+		total, ok := totalByOrder[carton.OrderID]
+		if !ok {
+			orderCartons, err := s.cartonRepo.ListByOrder(ctx, carton.OrderID)
+			if err != nil {
+				return nil, err
+			}
+			total = len(orderCartons)
+			totalByOrder[carton.OrderID] = total
+		}
+
+		carton.PrintCount++
+		carton.PrintedAt = now
+		err = s.cartonRepo.Update(ctx, carton.ID, bson.M{
+			"print_count": carton.PrintCount,
+			"printed_at":  carton.PrintedAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("更新第%d箱打印次数失败: %w", carton.CartonNo, err)
+		}
+
+		labels = append(labels, dto.CartonLabel{
+			CartonID:     carton.ID,
+			Barcode:      carton.Barcode,
+			ContractNo:   carton.ContractNo,
+			StyleNo:      carton.StyleNo,
+			StyleName:    carton.StyleName,
+			CustomerName: carton.CustomerName,
+			CartonNo:     carton.CartonNo,
+			TotalCartons: total,
+			PackType:     carton.PackType,
+			Items:        carton.Items,
+			TotalQty:     carton.TotalQty,
+			GrossWeight:  carton.GrossWeight,
+			NetWeight:    carton.NetWeight,
+			Dimensions:   carton.Dimensions,
+			PrintCount:   carton.PrintCount,
+		})
+	}
+
+	return labels, nil
+}
+
+// GetPackingList 获取订单装箱单
+func (s *shipmentService) GetPackingList(ctx context.Context, orderID string) (*dto.PackingListResponse, error) {
+	order, err := s.orderRepo.Get(ctx, orderID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("订单不存在")
+		}
+		return nil, err
+	}
+
+	cartons, err := s.cartonRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if cartons == nil {
+		cartons = []*models.PackingCarton{}
+	}
+
+	resp := &dto.PackingListResponse{
+		OrderID:      order.ID,
+		ContractNo:   order.ContractNo,
+		StyleNo:      order.StyleNo,
+		StyleName:    order.StyleName,
+		CustomerName: order.CustomerName,
+		Cartons:      cartons,
+		TotalCartons: len(cartons),
+		ShippedQty:   order.ShippedQty,
+	}
+
+	// 以订单明细为基础汇总，保证顺序与订单一致
+	summary := make([]dto.PackingSummaryItem, 0, len(order.Items))
+	index := make(map[string]int, len(order.Items))
+	for _, item := range order.Items {
+		index[packKey(item.Color, item.Size)] = len(summary)
+		summary = append(summary, dto.PackingSummaryItem{
+			Color:      item.Color,
+			Size:       item.Size,
+			OrderQty:   item.Quantity,
+			ShippedQty: item.ShippedQty,
+		})
+		resp.OrderQty += item.Quantity
+	}
+
+	for _, carton := range cartons {
+		resp.GrossWeight += carton.GrossWeight
+		resp.NetWeight += carton.NetWeight
+		for _, item := range carton.Items {
+			if i, ok := index[packKey(item.Color, item.Size)]; ok {
+				summary[i].PackedQty += item.Quantity
+			}
+			resp.PackedQty += item.Quantity
+		}
+	}
+	resp.Summary = summary
+
+	return resp, nil
+}
+
+// CreateShipment 创建发货单（一个订单可以分多次发货）
+func (s *shipmentService) CreateShipment(ctx context.Context, req *dto.ShipmentCreateRequest) (*dto.ShipmentResponse, error) {
+	order, err := s.orderRepo.Get(ctx, req.OrderID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("订单不存在")
+		}
+		return nil, err
+	}
+	if order.Status != 2 {
+		return nil, fmt.Errorf("订单未开始生产，不允许发货")
+	}
+	if len(req.CartonIDs) == 0 {
+		return nil, fmt.Errorf("发货箱不能为空")
+	}
+
+	cartons, err := s.cartonRepo.GetByIDs(ctx, req.CartonIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(cartons) != len(req.CartonIDs) {
+		return nil, fmt.Errorf("部分装箱不存在或已删除")
+	}
+
+	// 按颜色尺码汇总发货明细
+	items := make([]models.PackItem, 0)
+	index := make(map[string]int)
+	totalQty := 0
+	grossWeight := 0.0
+	cartonIDs := make([]string, 0, len(cartons))
+	for _, carton := range cartons {
+		if carton.OrderID != order.ID {
+			return nil, fmt.Errorf("第%d箱不属于该订单", carton.CartonNo)
+		}
+		if carton.Status != 0 {
+			return nil, fmt.Errorf("第%d箱已发货", carton.CartonNo)
+		}
+		for _, item := range carton.Items {
+			key := packKey(item.Color, item.Size)
+			if i, ok := index[key]; ok {
+				items[i].Quantity += item.Quantity
+			} else {
+				index[key] = len(items)
+				items = append(items, item)
+			}
+		}
+		totalQty += carton.TotalQty
+		grossWeight += carton.GrossWeight
+		cartonIDs = append(cartonIDs, carton.ID)
+	}
+
+	// 发货单号：合同号-S序号（计数器原子递增，并发发货不会重号；从已有单据数量初始化，包含已作废的单据）
+	counterKey := "shipment:" + order.ID
+	existing, err := s.shipmentRepo.Count(ctx, bson.M{"order_id": order.ID})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.counterRepo.EnsureAtLeast(ctx, counterKey, existing); err != nil {
+		return nil, err
+	}
+	seq, err := s.counterRepo.Next(ctx, counterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	createdBy := req.CreatedBy
+	if createdBy == "" {
+		createdBy = corecontext.GetUsername(ctx)
+	}
+
+	now := time.Now().Unix()
+	shipment := &models.Shipment{
+		ID:              bson.NewObjectID().Hex(),
+		ShipmentNo:      fmt.Sprintf("%s-S%02d", order.ContractNo, seq),
+		OrderID:         order.ID,
+		ContractNo:      order.ContractNo,
+		StyleNo:         order.StyleNo,
+		StyleName:       order.StyleName,
+		CustomerID:      order.CustomerID,
+		CustomerName:    order.CustomerName,
+		SalesmanID:      order.SalesmanID,
+		SalesmanName:    order.SalesmanName,
+		CartonIDs:       cartonIDs,
+		CartonCount:     len(cartonIDs),
+		Items:           items,
+		TotalQty:        totalQty,
+		GrossWeight:     grossWeight,
+		Carrier:         req.Carrier,
+		TrackingNo:      req.TrackingNo,
+		DeliveryAddress: req.DeliveryAddress,
+		ShippedAt:       now,
+		Status:          1, // 已发货
+		Remark:          req.Remark,
+		IsDeleted:       0,
+		CreatedBy:       createdBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	// 先占用装箱：并发发货同一批箱时只有一个能全部标记成功，其余恢复后返回
+	if err := s.cartonRepo.MarkShipped(ctx, cartonIDs, shipment.ID); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("部分装箱已被其他发货单发出，请刷新后重试")
+		}
+		return nil, fmt.Errorf("更新装箱状态失败: %v", err)
+	}
+
+	if err := s.shipmentRepo.Create(ctx, shipment); err != nil {
+		if resetErr := s.cartonRepo.ResetShipped(ctx, shipment.ID, len(cartonIDs)); resetErr != nil {
+			log.Ctx(ctx).Error("创建发货单失败，恢复装箱状态失败", zap.String("shipment_id", shipment.ID), zap.Error(resetErr))
+		}
+		return nil, err
+	}
+
+	// 回写订单发货数量
+	fullyShipped, shippedQty, err := s.syncOrderShipped(ctx, order)
+	if err != nil {
+		return nil, fmt.Errorf("更新订单发货数量失败: %v", err)
+	}
+
+	resp := &dto.ShipmentResponse{Shipment: shipment, FullyShipped: fullyShipped}
+
+	// 🔥 全部发货后才触发订单完成
+	if fullyShipped {
+		err = s.workflowEngine.TransitionOrderState(
+			ctx,
+			order.ID,
+			"complete",
+			createdBy,
+			"订单已全部发货",
+			map[string]interface{}{
+				"shipped_qty":   shippedQty,
+				"ship_progress": 1.0,
+				"shipment_id":   shipment.ID,
+			},
+		)
+		if err != nil {
+			log.Ctx(ctx).Error("订单已全部发货，但完成订单失败", zap.String("order_id", order.ID), zap.Error(err))
+		} else {
+			resp.OrderCompleted = true
+		}
+	}
+
+	return resp, nil
+}
+
+// GetShipmentList 获取发货单列表
+func (s *shipmentService) GetShipmentList(ctx context.Context, req *dto.ShipmentListRequest) ([]*models.Shipment, int64, error) {
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return s.shipmentRepo.List(ctx, page, pageSize, req.OrderID, req.CustomerID, req.ContractNo, req.Status)
+}
+
+// GetShipmentByID 根据ID获取发货单
+func (s *shipmentService) GetShipmentByID(ctx context.Context, id string) (*models.Shipment, error) {
+	return s.shipmentRepo.GetByID(ctx, id)
+}
+
+// VoidShipment 作废发货单（订单已完成后不允许作废）
+func (s *shipmentService) VoidShipment(ctx context.Context, req *dto.ShipmentVoidRequest) (*models.Shipment, error) {
+	shipment, err := s.shipmentRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if shipment.Status != 1 {
+		return nil, fmt.Errorf("发货单已作废")
+	}
+
+	order, err := s.orderRepo.Get(ctx, shipment.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("订单不存在")
+	}
+	if order.Status == 3 {
+		return nil, fmt.Errorf("订单已完成，不允许作废发货单")
+	}
+
+	// 已开票的发货单需先作废发票
+	invoiced, err := s.invoiceRepo.Count(ctx, bson.M{
+		"source_type": models.InvoiceSourceShipment,
+		"source_id":   shipment.ID,
+		"status":      bson.M{"$ne": models.InvoiceStatusVoid},
+		"is_deleted":  0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if invoiced > 0 {
+		return nil, fmt.Errorf("发货单已开票，请先作废发票")
+	}
+
+	remark := shipment.Remark
+	if req.Reason != "" {
+		remark = "作废原因: " + req.Reason
+	}
+	// 按状态条件作废，并发作废同一发货单时只有一个成功
+	err = s.shipmentRepo.UpdateIfStatus(ctx, shipment.ID, 1, bson.M{
+		"status":     2,
+		"remark":     remark,
+		"updated_at": time.Now().Unix(),
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("发货单已作废")
+		}
+		return nil, err
+	}
+
+	if err := s.cartonRepo.ResetShipped(ctx, shipment.ID, len(shipment.CartonIDs)); err != nil {
+		// 装箱状态与发货单不一致时恢复发货单状态
+		if restoreErr := s.shipmentRepo.Update(ctx, shipment.ID, bson.M{"status": 1, "remark": shipment.Remark, "updated_at": time.Now().Unix()}); restoreErr != nil {
+			log.Ctx(ctx).Error("恢复发货单状态失败", zap.String("shipment_id", shipment.ID), zap.Error(restoreErr))
+		}
+		return nil, fmt.Errorf("恢复装箱状态失败: %v", err)
+	}
+
+	if _, _, err := s.syncOrderShipped(ctx, order); err != nil {
+		return nil, fmt.Errorf("更新订单发货数量失败: %v", err)
+	}
+
+	return s.shipmentRepo.GetByID(ctx, shipment.ID)
+}
+
+// syncOrderShipped 根据有效发货单重新计算订单明细的已发货数量
+func (s *shipmentService) syncOrderShipped(ctx context.Context, order *models.Order) (bool, int, error) {
+	shipments, err := s.shipmentRepo.ListShippedByOrder(ctx, order.ID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	shipped := make(map[string]int)
+	for _, shipment := range shipments {
+		for _, item := range shipment.Items {
+			shipped[packKey(item.Color, item.Size)] += item.Quantity
+		}
+	}
+
+	items, totalShipped, fullyShipped := distributeShipped(order.Items, shipped)
+	err = s.orderRepo.Update(ctx, order.ID, bson.M{
+		"items":       items,
+		"shipped_qty": totalShipped,
+		"updated_at":  time.Now().Unix(),
+	})
+	if err != nil {
+		return false, 0, err
+	}
+
+	return fullyShipped, totalShipped, nil
+}
+
+// distributeShipped 按颜色尺码的发货数量依次分配到订单明细
+//
+// 同一颜色尺码有多行时按行顺序填满，超出订单数量的部分计入该颜色尺码的最后一行，
+// 返回分配后的明细、发货总数以及是否全部发货
+func distributeShipped(orderItems []models.OrderItem, shipped map[string]int) ([]models.OrderItem, int, bool) {
+	last := make(map[string]int, len(orderItems))
+	for i, item := range orderItems {
+		last[packKey(item.Color, item.Size)] = i
+	}
+
+	remaining := make(map[string]int, len(shipped))
+	for key, qty := range shipped {
+		remaining[key] = qty
+	}
+
+	items := make([]models.OrderItem, len(orderItems))
+	totalShipped := 0
+	fullyShipped := len(orderItems) > 0
+	for i, item := range orderItems {
+		key := packKey(item.Color, item.Size)
+		item.ShippedQty = min(remaining[key], item.Quantity)
+		if last[key] == i {
+			item.ShippedQty = remaining[key]
+		}
+		remaining[key] -= item.ShippedQty
+		if item.ShippedQty < item.Quantity {
+			fullyShipped = false
+		}
+		totalShipped += item.ShippedQty
+		items[i] = item
+	}
+	return items, totalShipped, fullyShipped
+}
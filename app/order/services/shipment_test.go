@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+)
+
+// TestDistributeShipped 测试发货数量按行分配到订单明细
+func TestDistributeShipped(t *testing.T) {
+	item := func(color, size string, qty int) models.OrderItem {
+		return models.OrderItem{Color: color, Size: size, Quantity: qty}
+	}
+	shippedQty := func(items []models.OrderItem) []int {
+		qty := make([]int, len(items))
+		for i, item := range items {
+			qty[i] = item.ShippedQty
+		}
+		return qty
+	}
+
+	tests := []struct {
+		name      string
+		items     []models.OrderItem
+		shipped   map[string]int
+		wantQty   []int
+		wantTotal int
+		wantFull  bool
+	}{
+		{
+			name:      "partial",
+			items:     []models.OrderItem{item("红", "M", 100), item("红", "L", 50)},
+			shipped:   map[string]int{packKey("红", "M"): 60},
+			wantQty:   []int{60, 0},
+			wantTotal: 60,
+		},
+		{
+			name:      "fully shipped",
+			items:     []models.OrderItem{item("红", "M", 100), item("红", "L", 50)},
+			shipped:   map[string]int{packKey("红", "M"): 100, packKey("红", "L"): 50},
+			wantQty:   []int{100, 50},
+			wantTotal: 150,
+			wantFull:  true,
+		},
+		{
+			name:      "duplicate rows are filled in order, not double counted",
+			items:     []models.OrderItem{item("红", "M", 100), item("蓝", "M", 30), item("红", "M", 50)},
+			shipped:   map[string]int{packKey("红", "M"): 120},
+			wantQty:   []int{100, 0, 20},
+			wantTotal: 120,
+		},
+		{
+			name:      "excess goes to the last row of the key",
+			items:     []models.OrderItem{item("红", "M", 10), item("红", "M", 10)},
+			shipped:   map[string]int{packKey("红", "M"): 25},
+			wantQty:   []int{10, 15},
+			wantTotal: 25,
+			wantFull:  true,
+		},
+		{
+			name:    "no items",
+			items:   nil,
+			shipped: map[string]int{packKey("红", "M"): 10},
+			wantQty: []int{},
+		},
+	}
+	for _, tt := range tests {
+		items, total, full := distributeShipped(tt.items, tt.shipped)
+		if got := shippedQty(items); !reflect.DeepEqual(got, tt.wantQty) {
+			t.Errorf("%s: shipped = %v, want %v", tt.name, got, tt.wantQty)
+		}
+		if total != tt.wantTotal || full != tt.wantFull {
+			t.Errorf("%s: total = %d, full = %v, want %d, %v", tt.name, total, full, tt.wantTotal, tt.wantFull)
+		}
+	}
+	// 不修改原订单明细
+	items := []models.OrderItem{item("红", "M", 10)}
+	distributeShipped(items, map[string]int{packKey("红", "M"): 5})
+	if items[0].ShippedQty != 0 {
+		t.Errorf("original items modified: %+v", items)
+	}
+}
+
+// memCounter 内存计数器
+type memCounter struct {
+	repository.CounterRepository
+	seq map[string]int64
+}
+
+func (c *memCounter) Add(ctx context.Context, key string, delta int64) (int64, error) {
+	c.seq[key] += delta
+	return c.seq[key], nil
+}
+
+func (c *memCounter) AddIfAtMost(ctx context.Context, key string, delta, limit int64) (bool, error) {
+	if c.seq[key]+delta > limit {
+		return false, nil
+	}
+	c.seq[key] += delta
+	return true, nil
+}
+
+// TestReservePacked 测试装箱数量占用：超出订单数量时失败并退回本次已占用的数量
+func TestReservePacked(t *testing.T) {
+	ctx := context.Background()
+	counter := &memCounter{seq: map[string]int64{
+		packedCounterKey("o1", packKey("红", "M")): 80,
+		packedCounterKey("o1", packKey("红", "L")): 0,
+	}}
+	s := &shipmentService{counterRepo: counter}
+	limits := map[string]int{packKey("红", "M"): 100, packKey("红", "L"): 50}
+
+	if err := s.reservePacked(ctx, "o1", map[string]int{packKey("红", "M"): 20, packKey("红", "L"): 50}, limits); err != nil {
+		t.Fatalf("reservePacked() error = %v", err)
+	}
+	want := map[string]int64{
+		packedCounterKey("o1", packKey("红", "M")): 100,
+		packedCounterKey("o1", packKey("红", "L")): 50,
+	}
+	if !reflect.DeepEqual(counter.seq, want) {
+		t.Fatalf("seq = %v, want %v", counter.seq, want)
+	}
+
+	// 红L 已满，红M 退回后本次占用全部回滚
+	s.releasePacked(ctx, "o1", map[string]int{packKey("红", "M"): 30})
+	if err := s.reservePacked(ctx, "o1", map[string]int{packKey("红", "M"): 10, packKey("红", "L"): 1}, limits); err == nil {
+		t.Fatal("reservePacked() error = nil, want over limit")
+	}
+	want[packedCounterKey("o1", packKey("红", "M"))] = 70
+	if !reflect.DeepEqual(counter.seq, want) {
+		t.Fatalf("seq after rollback = %v, want %v", counter.seq, want)
+	}
+}
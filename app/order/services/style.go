@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"mule-cloud/app/order/dto"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
@@ -19,16 +20,26 @@ type IStyleService interface {
 	Create(ctx context.Context, req dto.StyleCreateRequest) (*models.Style, error)
 	Update(ctx context.Context, req dto.StyleUpdateRequest) (*models.Style, error)
 	Delete(ctx context.Context, id string) error
+	ApplyTemplate(ctx context.Context, req dto.StyleApplyTemplateRequest) (*models.Style, error)
+	Reprice(ctx context.Context, req dto.StyleRepriceRequest) (*dto.StyleRepriceResponse, error)
 }
 
 // StyleService 款式服务实现
 type StyleService struct {
-	repo repository.StyleRepository
+	repo          repository.StyleRepository
+	procedureRepo repository.MasterDataRepository[models.Procedure]
+	templateRepo  repository.MasterDataRepository[models.ProcedureTemplate]
+	pricingRepo   repository.PricingSettingRepository
 }
 
 // NewStyleService 创建款式服务
 func NewStyleService() IStyleService {
-	return &StyleService{repo: repository.NewStyleRepository()}
+	return &StyleService{
+		repo:          repository.NewStyleRepository(),
+		procedureRepo: repository.NewProcedureRepository(),
+		templateRepo:  repository.NewProcedureTemplateRepository(),
+		pricingRepo:   repository.NewPricingSettingRepository(),
+	}
 }
 
 // Get 获取款式
@@ -150,6 +161,9 @@ func (s *StyleService) Create(ctx context.Context, req dto.StyleCreateRequest) (
 		if err := ValidateStyleProcedures(req.Procedures); err != nil {
 			return nil, err
 		}
+		if err := s.priceProcedures(ctx, req.Procedures); err != nil {
+			return nil, err
+		}
 	}
 
 	now := time.Now().Unix()
@@ -215,6 +229,9 @@ func (s *StyleService) Update(ctx context.Context, req dto.StyleUpdateRequest) (
 		if err := ValidateStyleProcedures(req.Procedures); err != nil {
 			return nil, err
 		}
+		if err := s.priceProcedures(ctx, req.Procedures); err != nil {
+			return nil, err
+		}
 		update["procedures"] = req.Procedures
 	}
 	if req.Status >= 0 {
@@ -233,3 +250,161 @@ func (s *StyleService) Update(ctx context.Context, req dto.StyleUpdateRequest) (
 func (s *StyleService) Delete(ctx context.Context, id string) error {
 	return s.repo.Delete(ctx, id)
 }
+
+// ApplyTemplate 款式套用工序模板（工价按 SAM×费率 计算）
+func (s *StyleService) ApplyTemplate(ctx context.Context, req dto.StyleApplyTemplateRequest) (*models.Style, error) {
+	style, err := s.repo.Get(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	template, err := s.templateRepo.Get(ctx, req.TemplateID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("工序模板不存在")
+		}
+		return nil, err
+	}
+
+	procedures := []models.StyleProcedure{}
+	if req.Append {
+		procedures = append(procedures, style.Procedures...)
+	}
+	hasFinal := false
+	for _, p := range procedures {
+		if p.IsSlowest {
+			hasFinal = true
+		}
+	}
+	for _, item := range template.Items {
+		procedures = append(procedures, models.StyleProcedure{
+			Sequence:      len(procedures) + 1,
+			ProcedureID:   item.ProcedureID,
+			ProcedureName: item.ProcedureName,
+			// 追加时保留原有的最终工序
+			IsSlowest: item.IsSlowest && !hasFinal,
+			NoBundle:  item.NoBundle,
+		})
+	}
+	if err := ValidateStyleProcedures(procedures); err != nil {
+		return nil, err
+	}
+	if err := s.priceProcedures(ctx, procedures); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"procedures": procedures, "updated_at": time.Now().Unix()}
+	if err := s.repo.Update(ctx, req.ID, update); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, req.ID)
+}
+
+// Reprice 按工序库标准工时和当前费率重算款式工价（仅处理来自工序库的工序）
+func (s *StyleService) Reprice(ctx context.Context, req dto.StyleRepriceRequest) (*dto.StyleRepriceResponse, error) {
+	pricing, err := s.pricingRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"is_deleted": 0, "procedures.procedure_id": bson.M{"$gt": ""}}
+	if len(req.StyleIDs) > 0 {
+		ids := make([]bson.ObjectID, 0, len(req.StyleIDs))
+		for _, id := range req.StyleIDs {
+			objectID, err := bson.ObjectIDFromHex(id)
+			if err != nil {
+				return nil, fmt.Errorf("无效的款式ID: %s", id)
+			}
+			ids = append(ids, objectID)
+		}
+		filter["_id"] = bson.M{"$in": ids}
+	}
+
+	collection := s.repo.GetCollectionWithContext(ctx)
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	styles := []models.Style{}
+	if err := cursor.All(ctx, &styles); err != nil {
+		return nil, err
+	}
+
+	resp := &dto.StyleRepriceResponse{MinuteRate: pricing.MinuteRate}
+	library := map[string]*models.Procedure{}
+	for _, style := range styles {
+		changed := 0
+		for i := range style.Procedures {
+			p := &style.Procedures[i]
+			if p.ProcedureID == "" {
+				continue
+			}
+			procedure, err := s.libraryProcedure(ctx, library, p.ProcedureID)
+			if err != nil {
+				return nil, err
+			}
+			if procedure == nil {
+				continue
+			}
+			price := procedure.PriceAt(pricing.MinuteRate)
+			if p.SAM == procedure.SAM && p.UnitPrice == price {
+				continue
+			}
+			p.SAM = procedure.SAM
+			p.UnitPrice = price
+			changed++
+		}
+		if changed == 0 {
+			continue
+		}
+		update := bson.M{"procedures": style.Procedures, "updated_at": time.Now().Unix()}
+		if err := s.repo.Update(ctx, style.ID, update); err != nil {
+			return nil, err
+		}
+		resp.Styles++
+		resp.Procedures += changed
+	}
+	return resp, nil
+}
+
+// priceProcedures 来自工序库的工序统一带出名称、标准工时和工价
+func (s *StyleService) priceProcedures(ctx context.Context, procedures []models.StyleProcedure) error {
+	var pricing *models.PricingSetting
+	library := map[string]*models.Procedure{}
+	for i := range procedures {
+		p := &procedures[i]
+		if p.ProcedureID == "" {
+			continue
+		}
+		procedure, err := s.libraryProcedure(ctx, library, p.ProcedureID)
+		if err != nil {
+			return err
+		}
+		if procedure == nil {
+			return fmt.Errorf("工序[%s]在工序库中不存在", p.ProcedureName)
+		}
+		if pricing == nil {
+			if pricing, err = s.pricingRepo.Get(ctx); err != nil {
+				return err
+			}
+		}
+		p.ProcedureName = procedure.Value
+		p.SAM = procedure.SAM
+		if procedure.Priced(pricing.MinuteRate) || p.UnitPrice <= 0 {
+			p.UnitPrice = procedure.PriceAt(pricing.MinuteRate)
+		}
+	}
+	return nil
+}
+
+// libraryProcedure 带缓存地读取工序库（已删除的工序返回 nil）
+func (s *StyleService) libraryProcedure(ctx context.Context, cache map[string]*models.Procedure, id string) (*models.Procedure, error) {
+	if procedure, ok := cache[id]; ok {
+		return procedure, nil
+	}
+	procedure, err := s.procedureRepo.Get(ctx, id)
+	if err != nil && err != repository.ErrNotFound {
+		return nil, err
+	}
+	cache[id] = procedure
+	return procedure, nil
+}
@@ -181,6 +181,8 @@ func (s *workflowEngineService) TransitionOrderState(
 		contextData["quantity"] = order.Quantity
 		contextData["progress"] = order.Progress
 		contextData["status"] = order.Status
+		contextData["shipped_qty"] = order.ShippedQty
+		contextData["ship_progress"] = shipProgress(order)
 		for k, v := range metadata {
 			contextData[k] = v
 		}
@@ -333,6 +335,14 @@ func (s *workflowEngineService) GetAvailableTransitions(ctx context.Context, ord
 	return availableTransitions, nil
 }
 
+// shipProgress 计算订单发货进度（0-1之间的小数）
+func shipProgress(order *models.Order) float64 {
+	if order.Quantity <= 0 {
+		return 0
+	}
+	return float64(order.ShippedQty) / float64(order.Quantity)
+}
+
 // checkCondition 检查单个条件
 func (s *workflowEngineService) checkCondition(condition models.TransitionCondition, data map[string]interface{}) bool {
 	if condition.Type != "field" {
@@ -71,7 +71,7 @@ func (s *WorkflowTemplateService) getBasicOrderTemplate() WorkflowTemplate {
 		Description: "标准的订单处理流程，包含草稿、已下单、生产中、已完成、已取消五个状态，支持进度检查和权限控制",
 		Category:    "订单管理",
 		Icon:        "📦",
-		Preview:     "草稿 → 已下单 → 生产中 → 已完成",
+		Preview:     "草稿 → 已下单 → 生产中 → 已完成（全部发货）",
 		States: []WorkflowTemplateState{
 			{
 				Code:        "draft",
@@ -99,7 +99,7 @@ func (s *WorkflowTemplateService) getBasicOrderTemplate() WorkflowTemplate {
 				Name:        "已完成",
 				Type:        "end",
 				Color:       "#67C23A",
-				Description: "订单已完成，进度达到100%",
+				Description: "订单已完成，全部发货",
 			},
 			{
 				Code:        "cancelled",
@@ -140,8 +140,14 @@ func (s *WorkflowTemplateService) getBasicOrderTemplate() WorkflowTemplate {
 				Event:         "complete",
 				EventLabel:    "完成",
 				HasCondition:  true,
-				ConditionDesc: "进度必须达到100%",
+				ConditionDesc: "订单必须全部发货",
 				AvailableFields: []WorkflowConditionField{
+					{
+						Key:         "ship_progress",
+						Label:       "发货进度",
+						Type:        "number",
+						Description: "订单的发货进度（0-1之间的小数），全部发货为1",
+					},
 					{
 						Key:         "progress",
 						Label:       "生产进度",
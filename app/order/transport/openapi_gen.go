@@ -0,0 +1,73 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package transport
+
+import (
+	"mule-cloud/app/order/dto"
+	"mule-cloud/core/openapi"
+)
+
+// Operations 处理器的接口描述（cmd/openapi-gen 生成，服务的 /openapi.json 使用）
+var Operations = openapi.Operations{
+	"mule-cloud/app/order/transport.HealthHandler":                       {Summary: "健康检查", Tag: "common", Response: map[string]string{}},
+	"mule-cloud/app/order/transport.CreateCuttingTaskHandler":            {Summary: "创建裁剪任务", Tag: "cutting", Request: dto.CuttingTaskCreateRequest{}, Response: dto.CuttingTaskResponse{}},
+	"mule-cloud/app/order/transport.ListCuttingTasksHandler":             {Summary: "裁剪任务列表", Tag: "cutting", Request: dto.CuttingTaskListRequest{}, Response: dto.CuttingTaskListResponse{}},
+	"mule-cloud/app/order/transport.GetCuttingTaskHandler":               {Summary: "获取裁剪任务详情", Tag: "cutting", Response: dto.CuttingTaskResponse{}},
+	"mule-cloud/app/order/transport.GetCuttingTaskByOrderHandler":        {Summary: "根据订单ID获取裁剪任务", Tag: "cutting", Response: dto.CuttingTaskResponse{}},
+	"mule-cloud/app/order/transport.CreateCuttingBatchHandler":           {Summary: "创建裁剪批次", Tag: "cutting", Request: dto.CuttingBatchCreateRequest{}, Response: dto.CuttingBatchResponse{}},
+	"mule-cloud/app/order/transport.BulkCreateCuttingBatchHandler":       {Summary: "批量创建裁剪批次", Tag: "cutting", Request: dto.CuttingBatchBulkCreateRequest{}, Response: dto.CuttingBatchBulkCreateResponse{}},
+	"mule-cloud/app/order/transport.ListCuttingBatchesHandler":           {Summary: "裁剪批次列表", Tag: "cutting", Request: dto.CuttingBatchListRequest{}, Response: dto.CuttingBatchListResponse{}},
+	"mule-cloud/app/order/transport.GetCuttingBatchHandler":              {Summary: "获取裁剪批次详情", Tag: "cutting", Response: dto.CuttingBatchResponse{}},
+	"mule-cloud/app/order/transport.DeleteCuttingBatchHandler":           {Summary: "删除裁剪批次", Tag: "cutting", Response: map[string]interface{}{}},
+	"mule-cloud/app/order/transport.ClearTaskBatchesHandler":             {Summary: "清空任务批次", Tag: "cutting", Response: map[string]interface{}{}},
+	"mule-cloud/app/order/transport.PrintCuttingBatchHandler":            {Summary: "打印裁剪批次", Tag: "cutting", Response: dto.CuttingBatchResponse{}},
+	"mule-cloud/app/order/transport.BatchPrintCuttingBatchesHandler":     {Summary: "批量打印裁剪批次", Tag: "cutting", Request: dto.BatchPrintRequest{}, Bind: openapi.FromBody, Response: dto.BatchPrintResponse{}},
+	"mule-cloud/app/order/transport.ListCuttingPiecesHandler":            {Summary: "裁片监控列表", Tag: "cutting", Request: dto.CuttingPieceListRequest{}, Response: dto.CuttingPieceListResponse{}},
+	"mule-cloud/app/order/transport.GetCuttingPieceHandler":              {Summary: "获取裁片监控详情", Tag: "cutting", Response: dto.CuttingPieceResponse{}},
+	"mule-cloud/app/order/transport.UpdateCuttingPieceProgressHandler":   {Summary: "更新裁片进度", Tag: "cutting", Request: dto.CuttingPieceProgressRequest{}, Response: map[string]interface{}{}},
+	"mule-cloud/app/order/transport.GetOrderHandler":                     {Summary: "获取订单", Tag: "order", Request: dto.OrderListRequest{}, Response: dto.OrderResponse{}},
+	"mule-cloud/app/order/transport.ListOrdersHandler":                   {Summary: "订单列表（分页）", Tag: "order", Request: dto.OrderListRequest{}, Response: dto.OrderListResponse{}},
+	"mule-cloud/app/order/transport.CreateOrderHandler":                  {Summary: "创建订单", Tag: "order", Request: dto.OrderCreateRequest{}, Response: dto.OrderResponse{}},
+	"mule-cloud/app/order/transport.UpdateOrderStyleHandler":             {Summary: "更新订单款式", Tag: "order", Request: dto.OrderStyleRequest{}, Response: dto.OrderResponse{}},
+	"mule-cloud/app/order/transport.UpdateOrderProcedureHandler":         {Summary: "更新订单工序", Tag: "order", Request: dto.OrderProcedureRequest{}, Response: dto.OrderResponse{}},
+	"mule-cloud/app/order/transport.UpdateOrderStandardMinutesHandler":   {Summary: "设置订单工序标准工时", Tag: "order", Request: dto.OrderStandardMinutesRequest{}, Response: dto.OrderStandardMinutesResponse{}},
+	"mule-cloud/app/order/transport.UpdateOrderHandler":                  {Summary: "更新订单", Tag: "order", Request: dto.OrderUpdateRequest{}, Response: dto.OrderResponse{}},
+	"mule-cloud/app/order/transport.CopyOrderHandler":                    {Summary: "复制订单", Tag: "order", Request: dto.OrderCopyRequest{}, Response: dto.OrderResponse{}},
+	"mule-cloud/app/order/transport.DeleteOrderHandler":                  {Summary: "删除订单", Tag: "order", Request: dto.OrderListRequest{}, Response: map[string]string{}},
+	"mule-cloud/app/order/transport.TransitionOrderWorkflowHandler":      {Summary: "执行订单工作流状态转换", Tag: "order", Request: dto.OrderWorkflowTransitionRequest{}, Response: map[string]string{}},
+	"mule-cloud/app/order/transport.GetOrderWorkflowStateHandler":        {Summary: "获取订单工作流状态", Tag: "order", Request: dto.OrderListRequest{}, Response: dto.OrderWorkflowStateResponse{}},
+	"mule-cloud/app/order/transport.GetOrderAvailableTransitionsHandler": {Summary: "获取订单可用的状态转换", Tag: "order", Request: dto.OrderListRequest{}, Response: dto.OrderWorkflowTransitionsResponse{}},
+	"mule-cloud/app/order/transport.GetCustomerAccountHandler":           {Summary: "获取客户信用条款及账龄", Tag: "receivable", Response: dto.CustomerAccountResponse{}},
+	"mule-cloud/app/order/transport.SaveCustomerAccountHandler":          {Summary: "保存客户信用条款", Tag: "receivable", Request: dto.CustomerAccountSaveRequest{}, Response: dto.CustomerAccountResponse{}},
+	"mule-cloud/app/order/transport.CreateInvoiceHandler":                {Summary: "开票", Tag: "receivable", Request: dto.InvoiceCreateRequest{}, Response: dto.InvoiceResponse{}},
+	"mule-cloud/app/order/transport.ListInvoicesHandler":                 {Summary: "发票列表", Tag: "receivable", Request: dto.InvoiceListRequest{}, Response: dto.InvoiceListResponse{}},
+	"mule-cloud/app/order/transport.GetInvoiceHandler":                   {Summary: "获取发票详情", Tag: "receivable", Response: dto.InvoiceResponse{}},
+	"mule-cloud/app/order/transport.VoidInvoiceHandler":                  {Summary: "作废发票", Tag: "receivable", Request: dto.InvoiceVoidRequest{}, Response: dto.InvoiceResponse{}},
+	"mule-cloud/app/order/transport.CreateReceiptHandler":                {Summary: "登记收款", Tag: "receivable", Request: dto.ReceiptCreateRequest{}, Response: dto.ReceiptResponse{}},
+	"mule-cloud/app/order/transport.ListReceiptsHandler":                 {Summary: "收款单列表", Tag: "receivable", Request: dto.ReceiptListRequest{}, Response: dto.ReceiptListResponse{}},
+	"mule-cloud/app/order/transport.GetReceiptHandler":                   {Summary: "获取收款单详情", Tag: "receivable", Response: dto.ReceiptResponse{}},
+	"mule-cloud/app/order/transport.VoidReceiptHandler":                  {Summary: "作废收款单", Tag: "receivable", Request: dto.ReceiptVoidRequest{}, Response: dto.ReceiptResponse{}},
+	"mule-cloud/app/order/transport.AgingReportHandler":                  {Summary: "账龄分析（按客户/业务员）", Tag: "receivable", Request: dto.AgingRequest{}, Response: dto.AgingResponse{}},
+	"mule-cloud/app/order/transport.StatementHandler":                    {Summary: "客户对账单", Tag: "receivable", Request: dto.StatementRequest{}, Response: dto.StatementResponse{}},
+	"mule-cloud/app/order/transport.StatementPDFHandler":                 {Summary: "导出客户对账单 PDF", Tag: "receivable", Request: dto.StatementRequest{}, Binary: true},
+	"mule-cloud/app/order/transport.PackCartonsHandler":                  {Summary: "装箱", Tag: "shipment", Request: dto.PackCartonsRequest{}, Response: dto.PackCartonsResponse{}},
+	"mule-cloud/app/order/transport.ListCartonsHandler":                  {Summary: "装箱列表", Tag: "shipment", Request: dto.CartonListRequest{}, Response: dto.CartonListResponse{}},
+	"mule-cloud/app/order/transport.GetCartonHandler":                    {Summary: "获取装箱详情", Tag: "shipment", Response: dto.CartonResponse{}},
+	"mule-cloud/app/order/transport.GetCartonByBarcodeHandler":           {Summary: "扫描箱唛条码", Tag: "shipment", Response: dto.CartonResponse{}},
+	"mule-cloud/app/order/transport.DeleteCartonHandler":                 {Summary: "删除装箱", Tag: "shipment", Response: map[string]interface{}{}},
+	"mule-cloud/app/order/transport.PrintCartonLabelsHandler":            {Summary: "打印箱唛", Tag: "shipment", Request: dto.CartonLabelRequest{}, Bind: openapi.FromBody, Response: dto.CartonLabelResponse{}},
+	"mule-cloud/app/order/transport.GetPackingListHandler":               {Summary: "获取订单装箱单", Tag: "shipment", Response: dto.PackingListResponse{}},
+	"mule-cloud/app/order/transport.CreateShipmentHandler":               {Summary: "创建发货单", Tag: "shipment", Request: dto.ShipmentCreateRequest{}, Response: dto.ShipmentResponse{}},
+	"mule-cloud/app/order/transport.ListShipmentsHandler":                {Summary: "发货单列表", Tag: "shipment", Request: dto.ShipmentListRequest{}, Response: dto.ShipmentListResponse{}},
+	"mule-cloud/app/order/transport.GetShipmentHandler":                  {Summary: "获取发货单详情", Tag: "shipment", Response: dto.ShipmentResponse{}},
+	"mule-cloud/app/order/transport.VoidShipmentHandler":                 {Summary: "作废发货单", Tag: "shipment", Request: dto.ShipmentVoidRequest{}, Response: dto.ShipmentResponse{}},
+	"mule-cloud/app/order/transport.GetStyleHandler":                     {Summary: "获取款式", Tag: "style", Request: dto.StyleListRequest{}, Response: dto.StyleResponse{}},
+	"mule-cloud/app/order/transport.GetAllStylesHandler":                 {Summary: "获取所有款式（不分页）", Tag: "style", Request: dto.StyleListRequest{}, Response: dto.StyleListResponse{}},
+	"mule-cloud/app/order/transport.ListStylesHandler":                   {Summary: "款式列表（分页）", Tag: "style", Request: dto.StyleListRequest{}, Response: dto.StyleListResponse{}},
+	"mule-cloud/app/order/transport.CreateStyleHandler":                  {Summary: "创建款式", Tag: "style", Request: dto.StyleCreateRequest{}, Response: dto.StyleResponse{}},
+	"mule-cloud/app/order/transport.UpdateStyleHandler":                  {Summary: "更新款式", Tag: "style", Request: dto.StyleUpdateRequest{}, Response: dto.StyleResponse{}},
+	"mule-cloud/app/order/transport.DeleteStyleHandler":                  {Summary: "删除款式", Tag: "style", Request: dto.StyleListRequest{}, Response: map[string]string{}},
+	"mule-cloud/app/order/transport.ApplyStyleTemplateHandler":           {Summary: "款式套用工序模板", Tag: "style", Request: dto.StyleApplyTemplateRequest{}, Response: dto.StyleResponse{}},
+	"mule-cloud/app/order/transport.RepriceStylesHandler":                {Summary: "款式工价重算", Tag: "style", Request: dto.StyleRepriceRequest{}, Response: dto.StyleRepriceResponse{}},
+	"mule-cloud/app/order/transport.GetWorkflowTemplatesHandler":         {Summary: "获取工作流模板", Tag: "workflow_designer", Response: dto.WorkflowTemplateResponse{}},
+}
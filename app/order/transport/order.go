@@ -112,6 +112,26 @@ func UpdateOrderProcedureHandler(svc services.IOrderService) gin.HandlerFunc {
 	}
 }
 
+// UpdateOrderStandardMinutesHandler 设置订单工序标准工时处理器
+func UpdateOrderStandardMinutesHandler(svc services.IOrderService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.OrderStandardMinutesRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.UpdateOrderStandardMinutesEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
 // UpdateOrderHandler 更新订单处理器
 func UpdateOrderHandler(svc services.IOrderService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,283 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+
+	"mule-cloud/app/order/dto"
+	"mule-cloud/app/order/endpoint"
+	"mule-cloud/app/order/services"
+	"mule-cloud/core/binding"
+	"mule-cloud/core/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ==================== 客户应收账户 Handlers ====================
+
+// GetCustomerAccountHandler 获取客户信用条款及账龄处理器
+func GetCustomerAccountHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("customer_id")
+		if id == "" {
+			response.Error(c, "客户ID不能为空")
+			return
+		}
+
+		ep := endpoint.GetCustomerAccountEndpoint(svc)
+		resp, err := ep(c.Request.Context(), id)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// SaveCustomerAccountHandler 保存客户信用条款处理器
+func SaveCustomerAccountHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CustomerAccountSaveRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.SaveCustomerAccountEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ==================== 发票 Handlers ====================
+
+// CreateInvoiceHandler 开票处理器
+func CreateInvoiceHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.InvoiceCreateRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.CreateInvoiceEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ListInvoicesHandler 发票列表处理器
+func ListInvoicesHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.InvoiceListRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.ListInvoicesEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetInvoiceHandler 获取发票详情处理器
+func GetInvoiceHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			response.Error(c, "发票ID不能为空")
+			return
+		}
+
+		ep := endpoint.GetInvoiceEndpoint(svc)
+		resp, err := ep(c.Request.Context(), id)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// VoidInvoiceHandler 作废发票处理器
+func VoidInvoiceHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.InvoiceVoidRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.VoidInvoiceEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ==================== 收款 Handlers ====================
+
+// CreateReceiptHandler 登记收款处理器
+func CreateReceiptHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReceiptCreateRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.CreateReceiptEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ListReceiptsHandler 收款单列表处理器
+func ListReceiptsHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReceiptListRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.ListReceiptsEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetReceiptHandler 获取收款单详情处理器
+func GetReceiptHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			response.Error(c, "收款单ID不能为空")
+			return
+		}
+
+		ep := endpoint.GetReceiptEndpoint(svc)
+		resp, err := ep(c.Request.Context(), id)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// VoidReceiptHandler 作废收款单处理器
+func VoidReceiptHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReceiptVoidRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.VoidReceiptEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ==================== 报表 Handlers ====================
+
+// AgingReportHandler 账龄分析处理器（按客户/业务员）
+func AgingReportHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.AgingRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.AgingReportEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// StatementHandler 客户对账单处理器
+func StatementHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.StatementRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.StatementEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// StatementPDFHandler 导出客户对账单 PDF
+func StatementPDFHandler(svc services.IReceivableService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.StatementRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.StatementPDFEndpoint(svc)
+		data, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=statement-%s.pdf", req.CustomerID))
+		c.Data(http.StatusOK, "application/pdf", data.([]byte))
+	}
+}
@@ -0,0 +1,235 @@
+package transport
+
+import (
+	"mule-cloud/app/order/dto"
+	"mule-cloud/app/order/endpoint"
+	"mule-cloud/app/order/services"
+	"mule-cloud/core/binding"
+	"mule-cloud/core/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ==================== 装箱 Handlers ====================
+
+// PackCartonsHandler 装箱处理器
+func PackCartonsHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.PackCartonsRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.PackCartonsEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ListCartonsHandler 装箱列表处理器
+func ListCartonsHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CartonListRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.ListCartonsEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetCartonHandler 获取装箱详情处理器
+func GetCartonHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			response.Error(c, "装箱ID不能为空")
+			return
+		}
+
+		ep := endpoint.GetCartonEndpoint(svc)
+		resp, err := ep(c.Request.Context(), id)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetCartonByBarcodeHandler 扫描箱唛条码处理器
+func GetCartonByBarcodeHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		barcode := c.Param("barcode")
+		if barcode == "" {
+			response.Error(c, "条码不能为空")
+			return
+		}
+
+		ep := endpoint.GetCartonByBarcodeEndpoint(svc)
+		resp, err := ep(c.Request.Context(), barcode)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// DeleteCartonHandler 删除装箱处理器
+func DeleteCartonHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			response.Error(c, "装箱ID不能为空")
+			return
+		}
+
+		ep := endpoint.DeleteCartonEndpoint(svc)
+		resp, err := ep(c.Request.Context(), id)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// PrintCartonLabelsHandler 打印箱唛处理器
+func PrintCartonLabelsHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CartonLabelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.PrintCartonLabelsEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetPackingListHandler 获取订单装箱单处理器
+func GetPackingListHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orderID := c.Param("order_id")
+		if orderID == "" {
+			response.Error(c, "订单ID不能为空")
+			return
+		}
+
+		ep := endpoint.GetPackingListEndpoint(svc)
+		resp, err := ep(c.Request.Context(), orderID)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ==================== 发货 Handlers ====================
+
+// CreateShipmentHandler 创建发货单处理器
+func CreateShipmentHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ShipmentCreateRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.CreateShipmentEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ListShipmentsHandler 发货单列表处理器
+func ListShipmentsHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ShipmentListRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.ListShipmentsEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetShipmentHandler 获取发货单详情处理器
+func GetShipmentHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			response.Error(c, "发货单ID不能为空")
+			return
+		}
+
+		ep := endpoint.GetShipmentEndpoint(svc)
+		resp, err := ep(c.Request.Context(), id)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// VoidShipmentHandler 作废发货单处理器
+func VoidShipmentHandler(svc services.IShipmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ShipmentVoidRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.VoidShipmentEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
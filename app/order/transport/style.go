@@ -130,3 +130,47 @@ func DeleteStyleHandler(svc services.IStyleService) gin.HandlerFunc {
 		response.Success(c, resp)
 	}
 }
+
+// ApplyStyleTemplateHandler 款式套用工序模板处理器
+func ApplyStyleTemplateHandler(svc services.IStyleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.StyleApplyTemplateRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+		if req.TemplateID == "" {
+			response.Error(c, "请选择工序模板")
+			return
+		}
+
+		ep := endpoint.ApplyStyleTemplateEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// RepriceStylesHandler 款式工价重算处理器
+func RepriceStylesHandler(svc services.IStyleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.StyleRepriceRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.RepriceStylesEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
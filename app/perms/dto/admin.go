@@ -48,3 +48,20 @@ type AdminListResponse struct {
 	Admins []models.Admin `json:"admins"`
 	Total  int64          `json:"total"`
 }
+
+// AdminSessionRequest 管理员会话请求
+type AdminSessionRequest struct {
+	ID        string `uri:"id" binding:"required"`
+	SessionID string `uri:"sid"` // 为空时注销全部会话
+}
+
+// AdminSessionItem 管理员登录会话
+type AdminSessionItem struct {
+	ID         string `json:"id"`
+	Device     string `json:"device"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  int64  `json:"created_at"`
+	LastSeenAt int64  `json:"last_seen_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "mule-cloud/internal/models"
+
+// CreateAPIKeyRequest 创建API密钥请求
+type CreateAPIKeyRequest struct {
+	Name        string                    `json:"name" binding:"required"`        // 名称
+	Permissions []models.APIKeyPermission `json:"permissions" binding:"required"` // 允许访问的接口
+	AllowedIPs  []string                  `json:"allowed_ips"`                    // IP白名单（IP或CIDR）
+	ExpiresAt   int64                     `json:"expires_at"`                     // 过期时间（0表示永不过期）
+	Remark      string                    `json:"remark"`                         // 备注
+}
+
+// CreateAPIKeyResponse 创建API密钥响应（密钥明文只返回这一次）
+type CreateAPIKeyResponse struct {
+	Key    string         `json:"key"`
+	APIKey *models.APIKey `json:"api_key"`
+}
+
+// UpdateAPIKeyRequest 更新API密钥请求
+type UpdateAPIKeyRequest struct {
+	Name        string                    `json:"name"`        // 名称
+	Permissions []models.APIKeyPermission `json:"permissions"` // 允许访问的接口
+	AllowedIPs  *[]string                 `json:"allowed_ips"` // IP白名单（传空数组表示不限制）
+	ExpiresAt   *int64                    `json:"expires_at"`  // 过期时间（0表示永不过期）
+	Status      *int                      `json:"status"`      // 状态：1-启用 0-禁用
+	Remark      *string                   `json:"remark"`      // 备注
+}
+
+// ListAPIKeyRequest 查询API密钥列表请求
+type ListAPIKeyRequest struct {
+	Name     string `json:"name" form:"name"`           // 名称（模糊查询）
+	Status   *int   `json:"status" form:"status"`       // 状态
+	Page     int    `json:"page" form:"page"`           // 页码
+	PageSize int    `json:"page_size" form:"page_size"` // 每页数量
+}
@@ -0,0 +1,56 @@
+package dto
+
+import "mule-cloud/core/casbin"
+
+// SimulatePermissionRequest 权限模拟请求（user_id 和 role_id 二选一）
+type SimulatePermissionRequest struct {
+	UserID     string `json:"user_id" form:"user_id"`                  // 管理员ID
+	RoleID     string `json:"role_id" form:"role_id"`                  // 角色ID（模拟单个角色）
+	TenantCode string `json:"tenant_code" form:"tenant_code"`          // 租户代码（为空使用当前租户，system 表示系统）
+	Path       string `json:"path" form:"path" binding:"required"`     // 请求路径，如 /admin/perms/roles/123
+	Method     string `json:"method" form:"method" binding:"required"` // 请求方法
+}
+
+// TenantEntitlement 租户菜单授权（超管分配给租户的菜单，是租户内角色权限的上限）
+type TenantEntitlement struct {
+	TenantID     string   `json:"tenant_id"`
+	TenantCode   string   `json:"tenant_code"`
+	MatchedMenus []string `json:"matched_menus"` // 路径命中的菜单
+	Entitled     bool     `json:"entitled"`      // 命中的菜单是否分配给了租户
+}
+
+// SimulatePermissionResponse 权限模拟结果
+type SimulatePermissionResponse struct {
+	casbin.Explanation
+	Method        string             `json:"method"`
+	Path          string             `json:"path"`
+	Roles         []string           `json:"roles"`                    // 用户的角色ID
+	InactiveRoles []string           `json:"inactive_roles,omitempty"` // 不存在、已禁用或已删除的角色（不参与计算）
+	Bypass        string             `json:"bypass,omitempty"`         // 直接放行的超管角色（super / tenant_admin）
+	Tenant        *TenantEntitlement `json:"tenant,omitempty"`         // 租户菜单授权（系统用户为空）
+	Decision      bool               `json:"decision"`                 // 最终结果
+	Reason        string             `json:"reason"`                   // 判断依据
+}
+
+// PermissionMatrixRequest 角色权限矩阵请求
+type PermissionMatrixRequest struct {
+	RoleID     string `json:"role_id" form:"role_id" binding:"required"` // 角色ID
+	TenantCode string `json:"tenant_code" form:"tenant_code"`            // 租户代码（为空使用当前租户）
+}
+
+// PermissionMatrixRow 一个菜单的权限
+type PermissionMatrixRow struct {
+	MenuName string          `json:"menu_name"`
+	Title    string          `json:"title"`
+	Path     string          `json:"path"`
+	Entitled bool            `json:"entitled"` // 菜单是否分配给了租户（系统角色恒为 true）
+	Actions  map[string]bool `json:"actions"`  // 动作 -> 是否允许
+}
+
+// PermissionMatrixResponse 角色的有效权限矩阵
+type PermissionMatrixResponse struct {
+	RoleID   string                `json:"role_id"`
+	RoleName string                `json:"role_name"`
+	Subject  string                `json:"subject"`
+	Rows     []PermissionMatrixRow `json:"rows"`
+}
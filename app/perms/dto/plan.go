@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"mule-cloud/core/quota"
+	"mule-cloud/internal/models"
+)
+
+// PlanRequest 创建/更新套餐请求
+type PlanRequest struct {
+	Code        string            `json:"code" binding:"required"` // 套餐代码（唯一）
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Limits      models.PlanLimits `json:"limits"`   // 配额（0 表示不限制）
+	Features    []string          `json:"features"` // 开通的功能：workflow_designer / quality / outsourcing
+	Status      *int              `json:"status"`   // 状态：1-启用 0-停用，默认启用
+}
+
+// TenantSubscriptionRequest 设置租户订阅请求（plan_code 为空表示取消订阅，不再限制）
+type TenantSubscriptionRequest struct {
+	PlanCode  string `json:"plan_code"`
+	ExpiresAt int64  `json:"expires_at"` // 到期时间，0 表示长期有效
+	GraceDays int    `json:"grace_days"` // 到期后的宽限天数
+}
+
+// TenantUsageResponse 租户套餐用量
+type TenantUsageResponse struct {
+	TenantID     string                    `json:"tenant_id"`
+	TenantCode   string                    `json:"tenant_code"`
+	TenantName   string                    `json:"tenant_name"`
+	Subscription models.TenantSubscription `json:"subscription"`
+	GraceUntil   int64                     `json:"grace_until"` // 宽限期结束时间
+	State        string                    `json:"state"`       // none / active / grace / expired
+	Plan         *models.Plan              `json:"plan,omitempty"`
+	Usage        *quota.Usage              `json:"usage"`
+}
@@ -2,21 +2,25 @@ package dto
 
 // CreateRoleRequest 创建角色请求
 type CreateRoleRequest struct {
-	TenantID        string              `json:"tenant_id" binding"required"` // 租户ID
-	Name            string              `json:"name" binding"required"`      // 角色名称
-	Code            string              `json:"code" binding"required"`      // 角色代码
-	Description     string              `json:"description"`                 // 角色描述
-	Menus           []string            `json:"menus"`                       // 菜单名称数组（menu.name）
-	MenuPermissions map[string][]string `json:"menu_permissions,omitempty"`  // 菜单权限映射: {"admin": ["read", "create"], "role": ["read"]}
+	TenantID         string              `json:"tenant_id" binding"required"` // 租户ID
+	Name             string              `json:"name" binding"required"`      // 角色名称
+	Code             string              `json:"code" binding"required"`      // 角色代码
+	Description      string              `json:"description"`                 // 角色描述
+	Menus            []string            `json:"menus"`                       // 菜单名称数组（menu.name）
+	MenuPermissions  map[string][]string `json:"menu_permissions,omitempty"`  // 菜单权限映射: {"admin": ["read", "create"], "role": ["read"]}
+	DataScope        string              `json:"data_scope"`                  // 数据范围：self/team/workshop/dept/all，为空表示全部
+	FieldPermissions map[string]string   `json:"field_permissions,omitempty"` // 敏感字段权限: {"salary": "write", "bank_account": "read"}
 }
 
 // UpdateRoleRequest 更新角色请求
 type UpdateRoleRequest struct {
-	Name            string              `json:"name"`                       // 角色名称
-	Description     string              `json:"description"`                // 角色描述
-	Menus           []string            `json:"menus"`                      // 菜单名称数组（menu.name）
-	MenuPermissions map[string][]string `json:"menu_permissions,omitempty"` // 菜单权限映射
-	Status          *int                `json:"status"`                     // 状态
+	Name             string              `json:"name"`                        // 角色名称
+	Description      string              `json:"description"`                 // 角色描述
+	Menus            []string            `json:"menus"`                       // 菜单名称数组（menu.name）
+	MenuPermissions  map[string][]string `json:"menu_permissions,omitempty"`  // 菜单权限映射
+	DataScope        *string             `json:"data_scope"`                  // 数据范围：self/team/workshop/dept/all
+	FieldPermissions map[string]string   `json:"field_permissions,omitempty"` // 敏感字段权限（传空对象表示清除）
+	Status           *int                `json:"status"`                      // 状态
 }
 
 // ListRoleRequest 查询角色列表请求
@@ -13,12 +13,13 @@ type TenantListRequest struct {
 
 // TenantCreateRequest 创建租户请求
 type TenantCreateRequest struct {
-	Code    string `json:"code" binding"required"`
-	Name    string `json:"name" binding"required"`
-	Contact string `json:"contact"`
-	Phone   string `json:"phone"`
-	Email   string `json:"email"`
-	Status  int    `json:"status"`
+	Code       string `json:"code" binding"required"`
+	Name       string `json:"name" binding"required"`
+	Contact    string `json:"contact"`
+	Phone      string `json:"phone"`
+	Email      string `json:"email"`
+	Status     int    `json:"status"`
+	Require2FA bool   `json:"require_2fa"` // 是否强制租户管理员启用两步验证
 	// 租户管理员信息（可选）
 	AdminPhone    string `json:"admin_phone"`    // 管理员手机号
 	AdminPassword string `json:"admin_password"` // 管理员密码
@@ -27,13 +28,35 @@ type TenantCreateRequest struct {
 
 // TenantUpdateRequest 更新租户请求
 type TenantUpdateRequest struct {
-	ID      string `uri:"id"`
-	Code    string `json:"code"`
-	Name    string `json:"name"`
-	Contact string `json:"contact"`
-	Phone   string `json:"phone"`
-	Email   string `json:"email"`
-	Status  *int   `json:"status"`
+	ID         string `uri:"id"`
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Contact    string `json:"contact"`
+	Phone      string `json:"phone"`
+	Email      string `json:"email"`
+	Status     *int   `json:"status"`
+	Require2FA *bool  `json:"require_2fa"`
+}
+
+// TenantSSORequest 配置租户单点登录请求（client_secret 为空时保留原值）
+type TenantSSORequest struct {
+	Enabled       bool                    `json:"enabled"`
+	Issuer        string                  `json:"issuer"`
+	ClientID      string                  `json:"client_id"`
+	ClientSecret  string                  `json:"client_secret"`
+	RedirectURL   string                  `json:"redirect_url"`
+	Scopes        []string                `json:"scopes"`
+	Enforce       bool                    `json:"enforce"`
+	AutoProvision bool                    `json:"auto_provision"`
+	ClaimMapping  models.SSOClaimMapping  `json:"claim_mapping"`
+	RoleMappings  []models.SSORoleMapping `json:"role_mappings"`
+	DefaultRoles  []string                `json:"default_roles"`
+}
+
+// TenantSSOResponse 租户单点登录配置（不返回客户端密钥）
+type TenantSSOResponse struct {
+	models.TenantSSO
+	HasClientSecret bool `json:"has_client_secret"`
 }
 
 // AssignTenantMenusRequest 分配菜单权限给租户请求（超管使用）
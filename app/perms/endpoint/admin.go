@@ -82,3 +82,58 @@ func DeleteAdminEndpoint(svc services.IAdminService) endpoint.Endpoint {
 		return map[string]string{"message": "删除成功"}, nil
 	}
 }
+
+// ListAdminSessionsEndpoint 获取管理员登录会话端点
+func ListAdminSessionsEndpoint(svc services.IAdminService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.AdminSessionRequest)
+		sessions, err := svc.ListSessions(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"sessions": sessions, "total": len(sessions)}, nil
+	}
+}
+
+// RevokeAdminSessionsEndpoint 注销管理员会话端点
+func RevokeAdminSessionsEndpoint(svc services.IAdminService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.AdminSessionRequest)
+		count, err := svc.RevokeSessions(ctx, req.ID, req.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"message": "会话已注销", "revoked": count}, nil
+	}
+}
+
+// GetAdminLockStatusEndpoint 获取管理员登录锁定状态端点
+func GetAdminLockStatusEndpoint(svc services.IAdminService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.AdminListRequest)
+		return svc.GetLockStatus(ctx, req.ID)
+	}
+}
+
+// UnlockAdminEndpoint 解锁管理员端点
+func UnlockAdminEndpoint(svc services.IAdminService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.AdminListRequest)
+		locked, err := svc.Unlock(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"message": "解锁成功", "was_locked": locked}, nil
+	}
+}
+
+// ResetAdminTwoFactorEndpoint 重置管理员两步验证端点
+func ResetAdminTwoFactorEndpoint(svc services.IAdminService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.AdminListRequest)
+		if err := svc.ResetTwoFactor(ctx, req.ID); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"message": "两步验证已重置"}, nil
+	}
+}
@@ -3,13 +3,17 @@ package services
 import (
 	"context"
 	"mule-cloud/app/perms/dto"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/password"
+	"mule-cloud/core/security"
+	"mule-cloud/core/session"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
-	"mule-cloud/util"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.uber.org/zap"
 )
 
 // IAdminService 管理员服务接口
@@ -20,6 +24,11 @@ type IAdminService interface {
 	Create(ctx context.Context, req dto.AdminCreateRequest) (*models.Admin, error)
 	Update(ctx context.Context, req dto.AdminUpdateRequest) (*models.Admin, error)
 	Delete(ctx context.Context, id string) error
+	ListSessions(ctx context.Context, id string) ([]dto.AdminSessionItem, error)
+	RevokeSessions(ctx context.Context, id, sessionID string) (int, error)
+	GetLockStatus(ctx context.Context, id string) (*security.LockStatus, error)
+	Unlock(ctx context.Context, id string) (bool, error)
+	ResetTwoFactor(ctx context.Context, id string) error
 }
 
 // AdminService 管理员服务实现
@@ -131,11 +140,17 @@ func (s *AdminService) GetAll(ctx context.Context, req dto.AdminListRequest) ([]
 // Create 创建管理员
 func (s *AdminService) Create(ctx context.Context, req dto.AdminCreateRequest) (*models.Admin, error) {
 	now := time.Now().Unix()
-	password := util.ToolsUtil.Md5(req.Password + "mule-zdm")
+	if err := password.Validate(req.Password); err != nil {
+		return nil, err
+	}
+	hash, err := password.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
 
 	admin := &models.Admin{
 		Phone:     req.Phone,
-		Password:  password,
+		Password:  hash,
 		Nickname:  req.Nickname,
 		Email:     req.Email,
 		Roles:     req.Roles, // 使用请求中的角色
@@ -146,7 +161,7 @@ func (s *AdminService) Create(ctx context.Context, req dto.AdminCreateRequest) (
 		UpdatedAt: now,
 	}
 
-	err := s.repo.Create(ctx, admin)
+	err = s.repo.Create(ctx, admin)
 	if err != nil {
 		return nil, err
 	}
@@ -164,7 +179,26 @@ func (s *AdminService) Update(ctx context.Context, req dto.AdminUpdateRequest) (
 		update["phone"] = req.Phone
 	}
 	if req.Password != "" {
-		update["password"] = util.ToolsUtil.Md5(req.Password + "mule-zdm")
+		// 管理员重置密码同样校验密码策略和历史密码（与修改密码一致）
+		admin, err := s.repo.Get(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+		if admin == nil {
+			return nil, repository.ErrNotFound
+		}
+		if err := password.Validate(req.Password); err != nil {
+			return nil, err
+		}
+		if err := password.CheckReuse(req.Password, admin.Password, admin.PasswordHistory); err != nil {
+			return nil, err
+		}
+		hash, err := password.Hash(req.Password)
+		if err != nil {
+			return nil, err
+		}
+		update["password"] = hash
+		update["password_history"] = password.PushHistory(admin.PasswordHistory, admin.Password)
 	}
 	if req.Nickname != "" {
 		update["nickname"] = req.Nickname
@@ -188,13 +222,139 @@ func (s *AdminService) Update(ctx context.Context, req dto.AdminUpdateRequest) (
 		return nil, err
 	}
 
+	// 禁用或重置密码后踢下线
+	if (req.Status != nil && *req.Status != 1) || req.Password != "" {
+		s.kickOut(ctx, req.ID)
+	}
+
 	// 返回更新后的数据
 	return s.repo.Get(ctx, req.ID)
 }
 
 // Delete 删除管理员
 func (s *AdminService) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.kickOut(ctx, id)
+	return nil
+}
+
+// ListSessions 获取管理员的登录会话
+func (s *AdminService) ListSessions(ctx context.Context, id string) ([]dto.AdminSessionItem, error) {
+	sessions, err := session.List(ctx, tenantCtx.GetTenantCode(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]dto.AdminSessionItem, 0, len(sessions))
+	for _, sess := range sessions {
+		items = append(items, dto.AdminSessionItem{
+			ID:         sess.ID,
+			Device:     sess.Device,
+			UserAgent:  sess.UserAgent,
+			IP:         sess.IP,
+			CreatedAt:  sess.CreatedAt,
+			LastSeenAt: sess.LastSeenAt,
+			ExpiresAt:  sess.ExpiresAt,
+		})
+	}
+	return items, nil
+}
+
+// RevokeSessions 注销管理员的指定会话，sessionID 为空时注销全部会话
+func (s *AdminService) RevokeSessions(ctx context.Context, id, sessionID string) (int, error) {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	if sessionID == "" {
+		return session.RevokeUser(ctx, tenantCode, id)
+	}
+
+	sessions, err := session.List(ctx, tenantCode, id)
+	if err != nil {
+		return 0, err
+	}
+	for _, sess := range sessions {
+		if sess.ID == sessionID {
+			return 1, session.Revoke(ctx, sessionID)
+		}
+	}
+	return 0, session.ErrNotFound
+}
+
+// GetLockStatus 查询管理员登录锁定状态
+func (s *AdminService) GetLockStatus(ctx context.Context, id string) (*security.LockStatus, error) {
+	admin, err := s.getExisting(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return security.AccountLockStatus(ctx, tenantCtx.GetTenantCode(ctx), admin.Phone)
+}
+
+// Unlock 解锁因登录失败次数过多被锁定的管理员，返回解锁前是否处于锁定状态
+func (s *AdminService) Unlock(ctx context.Context, id string) (bool, error) {
+	admin, err := s.getExisting(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	locked, err := security.UnlockAccount(ctx, tenantCode, admin.Phone)
+	if err != nil {
+		return false, err
+	}
+	security.RecordEvent(tenantCode, security.Event{
+		Type:     security.EventAccountUnlocked,
+		UserID:   admin.ID,
+		Username: admin.Phone,
+		Path:     "/perms/admins/" + admin.ID + "/unlock",
+		Detail:   "操作人: " + tenantCtx.GetUsername(ctx),
+	})
+	return locked, nil
+}
+
+// ResetTwoFactor 重置管理员的两步验证（丢失验证器设备时使用），同时注销其全部会话
+// 租户强制两步验证时，管理员下次登录会被要求重新绑定
+func (s *AdminService) ResetTwoFactor(ctx context.Context, id string) error {
+	admin, err := s.getExisting(ctx, id)
+	if err != nil {
+		return err
+	}
+	update := bson.M{
+		"two_factor": models.TwoFactor{},
+		"updated_by": tenantCtx.GetUsername(ctx),
+		"updated_at": time.Now().Unix(),
+	}
+	if err := s.repo.Update(ctx, id, update); err != nil {
+		return err
+	}
+	s.kickOut(ctx, id)
+
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	security.RecordEvent(tenantCode, security.Event{
+		Type:     security.EventTwoFactorReset,
+		UserID:   admin.ID,
+		Username: admin.Phone,
+		Path:     "/perms/admins/" + admin.ID + "/2fa/reset",
+		Detail:   "操作人: " + tenantCtx.GetUsername(ctx),
+	})
+	return nil
+}
+
+// getExisting 获取管理员，不存在时返回 ErrNotFound
+func (s *AdminService) getExisting(ctx context.Context, id string) (*models.Admin, error) {
+	admin, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if admin == nil {
+		return nil, repository.ErrNotFound
+	}
+	return admin, nil
+}
+
+// kickOut 注销管理员的全部会话，失败只记录日志（Redis 未启用时令牌只能等待自然过期）
+func (s *AdminService) kickOut(ctx context.Context, id string) {
+	if _, err := session.RevokeUser(ctx, tenantCtx.GetTenantCode(ctx), id); err != nil {
+		log.Ctx(ctx).Warn("注销管理员会话失败", zap.String("admin_id", id), zap.Error(err))
+	}
 }
 
 // AssignRoles 分配角色给管理员
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mule-cloud/app/perms/dto"
+	"mule-cloud/core/config"
+	"mule-cloud/core/password"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeAdminRepo 只实现重置密码用到的方法
+type fakeAdminRepo struct {
+	repository.AdminRepository
+	admin   *models.Admin
+	updates []bson.M
+}
+
+func (r *fakeAdminRepo) Get(ctx context.Context, id string) (*models.Admin, error) {
+	return r.admin, nil
+}
+
+func (r *fakeAdminRepo) Update(ctx context.Context, id string, update bson.M) error {
+	r.updates = append(r.updates, update)
+	return nil
+}
+
+// TestAdminUpdatePasswordPolicy 测试管理员重置密码同样校验密码策略和历史密码
+func TestAdminUpdatePasswordPolicy(t *testing.T) {
+	password.Init(&config.PasswordConfig{
+		Argon2Memory: 1024, Argon2Time: 1, Argon2Threads: 1,
+		MinLength: 8, RequireLower: true, RequireDigit: true, HistorySize: 3,
+	})
+	current, _ := password.Hash("pass0002")
+	previous, _ := password.Hash("pass0001")
+	repo := &fakeAdminRepo{admin: &models.Admin{ID: "a1", Password: current, PasswordHistory: []string{previous}}}
+	svc := &AdminService{repo: repo}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		pwd     string
+		wantErr error
+	}{
+		{"too weak", "abc", nil},
+		{"same as current", "pass0002", password.ErrPasswordReused},
+		{"in history", "pass0001", password.ErrPasswordReused},
+	}
+	for _, tt := range tests {
+		_, err := svc.Update(ctx, dto.AdminUpdateRequest{ID: "a1", Password: tt.pwd})
+		if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+			t.Errorf("%s: Update() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if len(repo.updates) != 0 {
+		t.Fatalf("rejected passwords must not be saved: %v", repo.updates)
+	}
+
+	if _, err := svc.Update(ctx, dto.AdminUpdateRequest{ID: "a1", Password: "pass0003"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	update := repo.updates[0]
+	if ok, _ := password.Verify("pass0003", update["password"].(string)); !ok {
+		t.Error("new password hash not saved")
+	}
+	if history := update["password_history"].([]string); len(history) != 2 || history[0] != current {
+		t.Errorf("password_history = %v, want current hash pushed first", history)
+	}
+
+	if _, err := svc.Create(ctx, dto.AdminCreateRequest{Phone: "13800000000", Password: "weak"}); err == nil {
+		t.Error("Create() accepted a password that violates the policy")
+	}
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mule-cloud/app/perms/dto"
+	"mule-cloud/core/apikey"
+	"mule-cloud/core/casbin"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// APIKeyService API密钥管理（密钥属于当前上下文的租户，超管切换租户后管理的是目标租户的密钥）
+type APIKeyService struct {
+	apiKeyRepo *repository.APIKeyRepository
+	tenantRepo repository.TenantRepository
+	roleRepo   repository.RoleRepository
+	menuRepo   *repository.MenuRepository
+}
+
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: repository.NewAPIKeyRepository(),
+		tenantRepo: repository.NewTenantRepository(),
+		roleRepo:   repository.NewRoleRepository(),
+		menuRepo:   repository.NewMenuRepository(),
+	}
+}
+
+// Create 创建API密钥，返回的明文密钥之后无法再次查看
+func (s *APIKeyService) Create(ctx context.Context, req *dto.CreateAPIKeyRequest, createdBy string) (*dto.CreateAPIKeyResponse, error) {
+	if err := validateAPIKey(req.Permissions, req.AllowedIPs, req.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	tenantCode := apiKeyTenantCode(ctx)
+	tenant, err := s.keyTenant(ctx, tenantCode)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := ""
+	if tenant != nil {
+		tenantID = tenant.ID
+	}
+	if err := s.checkGrantable(ctx, tenant, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	apiKey := &models.APIKey{
+		Name:        req.Name,
+		Prefix:      prefix,
+		KeyHash:     hash,
+		TenantID:    tenantID,
+		TenantCode:  tenantCode,
+		Permissions: req.Permissions,
+		AllowedIPs:  trimAll(req.AllowedIPs),
+		ExpiresAt:   req.ExpiresAt,
+		Status:      1,
+		Remark:      req.Remark,
+		IsDeleted:   0,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("创建API密钥失败: %w", err)
+	}
+
+	return &dto.CreateAPIKeyResponse{Key: key, APIKey: apiKey}, nil
+}
+
+// GetByID 获取API密钥
+func (s *APIKeyService) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	apiKey, err := s.apiKeyRepo.Get(ctx, apiKeyTenantCode(ctx), id)
+	if err != nil {
+		return nil, fmt.Errorf("获取API密钥失败: %w", err)
+	}
+	if apiKey == nil {
+		return nil, fmt.Errorf("API密钥不存在")
+	}
+	return apiKey, nil
+}
+
+// List 查询当前租户的API密钥（分页）
+func (s *APIKeyService) List(ctx context.Context, req *dto.ListAPIKeyRequest) ([]*models.APIKey, int64, error) {
+	filter := bson.M{
+		"tenant_code": apiKeyTenantCode(ctx),
+		"is_deleted":  0,
+	}
+	if req.Name != "" {
+		filter["name"] = bson.M{"$regex": req.Name, "$options": "i"}
+	}
+	if req.Status != nil {
+		filter["status"] = *req.Status
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	keys, total, err := s.apiKeyRepo.List(ctx, filter, int64(page), int64(pageSize))
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询API密钥列表失败: %w", err)
+	}
+	return keys, total, nil
+}
+
+// Update 更新API密钥（网关缓存最多延迟30秒生效）
+func (s *APIKeyService) Update(ctx context.Context, id string, req *dto.UpdateAPIKeyRequest, updatedBy string) error {
+	apiKey, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"updated_by": updatedBy,
+		"updated_at": time.Now().Unix(),
+	}
+	if req.Name != "" {
+		update["name"] = req.Name
+	}
+	permissions := apiKey.Permissions
+	if req.Permissions != nil {
+		permissions = req.Permissions
+		update["permissions"] = req.Permissions
+	}
+	allowedIPs := apiKey.AllowedIPs
+	if req.AllowedIPs != nil {
+		allowedIPs = trimAll(*req.AllowedIPs)
+		update["allowed_ips"] = allowedIPs
+	}
+	expiresAt := int64(0)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+		update["expires_at"] = expiresAt
+	}
+	if err := validateAPIKey(permissions, allowedIPs, expiresAt); err != nil {
+		return err
+	}
+	if req.Permissions != nil {
+		tenant, err := s.keyTenant(ctx, apiKey.TenantCode)
+		if err != nil {
+			return err
+		}
+		if err := s.checkGrantable(ctx, tenant, req.Permissions); err != nil {
+			return err
+		}
+	}
+	if req.Status != nil {
+		update["status"] = *req.Status
+	}
+	if req.Remark != nil {
+		update["remark"] = *req.Remark
+	}
+
+	if err := s.apiKeyRepo.Update(ctx, apiKey.TenantCode, id, update); err != nil {
+		return fmt.Errorf("更新API密钥失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 吊销API密钥（软删除，网关缓存最多延迟30秒生效）
+func (s *APIKeyService) Delete(ctx context.Context, id string, deletedBy string) error {
+	err := s.apiKeyRepo.Update(ctx, apiKeyTenantCode(ctx), id, bson.M{
+		"is_deleted": 1,
+		"status":     0,
+		"updated_by": deletedBy,
+		"updated_at": time.Now().Unix(),
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("API密钥不存在")
+	}
+	if err != nil {
+		return fmt.Errorf("吊销API密钥失败: %w", err)
+	}
+	return nil
+}
+
+// keyTenant 密钥所属的租户（系统密钥返回 nil）
+func (s *APIKeyService) keyTenant(ctx context.Context, tenantCode string) (*models.Tenant, error) {
+	if tenantCode == "system" {
+		return nil, nil
+	}
+	tenant, err := s.tenantRepo.GetByCode(ctx, tenantCode)
+	if err != nil {
+		return nil, fmt.Errorf("获取租户失败: %w", err)
+	}
+	if tenant == nil {
+		return nil, fmt.Errorf("租户不存在: %s", tenantCode)
+	}
+	return tenant, nil
+}
+
+// checkGrantable 密钥权限不能超出调用者的有效权限（角色的菜单权限，租户内再限定为租户拥有的菜单）；
+// 系统超管、租户超管与网关鉴权一致直接放行
+func (s *APIKeyService) checkGrantable(ctx context.Context, tenant *models.Tenant, perms []models.APIKeyPermission) error {
+	tenantID := ""
+	if tenant != nil {
+		tenantID = tenant.ID
+	}
+	roleIDs := tenantCtx.GetRoles(ctx)
+	if casbin.BypassRole(tenantID, roleIDs) != "" {
+		return nil
+	}
+
+	roles, err := s.roleRepo.GetRolesByIDs(ctx, roleIDs)
+	if err != nil {
+		return fmt.Errorf("获取角色失败: %w", err)
+	}
+	menus, err := s.menuRepo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("获取菜单失败: %w", err)
+	}
+	policies := rolePolicies(tenantID, roles, menus)
+	if tenant != nil {
+		policies = entitledPolicies(tenant, menus, policies)
+	}
+	return apikey.WithinPolicies(perms, policies)
+}
+
+// entitledPolicies 只保留租户拥有的菜单对应的策略
+func entitledPolicies(tenant *models.Tenant, menus []*models.Menu, policies []casbin.Policy) []casbin.Policy {
+	names := make(map[string]bool, len(tenant.Menus))
+	for _, name := range tenant.Menus {
+		names[name] = true
+	}
+	owned := make(map[string]bool, len(tenant.Menus))
+	for _, menu := range menus {
+		if names[menu.Name] && menu.Path != "" {
+			owned[menu.Path] = true
+		}
+	}
+	result := make([]casbin.Policy, 0, len(policies))
+	for _, p := range policies {
+		if owned[p.Object] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// apiKeyTenantCode 当前上下文的租户代码（系统用户统一记为 system）
+func apiKeyTenantCode(ctx context.Context) string {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	if tenantCode == "" {
+		return "system"
+	}
+	return tenantCode
+}
+
+// validateAPIKey 校验权限、IP白名单和过期时间
+func validateAPIKey(permissions []models.APIKeyPermission, allowedIPs []string, expiresAt int64) error {
+	if err := apikey.ValidatePermissions(permissions); err != nil {
+		return err
+	}
+	if err := apikey.ValidateAllowedIPs(trimAll(allowedIPs)); err != nil {
+		return err
+	}
+	if expiresAt != 0 && expiresAt <= time.Now().Unix() {
+		return fmt.Errorf("过期时间必须晚于当前时间")
+	}
+	return nil
+}
+
+func trimAll(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
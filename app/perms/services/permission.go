@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mule-cloud/app/perms/dto"
+	"mule-cloud/core/casbin"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+	"sort"
+	"strings"
+)
+
+// ErrCrossTenantSimulate 非系统超管不能模拟其他租户
+var ErrCrossTenantSimulate = errors.New("只有系统超管可以模拟其他租户的权限")
+
+// defaultMenuActions 菜单未声明可用权限时的默认动作
+var defaultMenuActions = []string{"read", "create", "update", "delete"}
+
+// PermissionService 权限模拟：按角色的菜单权限生成 Casbin 策略（与分配菜单时同步到 Casbin 的策略一致），
+// 用鉴权中间件相同的解析规则和匹配模型解释请求为什么被允许或拒绝
+type PermissionService struct {
+	tenantRepo repository.TenantRepository
+	adminRepo  repository.AdminRepository
+	roleRepo   repository.RoleRepository
+	menuRepo   *repository.MenuRepository
+}
+
+func NewPermissionService() *PermissionService {
+	return &PermissionService{
+		tenantRepo: repository.NewTenantRepository(),
+		adminRepo:  repository.NewAdminRepository(),
+		roleRepo:   repository.NewRoleRepository(),
+		menuRepo:   repository.NewMenuRepository(),
+	}
+}
+
+// Simulate 模拟管理员或角色访问接口
+func (s *PermissionService) Simulate(ctx context.Context, req *dto.SimulatePermissionRequest) (*dto.SimulatePermissionResponse, error) {
+	if (req.UserID == "") == (req.RoleID == "") {
+		return nil, fmt.Errorf("user_id 和 role_id 必须且只能传一个")
+	}
+	tenant, dbCtx, err := s.resolveTenant(ctx, req.TenantCode)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := ""
+	if tenant != nil {
+		tenantID = tenant.ID
+	}
+
+	// 主体和角色
+	var sub string
+	var roleIDs []string
+	if req.UserID != "" {
+		admin, err := s.adminRepo.Get(dbCtx, req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("获取管理员失败: %w", err)
+		}
+		if admin == nil {
+			return nil, fmt.Errorf("管理员不存在")
+		}
+		sub = casbin.UserSubject(tenantID, req.UserID)
+		roleIDs = admin.Roles
+	} else {
+		sub = casbin.RoleSubject(tenantID, req.RoleID)
+		roleIDs = []string{req.RoleID}
+	}
+
+	resource, action := casbin.ParseResourceAndAction(req.Path, strings.ToUpper(req.Method))
+	result := &dto.SimulatePermissionResponse{
+		Method: strings.ToUpper(req.Method),
+		Path:   req.Path,
+		Roles:  roleIDs,
+		Bypass: casbin.BypassRole(tenantID, roleIDs),
+	}
+	if result.Roles == nil {
+		result.Roles = []string{}
+	}
+
+	roles, err := s.roleRepo.GetRolesByIDs(dbCtx, roleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取角色失败: %w", err)
+	}
+	result.InactiveRoles = inactiveRoles(roleIDs, roles)
+
+	menus, err := s.menuRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取菜单失败: %w", err)
+	}
+
+	var groupings [][2]string
+	if req.UserID != "" {
+		for _, role := range roles {
+			groupings = append(groupings, [2]string{sub, casbin.RoleSubject(tenantID, role.ID)})
+		}
+	}
+	e, err := casbin.NewMemoryEnforcer(rolePolicies(tenantID, roles, menus), groupings)
+	if err != nil {
+		return nil, err
+	}
+	exp, err := casbin.Explain(e, sub, resource, action)
+	if err != nil {
+		return nil, err
+	}
+	result.Explanation = *exp
+
+	if tenant != nil {
+		result.Tenant = tenantEntitlement(tenant, resource, menus)
+	}
+
+	switch {
+	case result.Bypass != "":
+		result.Decision = true
+		result.Reason = fmt.Sprintf("超管角色 %s 直接放行", result.Bypass)
+	case len(roles) == 0:
+		result.Reason = "没有可用的角色"
+	case !exp.Allowed:
+		result.Reason = fmt.Sprintf("没有策略允许 %s (%s)：检查角色的菜单权限是否包含该动作、菜单路径能否匹配该资源", resource, action)
+	case result.Tenant != nil && !result.Tenant.Entitled:
+		result.Reason = "角色策略允许，但对应菜单未分配给租户（超出租户权限范围）"
+	default:
+		result.Decision = true
+		result.Reason = "命中角色策略"
+	}
+	return result, nil
+}
+
+// Matrix 角色对每个菜单、每个动作的有效权限
+func (s *PermissionService) Matrix(ctx context.Context, req *dto.PermissionMatrixRequest) (*dto.PermissionMatrixResponse, error) {
+	tenant, dbCtx, err := s.resolveTenant(ctx, req.TenantCode)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := ""
+	if tenant != nil {
+		tenantID = tenant.ID
+	}
+
+	role, err := s.roleRepo.Get(dbCtx, req.RoleID)
+	if err != nil {
+		return nil, fmt.Errorf("获取角色失败: %w", err)
+	}
+	if role == nil {
+		return nil, fmt.Errorf("角色不存在")
+	}
+	menus, err := s.menuRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取菜单失败: %w", err)
+	}
+
+	sub := casbin.RoleSubject(tenantID, role.ID)
+	e, err := casbin.NewMemoryEnforcer(rolePolicies(tenantID, []*models.Role{role}, menus), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entitled := make(map[string]bool)
+	if tenant != nil {
+		for _, name := range tenant.Menus {
+			entitled[name] = true
+		}
+	}
+
+	result := &dto.PermissionMatrixResponse{
+		RoleID:   role.ID,
+		RoleName: role.Name,
+		Subject:  sub,
+		Rows:     []dto.PermissionMatrixRow{},
+	}
+	for _, menu := range menus {
+		if menu.Path == "" || menu.MenuType == "dir" {
+			continue
+		}
+		// 系统角色列出全部菜单，租户角色列出租户拥有的菜单和角色配置过的菜单
+		_, configured := role.MenuPermissions[menu.Name]
+		if tenant != nil && !entitled[menu.Name] && !configured {
+			continue
+		}
+
+		row := dto.PermissionMatrixRow{
+			MenuName: menu.Name,
+			Title:    menu.Title,
+			Path:     menu.Path,
+			Entitled: tenant == nil || entitled[menu.Name],
+			Actions:  make(map[string]bool),
+		}
+		for _, act := range menuActions(menu, role.MenuPermissions[menu.Name]) {
+			allowed, err := e.Enforce(sub, menu.Path, act)
+			if err != nil {
+				return nil, err
+			}
+			row.Actions[act] = allowed && row.Entitled
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
+
+// resolveTenant 确定模拟的租户（为空使用当前租户），返回租户（系统为 nil）和访问该租户数据库的 context
+func (s *PermissionService) resolveTenant(ctx context.Context, tenantCode string) (*models.Tenant, context.Context, error) {
+	current := tenantCtx.GetTenantCode(ctx)
+	if current == "" {
+		current = "system"
+	}
+	if tenantCode == "" {
+		tenantCode = current
+	}
+	if tenantCode != current && !hasRole(tenantCtx.GetRoles(ctx), "super") {
+		return nil, nil, ErrCrossTenantSimulate
+	}
+
+	if tenantCode == "system" {
+		return nil, tenantCtx.WithTenantCode(ctx, ""), nil
+	}
+	tenant, err := s.tenantRepo.GetByCode(ctx, tenantCode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取租户失败: %w", err)
+	}
+	if tenant == nil {
+		return nil, nil, fmt.Errorf("租户不存在: %s", tenantCode)
+	}
+	return tenant, tenantCtx.WithTenantCode(ctx, tenant.Code), nil
+}
+
+// rolePolicies 按角色的菜单权限生成策略（菜单名换成菜单路径，与 SyncRoleMenusWithPermissions 一致）
+func rolePolicies(tenantID string, roles []*models.Role, menus []*models.Menu) []casbin.Policy {
+	paths := make(map[string]string, len(menus))
+	for _, menu := range menus {
+		paths[menu.Name] = menu.Path
+	}
+
+	var policies []casbin.Policy
+	for _, role := range roles {
+		sub := casbin.RoleSubject(tenantID, role.ID)
+		for menuName, actions := range role.MenuPermissions {
+			path := paths[menuName]
+			if path == "" {
+				path = "/" + menuName
+			}
+			for _, act := range actions {
+				policies = append(policies, casbin.Policy{Subject: sub, Object: path, Action: act})
+			}
+		}
+	}
+	return policies
+}
+
+// tenantEntitlement 资源命中的菜单是否分配给了租户
+func tenantEntitlement(tenant *models.Tenant, resource string, menus []*models.Menu) *dto.TenantEntitlement {
+	owned := make(map[string]bool, len(tenant.Menus))
+	for _, name := range tenant.Menus {
+		owned[name] = true
+	}
+
+	ent := &dto.TenantEntitlement{TenantID: tenant.ID, TenantCode: tenant.Code, MatchedMenus: []string{}}
+	for _, menu := range menus {
+		if menu.Path == "" || !casbin.MatchResource(resource, menu.Path) {
+			continue
+		}
+		ent.MatchedMenus = append(ent.MatchedMenus, menu.Name)
+		if owned[menu.Name] {
+			ent.Entitled = true
+		}
+	}
+	return ent
+}
+
+// menuActions 菜单的可用动作（加上角色配置的自定义动作）
+func menuActions(menu *models.Menu, granted []string) []string {
+	seen := make(map[string]bool)
+	var actions []string
+	add := func(act string) {
+		if act != "" && !seen[act] {
+			seen[act] = true
+			actions = append(actions, act)
+		}
+	}
+	if len(menu.AvailablePermissions) == 0 {
+		for _, act := range defaultMenuActions {
+			add(act)
+		}
+	}
+	for _, p := range menu.AvailablePermissions {
+		add(p.Action)
+	}
+	for _, act := range granted {
+		add(act)
+	}
+	return actions
+}
+
+// inactiveRoles 找出不参与计算的角色（super、tenant_admin 为内置角色标识，不在角色表中）
+func inactiveRoles(roleIDs []string, roles []*models.Role) []string {
+	found := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		found[role.ID] = true
+	}
+	var inactive []string
+	for _, id := range roleIDs {
+		if !found[id] && id != "super" && id != "tenant_admin" {
+			inactive = append(inactive, id)
+		}
+	}
+	sort.Strings(inactive)
+	return inactive
+}
+
+func hasRole(roles []string, target string) bool {
+	for _, role := range roles {
+		if role == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mule-cloud/app/perms/dto"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/quota"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrPlanForbidden = errors.New("只有系统超管可以管理套餐和订阅")
+	ErrPlanNotFound  = errors.New("套餐不存在")
+	ErrPlanInUse     = errors.New("套餐正在被租户使用，不能删除")
+)
+
+// validFeatures 可以开通的功能
+var validFeatures = map[string]bool{
+	models.FeatureWorkflowDesigner: true,
+	models.FeatureQuality:          true,
+	models.FeatureOutsourcing:      true,
+}
+
+// PlanService 订阅套餐、租户订阅和用量（系统超管）
+type PlanService struct {
+	planRepo   *repository.PlanRepository
+	tenantRepo repository.TenantRepository
+}
+
+func NewPlanService() *PlanService {
+	return &PlanService{
+		planRepo:   repository.NewPlanRepository(),
+		tenantRepo: repository.NewTenantRepository(),
+	}
+}
+
+// List 全部套餐
+func (s *PlanService) List(ctx context.Context) ([]*models.Plan, error) {
+	if err := requireSuper(ctx); err != nil {
+		return nil, err
+	}
+	return s.planRepo.List(ctx)
+}
+
+// Create 创建套餐
+func (s *PlanService) Create(ctx context.Context, req *dto.PlanRequest, createdBy string) (*models.Plan, error) {
+	if err := requireSuper(ctx); err != nil {
+		return nil, err
+	}
+	if err := validatePlan(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	plan := &models.Plan{
+		Code:        strings.TrimSpace(req.Code),
+		Name:        req.Name,
+		Description: req.Description,
+		Limits:      req.Limits,
+		Features:    req.Features,
+		Status:      1,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if req.Status != nil {
+		plan.Status = *req.Status
+	}
+	if err := s.planRepo.Create(ctx, plan); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, fmt.Errorf("套餐代码已存在: %s", plan.Code)
+		}
+		return nil, fmt.Errorf("创建套餐失败: %w", err)
+	}
+	return plan, nil
+}
+
+// Update 更新套餐（套餐代码不能修改，租户按代码关联）
+func (s *PlanService) Update(ctx context.Context, id string, req *dto.PlanRequest, updatedBy string) (*models.Plan, error) {
+	if err := requireSuper(ctx); err != nil {
+		return nil, err
+	}
+	if err := validatePlan(req); err != nil {
+		return nil, err
+	}
+	plan, err := s.planRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, ErrPlanNotFound
+	}
+	if strings.TrimSpace(req.Code) != plan.Code {
+		return nil, fmt.Errorf("套餐代码不能修改")
+	}
+
+	update := bson.M{
+		"name":        req.Name,
+		"description": req.Description,
+		"limits":      req.Limits,
+		"features":    req.Features,
+		"updated_by":  updatedBy,
+		"updated_at":  time.Now().Unix(),
+	}
+	if req.Status != nil {
+		update["status"] = *req.Status
+	}
+	if err := s.planRepo.Update(ctx, id, update); err != nil {
+		return nil, err
+	}
+	quota.Invalidate("")
+	return s.planRepo.Get(ctx, id)
+}
+
+// Delete 删除套餐（有租户订阅时不能删除）
+func (s *PlanService) Delete(ctx context.Context, id, deletedBy string) error {
+	if err := requireSuper(ctx); err != nil {
+		return err
+	}
+	plan, err := s.planRepo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if plan == nil {
+		return ErrPlanNotFound
+	}
+	inUse, err := s.tenantRepo.Count(ctx, bson.M{"is_deleted": 0, "subscription.plan_code": plan.Code})
+	if err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return ErrPlanInUse
+	}
+
+	now := time.Now().Unix()
+	return s.planRepo.Update(ctx, id, bson.M{
+		"is_deleted": 1,
+		"updated_by": deletedBy,
+		"updated_at": now,
+	})
+}
+
+// SetSubscription 设置租户的订阅套餐和到期时间
+func (s *PlanService) SetSubscription(ctx context.Context, tenantID string, req *dto.TenantSubscriptionRequest, updatedBy string) error {
+	if err := requireSuper(ctx); err != nil {
+		return err
+	}
+	tenant, err := s.tenantRepo.Get(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if tenant == nil {
+		return repository.ErrNotFound
+	}
+	if req.GraceDays < 0 || req.ExpiresAt < 0 {
+		return fmt.Errorf("到期时间和宽限天数不能为负数")
+	}
+
+	subscription := models.TenantSubscription{}
+	if req.PlanCode != "" {
+		plan, err := s.planRepo.GetByCode(ctx, req.PlanCode)
+		if err != nil {
+			return err
+		}
+		if plan == nil {
+			return ErrPlanNotFound
+		}
+		if plan.Status != 1 && plan.Code != tenant.Subscription.PlanCode {
+			return fmt.Errorf("套餐已停用: %s", plan.Code)
+		}
+		subscription = models.TenantSubscription{
+			PlanCode:  plan.Code,
+			StartedAt: tenant.Subscription.StartedAt,
+			ExpiresAt: req.ExpiresAt,
+			GraceDays: req.GraceDays,
+		}
+		// 更换套餐时重新计算开始时间，续费保留原开始时间
+		if subscription.StartedAt == 0 || plan.Code != tenant.Subscription.PlanCode {
+			subscription.StartedAt = time.Now().Unix()
+		}
+	}
+
+	if err := s.tenantRepo.Update(ctx, tenantID, bson.M{
+		"subscription": subscription,
+		"updated_by":   updatedBy,
+		"updated_at":   time.Now().Unix(),
+	}); err != nil {
+		return err
+	}
+	quota.Invalidate(tenant.Code)
+	return nil
+}
+
+// TenantUsage 租户的套餐和用量
+func (s *PlanService) TenantUsage(ctx context.Context, tenantID string) (*dto.TenantUsageResponse, error) {
+	if err := requireSuper(ctx); err != nil {
+		return nil, err
+	}
+	tenant, err := s.tenantRepo.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, repository.ErrNotFound
+	}
+	return s.usage(ctx, tenant)
+}
+
+// UsageOverview 全部租户的套餐和用量（用量看板）
+func (s *PlanService) UsageOverview(ctx context.Context) ([]*dto.TenantUsageResponse, error) {
+	if err := requireSuper(ctx); err != nil {
+		return nil, err
+	}
+	tenants, err := s.tenantRepo.Find(ctx, bson.M{"is_deleted": 0})
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*dto.TenantUsageResponse, 0, len(tenants))
+	for _, tenant := range tenants {
+		item, err := s.usage(ctx, tenant)
+		if err != nil {
+			return nil, fmt.Errorf("统计租户 %s 用量失败: %w", tenant.Code, err)
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+func (s *PlanService) usage(ctx context.Context, tenant *models.Tenant) (*dto.TenantUsageResponse, error) {
+	entitlement, err := quota.Resolve(ctx, tenant.Code)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := quota.GetUsage(ctx, tenant.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TenantUsageResponse{
+		TenantID:     tenant.ID,
+		TenantCode:   tenant.Code,
+		TenantName:   tenant.Name,
+		Subscription: entitlement.Subscription,
+		GraceUntil:   entitlement.Subscription.GraceUntil(),
+		State:        entitlement.State,
+		Plan:         entitlement.Plan,
+		Usage:        usage,
+	}, nil
+}
+
+func validatePlan(req *dto.PlanRequest) error {
+	if strings.TrimSpace(req.Code) == "" || req.Name == "" {
+		return fmt.Errorf("套餐代码和名称不能为空")
+	}
+	l := req.Limits
+	if l.MaxMembers < 0 || l.MaxOrdersPerMonth < 0 || l.MaxStorageBytes < 0 || l.MaxAPICallsPerMonth < 0 {
+		return fmt.Errorf("配额不能为负数（0 表示不限制）")
+	}
+	for _, f := range req.Features {
+		if !validFeatures[f] {
+			return fmt.Errorf("未知的功能: %s", f)
+		}
+	}
+	return nil
+}
+
+// requireSuper 套餐和订阅只有系统租户的超管可以管理
+func requireSuper(ctx context.Context) error {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	if (tenantCode != "" && tenantCode != "system") || !hasRole(tenantCtx.GetRoles(ctx), "super") {
+		return ErrPlanForbidden
+	}
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"mule-cloud/app/perms/dto"
 	"mule-cloud/core/casbin"
-	"mule-cloud/core/logger"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
 	"time"
@@ -29,6 +28,13 @@ func NewRoleService() *RoleService {
 
 // Create 创建角色
 func (s *RoleService) Create(ctx context.Context, req *dto.CreateRoleRequest, createdBy string) (*models.Role, error) {
+	if !models.ValidDataScope(req.DataScope) {
+		return nil, fmt.Errorf("无效的数据范围: %s", req.DataScope)
+	}
+	if err := models.ValidFieldPermissions(req.FieldPermissions); err != nil {
+		return nil, err
+	}
+
 	// 检查角色代码是否已存在
 	existingRole, err := s.roleRepo.GetByCode(ctx, req.Code)
 	if err != nil {
@@ -48,16 +54,18 @@ func (s *RoleService) Create(ctx context.Context, req *dto.CreateRoleRequest, cr
 	}
 
 	role := &models.Role{
-		Name:            req.Name,
-		Code:            req.Code,
-		Description:     req.Description,
-		Menus:           req.Menus,
-		MenuPermissions: req.MenuPermissions,
-		Status:          1,
-		IsDeleted:       0,
-		CreatedBy:       createdBy,
-		CreatedAt:       time.Now().Unix(),
-		UpdatedAt:       time.Now().Unix(),
+		Name:             req.Name,
+		Code:             req.Code,
+		Description:      req.Description,
+		Menus:            req.Menus,
+		MenuPermissions:  req.MenuPermissions,
+		DataScope:        req.DataScope,
+		FieldPermissions: req.FieldPermissions,
+		Status:           1,
+		IsDeleted:        0,
+		CreatedBy:        createdBy,
+		CreatedAt:        time.Now().Unix(),
+		UpdatedAt:        time.Now().Unix(),
 	}
 
 	if role.Menus == nil {
@@ -170,6 +178,20 @@ func (s *RoleService) Update(ctx context.Context, id string, req *dto.UpdateRole
 		updates["menu_permissions"] = req.MenuPermissions
 	}
 
+	if req.DataScope != nil {
+		if !models.ValidDataScope(*req.DataScope) {
+			return fmt.Errorf("无效的数据范围: %s", *req.DataScope)
+		}
+		updates["data_scope"] = *req.DataScope
+	}
+
+	if req.FieldPermissions != nil {
+		if err := models.ValidFieldPermissions(req.FieldPermissions); err != nil {
+			return err
+		}
+		updates["field_permissions"] = req.FieldPermissions
+	}
+
 	if req.Status != nil {
 		updates["status"] = *req.Status
 	}
@@ -250,7 +272,7 @@ func (s *RoleService) AssignMenus(ctx context.Context, roleID string, menuNames
 		for _, menuName := range menuNames {
 			menu, err := s.menuRepo.GetByName(ctx, menuName)
 			if err != nil {
-				logger.Warn("查询菜单失败", zap.String("menu_name", menuName), zap.Error(err))
+				log.Ctx(ctx).Warn("查询菜单失败", zap.String("menu_name", menuName), zap.Error(err))
 				continue
 			}
 			if menu != nil {
@@ -262,10 +284,10 @@ func (s *RoleService) AssignMenus(ctx context.Context, roleID string, menuNames
 		// 数据库隔离后，Casbin只使用roleID作为唯一标识
 		err = casbin.SyncRoleMenusWithPermissions("", roleID, menuPermissions, menuPathMap)
 		if err != nil {
-			logger.Warn("同步Casbin权限失败", zap.String("role_id", roleID), zap.Error(err))
+			log.Ctx(ctx).Warn("同步Casbin权限失败", zap.String("role_id", roleID), zap.Error(err))
 			// 不中断流程，只记录日志
 		} else {
-			logger.Info("角色权限已同步到 Casbin", zap.String("role_id", roleID))
+			log.Ctx(ctx).Info("角色权限已同步到 Casbin", zap.String("role_id", roleID))
 		}
 	}
 
@@ -6,8 +6,10 @@ import (
 	"mule-cloud/app/perms/dto"
 	tenantCtx "mule-cloud/core/context"
 	"mule-cloud/core/database"
+	"mule-cloud/core/oidc"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -127,16 +129,17 @@ func (s *TenantService) Create(req dto.TenantCreateRequest) (*models.Tenant, err
 	now := time.Now().Unix()
 
 	tenant := &models.Tenant{
-		Code:      req.Code,
-		Name:      req.Name,
-		Contact:   req.Contact,
-		Phone:     req.Phone,
-		Email:     req.Email,
-		Menus:     []string{}, // 初始化为空菜单数组
-		Status:    req.Status,
-		IsDeleted: 0, // 初始化为未删除
-		CreatedAt: now,
-		UpdatedAt: now,
+		Code:       req.Code,
+		Name:       req.Name,
+		Contact:    req.Contact,
+		Phone:      req.Phone,
+		Email:      req.Email,
+		Menus:      []string{}, // 初始化为空菜单数组
+		Status:     req.Status,
+		Require2FA: req.Require2FA,
+		IsDeleted:  0, // 初始化为未删除
+		CreatedAt:  now,
+		UpdatedAt:  now,
 	}
 
 	// 如果未指定状态，默认为启用
@@ -248,6 +251,9 @@ func (s *TenantService) Update(req dto.TenantUpdateRequest) (*models.Tenant, err
 	if req.Status != nil {
 		update["status"] = *req.Status
 	}
+	if req.Require2FA != nil {
+		update["require_2fa"] = *req.Require2FA
+	}
 
 	err := s.repo.Update(ctx, req.ID, update)
 	if err != nil {
@@ -301,6 +307,66 @@ func (s *TenantService) AssignMenus(ctx context.Context, tenantID string, menuID
 	return nil
 }
 
+// GetSSO 获取租户单点登录配置
+func (s *TenantService) GetSSO(ctx context.Context, tenantID string) (*dto.TenantSSOResponse, error) {
+	tenant, err := s.repo.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, repository.ErrNotFound
+	}
+	return &dto.TenantSSOResponse{
+		TenantSSO:       tenant.SSO,
+		HasClientSecret: tenant.SSO.ClientSecret != "",
+	}, nil
+}
+
+// UpdateSSO 配置租户单点登录（启用时会访问身份提供方校验配置）
+func (s *TenantService) UpdateSSO(ctx context.Context, tenantID string, req *dto.TenantSSORequest, updatedBy string) error {
+	tenant, err := s.repo.Get(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if tenant == nil {
+		return repository.ErrNotFound
+	}
+
+	sso := models.TenantSSO{
+		Enabled:       req.Enabled,
+		Issuer:        strings.TrimSpace(req.Issuer),
+		ClientID:      strings.TrimSpace(req.ClientID),
+		ClientSecret:  req.ClientSecret,
+		RedirectURL:   strings.TrimSpace(req.RedirectURL),
+		Scopes:        req.Scopes,
+		Enforce:       req.Enforce,
+		AutoProvision: req.AutoProvision,
+		ClaimMapping:  req.ClaimMapping,
+		RoleMappings:  req.RoleMappings,
+		DefaultRoles:  req.DefaultRoles,
+	}
+	if sso.ClientSecret == "" {
+		sso.ClientSecret = tenant.SSO.ClientSecret
+	}
+
+	if sso.Enabled {
+		if sso.Issuer == "" || sso.ClientID == "" || sso.RedirectURL == "" {
+			return fmt.Errorf("启用单点登录需要配置 issuer、client_id 和 redirect_url")
+		}
+		if _, err := oidc.NewClient(ctx, oidc.Config{Issuer: sso.Issuer, ClientID: sso.ClientID}); err != nil {
+			return fmt.Errorf("身份提供方配置校验失败: %w", err)
+		}
+	} else if sso.Enforce {
+		return fmt.Errorf("未启用单点登录时不能禁用密码登录")
+	}
+
+	return s.repo.Update(ctx, tenantID, bson.M{
+		"sso":        sso,
+		"updated_by": updatedBy,
+		"updated_at": time.Now().Unix(),
+	})
+}
+
 // GetTenantMenus 获取租户的菜单权限
 func (s *TenantService) GetTenantMenus(ctx context.Context, tenantID string) ([]string, error) {
 	tenant, err := s.repo.Get(ctx, tenantID)
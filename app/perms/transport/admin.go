@@ -196,3 +196,103 @@ func RemoveAdminRoleHandler(adminSvc *services.AdminService) gin.HandlerFunc {
 		response.SuccessWithMsg(c, "移除角色成功", nil)
 	}
 }
+
+// ListAdminSessionsHandler 获取管理员登录会话
+func ListAdminSessionsHandler(svc services.IAdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.AdminSessionRequest
+		if err := c.ShouldBindUri(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.ListAdminSessionsEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// RevokeAdminSessionsHandler 注销管理员会话（踢下线）
+func RevokeAdminSessionsHandler(svc services.IAdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.AdminSessionRequest
+		if err := c.ShouldBindUri(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.RevokeAdminSessionsEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetAdminLockStatusHandler 获取管理员登录锁定状态
+func GetAdminLockStatusHandler(svc services.IAdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.AdminListRequest
+		if err := c.ShouldBindUri(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.GetAdminLockStatusEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// UnlockAdminHandler 解锁因登录失败次数过多被锁定的管理员
+func UnlockAdminHandler(svc services.IAdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.AdminListRequest
+		if err := c.ShouldBindUri(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.UnlockAdminEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ResetAdminTwoFactorHandler 重置管理员两步验证
+func ResetAdminTwoFactorHandler(svc services.IAdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.AdminListRequest
+		if err := c.ShouldBindUri(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.ResetAdminTwoFactorEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
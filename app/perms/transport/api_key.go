@@ -0,0 +1,118 @@
+package transport
+
+import (
+	"mule-cloud/app/perms/dto"
+	"mule-cloud/app/perms/services"
+	"mule-cloud/core/apikey"
+	"mule-cloud/core/response"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPIKeyHandler 创建API密钥
+func CreateAPIKeyHandler(apiKeySvc *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rejectAPIKeyCaller(c) {
+			return
+		}
+
+		var req dto.CreateAPIKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		result, err := apiKeySvc.Create(c.Request.Context(), &req, c.GetString("user_id"))
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.SuccessWithMsg(c, "创建成功，请立即保存密钥，关闭后将无法再次查看", result)
+	}
+}
+
+// GetAPIKeyHandler 获取API密钥详情
+func GetAPIKeyHandler(apiKeySvc *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey, err := apiKeySvc.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, apiKey)
+	}
+}
+
+// ListAPIKeysHandler 查询API密钥列表
+func ListAPIKeysHandler(apiKeySvc *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ListAPIKeyRequest
+		if err := c.ShouldBindQuery(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		keys, total, err := apiKeySvc.List(c.Request.Context(), &req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, map[string]interface{}{
+			"api_keys": keys,
+			"total":    total,
+			"page":     req.Page,
+			"size":     req.PageSize,
+		})
+	}
+}
+
+// UpdateAPIKeyHandler 更新API密钥
+func UpdateAPIKeyHandler(apiKeySvc *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rejectAPIKeyCaller(c) {
+			return
+		}
+
+		var req dto.UpdateAPIKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		if err := apiKeySvc.Update(c.Request.Context(), c.Param("id"), &req, c.GetString("user_id")); err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.SuccessWithMsg(c, "更新成功", nil)
+	}
+}
+
+// DeleteAPIKeyHandler 吊销API密钥
+func DeleteAPIKeyHandler(apiKeySvc *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rejectAPIKeyCaller(c) {
+			return
+		}
+
+		if err := apiKeySvc.Delete(c.Request.Context(), c.Param("id"), c.GetString("user_id")); err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.SuccessWithMsg(c, "吊销成功", nil)
+	}
+}
+
+// rejectAPIKeyCaller 禁止用API密钥管理API密钥（避免密钥给自己扩权）
+func rejectAPIKeyCaller(c *gin.Context) bool {
+	if strings.HasPrefix(c.GetString("user_id"), apikey.UserIDPrefix) {
+		response.ErrorWithCode(c, 403, "API密钥不能管理API密钥")
+		return true
+	}
+	return false
+}
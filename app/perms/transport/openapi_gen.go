@@ -0,0 +1,84 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package transport
+
+import (
+	"mule-cloud/app/perms/dto"
+	"mule-cloud/core/openapi"
+	"mule-cloud/core/security"
+	"mule-cloud/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operations 处理器的接口描述（cmd/openapi-gen 生成，服务的 /openapi.json 使用）
+var Operations = openapi.Operations{
+	"mule-cloud/app/perms/transport.GetAdminHandler":               {Summary: "获取管理员", Tag: "admin", Request: dto.AdminListRequest{}, Bind: openapi.FromURI, Response: dto.AdminResponse{}},
+	"mule-cloud/app/perms/transport.GetAllAdminsHandler":           {Summary: "获取所有管理员（不分页）", Tag: "admin", Request: dto.AdminListRequest{}, Bind: openapi.FromQuery | openapi.FromBody, Response: dto.AdminListResponse{}},
+	"mule-cloud/app/perms/transport.ListAdminsHandler":             {Summary: "管理员列表（分页）", Tag: "admin", Request: dto.AdminListRequest{}, Bind: openapi.FromQuery | openapi.FromBody, Response: dto.AdminListResponse{}},
+	"mule-cloud/app/perms/transport.CreateAdminHandler":            {Summary: "创建管理员", Tag: "admin", Request: dto.AdminCreateRequest{}, Bind: openapi.FromBody, Response: dto.AdminResponse{}},
+	"mule-cloud/app/perms/transport.UpdateAdminHandler":            {Summary: "更新管理员", Tag: "admin", Request: dto.AdminUpdateRequest{}, Bind: openapi.FromURI | openapi.FromBody, Response: dto.AdminResponse{}},
+	"mule-cloud/app/perms/transport.DeleteAdminHandler":            {Summary: "删除管理员", Tag: "admin", Request: dto.AdminListRequest{}, Bind: openapi.FromURI, Response: map[string]string{}},
+	"mule-cloud/app/perms/transport.AssignAdminRolesHandler":       {Summary: "分配角色给管理员", Tag: "admin", Request: dto.AssignRolesRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.GetAdminRolesHandler":          {Summary: "获取管理员的角色", Tag: "admin", Response: []string{}},
+	"mule-cloud/app/perms/transport.RemoveAdminRoleHandler":        {Summary: "移除管理员的某个角色", Tag: "admin"},
+	"mule-cloud/app/perms/transport.ListAdminSessionsHandler":      {Summary: "获取管理员登录会话", Tag: "admin", Request: dto.AdminSessionRequest{}, Bind: openapi.FromURI, Response: map[string]interface{}{}},
+	"mule-cloud/app/perms/transport.RevokeAdminSessionsHandler":    {Summary: "注销管理员会话（踢下线）", Tag: "admin", Request: dto.AdminSessionRequest{}, Bind: openapi.FromURI, Response: map[string]interface{}{}},
+	"mule-cloud/app/perms/transport.GetAdminLockStatusHandler":     {Summary: "获取管理员登录锁定状态", Tag: "admin", Request: dto.AdminListRequest{}, Bind: openapi.FromURI, Response: security.LockStatus{}},
+	"mule-cloud/app/perms/transport.UnlockAdminHandler":            {Summary: "解锁因登录失败次数过多被锁定的管理员", Tag: "admin", Request: dto.AdminListRequest{}, Bind: openapi.FromURI, Response: map[string]interface{}{}},
+	"mule-cloud/app/perms/transport.ResetAdminTwoFactorHandler":    {Summary: "重置管理员两步验证", Tag: "admin", Request: dto.AdminListRequest{}, Bind: openapi.FromURI, Response: map[string]interface{}{}},
+	"mule-cloud/app/perms/transport.CreateAPIKeyHandler":           {Summary: "创建API密钥", Tag: "api_key", Request: dto.CreateAPIKeyRequest{}, Bind: openapi.FromBody, Response: dto.CreateAPIKeyResponse{}},
+	"mule-cloud/app/perms/transport.GetAPIKeyHandler":              {Summary: "获取API密钥详情", Tag: "api_key", Response: models.APIKey{}},
+	"mule-cloud/app/perms/transport.ListAPIKeysHandler":            {Summary: "查询API密钥列表", Tag: "api_key", Request: dto.ListAPIKeyRequest{}, Bind: openapi.FromQuery, Response: map[string]interface{}{}},
+	"mule-cloud/app/perms/transport.UpdateAPIKeyHandler":           {Summary: "更新API密钥", Tag: "api_key", Request: dto.UpdateAPIKeyRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.DeleteAPIKeyHandler":           {Summary: "吊销API密钥", Tag: "api_key"},
+	"mule-cloud/app/perms/transport.CreateDepartmentHandler":       {Summary: "创建部门", Tag: "dept", Request: dto.CreateDepartmentRequest{}, Bind: openapi.FromBody, Response: models.Department{}},
+	"mule-cloud/app/perms/transport.GetDepartmentHandler":          {Summary: "获取部门详情", Tag: "dept", Response: models.Department{}},
+	"mule-cloud/app/perms/transport.ListDepartmentsHandler":        {Summary: "查询部门列表", Tag: "dept", Request: dto.ListDepartmentRequest{}, Bind: openapi.FromQuery, Response: map[string]interface{}{}},
+	"mule-cloud/app/perms/transport.GetAllDepartmentsHandler":      {Summary: "获取所有部门（不分页）", Tag: "dept", Response: map[string]interface{}{}},
+	"mule-cloud/app/perms/transport.UpdateDepartmentHandler":       {Summary: "更新部门", Tag: "dept", Request: dto.UpdateDepartmentRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.DeleteDepartmentHandler":       {Summary: "删除部门", Tag: "dept"},
+	"mule-cloud/app/perms/transport.BatchDeleteDepartmentsHandler": {Summary: "批量删除部门", Tag: "dept", Request: dto.BatchDeleteDepartmentRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.GetAllMenusHandler":            {Summary: "获取所有菜单（Nova-admin路由数据）", Tag: "menu", Response: []*models.Menu{}},
+	"mule-cloud/app/perms/transport.GetMenuHandler":                {Summary: "获取单个菜单", Tag: "menu", Response: models.Menu{}},
+	"mule-cloud/app/perms/transport.CreateMenuHandler":             {Summary: "创建菜单", Tag: "menu", Request: dto.CreateMenuRequest{}, Bind: openapi.FromBody, Response: models.Menu{}},
+	"mule-cloud/app/perms/transport.UpdateMenuHandler":             {Summary: "更新菜单", Tag: "menu", Request: dto.UpdateMenuRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.DeleteMenuHandler":             {Summary: "删除菜单", Tag: "menu"},
+	"mule-cloud/app/perms/transport.ListMenusHandler":              {Summary: "分页查询菜单", Tag: "menu", Request: dto.ListMenuRequest{}, Bind: openapi.FromQuery, Response: gin.H{}},
+	"mule-cloud/app/perms/transport.BatchDeleteMenusHandler":       {Summary: "批量删除菜单", Tag: "menu", Request: dto.BatchDeleteMenuRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.SimulatePermissionHandler":     {Summary: "权限模拟：解释某个管理员或角色访问接口为什么被允许或拒绝", Tag: "permission", Request: dto.SimulatePermissionRequest{}, Bind: openapi.FromBody, Response: dto.SimulatePermissionResponse{}},
+	"mule-cloud/app/perms/transport.GetPermissionMatrixHandler":    {Summary: "角色的有效权限矩阵（菜单 × 动作）", Tag: "permission", Request: dto.PermissionMatrixRequest{}, Bind: openapi.FromQuery, Response: dto.PermissionMatrixResponse{}},
+	"mule-cloud/app/perms/transport.ListPlansHandler":              {Summary: "查询套餐列表", Tag: "plan", Response: []*models.Plan{}},
+	"mule-cloud/app/perms/transport.CreatePlanHandler":             {Summary: "创建套餐", Tag: "plan", Request: dto.PlanRequest{}, Bind: openapi.FromBody, Response: models.Plan{}},
+	"mule-cloud/app/perms/transport.UpdatePlanHandler":             {Summary: "更新套餐", Tag: "plan", Request: dto.PlanRequest{}, Bind: openapi.FromBody, Response: models.Plan{}},
+	"mule-cloud/app/perms/transport.DeletePlanHandler":             {Summary: "删除套餐", Tag: "plan"},
+	"mule-cloud/app/perms/transport.SetTenantSubscriptionHandler":  {Summary: "设置租户订阅（套餐、到期时间、宽限天数）", Tag: "plan", Request: dto.TenantSubscriptionRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.GetTenantUsageHandler":         {Summary: "获取租户的套餐用量", Tag: "plan", Response: dto.TenantUsageResponse{}},
+	"mule-cloud/app/perms/transport.ListTenantUsageHandler":        {Summary: "全部租户的套餐用量（用量看板）", Tag: "plan", Response: []*dto.TenantUsageResponse{}},
+	"mule-cloud/app/perms/transport.CreatePostHandler":             {Summary: "创建岗位", Tag: "post", Request: dto.CreatePostRequest{}, Bind: openapi.FromBody, Response: models.Post{}},
+	"mule-cloud/app/perms/transport.GetPostHandler":                {Summary: "获取岗位详情", Tag: "post", Response: models.Post{}},
+	"mule-cloud/app/perms/transport.ListPostsHandler":              {Summary: "查询岗位列表", Tag: "post", Request: dto.ListPostRequest{}, Bind: openapi.FromQuery, Response: map[string]interface{}{}},
+	"mule-cloud/app/perms/transport.GetAllPostsHandler":            {Summary: "获取所有岗位（不分页）", Tag: "post", Response: map[string]interface{}{}},
+	"mule-cloud/app/perms/transport.UpdatePostHandler":             {Summary: "更新岗位", Tag: "post", Request: dto.UpdatePostRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.DeletePostHandler":             {Summary: "删除岗位", Tag: "post"},
+	"mule-cloud/app/perms/transport.BatchDeletePostsHandler":       {Summary: "批量删除岗位", Tag: "post", Request: dto.BatchDeletePostRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.CreateRoleHandler":             {Summary: "创建角色", Tag: "role", Request: dto.CreateRoleRequest{}, Bind: openapi.FromBody, Response: models.Role{}},
+	"mule-cloud/app/perms/transport.GetRoleHandler":                {Summary: "获取角色详情", Tag: "role", Response: models.Role{}},
+	"mule-cloud/app/perms/transport.ListRolesHandler":              {Summary: "查询角色列表", Tag: "role", Request: dto.ListRoleRequest{}, Bind: openapi.FromQuery, Response: map[string]interface{}{}},
+	"mule-cloud/app/perms/transport.UpdateRoleHandler":             {Summary: "更新角色", Tag: "role", Request: dto.UpdateRoleRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.DeleteRoleHandler":             {Summary: "删除角色", Tag: "role"},
+	"mule-cloud/app/perms/transport.BatchDeleteRolesHandler":       {Summary: "批量删除角色", Tag: "role", Request: dto.BatchDeleteRoleRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.AssignMenusHandler":            {Summary: "分配菜单权限（支持细粒度权限）", Tag: "role", Request: dto.AssignMenusRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.GetRoleMenusHandler":           {Summary: "获取角色的菜单权限", Tag: "role", Response: []string{}},
+	"mule-cloud/app/perms/transport.GetTenantRolesHandler":         {Summary: "获取租户下的所有角色", Tag: "role", Response: []*models.Role{}},
+	"mule-cloud/app/perms/transport.GetTenantHandler":              {Summary: "获取租户", Tag: "tenant", Request: dto.TenantListRequest{}, Bind: openapi.FromURI, Response: dto.TenantResponse{}},
+	"mule-cloud/app/perms/transport.GetAllTenantsHandler":          {Summary: "获取所有租户（不分页）", Tag: "tenant", Request: dto.TenantListRequest{}, Bind: openapi.FromQuery | openapi.FromBody, Response: dto.TenantListResponse{}},
+	"mule-cloud/app/perms/transport.ListTenantsHandler":            {Summary: "租户列表（分页）", Tag: "tenant", Request: dto.TenantListRequest{}, Bind: openapi.FromQuery | openapi.FromBody, Response: dto.TenantListResponse{}},
+	"mule-cloud/app/perms/transport.CreateTenantHandler":           {Summary: "创建租户", Tag: "tenant", Request: dto.TenantCreateRequest{}, Bind: openapi.FromBody, Response: dto.TenantResponse{}},
+	"mule-cloud/app/perms/transport.UpdateTenantHandler":           {Summary: "更新租户", Tag: "tenant", Request: dto.TenantUpdateRequest{}, Bind: openapi.FromURI | openapi.FromBody, Response: dto.TenantResponse{}},
+	"mule-cloud/app/perms/transport.DeleteTenantHandler":           {Summary: "删除租户", Tag: "tenant", Request: dto.TenantListRequest{}, Bind: openapi.FromURI, Response: map[string]string{}},
+	"mule-cloud/app/perms/transport.AssignTenantMenusHandler":      {Summary: "分配菜单权限给租户（超管使用）", Tag: "tenant", Request: dto.AssignTenantMenusRequest{}, Bind: openapi.FromBody},
+	"mule-cloud/app/perms/transport.GetTenantMenusHandler":         {Summary: "获取租户的菜单权限", Tag: "tenant", Response: []string{}},
+	"mule-cloud/app/perms/transport.GetTenantSSOHandler":           {Summary: "获取租户单点登录配置", Tag: "tenant", Response: dto.TenantSSOResponse{}},
+	"mule-cloud/app/perms/transport.UpdateTenantSSOHandler":        {Summary: "配置租户单点登录（OIDC）", Tag: "tenant", Request: dto.TenantSSORequest{}, Bind: openapi.FromBody},
+}
@@ -0,0 +1,56 @@
+package transport
+
+import (
+	"errors"
+	"mule-cloud/app/perms/dto"
+	"mule-cloud/app/perms/services"
+	"mule-cloud/core/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SimulatePermissionHandler 权限模拟：解释某个管理员或角色访问接口为什么被允许或拒绝
+func SimulatePermissionHandler(permSvc *services.PermissionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.SimulatePermissionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		result, err := permSvc.Simulate(c.Request.Context(), &req)
+		if err != nil {
+			permissionError(c, err)
+			return
+		}
+
+		response.Success(c, result)
+	}
+}
+
+// GetPermissionMatrixHandler 角色的有效权限矩阵（菜单 × 动作）
+func GetPermissionMatrixHandler(permSvc *services.PermissionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.PermissionMatrixRequest
+		if err := c.ShouldBindQuery(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		result, err := permSvc.Matrix(c.Request.Context(), &req)
+		if err != nil {
+			permissionError(c, err)
+			return
+		}
+
+		response.Success(c, result)
+	}
+}
+
+func permissionError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrCrossTenantSimulate) {
+		response.ErrorWithCode(c, 403, err.Error())
+		return
+	}
+	response.Error(c, err.Error())
+}
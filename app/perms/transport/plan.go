@@ -0,0 +1,116 @@
+package transport
+
+import (
+	"mule-cloud/app/perms/dto"
+	"mule-cloud/app/perms/services"
+	"mule-cloud/core/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListPlansHandler 查询套餐列表
+func ListPlansHandler(planSvc *services.PlanService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		plans, err := planSvc.List(c.Request.Context())
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, plans)
+	}
+}
+
+// CreatePlanHandler 创建套餐
+func CreatePlanHandler(planSvc *services.PlanService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.PlanRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		plan, err := planSvc.Create(c.Request.Context(), &req, c.GetString("user_id"))
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.SuccessWithMsg(c, "创建成功", plan)
+	}
+}
+
+// UpdatePlanHandler 更新套餐
+func UpdatePlanHandler(planSvc *services.PlanService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.PlanRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		plan, err := planSvc.Update(c.Request.Context(), c.Param("id"), &req, c.GetString("user_id"))
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.SuccessWithMsg(c, "更新成功", plan)
+	}
+}
+
+// DeletePlanHandler 删除套餐
+func DeletePlanHandler(planSvc *services.PlanService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := planSvc.Delete(c.Request.Context(), c.Param("id"), c.GetString("user_id")); err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.SuccessWithMsg(c, "删除成功", nil)
+	}
+}
+
+// SetTenantSubscriptionHandler 设置租户订阅（套餐、到期时间、宽限天数）
+func SetTenantSubscriptionHandler(planSvc *services.PlanService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.TenantSubscriptionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		if err := planSvc.SetSubscription(c.Request.Context(), c.Param("id"), &req, c.GetString("user_id")); err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.SuccessWithMsg(c, "设置成功", nil)
+	}
+}
+
+// GetTenantUsageHandler 获取租户的套餐用量
+func GetTenantUsageHandler(planSvc *services.PlanService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		usage, err := planSvc.TenantUsage(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, usage)
+	}
+}
+
+// ListTenantUsageHandler 全部租户的套餐用量（用量看板）
+func ListTenantUsageHandler(planSvc *services.PlanService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := planSvc.UsageOverview(c.Request.Context())
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, list)
+	}
+}
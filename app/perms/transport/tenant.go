@@ -171,3 +171,35 @@ func GetTenantMenusHandler(tenantSvc *services.TenantService) gin.HandlerFunc {
 		response.Success(c, menus)
 	}
 }
+
+// GetTenantSSOHandler 获取租户单点登录配置
+func GetTenantSSOHandler(tenantSvc *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sso, err := tenantSvc.GetSSO(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, sso)
+	}
+}
+
+// UpdateTenantSSOHandler 配置租户单点登录（OIDC）
+func UpdateTenantSSOHandler(tenantSvc *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.TenantSSORequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		err := tenantSvc.UpdateSSO(c.Request.Context(), c.Param("id"), &req, c.GetString("user_id"))
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.SuccessWithMsg(c, "单点登录配置已保存", nil)
+	}
+}
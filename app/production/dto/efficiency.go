@@ -0,0 +1,89 @@
+package dto
+
+import "mule-cloud/internal/models"
+
+// EfficiencyRequest 效率报表请求
+type EfficiencyRequest struct {
+	StartDate  string `form:"start_date"`  // 开始日期 YYYY-MM-DD
+	EndDate    string `form:"end_date"`    // 结束日期 YYYY-MM-DD
+	GroupBy    string `form:"group_by"`    // 统计维度：worker-工人（默认） team-班组 workshop-车间
+	GroupID    string `form:"group_id"`    // 趋势查询时指定工人/班组/车间ID
+	WorkerID   string `form:"worker_id"`   // 工人ID
+	TeamID     string `form:"team_id"`     // 班组ID
+	WorkshopID string `form:"workshop_id"` // 车间ID
+}
+
+// EfficiencyRow 效率统计行
+type EfficiencyRow struct {
+	Rank            int     `json:"rank"`              // 排名（按效率）
+	GroupID         string  `json:"group_id"`          // 工人/班组/车间ID
+	GroupName       string  `json:"group_name"`        // 工人/班组/车间名称
+	WorkerNo        string  `json:"worker_no"`         // 工号（按工人统计时）
+	Team            string  `json:"team"`              // 班组（按工人统计时）
+	Workshop        string  `json:"workshop"`          // 车间（按工人/班组统计时）
+	Workers         int     `json:"workers"`           // 人数
+	Days            int     `json:"days"`              // 出勤人天
+	Quantity        int     `json:"quantity"`          // 产量
+	EarnedMinutes   float64 `json:"earned_minutes"`    // 产出工时（分钟）
+	AttendedMinutes float64 `json:"attended_minutes"`  // 出勤工时（分钟）
+	Efficiency      float64 `json:"efficiency"`        // 效率% = 产出工时 / 出勤工时
+	Earnings        float64 `json:"earnings"`          // 计件工资
+	EarningsPerHour float64 `json:"earnings_per_hour"` // 时均收入 = 计件工资 / 出勤小时
+}
+
+// EfficiencyResponse 效率报表响应
+type EfficiencyResponse struct {
+	StartDate string           `json:"start_date"`
+	EndDate   string           `json:"end_date"`
+	GroupBy   string           `json:"group_by"`
+	SelfOnly  bool             `json:"self_only"` // 是否仅本人数据（工人查看）
+	Rows      []*EfficiencyRow `json:"rows"`
+	Summary   *EfficiencyRow   `json:"summary"` // 合计
+}
+
+// EfficiencyTrendPoint 效率趋势点（按天）
+type EfficiencyTrendPoint struct {
+	Date            string  `json:"date"`
+	Quantity        int     `json:"quantity"`
+	EarnedMinutes   float64 `json:"earned_minutes"`
+	AttendedMinutes float64 `json:"attended_minutes"`
+	Efficiency      float64 `json:"efficiency"`
+	Earnings        float64 `json:"earnings"`
+	EarningsPerHour float64 `json:"earnings_per_hour"`
+}
+
+// EfficiencyTrendResponse 效率趋势响应
+type EfficiencyTrendResponse struct {
+	GroupBy  string                  `json:"group_by"`
+	GroupID  string                  `json:"group_id"`
+	SelfOnly bool                    `json:"self_only"`
+	Points   []*EfficiencyTrendPoint `json:"points"`
+}
+
+// AttendanceSaveRequest 录入出勤请求（同一工人同一天重复录入会覆盖）
+type AttendanceSaveRequest struct {
+	Items []AttendanceItem `json:"items"`
+}
+
+// AttendanceItem 出勤条目
+type AttendanceItem struct {
+	WorkerID string `json:"worker_id"` // 工人ID（成员的 user_id）
+	WorkDate string `json:"work_date"` // 出勤日期 YYYY-MM-DD
+	Minutes  int    `json:"minutes"`   // 出勤分钟数
+	Remark   string `json:"remark"`
+}
+
+// AttendanceListRequest 出勤列表请求
+type AttendanceListRequest struct {
+	StartDate  string `form:"start_date"`
+	EndDate    string `form:"end_date"`
+	WorkerID   string `form:"worker_id"`
+	TeamID     string `form:"team_id"`
+	WorkshopID string `form:"workshop_id"`
+}
+
+// AttendanceListResponse 出勤列表响应
+type AttendanceListResponse struct {
+	Attendances []*models.Attendance `json:"attendances"`
+	Total       int                  `json:"total"`
+}
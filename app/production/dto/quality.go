@@ -34,6 +34,7 @@ type InspectionListRequest struct {
 	ContractNo  string `json:"contract_no" form:"contract_no"`
 	StartDate   int64  `json:"start_date" form:"start_date"`
 	EndDate     int64  `json:"end_date" form:"end_date"`
+	All         bool   `json:"all" form:"all"` // 查看数据范围内的全部记录（默认只看本人）
 }
 
 // InspectionListResponse 质检列表响应
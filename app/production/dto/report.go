@@ -30,6 +30,7 @@ type ReportListRequest struct {
 	ContractNo string `json:"contract_no" form:"contract_no"`
 	StartDate  string `json:"start_date" form:"start_date"`
 	EndDate    string `json:"end_date" form:"end_date"`
+	All        bool   `json:"all" form:"all"` // 查看数据范围内的全部记录（默认只看本人）
 }
 
 // ReportListResponse 上报记录列表响应
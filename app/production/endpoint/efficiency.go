@@ -0,0 +1,57 @@
+package endpoint
+
+import (
+	"context"
+
+	"mule-cloud/app/production/dto"
+	"mule-cloud/app/production/services"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// GetEfficiencyEndpoint 效率报表端点
+func GetEfficiencyEndpoint(s services.IEfficiencyService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.EfficiencyRequest)
+		return s.GetEfficiency(ctx, &req)
+	}
+}
+
+// GetEfficiencyTrendEndpoint 效率趋势端点
+func GetEfficiencyTrendEndpoint(s services.IEfficiencyService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.EfficiencyRequest)
+		return s.GetEfficiencyTrend(ctx, &req)
+	}
+}
+
+// SaveAttendancesEndpoint 录入出勤端点
+func SaveAttendancesEndpoint(s services.IEfficiencyService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.AttendanceSaveRequest)
+		count, err := s.SaveAttendances(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"message": "保存成功", "count": count}, nil
+	}
+}
+
+// ListAttendancesEndpoint 出勤列表端点
+func ListAttendancesEndpoint(s services.IEfficiencyService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(dto.AttendanceListRequest)
+		return s.ListAttendances(ctx, &req)
+	}
+}
+
+// DeleteAttendanceEndpoint 删除出勤端点
+func DeleteAttendanceEndpoint(s services.IEfficiencyService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		id := request.(string)
+		if err := s.DeleteAttendance(ctx, id); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"message": "删除成功"}, nil
+	}
+}
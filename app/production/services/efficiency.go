@@ -0,0 +1,487 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"mule-cloud/app/production/dto"
+	corecontext "mule-cloud/core/context"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// EfficiencyViewAllPermission 成员拥有该权限时可查看全部工人的效率（班组长、车间主任等）
+const EfficiencyViewAllPermission = "production:efficiency"
+
+// maxEfficiencyDays 单次统计的最大天数
+const maxEfficiencyDays = 366
+
+// IEfficiencyService 工人效率服务接口
+type IEfficiencyService interface {
+	// 效率报表
+	GetEfficiency(ctx context.Context, req *dto.EfficiencyRequest) (*dto.EfficiencyResponse, error)
+	GetEfficiencyTrend(ctx context.Context, req *dto.EfficiencyRequest) (*dto.EfficiencyTrendResponse, error)
+
+	// 出勤
+	SaveAttendances(ctx context.Context, req *dto.AttendanceSaveRequest) (int, error)
+	ListAttendances(ctx context.Context, req *dto.AttendanceListRequest) (*dto.AttendanceListResponse, error)
+	DeleteAttendance(ctx context.Context, id string) error
+}
+
+type efficiencyService struct {
+	reportRepo     repository.ProcedureReportRepository
+	attendanceRepo repository.AttendanceRepository
+	memberRepo     repository.TenantMemberRepository
+}
+
+// NewEfficiencyService 创建工人效率服务
+func NewEfficiencyService() IEfficiencyService {
+	return &efficiencyService{
+		reportRepo:     repository.NewProcedureReportRepository(),
+		attendanceRepo: repository.NewAttendanceRepository(),
+		memberRepo:     repository.NewTenantMemberRepository(),
+	}
+}
+
+// workerDay 工人单日产出和出勤
+type workerDay struct {
+	output     *models.WorkerDayOutput
+	attendance *models.Attendance
+}
+
+// GetEfficiency 效率报表（按工人/班组/车间汇总并排名）
+func (s *efficiencyService) GetEfficiency(ctx context.Context, req *dto.EfficiencyRequest) (*dto.EfficiencyResponse, error) {
+	selfOnly, err := s.restrictToSelf(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.loadWorkerDays(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]*dto.EfficiencyRow{}
+	workers := map[string]map[string]bool{}
+	summary := &dto.EfficiencyRow{GroupName: "合计"}
+	allWorkers := map[string]bool{}
+	for _, day := range days {
+		key, row := groupOf(req.GroupBy, day)
+		if groups[key] == nil {
+			groups[key] = row
+			workers[key] = map[string]bool{}
+		}
+		workerID := workerIDOf(day)
+		workers[key][workerID] = true
+		allWorkers[workerID] = true
+		addWorkerDay(groups[key], day)
+		addWorkerDay(summary, day)
+	}
+
+	rows := make([]*dto.EfficiencyRow, 0, len(groups))
+	for key, row := range groups {
+		row.Workers = len(workers[key])
+		finishEfficiencyRow(row)
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Efficiency != rows[j].Efficiency {
+			return rows[i].Efficiency > rows[j].Efficiency
+		}
+		if rows[i].EarningsPerHour != rows[j].EarningsPerHour {
+			return rows[i].EarningsPerHour > rows[j].EarningsPerHour
+		}
+		return rows[i].GroupName < rows[j].GroupName
+	})
+	for i, row := range rows {
+		row.Rank = i + 1
+	}
+	summary.Workers = len(allWorkers)
+	finishEfficiencyRow(summary)
+
+	return &dto.EfficiencyResponse{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		GroupBy:   req.GroupBy,
+		SelfOnly:  selfOnly,
+		Rows:      rows,
+		Summary:   summary,
+	}, nil
+}
+
+// GetEfficiencyTrend 效率趋势（按天）
+func (s *efficiencyService) GetEfficiencyTrend(ctx context.Context, req *dto.EfficiencyRequest) (*dto.EfficiencyTrendResponse, error) {
+	selfOnly, err := s.restrictToSelf(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if req.GroupID != "" {
+		switch req.GroupBy {
+		case "team":
+			req.TeamID = req.GroupID
+		case "workshop":
+			req.WorkshopID = req.GroupID
+		default:
+			if !selfOnly {
+				req.WorkerID = req.GroupID
+			}
+		}
+	}
+	days, err := s.loadWorkerDays(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := map[string]*dto.EfficiencyRow{}
+	for _, day := range days {
+		date := dateOf(day)
+		if byDate[date] == nil {
+			byDate[date] = &dto.EfficiencyRow{}
+		}
+		addWorkerDay(byDate[date], day)
+	}
+
+	// 补齐没有数据的日期，便于前端画图
+	start, end, _ := parseDateRange(req.StartDate, req.EndDate)
+	points := []*dto.EfficiencyTrendPoint{}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		row := byDate[date]
+		if row == nil {
+			row = &dto.EfficiencyRow{}
+		}
+		finishEfficiencyRow(row)
+		points = append(points, &dto.EfficiencyTrendPoint{
+			Date:            date,
+			Quantity:        row.Quantity,
+			EarnedMinutes:   row.EarnedMinutes,
+			AttendedMinutes: row.AttendedMinutes,
+			Efficiency:      row.Efficiency,
+			Earnings:        row.Earnings,
+			EarningsPerHour: row.EarningsPerHour,
+		})
+	}
+
+	return &dto.EfficiencyTrendResponse{
+		GroupBy:  req.GroupBy,
+		GroupID:  req.GroupID,
+		SelfOnly: selfOnly,
+		Points:   points,
+	}, nil
+}
+
+// SaveAttendances 录入出勤（仅主管）
+func (s *efficiencyService) SaveAttendances(ctx context.Context, req *dto.AttendanceSaveRequest) (int, error) {
+	supervisor, _, err := s.isSupervisor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !supervisor {
+		return 0, fmt.Errorf("无权录入出勤")
+	}
+	if len(req.Items) == 0 {
+		return 0, fmt.Errorf("出勤记录不能为空")
+	}
+
+	members := map[string]*models.TenantMember{}
+	for i, item := range req.Items {
+		if _, err := time.ParseInLocation("2006-01-02", item.WorkDate, time.Local); err != nil {
+			return 0, fmt.Errorf("第%d条出勤日期格式错误", i+1)
+		}
+		if item.Minutes < 0 || item.Minutes > 24*60 {
+			return 0, fmt.Errorf("第%d条出勤分钟数必须在0-1440之间", i+1)
+		}
+		if _, ok := members[item.WorkerID]; ok {
+			continue
+		}
+		member, err := s.memberRepo.GetByUserID(ctx, item.WorkerID)
+		if err != nil {
+			return 0, err
+		}
+		if member == nil {
+			return 0, fmt.Errorf("第%d条出勤的工人不存在", i+1)
+		}
+		members[item.WorkerID] = member
+	}
+
+	username := corecontext.GetUsername(ctx)
+	for _, item := range req.Items {
+		member := members[item.WorkerID]
+		attendance := &models.Attendance{
+			WorkerID:   item.WorkerID,
+			WorkerName: member.Name,
+			WorkerNo:   member.JobNumber,
+			TeamID:     member.TeamID,
+			Team:       member.Team,
+			WorkshopID: member.WorkshopID,
+			Workshop:   member.Workshop,
+			WorkDate:   item.WorkDate,
+			Minutes:    item.Minutes,
+			Remark:     item.Remark,
+			CreatedBy:  username,
+		}
+		if err := s.attendanceRepo.Save(ctx, attendance); err != nil {
+			return 0, err
+		}
+	}
+	return len(req.Items), nil
+}
+
+// ListAttendances 出勤列表（工人只能看自己的）
+func (s *efficiencyService) ListAttendances(ctx context.Context, req *dto.AttendanceListRequest) (*dto.AttendanceListResponse, error) {
+	supervisor, _, err := s.isSupervisor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.M{}
+	if !supervisor {
+		filter["worker_id"] = corecontext.GetUserID(ctx)
+	} else {
+		if req.WorkerID != "" {
+			filter["worker_id"] = req.WorkerID
+		}
+		if req.TeamID != "" {
+			filter["team_id"] = req.TeamID
+		}
+		if req.WorkshopID != "" {
+			filter["workshop_id"] = req.WorkshopID
+		}
+	}
+	if req.StartDate != "" || req.EndDate != "" {
+		dateFilter := bson.M{}
+		if req.StartDate != "" {
+			dateFilter["$gte"] = req.StartDate
+		}
+		if req.EndDate != "" {
+			dateFilter["$lte"] = req.EndDate
+		}
+		filter["work_date"] = dateFilter
+	}
+
+	attendances, err := s.attendanceRepo.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AttendanceListResponse{Attendances: attendances, Total: len(attendances)}, nil
+}
+
+// DeleteAttendance 删除出勤（仅主管）
+func (s *efficiencyService) DeleteAttendance(ctx context.Context, id string) error {
+	supervisor, _, err := s.isSupervisor(ctx)
+	if err != nil {
+		return err
+	}
+	if !supervisor {
+		return fmt.Errorf("无权删除出勤")
+	}
+	if err := s.attendanceRepo.Delete(ctx, id); err != nil {
+		if err == repository.ErrNotFound {
+			return fmt.Errorf("出勤记录不存在")
+		}
+		return err
+	}
+	return nil
+}
+
+// isSupervisor 判断当前用户能否查看全部工人数据
+//
+// 后台用户（不是租户成员）和拥有 EfficiencyViewAllPermission 权限的成员视为主管，
+// 其他成员（小程序工人）只能查看本人数据。
+func (s *efficiencyService) isSupervisor(ctx context.Context) (bool, *models.TenantMember, error) {
+	userID := corecontext.GetUserID(ctx)
+	if userID == "" {
+		return false, nil, fmt.Errorf("未登录")
+	}
+	member, err := s.memberRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, nil, err
+	}
+	if member == nil {
+		return true, nil, nil
+	}
+	for _, permission := range member.Permissions {
+		if permission == EfficiencyViewAllPermission {
+			return true, member, nil
+		}
+	}
+	return false, member, nil
+}
+
+// restrictToSelf 校验查询参数，工人只能按工人维度查看本人数据
+func (s *efficiencyService) restrictToSelf(ctx context.Context, req *dto.EfficiencyRequest) (bool, error) {
+	if _, _, err := parseDateRange(req.StartDate, req.EndDate); err != nil {
+		return false, err
+	}
+	switch req.GroupBy {
+	case "":
+		req.GroupBy = "worker"
+	case "worker", "team", "workshop":
+	default:
+		return false, fmt.Errorf("无效的统计维度: %s", req.GroupBy)
+	}
+
+	supervisor, _, err := s.isSupervisor(ctx)
+	if err != nil {
+		return false, err
+	}
+	if supervisor {
+		return false, nil
+	}
+	req.GroupBy = "worker"
+	req.GroupID = ""
+	req.WorkerID = corecontext.GetUserID(ctx)
+	req.TeamID = ""
+	req.WorkshopID = ""
+	return true, nil
+}
+
+// loadWorkerDays 读取时间范围内每个工人每天的产出和出勤
+func (s *efficiencyService) loadWorkerDays(ctx context.Context, req *dto.EfficiencyRequest) ([]*workerDay, error) {
+	start, end, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	reportFilter := bson.M{"report_time": bson.M{"$gte": start.Unix(), "$lte": end.AddDate(0, 0, 1).Unix() - 1}}
+	attendanceFilter := bson.M{"work_date": bson.M{"$gte": req.StartDate, "$lte": req.EndDate}}
+	for field, value := range map[string]string{"worker_id": req.WorkerID, "team_id": req.TeamID, "workshop_id": req.WorkshopID} {
+		if value != "" {
+			reportFilter[field] = value
+			attendanceFilter[field] = value
+		}
+	}
+
+	outputs, err := s.reportRepo.SumByWorkerDay(ctx, reportFilter)
+	if err != nil {
+		return nil, err
+	}
+	attendances, err := s.attendanceRepo.Find(ctx, attendanceFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]*workerDay{}
+	days := []*workerDay{}
+	for _, output := range outputs {
+		day := &workerDay{output: output}
+		index[output.WorkerID+"|"+output.Date] = day
+		days = append(days, day)
+	}
+	for _, attendance := range attendances {
+		if day, ok := index[attendance.WorkerID+"|"+attendance.WorkDate]; ok {
+			day.attendance = attendance
+			continue
+		}
+		days = append(days, &workerDay{attendance: attendance})
+	}
+	return days, nil
+}
+
+// groupOf 返回工人单日数据所属的分组（优先使用上报时的班组/车间快照）
+func groupOf(groupBy string, day *workerDay) (string, *dto.EfficiencyRow) {
+	var workerID, workerName, workerNo, teamID, team, workshopID, workshop string
+	if a := day.attendance; a != nil {
+		workerID, workerName, workerNo = a.WorkerID, a.WorkerName, a.WorkerNo
+		teamID, team, workshopID, workshop = a.TeamID, a.Team, a.WorkshopID, a.Workshop
+	}
+	if o := day.output; o != nil {
+		workerID, workerName = o.WorkerID, o.WorkerName
+		if o.WorkerNo != "" {
+			workerNo = o.WorkerNo
+		}
+		if o.TeamID != "" {
+			teamID, team = o.TeamID, o.Team
+		}
+		if o.WorkshopID != "" {
+			workshopID, workshop = o.WorkshopID, o.Workshop
+		}
+	}
+
+	switch groupBy {
+	case "team":
+		if teamID == "" {
+			team = "未分班组"
+		}
+		return teamID, &dto.EfficiencyRow{GroupID: teamID, GroupName: team, Workshop: workshop}
+	case "workshop":
+		if workshopID == "" {
+			workshop = "未分车间"
+		}
+		return workshopID, &dto.EfficiencyRow{GroupID: workshopID, GroupName: workshop}
+	default:
+		return workerID, &dto.EfficiencyRow{
+			GroupID:   workerID,
+			GroupName: workerName,
+			WorkerNo:  workerNo,
+			Team:      team,
+			Workshop:  workshop,
+		}
+	}
+}
+
+func workerIDOf(day *workerDay) string {
+	if day.output != nil {
+		return day.output.WorkerID
+	}
+	return day.attendance.WorkerID
+}
+
+func dateOf(day *workerDay) string {
+	if day.output != nil {
+		return day.output.Date
+	}
+	return day.attendance.WorkDate
+}
+
+// addWorkerDay 累加工人单日数据
+func addWorkerDay(row *dto.EfficiencyRow, day *workerDay) {
+	if o := day.output; o != nil {
+		row.Quantity += o.Quantity
+		row.EarnedMinutes += o.EarnedMinutes
+		row.Earnings += o.Amount
+	}
+	if a := day.attendance; a != nil && a.Minutes > 0 {
+		row.AttendedMinutes += float64(a.Minutes)
+		row.Days++
+	}
+}
+
+// finishEfficiencyRow 计算效率和时均收入（无出勤记录时为0）
+func finishEfficiencyRow(row *dto.EfficiencyRow) {
+	if row.AttendedMinutes > 0 {
+		row.Efficiency = round2(row.EarnedMinutes / row.AttendedMinutes * 100)
+		row.EarningsPerHour = round2(row.Earnings / (row.AttendedMinutes / 60))
+	}
+	row.EarnedMinutes = round2(row.EarnedMinutes)
+	row.Earnings = round2(row.Earnings)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// parseDateRange 解析统计区间（按服务器本地时区）
+func parseDateRange(startDate, endDate string) (time.Time, time.Time, error) {
+	if startDate == "" || endDate == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("请选择统计日期范围")
+	}
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("开始日期格式错误")
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("结束日期格式错误")
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("结束日期不能早于开始日期")
+	}
+	if end.Sub(start) > maxEfficiencyDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("统计区间不能超过%d天", maxEfficiencyDays)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"testing"
+
+	"mule-cloud/app/production/dto"
+	"mule-cloud/internal/models"
+)
+
+// TestEfficiencyRow 测试效率和时均收入的计算
+func TestEfficiencyRow(t *testing.T) {
+	output := func(qty int, earned, amount float64) *models.WorkerDayOutput {
+		return &models.WorkerDayOutput{WorkerID: "w1", Quantity: qty, EarnedMinutes: earned, Amount: amount}
+	}
+	attendance := func(minutes int) *models.Attendance {
+		return &models.Attendance{WorkerID: "w1", Minutes: minutes}
+	}
+
+	tests := []struct {
+		name           string
+		days           []*workerDay
+		wantDays       int
+		wantEfficiency float64
+		wantPerHour    float64
+		wantEarned     float64
+	}{
+		{
+			name:           "single day",
+			days:           []*workerDay{{output: output(100, 432, 86.4), attendance: attendance(480)}},
+			wantDays:       1,
+			wantEfficiency: 90,
+			wantPerHour:    10.8,
+			wantEarned:     432,
+		},
+		{
+			// 有出勤无产出的天数计入出勤工时，拉低效率
+			name: "day without output",
+			days: []*workerDay{
+				{output: output(100, 480, 96), attendance: attendance(480)},
+				{attendance: attendance(480)},
+			},
+			wantDays:       2,
+			wantEfficiency: 50,
+			wantPerHour:    6,
+			wantEarned:     480,
+		},
+		{
+			// 有产出无出勤记录时不计效率
+			name:       "output without attendance",
+			days:       []*workerDay{{output: output(10, 33.333, 5)}},
+			wantEarned: 33.33,
+		},
+		{
+			name:       "zero minutes attendance ignored",
+			days:       []*workerDay{{output: output(10, 60, 5), attendance: attendance(0)}},
+			wantEarned: 60,
+		},
+		{
+			name:           "over 100 percent",
+			days:           []*workerDay{{output: output(200, 600, 120), attendance: attendance(450)}},
+			wantDays:       1,
+			wantEfficiency: 133.33,
+			wantPerHour:    16,
+			wantEarned:     600,
+		},
+	}
+	for _, tt := range tests {
+		row := &dto.EfficiencyRow{}
+		for _, day := range tt.days {
+			addWorkerDay(row, day)
+		}
+		finishEfficiencyRow(row)
+		if row.Days != tt.wantDays || row.Efficiency != tt.wantEfficiency || row.EarningsPerHour != tt.wantPerHour || row.EarnedMinutes != tt.wantEarned {
+			t.Errorf("%s: days=%d efficiency=%v perHour=%v earned=%v, want %d %v %v %v",
+				tt.name, row.Days, row.Efficiency, row.EarningsPerHour, row.EarnedMinutes,
+				tt.wantDays, tt.wantEfficiency, tt.wantPerHour, tt.wantEarned)
+		}
+	}
+}
+
+// TestGroupOf 测试按工人/班组/车间分组（优先使用上报时的快照）
+func TestGroupOf(t *testing.T) {
+	day := &workerDay{
+		output: &models.WorkerDayOutput{WorkerID: "w1", WorkerName: "张三", TeamID: "t2", Team: "二组"},
+		attendance: &models.Attendance{
+			WorkerID: "w1", WorkerName: "张三", WorkerNo: "A001",
+			TeamID: "t1", Team: "一组", WorkshopID: "s1", Workshop: "缝制车间",
+		},
+	}
+
+	tests := []struct {
+		groupBy  string
+		day      *workerDay
+		wantID   string
+		wantName string
+	}{
+		{"worker", day, "w1", "张三"},
+		{"team", day, "t2", "二组"},
+		{"workshop", day, "s1", "缝制车间"},
+		{"team", &workerDay{attendance: &models.Attendance{WorkerID: "w2"}}, "", "未分班组"},
+		{"workshop", &workerDay{output: &models.WorkerDayOutput{WorkerID: "w2"}}, "", "未分车间"},
+	}
+	for _, tt := range tests {
+		id, row := groupOf(tt.groupBy, tt.day)
+		if id != tt.wantID || row.GroupName != tt.wantName {
+			t.Errorf("groupOf(%s) = %q %q, want %q %q", tt.groupBy, id, row.GroupName, tt.wantID, tt.wantName)
+		}
+	}
+	if _, row := groupOf("worker", day); row.WorkerNo != "A001" || row.Team != "二组" {
+		t.Errorf("worker row = %+v, want worker no from attendance and team from output", row)
+	}
+}
+
+// TestParseDateRange 测试统计区间校验
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		start, end string
+		wantErr    bool
+	}{
+		{"2025-01-01", "2025-01-31", false},
+		{"2025-01-01", "2025-01-01", false},
+		{"", "2025-01-31", true},
+		{"2025/01/01", "2025-01-31", true},
+		{"2025-02-01", "2025-01-31", true},
+		{"2024-01-01", "2025-01-01", false}, // 366天
+		{"2024-01-01", "2025-01-02", true},
+	}
+	for _, tt := range tests {
+		if _, _, err := parseDateRange(tt.start, tt.end); (err != nil) != tt.wantErr {
+			t.Errorf("parseDateRange(%s, %s) error = %v, wantErr %v", tt.start, tt.end, err, tt.wantErr)
+		}
+	}
+}
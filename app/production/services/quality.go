@@ -121,9 +121,9 @@ func (s *qualityService) GetInspectionList(ctx context.Context, req *dto.Inspect
 		pageSize = 20
 	}
 
-	// 如果没有指定质检员ID，使用当前登录用户
+	// 如果没有指定质检员ID，使用当前登录用户（查看全部时按数据范围过滤）
 	inspectorID := req.InspectorID
-	if inspectorID == "" {
+	if inspectorID == "" && !req.All {
 		inspectorID = corecontext.GetUserID(ctx)
 	}
 
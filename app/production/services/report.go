@@ -8,10 +8,13 @@ import (
 	"mule-cloud/app/order/services"
 	"mule-cloud/app/production/dto"
 	corecontext "mule-cloud/core/context"
+	"mule-cloud/core/metrics"
+	"mule-cloud/core/tracing"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.uber.org/zap"
 )
 
 // IReportService 工序上报服务接口
@@ -38,6 +41,7 @@ type reportService struct {
 	orderProgressRepo repository.OrderProcedureProgressRepository
 	cuttingPieceRepo  repository.CuttingPieceRepository
 	cuttingBatchRepo  repository.CuttingBatchRepository
+	memberRepo        repository.TenantMemberRepository
 	workflowEngine    services.IWorkflowEngineService
 }
 
@@ -50,6 +54,7 @@ func NewReportService() IReportService {
 		orderProgressRepo: repository.NewOrderProcedureProgressRepository(),
 		cuttingPieceRepo:  repository.NewCuttingPieceRepository(),
 		cuttingBatchRepo:  repository.NewCuttingBatchRepository(),
+		memberRepo:        repository.NewTenantMemberRepository(),
 		workflowEngine:    services.NewWorkflowEngineService(),
 	}
 }
@@ -131,6 +136,9 @@ func (s *reportService) SubmitReport(ctx context.Context, req *dto.ProcedureRepo
 	// 计算工资
 	totalPrice := float64(req.Quantity) * procedure.UnitPrice
 
+	// 班组、车间快照（效率报表按上报时的归属统计）
+	member, _ := s.memberRepo.GetByUserID(ctx, userID)
+
 	// 创建上报记录
 	report := &models.ProcedureReport{
 		ID:            bson.NewObjectID().Hex(),
@@ -147,6 +155,8 @@ func (s *reportService) SubmitReport(ctx context.Context, req *dto.ProcedureRepo
 		ProcedureName: req.ProcedureName,
 		UnitPrice:     procedure.UnitPrice,
 		TotalPrice:    totalPrice,
+		SAM:           procedure.SAM,
+		EarnedMinutes: float64(req.Quantity) * procedure.SAM,
 		WorkerID:      userID,
 		WorkerName:    username,
 		WorkerNo:      "", // 工号可从其他地方获取或留空
@@ -157,11 +167,23 @@ func (s *reportService) SubmitReport(ctx context.Context, req *dto.ProcedureRepo
 		UpdatedAt:     time.Now().Unix(),
 	}
 
+	if member != nil {
+		report.WorkerName = member.Name
+		report.WorkerNo = member.JobNumber
+		report.TeamID = member.TeamID
+		report.Team = member.Team
+		report.WorkshopID = member.WorkshopID
+		report.Workshop = member.Workshop
+	}
+
 	// 保存上报记录
 	err = s.reportRepo.Create(ctx, report)
 	if err != nil {
 		return nil, fmt.Errorf("保存上报记录失败: %v", err)
 	}
+	tenantCode := corecontext.GetTenantCode(ctx)
+	metrics.ReportsSubmitted.WithLabelValues(tenantCode).Inc()
+	metrics.ReportedPieces.WithLabelValues(tenantCode).Add(float64(req.Quantity))
 
 	// 更新批次工序进度（如果有批次）
 	if req.BatchID != "" {
@@ -175,14 +197,12 @@ func (s *reportService) SubmitReport(ctx context.Context, req *dto.ProcedureRepo
 	if req.BundleNo != "" && bedNo != "" {
 		err = s.cuttingPieceRepo.IncrementProgressByBundleNo(ctx, bedNo, req.BundleNo)
 		if err != nil {
-			fmt.Printf("⚠️ 更新裁片进度失败: %v\n", err)
+			log.Ctx(ctx).Warn("更新裁片进度失败", zap.String("bundle_no", req.BundleNo), zap.Error(err))
 		} else {
 			// 🔥 重要：裁片进度更新后，需要触发订单进度计算和工作流状态更新
-			// 创建新的context，保留租户信息但不受原始请求超时限制
-			tenantCode := corecontext.GetTenantCode(ctx)
-			bgCtx := corecontext.WithTenantCode(context.Background(), tenantCode)
-
-			fmt.Printf("🚀 触发订单进度更新: 订单=%s, 租户=%s\n", order.ID, tenantCode)
+			// 创建新的context，保留租户信息和调用链但不受原始请求超时限制
+			bgCtx := corecontext.WithTenantCode(tracing.Detach(ctx), tenantCode)
+			log.Ctx(bgCtx).Debug("触发订单进度更新", zap.String("order_id", order.ID))
 
 			// 使用goroutine异步处理，避免阻塞上报响应
 			go s.updateOrderProgressFromPieces(bgCtx, order.ID, order.ContractNo)
@@ -206,7 +226,7 @@ func (s *reportService) updateOrderProgressFromPieces(ctx context.Context, order
 	// 1. 获取所有裁片的进度
 	pieces, _, err := s.cuttingPieceRepo.List(ctx, 1, 10000, orderID, contractNo, "", "")
 	if err != nil || len(pieces) == 0 {
-		fmt.Printf("❌ 获取裁片列表失败: %v\n", err)
+		log.Ctx(ctx).Error("获取裁片列表失败", zap.String("order_id", orderID), zap.Error(err))
 		return
 	}
 
@@ -230,8 +250,13 @@ func (s *reportService) updateOrderProgressFromPieces(ctx context.Context, order
 		orderProgress = totalWeightedProgress / float64(totalQuantity)
 	}
 
-	fmt.Printf("📊 订单进度计算（基于裁片）: 订单=%s, 总件数=%d, 已完成=%d/%d, 进度=%.2f%%\n",
-		orderID, totalQuantity, completedCount, len(pieces), orderProgress*100)
+	log.Ctx(ctx).Info("订单进度计算（基于裁片）",
+		zap.String("order_id", orderID),
+		zap.Int("total_quantity", totalQuantity),
+		zap.Int("completed_pieces", completedCount),
+		zap.Int("pieces", len(pieces)),
+		zap.Float64("progress", orderProgress),
+	)
 
 	// 3. 更新订单进度字段
 	// 注意：orderRepo.Update 方法内部会自动包装 $set，这里直接传字段即可
@@ -240,7 +265,7 @@ func (s *reportService) updateOrderProgressFromPieces(ctx context.Context, order
 		"updated_at": time.Now().Unix(),
 	})
 	if err != nil {
-		fmt.Printf("❌ 更新订单进度失败: %v\n", err)
+		log.Ctx(ctx).Error("更新订单进度失败", zap.String("order_id", orderID), zap.Error(err))
 		return
 	}
 
@@ -253,36 +278,17 @@ func (s *reportService) triggerWorkflowByProgress(ctx context.Context, orderID s
 	// 获取订单当前状态
 	order, err := s.orderRepo.Get(ctx, orderID)
 	if err != nil {
-		fmt.Printf("❌ 获取订单失败: %v\n", err)
+		log.Ctx(ctx).Error("获取订单失败", zap.String("order_id", orderID), zap.Error(err))
 		return
 	}
 
-	// 如果进度达到100%且当前状态是"生产中"，自动完成订单
+	// 进度达到100%只表示生产完成，订单完成由发货服务在全部发货后触发
 	if orderProgress >= 1.0 && order.Status == 2 { // 2 = 生产中
-		fmt.Printf("✅ 订单 %s 进度已达100%%，自动触发完成事件\n", orderID)
-
-		err = s.workflowEngine.TransitionOrderState(
-			ctx,
-			orderID,
-			"complete", // 事件：完成
-			"system",   // 操作者：系统自动
-			"所有裁片已完成",  // 原因
-			map[string]interface{}{
-				"progress":        orderProgress,
-				"completed_count": completedCount,
-				"total_pieces":    totalPieces,
-			},
-		)
-
-		if err != nil {
-			fmt.Printf("❌ 自动完成订单失败: %v\n", err)
-		} else {
-			fmt.Printf("🎉 订单 %s 已自动完成！\n", orderID)
-		}
+		log.Ctx(ctx).Info("订单生产进度已达100%，等待装箱发货", zap.String("order_id", orderID), zap.Int("pieces", totalPieces))
 	} else {
 		// 如果订单还在"草稿"或"已下单"状态，但已经有进度了，应该转换到"生产中"
 		if orderProgress > 0 && (order.Status == 0 || order.Status == 1) { // 0=草稿, 1=已下单
-			fmt.Printf("📌 订单 %s 有进度了(%.2f%%)，尝试转换到生产中状态\n", orderID, orderProgress*100)
+			log.Ctx(ctx).Info("订单已有进度，尝试转换到生产中状态", zap.String("order_id", orderID), zap.Float64("progress", orderProgress))
 
 			// 根据当前状态选择合适的事件
 			event := "start_production"
@@ -293,15 +299,15 @@ func (s *reportService) triggerWorkflowByProgress(ctx context.Context, orderID s
 
 			err = s.workflowEngine.TransitionOrderState(ctx, orderID, event, "system", "工序上报自动触发", nil)
 			if err != nil {
-				fmt.Printf("⚠️ 转换状态失败: %v\n", err)
+				log.Ctx(ctx).Warn("转换订单状态失败", zap.String("order_id", orderID), zap.String("event", event), zap.Error(err))
 			} else {
-				fmt.Printf("✅ 订单 %s 状态已更新 (事件: %s)\n", orderID, event)
+				log.Ctx(ctx).Info("订单状态已更新", zap.String("order_id", orderID), zap.String("event", event))
 
 				// 如果是从草稿提交，还需要再转换到生产中
 				if event == "submit_order" {
 					err = s.workflowEngine.TransitionOrderState(ctx, orderID, "start_production", "system", "工序上报自动触发", nil)
 					if err != nil {
-						fmt.Printf("⚠️ 转换到生产中状态失败: %v\n", err)
+						log.Ctx(ctx).Warn("转换到生产中状态失败", zap.String("order_id", orderID), zap.Error(err))
 					}
 				}
 			}
@@ -331,7 +337,7 @@ func (s *reportService) updateOrderProgress(ctx context.Context, orderID string)
 		"updated_at": time.Now().Unix(),
 	})
 
-	fmt.Printf("📊 订单进度更新（基于工序）: 订单=%s, 进度=%.2f%%\n", orderID, newProgress*100)
+	log.Ctx(ctx).Info("订单进度更新（基于工序）", zap.String("order_id", orderID), zap.Float64("progress", newProgress))
 }
 
 // GetReportList 获取上报记录列表
@@ -346,9 +352,9 @@ func (s *reportService) GetReportList(ctx context.Context, req *dto.ReportListRe
 		pageSize = 20
 	}
 
-	// 如果没有指定工人ID，使用当前登录工人
+	// 如果没有指定工人ID，使用当前登录工人（班组长等查看全部时按数据范围过滤）
 	workerID := req.WorkerID
-	if workerID == "" {
+	if workerID == "" && !req.All {
 		workerID = corecontext.GetUserID(ctx)
 	}
 
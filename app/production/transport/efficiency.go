@@ -0,0 +1,111 @@
+package transport
+
+import (
+	"mule-cloud/app/production/dto"
+	"mule-cloud/app/production/endpoint"
+	"mule-cloud/app/production/services"
+	"mule-cloud/core/binding"
+	"mule-cloud/core/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetEfficiencyHandler 效率报表处理器
+func GetEfficiencyHandler(svc services.IEfficiencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.EfficiencyRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.GetEfficiencyEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// GetEfficiencyTrendHandler 效率趋势处理器
+func GetEfficiencyTrendHandler(svc services.IEfficiencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.EfficiencyRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.GetEfficiencyTrendEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// SaveAttendancesHandler 录入出勤处理器
+func SaveAttendancesHandler(svc services.IEfficiencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.AttendanceSaveRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.SaveAttendancesEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// ListAttendancesHandler 出勤列表处理器
+func ListAttendancesHandler(svc services.IEfficiencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.AttendanceListRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+
+		ep := endpoint.ListAttendancesEndpoint(svc)
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
+
+// DeleteAttendanceHandler 删除出勤处理器
+func DeleteAttendanceHandler(svc services.IEfficiencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			response.Error(c, "出勤记录ID不能为空")
+			return
+		}
+
+		ep := endpoint.DeleteAttendanceEndpoint(svc)
+		resp, err := ep(c.Request.Context(), id)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		response.Success(c, resp)
+	}
+}
@@ -0,0 +1,36 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package transport
+
+import (
+	"mule-cloud/app/production/dto"
+	"mule-cloud/core/openapi"
+	"mule-cloud/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operations 处理器的接口描述（cmd/openapi-gen 生成，服务的 /openapi.json 使用）
+var Operations = openapi.Operations{
+	"mule-cloud/app/production/transport.GetEfficiencyHandler":      {Summary: "效率报表", Tag: "efficiency", Request: dto.EfficiencyRequest{}, Response: dto.EfficiencyResponse{}},
+	"mule-cloud/app/production/transport.GetEfficiencyTrendHandler": {Summary: "效率趋势", Tag: "efficiency", Request: dto.EfficiencyRequest{}, Response: dto.EfficiencyTrendResponse{}},
+	"mule-cloud/app/production/transport.SaveAttendancesHandler":    {Summary: "录入出勤", Tag: "efficiency", Request: dto.AttendanceSaveRequest{}, Response: map[string]interface{}{}},
+	"mule-cloud/app/production/transport.ListAttendancesHandler":    {Summary: "出勤列表", Tag: "efficiency", Request: dto.AttendanceListRequest{}, Response: dto.AttendanceListResponse{}},
+	"mule-cloud/app/production/transport.DeleteAttendanceHandler":   {Summary: "删除出勤", Tag: "efficiency", Response: map[string]interface{}{}},
+	"mule-cloud/app/production/transport.SubmitInspectionHandler":   {Summary: "提交质检", Tag: "quality", Request: dto.InspectionRequest{}, Bind: openapi.FromBody, Response: dto.InspectionResponse{}, Raw: true},
+	"mule-cloud/app/production/transport.GetInspectionListHandler":  {Summary: "获取质检列表", Tag: "quality", Request: dto.InspectionListRequest{}, Bind: openapi.FromQuery, Response: dto.InspectionListResponse{}, Raw: true},
+	"mule-cloud/app/production/transport.GetInspectionHandler":      {Summary: "获取质检详情", Tag: "quality", Response: dto.InspectionItem{}, Raw: true},
+	"mule-cloud/app/production/transport.DeleteInspectionHandler":   {Summary: "删除质检记录", Tag: "quality", Response: gin.H{}, Raw: true},
+	"mule-cloud/app/production/transport.SubmitReportHandler":       {Summary: "工序上报", Tag: "report", Request: dto.ProcedureReportRequest{}, Response: dto.ProcedureReportResponse{}},
+	"mule-cloud/app/production/transport.GetReportListHandler":      {Summary: "上报记录列表", Tag: "report", Request: dto.ReportListRequest{}, Response: dto.ReportListResponse{}},
+	"mule-cloud/app/production/transport.GetReportByIDHandler":      {Summary: "获取上报记录详情", Tag: "report", Response: models.ProcedureReport{}},
+	"mule-cloud/app/production/transport.DeleteReportHandler":       {Summary: "删除上报记录", Tag: "report", Response: map[string]interface{}{}},
+	"mule-cloud/app/production/transport.GetOrderProgressHandler":   {Summary: "获取订单进度", Tag: "report", Response: dto.OrderProgressResponse{}},
+	"mule-cloud/app/production/transport.GetSalaryHandler":          {Summary: "获取工资统计", Tag: "report", Request: dto.SalaryRequest{}, Response: dto.SalaryResponse{}},
+	"mule-cloud/app/production/transport.CreateReworkHandler":       {Summary: "创建返工单", Tag: "rework", Request: dto.ReworkRequest{}, Bind: openapi.FromBody, Response: dto.ReworkResponse{}, Raw: true},
+	"mule-cloud/app/production/transport.GetReworkListHandler":      {Summary: "获取返工列表", Tag: "rework", Request: dto.ReworkListRequest{}, Bind: openapi.FromQuery, Response: dto.ReworkListResponse{}, Raw: true},
+	"mule-cloud/app/production/transport.GetReworkHandler":          {Summary: "获取返工详情", Tag: "rework", Response: dto.ReworkItem{}, Raw: true},
+	"mule-cloud/app/production/transport.CompleteReworkHandler":     {Summary: "完成返工", Tag: "rework", Request: dto.CompleteReworkRequest{}, Bind: openapi.FromBody, Response: gin.H{}, Raw: true},
+	"mule-cloud/app/production/transport.DeleteReworkHandler":       {Summary: "删除返工记录", Tag: "rework", Response: gin.H{}, Raw: true},
+	"mule-cloud/app/production/transport.ParseScanCodeHandler":      {Summary: "扫码解析", Tag: "scan", Request: dto.ScanCodeRequest{}, Response: dto.ScanCodeResponse{}},
+}
@@ -4,16 +4,17 @@ import "mule-cloud/internal/models"
 
 // OperationLogListRequest 操作日志列表请求
 type OperationLogListRequest struct {
-	UserID       string `form:"user_id"`       // 用户ID过滤
-	Username     string `form:"username"`      // 用户名过滤（模糊查询）
-	Method       string `form:"method"`        // HTTP方法过滤
-	Resource     string `form:"resource"`      // 资源名称过滤（模糊查询）
-	Action       string `form:"action"`        // 操作类型过滤
-	StartTime    int64  `form:"start_time"`    // 开始时间（Unix时间戳）
-	EndTime      int64  `form:"end_time"`      // 结束时间（Unix时间戳）
-	ResponseCode *int   `form:"response_code"` // 响应状态码过滤
-	Page         int    `form:"page" binding:"required,min=1"`
-	PageSize     int    `form:"page_size" binding:"required,min=1,max=100"`
+	UserID          string `form:"user_id"`          // 用户ID过滤
+	Username        string `form:"username"`         // 用户名过滤（模糊查询）
+	Method          string `form:"method"`           // HTTP方法过滤
+	Resource        string `form:"resource"`         // 资源名称过滤（模糊查询）
+	Action          string `form:"action"`           // 操作类型过滤
+	StartTime       int64  `form:"start_time"`       // 开始时间（Unix时间戳）
+	EndTime         int64  `form:"end_time"`         // 结束时间（Unix时间戳）
+	ResponseCode    *int   `form:"response_code"`    // 响应状态码过滤
+	ImpersonationID string `form:"impersonation_id"` // 代管会话ID过滤（查看超管代管期间的操作）
+	Page            int    `form:"page" binding:"required,min=1"`
+	PageSize        int    `form:"page_size" binding:"required,min=1,max=100"`
 }
 
 // OperationLogDetailRequest 操作日志详情请求
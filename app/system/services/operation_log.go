@@ -67,6 +67,11 @@ func (s *operationLogService) List(ctx context.Context, req dto.OperationLogList
 		filter["response_code"] = *req.ResponseCode
 	}
 
+	// 代管会话过滤
+	if req.ImpersonationID != "" {
+		filter["impersonation_id"] = req.ImpersonationID
+	}
+
 	// 时间范围过滤
 	if req.StartTime > 0 || req.EndTime > 0 {
 		timeFilter := bson.M{}
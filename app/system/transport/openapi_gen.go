@@ -0,0 +1,15 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package transport
+
+import (
+	"mule-cloud/app/system/dto"
+	"mule-cloud/core/openapi"
+)
+
+// Operations 处理器的接口描述（cmd/openapi-gen 生成，服务的 /openapi.json 使用）
+var Operations = openapi.Operations{
+	"mule-cloud/app/system/transport.ListOperationLogsHandler":  {Summary: "操作日志列表", Tag: "operation_log", Request: dto.OperationLogListRequest{}, Response: dto.OperationLogListResponse{}},
+	"mule-cloud/app/system/transport.GetOperationLogHandler":    {Summary: "操作日志详情", Tag: "operation_log", Request: dto.OperationLogDetailRequest{}, Bind: openapi.FromURI, Response: dto.OperationLogDetailResponse{}},
+	"mule-cloud/app/system/transport.StatsOperationLogsHandler": {Summary: "操作日志统计", Tag: "operation_log", Request: dto.OperationLogStatsRequest{}, Response: dto.OperationLogStatsResponse{}},
+}
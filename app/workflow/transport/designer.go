@@ -245,7 +245,7 @@ func GetWorkflowTemplatesHandler() gin.HandlerFunc {
 					Name:        "已完成",
 					Type:        "end",
 					Color:       "#67C23A",
-					Description: "订单已完成，进度达到100%",
+					Description: "订单已完成，全部发货",
 				},
 				{
 					Code:        "cancelled",
@@ -286,8 +286,14 @@ func GetWorkflowTemplatesHandler() gin.HandlerFunc {
 					Event:         "complete",
 					EventLabel:    "完成",
 					HasCondition:  true,
-					ConditionDesc: "进度必须达到100%",
+					ConditionDesc: "订单必须全部发货",
 					AvailableFields: []dto.WorkflowConditionField{
+						{
+							Key:         "ship_progress",
+							Label:       "发货进度",
+							Type:        "number",
+							Description: "订单的发货进度（0-1之间的小数），全部发货为1",
+						},
 						{
 							Key:         "progress",
 							Label:       "生产进度",
@@ -0,0 +1,33 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package transport
+
+import (
+	"mule-cloud/app/workflow/dto"
+	"mule-cloud/core/openapi"
+	"mule-cloud/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operations 处理器的接口描述（cmd/openapi-gen 生成，服务的 /openapi.json 使用）
+var Operations = openapi.Operations{
+	"mule-cloud/app/workflow/transport.CreateWorkflowDefinitionHandler":     {Summary: "创建工作流定义", Tag: "designer", Request: dto.WorkflowDefinitionRequest{}, Bind: openapi.FromBody, Response: models.WorkflowDefinition{}},
+	"mule-cloud/app/workflow/transport.UpdateWorkflowDefinitionHandler":     {Summary: "更新工作流定义", Tag: "designer", Request: dto.WorkflowDefinitionRequest{}, Bind: openapi.FromBody, Response: gin.H{}},
+	"mule-cloud/app/workflow/transport.GetDesignerDefinitionHandler":        {Summary: "获取工作流定义", Tag: "designer", Response: models.WorkflowDefinition{}},
+	"mule-cloud/app/workflow/transport.ListWorkflowDefinitionsHandler":      {Summary: "获取工作流定义列表", Tag: "designer", Request: dto.WorkflowListRequest{}, Bind: openapi.FromQuery, Response: dto.WorkflowListResponse{}},
+	"mule-cloud/app/workflow/transport.DeleteWorkflowDefinitionHandler":     {Summary: "删除工作流定义", Tag: "designer", Response: gin.H{}},
+	"mule-cloud/app/workflow/transport.ActivateWorkflowDefinitionHandler":   {Summary: "激活工作流定义", Tag: "designer", Response: gin.H{}},
+	"mule-cloud/app/workflow/transport.DeactivateWorkflowDefinitionHandler": {Summary: "停用工作流定义", Tag: "designer", Response: gin.H{}},
+	"mule-cloud/app/workflow/transport.GetWorkflowInstanceHandler":          {Summary: "获取工作流实例", Tag: "designer", Response: models.WorkflowInstance{}},
+	"mule-cloud/app/workflow/transport.ExecuteTransitionHandler":            {Summary: "执行工作流转换", Tag: "designer", Request: dto.ExecuteTransitionRequest{}, Bind: openapi.FromBody, Response: gin.H{}},
+	"mule-cloud/app/workflow/transport.GetWorkflowTemplatesHandler":         {Summary: "获取工作流模板列表", Tag: "designer", Response: dto.WorkflowTemplateResponse{}},
+	"mule-cloud/app/workflow/transport.GetWorkflowDefinitionHandler":        {Summary: "获取工作流定义", Tag: "workflow", Response: map[string]interface{}{}},
+	"mule-cloud/app/workflow/transport.GetMermaidDiagramHandler":            {Summary: "获取 Mermaid 流程图", Tag: "workflow", Response: dto.MermaidDiagramResponse{}},
+	"mule-cloud/app/workflow/transport.GetTransitionRulesHandler":           {Summary: "获取所有转换规则", Tag: "workflow", Response: gin.H{}},
+	"mule-cloud/app/workflow/transport.GetOrderStatusHandler":               {Summary: "获取订单当前状态", Tag: "workflow", Response: dto.OrderStatusResponse{}},
+	"mule-cloud/app/workflow/transport.GetOrderHistoryHandler":              {Summary: "获取订单状态历史", Tag: "workflow", Response: gin.H{}},
+	"mule-cloud/app/workflow/transport.GetRollbackHistoryHandler":           {Summary: "获取回滚历史", Tag: "workflow", Response: gin.H{}},
+	"mule-cloud/app/workflow/transport.TransitionOrderHandler":              {Summary: "执行状态转换", Tag: "workflow", Request: dto.TransitionRequest{}, Bind: openapi.FromBody, Response: gin.H{}},
+	"mule-cloud/app/workflow/transport.RollbackOrderHandler":                {Summary: "回滚订单状态", Tag: "workflow", Request: dto.RollbackRequest{}, Bind: openapi.FromBody, Response: gin.H{}},
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -10,13 +11,20 @@ import (
 	cfgPkg "mule-cloud/core/config"
 	"mule-cloud/core/cousul"
 	dbPkg "mule-cloud/core/database"
+	gatewayauthPkg "mule-cloud/core/gatewayauth"
 	jwtPkg "mule-cloud/core/jwt"
 	loggerPkg "mule-cloud/core/logger"
+	metricsPkg "mule-cloud/core/metrics"
+	openapiPkg "mule-cloud/core/openapi"
+	passwordPkg "mule-cloud/core/password"
 	"mule-cloud/core/response"
+	securityPkg "mule-cloud/core/security"
+	tracingPkg "mule-cloud/core/tracing"
 
 	"mule-cloud/app/auth/services"
 	"mule-cloud/app/auth/transport"
 	"mule-cloud/core/middleware"
+	"mule-cloud/internal/repository"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -34,13 +42,32 @@ func main() {
 	}
 
 	// 初始化日志系统
-	if err := loggerPkg.InitLogger(&cfg.Log); err != nil {
+	if err := loggerPkg.InitLogger(&cfg.Log, cfg.Server.Name); err != nil {
 		log.Fatalf("初始化日志系统失败: %v", err)
 	}
 	defer loggerPkg.Close()
 
+	// 初始化网关身份头签名（网关签名，服务校验）
+	if err := gatewayauthPkg.Init(&cfg.GatewayAuth); err != nil {
+		loggerPkg.Fatal("初始化网关身份签名失败", zap.Error(err))
+	}
+	if err := gatewayauthPkg.RequireSecret(cfg.Server.Mode); err != nil {
+		loggerPkg.Fatal("网关身份签名未配置", zap.Error(err))
+	} else if !gatewayauthPkg.Enabled() {
+		loggerPkg.Warn("未配置 gateway_auth.secret，身份头不签名也不校验，只能用于本地开发")
+	}
+
+	// 初始化链路追踪（未启用时只传递 traceparent 和 X-Request-ID）
+	shutdownTracing, err := tracingPkg.Init(&cfg.Tracing, cfg.Server.Name)
+	if err != nil {
+		loggerPkg.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
+
+	// 初始化密码哈希参数与密码策略
+	passwordPkg.Init(&cfg.Password)
+
 	loggerPkg.Info("🚀 AuthService 启动中...",
-		zap.String("service", cfg.Server.Name),
 		zap.Int("port", cfg.Server.Port),
 	)
 
@@ -52,6 +79,10 @@ func main() {
 		}
 		dbPkg.InitDatabaseManager(client)
 		loggerPkg.Info("✅ DatabaseManager初始化成功（支持多租户数据库隔离）")
+
+		if err := repository.NewImpersonationRepository().CreateIndexes(context.Background()); err != nil {
+			loggerPkg.Warn("创建代管记录索引失败", zap.Error(err))
+		}
 	}
 
 	// 初始化Redis（如果启用）
@@ -62,33 +93,68 @@ func main() {
 		defer cachePkg.CloseRedis()
 	}
 
-	// 初始化JWT管理器
-	jwtManager := jwtPkg.NewJWTManager(
-		[]byte(cfg.JWT.SecretKey),
-		time.Duration(cfg.JWT.ExpireTime)*time.Hour,
-	)
+	// 初始化JWT管理器（配置了 access_expire_minutes 时签发短期访问令牌）
+	accessTTL := time.Duration(cfg.JWT.ExpireTime) * time.Hour
+	if cfg.JWT.AccessExpireMinutes > 0 {
+		accessTTL = time.Duration(cfg.JWT.AccessExpireMinutes) * time.Minute
+	}
+	jwtManager, err := jwtPkg.NewFromConfig(&cfg.JWT, accessTTL)
+	if err != nil {
+		loggerPkg.Fatal("初始化JWT签名密钥失败", zap.Error(err))
+	}
+
+	// 刷新令牌有效期（默认7天）
+	refreshTTL := 7 * 24 * time.Hour
+	if cfg.JWT.RefreshExpireTime > 0 {
+		refreshTTL = time.Duration(cfg.JWT.RefreshExpireTime) * time.Hour
+	}
+
+	// 客户端IP只采信可信代理（网关）转发的 X-Real-IP；未配置时取到的都是网关地址，
+	// 所有用户共用一个IP计数会互相触发验证码和锁定，因此关闭按IP的登录限制
+	if len(cfg.Server.TrustedProxies) == 0 {
+		loggerPkg.Warn("未配置 server.trusted_proxies，无法识别客户端IP，已关闭按IP的登录失败限制")
+		cfg.Login.IPMaxFailures = -1
+	}
 
 	// 初始化认证服务
-	authSvc := services.NewAuthService(jwtManager)
+	authSvc := services.NewAuthService(jwtManager, refreshTTL, securityPkg.NewLoginGuard(&cfg.Login), &cfg.TwoFactor, &cfg.Impersonation)
 
 	// 初始化路由
 	gin.SetMode(cfg.Server.Mode)
 	r := gin.New()
+	r.RemoteIPHeaders = []string{"X-Real-IP"}
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		loggerPkg.Fatal("可信代理配置无效", zap.Error(err))
+	}
 
 	// 全局中间件
 	r.Use(gin.Logger())
+	r.Use(tracingPkg.Middleware())
+	r.Use(metricsPkg.Middleware())
 	r.Use(response.RecoveryMiddleware())
 	r.Use(response.UnifiedResponseMiddleware())
 	r.Use(middleware.OperationLogMiddleware())
+	r.GET("/metrics", metricsPkg.Handler()) // Prometheus 指标
 	// 公开路由（不需要认证）
 	public := r.Group("/auth")
 	{
 		public.POST("/login", transport.LoginHandler(authSvc))
+		public.POST("/login/2fa", transport.LoginTwoFactorHandler(authSvc)) // 登录第二步（两步验证）
+		public.GET("/sso/:tenant_code", transport.StartSSOHandler(authSvc)) // 发起企业单点登录（OIDC）
+		public.POST("/sso/callback", transport.LoginSSOHandler(authSvc))    // 单点登录回调
 		public.POST("/register", transport.RegisterHandler(authSvc))
 		public.POST("/refresh", transport.RefreshTokenHandler(authSvc))
-		public.GET("/tenants", transport.GetTenantListHandler(authSvc)) // 获取租户列表
+		public.GET("/captcha", transport.GetCaptchaHandler(authSvc))         // 登录图形验证码
+		public.GET("/.well-known/jwks.json", transport.JWKSHandler(authSvc)) // 令牌签名公钥
+		public.GET("/tenants", transport.GetTenantListHandler(authSvc))      // 获取租户列表
 	}
 
+	// 日志级别管理（超级管理员，运行时按模块调整）
+	middleware.ApplyLogLevels(r, jwtManager)
+
+	// OpenAPI 文档（按注册的路由和 DTO 生成，网关聚合后在 /gateway/openapi.json 提供）
+	r.GET("/openapi.json", openapiPkg.Handler(r, openapiPkg.Info{Title: cfg.Server.Name}, transport.Operations))
+
 	// 需要认证的路由
 	protected := r.Group("/auth")
 	middleware.Apply(protected, jwtManager) // ✅ 一个函数搞定
@@ -96,7 +162,22 @@ func main() {
 		protected.GET("/profile", transport.GetProfileHandler(authSvc))
 		protected.PUT("/profile", transport.UpdateProfileHandler(authSvc))
 		protected.POST("/password", transport.ChangePasswordHandler(authSvc))
-		protected.GET("/getUserRoutes", transport.GetUserRoutesHandler(authSvc)) // 获取用户路由
+		protected.POST("/logout", transport.LogoutHandler(authSvc))                // 退出登录
+		protected.GET("/sessions", transport.ListSessionsHandler(authSvc))         // 当前用户的登录会话
+		protected.DELETE("/sessions/:id", transport.RevokeSessionHandler(authSvc)) // 注销指定会话
+		protected.GET("/getUserRoutes", transport.GetUserRoutesHandler(authSvc))   // 获取用户路由
+
+		// 两步验证（TOTP）
+		protected.GET("/2fa", transport.GetTwoFactorStatusHandler(authSvc))
+		protected.POST("/2fa/setup", transport.SetupTwoFactorHandler(authSvc))                   // 生成密钥和绑定二维码地址
+		protected.POST("/2fa/enable", transport.EnableTwoFactorHandler(authSvc))                 // 提交验证码确认绑定
+		protected.POST("/2fa/disable", transport.DisableTwoFactorHandler(authSvc))               // 关闭
+		protected.POST("/2fa/recovery-codes", transport.RegenerateRecoveryCodesHandler(authSvc)) // 重新生成恢复码
+
+		// 超管代管租户（限时令牌，代管期间的请求记录到系统库和租户库）
+		protected.POST("/impersonations", transport.StartImpersonationHandler(authSvc))       // 申请代管（系统超管）
+		protected.POST("/impersonations/:id/end", transport.EndImpersonationHandler(authSvc)) // 提前结束代管
+		protected.GET("/impersonations", transport.ListImpersonationsHandler(authSvc))        // 代管记录（租户管理员查看本租户）
 	}
 
 	// 健康检查（不需要认证）
@@ -120,7 +201,7 @@ func main() {
 		}
 
 		loggerPkg.Info("准备注册到Consul",
-			zap.String("service", serviceConfig.ServiceName),
+			zap.String("consul_service", serviceConfig.ServiceName),
 			zap.Int("port", serviceConfig.ServicePort),
 			zap.String("consul", cfg.Consul.Address),
 		)
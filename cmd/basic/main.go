@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -8,11 +9,16 @@ import (
 	cfgPkg "mule-cloud/core/config"
 	"mule-cloud/core/cousul"
 	dbPkg "mule-cloud/core/database"
+	gatewayauthPkg "mule-cloud/core/gatewayauth"
 	loggerPkg "mule-cloud/core/logger"
+	metricsPkg "mule-cloud/core/metrics"
+	openapiPkg "mule-cloud/core/openapi"
 	"mule-cloud/core/response"
+	tracingPkg "mule-cloud/core/tracing"
 
 	"mule-cloud/app/basic/services"
 	"mule-cloud/app/basic/transport"
+	"mule-cloud/internal/repository"
 
 	jwtPkg "mule-cloud/core/jwt"
 	"mule-cloud/core/middleware"
@@ -32,13 +38,29 @@ func main() {
 		log.Fatalf("加载配置失败: %v", err)
 	}
 	// 初始化日志系统
-	if err := loggerPkg.InitLogger(&cfg.Log); err != nil {
+	if err := loggerPkg.InitLogger(&cfg.Log, cfg.Server.Name); err != nil {
 		log.Fatalf("初始化日志系统失败: %v", err)
 	}
 	defer loggerPkg.Close()
 
+	// 初始化网关身份头签名（网关签名，服务校验）
+	if err := gatewayauthPkg.Init(&cfg.GatewayAuth); err != nil {
+		loggerPkg.Fatal("初始化网关身份签名失败", zap.Error(err))
+	}
+	if err := gatewayauthPkg.RequireSecret(cfg.Server.Mode); err != nil {
+		loggerPkg.Fatal("网关身份签名未配置", zap.Error(err))
+	} else if !gatewayauthPkg.Enabled() {
+		loggerPkg.Warn("未配置 gateway_auth.secret，身份头不签名也不校验，只能用于本地开发")
+	}
+
+	// 初始化链路追踪（未启用时只传递 traceparent 和 X-Request-ID）
+	shutdownTracing, err := tracingPkg.Init(&cfg.Tracing, cfg.Server.Name)
+	if err != nil {
+		loggerPkg.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
+
 	loggerPkg.Info("🚀 BasicService 启动中...",
-		zap.String("service", cfg.Server.Name),
 		zap.Int("port", cfg.Server.Port),
 	)
 
@@ -50,6 +72,20 @@ func main() {
 		}
 		dbPkg.InitDatabaseManager(client)
 		loggerPkg.Info("✅ DatabaseManager初始化成功（支持多租户数据库隔离）")
+
+		// 旧 basic 集合数据迁移到独立集合（幂等）
+		migrated, err := services.MigrateBasicData(context.Background())
+		if err != nil {
+			loggerPkg.Error("基础数据迁移失败", zap.Error(err))
+		}
+		for tenantCode, count := range migrated {
+			loggerPkg.Info("✅ 基础数据迁移完成", zap.String("tenant", tenantCode), zap.Int("count", count))
+		}
+
+		// 补建业务唯一索引（基础资料编码等，幂等）
+		if err := repository.EnsureTenantIndexes(context.Background()); err != nil {
+			loggerPkg.Error("创建业务唯一索引失败", zap.Error(err))
+		}
 	}
 
 	// 初始化Redis（如果启用）
@@ -67,6 +103,7 @@ func main() {
 	orderTypeSvc := services.NewOrderTypeService()
 	procedureSvc := services.NewProcedureService()
 	salesmanSvc := services.NewSalesmanService()
+	procedureTemplateSvc := services.NewProcedureTemplateService()
 	commonSvc := services.NewCommonService()
 
 	// 初始化路由
@@ -75,11 +112,23 @@ func main() {
 
 	// 全局中间件
 	r.Use(gin.Logger())
+	r.Use(tracingPkg.Middleware())
+	r.Use(metricsPkg.Middleware())
 	r.Use(response.RecoveryMiddleware())
 	r.Use(response.UnifiedResponseMiddleware())
 	r.Use(middleware.OperationLogMiddleware())
-	// 初始化 JWT 管理器（用于直接访问时验证token）
-	jwtManager := jwtPkg.NewJWTManager(nil, 0)
+	r.GET("/metrics", metricsPkg.Handler()) // Prometheus 指标
+	// 初始化 JWT 管理器（用于直接访问时验证token，配置 jwks_url 时从认证服务获取公钥）
+	jwtManager, err := jwtPkg.NewFromConfig(&cfg.JWT, 0)
+	if err != nil {
+		loggerPkg.Fatal("初始化JWT管理器失败", zap.Error(err))
+	}
+
+	// 日志级别管理（超级管理员，运行时按模块调整）
+	middleware.ApplyLogLevels(r, jwtManager)
+
+	// OpenAPI 文档（按注册的路由和 DTO 生成，网关聚合后在 /gateway/openapi.json 提供）
+	r.GET("/openapi.json", openapiPkg.Handler(r, openapiPkg.Info{Title: cfg.Server.Name}, transport.Operations))
 
 	// Basic路由组（需要认证）
 	basic := r.Group("/basic")
@@ -132,12 +181,31 @@ func main() {
 		// 工序路由
 		procedure := basic.Group("/procedures")
 		{
-			procedure.GET("/:id", transport.GetProcedureHandler(procedureSvc))       // 获取单个工序
-			procedure.GET("", transport.ListProceduresHandler(procedureSvc))         // 分页列表
-			procedure.GET("/all", transport.GetAllProceduresHandler(procedureSvc))   // 获取所有（不分页）
-			procedure.POST("", transport.CreateProcedureHandler(procedureSvc))       // 创建工序
-			procedure.PUT("/:id", transport.UpdateProcedureHandler(procedureSvc))    // 更新工序
-			procedure.DELETE("/:id", transport.DeleteProcedureHandler(procedureSvc)) // 删除工序
+			procedure.GET("/:id", transport.GetProcedureHandler(procedureSvc))           // 获取单个工序
+			procedure.GET("", transport.ListProceduresHandler(procedureSvc))             // 分页列表
+			procedure.GET("/all", transport.GetAllProceduresHandler(procedureSvc))       // 获取所有（不分页）
+			procedure.POST("", transport.CreateProcedureHandler(procedureSvc))           // 创建工序
+			procedure.PUT("/:id", transport.UpdateProcedureHandler(procedureSvc))        // 更新工序
+			procedure.DELETE("/:id", transport.DeleteProcedureHandler(procedureSvc))     // 删除工序
+			procedure.POST("/reprice", transport.RepriceProceduresHandler(procedureSvc)) // 按当前费率重算工价
+		}
+
+		// 工价设置路由（租户统一费率）
+		pricing := basic.Group("/pricing")
+		{
+			pricing.GET("", transport.GetPricingHandler(procedureSvc))    // 获取工价设置
+			pricing.PUT("", transport.UpdatePricingHandler(procedureSvc)) // 更新费率并重算工序库工价
+		}
+
+		// 工序模板路由
+		procedureTemplate := basic.Group("/procedure_templates")
+		{
+			procedureTemplate.GET("/:id", transport.GetProcedureTemplateHandler(procedureTemplateSvc))       // 获取单个工序模板
+			procedureTemplate.GET("", transport.ListProcedureTemplatesHandler(procedureTemplateSvc))         // 分页列表
+			procedureTemplate.GET("/all", transport.GetAllProcedureTemplatesHandler(procedureTemplateSvc))   // 获取所有（不分页）
+			procedureTemplate.POST("", transport.CreateProcedureTemplateHandler(procedureTemplateSvc))       // 创建工序模板
+			procedureTemplate.PUT("/:id", transport.UpdateProcedureTemplateHandler(procedureTemplateSvc))    // 更新工序模板
+			procedureTemplate.DELETE("/:id", transport.DeleteProcedureTemplateHandler(procedureTemplateSvc)) // 删除工序模板
 		}
 
 		// 业务员路由
@@ -174,7 +242,7 @@ func main() {
 		}
 
 		loggerPkg.Info("准备注册到Consul",
-			zap.String("service", serviceConfig.ServiceName),
+			zap.String("consul_service", serviceConfig.ServiceName),
 			zap.Int("port", serviceConfig.ServicePort),
 			zap.String("consul", cfg.Consul.Address),
 		)
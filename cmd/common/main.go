@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -8,11 +9,15 @@ import (
 	cfgPkg "mule-cloud/core/config"
 	"mule-cloud/core/cousul"
 	dbPkg "mule-cloud/core/database"
+	gatewayauthPkg "mule-cloud/core/gatewayauth"
 	jwtPkg "mule-cloud/core/jwt"
 	loggerPkg "mule-cloud/core/logger"
+	metricsPkg "mule-cloud/core/metrics"
 	"mule-cloud/core/middleware"
+	openapiPkg "mule-cloud/core/openapi"
 	"mule-cloud/core/response"
 	"mule-cloud/core/storage"
+	tracingPkg "mule-cloud/core/tracing"
 
 	"mule-cloud/app/common/services"
 	"mule-cloud/app/common/transport"
@@ -34,13 +39,29 @@ func main() {
 	}
 
 	// 初始化日志系统
-	if err := loggerPkg.InitLogger(&cfg.Log); err != nil {
+	if err := loggerPkg.InitLogger(&cfg.Log, cfg.Server.Name); err != nil {
 		log.Fatalf("初始化日志系统失败: %v", err)
 	}
 	defer loggerPkg.Close()
 
+	// 初始化网关身份头签名（网关签名，服务校验）
+	if err := gatewayauthPkg.Init(&cfg.GatewayAuth); err != nil {
+		loggerPkg.Fatal("初始化网关身份签名失败", zap.Error(err))
+	}
+	if err := gatewayauthPkg.RequireSecret(cfg.Server.Mode); err != nil {
+		loggerPkg.Fatal("网关身份签名未配置", zap.Error(err))
+	} else if !gatewayauthPkg.Enabled() {
+		loggerPkg.Warn("未配置 gateway_auth.secret，身份头不签名也不校验，只能用于本地开发")
+	}
+
+	// 初始化链路追踪（未启用时只传递 traceparent 和 X-Request-ID）
+	shutdownTracing, err := tracingPkg.Init(&cfg.Tracing, cfg.Server.Name)
+	if err != nil {
+		loggerPkg.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
+
 	loggerPkg.Info("🚀 CommonService 启动中...",
-		zap.String("service", cfg.Server.Name),
 		zap.Int("port", cfg.Server.Port),
 	)
 
@@ -71,10 +92,13 @@ func main() {
 	fileService := services.NewFileService(fileRepo, storageInstance)
 
 	// 初始化Transport
-	fileTransport := transport.NewFileTransport(fileService, loggerPkg.Logger)
+	fileTransport := transport.NewFileTransport(fileService, loggerPkg.For("common"))
 
-	// 初始化 JWT 管理器（用于直接访问时验证token）
-	jwtManager := jwtPkg.NewJWTManager(nil, 0)
+	// 初始化 JWT 管理器（用于直接访问时验证token，配置 jwks_url 时从认证服务获取公钥）
+	jwtManager, err := jwtPkg.NewFromConfig(&cfg.JWT, 0)
+	if err != nil {
+		loggerPkg.Fatal("初始化JWT管理器失败", zap.Error(err))
+	}
 
 	// 创建Gin引擎
 	if cfg.Server.Mode == "release" {
@@ -82,12 +106,18 @@ func main() {
 	}
 	router := gin.New()
 	router.Use(gin.Logger()) // 添加日志中间件，记录所有HTTP请求
+	router.Use(tracingPkg.Middleware())
+	router.Use(metricsPkg.Middleware())
 	router.Use(gin.Recovery())
 	router.Use(response.UnifiedResponseMiddleware())
+	router.GET("/metrics", metricsPkg.Handler()) // Prometheus 指标
 
 	// 注册路由
 	registerRoutes(router, fileTransport, jwtManager)
 
+	// OpenAPI 文档（按注册的路由和 DTO 生成，网关聚合后在 /gateway/openapi.json 提供）
+	router.GET("/openapi.json", openapiPkg.Handler(router, openapiPkg.Info{Title: cfg.Server.Name}, transport.Operations))
+
 	// 注册到Consul（如果启用）
 	if cfg.Consul.Enabled {
 		serviceConfig := &cousul.ServiceConfig{
@@ -104,7 +134,7 @@ func main() {
 		}
 
 		loggerPkg.Info("准备注册到Consul",
-			zap.String("service", serviceConfig.ServiceName),
+			zap.String("consul_service", serviceConfig.ServiceName),
 			zap.Int("port", serviceConfig.ServicePort),
 			zap.String("consul", cfg.Consul.Address),
 		)
@@ -142,6 +172,9 @@ func registerRoutes(router *gin.Engine, fileTransport *transport.FileTransport,
 	// 静态文件服务（用于本地存储访问，无需认证）
 	router.Static("/files", "./uploads")
 
+	// 日志级别管理（超级管理员，运行时按模块调整）
+	middleware.ApplyLogLevels(router, jwtManager)
+
 	// API路由组（Gateway会去掉/admin前缀，所以这里只需要/common）
 	api := router.Group("/common")
 	middleware.Apply(api, jwtManager) // 应用认证中间件
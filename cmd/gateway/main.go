@@ -1,17 +1,24 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"mule-cloud/app/gateway/middleware"
+	coreMiddleware "mule-cloud/core/middleware"
+	cachePkg "mule-cloud/core/cache"
 	cfgPkg "mule-cloud/core/config"
+	dbPkg "mule-cloud/core/database"
+	gatewayauthPkg "mule-cloud/core/gatewayauth"
 	hystrixPkg "mule-cloud/core/hystrix"
 	jwtPkg "mule-cloud/core/jwt"
 	loggerPkg "mule-cloud/core/logger"
+	metricsPkg "mule-cloud/core/metrics"
+	openapiPkg "mule-cloud/core/openapi"
+	quotaPkg "mule-cloud/core/quota"
 	"mule-cloud/core/response"
-	"net/http/httputil"
-	"net/url"
+	tracingPkg "mule-cloud/core/tracing"
 	"strings"
 	"time"
 
@@ -20,11 +27,17 @@ import (
 	"go.uber.org/zap"
 )
 
+// proxyLog 转发日志（默认 info 级别不输出每个请求的转发详情）
+var proxyLog = loggerPkg.For("gateway.proxy")
+
 // Gateway API网关结构（增强版）
 type Gateway struct {
 	consulClient *api.Client
 	routeManager *middleware.DynamicRouteManager // 动态路由管理器
+	staticRoutes *middleware.RouteTable          // 静态路由表（未启用Consul时使用配置文件）
+	upstreams    *middleware.UpstreamManager     // 上游实例池（负载均衡、摘除、重试）
 	jwtManager   *jwtPkg.JWTManager
+	apiKeyStore  *middleware.APIKeyStore // API密钥（未启用MongoDB时为nil）
 	rateLimiter  *middleware.RateLimiter
 	config       *cfgPkg.Config
 }
@@ -49,27 +62,22 @@ func NewGateway(cfg *cfgPkg.Config) (*Gateway, error) {
 	var routeManager *middleware.DynamicRouteManager
 	if cfg.Consul.Enabled && client != nil {
 		routeManager = middleware.NewDynamicRouteManager(client)
-		log.Println("✅ 启用动态路由管理器 (基于Consul KV)")
+		loggerPkg.Info("启用动态路由管理器（基于Consul KV）")
 
 		// 从配置文件迁移路由到Consul（如果Consul中没有配置）
 		if len(routeManager.GetAllRoutes()) == 0 && len(cfg.Gateway.Routes) > 0 {
-			log.Println("🔄 检测到Consul中无路由配置，正在从配置文件迁移...")
+			loggerPkg.Info("Consul中无路由配置，从配置文件迁移")
 			for prefix, routeCfg := range cfg.Gateway.Routes {
-				config := &middleware.RouteConfig{
-					ServiceName:   routeCfg.ServiceName,
-					GatewayPrefix: "", // 默认无前缀，保持兼容
-					RequireAuth:   routeCfg.RequireAuth,
-					RequireRole:   routeCfg.RequireRole,
-				}
+				config := routeFromConfig(routeCfg) // 默认无网关前缀，保持兼容
 				if err := routeManager.AddRoute(prefix, config); err != nil {
-					log.Printf("⚠️  迁移路由配置失败 (%s): %v", prefix, err)
+					loggerPkg.Warn("迁移路由配置失败", zap.String("prefix", prefix), zap.Error(err))
 				}
 			}
 		}
 
 		// 从配置文件迁移Hystrix配置到Consul（如果Consul中没有配置）
 		if len(routeManager.GetAllHystrixConfigs()) == 0 && len(cfg.Hystrix.Command) > 0 {
-			log.Println("🔄 检测到Consul中无Hystrix配置，正在从配置文件迁移...")
+			loggerPkg.Info("Consul中无Hystrix配置，从配置文件迁移")
 			for serviceName, cmdCfg := range cfg.Hystrix.Command {
 				config := &middleware.DynamicHystrixConfig{
 					Timeout:                cmdCfg.Timeout,
@@ -79,47 +87,99 @@ func NewGateway(cfg *cfgPkg.Config) (*Gateway, error) {
 					ErrorPercentThreshold:  cmdCfg.ErrorPercentThreshold,
 				}
 				if err := routeManager.AddHystrixConfig(serviceName, config); err != nil {
-					log.Printf("⚠️  迁移Hystrix配置失败 (%s): %v", serviceName, err)
+					loggerPkg.Warn("迁移Hystrix配置失败", zap.String("command", serviceName), zap.Error(err))
 				}
 			}
 		}
 	} else {
-		log.Println("⚠️  Consul未启用，动态路由功能将不可用")
+		loggerPkg.Warn("Consul未启用，动态路由功能将不可用")
+	}
+
+	// 静态路由表（配置文件）
+	staticRoutes := make(map[string]*middleware.RouteConfig, len(cfg.Gateway.Routes))
+	for prefix, routeCfg := range cfg.Gateway.Routes {
+		staticRoutes[prefix] = routeFromConfig(routeCfg)
+	}
+	staticTable, err := middleware.CompileRoutes(staticRoutes)
+	if err != nil {
+		return nil, fmt.Errorf("编译路由配置失败: %w", err)
 	}
 
-	// JWT管理器
-	jwtSecret := []byte(cfg.JWT.SecretKey)
-	expireTime := time.Duration(cfg.JWT.ExpireTime) * time.Hour
+	// JWT管理器（配置 jwks_url 时从认证服务获取公钥验证令牌）
+	jwtManager, err := jwtPkg.NewFromConfig(&cfg.JWT, time.Duration(cfg.JWT.ExpireTime)*time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("初始化JWT管理器失败: %w", err)
+	}
 
-	// 限流器
+	// 限流器（启用Redis时多个网关副本共享限流状态；启用Consul时策略保存在 Consul KV）
 	var rateLimiter *middleware.RateLimiter
 	if cfg.Gateway.RateLimit.Enabled {
-		rateLimiter = middleware.NewRateLimiter(cfg.Gateway.RateLimit.Rate)
+		policies, err := rateLimitPolicies(cfg.Gateway.RateLimit)
+		if err != nil {
+			return nil, err
+		}
+		source := func() []*middleware.RateLimitPolicy { return policies }
+		if routeManager != nil {
+			if len(routeManager.GetAllRateLimits()) == 0 && len(policies) > 0 {
+				loggerPkg.Info("Consul中无限流策略，从配置文件迁移")
+				for _, policy := range policies {
+					if err := routeManager.AddRateLimit(policy); err != nil {
+						loggerPkg.Warn("迁移限流策略失败", zap.String("policy", policy.Name), zap.Error(err))
+					}
+				}
+			}
+			source = routeManager.RateLimitPolicies
+		}
+
+		var store middleware.RateLimitStore
+		if cachePkg.RedisEnabled() {
+			store = middleware.NewRedisRateLimitStore(cachePkg.GetRedis())
+		} else {
+			loggerPkg.Warn("Redis未启用，限流只在当前网关实例内生效")
+			store = middleware.NewMemoryRateLimitStore()
+		}
+		rateLimiter = middleware.NewRateLimiter(store, source)
+	}
+
+	// API密钥认证（需要MongoDB查询密钥）
+	var apiKeyStore *middleware.APIKeyStore
+	if cfg.MongoDB.Enabled {
+		apiKeyStore = middleware.NewAPIKeyStore()
 	}
 
-	return &Gateway{
+	gw := &Gateway{
 		consulClient: client,
 		routeManager: routeManager,
-		jwtManager:   jwtPkg.NewJWTManager(jwtSecret, expireTime),
+		staticRoutes: staticTable,
+		jwtManager:   jwtManager,
+		apiKeyStore:  apiKeyStore,
 		rateLimiter:  rateLimiter,
 		config:       cfg,
-	}, nil
+	}
+	// 健康实例按 load_balance.cache_ttl 缓存，不再每个请求查询Consul
+	gw.upstreams = middleware.NewUpstreamManager(gw.getServiceAddresses, cfg.Gateway.LoadBalance)
+	return gw, nil
 }
 
-// getServiceAddress 从Consul获取健康的服务地址
-func (gw *Gateway) getServiceAddress(serviceName string) (string, error) {
+// getServiceAddresses 从Consul获取服务的全部健康实例地址
+func (gw *Gateway) getServiceAddresses(serviceName string) ([]string, error) {
+	if gw.consulClient == nil {
+		return nil, fmt.Errorf("Consul未启用，无法发现服务: %s", serviceName)
+	}
 	services, _, err := gw.consulClient.Health().Service(serviceName, "", true, nil)
 	if err != nil {
-		return "", fmt.Errorf("查询服务失败: %v", err)
+		return nil, fmt.Errorf("查询服务失败: %v", err)
 	}
 
 	if len(services) == 0 {
-		return "", fmt.Errorf("未找到可用的服务实例: %s", serviceName)
+		return nil, fmt.Errorf("%w: %s", middleware.ErrNoInstance, serviceName)
 	}
 
-	// 简单负载均衡：返回第一个健康实例
-	service := services[0].Service
-	return fmt.Sprintf("http://%s:%d", service.Address, service.Port), nil
+	addrs := make([]string, 0, len(services))
+	for _, entry := range services {
+		addrs = append(addrs, fmt.Sprintf("http://%s:%d", entry.Service.Address, entry.Service.Port))
+	}
+	return addrs, nil
 }
 
 // proxyHandler 反向代理处理器（增强版）
@@ -128,38 +188,13 @@ func (gw *Gateway) proxyHandler() gin.HandlerFunc {
 		startTime := time.Now()
 		originalPath := c.Request.URL.Path
 
-		// 1. 匹配路由配置（从动态路由管理器获取）
+		// 1. 匹配路由（最长前缀优先，再按方法、Host、请求头条件）
+		match := gw.routeTable().Match(c.Request)
 		var routeConfig *middleware.RouteConfig
 		var serviceName string
-
-		if gw.routeManager != nil {
-			// 使用动态路由
-			routes := gw.routeManager.GetAllRoutes()
-			for routePrefix, config := range routes {
-				// 构建完整的匹配路径（网关前缀 + 路由前缀）
-				fullPrefix := config.GatewayPrefix + routePrefix
-				if fullPrefix == "" {
-					fullPrefix = routePrefix
-				}
-				if strings.HasPrefix(originalPath, fullPrefix) {
-					routeConfig = config
-					serviceName = config.ServiceName
-					break
-				}
-			}
-		} else {
-			// 降级到静态路由（从配置文件）
-			for prefix, staticCfg := range gw.config.Gateway.Routes {
-				if strings.HasPrefix(originalPath, prefix) {
-					routeConfig = &middleware.RouteConfig{
-						ServiceName: staticCfg.ServiceName,
-						RequireAuth: staticCfg.RequireAuth,
-						RequireRole: staticCfg.RequireRole,
-					}
-					serviceName = staticCfg.ServiceName
-					break
-				}
-			}
+		if match != nil {
+			routeConfig = match.Config
+			serviceName = routeConfig.ServiceName
 		}
 
 		if routeConfig == nil {
@@ -169,6 +204,8 @@ func (gw *Gateway) proxyHandler() gin.HandlerFunc {
 
 		// 设置服务名称（供Hystrix中间件使用）
 		c.Set("service_name", serviceName)
+		metricsPkg.SetRoute(c, match.FullPrefix)
+		tracingPkg.SetRoute(c, match.FullPrefix)
 
 		// 2. 认证检查（如果需要）
 		if routeConfig.RequireAuth {
@@ -188,36 +225,35 @@ func (gw *Gateway) proxyHandler() gin.HandlerFunc {
 			}
 		}
 
-		// 3. 从Consul获取服务地址
-		targetURL, err := gw.getServiceAddress(serviceName)
+		// 租户和用户写入请求上下文（转发、重试、上游错误的日志自动带上）
+		c.Request = c.Request.WithContext(middleware.LogContext(c))
+
+		// 3. 获取服务的健康实例（缓存的Consul查询结果）
+		instances, err := gw.upstreams.Instances(serviceName)
 		if err != nil {
-			log.Printf("[网关错误] 服务不可用: %s, 错误: %v", serviceName, err)
+			proxyLog.Ctx(c.Request.Context()).Warn("服务不可用", zap.String("upstream", serviceName), zap.Error(err))
+			metricsPkg.UpstreamErrors.WithLabelValues(serviceName, "", "no_instance").Inc()
 			c.JSON(503, gin.H{"code": 503, "msg": fmt.Sprintf("服务不可用: %s", serviceName)})
 			return
 		}
 
-		// 4. 构建反向代理
-		target, _ := url.Parse(targetURL)
-		proxy := httputil.NewSingleHostReverseProxy(target)
+		// 4. 服务的反向代理（按服务复用连接池，转发时按负载均衡策略选择实例）
+		proxy := gw.upstreams.Proxy(serviceName)
 
-		// 5. 修改请求路径（去掉网关配置的前缀）
-		targetPath := originalPath
-		if routeConfig.GatewayPrefix != "" && strings.HasPrefix(originalPath, routeConfig.GatewayPrefix) {
-			// 去掉网关前缀，保留路由前缀
-			targetPath = strings.TrimPrefix(originalPath, routeConfig.GatewayPrefix)
-			if targetPath == "" {
-				targetPath = "/"
-			}
-		}
+		// 5. 修改请求路径（去掉网关配置的前缀，按路由的重写规则改写）
+		targetPath := match.TargetPath
 		c.Request.URL.Path = targetPath
-		c.Request.URL.Host = target.Host
-		c.Request.URL.Scheme = target.Scheme
+		c.Request.URL.RawPath = ""
 
 		// 6. 设置转发头（包括用户信息）
 		c.Request.Header.Set("X-Forwarded-Host", c.Request.Host)
-		c.Request.Header.Set("X-Real-IP", c.ClientIP())
+		c.Request.Header.Set("X-Real-IP", c.ClientIP()) // 按可信代理解析的客户端IP，服务端只采信网关转发的这个头
 		c.Request.Header.Set("X-Gateway", "mule-cloud-gateway-")
 
+		// 客户端自带的身份头一律丢弃，只转发网关认证得到的身份（X-Tenant-Context 由前端发送，单独保留）
+		contextTenant := c.GetHeader("X-Tenant-Context")
+		gatewayauthPkg.StripIdentity(c.Request.Header)
+
 		// 传递用户信息到后端服务
 		if userID, exists := c.Get("user_id"); exists {
 			c.Request.Header.Set("X-User-ID", userID.(string))
@@ -237,36 +273,136 @@ func (gw *Gateway) proxyHandler() gin.HandlerFunc {
 				c.Request.Header.Set("X-Roles", strings.Join(roles, ","))
 			}
 		}
+		// 传递会话ID和令牌ID（用于退出登录、会话管理）
+		if sessionID := c.GetString("session_id"); sessionID != "" {
+			c.Request.Header.Set("X-Session-ID", sessionID)
+		}
+		if jti := c.GetString("jti"); jti != "" {
+			c.Request.Header.Set("X-Token-ID", jti)
+		}
+		// 传递两步验证状态（超管切换租户时要求）；客户端自带的同名头一律丢弃
+		if c.GetBool("mfa") {
+			c.Request.Header.Set("X-MFA", "1")
+		} else {
+			c.Request.Header.Del("X-MFA")
+		}
+		// 传递API密钥ID（操作日志记录调用方密钥）；客户端自带的同名头一律丢弃
+		if apiKeyID := c.GetString("api_key_id"); apiKeyID != "" {
+			c.Request.Header.Set("X-API-Key-ID", apiKeyID)
+		} else {
+			c.Request.Header.Del("X-API-Key-ID")
+		}
+		// 传递超管代管会话ID和只读标记（服务据此拒绝写操作、记录代管日志）
+		if impersonationID := c.GetString("impersonation_id"); impersonationID != "" {
+			c.Request.Header.Set("X-Impersonation-ID", impersonationID)
+			if c.GetBool("read_only") {
+				c.Request.Header.Set("X-Read-Only", "1")
+			}
+		}
 		
 		// ✅ 重要：转发前端发送的 X-Tenant-Context header（用于超管切换租户）
 		// 这个 header 是前端直接发送的，不在 JWT token 中，需要单独转发
-		if contextTenant := c.GetHeader("X-Tenant-Context"); contextTenant != "" {
+		if contextTenant != "" {
 			c.Request.Header.Set("X-Tenant-Context", contextTenant)
-			log.Printf("[网关转发] 转发租户上下文: %s", contextTenant)
+			proxyLog.Ctx(c.Request.Context()).Debug("转发租户上下文", zap.String("context_tenant", contextTenant))
 		}
 
-		c.Request.Host = target.Host
+		// 对身份头签名（绑定方法和转发路径），服务校验后才信任这些头
+		gatewayauthPkg.Sign(c.Request.Header, c.Request.Method, c.Request.URL.Path)
+
+		// 一致性哈希按租户分流（同一租户固定落在同一实例）
+		if tenantCode := c.GetString("tenant_code"); tenantCode != "" {
+			c.Request = c.Request.WithContext(middleware.WithBalanceKey(c.Request.Context(), tenantCode))
+		}
 
 		// 7. 记录日志
-		log.Printf("[网关转发] %s %s → %s%s (服务: %s, 前缀: %s, 用户: %v)",
-			c.Request.Method,
-			originalPath,
-			targetURL,
-			c.Request.URL.Path,
-			serviceName,
-			routeConfig.GatewayPrefix,
-			c.GetString("username"),
+		proxyLog.Ctx(c.Request.Context()).Debug("网关转发",
+			zap.String("method", c.Request.Method),
+			zap.String("path", originalPath),
+			zap.String("target", c.Request.URL.Path),
+			zap.String("upstream", serviceName),
+			zap.Int("instances", len(instances)),
+			zap.String("prefix", routeConfig.GatewayPrefix),
 		)
 
-		// 8. 执行代理转发
+		// 8. 执行代理转发（路由配置了超时则限制转发时间，超时返回504）
+		if timeout := match.Timeout(); timeout > 0 {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+		}
 		proxy.ServeHTTP(c.Writer, c.Request)
 
 		// 9. 记录响应时间
 		duration := time.Since(startTime)
-		log.Printf("[网关响应] %s %s 耗时: %v", c.Request.Method, originalPath, duration)
+		proxyLog.Ctx(c.Request.Context()).Debug("网关响应",
+			zap.String("method", c.Request.Method),
+			zap.String("path", originalPath),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("duration", duration),
+		)
 	}
 }
 
+// rateLimitPolicies 配置文件中的限流策略（rate 转换为按IP的默认策略）
+func rateLimitPolicies(cfg cfgPkg.RateLimitConfig) ([]*middleware.RateLimitPolicy, error) {
+	var policies []*middleware.RateLimitPolicy
+	if cfg.Rate > 0 {
+		policies = append(policies, &middleware.RateLimitPolicy{
+			Name:   "default",
+			Key:    middleware.RateLimitByIP,
+			Limit:  cfg.Rate,
+			Window: 1,
+		})
+	}
+	for _, p := range cfg.Policies {
+		policy := &middleware.RateLimitPolicy{
+			Name:    p.Name,
+			Key:     p.Key,
+			Path:    p.Path,
+			Methods: p.Methods,
+			Limit:   p.Limit,
+			Window:  p.Window,
+			Burst:   p.Burst,
+		}
+		if err := policy.Validate(); err != nil {
+			return nil, fmt.Errorf("限流配置无效: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// routeTable 当前的路由表（启用Consul时使用动态路由，否则使用配置文件）
+func (gw *Gateway) routeTable() *middleware.RouteTable {
+	if gw.routeManager != nil {
+		return gw.routeManager.Table()
+	}
+	return gw.staticRoutes
+}
+
+// routeFromConfig 配置文件中的路由转换为路由配置
+func routeFromConfig(routeCfg cfgPkg.RouteConfig) *middleware.RouteConfig {
+	config := &middleware.RouteConfig{
+		ServiceName: routeCfg.ServiceName,
+		RequireAuth: routeCfg.RequireAuth,
+		RequireRole: routeCfg.RequireRole,
+		Methods:     routeCfg.Methods,
+		Host:        routeCfg.Host,
+		Headers:     routeCfg.Headers,
+		Timeout:     routeCfg.Timeout,
+	}
+	if rw := routeCfg.Rewrite; rw.Regex != "" || rw.From != "" {
+		config.Rewrite = &middleware.RewriteRule{
+			Regex:       rw.Regex,
+			Replacement: rw.Replacement,
+			From:        rw.From,
+			To:          rw.To,
+		}
+	}
+	return config
+}
+
 // healthHandler 健康检查
 func (gw *Gateway) healthHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -285,7 +421,7 @@ func (gw *Gateway) healthHandler() gin.HandlerFunc {
 			healthStatus["consul"] = gw.config.Consul.Address
 
 			// 检查服务状态
-			services := make(map[string]string)
+			services := make(map[string]interface{})
 			serviceSet := make(map[string]bool)
 
 			// 从动态路由管理器获取服务列表
@@ -302,11 +438,11 @@ func (gw *Gateway) healthHandler() gin.HandlerFunc {
 			}
 
 			for svcName := range serviceSet {
-				addr, err := gw.getServiceAddress(svcName)
+				addrs, err := gw.upstreams.Instances(svcName)
 				if err != nil {
 					services[svcName] = "不可用"
 				} else {
-					services[svcName] = addr
+					services[svcName] = addrs
 				}
 			}
 			healthStatus["services"] = services
@@ -327,11 +463,52 @@ func main() {
 		log.Fatalf("加载配置失败: %v", err)
 	}
 	// 初始化日志系统
-	if err := loggerPkg.InitLogger(&cfg.Log); err != nil {
+	if err := loggerPkg.InitLogger(&cfg.Log, cfg.Server.Name); err != nil {
 		log.Fatalf("初始化日志系统失败: %v", err)
 	}
 	defer loggerPkg.Close()
 
+	// 初始化网关身份头签名（网关签名，服务校验）
+	if err := gatewayauthPkg.Init(&cfg.GatewayAuth); err != nil {
+		loggerPkg.Fatal("初始化网关身份签名失败", zap.Error(err))
+	}
+	if err := gatewayauthPkg.RequireSecret(cfg.Server.Mode); err != nil {
+		loggerPkg.Fatal("网关身份签名未配置", zap.Error(err))
+	} else if !gatewayauthPkg.Enabled() {
+		loggerPkg.Warn("未配置 gateway_auth.secret，身份头不签名也不校验，只能用于本地开发")
+	}
+
+	// 初始化链路追踪（未启用时只传递 traceparent 和 X-Request-ID）
+	shutdownTracing, err := tracingPkg.Init(&cfg.Tracing, cfg.Server.Name)
+	if err != nil {
+		loggerPkg.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
+	if !gatewayauthPkg.Enabled() {
+		loggerPkg.Warn("未配置 gateway_auth.secret，转发的身份头不签名")
+	}
+
+	// 初始化Redis（用于检查已注销的令牌）
+	if cfg.Redis.Enabled {
+		if _, err := cachePkg.InitRedis(&cfg.Redis); err != nil {
+			loggerPkg.Fatal("初始化Redis失败", zap.Error(err))
+		}
+		defer cachePkg.CloseRedis()
+	} else {
+		loggerPkg.Warn("Redis未启用，网关不会拒绝已注销的令牌")
+	}
+
+	// 初始化MongoDB（用于API密钥认证）
+	if cfg.MongoDB.Enabled {
+		client, err := dbPkg.InitMongoDB(&cfg.MongoDB)
+		if err != nil {
+			loggerPkg.Fatal("初始化MongoDB失败", zap.Error(err))
+		}
+		dbPkg.InitDatabaseManager(client)
+	} else {
+		loggerPkg.Warn("MongoDB未启用，网关不支持API密钥认证")
+	}
+
 	// 初始化Hystrix熔断器
 	if cfg.Hystrix.Enabled {
 		// 从配置文件读取服务级别配置
@@ -346,24 +523,34 @@ func main() {
 			}
 		}
 		hystrixPkg.InitWithConfig(commands)
+		metricsPkg.RegisterCircuitBreakers(hystrixPkg.CircuitStates)
 	}
 
 	// 创建网关实例
 	gateway, err := NewGateway(cfg)
 	if err != nil {
-		log.Fatalf("创建网关失败: %v", err)
+		loggerPkg.Fatal("创建网关失败", zap.Error(err))
 	}
 
 	// 创建Gin路由
 	gin.SetMode(cfg.Server.Mode)
 	r := gin.New()
+	// 客户端IP只采信可信代理转发的 X-Forwarded-For/X-Real-IP，未配置时使用直连地址（防止伪造来源IP绕过白名单和限流）
+	if err := r.SetTrustedProxies(cfg.Gateway.TrustedProxies); err != nil {
+		loggerPkg.Fatal("可信代理配置无效", zap.Error(err))
+	}
 
 	// 全局中间件
 	r.Use(gin.Logger())                         // 日志
+	r.Use(tracingPkg.Middleware())              // 请求ID和链路追踪（traceparent 经转发传到各服务）
+	r.Use(metricsPkg.Middleware())              // Prometheus 请求指标
 	r.Use(response.RecoveryMiddleware())        // 统一错误恢复
 	r.Use(response.UnifiedResponseMiddleware()) // 统一响应
 	r.Use(middleware.CORS())                    // 跨域
 
+	// Prometheus 指标在单独的内部地址提供，不在对外端口注册
+	go serveMetrics(cfg.Gateway.MetricsListen)
+
 	// 公开接口（无需认证）
 	public := r.Group("/api")
 	{
@@ -377,9 +564,23 @@ func main() {
 		admin.GET("/hystrix/metrics", middleware.HystrixMetricsHandler())
 		admin.GET("/hystrix/metrics/:service", middleware.HystrixMetricsHandler())
 
+		// 上游实例状态（进行中的请求数、连续失败次数、是否被摘除）
+		admin.GET("/upstreams", middleware.UpstreamStatsHandler(gateway.upstreams))
+
+		// 日志级别（运行时按模块调整，如把 gateway.proxy 调到 debug 查看转发详情；仅超级管理员）
+		logLevels := admin.Group("/log-levels", middleware.JWTAuth(gateway.jwtManager), middleware.RequireRole("super"))
+		logLevels.GET("", coreMiddleware.LogLevelsHandler())
+		logLevels.PUT("", coreMiddleware.SetLogLevelHandler())
+
+		// 聚合各服务的 OpenAPI 文档（路径为经网关访问的路径）
+		admin.GET("/openapi.json", middleware.OpenAPIHandler(openapiPkg.Info{
+			Title:       "mule-cloud API",
+			Description: "经网关访问的全部接口，由各服务的 /openapi.json 聚合",
+		}, gateway.routeTable, gateway.upstreams))
+
 		// 动态路由管理 API（需要动态路由管理器）
 		if gateway.routeManager != nil {
-			adminHandlers := middleware.NewAdminHandlers(gateway.routeManager)
+			adminHandlers := middleware.NewAdminHandlers(gateway.routeManager, gateway.upstreams.Instances)
 
 			// 路由配置管理
 			adminAPI := admin.Group("/admin")
@@ -390,6 +591,7 @@ func main() {
 				adminAPI.POST("/routes", adminHandlers.AddRoute)
 				adminAPI.PUT("/routes/*prefix", adminHandlers.UpdateRoute)
 				adminAPI.DELETE("/routes/*prefix", adminHandlers.DeleteRoute)
+				adminAPI.POST("/route-test", adminHandlers.TestRoute) // 路由测试：请求会命中哪条路由和上游
 
 				// Hystrix 配置管理
 				adminAPI.GET("/hystrix", adminHandlers.ListHystrixConfigs)
@@ -398,6 +600,11 @@ func main() {
 				adminAPI.PUT("/hystrix/:service", adminHandlers.UpdateHystrixConfig)
 				adminAPI.DELETE("/hystrix/:service", adminHandlers.DeleteHystrixConfig)
 
+				// 限流策略管理
+				adminAPI.GET("/ratelimits", adminHandlers.ListRateLimits)
+				adminAPI.POST("/ratelimits", adminHandlers.SaveRateLimit)
+				adminAPI.DELETE("/ratelimits/:name", adminHandlers.DeleteRateLimit)
+
 				// 配置重载
 				adminAPI.POST("/reload", adminHandlers.ReloadConfig)
 			}
@@ -407,10 +614,18 @@ func main() {
 	// 业务接口（动态路由）
 	// 使用 NoRoute 作为兜底，根据路由配置决定是否需要认证
 	var handlers []gin.HandlerFunc
-	if cfg.Gateway.RateLimit.Enabled {
-		handlers = append(handlers, gateway.rateLimiter.Middleware())
+	if gateway.apiKeyStore != nil {
+		handlers = append(handlers, middleware.APIKeyAuth(gateway.apiKeyStore))
 	}
 	handlers = append(handlers, middleware.OptionalAuth(gateway.jwtManager))
+	if gateway.rateLimiter != nil {
+		// 放在认证之后，才能按租户、用户、API密钥限流
+		handlers = append(handlers, gateway.rateLimiter.Middleware())
+	}
+	if cfg.MongoDB.Enabled {
+		// 套餐限制：过期租户只读、本月API调用次数（计数需要Redis）
+		handlers = append(handlers, middleware.PlanEnforcement(quotaPkg.Resolve, quotaPkg.RecordAPICall))
+	}
 	if cfg.Hystrix.Enabled {
 		handlers = append(handlers, middleware.HystrixMiddleware())
 	}
@@ -420,10 +635,27 @@ func main() {
 	port := fmt.Sprintf(":%d", cfg.Server.Port)
 
 	loggerPkg.Info("🚀 Gateway 启动中...",
-		zap.String("service", cfg.Server.Name),
 		zap.Int("port", cfg.Server.Port),
 	)
 	if err := r.Run(port); err != nil {
-		log.Fatalf("网关启动失败: %v", err)
+		loggerPkg.Fatal("网关启动失败", zap.Error(err))
+	}
+}
+
+// defaultMetricsListen 指标接口默认只监听本机
+const defaultMetricsListen = "127.0.0.1:9080"
+
+// serveMetrics 在内部地址提供 GET /metrics（失败只记录日志，不影响网关转发）
+func serveMetrics(addr string) {
+	if addr == "" {
+		addr = defaultMetricsListen
+	}
+	m := gin.New()
+	m.Use(gin.Recovery())
+	m.GET("/metrics", metricsPkg.Handler())
+
+	loggerPkg.Info("Prometheus 指标接口启动", zap.String("listen", addr))
+	if err := m.Run(addr); err != nil {
+		loggerPkg.Error("指标接口启动失败", zap.String("listen", addr), zap.Error(err))
 	}
 }
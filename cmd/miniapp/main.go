@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -10,9 +11,14 @@ import (
 	cfgPkg "mule-cloud/core/config"
 	"mule-cloud/core/cousul"
 	dbPkg "mule-cloud/core/database"
+	fieldcryptPkg "mule-cloud/core/fieldcrypt"
+	gatewayauthPkg "mule-cloud/core/gatewayauth"
 	jwtPkg "mule-cloud/core/jwt"
 	loggerPkg "mule-cloud/core/logger"
+	metricsPkg "mule-cloud/core/metrics"
+	openapiPkg "mule-cloud/core/openapi"
 	"mule-cloud/core/response"
+	tracingPkg "mule-cloud/core/tracing"
 
 	"mule-cloud/app/miniapp/services"
 	"mule-cloud/app/miniapp/transport"
@@ -34,13 +40,29 @@ func main() {
 	}
 
 	// 初始化日志系统
-	if err := loggerPkg.InitLogger(&cfg.Log); err != nil {
+	if err := loggerPkg.InitLogger(&cfg.Log, cfg.Server.Name); err != nil {
 		log.Fatalf("初始化日志系统失败: %v", err)
 	}
 	defer loggerPkg.Close()
 
+	// 初始化网关身份头签名（网关签名，服务校验）
+	if err := gatewayauthPkg.Init(&cfg.GatewayAuth); err != nil {
+		loggerPkg.Fatal("初始化网关身份签名失败", zap.Error(err))
+	}
+	if err := gatewayauthPkg.RequireSecret(cfg.Server.Mode); err != nil {
+		loggerPkg.Fatal("网关身份签名未配置", zap.Error(err))
+	} else if !gatewayauthPkg.Enabled() {
+		loggerPkg.Warn("未配置 gateway_auth.secret，身份头不签名也不校验，只能用于本地开发")
+	}
+
+	// 初始化链路追踪（未启用时只传递 traceparent 和 X-Request-ID）
+	shutdownTracing, err := tracingPkg.Init(&cfg.Tracing, cfg.Server.Name)
+	if err != nil {
+		loggerPkg.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
+
 	loggerPkg.Info("🚀 MiniappService 启动中...",
-		zap.String("service", cfg.Server.Name),
 		zap.Int("port", cfg.Server.Port),
 	)
 
@@ -54,6 +76,14 @@ func main() {
 		loggerPkg.Info("✅ DatabaseManager初始化成功（支持多租户数据库隔离）")
 	}
 
+	// 初始化敏感字段加密（员工身份证号、银行卡号，读取员工档案的服务需要配置相同的密钥）
+	if err := fieldcryptPkg.Init(&cfg.Encryption); err != nil {
+		loggerPkg.Fatal("初始化敏感字段加密失败", zap.Error(err))
+	}
+	if fieldcryptPkg.Enabled() && cfg.MongoDB.Enabled {
+		go services.EncryptMemberSensitiveFields(context.Background())
+	}
+
 	// 初始化Redis（如果启用）
 	if cfg.Redis.Enabled {
 		if _, err := cachePkg.InitRedis(&cfg.Redis); err != nil {
@@ -62,11 +92,11 @@ func main() {
 		defer cachePkg.CloseRedis()
 	}
 
-	// 初始化JWT管理器
-	jwtManager := jwtPkg.NewJWTManager(
-		[]byte(cfg.JWT.SecretKey),
-		time.Duration(cfg.JWT.ExpireTime)*time.Hour,
-	)
+	// 初始化JWT管理器（令牌的签发者取 jwt.issuer，网关只接受该签发者的 HS256 令牌）
+	jwtManager, err := jwtPkg.NewFromConfig(&cfg.JWT, time.Duration(cfg.JWT.ExpireTime)*time.Hour)
+	if err != nil {
+		loggerPkg.Fatal("初始化JWT管理器失败", zap.Error(err))
+	}
 
 	// 初始化微信服务
 	wechatSvc := services.NewWechatService(
@@ -84,9 +114,12 @@ func main() {
 
 	// 全局中间件
 	r.Use(gin.Logger())
+	r.Use(tracingPkg.Middleware())
+	r.Use(metricsPkg.Middleware())
 	r.Use(response.RecoveryMiddleware())
 	r.Use(response.UnifiedResponseMiddleware())
 	r.Use(middleware.OperationLogMiddleware())
+	r.GET("/metrics", metricsPkg.Handler()) // Prometheus 指标
 
 	// 公开路由（不需要认证）
 	public := r.Group("/miniapp")
@@ -96,6 +129,12 @@ func main() {
 		public.POST("/wechat/select-tenant", transport.SelectTenantHandler(wechatSvc)) // 选择租户
 	}
 
+	// 日志级别管理（超级管理员，运行时按模块调整）
+	middleware.ApplyLogLevels(r, jwtManager)
+
+	// OpenAPI 文档（按注册的路由和 DTO 生成，网关聚合后在 /gateway/openapi.json 提供）
+	r.GET("/openapi.json", openapiPkg.Handler(r, openapiPkg.Info{Title: cfg.Server.Name}, transport.Operations))
+
 	// 需要认证的路由
 	protected := r.Group("/miniapp")
 	middleware.Apply(protected, jwtManager) // 应用JWT认证中间件
@@ -143,7 +182,7 @@ func main() {
 		}
 
 		loggerPkg.Info("准备注册到Consul",
-			zap.String("service", serviceConfig.ServiceName),
+			zap.String("consul_service", serviceConfig.ServiceName),
 			zap.Int("port", serviceConfig.ServicePort),
 			zap.String("consul", cfg.Consul.Address),
 		)
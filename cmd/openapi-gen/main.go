@@ -0,0 +1,48 @@
+// openapi-gen 分析各服务 transport 包的处理器，生成接口描述 app/*/transport/openapi_gen.go
+//
+//	go run ./cmd/openapi-gen          # 重新生成
+//	go run ./cmd/openapi-gen -check   # 只检查是否最新（不一致时退出码为 1）
+//
+// 请求 DTO 取处理器绑定的变量（binding.BindAll、c.ShouldBind* 等），响应取 response.Success 的数据，
+// 数据来自 go-kit 端点时取端点返回的类型。修改处理器、DTO 或端点后需要重新生成，契约测试会检查生成结果
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+//go:generate go run .
+
+func main() {
+	check := flag.Bool("check", false, "只检查生成结果是否最新")
+	flag.Parse()
+
+	files, err := generate(transportPattern)
+	if err != nil {
+		log.Fatalf("生成接口描述失败: %v", err)
+	}
+
+	stale := 0
+	for _, path := range sortedKeys(files) {
+		existing, _ := os.ReadFile(path)
+		if bytes.Equal(existing, files[path]) {
+			continue
+		}
+		stale++
+		if *check {
+			fmt.Printf("需要重新生成: %s\n", path)
+			continue
+		}
+		if err := os.WriteFile(path, files[path], 0644); err != nil {
+			log.Fatalf("写入 %s 失败: %v", path, err)
+		}
+		fmt.Printf("已生成: %s\n", path)
+	}
+	if *check && stale > 0 {
+		os.Exit(1)
+	}
+}
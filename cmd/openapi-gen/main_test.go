@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const fixturePattern = "mule-cloud/cmd/openapi-gen/testdata/transport"
+
+var (
+	loadOnce  sync.Once
+	generated map[string][]byte
+	loadErr   error
+)
+
+// load 各服务和测试样例一起加载（从源码检查类型较慢，只加载一次）
+func load(t *testing.T) map[string][]byte {
+	t.Helper()
+	loadOnce.Do(func() { generated, loadErr = generate(transportPattern, fixturePattern) })
+	if loadErr != nil {
+		t.Fatalf("generate: %v", loadErr)
+	}
+	return generated
+}
+
+// TestGeneratedUpToDate 契约测试：处理器、DTO、端点修改后必须重新生成接口描述
+func TestGeneratedUpToDate(t *testing.T) {
+	files := load(t)
+	count := 0
+	for _, path := range sortedKeys(files) {
+		if strings.Contains(filepath.ToSlash(path), "/testdata/") {
+			continue
+		}
+		count++
+		existing, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s 不存在，请执行 go run ./cmd/openapi-gen", path)
+			continue
+		}
+		if !bytes.Equal(existing, files[path]) {
+			t.Errorf("%s 不是最新的，请执行 go run ./cmd/openapi-gen", path)
+		}
+	}
+	if count == 0 {
+		t.Fatal("没有生成任何服务的接口描述")
+	}
+}
+
+func TestGenerateFixture(t *testing.T) {
+	var src string
+	for path, content := range load(t) {
+		if strings.Contains(filepath.ToSlash(path), "/testdata/transport/") {
+			src = string(content)
+		}
+	}
+	if src == "" {
+		t.Fatal("测试样例没有生成接口描述")
+	}
+
+	tests := []struct {
+		handler string
+		want    []string
+		notWant []string
+	}{
+		// 摘要去掉“处理器”，端点返回的指针取元素类型
+		{"GetItemHandler", []string{`Summary: "获取条目"`, `Tag: "transport"`, `Request: endpoint.ItemRequest{}`, `Response: endpoint.ItemResponse{}`}, []string{"Bind:"}},
+		// 分别绑定路径和请求体；端点各分支返回不同类型时不写响应
+		{"UpdateItemHandler", []string{`Bind: openapi.FromURI | openapi.FromBody`}, []string{"Response:"}},
+		{"(*Uploader).UploadHandler", []string{`Files: []string{"file"}`, `Response: endpoint.ItemResponse{}`, `Raw: true`}, []string{"Request:"}},
+		{"ExportHandler", []string{`Binary: true`}, nil},
+		// 非导出类型无法在生成的代码中引用
+		{"StatsHandler", nil, []string{"Response:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.handler, func(t *testing.T) {
+			line := operationLine(src, tt.handler)
+			if line == "" {
+				t.Fatalf("缺少 %s:\n%s", tt.handler, src)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("缺少 %s: %s", want, line)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(line, notWant) {
+					t.Errorf("不应包含 %s: %s", notWant, line)
+				}
+			}
+		})
+	}
+}
+
+// operationLine 生成结果中某个处理器的描述
+func operationLine(src, handler string) string {
+	key := `"` + fixturePattern + "." + handler + `":`
+	for _, line := range strings.Split(src, "\n") {
+		if strings.Contains(line, key) {
+			return line
+		}
+	}
+	return ""
+}
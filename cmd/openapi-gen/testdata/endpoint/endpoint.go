@@ -0,0 +1,40 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// ItemRequest 条目请求
+type ItemRequest struct {
+	ID   string `uri:"id" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
+
+// ItemResponse 条目响应
+type ItemResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// GetItemEndpoint 返回指针和 nil 两种情况
+func GetItemEndpoint() endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ItemRequest)
+		if req.ID == "" {
+			return nil, nil
+		}
+		return &ItemResponse{ID: req.ID}, nil
+	}
+}
+
+// MixedEndpoint 不同分支返回不同类型
+func MixedEndpoint() endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if request == nil {
+			return ItemResponse{}, nil
+		}
+		return map[string]string{"ok": "1"}, nil
+	}
+}
@@ -0,0 +1,83 @@
+package transport
+
+import (
+	"net/http"
+
+	"mule-cloud/cmd/openapi-gen/testdata/endpoint"
+	"mule-cloud/core/binding"
+	"mule-cloud/core/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetItemHandler 获取条目处理器
+func GetItemHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req endpoint.ItemRequest
+		if err := binding.BindAll(c, &req); err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+		ep := endpoint.GetItemEndpoint()
+		resp, err := ep(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+		response.Success(c, resp)
+	}
+}
+
+// UpdateItemHandler 更新条目（先绑定路径再绑定请求体）
+func UpdateItemHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req endpoint.ItemRequest
+		if err := c.ShouldBindUri(&req); err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+		resp, err := endpoint.MixedEndpoint()(c.Request.Context(), req)
+		if err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+		response.SuccessWithMsg(c, "更新成功", resp)
+	}
+}
+
+// Uploader 上传
+type Uploader struct{}
+
+// UploadHandler 上传文件
+func (u *Uploader) UploadHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := c.FormFile("file"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, endpoint.ItemResponse{})
+	}
+}
+
+// ExportHandler 导出
+func ExportHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/csv", nil)
+	}
+}
+
+// hiddenResult 非导出类型无法在生成的代码中引用
+type hiddenResult struct {
+	Count int `json:"count"`
+}
+
+// StatsHandler 统计
+func StatsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.Success(c, hiddenResult{})
+	}
+}
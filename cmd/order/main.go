@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -8,13 +9,19 @@ import (
 	cfgPkg "mule-cloud/core/config"
 	"mule-cloud/core/cousul"
 	dbPkg "mule-cloud/core/database"
+	fieldcryptPkg "mule-cloud/core/fieldcrypt"
+	gatewayauthPkg "mule-cloud/core/gatewayauth"
 	loggerPkg "mule-cloud/core/logger"
+	metricsPkg "mule-cloud/core/metrics"
+	openapiPkg "mule-cloud/core/openapi"
 	"mule-cloud/core/response"
+	tracingPkg "mule-cloud/core/tracing"
 
 	"mule-cloud/app/order/services"
 	"mule-cloud/app/order/transport"
 	workflowServices "mule-cloud/app/workflow/services"
 	workflowTransport "mule-cloud/app/workflow/transport"
+	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
 
 	jwtPkg "mule-cloud/core/jwt"
@@ -36,13 +43,29 @@ func main() {
 	}
 
 	// 初始化日志系统
-	if err := loggerPkg.InitLogger(&cfg.Log); err != nil {
+	if err := loggerPkg.InitLogger(&cfg.Log, cfg.Server.Name); err != nil {
 		log.Fatalf("初始化日志系统失败: %v", err)
 	}
 	defer loggerPkg.Close()
 
+	// 初始化网关身份头签名（网关签名，服务校验）
+	if err := gatewayauthPkg.Init(&cfg.GatewayAuth); err != nil {
+		loggerPkg.Fatal("初始化网关身份签名失败", zap.Error(err))
+	}
+	if err := gatewayauthPkg.RequireSecret(cfg.Server.Mode); err != nil {
+		loggerPkg.Fatal("网关身份签名未配置", zap.Error(err))
+	} else if !gatewayauthPkg.Enabled() {
+		loggerPkg.Warn("未配置 gateway_auth.secret，身份头不签名也不校验，只能用于本地开发")
+	}
+
+	// 初始化链路追踪（未启用时只传递 traceparent 和 X-Request-ID）
+	shutdownTracing, err := tracingPkg.Init(&cfg.Tracing, cfg.Server.Name)
+	if err != nil {
+		loggerPkg.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
+
 	loggerPkg.Info("🚀 OrderService 启动中...",
-		zap.String("service", cfg.Server.Name),
 		zap.Int("port", cfg.Server.Port),
 	)
 
@@ -54,6 +77,16 @@ func main() {
 		}
 		dbPkg.InitDatabaseManager(client)
 		loggerPkg.Info("✅ DatabaseManager初始化成功（支持多租户数据库隔离）")
+
+		// 补建业务唯一索引（箱号、箱唛条码、发票来源等，幂等）
+		if err := repository.EnsureTenantIndexes(context.Background()); err != nil {
+			loggerPkg.Error("创建业务唯一索引失败", zap.Error(err))
+		}
+	}
+
+	// 初始化敏感字段加密（员工身份证号、银行卡号，读取员工档案的服务需要配置相同的密钥）
+	if err := fieldcryptPkg.Init(&cfg.Encryption); err != nil {
+		loggerPkg.Fatal("初始化敏感字段加密失败", zap.Error(err))
 	}
 
 	// 初始化Redis（如果启用）
@@ -73,6 +106,8 @@ func main() {
 		repository.NewCuttingPieceRepository(),
 		repository.NewOrderRepository(),
 	)
+	shipmentSvc := services.NewShipmentService()
+	receivableSvc := services.NewReceivableService()
 	commonSvc := services.NewCommonService()
 	workflowSvc := workflowServices.NewWorkflowService()
 	designerSvc := workflowServices.NewWorkflowDesignerService()
@@ -83,12 +118,24 @@ func main() {
 
 	// 全局中间件
 	r.Use(gin.Logger())
+	r.Use(tracingPkg.Middleware())
+	r.Use(metricsPkg.Middleware())
 	r.Use(response.RecoveryMiddleware())
 	r.Use(response.UnifiedResponseMiddleware())
 	r.Use(middleware.OperationLogMiddleware())
+	r.GET("/metrics", metricsPkg.Handler()) // Prometheus 指标
 
-	// 初始化 JWT 管理器（用于直接访问时验证token）
-	jwtManager := jwtPkg.NewJWTManager(nil, 0)
+	// 初始化 JWT 管理器（用于直接访问时验证token，配置 jwks_url 时从认证服务获取公钥）
+	jwtManager, err := jwtPkg.NewFromConfig(&cfg.JWT, 0)
+	if err != nil {
+		loggerPkg.Fatal("初始化JWT管理器失败", zap.Error(err))
+	}
+
+	// 日志级别管理（超级管理员，运行时按模块调整）
+	middleware.ApplyLogLevels(r, jwtManager)
+
+	// OpenAPI 文档（按注册的路由和 DTO 生成，网关聚合后在 /gateway/openapi.json 提供）
+	r.GET("/openapi.json", openapiPkg.Handler(r, openapiPkg.Info{Title: cfg.Server.Name}, transport.Operations, workflowTransport.Operations))
 
 	// Order路由组（需要认证）
 	order := r.Group("/order")
@@ -97,14 +144,15 @@ func main() {
 		// 订单路由
 		orders := order.Group("/orders")
 		{
-			orders.GET("/:id", transport.GetOrderHandler(orderSvc))                       // 获取单个订单
-			orders.GET("", transport.ListOrdersHandler(orderSvc))                         // 分页列表
-			orders.POST("", transport.CreateOrderHandler(orderSvc))                       // 创建订单（步骤1）
-			orders.PUT("/:id/style", transport.UpdateOrderStyleHandler(orderSvc))         // 更新款式数量（步骤2）
-			orders.PUT("/:id/procedure", transport.UpdateOrderProcedureHandler(orderSvc)) // 更新工序（步骤3）
-			orders.PUT("/:id", transport.UpdateOrderHandler(orderSvc))                    // 更新订单
-			orders.POST("/:id/copy", transport.CopyOrderHandler(orderSvc))                // 复制订单
-			orders.DELETE("/:id", transport.DeleteOrderHandler(orderSvc))                 // 删除订单
+			orders.GET("/:id", transport.GetOrderHandler(orderSvc))                                    // 获取单个订单
+			orders.GET("", transport.ListOrdersHandler(orderSvc))                                      // 分页列表
+			orders.POST("", transport.CreateOrderHandler(orderSvc))                                    // 创建订单（步骤1）
+			orders.PUT("/:id/style", transport.UpdateOrderStyleHandler(orderSvc))                      // 更新款式数量（步骤2）
+			orders.PUT("/:id/procedure", transport.UpdateOrderProcedureHandler(orderSvc))              // 更新工序（步骤3）
+			orders.PUT("/:id/standard-minutes", transport.UpdateOrderStandardMinutesHandler(orderSvc)) // 设置工序标准工时
+			orders.PUT("/:id", transport.UpdateOrderHandler(orderSvc))                                 // 更新订单
+			orders.POST("/:id/copy", transport.CopyOrderHandler(orderSvc))                             // 复制订单
+			orders.DELETE("/:id", transport.DeleteOrderHandler(orderSvc))                              // 删除订单
 			// 工作流相关
 			orders.POST("/:id/workflow/transition", transport.TransitionOrderWorkflowHandler(orderSvc))      // 执行工作流状态转换
 			orders.GET("/:id/workflow/state", transport.GetOrderWorkflowStateHandler(orderSvc))              // 获取工作流状态
@@ -114,12 +162,14 @@ func main() {
 		// 款式路由
 		styles := order.Group("/styles")
 		{
-			styles.GET("/:id", transport.GetStyleHandler(styleSvc))       // 获取单个款式
-			styles.GET("", transport.ListStylesHandler(styleSvc))         // 分页列表
-			styles.GET("/all", transport.GetAllStylesHandler(styleSvc))   // 获取所有（不分页）
-			styles.POST("", transport.CreateStyleHandler(styleSvc))       // 创建款式
-			styles.PUT("/:id", transport.UpdateStyleHandler(styleSvc))    // 更新款式
-			styles.DELETE("/:id", transport.DeleteStyleHandler(styleSvc)) // 删除款式
+			styles.GET("/:id", transport.GetStyleHandler(styleSvc))                               // 获取单个款式
+			styles.GET("", transport.ListStylesHandler(styleSvc))                                 // 分页列表
+			styles.GET("/all", transport.GetAllStylesHandler(styleSvc))                           // 获取所有（不分页）
+			styles.POST("", transport.CreateStyleHandler(styleSvc))                               // 创建款式
+			styles.PUT("/:id", transport.UpdateStyleHandler(styleSvc))                            // 更新款式
+			styles.DELETE("/:id", transport.DeleteStyleHandler(styleSvc))                         // 删除款式
+			styles.POST("/:id/procedure-template", transport.ApplyStyleTemplateHandler(styleSvc)) // 套用工序模板
+			styles.POST("/reprice", transport.RepriceStylesHandler(styleSvc))                     // 按 SAM×费率 重算工价
 		}
 
 		// 裁剪路由
@@ -156,6 +206,45 @@ func main() {
 			}
 		}
 
+		// 装箱路由
+		packing := order.Group("/packing")
+		{
+			packing.POST("/cartons", transport.PackCartonsHandler(shipmentSvc))                         // 装箱（单色单码/混色混码）
+			packing.GET("/cartons", transport.ListCartonsHandler(shipmentSvc))                          // 装箱列表
+			packing.GET("/cartons/barcode/:barcode", transport.GetCartonByBarcodeHandler(shipmentSvc))  // 扫描箱唛条码
+			packing.GET("/cartons/:id", transport.GetCartonHandler(shipmentSvc))                        // 装箱详情
+			packing.DELETE("/cartons/:id", transport.DeleteCartonHandler(shipmentSvc))                  // 删除装箱
+			packing.POST("/cartons/labels", transport.PrintCartonLabelsHandler(shipmentSvc))            // 打印箱唛
+			packing.GET("/orders/:order_id/packing-list", transport.GetPackingListHandler(shipmentSvc)) // 订单装箱单
+		}
+
+		// 发货路由
+		shipments := order.Group("/shipments")
+		{
+			shipments.POST("", transport.CreateShipmentHandler(shipmentSvc))        // 创建发货单（支持分批发货）
+			shipments.GET("", transport.ListShipmentsHandler(shipmentSvc))          // 发货单列表
+			shipments.GET("/:id", transport.GetShipmentHandler(shipmentSvc))        // 发货单详情
+			shipments.POST("/:id/void", transport.VoidShipmentHandler(shipmentSvc)) // 作废发货单
+		}
+
+		// 应收账款路由
+		receivables := order.Group("/receivables")
+		{
+			receivables.GET("/accounts/:customer_id", transport.GetCustomerAccountHandler(receivableSvc))         // 客户信用条款及账龄
+			receivables.PUT("/accounts/:customer_id", transport.SaveCustomerAccountHandler(receivableSvc))        // 保存客户信用条款
+			receivables.GET("/accounts/:customer_id/statement", transport.StatementHandler(receivableSvc))        // 客户对账单
+			receivables.GET("/accounts/:customer_id/statement/pdf", transport.StatementPDFHandler(receivableSvc)) // 导出对账单PDF
+			receivables.POST("/invoices", transport.CreateInvoiceHandler(receivableSvc))                          // 开票（发货单/订单）
+			receivables.GET("/invoices", transport.ListInvoicesHandler(receivableSvc))                            // 发票列表
+			receivables.GET("/invoices/:id", transport.GetInvoiceHandler(receivableSvc))                          // 发票详情
+			receivables.POST("/invoices/:id/void", transport.VoidInvoiceHandler(receivableSvc))                   // 作废发票
+			receivables.POST("/receipts", transport.CreateReceiptHandler(receivableSvc))                          // 登记收款并核销
+			receivables.GET("/receipts", transport.ListReceiptsHandler(receivableSvc))                            // 收款单列表
+			receivables.GET("/receipts/:id", transport.GetReceiptHandler(receivableSvc))                          // 收款单详情
+			receivables.POST("/receipts/:id/void", transport.VoidReceiptHandler(receivableSvc))                   // 作废收款单
+			receivables.GET("/aging", transport.AgingReportHandler(receivableSvc))                                // 账龄分析（按客户/业务员）
+		}
+
 		// 工作流路由
 		workflow := order.Group("/workflow")
 		{
@@ -169,7 +258,7 @@ func main() {
 			workflow.POST("/rollback", workflowTransport.RollbackOrderHandler(workflowSvc))                       // 回滚状态
 
 			// 工作流设计器路由
-			designer := workflow.Group("/designer")
+			designer := workflow.Group("/designer", middleware.RequireFeature(models.FeatureWorkflowDesigner)) // 需要套餐开通工作流设计器
 			{
 				designer.GET("/definitions", workflowTransport.ListWorkflowDefinitionsHandler(designerSvc))                      // 获取工作流定义列表
 				designer.POST("/definitions", workflowTransport.CreateWorkflowDefinitionHandler(designerSvc))                    // 创建工作流定义
@@ -207,7 +296,7 @@ func main() {
 		}
 
 		loggerPkg.Info("准备注册到Consul",
-			zap.String("service", serviceConfig.ServiceName),
+			zap.String("consul_service", serviceConfig.ServiceName),
 			zap.Int("port", serviceConfig.ServicePort),
 			zap.String("consul", cfg.Consul.Address),
 		)
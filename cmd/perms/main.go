@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -8,13 +9,19 @@ import (
 	cfgPkg "mule-cloud/core/config"
 	"mule-cloud/core/cousul"
 	dbPkg "mule-cloud/core/database"
+	gatewayauthPkg "mule-cloud/core/gatewayauth"
 	loggerPkg "mule-cloud/core/logger"
+	metricsPkg "mule-cloud/core/metrics"
+	openapiPkg "mule-cloud/core/openapi"
+	passwordPkg "mule-cloud/core/password"
 	"mule-cloud/core/response"
+	tracingPkg "mule-cloud/core/tracing"
 
 	"mule-cloud/app/perms/services"
 	"mule-cloud/app/perms/transport"
 	jwtPkg "mule-cloud/core/jwt"
 	"mule-cloud/core/middleware"
+	"mule-cloud/internal/repository"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -31,13 +38,32 @@ func main() {
 		log.Fatalf("加载配置失败: %v", err)
 	}
 	// 初始化日志系统
-	if err := loggerPkg.InitLogger(&cfg.Log); err != nil {
+	if err := loggerPkg.InitLogger(&cfg.Log, cfg.Server.Name); err != nil {
 		log.Fatalf("初始化日志系统失败: %v", err)
 	}
 	defer loggerPkg.Close()
 
+	// 初始化网关身份头签名（网关签名，服务校验）
+	if err := gatewayauthPkg.Init(&cfg.GatewayAuth); err != nil {
+		loggerPkg.Fatal("初始化网关身份签名失败", zap.Error(err))
+	}
+	if err := gatewayauthPkg.RequireSecret(cfg.Server.Mode); err != nil {
+		loggerPkg.Fatal("网关身份签名未配置", zap.Error(err))
+	} else if !gatewayauthPkg.Enabled() {
+		loggerPkg.Warn("未配置 gateway_auth.secret，身份头不签名也不校验，只能用于本地开发")
+	}
+
+	// 初始化链路追踪（未启用时只传递 traceparent 和 X-Request-ID）
+	shutdownTracing, err := tracingPkg.Init(&cfg.Tracing, cfg.Server.Name)
+	if err != nil {
+		loggerPkg.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
+
+	// 初始化密码哈希参数与密码策略
+	passwordPkg.Init(&cfg.Password)
+
 	loggerPkg.Info("🚀 PermsService 启动中...",
-		zap.String("service", cfg.Server.Name),
 		zap.Int("port", cfg.Server.Port),
 	)
 
@@ -49,6 +75,14 @@ func main() {
 		}
 		dbPkg.InitDatabaseManager(client)
 		loggerPkg.Info("✅ DatabaseManager初始化成功（支持多租户数据库隔离）")
+
+		// API密钥按哈希唯一索引（网关按哈希查找）
+		if err := repository.NewAPIKeyRepository().CreateIndexes(context.Background()); err != nil {
+			loggerPkg.Warn("创建API密钥索引失败", zap.Error(err))
+		}
+		if err := repository.NewPlanRepository().CreateIndexes(context.Background()); err != nil {
+			loggerPkg.Warn("创建套餐索引失败", zap.Error(err))
+		}
 	}
 
 	// 初始化Redis（如果启用）
@@ -66,9 +100,15 @@ func main() {
 	roleSvc := services.NewRoleService()
 	deptSvc := services.NewDepartmentService()
 	postSvc := services.NewPostService()
+	apiKeySvc := services.NewAPIKeyService()
+	planSvc := services.NewPlanService()
+	permSvc := services.NewPermissionService()
 
-	// 初始化 JWT 管理器（用于直接访问时验证token）
-	jwtManager := jwtPkg.NewJWTManager(nil, 0)
+	// 初始化 JWT 管理器（用于直接访问时验证token，配置 jwks_url 时从认证服务获取公钥）
+	jwtManager, err := jwtPkg.NewFromConfig(&cfg.JWT, 0)
+	if err != nil {
+		loggerPkg.Fatal("初始化JWT管理器失败", zap.Error(err))
+	}
 
 	// 初始化路由
 	gin.SetMode(cfg.Server.Mode)
@@ -76,9 +116,18 @@ func main() {
 
 	// 全局中间件
 	r.Use(gin.Logger())
+	r.Use(tracingPkg.Middleware())
+	r.Use(metricsPkg.Middleware())
 	r.Use(response.RecoveryMiddleware())
 	r.Use(response.UnifiedResponseMiddleware())
 	r.Use(middleware.OperationLogMiddleware())
+	r.GET("/metrics", metricsPkg.Handler()) // Prometheus 指标
+
+	// 日志级别管理（超级管理员，运行时按模块调整）
+	middleware.ApplyLogLevels(r, jwtManager)
+
+	// OpenAPI 文档（按注册的路由和 DTO 生成，网关聚合后在 /gateway/openapi.json 提供）
+	r.GET("/openapi.json", openapiPkg.Handler(r, openapiPkg.Info{Title: cfg.Server.Name}, transport.Operations))
 
 	// Perms路由组
 	perms := r.Group("/perms")
@@ -95,6 +144,11 @@ func main() {
 			tenant.DELETE("/:id", transport.DeleteTenantHandler(tenantSvc))                                    // 删除租户
 			tenant.POST("/:id/menus", transport.AssignTenantMenusHandler(tenantSvc.(*services.TenantService))) // 分配菜单权限（超管）
 			tenant.GET("/:id/menus", transport.GetTenantMenusHandler(tenantSvc.(*services.TenantService)))     // 获取租户菜单权限
+			tenant.GET("/:id/sso", transport.GetTenantSSOHandler(tenantSvc.(*services.TenantService)))         // 获取单点登录配置
+			tenant.PUT("/:id/sso", transport.UpdateTenantSSOHandler(tenantSvc.(*services.TenantService)))      // 配置单点登录（OIDC）
+			tenant.PUT("/:id/subscription", transport.SetTenantSubscriptionHandler(planSvc))                   // 设置订阅套餐（超管）
+			tenant.GET("/:id/usage", transport.GetTenantUsageHandler(planSvc))                                 // 租户套餐用量（超管）
+			tenant.GET("/usage", transport.ListTenantUsageHandler(planSvc))                                    // 全部租户用量看板（超管）
 		}
 
 		// 管理员路由
@@ -109,6 +163,12 @@ func main() {
 			admin.POST("/:id/roles", transport.AssignAdminRolesHandler(adminSvc.(*services.AdminService)))          // 分配角色
 			admin.GET("/:id/roles", transport.GetAdminRolesHandler(adminSvc.(*services.AdminService)))              // 获取管理员角色
 			admin.DELETE("/:id/roles/:roleId", transport.RemoveAdminRoleHandler(adminSvc.(*services.AdminService))) // 移除角色
+			admin.GET("/:id/sessions", transport.ListAdminSessionsHandler(adminSvc))                                // 登录会话
+			admin.DELETE("/:id/sessions", transport.RevokeAdminSessionsHandler(adminSvc))                           // 注销全部会话（踢下线）
+			admin.DELETE("/:id/sessions/:sid", transport.RevokeAdminSessionsHandler(adminSvc))                      // 注销指定会话
+			admin.GET("/:id/lock", transport.GetAdminLockStatusHandler(adminSvc))                                   // 登录锁定状态
+			admin.POST("/:id/unlock", transport.UnlockAdminHandler(adminSvc))                                       // 解锁登录
+			admin.POST("/:id/2fa/reset", transport.ResetAdminTwoFactorHandler(adminSvc))                            // 重置两步验证
 		}
 
 		// 菜单路由（Nova-admin前端路由数据）
@@ -160,6 +220,32 @@ func main() {
 			post.DELETE("/:id", transport.DeletePostHandler(postSvc))              // 删除岗位
 			post.POST("/batch-delete", transport.BatchDeletePostsHandler(postSvc)) // 批量删除
 		}
+
+		// API密钥路由（机器对机器集成，网关通过 X-API-Key 认证）
+		apiKey := perms.Group("/api-keys")
+		{
+			apiKey.GET("/:id", transport.GetAPIKeyHandler(apiKeySvc))       // 获取单个密钥
+			apiKey.GET("", transport.ListAPIKeysHandler(apiKeySvc))         // 分页列表
+			apiKey.POST("", transport.CreateAPIKeyHandler(apiKeySvc))       // 创建密钥（明文只返回一次）
+			apiKey.PUT("/:id", transport.UpdateAPIKeyHandler(apiKeySvc))    // 更新权限/白名单/有效期/状态
+			apiKey.DELETE("/:id", transport.DeleteAPIKeyHandler(apiKeySvc)) // 吊销密钥
+		}
+
+		// 订阅套餐路由（配额和功能开通，系统超管）
+		plan := perms.Group("/plans")
+		{
+			plan.GET("", transport.ListPlansHandler(planSvc))         // 套餐列表
+			plan.POST("", transport.CreatePlanHandler(planSvc))       // 创建套餐
+			plan.PUT("/:id", transport.UpdatePlanHandler(planSvc))    // 更新套餐
+			plan.DELETE("/:id", transport.DeletePlanHandler(planSvc)) // 删除套餐（有租户订阅时不能删除）
+		}
+
+		// 权限排查（模拟鉴权，解释拒绝原因）
+		permission := perms.Group("/permissions")
+		{
+			permission.POST("/simulate", transport.SimulatePermissionHandler(permSvc)) // 模拟管理员/角色访问接口
+			permission.GET("/matrix", transport.GetPermissionMatrixHandler(permSvc))   // 角色的有效权限矩阵
+		}
 	}
 
 	// 健康检查路由
@@ -186,7 +272,7 @@ func main() {
 		}
 
 		loggerPkg.Info("准备注册到Consul",
-			zap.String("service", serviceConfig.ServiceName),
+			zap.String("consul_service", serviceConfig.ServiceName),
 			zap.Int("port", serviceConfig.ServicePort),
 			zap.String("consul", cfg.Consul.Address),
 		)
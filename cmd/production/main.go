@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -8,11 +9,17 @@ import (
 	cfgPkg "mule-cloud/core/config"
 	"mule-cloud/core/cousul"
 	dbPkg "mule-cloud/core/database"
+	fieldcryptPkg "mule-cloud/core/fieldcrypt"
+	gatewayauthPkg "mule-cloud/core/gatewayauth"
 	loggerPkg "mule-cloud/core/logger"
+	metricsPkg "mule-cloud/core/metrics"
+	openapiPkg "mule-cloud/core/openapi"
 	"mule-cloud/core/response"
+	tracingPkg "mule-cloud/core/tracing"
 
 	"mule-cloud/app/production/services"
 	"mule-cloud/app/production/transport"
+	"mule-cloud/internal/models"
 
 	jwtPkg "mule-cloud/core/jwt"
 	"mule-cloud/core/middleware"
@@ -33,13 +40,29 @@ func main() {
 	}
 
 	// 初始化日志系统
-	if err := loggerPkg.InitLogger(&cfg.Log); err != nil {
+	if err := loggerPkg.InitLogger(&cfg.Log, cfg.Server.Name); err != nil {
 		log.Fatalf("初始化日志系统失败: %v", err)
 	}
 	defer loggerPkg.Close()
 
+	// 初始化网关身份头签名（网关签名，服务校验）
+	if err := gatewayauthPkg.Init(&cfg.GatewayAuth); err != nil {
+		loggerPkg.Fatal("初始化网关身份签名失败", zap.Error(err))
+	}
+	if err := gatewayauthPkg.RequireSecret(cfg.Server.Mode); err != nil {
+		loggerPkg.Fatal("网关身份签名未配置", zap.Error(err))
+	} else if !gatewayauthPkg.Enabled() {
+		loggerPkg.Warn("未配置 gateway_auth.secret，身份头不签名也不校验，只能用于本地开发")
+	}
+
+	// 初始化链路追踪（未启用时只传递 traceparent 和 X-Request-ID）
+	shutdownTracing, err := tracingPkg.Init(&cfg.Tracing, cfg.Server.Name)
+	if err != nil {
+		loggerPkg.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
+
 	loggerPkg.Info("🚀 ProductionService 启动中...",
-		zap.String("service", cfg.Server.Name),
 		zap.Int("port", cfg.Server.Port),
 	)
 
@@ -53,6 +76,11 @@ func main() {
 		loggerPkg.Info("✅ DatabaseManager初始化成功（支持多租户数据库隔离）")
 	}
 
+	// 初始化敏感字段加密（员工身份证号、银行卡号，读取员工档案的服务需要配置相同的密钥）
+	if err := fieldcryptPkg.Init(&cfg.Encryption); err != nil {
+		loggerPkg.Fatal("初始化敏感字段加密失败", zap.Error(err))
+	}
+
 	// 初始化Redis（如果启用）
 	if cfg.Redis.Enabled {
 		if _, err := cachePkg.InitRedis(&cfg.Redis); err != nil {
@@ -66,6 +94,7 @@ func main() {
 	reportSvc := services.NewReportService()
 	qualitySvc := services.NewQualityService()
 	reworkSvc := services.NewReworkService()
+	efficiencySvc := services.NewEfficiencyService()
 
 	// 初始化路由
 	gin.SetMode(cfg.Server.Mode)
@@ -73,12 +102,24 @@ func main() {
 
 	// 全局中间件
 	r.Use(gin.Logger())
+	r.Use(tracingPkg.Middleware())
+	r.Use(metricsPkg.Middleware())
 	r.Use(response.RecoveryMiddleware())
 	r.Use(response.UnifiedResponseMiddleware())
 	r.Use(middleware.OperationLogMiddleware())
+	r.GET("/metrics", metricsPkg.Handler()) // Prometheus 指标
+
+	// 初始化 JWT 管理器（用于直接访问时验证token，配置 jwks_url 时从认证服务获取公钥）
+	jwtManager, err := jwtPkg.NewFromConfig(&cfg.JWT, 0)
+	if err != nil {
+		loggerPkg.Fatal("初始化JWT管理器失败", zap.Error(err))
+	}
+
+	// 日志级别管理（超级管理员，运行时按模块调整）
+	middleware.ApplyLogLevels(r, jwtManager)
 
-	// 初始化 JWT 管理器（用于直接访问时验证token）
-	jwtManager := jwtPkg.NewJWTManager(nil, 0)
+	// OpenAPI 文档（按注册的路由和 DTO 生成，网关聚合后在 /gateway/openapi.json 提供）
+	r.GET("/openapi.json", openapiPkg.Handler(r, openapiPkg.Info{Title: cfg.Server.Name}, transport.Operations))
 
 	// Production路由组（需要认证）
 	production := r.Group("/production")
@@ -102,8 +143,23 @@ func main() {
 		// 工资统计路由
 		production.GET("/salary", transport.GetSalaryHandler(reportSvc)) // 工资统计
 
+		// 工人效率路由（工人只能查看本人数据）
+		efficiency := production.Group("/efficiency")
+		{
+			efficiency.GET("", transport.GetEfficiencyHandler(efficiencySvc))            // 效率报表（工人/班组/车间排名）
+			efficiency.GET("/trend", transport.GetEfficiencyTrendHandler(efficiencySvc)) // 效率趋势（按天）
+		}
+
+		// 出勤路由（效率计算的出勤工时）
+		attendances := production.Group("/attendances")
+		{
+			attendances.POST("", transport.SaveAttendancesHandler(efficiencySvc))        // 录入出勤（主管）
+			attendances.GET("", transport.ListAttendancesHandler(efficiencySvc))         // 出勤列表
+			attendances.DELETE("/:id", transport.DeleteAttendanceHandler(efficiencySvc)) // 删除出勤（主管）
+		}
+
 		// 质检路由
-		inspections := production.Group("/inspections")
+		inspections := production.Group("/inspections", middleware.RequireFeature(models.FeatureQuality)) // 需要套餐开通质检
 		{
 			inspections.POST("", transport.SubmitInspectionHandler(qualitySvc))       // 提交质检
 			inspections.GET("", transport.GetInspectionListHandler(qualitySvc))       // 质检列表
@@ -112,7 +168,7 @@ func main() {
 		}
 
 		// 返工路由
-		reworks := production.Group("/reworks")
+		reworks := production.Group("/reworks", middleware.RequireFeature(models.FeatureQuality))
 		{
 			reworks.POST("", transport.CreateReworkHandler(reworkSvc))               // 创建返工单
 			reworks.GET("", transport.GetReworkListHandler(reworkSvc))               // 返工列表
@@ -146,7 +202,7 @@ func main() {
 		}
 
 		loggerPkg.Info("准备注册到Consul",
-			zap.String("service", serviceConfig.ServiceName),
+			zap.String("consul_service", serviceConfig.ServiceName),
 			zap.Int("port", serviceConfig.ServicePort),
 			zap.String("consul", cfg.Consul.Address),
 		)
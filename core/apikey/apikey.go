@@ -0,0 +1,166 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"mule-cloud/core/casbin"
+	"mule-cloud/internal/models"
+)
+
+// KeyPrefix API密钥的固定前缀，便于在日志和代码仓库中识别泄露的密钥
+const KeyPrefix = "mk_"
+
+// displayLen 保存并展示的密钥前缀长度（KeyPrefix + 8位）
+const displayLen = len(KeyPrefix) + 8
+
+// UserIDPrefix 使用API密钥调用时记录的操作人ID前缀（user_id = apikey:<密钥ID>）
+const UserIDPrefix = "apikey:"
+
+// Generate 生成新密钥，返回明文（只展示一次）、展示用前缀和存储用哈希
+func Generate() (key, prefix, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("生成API密钥失败: %w", err)
+	}
+	key = KeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:displayLen], Hash(key), nil
+}
+
+// Hash 密钥哈希（密钥本身是高熵随机串，SHA-256 即可，无需慢哈希）
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Expired 是否已过期
+func Expired(k *models.APIKey, now time.Time) bool {
+	return k.ExpiresAt > 0 && now.Unix() >= k.ExpiresAt
+}
+
+// IPAllowed 检查IP是否在白名单内（白名单为空表示不限制）
+func IPAllowed(allowed []string, ip string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, entry := range allowed {
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(addr) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed 检查密钥权限是否允许访问资源（与 Casbin 默认模型的匹配规则一致）
+func Allowed(perms []models.APIKeyPermission, resource, action string) bool {
+	for _, p := range perms {
+		if casbin.MatchPolicy(resource, action, p.Resource, p.Action) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePermissions 校验权限配置（资源必须以 / 开头，动作必须是合法正则）
+func ValidatePermissions(perms []models.APIKeyPermission) error {
+	if len(perms) == 0 {
+		return errors.New("至少需要配置一项权限")
+	}
+	for _, p := range perms {
+		if !strings.HasPrefix(p.Resource, "/") {
+			return fmt.Errorf("权限资源必须以 / 开头: %s", p.Resource)
+		}
+		if p.Action == "" {
+			return fmt.Errorf("权限 %s 缺少动作", p.Resource)
+		}
+		if _, err := regexp.Compile(p.Action); err != nil {
+			return fmt.Errorf("权限动作不是合法的正则表达式: %s", p.Action)
+		}
+	}
+	return nil
+}
+
+// WithinPolicies 检查密钥权限是否都在给定策略（创建者的有效权限）范围内，防止用密钥给自己扩权
+//
+// 具体资源要能被策略路径匹配（keyMatch2）；含 * 或 :param 的资源是路径模式，keyMatch2 会把它当作具体路径，
+// /order/* 能被 /order/:id 匹配却覆盖更多路径，因此只接受与策略完全相同的模式。
+// 动作只接受字面量或 a|b 形式的字面量列表，每个动作都要有策略允许，.* 这类无法确定范围的动作一律拒绝。
+func WithinPolicies(perms []models.APIKeyPermission, policies []casbin.Policy) error {
+	for _, p := range perms {
+		actions := literalActions(p.Action)
+		if actions == nil {
+			return fmt.Errorf("权限动作只能是具体动作（如 read 或 read|create）: %s", p.Action)
+		}
+		for _, act := range actions {
+			if !policyAllows(policies, p.Resource, act) {
+				return fmt.Errorf("权限超出当前账号的权限范围: %s (%s)", p.Resource, act)
+			}
+		}
+	}
+	return nil
+}
+
+// literalActions 把 read|create、(read)|(create) 拆成动作列表，含其他正则语法时返回 nil
+func literalActions(action string) []string {
+	var actions []string
+	for _, part := range strings.Split(action, "|") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "(") && strings.HasSuffix(part, ")") {
+			part = part[1 : len(part)-1]
+		}
+		if part == "" || regexp.QuoteMeta(part) != part {
+			return nil
+		}
+		actions = append(actions, part)
+	}
+	return actions
+}
+
+// policyAllows 是否有策略允许该资源和动作（路径模式只与相同的策略路径比较）
+func policyAllows(policies []casbin.Policy, resource, action string) bool {
+	pattern := isPathPattern(resource)
+	for _, p := range policies {
+		if pattern && p.Object != resource {
+			continue
+		}
+		if casbin.MatchPolicy(resource, action, p.Object, p.Action) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPathPattern 资源是否为路径模式（含 * 通配或 :param 参数）
+func isPathPattern(resource string) bool {
+	return strings.Contains(resource, "*") || strings.Contains(resource, "/:")
+}
+
+// ValidateAllowedIPs 校验IP白名单（IP或CIDR）
+func ValidateAllowedIPs(ips []string) error {
+	for _, entry := range ips {
+		if strings.Contains(entry, "/") {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return fmt.Errorf("无效的CIDR: %s", entry)
+			}
+		} else if net.ParseIP(entry) == nil {
+			return fmt.Errorf("无效的IP地址: %s", entry)
+		}
+	}
+	return nil
+}
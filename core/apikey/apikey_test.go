@@ -0,0 +1,117 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"mule-cloud/core/casbin"
+	"mule-cloud/internal/models"
+)
+
+// TestGenerate 测试密钥格式和哈希
+func TestGenerate(t *testing.T) {
+	key, prefix, hash, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.HasPrefix(key, KeyPrefix) || !strings.HasPrefix(key, prefix) || len(prefix) != displayLen {
+		t.Errorf("Generate() key = %s, prefix = %s", key, prefix)
+	}
+	if Hash(key) != hash {
+		t.Error("Hash() should match the generated hash")
+	}
+}
+
+// TestAllowed 测试权限匹配（keyMatch2 + 正则）
+func TestAllowed(t *testing.T) {
+	perms := []models.APIKeyPermission{
+		{Resource: "/order/orders/*", Action: "read|create"},
+		{Resource: "/basic/colors/:id", Action: "update"},
+	}
+	tests := []struct {
+		resource, action string
+		want             bool
+	}{
+		{"/order/orders/123", "read", true},
+		{"/order/orders", "create", false},
+		{"/order/orders/123", "delete", false},
+		{"/basic/colors/abc", "update", true},
+		{"/basic/colors/abc/extra", "update", false},
+		{"/perms/api-keys", "create", false},
+	}
+	for _, tt := range tests {
+		if got := Allowed(perms, tt.resource, tt.action); got != tt.want {
+			t.Errorf("Allowed(%s, %s) = %v, want %v", tt.resource, tt.action, got, tt.want)
+		}
+	}
+}
+
+// TestIPAllowed 测试IP白名单
+func TestIPAllowed(t *testing.T) {
+	allowed := []string{"10.0.0.0/8", "192.168.1.10"}
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"not-an-ip", false},
+	}
+	for _, tt := range tests {
+		if got := IPAllowed(allowed, tt.ip); got != tt.want {
+			t.Errorf("IPAllowed(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+	if !IPAllowed(nil, "8.8.8.8") {
+		t.Error("IPAllowed() with empty allowlist should allow any IP")
+	}
+}
+
+// TestValidatePermissions 测试权限配置校验
+func TestValidatePermissions(t *testing.T) {
+	if err := ValidatePermissions(nil); err == nil {
+		t.Error("ValidatePermissions() should reject empty permissions")
+	}
+	if err := ValidatePermissions([]models.APIKeyPermission{{Resource: "order", Action: "read"}}); err == nil {
+		t.Error("ValidatePermissions() should reject resource without leading slash")
+	}
+	if err := ValidatePermissions([]models.APIKeyPermission{{Resource: "/order", Action: "read("}}); err == nil {
+		t.Error("ValidatePermissions() should reject invalid action regex")
+	}
+}
+
+// TestWithinPolicies 测试密钥权限不能超出创建者的有效权限
+func TestWithinPolicies(t *testing.T) {
+	policies := []casbin.Policy{
+		{Subject: "role", Object: "/order/orders", Action: "read"},
+		{Subject: "role", Object: "/order/orders", Action: "create"},
+		{Subject: "role", Object: "/basic/*", Action: "read"},
+		{Subject: "role", Object: "/order/orders/:id", Action: "read"},
+	}
+	tests := []struct {
+		name    string
+		perm    models.APIKeyPermission
+		wantErr bool
+	}{
+		{"相同权限", models.APIKeyPermission{Resource: "/order/orders", Action: "read"}, false},
+		{"相同的通配模式", models.APIKeyPermission{Resource: "/basic/*", Action: "read"}, false},
+		{"相同的参数模式", models.APIKeyPermission{Resource: "/order/orders/:id", Action: "read"}, false},
+		{"参数模式下的具体资源", models.APIKeyPermission{Resource: "/order/orders/o1", Action: "read"}, false},
+		{"通配放大参数模式", models.APIKeyPermission{Resource: "/order/orders/*", Action: "read"}, true},
+		{"与通配策略不同的参数模式", models.APIKeyPermission{Resource: "/basic/:kind", Action: "read"}, true},
+		{"通配模式下的参数模式", models.APIKeyPermission{Resource: "/basic/colors/:id", Action: "read"}, true},
+		{"动作列表", models.APIKeyPermission{Resource: "/order/orders", Action: "(read)|(create)"}, false},
+		{"通配路径下的资源", models.APIKeyPermission{Resource: "/basic/colors", Action: "read"}, false},
+		{"超出动作", models.APIKeyPermission{Resource: "/order/orders", Action: "read|delete"}, true},
+		{"通配动作", models.APIKeyPermission{Resource: "/order/orders", Action: ".*"}, true},
+		{"通配资源", models.APIKeyPermission{Resource: "/order/*", Action: "read"}, true},
+		{"其他资源", models.APIKeyPermission{Resource: "/system/admins", Action: "read"}, true},
+	}
+	for _, tt := range tests {
+		err := WithinPolicies([]models.APIKeyPermission{tt.perm}, policies)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: WithinPolicies() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
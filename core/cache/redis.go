@@ -3,14 +3,19 @@ package cache
 import (
 	"context"
 	"fmt"
-	"log"
 	"mule-cloud/core/config"
+	"mule-cloud/core/logger"
+	"mule-cloud/core/metrics"
 	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
+// log 缓存的模块日志
+var log = logger.For("cache")
+
 var (
 	redisClient *redis.Client
 	redisOnce   sync.Once
@@ -23,7 +28,7 @@ var Redis = &RedisInstance{}
 // InitRedis 初始化Redis连接
 func InitRedis(cfg *config.RedisConfig) (*redis.Client, error) {
 	if !cfg.Enabled {
-		log.Println("⚠️  Redis未启用")
+		log.Warn("Redis未启用")
 		return nil, nil
 	}
 
@@ -47,10 +52,11 @@ func InitRedis(cfg *config.RedisConfig) (*redis.Client, error) {
 		return nil, fmt.Errorf("连接Redis失败: %v", err)
 	}
 
-	log.Printf("✅ Redis连接成功: %s:%d (DB:%d)", cfg.Host, cfg.Port, cfg.DB)
+	log.Info("Redis连接成功", zap.String("host", cfg.Host), zap.Int("port", cfg.Port), zap.Int("db", cfg.DB))
 
 	// 保存全局实例
 	redisClient = client
+	metrics.RegisterRedisPool(client)
 
 	return client, nil
 }
@@ -63,6 +69,16 @@ func GetRedis() *redis.Client {
 	return redisClient
 }
 
+// SetRedis 使用已创建的客户端作为全局实例（测试中配合 miniredis 使用），传 nil 表示未启用
+func SetRedis(client *redis.Client) {
+	redisClient = client
+}
+
+// RedisEnabled Redis是否已初始化（未启用时依赖Redis的功能应降级）
+func RedisEnabled() bool {
+	return redisClient != nil
+}
+
 // CloseRedis 关闭Redis连接
 func CloseRedis() error {
 	if redisClient == nil {
@@ -73,7 +89,7 @@ func CloseRedis() error {
 		return fmt.Errorf("关闭Redis连接失败: %v", err)
 	}
 
-	log.Println("✅ Redis连接已关闭")
+	log.Info("Redis连接已关闭")
 	return nil
 }
 
@@ -2,12 +2,14 @@ package casbin
 
 import (
 	"fmt"
-	"log"
+	"mule-cloud/core/logger"
 	"sync"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/util"
 	mongodbadapter "github.com/casbin/mongodb-adapter/v3"
+	"go.uber.org/zap"
 )
 
 var (
@@ -16,6 +18,9 @@ var (
 	once     sync.Once
 )
 
+// log 鉴权的模块日志
+var log = logger.For("casbin")
+
 // Config Casbin 配置
 type Config struct {
 	MongoURI     string // MongoDB 连接URI
@@ -58,10 +63,10 @@ func InitCasbin(cfg *Config) (*casbin.Enforcer, error) {
 
 		// 加载策略
 		if loadErr := Enforcer.LoadPolicy(); loadErr != nil {
-			log.Printf("⚠️  加载Casbin策略失败: %v", loadErr)
+			log.Warn("加载Casbin策略失败", zap.Error(loadErr))
 		}
 
-		log.Println("✅ Casbin 初始化成功")
+		log.Info("Casbin 初始化成功")
 	})
 
 	return Enforcer, err
@@ -96,6 +101,11 @@ func CheckPermission(sub, obj, act string) (bool, error) {
 	return Enforcer.Enforce(sub, obj, act)
 }
 
+// MatchPolicy 按默认模型的匹配规则判断请求是否命中策略（不经过 Enforcer，用于API密钥等自带权限的主体）
+func MatchPolicy(obj, act, policyObj, policyAct string) bool {
+	return util.KeyMatch2(obj, policyObj) && util.RegexMatch(act, policyAct)
+}
+
 // AddPolicy 添加权限策略
 func AddPolicy(sub, obj, act string) (bool, error) {
 	if Enforcer == nil {
@@ -109,7 +119,7 @@ func AddPolicy(sub, obj, act string) (bool, error) {
 
 	// 持久化
 	if saveErr := Enforcer.SavePolicy(); saveErr != nil {
-		log.Printf("⚠️  保存策略失败: %v", saveErr)
+		log.Warn("保存策略失败", zap.Error(saveErr))
 	}
 
 	return added, nil
@@ -128,7 +138,7 @@ func RemovePolicy(sub, obj, act string) (bool, error) {
 
 	// 持久化
 	if saveErr := Enforcer.SavePolicy(); saveErr != nil {
-		log.Printf("⚠️  保存策略失败: %v", saveErr)
+		log.Warn("保存策略失败", zap.Error(saveErr))
 	}
 
 	return removed, nil
@@ -147,7 +157,7 @@ func AddRoleForUser(user, role string) (bool, error) {
 
 	// 持久化
 	if saveErr := Enforcer.SavePolicy(); saveErr != nil {
-		log.Printf("⚠️  保存策略失败: %v", saveErr)
+		log.Warn("保存策略失败", zap.Error(saveErr))
 	}
 
 	return added, nil
@@ -166,7 +176,7 @@ func DeleteRoleForUser(user, role string) (bool, error) {
 
 	// 持久化
 	if saveErr := Enforcer.SavePolicy(); saveErr != nil {
-		log.Printf("⚠️  保存策略失败: %v", saveErr)
+		log.Warn("保存策略失败", zap.Error(saveErr))
 	}
 
 	return removed, nil
@@ -214,12 +224,12 @@ func DeleteRole(role string) (bool, error) {
 
 	// 删除角色的所有分组关系
 	if _, err := Enforcer.RemoveFilteredGroupingPolicy(1, role); err != nil {
-		log.Printf("⚠️  删除角色分组关系失败: %v", err)
+		log.Warn("删除角色分组关系失败", zap.Error(err))
 	}
 
 	// 持久化
 	if saveErr := Enforcer.SavePolicy(); saveErr != nil {
-		log.Printf("⚠️  保存策略失败: %v", saveErr)
+		log.Warn("保存策略失败", zap.Error(saveErr))
 	}
 
 	return removed, nil
@@ -245,7 +255,7 @@ func DeleteUser(user string) (bool, error) {
 
 	// 持久化
 	if saveErr := Enforcer.SavePolicy(); saveErr != nil {
-		log.Printf("⚠️  保存策略失败: %v", saveErr)
+		log.Warn("保存策略失败", zap.Error(saveErr))
 	}
 
 	return removed1 || removed2, nil
@@ -271,7 +281,7 @@ func SyncRoleMenus(tenantID, roleID string, menuPaths []string) error {
 		actions := []string{"read", "create", "update", "delete"}
 		for _, action := range actions {
 			if _, err := Enforcer.AddPolicy(roleSub, menuPath, action); err != nil {
-				log.Printf("⚠️  添加权限失败 %s -> %s: %v", roleSub, menuPath, err)
+				log.Warn("添加权限失败", zap.String("sub", roleSub), zap.String("resource", menuPath), zap.Error(err))
 			}
 		}
 	}
@@ -306,12 +316,12 @@ func SyncRoleMenusWithPermissions(tenantID, roleID string, menuPermissions map[s
 		if menuPath == "" {
 			// 如果映射中没有，使用降级方案
 			menuPath = getMenuPathFromNameFallback(menuName)
-			log.Printf("⚠️  菜单 %s 没有提供路径映射，使用降级路径: %s", menuName, menuPath)
+			log.Warn("菜单没有提供路径映射，使用降级路径", zap.String("menu", menuName), zap.String("resource", menuPath))
 		}
 
 		for _, action := range actions {
 			if _, err := Enforcer.AddPolicy(roleSub, menuPath, action); err != nil {
-				log.Printf("⚠️  添加权限失败 %s -> %s:%s: %v", roleSub, menuPath, action, err)
+				log.Warn("添加权限失败", zap.String("sub", roleSub), zap.String("resource", menuPath), zap.String("action", action), zap.Error(err))
 			}
 		}
 	}
@@ -321,7 +331,7 @@ func SyncRoleMenusWithPermissions(tenantID, roleID string, menuPermissions map[s
 		return fmt.Errorf("保存策略失败: %w", err)
 	}
 
-	log.Printf("[Casbin] 角色权限同步成功: %s, %d个菜单", roleSub, len(menuPermissions))
+	log.Info("角色权限同步成功", zap.String("sub", roleSub), zap.Int("menus", len(menuPermissions)))
 	return nil
 }
 
@@ -362,7 +372,7 @@ func SyncUserRoles(tenantID, userID string, roleIDs []string) error {
 	for _, roleID := range roleIDs {
 		roleSub := fmt.Sprintf("tenant:%s:role:%s", tenantID, roleID)
 		if _, err := Enforcer.AddGroupingPolicy(userSub, roleSub); err != nil {
-			log.Printf("⚠️  添加用户角色失败 %s -> %s: %v", userSub, roleSub, err)
+			log.Warn("添加用户角色失败", zap.String("sub", userSub), zap.String("role", roleSub), zap.Error(err))
 		}
 	}
 
@@ -439,11 +449,11 @@ func SyncTenantMenus(tenantID string, menuPaths []string) error {
 	for _, menuPath := range menuPaths {
 		// 添加读权限
 		if _, err := Enforcer.AddPolicy(tenantSub, menuPath, "read"); err != nil {
-			log.Printf("⚠️  添加权限失败 %s -> %s: %v", tenantSub, menuPath, err)
+			log.Warn("添加权限失败", zap.String("sub", tenantSub), zap.String("resource", menuPath), zap.Error(err))
 		}
 		// 添加写权限
 		if _, err := Enforcer.AddPolicy(tenantSub, menuPath, "write"); err != nil {
-			log.Printf("⚠️  添加权限失败 %s -> %s: %v", tenantSub, menuPath, err)
+			log.Warn("添加权限失败", zap.String("sub", tenantSub), zap.String("resource", menuPath), zap.Error(err))
 		}
 	}
 
@@ -452,7 +462,7 @@ func SyncTenantMenus(tenantID string, menuPaths []string) error {
 		return fmt.Errorf("保存策略失败: %w", err)
 	}
 
-	log.Printf("[Casbin] 租户权限同步成功: %s, %d个菜单", tenantID, len(menuPaths))
+	log.Info("租户权限同步成功", zap.String("tenant_id", tenantID), zap.Int("menus", len(menuPaths)))
 	return nil
 }
 
@@ -0,0 +1,142 @@
+package casbin
+
+import (
+	"fmt"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/util"
+)
+
+// Policy 权限策略（p = sub, obj, act）
+type Policy struct {
+	Subject string `json:"subject"`
+	Object  string `json:"object"`
+	Action  string `json:"action"`
+}
+
+// Explanation 一次鉴权的判断过程
+type Explanation struct {
+	Subject         string     `json:"subject"`          // 请求主体
+	Resource        string     `json:"resource"`         // 解析出的资源
+	Action          string     `json:"action"`           // 解析出的动作
+	RoleChain       [][]string `json:"role_chain"`       // 主体到各角色的继承链
+	MatchedPolicies []Policy   `json:"matched_policies"` // 命中的策略
+	Allowed         bool       `json:"allowed"`          // Casbin 判断结果
+}
+
+// UserSubject 用户主体（与鉴权中间件一致：租户用户 tenant:<租户ID>:user:<用户ID>，系统用户 user:<用户ID>）
+func UserSubject(tenantID, userID string) string {
+	if tenantID != "" {
+		return fmt.Sprintf("tenant:%s:user:%s", tenantID, userID)
+	}
+	return fmt.Sprintf("user:%s", userID)
+}
+
+// RoleSubject 角色主体
+func RoleSubject(tenantID, roleID string) string {
+	return fmt.Sprintf("tenant:%s:role:%s", tenantID, roleID)
+}
+
+// BypassRole 直接放行的超管角色：系统超管（super 且无租户）、租户超管（tenant_admin 且有租户），否则返回空
+func BypassRole(tenantID string, roles []string) string {
+	for _, role := range roles {
+		if role == "super" && tenantID == "" {
+			return role
+		}
+		if role == "tenant_admin" && tenantID != "" {
+			return role
+		}
+	}
+	return ""
+}
+
+// MatchResource 资源是否命中策略路径（keyMatch2）
+func MatchResource(obj, policyObj string) bool {
+	return util.KeyMatch2(obj, policyObj)
+}
+
+// NewMemoryEnforcer 用默认模型和给定的策略创建内存 Enforcer（不读写数据库，用于权限模拟）
+// groupings 为用户到角色的关系（g = user, role）
+func NewMemoryEnforcer(policies []Policy, groupings [][2]string) (*casbin.Enforcer, error) {
+	m, err := model.NewModelFromString(getDefaultModel())
+	if err != nil {
+		return nil, fmt.Errorf("加载Casbin模型失败: %w", err)
+	}
+	e, err := casbin.NewEnforcer(m)
+	if err != nil {
+		return nil, fmt.Errorf("创建Casbin Enforcer失败: %w", err)
+	}
+	for _, p := range policies {
+		if _, err := e.AddPolicy(p.Subject, p.Object, p.Action); err != nil {
+			return nil, err
+		}
+	}
+	for _, g := range groupings {
+		if _, err := e.AddGroupingPolicy(g[0], g[1]); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Explain 判断主体能否访问资源，并返回角色继承链和全部命中的策略
+func Explain(e *casbin.Enforcer, sub, resource, action string) (*Explanation, error) {
+	exp := &Explanation{
+		Subject:         sub,
+		Resource:        resource,
+		Action:          action,
+		RoleChain:       roleChains(e, []string{sub}),
+		MatchedPolicies: []Policy{},
+	}
+
+	if exp.RoleChain == nil {
+		exp.RoleChain = [][]string{}
+	}
+
+	subjects := []string{sub}
+	roles, err := e.GetImplicitRolesForUser(sub)
+	if err != nil {
+		return nil, err
+	}
+	subjects = append(subjects, roles...)
+	for _, s := range subjects {
+		for _, p := range e.GetPermissionsForUser(s) {
+			if len(p) >= 3 && MatchPolicy(resource, action, p[1], p[2]) {
+				exp.MatchedPolicies = append(exp.MatchedPolicies, Policy{Subject: p[0], Object: p[1], Action: p[2]})
+			}
+		}
+	}
+
+	if exp.Allowed, err = e.Enforce(sub, resource, action); err != nil {
+		return nil, err
+	}
+	return exp, nil
+}
+
+// roleChains 从主体出发展开全部角色继承路径（如 [user, role:A, role:B]）
+func roleChains(e *casbin.Enforcer, path []string) [][]string {
+	roles, _ := e.GetRolesForUser(path[len(path)-1])
+	var chains [][]string
+	for _, role := range roles {
+		if contains(path, role) {
+			continue // 循环继承
+		}
+		next := append(append([]string(nil), path...), role)
+		if sub := roleChains(e, next); len(sub) > 0 {
+			chains = append(chains, sub...)
+		} else {
+			chains = append(chains, next)
+		}
+	}
+	return chains
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package casbin
+
+import "testing"
+
+// TestExplain 测试命中策略、角色链和拒绝
+func TestExplain(t *testing.T) {
+	user := UserSubject("t1", "u1")
+	role := RoleSubject("t1", "r1")
+	e, err := NewMemoryEnforcer([]Policy{
+		{Subject: role, Object: "/perms/roles", Action: "read"},
+		{Subject: role, Object: "/perms/roles/:id", Action: "update"},
+		{Subject: role, Object: "/finance", Action: "pending"},
+	}, [][2]string{{user, role}})
+	if err != nil {
+		t.Fatalf("NewMemoryEnforcer() error = %v", err)
+	}
+
+	resource, action := ParseResourceAndAction("/admin/perms/roles/123", "PUT")
+	exp, err := Explain(e, user, resource, action)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if !exp.Allowed || len(exp.MatchedPolicies) != 1 || exp.MatchedPolicies[0].Object != "/perms/roles/:id" {
+		t.Errorf("Explain(PUT) = %+v, want allowed by /perms/roles/:id", exp)
+	}
+	if len(exp.RoleChain) != 1 || len(exp.RoleChain[0]) != 2 || exp.RoleChain[0][1] != role {
+		t.Errorf("RoleChain = %v, want [[%s %s]]", exp.RoleChain, user, role)
+	}
+
+	// 业务动作：资源去掉 ID 段
+	resource, action = ParseResourceAndAction("/admin/finance/507f1f77bcf86cd799439011/pending", "POST")
+	if resource != "/finance" || action != "pending" {
+		t.Errorf("ParseResourceAndAction() = %s %s, want /finance pending", resource, action)
+	}
+	if exp, _ := Explain(e, user, resource, action); !exp.Allowed {
+		t.Error("Explain(pending) should be allowed")
+	}
+
+	exp, err = Explain(e, user, "/perms/roles/123", "delete")
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if exp.Allowed || len(exp.MatchedPolicies) != 0 {
+		t.Errorf("Explain(delete) = %+v, want denied without matches", exp)
+	}
+}
+
+// TestBypassRole 测试超管放行规则
+func TestBypassRole(t *testing.T) {
+	if BypassRole("", []string{"super"}) != "super" {
+		t.Error("super without tenant should bypass")
+	}
+	if BypassRole("t1", []string{"super"}) != "" {
+		t.Error("super inside a tenant should not bypass")
+	}
+	if BypassRole("t1", []string{"r1", "tenant_admin"}) != "tenant_admin" {
+		t.Error("tenant_admin inside a tenant should bypass")
+	}
+}
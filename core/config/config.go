@@ -10,29 +10,38 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
 // Config 全局配置
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Consul   ConsulConfig   `mapstructure:"consul"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Hystrix  HystrixConfig  `mapstructure:"hystrix"`
-	Gateway  GatewayConfig  `mapstructure:"gateway"`
-	Database DatabaseConfig `mapstructure:"database"` // 已废弃
-	MongoDB  MongoDBConfig  `mapstructure:"mongodb"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Log      LogConfig      `mapstructure:"log"`
-	Storage  StorageConfig  `mapstructure:"storage"`
-	Wechat   WechatConfig   `mapstructure:"wechat"`
+	Server        ServerConfig        `mapstructure:"server"`
+	Consul        ConsulConfig        `mapstructure:"consul"`
+	JWT           JWTConfig           `mapstructure:"jwt"`
+	Hystrix       HystrixConfig       `mapstructure:"hystrix"`
+	Gateway       GatewayConfig       `mapstructure:"gateway"`
+	Database      DatabaseConfig      `mapstructure:"database"` // 已废弃
+	MongoDB       MongoDBConfig       `mapstructure:"mongodb"`
+	Redis         RedisConfig         `mapstructure:"redis"`
+	Log           LogConfig           `mapstructure:"log"`
+	Storage       StorageConfig       `mapstructure:"storage"`
+	Wechat        WechatConfig        `mapstructure:"wechat"`
+	Password      PasswordConfig      `mapstructure:"password"`
+	Login         LoginConfig         `mapstructure:"login"`
+	TwoFactor     TwoFactorConfig     `mapstructure:"two_factor"`
+	Encryption    EncryptionConfig    `mapstructure:"encryption"`
+	GatewayAuth   GatewayAuthConfig   `mapstructure:"gateway_auth"`
+	Impersonation ImpersonationConfig `mapstructure:"impersonation"`
+	Tracing       TracingConfig       `mapstructure:"tracing"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Name string `mapstructure:"name"`
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"` // debug, release, test
+	Name           string   `mapstructure:"name"`
+	Host           string   `mapstructure:"host"`
+	Port           int      `mapstructure:"port"`
+	Mode           string   `mapstructure:"mode"`            // debug, release, test
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // 可信代理（网关地址/网段），只采信其转发的 X-Real-IP 作为客户端IP
 }
 
 // ConsulConfig Consul配置
@@ -54,9 +63,26 @@ type ConsulConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	SecretKey  string `mapstructure:"secret_key"`
-	ExpireTime int    `mapstructure:"expire_time"` // 小时
-	Issuer     string `mapstructure:"issuer"`
+	SecretKey           string `mapstructure:"secret_key"`
+	HS256Issuer         string `mapstructure:"hs256_issuer"`          // 与非对称密钥同时配置时只接受该签发者的 HS256 令牌（如小程序）
+	ExpireTime          int    `mapstructure:"expire_time"`           // 小时
+	Issuer              string `mapstructure:"issuer"`                // 签发者
+	AccessExpireMinutes int    `mapstructure:"access_expire_minutes"` // 访问令牌有效期（分钟），配置后优先于 expire_time
+	RefreshExpireTime   int    `mapstructure:"refresh_expire_time"`   // 刷新令牌有效期（小时），默认 168
+
+	// 非对称签名（RS256/EdDSA）
+	Algorithm          string         `mapstructure:"algorithm"`            // 私钥文件不存在时生成的密钥类型：RS256（默认）/ EdDSA
+	SigningKeyID       string         `mapstructure:"signing_kid"`          // 当前用于签名的密钥，默认第一个带私钥的密钥
+	Keys               []JWTKeyConfig `mapstructure:"keys"`                 // 签名密钥（仅认证服务配置），轮换时旧密钥保留到其签发的令牌过期
+	JWKSURL            string         `mapstructure:"jwks_url"`             // 认证服务的 JWKS 地址（网关和其他服务配置）
+	JWKSRefreshMinutes int            `mapstructure:"jwks_refresh_minutes"` // JWKS 缓存时间（分钟），默认 10
+}
+
+// JWTKeyConfig JWT 签名密钥
+type JWTKeyConfig struct {
+	KID            string `mapstructure:"kid"`
+	PrivateKeyFile string `mapstructure:"private_key_file"` // PEM 私钥（PKCS#8 或 PKCS#1），文件不存在时自动生成
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // 已退役密钥可以只配置公钥，继续用于验证旧令牌
 }
 
 // HystrixConfig Hystrix配置
@@ -83,12 +109,41 @@ type GatewayConfig struct {
 	Routes map[string]RouteConfig `mapstructure:"routes"`
 	// 超时配置
 	Timeout TimeoutConfig `mapstructure:"timeout"`
+	// 负载均衡配置
+	LoadBalance LoadBalanceConfig `mapstructure:"load_balance"`
+	// 可信代理（网关前的 Nginx/负载均衡）的IP或网段，只采信它们转发的 X-Forwarded-For；为空时使用直连地址
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// Prometheus 指标的内部监听地址（不在对外端口提供），默认 127.0.0.1:9080
+	MetricsListen string `mapstructure:"metrics_listen"`
+}
+
+// LoadBalanceConfig 网关转发的负载均衡配置（未配置的项使用默认值）
+type LoadBalanceConfig struct {
+	Strategy            string            `mapstructure:"strategy"`                // round_robin（默认）/ least_conn / consistent_hash（按租户）
+	Services            map[string]string `mapstructure:"services"`                // 服务级策略，覆盖 strategy
+	CacheTTL            int               `mapstructure:"cache_ttl"`               // Consul 健康实例缓存（秒），默认 5
+	Retries             int               `mapstructure:"retries"`                 // GET/HEAD/OPTIONS 失败后换实例重试次数，默认 1，-1 关闭
+	MaxFailures         int               `mapstructure:"max_failures"`            // 连续失败多少次后摘除实例，默认 3
+	EjectSeconds        int               `mapstructure:"eject_seconds"`           // 摘除时长（秒），默认 30
+	MaxIdleConnsPerHost int               `mapstructure:"max_idle_conns_per_host"` // 每个实例保持的空闲连接，默认 32
+}
+
+// RateLimitConfig 限流配置（启用Redis时多个网关副本共享限流状态）
+type RateLimitConfig struct {
+	Enabled  bool                    `mapstructure:"enabled"`
+	Rate     int                     `mapstructure:"rate"`     // 每个IP每秒请求数（默认策略，0 表示不启用）
+	Policies []RateLimitPolicyConfig `mapstructure:"policies"` // 启用Consul时首次启动迁移到 Consul KV
 }
 
-// RateLimitConfig 限流配置
-type RateLimitConfig struct {
-	Enabled bool `mapstructure:"enabled"`
-	Rate    int  `mapstructure:"rate"` // 每秒请求数
+// RateLimitPolicyConfig 限流策略：window 秒内最多 limit 个请求
+type RateLimitPolicyConfig struct {
+	Name    string   `mapstructure:"name"`
+	Key     string   `mapstructure:"key"`     // tenant / user / api_key / ip / route
+	Path    string   `mapstructure:"path"`    // 路径前缀（含网关前缀），为空匹配全部
+	Methods []string `mapstructure:"methods"` // 为空不限
+	Limit   int      `mapstructure:"limit"`
+	Window  int      `mapstructure:"window"` // 秒
+	Burst   int      `mapstructure:"burst"`  // 默认等于 limit
 }
 
 // RouteConfig 路由配置
@@ -96,6 +151,20 @@ type RouteConfig struct {
 	ServiceName string   `mapstructure:"service_name"`
 	RequireAuth bool     `mapstructure:"require_auth"`
 	RequireRole []string `mapstructure:"require_role"`
+
+	Methods []string           `mapstructure:"methods"` // 只匹配这些请求方法（为空不限）
+	Host    string             `mapstructure:"host"`    // 只匹配该 Host（支持 *.example.com）
+	Headers map[string]string  `mapstructure:"headers"` // 请求头必须等于给定值（* 表示存在即可）
+	Rewrite RouteRewriteConfig `mapstructure:"rewrite"` // 转发路径重写
+	Timeout int                `mapstructure:"timeout"` // 转发超时（毫秒），0 表示不限制
+}
+
+// RouteRewriteConfig 路径重写（regex/replacement 或 from/to 模板二选一，都为空表示不重写）
+type RouteRewriteConfig struct {
+	Regex       string `mapstructure:"regex"`
+	Replacement string `mapstructure:"replacement"`
+	From        string `mapstructure:"from"`
+	To          string `mapstructure:"to"`
 }
 
 // TimeoutConfig 超时配置
@@ -153,6 +222,9 @@ type LogConfig struct {
 	MaxBackups int    `mapstructure:"max_backups"` // 保留旧文件数量
 	MaxAge     int    `mapstructure:"max_age"`     // 天
 	Compress   bool   `mapstructure:"compress"`    // 是否压缩
+
+	// Levels 按模块单独设置级别（如 gateway.proxy: debug），运行时可通过 /log-levels 调整
+	Levels map[string]string `mapstructure:"levels"`
 }
 
 // StorageConfig 存储配置
@@ -204,6 +276,67 @@ type WechatConfig struct {
 	OpenAppID string `mapstructure:"open_app_id"` // 微信开放平台AppID（用于UnionID）
 }
 
+// PasswordConfig 密码哈希与密码策略配置（未配置的项使用默认值）
+type PasswordConfig struct {
+	Algorithm     string `mapstructure:"algorithm"`      // 哈希算法: argon2id（默认）/bcrypt
+	Argon2Memory  uint32 `mapstructure:"argon2_memory"`  // argon2id 内存（KiB），默认 65536
+	Argon2Time    uint32 `mapstructure:"argon2_time"`    // argon2id 迭代次数，默认 3
+	Argon2Threads uint8  `mapstructure:"argon2_threads"` // argon2id 并行度，默认 2
+	BcryptCost    int    `mapstructure:"bcrypt_cost"`    // bcrypt 成本，默认 12
+	MinLength     int    `mapstructure:"min_length"`     // 最小长度，默认 8
+	RequireUpper  bool   `mapstructure:"require_upper"`  // 必须包含大写字母
+	RequireLower  bool   `mapstructure:"require_lower"`  // 必须包含小写字母
+	RequireDigit  bool   `mapstructure:"require_digit"`  // 必须包含数字
+	RequireSymbol bool   `mapstructure:"require_symbol"` // 必须包含特殊字符
+	HistorySize   int    `mapstructure:"history_size"`   // 不能与最近几次密码相同，0 表示不限制
+}
+
+// LoginConfig 登录防暴力破解配置（未配置的项使用默认值）
+type LoginConfig struct {
+	FailureWindow   int  `mapstructure:"failure_window"`    // 失败次数统计窗口（分钟），默认 15
+	MaxFailures     int  `mapstructure:"max_failures"`      // 同一账号连续失败多少次后锁定，默认 5
+	LockMinutes     int  `mapstructure:"lock_minutes"`      // 账号锁定时长（分钟），默认 15
+	IPMaxFailures   int  `mapstructure:"ip_max_failures"`   // 同一IP失败多少次后锁定，默认 20，-1 关闭按IP的计数和锁定
+	IPLockMinutes   int  `mapstructure:"ip_lock_minutes"`   // IP锁定时长（分钟），默认 30
+	DelaySeconds    int  `mapstructure:"delay_seconds"`     // 递增等待的基数（秒），第2次失败起每次翻倍，默认 1
+	MaxDelaySeconds int  `mapstructure:"max_delay_seconds"` // 最长等待（秒），默认 30
+	CaptchaEnabled  bool `mapstructure:"captcha_enabled"`   // 是否启用验证码
+	CaptchaAfter    int  `mapstructure:"captcha_after"`     // 失败多少次后要求验证码，默认 3
+}
+
+// TwoFactorConfig 两步验证配置
+type TwoFactorConfig struct {
+	Issuer           string `mapstructure:"issuer"`            // 验证器App中显示的发行方，默认 Mule Cloud
+	ChallengeMinutes int    `mapstructure:"challenge_minutes"` // 登录第二步的有效期（分钟），默认 5
+}
+
+// EncryptionConfig 敏感字段加密配置（AES-256-GCM）
+type EncryptionConfig struct {
+	Key          string   `mapstructure:"key"`           // 当前密钥（32字节，Base64编码），未配置时不加密
+	PreviousKeys []string `mapstructure:"previous_keys"` // 轮换前的旧密钥，仅用于解密
+}
+
+// GatewayAuthConfig 网关身份头签名配置（网关和各服务配置相同的密钥）
+type GatewayAuthConfig struct {
+	Secret        string `mapstructure:"secret"`         // HMAC 签名密钥
+	RequireSigned bool   `mapstructure:"require_signed"` // 拒绝未签名的 X-User-ID 等身份头（全部网关升级后开启）
+	MaxSkew       int    `mapstructure:"max_skew"`       // 签名有效期（秒），默认 30
+}
+
+// ImpersonationConfig 超管代管租户配置
+type ImpersonationConfig struct {
+	DefaultMinutes int `mapstructure:"default_minutes"` // 代管令牌默认有效期（分钟），默认 30
+	MaxMinutes     int `mapstructure:"max_minutes"`     // 代管令牌最长有效期（分钟），默认 120
+}
+
+// TracingConfig 链路追踪配置（OpenTelemetry，OTLP gRPC 导出）
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`      // 未启用时仍传递 traceparent 和 X-Request-ID，只是不导出
+	Endpoint    string  `mapstructure:"endpoint"`     // OTLP 采集器地址，默认 localhost:4317
+	Insecure    bool    `mapstructure:"insecure"`     // 不使用 TLS（本地采集器）
+	SampleRatio float64 `mapstructure:"sample_ratio"` // 采样比例（0-1），默认 1；上游已采样的请求始终采样
+}
+
 var (
 	globalConfig *Config
 	configOnce   sync.Once
@@ -240,7 +373,7 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("读取配置文件失败: %v", err)
 	}
 
-	log.Printf("✅ 配置文件加载成功: %s", v.ConfigFileUsed())
+	configLog().Info("配置文件加载成功", zap.String("file", v.ConfigFileUsed()))
 
 	// 解析配置
 	var cfg Config
@@ -274,6 +407,9 @@ func overrideFromEnv(cfg *Config) {
 	if secret := os.Getenv("JWT_SECRET"); secret != "" {
 		cfg.JWT.SecretKey = secret
 	}
+	if url := os.Getenv("JWT_JWKS_URL"); url != "" {
+		cfg.JWT.JWKSURL = url
+	}
 
 	// 服务IP
 	if ip := os.Getenv("SERVICE_IP"); ip != "" {
@@ -322,7 +458,7 @@ func Reload() error {
 	}
 
 	globalConfig = cfg
-	log.Println("🔄 配置文件已重新加载")
+	configLog().Info("配置文件已重新加载")
 	return nil
 }
 
@@ -331,15 +467,20 @@ func Watch(callback func(*Config)) {
 	v := viper.New()
 	v.WatchConfig()
 	v.OnConfigChange(func(e fsnotify.Event) {
-		log.Printf("🔄 配置文件已更改: %s", e.Name)
+		configLog().Info("配置文件已更改", zap.String("file", e.Name))
 		if err := Reload(); err != nil {
-			log.Printf("❌ 重新加载配置失败: %v", err)
+			configLog().Error("重新加载配置失败", zap.Error(err))
 		} else if callback != nil {
 			callback(globalConfig)
 		}
 	})
 }
 
+// configLog 配置包的日志（logger 依赖 config，不能反向引用；使用 logger.InitLogger 设置的 zap 全局日志，初始化前不输出）
+func configLog() *zap.Logger {
+	return zap.L().Named("config")
+}
+
 // ConfigInstance 全局配置实例包装器
 type ConfigInstance struct {
 	defaultPath string
@@ -14,6 +14,9 @@ const (
 	UserIDKey   contextKey = "user_id"
 	UsernameKey contextKey = "username"
 	RolesKey    contextKey = "roles"
+
+	// RequestIDKey 请求ID（网关生成，经 X-Request-ID 传递到各服务）
+	RequestIDKey contextKey = "request_id"
 )
 
 // WithTenantID 设置租户ID到Context
@@ -82,6 +85,19 @@ func GetRoles(ctx context.Context) []string {
 	return []string{}
 }
 
+// WithRequestID 设置请求ID到Context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, requestID)
+}
+
+// GetRequestID 从Context获取请求ID
+func GetRequestID(ctx context.Context) string {
+	if requestID, ok := ctx.Value(RequestIDKey).(string); ok {
+		return requestID
+	}
+	return ""
+}
+
 // WithUserInfo 一次性设置所有用户信息到Context
 func WithUserInfo(ctx context.Context, tenantID, userID, username string, roles []string) context.Context {
 	ctx = WithTenantID(ctx, tenantID)
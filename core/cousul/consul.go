@@ -3,7 +3,7 @@ package cousul
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"mule-cloud/core/logger"
 	"net"
 	"os"
 	"os/signal"
@@ -12,8 +12,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/hashicorp/consul/api"
+	"go.uber.org/zap"
 )
 
+// log 服务注册的模块日志
+var log = logger.For("consul")
+
 // ConsulClient Consul客户端封装
 type ConsulClient struct {
 	client *api.Client
@@ -116,8 +120,10 @@ func (c *ConsulClient) RegisterService(cfg *ServiceConfig) error {
 		return fmt.Errorf("服务注册失败: %v", err)
 	}
 
-	log.Printf("[Consul] 服务注册成功 -> ID: %s, Name: %s, Address: %s:%d",
-		cfg.ServiceID, cfg.ServiceName, cfg.ServiceAddress, cfg.ServicePort)
+	log.Info("服务注册成功",
+		zap.String("service_id", cfg.ServiceID),
+		zap.String("consul_service", cfg.ServiceName),
+		zap.String("address", fmt.Sprintf("%s:%d", cfg.ServiceAddress, cfg.ServicePort)))
 	return nil
 }
 
@@ -132,7 +138,7 @@ func (c *ConsulClient) DeregisterService() error {
 		return fmt.Errorf("服务注销失败: %v", err)
 	}
 
-	log.Printf("[Consul] 服务注销成功 -> ID: %s", c.config.ServiceID)
+	log.Info("服务注销成功", zap.String("service_id", c.config.ServiceID))
 	return nil
 }
 
@@ -189,7 +195,7 @@ func (c *ConsulClient) RegisterRoute(routeConfig *RouteConfig) error {
 		return fmt.Errorf("保存路由配置到Consul失败: %v", err)
 	}
 
-	log.Printf("[Consul] 路由配置注册成功 -> Key: %s, Service: %s", key, routeConfig.ServiceName)
+	log.Info("路由配置写入Consul", zap.String("key", key), zap.String("consul_service", routeConfig.ServiceName))
 	return nil
 }
 
@@ -242,14 +248,14 @@ func RegisterAndRun(router *gin.Engine, config *ServiceConfig, consulAddress str
 	// 创建Consul客户端
 	consulClient, err := NewConsulClient(consulAddress)
 	if err != nil {
-		log.Fatalf("连接Consul失败: %v", err)
+		log.Fatal("连接Consul失败", zap.Error(err))
 		return err
 	}
 
 	// 注册服务
 	err = consulClient.RegisterService(config)
 	if err != nil {
-		log.Fatalf("服务注册失败: %v", err)
+		log.Fatal("服务注册失败", zap.Error(err))
 		return err
 	}
 
@@ -257,15 +263,17 @@ func RegisterAndRun(router *gin.Engine, config *ServiceConfig, consulAddress str
 	if len(routeConfig) > 0 && routeConfig[0] != nil {
 		err = consulClient.RegisterRoute(routeConfig[0])
 		if err != nil {
-			log.Printf("⚠️  路由配置注册失败: %v", err)
+			log.Warn("路由配置注册失败", zap.Error(err))
 			// 不阻断服务启动，只记录警告
 		} else {
 			gwPrefix := routeConfig[0].GatewayPrefix
 			if gwPrefix == "" {
 				gwPrefix = "(无前缀)"
 			}
-			log.Printf("✅ 路由配置注册成功: %s%s -> %s (网关前缀: %s)",
-				gwPrefix, routeConfig[0].Prefix, routeConfig[0].ServiceName, gwPrefix)
+			log.Info("路由配置注册成功",
+				zap.String("route", gwPrefix+routeConfig[0].Prefix),
+				zap.String("consul_service", routeConfig[0].ServiceName),
+				zap.String("gateway_prefix", gwPrefix))
 		}
 	}
 
@@ -275,16 +283,16 @@ func RegisterAndRun(router *gin.Engine, config *ServiceConfig, consulAddress str
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 		<-quit
 
-		log.Println("正在关闭服务...")
+		log.Info("正在关闭服务")
 		if err := consulClient.DeregisterService(); err != nil {
-			log.Printf("服务注销失败: %v", err)
+			log.Warn("服务注销失败", zap.Error(err))
 		}
+		_ = logger.Sync()
 		os.Exit(0)
 	}()
 
 	// 启动HTTP服务
 	addr := fmt.Sprintf(":%d", config.ServicePort)
-	log.Printf("✅ HTTP服务启动成功，监听端口: %d", config.ServicePort)
-	log.Printf("🌐 访问地址: http://localhost:%d", config.ServicePort)
+	log.Info("HTTP服务启动", zap.Int("port", config.ServicePort))
 	return router.Run(addr)
 }
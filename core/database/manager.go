@@ -3,7 +3,6 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"sync"
 	"time"
@@ -11,6 +10,7 @@ import (
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.uber.org/zap"
 )
 
 const (
@@ -38,7 +38,7 @@ func InitDatabaseManager(client *mongo.Client) *DatabaseManager {
 			client:   client,
 			systemDB: client.Database(SystemDatabase),
 		}
-		log.Printf("✅ 数据库管理器初始化成功，系统库: %s", SystemDatabase)
+		log.Info("数据库管理器初始化成功", zap.String("system_database", SystemDatabase))
 	})
 	return globalDBManager
 }
@@ -46,7 +46,7 @@ func InitDatabaseManager(client *mongo.Client) *DatabaseManager {
 // GetDatabaseManager 获取全局数据库管理器
 func GetDatabaseManager() *DatabaseManager {
 	if globalDBManager == nil {
-		log.Fatal("❌ 数据库管理器未初始化，请先调用 InitDatabaseManager()")
+		log.Fatal("数据库管理器未初始化，请先调用 InitDatabaseManager()")
 	}
 	return globalDBManager
 }
@@ -77,7 +77,7 @@ func (m *DatabaseManager) GetDatabase(tenantCode string) *mongo.Database {
 	db := m.client.Database(dbName)
 	m.tenantDBs.Store(tenantCode, db)
 
-	log.Printf("🔗 创建租户数据库连接: %s", dbName)
+	log.Debug("创建租户数据库连接", zap.String("database", dbName))
 	return db
 }
 
@@ -86,22 +86,98 @@ func (m *DatabaseManager) GetSystemDatabase() *mongo.Database {
 	return m.systemDB
 }
 
+// masterDataCollections 基础资料集合
+var masterDataCollections = map[string]bool{
+	"customers":           true,
+	"salesmen":            true,
+	"colors":              true,
+	"sizes":               true,
+	"order_types":         true,
+	"procedures":          true,
+	"procedure_templates": true,
+}
+
+// tenantIndex 租户库业务索引
+type tenantIndex struct {
+	collection string
+	name       string
+	keys       bson.D
+	partial    bson.M // 部分索引条件，为空时索引全部文档
+}
+
+// tenantIndexes 业务集合的唯一索引（防止并发写入产生重复数据）
+//
+// 新租户建库时创建，已有租户由服务启动时调用 EnsureTenantIndexes 补建
+var tenantIndexes = append([]tenantIndex{
+	// 同一订单内箱号唯一（含已删除的箱，箱号不复用）
+	{"packing_cartons", "uniq_order_carton_no", bson.D{{Key: "order_id", Value: 1}, {Key: "carton_no", Value: 1}}, nil},
+	// 箱唛条码唯一（扫码发货按条码查箱）
+	{"packing_cartons", "uniq_barcode", bson.D{{Key: "barcode", Value: 1}}, bson.M{"is_deleted": 0}},
+	// 同一来源单据只能有一张有效发票（作废状态为 4，作废后可重新开票）
+	{"invoices", "uniq_active_source", bson.D{{Key: "source_type", Value: 1}, {Key: "source_id", Value: 1}},
+		bson.M{"is_deleted": 0, "status": bson.M{"$lt": 4}}},
+}, masterDataCodeIndexes()...)
+
+// masterDataCodeIndexes 基础资料编码唯一（只约束未删除且填写了编码的记录）
+func masterDataCodeIndexes() []tenantIndex {
+	indexes := make([]tenantIndex, 0, len(masterDataCollections))
+	for collName := range masterDataCollections {
+		indexes = append(indexes, tenantIndex{collName, "uniq_code",
+			bson.D{{Key: "code", Value: 1}, {Key: "is_deleted", Value: 1}},
+			bson.M{"is_deleted": 0, "code": bson.M{"$gt": ""}}})
+	}
+	return indexes
+}
+
+// EnsureTenantIndexes 创建租户库的业务唯一索引（幂等）
+//
+// 已有重复数据时该索引创建失败，记录日志后继续创建其余索引，返回第一个错误
+func (m *DatabaseManager) EnsureTenantIndexes(ctx context.Context, tenantCode string) error {
+	db := m.GetDatabase(tenantCode)
+	var firstErr error
+	for _, index := range tenantIndexes {
+		opts := options.Index().SetName(index.name).SetUnique(true)
+		if index.partial != nil {
+			opts.SetPartialFilterExpression(index.partial)
+		}
+		_, err := db.Collection(index.collection).Indexes().CreateOne(ctx, mongo.IndexModel{Keys: index.keys, Options: opts})
+		if err != nil {
+			log.Ctx(ctx).Warn("创建唯一索引失败",
+				zap.String("database", db.Name()),
+				zap.String("collection", index.collection),
+				zap.String("index", index.name),
+				zap.Error(err))
+			if firstErr == nil {
+				firstErr = fmt.Errorf("集合 %s 创建索引 %s 失败: %w", index.collection, index.name, err)
+			}
+		}
+	}
+	return firstErr
+}
+
 // CreateTenantDatabase 创建租户数据库（初始化集合和索引）
 // 参数使用 tenantCode 而不是 tenantID，这样数据库名更易读
 func (m *DatabaseManager) CreateTenantDatabase(ctx context.Context, tenantCode string) error {
 	dbName := GetTenantDatabaseName(tenantCode)
 	db := m.client.Database(dbName)
 
-	log.Printf("📦 开始创建租户数据库: %s", dbName)
+	log.Ctx(ctx).Info("开始创建租户数据库", zap.String("database", dbName))
 
 	// 创建集合列表
 	collections := []string{
 		"admin", // 管理员
 		"role",  // 角色
 		// "menu",  // 菜单（租户可以自定义菜单，但通常从系统库同步）
-		"basic", // 基础数据（统一存储：颜色、尺码、客户、订单类型、工序、业务员等，通过 type 字段区分）
-		// ❌ 不再创建独立集合：color、size、customer、order_type、procedure、salesman
-		// ✅ 所有基础数据统一存储在 basic 集合中
+		"basic", // 旧版基础数据（已迁移到下方独立集合，仅保留用于兼容）
+		// 基础资料独立集合（各自的字段和校验）
+		"customers",           // 客户
+		"salesmen",            // 业务员
+		"colors",              // 颜色
+		"sizes",               // 尺寸
+		"order_types",         // 订单类型
+		"procedures",          // 工序
+		"procedure_templates", // 工序模板
+		"pricing_settings",    // 工价设置（统一费率）
 	}
 
 	for _, collName := range collections {
@@ -122,7 +198,7 @@ func (m *DatabaseManager) CreateTenantDatabase(ctx context.Context, tenantCode s
 			Keys: bson.D{{Key: "is_deleted", Value: 1}},
 		})
 		if err != nil {
-			log.Printf("⚠️  创建 is_deleted 索引失败 (%s): %v", collName, err)
+			log.Ctx(ctx).Warn("创建 is_deleted 索引失败", zap.String("collection", collName), zap.Error(err))
 		}
 
 		// admin 集合特殊索引
@@ -133,7 +209,7 @@ func (m *DatabaseManager) CreateTenantDatabase(ctx context.Context, tenantCode s
 				Options: options.Index().SetUnique(true).SetSparse(true),
 			})
 			if err != nil {
-				log.Printf("⚠️  创建 phone 索引失败: %v", err)
+				log.Ctx(ctx).Warn("创建 phone 索引失败", zap.Error(err))
 			}
 
 			// 邮箱索引
@@ -142,7 +218,7 @@ func (m *DatabaseManager) CreateTenantDatabase(ctx context.Context, tenantCode s
 				Options: options.Index().SetSparse(true),
 			})
 			if err != nil {
-				log.Printf("⚠️  创建 email 索引失败: %v", err)
+				log.Ctx(ctx).Warn("创建 email 索引失败", zap.Error(err))
 			}
 		}
 
@@ -153,7 +229,7 @@ func (m *DatabaseManager) CreateTenantDatabase(ctx context.Context, tenantCode s
 				Keys: bson.D{{Key: "type", Value: 1}},
 			})
 			if err != nil {
-				log.Printf("⚠️  创建 type 索引失败: %v", err)
+				log.Ctx(ctx).Warn("创建 type 索引失败", zap.Error(err))
 			}
 
 			// type + code 复合索引（确保同类型下 code 唯一）
@@ -165,17 +241,32 @@ func (m *DatabaseManager) CreateTenantDatabase(ctx context.Context, tenantCode s
 				Options: options.Index().SetUnique(true).SetSparse(true),
 			})
 			if err != nil {
-				log.Printf("⚠️  创建 type+code 复合索引失败: %v", err)
+				log.Ctx(ctx).Warn("创建 type+code 复合索引失败", zap.Error(err))
+			}
+		}
+
+		// 基础资料集合索引（名称、编码查重和搜索；编码唯一索引见 tenantIndexes）
+		if masterDataCollections[collName] {
+			_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: bson.D{{Key: "value", Value: 1}}},
+				{Keys: bson.D{{Key: "code", Value: 1}}},
+			})
+			if err != nil {
+				log.Ctx(ctx).Warn("创建 value/code 索引失败", zap.String("collection", collName), zap.Error(err))
 			}
 		}
 
-		log.Printf("  ✅ 集合 %s 创建成功", collName)
+		log.Ctx(ctx).Debug("集合创建成功", zap.String("database", dbName), zap.String("collection", collName))
 	}
 
 	// 缓存数据库连接（使用 code 作为缓存 key）
 	m.tenantDBs.Store(tenantCode, db)
 
-	log.Printf("✅ 租户数据库创建完成: %s", dbName)
+	if err := m.EnsureTenantIndexes(ctx, tenantCode); err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info("租户数据库创建完成", zap.String("database", dbName))
 	return nil
 }
 
@@ -183,7 +274,7 @@ func (m *DatabaseManager) CreateTenantDatabase(ctx context.Context, tenantCode s
 func (m *DatabaseManager) DeleteTenantDatabase(ctx context.Context, tenantCode string) error {
 	dbName := GetTenantDatabaseName(tenantCode)
 
-	log.Printf("⚠️  准备删除租户数据库: %s", dbName)
+	log.Ctx(ctx).Warn("准备删除租户数据库", zap.String("database", dbName))
 
 	// 删除数据库
 	err := m.client.Database(dbName).Drop(ctx)
@@ -194,7 +285,7 @@ func (m *DatabaseManager) DeleteTenantDatabase(ctx context.Context, tenantCode s
 	// 从缓存移除（使用 code 作为 key）
 	m.tenantDBs.Delete(tenantCode)
 
-	log.Printf("✅ 租户数据库已删除: %s", dbName)
+	log.Ctx(ctx).Warn("租户数据库已删除", zap.String("database", dbName))
 	return nil
 }
 
@@ -257,7 +348,7 @@ func (m *DatabaseManager) CleanupInactiveDatabases() {
 
 	// 清空缓存，下次访问时会重新创建连接
 	m.tenantDBs = sync.Map{}
-	log.Println("🧹 已清理不活跃的数据库连接缓存")
+	log.Info("已清理不活跃的数据库连接缓存")
 }
 
 // HealthCheck 健康检查
@@ -3,8 +3,10 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
 	"mule-cloud/core/config"
+	"mule-cloud/core/logger"
+	"mule-cloud/core/metrics"
+	"mule-cloud/core/tracing"
 	"sync"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.uber.org/zap"
 )
 
 var (
@@ -21,13 +24,19 @@ var (
 	mongoErr    error
 )
 
+// 数据库的模块日志（database.command 输出每条命令，默认不输出）
+var (
+	log        = logger.For("database")
+	commandLog = logger.For("database.command")
+)
+
 // MongoDB 全局MongoDB实例（懒加载）
 var MongoDB = &MongoDBInstance{}
 
 // InitMongoDB 初始化MongoDB连接
 func InitMongoDB(cfg *config.MongoDBConfig) (*mongo.Client, error) {
 	if !cfg.Enabled {
-		log.Println("⚠️  MongoDB未启用")
+		log.Warn("MongoDB未启用")
 		return nil, nil
 	}
 
@@ -65,19 +74,22 @@ func InitMongoDB(cfg *config.MongoDBConfig) (*mongo.Client, error) {
 		clientOpts.SetReplicaSet(cfg.ReplicaSet)
 	}
 
-	// 命令监控（开发环境）
+	// 命令监控（database.command 模块调到 debug 时输出每条命令）
 	cmdMonitor := &event.CommandMonitor{
 		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
-			log.Printf("[MongoDB] 执行命令: %s, 命令内容: %v", e.CommandName, e.Command)
+			commandLog.Ctx(ctx).Debug("执行命令", zap.String("command", e.CommandName), zap.Stringer("body", e.Command))
 		},
 		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
-			// log.Printf("[MongoDB] 命令成功: %s, 耗时: %dms", e.CommandName, e.Duration.Milliseconds())
+			commandLog.Ctx(ctx).Debug("命令成功", zap.String("command", e.CommandName), zap.Duration("duration", e.Duration))
 		},
 		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
-			log.Printf("[MongoDB] 命令失败: %s, 错误: %v", e.CommandName, e.Failure)
+			commandLog.Ctx(ctx).Warn("命令失败", zap.String("command", e.CommandName), zap.Duration("duration", e.Duration), zap.Error(e.Failure))
 		},
 	}
-	clientOpts.SetMonitor(cmdMonitor)
+	clientOpts.SetMonitor(tracing.MongoMonitor(cmdMonitor))
+
+	// 连接池监控（Prometheus 指标）
+	clientOpts.SetPoolMonitor(metrics.MongoPoolMonitor())
 
 	// 连接MongoDB
 	client, err := mongo.Connect(clientOpts)
@@ -90,7 +102,7 @@ func InitMongoDB(cfg *config.MongoDBConfig) (*mongo.Client, error) {
 		return nil, fmt.Errorf("Ping MongoDB失败: %v", err)
 	}
 
-	log.Printf("✅ MongoDB连接成功: %s:%d/%s", cfg.Host, cfg.Port, cfg.Database)
+	log.Info("MongoDB连接成功", zap.String("host", cfg.Host), zap.Int("port", cfg.Port), zap.String("database", cfg.Database))
 
 	// 保存全局实例
 	mongoClient = client
@@ -159,7 +171,7 @@ func CloseMongoDB() error {
 		return fmt.Errorf("关闭MongoDB连接失败: %v", err)
 	}
 
-	log.Println("✅ MongoDB连接已关闭")
+	log.Info("MongoDB连接已关闭")
 	return nil
 }
 
@@ -0,0 +1,159 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"mule-cloud/core/config"
+)
+
+// prefix 密文前缀：enc:v1:<密钥ID>:<Base64(nonce+密文)>
+const prefix = "enc:v1:"
+
+var (
+	ErrNoKey      = errors.New("未配置敏感字段加密密钥")
+	ErrUnknownKey = errors.New("找不到密文对应的加密密钥")
+	ErrCorrupted  = errors.New("密文格式错误")
+)
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+var (
+	mu      sync.RWMutex
+	current *key
+	keys    = make(map[string]*key) // 密钥ID -> 密钥（含旧密钥）
+)
+
+// Init 加载加密密钥；未配置 key 时不加密，已有密文也无法解密
+func Init(cfg *config.EncryptionConfig) error {
+	if cfg == nil || cfg.Key == "" {
+		return nil
+	}
+	cur, err := parseKey(cfg.Key)
+	if err != nil {
+		return fmt.Errorf("加密密钥无效: %w", err)
+	}
+	all := map[string]*key{cur.id: cur}
+	for _, raw := range cfg.PreviousKeys {
+		old, err := parseKey(raw)
+		if err != nil {
+			return fmt.Errorf("旧加密密钥无效: %w", err)
+		}
+		all[old.id] = old
+	}
+
+	mu.Lock()
+	current, keys = cur, all
+	mu.Unlock()
+	return nil
+}
+
+func parseKey(raw string) (*key, error) {
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("密钥长度必须为32字节，实际 %d", len(secret))
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(secret)
+	return &key{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// Enabled 是否已配置加密密钥
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current != nil
+}
+
+// IsEncrypted 是否为本包生成的密文
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Encrypt 加密字段值；空值、已加密的值原样返回，未配置密钥时返回明文
+func Encrypt(plain string) (string, error) {
+	if plain == "" || IsEncrypted(plain) {
+		return plain, nil
+	}
+	mu.RLock()
+	k := current
+	mu.RUnlock()
+	if k == nil {
+		return plain, nil
+	}
+
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := k.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return prefix + k.id + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密字段值；不是密文（历史明文数据）时原样返回
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, data, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", ErrCorrupted
+	}
+
+	mu.RLock()
+	k := keys[id]
+	noKey := current == nil
+	mu.RUnlock()
+	if k == nil {
+		if noKey {
+			return "", ErrNoKey
+		}
+		return "", ErrUnknownKey
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil || len(sealed) < k.aead.NonceSize() {
+		return "", ErrCorrupted
+	}
+	nonce, ciphertext := sealed[:k.aead.NonceSize()], sealed[k.aead.NonceSize():]
+	plain, err := k.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrCorrupted
+	}
+	return string(plain), nil
+}
+
+// NeedsRotation 值是否需要（重新）加密：明文或使用旧密钥加密的密文
+func NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	mu.RLock()
+	k := current
+	mu.RUnlock()
+	if k == nil {
+		return false
+	}
+	return !strings.HasPrefix(value, prefix+k.id+":")
+}
@@ -0,0 +1,84 @@
+package fieldcrypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"mule-cloud/core/config"
+)
+
+func newKey(t *testing.T) string {
+	t.Helper()
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		t.Fatalf("rand.Read() error = %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// TestEncryptDecrypt 测试加解密、明文兼容和密钥轮换
+func TestEncryptDecrypt(t *testing.T) {
+	oldKey, newKeyValue := newKey(t), newKey(t)
+	if err := Init(&config.EncryptionConfig{Key: oldKey}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	enc, err := Encrypt("110101199001011234")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !IsEncrypted(enc) || enc == "110101199001011234" {
+		t.Fatalf("Encrypt() = %q, want ciphertext", enc)
+	}
+	if again, _ := Encrypt(enc); again != enc {
+		t.Error("Encrypt() should not encrypt ciphertext twice")
+	}
+	if plain, err := Decrypt(enc); err != nil || plain != "110101199001011234" {
+		t.Errorf("Decrypt() = %q, %v", plain, err)
+	}
+	if plain, err := Decrypt("6222020200001234"); err != nil || plain != "6222020200001234" {
+		t.Errorf("Decrypt(plaintext) = %q, %v", plain, err)
+	}
+
+	// 轮换后旧密文仍可解密，并标记为需要重新加密
+	if err := Init(&config.EncryptionConfig{Key: newKeyValue, PreviousKeys: []string{oldKey}}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if plain, err := Decrypt(enc); err != nil || plain != "110101199001011234" {
+		t.Errorf("Decrypt() after rotation = %q, %v", plain, err)
+	}
+	if !NeedsRotation(enc) || !NeedsRotation("plain") {
+		t.Error("NeedsRotation() should be true for old ciphertext and plaintext")
+	}
+	fresh, _ := Encrypt("x")
+	if NeedsRotation(fresh) {
+		t.Error("NeedsRotation() should be false for current ciphertext")
+	}
+
+	// 缺少对应密钥或密文被篡改
+	if err := Init(&config.EncryptionConfig{Key: newKey(t)}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if _, err := Decrypt(enc); err != ErrUnknownKey {
+		t.Errorf("Decrypt() with unknown key error = %v, want ErrUnknownKey", err)
+	}
+	tampered, _ := Encrypt("secret")
+	i := len(tampered) - 8 // 修改认证标签中的一个字符
+	c := byte('A')
+	if tampered[i] == 'A' {
+		c = 'B'
+	}
+	tampered = tampered[:i] + string(c) + tampered[i+1:]
+	if _, err := Decrypt(tampered); err != ErrCorrupted {
+		t.Errorf("Decrypt(tampered) error = %v, want ErrCorrupted", err)
+	}
+}
+
+// TestInitRejectsBadKey 测试非法密钥
+func TestInitRejectsBadKey(t *testing.T) {
+	short := base64.StdEncoding.EncodeToString([]byte("too-short"))
+	if err := Init(&config.EncryptionConfig{Key: short}); err == nil {
+		t.Error("Init() should reject a key that is not 32 bytes")
+	}
+}
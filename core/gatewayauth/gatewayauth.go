@@ -0,0 +1,167 @@
+package gatewayauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mule-cloud/core/config"
+)
+
+const (
+	// HeaderTimestamp 签名时间（Unix 秒）
+	HeaderTimestamp = "X-Gateway-Timestamp"
+	// HeaderSignature 身份头签名：v1=<Base64URL(HMAC-SHA256)>
+	HeaderSignature = "X-Gateway-Signature"
+
+	signatureVersion = "v1"
+	defaultMaxSkew   = 30 * time.Second
+)
+
+// IdentityHeaders 网关转发给服务的身份头（全部参与签名，顺序固定）
+var IdentityHeaders = []string{
+	"X-User-ID",
+	"X-Username",
+	"X-Tenant-ID",
+	"X-Tenant-Code",
+	"X-Roles",
+	"X-Session-ID",
+	"X-Token-ID",
+	"X-MFA",
+	"X-API-Key-ID",
+	"X-Tenant-Context",
+	"X-Impersonation-ID",
+	"X-Read-Only",
+}
+
+var (
+	ErrUnsigned         = errors.New("身份头未签名")
+	ErrExpired          = errors.New("身份头签名已过期")
+	ErrInvalidSignature = errors.New("身份头签名无效")
+	ErrSecretRequired   = errors.New("未配置 gateway_auth.secret，直连服务端口可以伪造身份头（生成密钥: openssl rand -base64 32）")
+)
+
+var (
+	mu       sync.RWMutex
+	secret   []byte
+	required bool
+	maxSkew  = defaultMaxSkew
+)
+
+// Init 加载签名配置；网关用密钥签名，服务用同一密钥校验
+func Init(cfg *config.GatewayAuthConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.RequireSigned && cfg.Secret == "" {
+		return fmt.Errorf("开启 require_signed 时必须配置 gateway_auth.secret")
+	}
+	skew := defaultMaxSkew
+	if cfg.MaxSkew > 0 {
+		skew = time.Duration(cfg.MaxSkew) * time.Second
+	}
+
+	mu.Lock()
+	secret, required, maxSkew = []byte(cfg.Secret), cfg.RequireSigned, skew
+	mu.Unlock()
+	return nil
+}
+
+// Enabled 是否配置了签名密钥
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(secret) > 0
+}
+
+// RequireSecret 检查签名密钥：只有开发模式（debug/test）允许不配置
+func RequireSecret(mode string) error {
+	if Enabled() {
+		return nil
+	}
+	switch mode {
+	case "", "debug", "test":
+		return nil
+	}
+	return ErrSecretRequired
+}
+
+// StripIdentity 删除请求中的身份头和签名（网关转发前调用，客户端自带的同名头一律丢弃）
+func StripIdentity(h http.Header) {
+	for _, name := range IdentityHeaders {
+		h.Del(name)
+	}
+	h.Del(HeaderTimestamp)
+	h.Del(HeaderSignature)
+}
+
+// Sign 对身份头签名（绑定请求方法和路径），未配置密钥时不处理
+func Sign(h http.Header, method, path string) {
+	mu.RLock()
+	key := secret
+	mu.RUnlock()
+	if len(key) == 0 {
+		return
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	h.Set(HeaderTimestamp, ts)
+	h.Set(HeaderSignature, signatureVersion+"="+sign(key, ts, method, path, h))
+}
+
+// Verify 校验身份头签名
+// 带签名的请求必须校验通过；没有签名时，开启 require_signed 返回 ErrUnsigned，否则放行（兼容旧网关）
+func Verify(h http.Header, method, path string, now time.Time) error {
+	mu.RLock()
+	key, require, skew := secret, required, maxSkew
+	mu.RUnlock()
+
+	sig := h.Get(HeaderSignature)
+	if sig == "" || len(key) == 0 {
+		if require {
+			return ErrUnsigned
+		}
+		return nil
+	}
+
+	ts := h.Get(HeaderTimestamp)
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > skew || d < -skew {
+		return ErrExpired
+	}
+
+	version, mac, ok := strings.Cut(sig, "=")
+	if !ok || version != signatureVersion {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(mac), []byte(sign(key, ts, method, path, h))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// sign 计算签名：时间、方法、路径和全部身份头按固定顺序换行拼接
+func sign(key []byte, ts, method, path string, h http.Header) string {
+	var b strings.Builder
+	b.WriteString(signatureVersion)
+	for _, v := range []string{ts, strings.ToUpper(method), path} {
+		b.WriteByte('\n')
+		b.WriteString(v)
+	}
+	for _, name := range IdentityHeaders {
+		b.WriteByte('\n')
+		b.WriteString(h.Get(name))
+	}
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(b.String()))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,28 @@
+package gatewayauth
+
+import (
+	"errors"
+	"testing"
+
+	"mule-cloud/core/config"
+)
+
+// TestRequireSecret 测试只有开发模式允许不配置签名密钥
+func TestRequireSecret(t *testing.T) {
+	t.Cleanup(func() { Init(&config.GatewayAuthConfig{}) })
+
+	Init(&config.GatewayAuthConfig{})
+	for _, mode := range []string{"", "debug", "test"} {
+		if err := RequireSecret(mode); err != nil {
+			t.Errorf("RequireSecret(%q) error = %v", mode, err)
+		}
+	}
+	if err := RequireSecret("release"); !errors.Is(err, ErrSecretRequired) {
+		t.Errorf("RequireSecret(release) error = %v, want ErrSecretRequired", err)
+	}
+
+	Init(&config.GatewayAuthConfig{Secret: "gateway-secret"})
+	if err := RequireSecret("release"); err != nil {
+		t.Errorf("RequireSecret(release) with secret error = %v", err)
+	}
+}
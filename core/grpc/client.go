@@ -3,14 +3,20 @@ package grpc
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
+	"mule-cloud/core/logger"
+	"mule-cloud/core/tracing"
+
 	"github.com/hashicorp/consul/api"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// log gRPC 的模块日志（grpc 模块调到 debug 时输出每个请求）
+var log = logger.For("grpc")
+
 // ClientManager gRPC客户端管理器
 type ClientManager struct {
 	consulClient *api.Client
@@ -54,16 +60,17 @@ func (cm *ClientManager) GetConnection(serviceName string) (*grpc.ClientConn, er
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	conn, err := grpc.DialContext(ctx, addr,
+	opts := append([]grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithBlock(),
-	)
+	}, tracing.GRPCDialOptions()...)
+	conn, err := grpc.DialContext(ctx, addr, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("连接gRPC服务失败: %v", err)
 	}
 
 	cm.connections[serviceName] = conn
-	log.Printf("[gRPC] 已连接到服务: %s (%s)", serviceName, addr)
+	log.Info("已连接到服务", zap.String("target_service", serviceName), zap.String("addr", addr))
 
 	return conn, nil
 }
@@ -88,9 +95,9 @@ func (cm *ClientManager) getServiceAddress(serviceName string) (string, error) {
 func (cm *ClientManager) Close() {
 	for name, conn := range cm.connections {
 		if err := conn.Close(); err != nil {
-			log.Printf("[gRPC] 关闭连接失败: %s, 错误: %v", name, err)
+			log.Warn("关闭连接失败", zap.String("target_service", name), zap.Error(err))
 		} else {
-			log.Printf("[gRPC] 已关闭连接: %s", name)
+			log.Info("已关闭连接", zap.String("target_service", name))
 		}
 	}
 }
@@ -106,7 +113,7 @@ func CallWithRetry(ctx context.Context, maxRetries int, fn func() error) error {
 
 		if i < maxRetries-1 {
 			time.Sleep(time.Duration(i+1) * 100 * time.Millisecond)
-			log.Printf("[gRPC] 重试 %d/%d: %v", i+1, maxRetries, err)
+			log.Ctx(ctx).Warn("调用失败，重试", zap.Int("attempt", i+1), zap.Int("max_retries", maxRetries), zap.Error(err))
 		}
 	}
 	return fmt.Errorf("重试%d次后失败: %v", maxRetries, err)
@@ -3,9 +3,11 @@ package grpc
 import (
 	"context"
 	"fmt"
-	"log"
 	"net"
 
+	"mule-cloud/core/tracing"
+
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -17,10 +19,8 @@ type ServerConfig struct {
 
 // NewServer 创建gRPC服务器
 func NewServer(config *ServerConfig) *grpc.Server {
-	server := grpc.NewServer(
-		// 可以添加拦截器
-		grpc.UnaryInterceptor(loggingInterceptor),
-	)
+	opts := append(tracing.GRPCServerOptions(), grpc.ChainUnaryInterceptor(loggingInterceptor))
+	server := grpc.NewServer(opts...)
 
 	// 注册反射服务（用于grpcurl等工具）
 	reflection.Register(server)
@@ -35,16 +35,16 @@ func StartServer(server *grpc.Server, port int) error {
 		return fmt.Errorf("监听端口失败: %v", err)
 	}
 
-	log.Printf("[gRPC] 服务器启动: :%d", port)
+	log.Info("gRPC服务器启动", zap.Int("port", port))
 	return server.Serve(lis)
 }
 
 // loggingInterceptor 日志拦截器
 func loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	log.Printf("[gRPC] 请求: %s", info.FullMethod)
+	log.Ctx(ctx).Debug("gRPC请求", zap.String("method", info.FullMethod))
 	resp, err := handler(ctx, req)
 	if err != nil {
-		log.Printf("[gRPC] 错误: %s - %v", info.FullMethod, err)
+		log.Ctx(ctx).Warn("gRPC请求失败", zap.String("method", info.FullMethod), zap.Error(err))
 	}
 	return resp, err
 }
@@ -9,6 +9,9 @@ import (
 	"net/http"
 	"time"
 
+	"mule-cloud/core/gatewayauth"
+	"mule-cloud/core/tracing"
+
 	"github.com/hashicorp/consul/api"
 )
 
@@ -45,7 +48,8 @@ func NewServiceClient(consulAddr string) (*ServiceClient, error) {
 	return &ServiceClient{
 		consulClient: client,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: tracing.Transport(nil), // 传递 traceparent 和 X-Request-ID
 		},
 	}, nil
 }
@@ -166,6 +170,8 @@ func (c *ServiceClient) doRequest(ctx context.Context, method, serviceName, path
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
+	// 5.4 身份头签名（与网关相同的密钥，开启 require_signed 的服务才会信任）
+	gatewayauth.Sign(req.Header, method, req.URL.Path)
 
 	// 6. 发送请求
 	resp, err := c.httpClient.Do(req)
@@ -2,12 +2,16 @@ package hystrix
 
 import (
 	"fmt"
-	"log"
+	"mule-cloud/core/logger"
 	"time"
 
 	"github.com/afex/hystrix-go/hystrix"
+	"go.uber.org/zap"
 )
 
+// log 熔断器的模块日志
+var log = logger.For("hystrix")
+
 // Config Hystrix配置
 type Config struct {
 	// Timeout 超时时间（毫秒）
@@ -36,13 +40,16 @@ var ServiceConfigs = map[string]Config{}
 
 // Init 初始化Hystrix配置
 func Init() {
-	log.Println("🔧 初始化Hystrix熔断器配置...")
+	log.Info("初始化Hystrix熔断器配置")
 
 	// 配置所有服务
 	for serviceName, config := range ServiceConfigs {
 		ConfigureCommand(serviceName, config)
-		log.Printf("   ✓ %s: 超时=%dms, 并发=%d, 错误率阈值=%d%%",
-			serviceName, config.Timeout, config.MaxConcurrentRequests, config.ErrorPercentThreshold)
+		log.Info("熔断器配置",
+			zap.String("command", serviceName),
+			zap.Int("timeout_ms", config.Timeout),
+			zap.Int("max_concurrent", config.MaxConcurrentRequests),
+			zap.Int("error_percent_threshold", config.ErrorPercentThreshold))
 	}
 
 	// 配置默认值
@@ -52,12 +59,12 @@ func Init() {
 	hystrix.DefaultSleepWindow = DefaultConfig.SleepWindow
 	hystrix.DefaultErrorPercentThreshold = DefaultConfig.ErrorPercentThreshold
 
-	log.Println("✅ Hystrix初始化完成")
+	log.Info("Hystrix初始化完成")
 }
 
 // InitWithConfig 使用配置初始化Hystrix
 func InitWithConfig(commands map[string]Config) {
-	log.Println("🔧 初始化Hystrix熔断器配置...")
+	log.Info("初始化Hystrix熔断器配置")
 
 	// 设置服务配置
 	ServiceConfigs = commands
@@ -65,8 +72,11 @@ func InitWithConfig(commands map[string]Config) {
 	// 配置所有服务
 	for serviceName, config := range ServiceConfigs {
 		ConfigureCommand(serviceName, config)
-		log.Printf("   ✓ %s: 超时=%dms, 并发=%d, 错误率阈值=%d%%",
-			serviceName, config.Timeout, config.MaxConcurrentRequests, config.ErrorPercentThreshold)
+		log.Info("熔断器配置",
+			zap.String("command", serviceName),
+			zap.Int("timeout_ms", config.Timeout),
+			zap.Int("max_concurrent", config.MaxConcurrentRequests),
+			zap.Int("error_percent_threshold", config.ErrorPercentThreshold))
 	}
 
 	// 配置默认值
@@ -76,7 +86,7 @@ func InitWithConfig(commands map[string]Config) {
 	hystrix.DefaultSleepWindow = DefaultConfig.SleepWindow
 	hystrix.DefaultErrorPercentThreshold = DefaultConfig.ErrorPercentThreshold
 
-	log.Println("✅ Hystrix初始化完成")
+	log.Info("Hystrix初始化完成")
 }
 
 // ConfigureCommand 配置指定命令的Hystrix参数
@@ -157,6 +167,15 @@ func GetAllCircuitStatus() map[string]interface{} {
 	return status
 }
 
+// CircuitStates 已配置的熔断器是否打开（Prometheus 采集）
+func CircuitStates() map[string]bool {
+	states := make(map[string]bool, len(ServiceConfigs))
+	for serviceName := range ServiceConfigs {
+		states[serviceName] = CircuitBreakerStatus(serviceName) == "open"
+	}
+	return states
+}
+
 // Metrics 熔断器指标
 type Metrics struct {
 	TotalRequests        int64   `json:"total_requests"`
@@ -186,7 +205,7 @@ func GetMetrics(commandName string) (*Metrics, error) {
 // StartStreamHandler 启动Hystrix Stream（用于监控）
 func StartStreamHandler(port string) {
 	go func() {
-		log.Printf("🔍 Hystrix Stream监控启动: http://localhost%s", port)
+		log.Info("Hystrix Stream监控启动", zap.String("addr", "http://localhost"+port))
 		// 可以使用 hystrix.StreamHandler 配合 net/http
 		// import "github.com/afex/hystrix-go/hystrix"
 		// http.Handle("/hystrix.stream", hystrix.NewStreamHandler())
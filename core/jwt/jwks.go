@@ -0,0 +1,105 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval 遇到未知 kid 时重新拉取 JWKS 的最短间隔（防止伪造 kid 打爆认证服务）
+const minRefreshInterval = 30 * time.Second
+
+// RemoteKeySet 从认证服务的 JWKS 端点获取并缓存公钥
+//
+// 缓存按 refresh 周期过期；遇到未知 kid（认证服务已轮换密钥）时立即重新拉取。
+// 拉取失败时继续使用已缓存的公钥。
+type RemoteKeySet struct {
+	url     string
+	refresh time.Duration
+	client  *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	lastTry   time.Time
+}
+
+// NewRemoteKeySet 创建 JWKS 客户端（首次验证令牌时才会拉取）
+func NewRemoteKeySet(url string, refresh time.Duration) *RemoteKeySet {
+	if refresh <= 0 {
+		refresh = 10 * time.Minute
+	}
+	return &RemoteKeySet{
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		keys:    make(map[string]crypto.PublicKey),
+	}
+}
+
+// PublicKey 实现 KeyProvider
+func (r *RemoteKeySet) PublicKey(kid string) (crypto.PublicKey, error) {
+	r.mu.RLock()
+	key, ok := r.keys[kid]
+	stale := time.Since(r.fetchedAt) > r.refresh
+	r.mu.RUnlock()
+	if ok && !stale {
+		return key, nil
+	}
+
+	if err := r.fetch(); err != nil && !ok {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if key, ok := r.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+// fetch 拉取 JWKS，距上次尝试不足 minRefreshInterval 时跳过
+func (r *RemoteKeySet) fetch() error {
+	r.mu.Lock()
+	if time.Since(r.lastTry) < minRefreshInterval {
+		r.mu.Unlock()
+		return nil
+	}
+	r.lastTry = time.Now()
+	r.mu.Unlock()
+
+	resp, err := r.client.Get(r.url)
+	if err != nil {
+		return fmt.Errorf("获取 JWKS 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("获取 JWKS 失败: HTTP %d", resp.StatusCode)
+	}
+
+	var set JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("解析 JWKS 失败: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.fetchedAt = time.Now()
+	r.mu.Unlock()
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"time"
 
+	"mule-cloud/core/config"
+
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
@@ -16,20 +19,43 @@ var (
 	ErrTokenNotValidYet = errors.New("token未生效")
 )
 
+// DefaultIssuer 未配置 issuer 时签发令牌使用的签发者
+const DefaultIssuer = "mule-cloud"
+
+// hs256PrivilegedRoles 限定签发者的 HS256 令牌（如小程序）不允许携带的特权角色
+var hs256PrivilegedRoles = map[string]bool{"super": true, "tenant_admin": true}
+
 // Claims JWT声明
 type Claims struct {
 	UserID     string   `json:"user_id"`
 	Username   string   `json:"username"`
-	TenantID   string   `json:"tenant_id"`   // 租户ID（MongoDB ObjectID，用于兼容）
-	TenantCode string   `json:"tenant_code"` // 租户代码（用于数据库名称，推荐使用）
-	Roles      []string `json:"roles"`       // 用户角色
+	TenantID   string   `json:"tenant_id"`     // 租户ID（MongoDB ObjectID，用于兼容）
+	TenantCode string   `json:"tenant_code"`   // 租户代码（用于数据库名称，推荐使用）
+	Roles      []string `json:"roles"`         // 用户角色
+	SessionID  string   `json:"sid,omitempty"` // 登录会话ID（用于注销和吊销）
+	MFA        bool     `json:"mfa,omitempty"` // 本次登录是否通过了两步验证
+	// 超管代管租户时的代管会话ID和只读标记（普通令牌为空）
+	ImpersonationID string `json:"imp,omitempty"`
+	ReadOnly        bool   `json:"ro,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // JWTManager JWT管理器
+//
+// 两种模式：
+//   - HS256：所有服务共享 secretKey（旧方式）
+//   - RS256/EdDSA：只有认证服务持有私钥签名，其他服务通过 JWKS 获取公钥验证
+//
+// 同时配置两者时（如网关），HS256 令牌只接受 hs256_issuer 签发的（如小程序），
+// 且必须属于某个租户、不能携带特权角色和代管、两步验证标记，
+// 避免持有共享密钥的服务伪造认证服务的令牌或给自己授予管理员权限。
 type JWTManager struct {
 	secretKey     []byte
 	tokenDuration time.Duration
+	issuer        string      // 签发令牌使用的签发者
+	hs256Issuer   string      // 非空时 HS256 令牌的签发者必须与之一致
+	signingKey    *SigningKey // 非对称签名密钥（仅认证服务）
+	keys          KeyProvider // 非对称验证公钥
 }
 
 // NewJWTManager 创建JWT管理器
@@ -43,37 +69,119 @@ func NewJWTManager(secretKey []byte, duration time.Duration) *JWTManager {
 	return &JWTManager{
 		secretKey:     secretKey,
 		tokenDuration: duration,
+		issuer:        DefaultIssuer,
+	}
+}
+
+// NewFromConfig 按配置创建JWT管理器
+//
+//   - 配置了 keys：使用私钥签名（认证服务），同时用这些密钥验证
+//   - 配置了 jwks_url：从认证服务获取公钥验证（网关和其他服务）
+//   - 配置了 secret_key：接受 HS256 令牌（未迁移的签发方，如小程序）；
+//     与 keys 或 jwks_url 同时配置时必须指定 hs256_issuer，只接受该签发方的 HS256 令牌
+//   - 都未配置时返回错误（不再退回默认密钥，避免用公开的默认密钥签发和验证令牌）
+func NewFromConfig(cfg *config.JWTConfig, duration time.Duration) (*JWTManager, error) {
+	if duration == 0 {
+		duration = 24 * time.Hour
+	}
+	m := &JWTManager{tokenDuration: duration, issuer: cfg.Issuer}
+	if m.issuer == "" {
+		m.issuer = DefaultIssuer
+	}
+	if cfg.SecretKey != "" {
+		m.secretKey = []byte(cfg.SecretKey)
+		if len(cfg.Keys) > 0 || cfg.JWKSURL != "" {
+			if cfg.HS256Issuer == "" {
+				return nil, errors.New("同时配置 secret_key 和非对称密钥时必须配置 jwt.hs256_issuer")
+			}
+			m.hs256Issuer = cfg.HS256Issuer
+		}
+	}
+
+	switch {
+	case len(cfg.Keys) > 0:
+		ks, err := LoadKeySet(cfg)
+		if err != nil {
+			return nil, err
+		}
+		m.signingKey = ks.Signing()
+		m.keys = ks
+	case cfg.JWKSURL != "":
+		m.keys = NewRemoteKeySet(cfg.JWKSURL, time.Duration(cfg.JWKSRefreshMinutes)*time.Minute)
+	case m.secretKey == nil:
+		return nil, errors.New("未配置 jwt.keys、jwt.jwks_url 或 jwt.secret_key")
+	}
+	return m, nil
+}
+
+// JWKS 返回签名公钥集合（只有配置了签名密钥时可用）
+func (m *JWTManager) JWKS() (*JWKS, bool) {
+	ks, ok := m.keys.(*KeySet)
+	if !ok {
+		return nil, false
 	}
+	return ks.JWKS(), true
 }
 
 // GenerateToken 生成JWT Token
 // tenantID: 租户的 MongoDB ObjectID（用于兼容查询）
 // tenantCode: 租户代码（用于数据库名称）
 func (m *JWTManager) GenerateToken(userID, username, tenantID, tenantCode string, roles []string) (string, error) {
-	now := time.Now()
-	claims := Claims{
+	token, _, err := m.GenerateSessionToken("", userID, username, tenantID, tenantCode, roles)
+	return token, err
+}
+
+// GenerateSessionToken 生成属于指定登录会话的JWT Token，同时返回声明（jti、过期时间）
+func (m *JWTManager) GenerateSessionToken(sessionID, userID, username, tenantID, tenantCode string, roles []string) (string, *Claims, error) {
+	return m.IssueToken(&Claims{
 		UserID:     userID,
 		Username:   username,
 		TenantID:   tenantID,
 		TenantCode: tenantCode, // ✅ 新增：用于数据库连接
 		Roles:      roles,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(m.tokenDuration)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "mule-cloud",
-		},
+		SessionID:  sessionID,
+	})
+}
+
+// IssueToken 按给定的业务声明签发JWT Token，jti、签发时间和过期时间由管理器填充
+func (m *JWTManager) IssueToken(claims *Claims) (string, *Claims, error) {
+	return m.IssueTokenWithTTL(claims, m.tokenDuration)
+}
+
+// IssueTokenWithTTL 按指定有效期签发JWT Token（如限时的代管令牌）
+func (m *JWTManager) IssueTokenWithTTL(claims *Claims, ttl time.Duration) (string, *Claims, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    m.issuer,
+	}
+
+	var token string
+	var err error
+	if m.signingKey != nil {
+		t := jwt.NewWithClaims(m.signingKey.method(), claims)
+		t.Header["kid"] = m.signingKey.ID
+		token, err = t.SignedString(m.signingKey.Private)
+	} else {
+		token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secretKey)
 	}
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secretKey)
+// TokenDuration 访问令牌有效期
+func (m *JWTManager) TokenDuration() time.Duration {
+	return m.tokenDuration
 }
 
 // ValidateToken 验证JWT Token
 func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return m.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.keyFunc, jwt.WithValidMethods(m.validMethods()))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -83,21 +191,54 @@ func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		if token.Method.Alg() == AlgHS256 && m.hs256Issuer != "" && !hs256ClaimsAllowed(claims, m.hs256Issuer) {
+			return nil, ErrTokenInvalid
+		}
 		return claims, nil
 	}
 
 	return nil, ErrTokenInvalid
 }
 
-// RefreshToken 刷新Token（重新生成）
-func (m *JWTManager) RefreshToken(oldToken string) (string, error) {
-	claims, err := m.ValidateToken(oldToken)
-	if err != nil && !errors.Is(err, ErrTokenExpired) {
-		return "", err
+// hs256ClaimsAllowed 限定签发者的 HS256 令牌的声明范围：签发者一致、属于某个租户、
+// 不带特权角色，也不带代管会话和两步验证标记（这些只由认证服务签发）
+func hs256ClaimsAllowed(c *Claims, issuer string) bool {
+	if c.Issuer != issuer || c.TenantCode == "" || c.TenantCode == "system" {
+		return false
+	}
+	if c.ImpersonationID != "" || c.ReadOnly || c.MFA {
+		return false
+	}
+	for _, role := range c.Roles {
+		if hs256PrivilegedRoles[role] {
+			return false
+		}
+	}
+	return true
+}
+
+// keyFunc 按令牌的签名算法选择验证密钥：HS256 用共享密钥，RS256/EdDSA 按 kid 查公钥
+func (m *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		return m.secretKey, nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrKeyNotFound
 	}
+	return m.keys.PublicKey(kid)
+}
 
-	// 即使过期也允许刷新（在一定时间窗口内）
-	return m.GenerateToken(claims.UserID, claims.Username, claims.TenantID, claims.TenantCode, claims.Roles)
+// validMethods 允许的签名算法（防止算法混淆：未配置共享密钥时拒绝 HS256，反之亦然）
+func (m *JWTManager) validMethods() []string {
+	var methods []string
+	if m.secretKey != nil {
+		methods = append(methods, AlgHS256)
+	}
+	if m.keys != nil {
+		methods = append(methods, AlgRS256, AlgEdDSA)
+	}
+	return methods
 }
 
 // HasRole 检查用户是否有指定角色
@@ -119,3 +260,12 @@ func (c *Claims) HasAnyRole(roles ...string) bool {
 	}
 	return false
 }
+
+// ReadOnlyAllowed 只读令牌（如只读代管）允许的请求方法
+func ReadOnlyAllowed(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
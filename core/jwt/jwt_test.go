@@ -0,0 +1,152 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mule-cloud/core/config"
+)
+
+// TestAsymmetricSigning 测试认证服务签名、其他服务通过 JWKS 验证以及密钥轮换
+func TestAsymmetricSigning(t *testing.T) {
+	dir := t.TempDir()
+	for _, alg := range []string{AlgRS256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			oldKey := config.JWTKeyConfig{KID: alg + "-old", PrivateKeyFile: filepath.Join(dir, alg+"-old.pem")}
+			newKey := config.JWTKeyConfig{KID: alg + "-new", PrivateKeyFile: filepath.Join(dir, alg+"-new.pem")}
+
+			// 轮换前：只有旧密钥
+			issuer, err := NewFromConfig(&config.JWTConfig{Algorithm: alg, Keys: []config.JWTKeyConfig{oldKey}}, time.Minute)
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			oldToken, _, err := issuer.GenerateSessionToken("sid", "u1", "user", "t1", "tenant", []string{"admin"})
+			if err != nil {
+				t.Fatalf("GenerateSessionToken() error = %v", err)
+			}
+
+			// 轮换后：新密钥签名，旧密钥继续发布
+			issuer, err = NewFromConfig(&config.JWTConfig{
+				Algorithm:    alg,
+				SigningKeyID: newKey.KID,
+				Keys:         []config.JWTKeyConfig{oldKey, newKey},
+			}, time.Minute)
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			newToken, _, _ := issuer.GenerateSessionToken("sid", "u1", "user", "t1", "tenant", nil)
+
+			set, ok := issuer.JWKS()
+			if !ok || len(set.Keys) != 2 {
+				t.Fatalf("JWKS() = %v, %v, want 2 keys", set, ok)
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(set)
+			}))
+			defer server.Close()
+
+			verifier, err := NewFromConfig(&config.JWTConfig{JWKSURL: server.URL}, 0)
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+				claims, err := verifier.ValidateToken(token)
+				if err != nil {
+					t.Fatalf("ValidateToken(%s) error = %v", name, err)
+				}
+				if claims.UserID != "u1" || claims.TenantCode != "tenant" {
+					t.Errorf("ValidateToken(%s) claims = %+v", name, claims)
+				}
+			}
+
+			// 只配置 JWKS 的服务不接受共享密钥签名的令牌
+			hsToken, _ := NewJWTManager(nil, time.Minute).GenerateToken("u1", "user", "t1", "tenant", nil)
+			if _, err := verifier.ValidateToken(hsToken); err == nil {
+				t.Error("ValidateToken() should reject HS256 token when only JWKS is configured")
+			}
+		})
+	}
+}
+
+// TestLegacySecret 未配置非对称密钥时保持 HS256 行为
+func TestLegacySecret(t *testing.T) {
+	m, err := NewFromConfig(&config.JWTConfig{SecretKey: "test-secret"}, time.Minute)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	token, err := m.GenerateToken("u1", "user", "", "system", []string{"super"})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := m.ValidateToken(token); err != nil {
+		t.Errorf("ValidateToken() error = %v", err)
+	}
+	if _, err := NewJWTManager([]byte("other"), time.Minute).ValidateToken(token); err == nil {
+		t.Error("ValidateToken() should reject a token signed with another secret")
+	}
+	if _, ok := m.JWKS(); ok {
+		t.Error("JWKS() should be unavailable without signing keys")
+	}
+}
+
+// TestHS256Issuer 同时配置共享密钥和 JWKS 时，只接受指定签发者的 HS256 令牌
+func TestHS256Issuer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&JWKS{})
+	}))
+	defer server.Close()
+
+	if _, err := NewFromConfig(&config.JWTConfig{SecretKey: "shared", JWKSURL: server.URL}, 0); err == nil {
+		t.Fatal("NewFromConfig() should require hs256_issuer when mixing secret_key and jwks_url")
+	}
+	gateway, err := NewFromConfig(&config.JWTConfig{SecretKey: "shared", JWKSURL: server.URL, HS256Issuer: "mule-cloud-miniapp"}, 0)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+
+	miniapp, _ := NewFromConfig(&config.JWTConfig{SecretKey: "shared", Issuer: "mule-cloud-miniapp"}, time.Minute)
+	token, _ := miniapp.GenerateToken("u1", "user", "t1", "tenant", []string{"worker"})
+	claims, err := gateway.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken(miniapp) error = %v", err)
+	}
+	if claims.Issuer != "mule-cloud-miniapp" {
+		t.Errorf("Issuer = %q, want mule-cloud-miniapp", claims.Issuer)
+	}
+
+	// 持有共享密钥伪造认证服务签发者的令牌
+	forged, _ := NewJWTManager([]byte("shared"), time.Minute).GenerateToken("u1", "user", "", "system", []string{"super"})
+	if _, err := gateway.ValidateToken(forged); err == nil {
+		t.Error("ValidateToken() should reject HS256 token from another issuer")
+	}
+
+	// 小程序签发者的令牌也不能越权
+	tests := []struct {
+		name   string
+		claims Claims
+	}{
+		{"超管角色", Claims{UserID: "u1", TenantCode: "tenant", Roles: []string{"super"}}},
+		{"租户管理员角色", Claims{UserID: "u1", TenantCode: "tenant", Roles: []string{"worker", "tenant_admin"}}},
+		{"无租户", Claims{UserID: "u1", Roles: []string{"worker"}}},
+		{"系统库", Claims{UserID: "u1", TenantCode: "system", Roles: []string{"worker"}}},
+		{"代管会话", Claims{UserID: "u1", TenantCode: "tenant", ImpersonationID: "imp1"}},
+		{"两步验证标记", Claims{UserID: "u1", TenantCode: "tenant", MFA: true}},
+	}
+	for _, tt := range tests {
+		token, _, _ := miniapp.IssueToken(&tt.claims)
+		if _, err := gateway.ValidateToken(token); err == nil {
+			t.Errorf("%s: ValidateToken() should reject the miniapp token", tt.name)
+		}
+	}
+}
+
+// TestNewFromConfigRequiresKey 测试未配置任何密钥时拒绝启动，不退回默认密钥
+func TestNewFromConfigRequiresKey(t *testing.T) {
+	if _, err := NewFromConfig(&config.JWTConfig{Issuer: "mule-cloud"}, time.Minute); err == nil {
+		t.Fatal("NewFromConfig() should fail without keys, jwks_url or secret_key")
+	}
+}
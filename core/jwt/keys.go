@@ -0,0 +1,267 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"mule-cloud/core/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256" // 共享密钥（旧方式，所有服务都持有同一个密钥）
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrKeyNotFound    = errors.New("未找到令牌签名密钥")
+	ErrNoSigningKey   = errors.New("未配置签名密钥")
+	ErrUnsupportedKey = errors.New("不支持的密钥类型，仅支持 RSA 和 Ed25519")
+)
+
+// KeyProvider 按 kid 提供验证令牌用的公钥
+type KeyProvider interface {
+	PublicKey(kid string) (crypto.PublicKey, error)
+}
+
+// SigningKey 非对称签名密钥（私钥为空时只用于验证，例如轮换后仍需验证旧令牌的退役密钥）
+type SigningKey struct {
+	ID      string
+	Alg     string
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// method 对应的 JWT 签名方法
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Alg == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// KeySet 认证服务持有的密钥集合：一个当前签名密钥，其余密钥继续通过 JWKS 发布直到旧令牌过期
+type KeySet struct {
+	signing *SigningKey
+	keys    map[string]*SigningKey
+	order   []string
+}
+
+// LoadKeySet 按配置加载密钥；私钥文件不存在时按 algorithm 生成并写入文件
+func LoadKeySet(cfg *config.JWTConfig) (*KeySet, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+	ks := &KeySet{keys: make(map[string]*SigningKey)}
+	for _, kc := range cfg.Keys {
+		if kc.KID == "" {
+			return nil, errors.New("JWT 密钥缺少 kid")
+		}
+		if _, exists := ks.keys[kc.KID]; exists {
+			return nil, fmt.Errorf("JWT 密钥 kid 重复: %s", kc.KID)
+		}
+		key, err := loadKey(kc, cfg.Algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("加载 JWT 密钥 %s 失败: %w", kc.KID, err)
+		}
+		ks.keys[kc.KID] = key
+		ks.order = append(ks.order, kc.KID)
+	}
+
+	// 未指定签名密钥时使用第一个带私钥的密钥
+	signingKID := cfg.SigningKeyID
+	if signingKID == "" {
+		for _, kid := range ks.order {
+			if ks.keys[kid].Private != nil {
+				signingKID = kid
+				break
+			}
+		}
+	}
+	ks.signing = ks.keys[signingKID]
+	if ks.signing == nil || ks.signing.Private == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoSigningKey, signingKID)
+	}
+	return ks, nil
+}
+
+// Signing 当前签名密钥
+func (ks *KeySet) Signing() *SigningKey {
+	return ks.signing
+}
+
+// PublicKey 实现 KeyProvider
+func (ks *KeySet) PublicKey(kid string) (crypto.PublicKey, error) {
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key.Public, nil
+}
+
+// JWKS 返回全部公钥
+func (ks *KeySet) JWKS() *JWKS {
+	set := &JWKS{Keys: make([]JWK, 0, len(ks.order))}
+	for _, kid := range ks.order {
+		set.Keys = append(set.Keys, toJWK(ks.keys[kid]))
+	}
+	return set
+}
+
+// JWKS JSON Web Key Set（RFC 7517）
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK 单个公钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA 模数
+	E   string `json:"e,omitempty"`   // RSA 指数
+	Crv string `json:"crv,omitempty"` // OKP 曲线
+	X   string `json:"x,omitempty"`   // Ed25519 公钥
+}
+
+func toJWK(k *SigningKey) JWK {
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Alg}
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
+
+// publicKey 解析 JWK 中的公钥
+func (j JWK) publicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || j.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// loadKey 加载私钥（优先）或公钥
+func loadKey(kc config.JWTKeyConfig, algorithm string) (*SigningKey, error) {
+	if kc.PrivateKeyFile != "" {
+		data, err := os.ReadFile(kc.PrivateKeyFile)
+		if errors.Is(err, os.ErrNotExist) {
+			data, err = generateKeyFile(kc.PrivateKeyFile, algorithm)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return parsePrivateKey(kc.KID, data)
+	}
+	if kc.PublicKeyFile != "" {
+		data, err := os.ReadFile(kc.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return parsePublicKey(kc.KID, data)
+	}
+	return nil, errors.New("需要配置 private_key_file 或 public_key_file")
+}
+
+func parsePrivateKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("无效的 PEM 文件")
+	}
+	var key interface{}
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &SigningKey{ID: kid, Alg: AlgRS256, Private: k, Public: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{ID: kid, Alg: AlgEdDSA, Private: k, Public: k.Public()}, nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+func parsePublicKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("无效的 PEM 文件")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return &SigningKey{ID: kid, Alg: AlgRS256, Public: k}, nil
+	case ed25519.PublicKey:
+		return &SigningKey{ID: kid, Alg: AlgEdDSA, Public: k}, nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// generateKeyFile 生成私钥并以 PKCS#8 PEM 格式写入文件（权限 0600）
+func generateKeyFile(path, algorithm string) ([]byte, error) {
+	var key interface{}
+	var err error
+	if algorithm == AlgEdDSA {
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	} else {
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,238 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 按模块调整日志级别
+//
+//	包内声明 var log = logger.For("gateway.proxy")，日志带 logger 字段
+//	级别按名称逐级匹配（gateway.proxy → gateway → 默认级别），运行时通过 SetLevel 调整
+
+// levelTable 当前生效的级别（整体替换，读取无锁）
+type levelTable struct {
+	def     zapcore.Level
+	modules map[string]zapcore.Level
+	lowest  zapcore.Level // 所有级别中最低的，供 Enabled 快速判断
+}
+
+var (
+	levelsMu sync.Mutex // 串行化修改
+	levels   atomic.Pointer[levelTable]
+
+	modulesMu sync.Mutex
+	modules   = map[string]struct{}{} // 代码中声明过的模块
+)
+
+func init() {
+	levels.Store(&levelTable{def: zapcore.InfoLevel, modules: map[string]zapcore.Level{}, lowest: zapcore.InfoLevel})
+}
+
+// levelFor 模块的生效级别
+func (t *levelTable) levelFor(name string) zapcore.Level {
+	for name != "" {
+		if l, ok := t.modules[name]; ok {
+			return l
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return t.def
+}
+
+// store 计算最低级别后替换（调用方持有 levelsMu）
+func store(def zapcore.Level, overrides map[string]zapcore.Level) {
+	lowest := def
+	for _, l := range overrides {
+		if l < lowest {
+			lowest = l
+		}
+	}
+	levels.Store(&levelTable{def: def, modules: overrides, lowest: lowest})
+}
+
+// resetLevels 按配置重置全部级别
+func resetLevels(def zapcore.Level, configured map[string]string) error {
+	overrides := make(map[string]zapcore.Level, len(configured))
+	for name, text := range configured {
+		l, err := zapcore.ParseLevel(text)
+		if err != nil {
+			return fmt.Errorf("模块 %s 的日志级别无效: %s", name, text)
+		}
+		overrides[name] = l
+	}
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	store(def, overrides)
+	return nil
+}
+
+// SetLevel 运行时调整级别：module 为空时调整默认级别；level 为空时取消模块的单独设置
+func SetLevel(module, level string) error {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	current := levels.Load()
+	def := current.def
+	overrides := make(map[string]zapcore.Level, len(current.modules)+1)
+	for name, l := range current.modules {
+		overrides[name] = l
+	}
+
+	switch {
+	case module == "" && level == "":
+		return fmt.Errorf("默认日志级别不能为空")
+	case level == "":
+		delete(overrides, module)
+	default:
+		l, err := zapcore.ParseLevel(level)
+		if err != nil {
+			return fmt.Errorf("无效的日志级别: %s", level)
+		}
+		if module == "" {
+			def = l
+		} else {
+			overrides[module] = l
+		}
+	}
+	store(def, overrides)
+	return nil
+}
+
+// LevelsInfo 当前的日志级别
+type LevelsInfo struct {
+	Default   string            `json:"default"`
+	Overrides map[string]string `json:"overrides"` // 单独设置过级别的模块
+	Modules   map[string]string `json:"modules"`   // 代码中声明的模块及其生效级别
+}
+
+// Levels 获取当前的日志级别
+func Levels() LevelsInfo {
+	t := levels.Load()
+	info := LevelsInfo{
+		Default:   t.def.String(),
+		Overrides: make(map[string]string, len(t.modules)),
+		Modules:   map[string]string{},
+	}
+	for name, l := range t.modules {
+		info.Overrides[name] = l.String()
+	}
+	for _, name := range ModuleNames() {
+		info.Modules[name] = t.levelFor(name).String()
+	}
+	return info
+}
+
+// ModuleNames 代码中声明的模块（排序）
+func ModuleNames() []string {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// levelCore 按 logger 名称（模块）过滤级别
+type levelCore struct {
+	zapcore.Core
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	return l >= levels.Load().lowest
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields)}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < levels.Load().levelFor(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// Module 模块日志（级别可单独调整）
+type Module struct {
+	name   string
+	cached atomic.Pointer[moduleLogger]
+}
+
+type moduleLogger struct {
+	base, named *zap.Logger
+}
+
+// For 声明模块日志，名称用点分隔层级（如 gateway.proxy、order.cutting）
+func For(name string) *Module {
+	modulesMu.Lock()
+	modules[name] = struct{}{}
+	modulesMu.Unlock()
+	return &Module{name: name}
+}
+
+// fallback InitLogger 之前（如加载配置时、单元测试中）模块日志输出到标准错误
+var fallback = zap.New(&levelCore{Core: zapcore.NewCore(
+	zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), zapcore.Lock(os.Stderr), zapcore.DebugLevel,
+)}, zap.AddCaller(), zap.AddCallerSkip(1))
+
+// logger 模块的 zap logger（InitLogger 重新初始化后自动切换）
+func (m *Module) logger() *zap.Logger {
+	base := Logger
+	if base == nil {
+		base = fallback
+	}
+	if cached := m.cached.Load(); cached != nil && cached.base == base {
+		return cached.named
+	}
+	named := base.Named(m.name)
+	m.cached.Store(&moduleLogger{base: base, named: named})
+	return named
+}
+
+// Ctx 带请求上下文的模块日志（tenant_code、user_id、request_id、trace_id）
+func (m *Module) Ctx(ctx context.Context, fields ...zap.Field) *zap.Logger {
+	if Logger == nil {
+		return fallback.WithOptions(zap.AddCallerSkip(-1)).Named(m.name).With(fields...)
+	}
+	return WithContext(ctx, fields...).Named(m.name)
+}
+
+// Debug 调试日志
+func (m *Module) Debug(msg string, fields ...zap.Field) {
+	m.logger().Debug(msg, fields...)
+}
+
+// Info 信息日志
+func (m *Module) Info(msg string, fields ...zap.Field) {
+	m.logger().Info(msg, fields...)
+}
+
+// Warn 警告日志
+func (m *Module) Warn(msg string, fields ...zap.Field) {
+	m.logger().Warn(msg, fields...)
+}
+
+// Error 错误日志
+func (m *Module) Error(msg string, fields ...zap.Field) {
+	m.logger().Error(msg, fields...)
+}
+
+// Fatal 致命错误日志
+func (m *Module) Fatal(msg string, fields ...zap.Field) {
+	m.logger().Fatal(msg, fields...)
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	tenantCtx "mule-cloud/core/context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observe 用内存 core 替换全局 Logger，返回记录的日志
+func observe(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := Logger
+	Logger = zap.New(&levelCore{Core: core})
+	t.Cleanup(func() {
+		Logger = prev
+		_ = resetLevels(zapcore.InfoLevel, nil)
+	})
+	return logs
+}
+
+// TestModuleLevels 模块级别按名称逐级匹配，运行时调整立即生效
+func TestModuleLevels(t *testing.T) {
+	logs := observe(t)
+	if err := resetLevels(zapcore.InfoLevel, map[string]string{"gateway": "debug"}); err != nil {
+		t.Fatal(err)
+	}
+	proxy := For("gateway.proxy")
+	order := For("order")
+
+	proxy.Debug("转发请求")
+	order.Debug("订单调试")
+	if logs.Len() != 1 || logs.All()[0].LoggerName != "gateway.proxy" {
+		t.Fatalf("gateway.proxy 应继承 gateway 的 debug 级别，order 应被过滤，got %v", logs.All())
+	}
+
+	// 子模块单独调高，父模块不受影响
+	if err := SetLevel("gateway.proxy", "warn"); err != nil {
+		t.Fatal(err)
+	}
+	proxy.Info("转发请求")
+	For("gateway.upstream").Debug("实例剔除")
+	if logs.Len() != 2 || logs.All()[1].LoggerName != "gateway.upstream" {
+		t.Fatalf("gateway.proxy 调为 warn 后 info 应被过滤，got %v", logs.All())
+	}
+
+	// 取消单独设置后回到父模块级别
+	if err := SetLevel("gateway.proxy", ""); err != nil {
+		t.Fatal(err)
+	}
+	proxy.Debug("转发请求")
+	if logs.Len() != 3 {
+		t.Fatalf("取消单独设置后应回到 gateway 的 debug 级别，got %d 条", logs.Len())
+	}
+
+	// 默认级别
+	if err := SetLevel("", "error"); err != nil {
+		t.Fatal(err)
+	}
+	order.Warn("库存不足")
+	if logs.Len() != 3 {
+		t.Fatalf("默认级别调为 error 后 warn 应被过滤")
+	}
+
+	if err := SetLevel("order", "verbose"); err == nil {
+		t.Error("无效级别应返回错误")
+	}
+	if err := SetLevel("", ""); err == nil {
+		t.Error("默认级别不能为空")
+	}
+	info := Levels()
+	if info.Default != "error" || info.Overrides["gateway"] != "debug" || info.Modules["gateway.proxy"] != "debug" {
+		t.Errorf("Levels() = %+v", info)
+	}
+}
+
+// TestContextFields 请求上下文字段自动带上，显式字段优先且不重复
+func TestContextFields(t *testing.T) {
+	logs := observe(t)
+	ctx := tenantCtx.WithTenantCode(context.Background(), "ace")
+	ctx = tenantCtx.WithUserID(ctx, "u1")
+	ctx = tenantCtx.WithRequestID(ctx, "req-1")
+
+	For("order").Ctx(ctx).Info("创建订单", zap.String("order_id", "o1"))
+	For("order").Ctx(ctx, zap.String("tenant_code", "system")).Info("跨租户查询")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	first := entries[0].ContextMap()
+	for key, want := range map[string]string{"tenant_code": "ace", "user_id": "u1", "request_id": "req-1", "order_id": "o1"} {
+		if first[key] != want {
+			t.Errorf("%s = %v, want %s", key, first[key], want)
+		}
+	}
+	if entries[0].LoggerName != "order" {
+		t.Errorf("logger = %q, want order", entries[0].LoggerName)
+	}
+
+	count := 0
+	for _, f := range entries[1].Context {
+		if f.Key == "tenant_code" {
+			count++
+			if f.String != "system" {
+				t.Errorf("显式字段应优先，tenant_code = %s", f.String)
+			}
+		}
+	}
+	if count != 1 {
+		t.Errorf("tenant_code 输出 %d 次，应为 1 次", count)
+	}
+}
@@ -1,11 +1,14 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"mule-cloud/core/config"
+	tenantCtx "mule-cloud/core/context"
 	"os"
 	"path/filepath"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -16,10 +19,12 @@ var (
 	Sugar  *zap.SugaredLogger
 )
 
-// InitLogger 初始化日志系统
-func InitLogger(cfg *config.LogConfig) error {
-	// 日志级别
-	level := getLogLevel(cfg.Level)
+// InitLogger 初始化日志系统（serviceName 写入每条日志的 service 字段）
+func InitLogger(cfg *config.LogConfig, serviceName string) error {
+	// 日志级别（默认级别和按模块的级别，由 levelCore 过滤）
+	if err := resetLevels(getLogLevel(cfg.Level), cfg.Levels); err != nil {
+		return err
+	}
 
 	// 编码器配置
 	encoderConfig := zapcore.EncoderConfig{
@@ -69,11 +74,14 @@ func InitLogger(cfg *config.LogConfig) error {
 		writeSyncer = zapcore.AddSync(os.Stdout)
 	}
 
-	// 创建core
-	core := zapcore.NewCore(encoder, writeSyncer, level)
+	// 创建core（底层不限级别，按模块过滤）
+	core := &levelCore{Core: zapcore.NewCore(encoder, writeSyncer, zapcore.DebugLevel)}
 
 	// 创建logger
 	Logger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	if serviceName != "" {
+		Logger = Logger.With(zap.String("service", serviceName))
+	}
 	Sugar = Logger.Sugar()
 
 	// 替换全局logger
@@ -195,7 +203,68 @@ func With(fields ...zap.Field) *zap.Logger {
 	return zap.NewNop()
 }
 
-// WithContext 创建带上下文的logger（可扩展）
-func WithContext(fields ...zap.Field) *zap.Logger {
-	return With(fields...)
+// WithContext 创建带请求上下文的logger（自动加上 tenant_code、user_id、request_id、trace_id、span_id，日志可与调用链关联）
+// 调用方显式传入同名字段时以显式字段为准，不重复输出
+func WithContext(ctx context.Context, fields ...zap.Field) *zap.Logger {
+	if Logger == nil {
+		return zap.NewNop()
+	}
+	var ctxFields []zap.Field
+	if tenantCode := tenantCtx.GetTenantCode(ctx); tenantCode != "" {
+		ctxFields = append(ctxFields, zap.String("tenant_code", tenantCode))
+	}
+	if userID := tenantCtx.GetUserID(ctx); userID != "" {
+		ctxFields = append(ctxFields, zap.String("user_id", userID))
+	}
+	if requestID := tenantCtx.GetRequestID(ctx); requestID != "" {
+		ctxFields = append(ctxFields, zap.String("request_id", requestID))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		ctxFields = append(ctxFields, zap.String("trace_id", sc.TraceID().String()), zap.String("span_id", sc.SpanID().String()))
+	}
+	// 返回的 logger 由调用方直接使用，去掉便捷方法的调用层级跳过，caller 才是调用方
+	return Logger.WithOptions(zap.AddCallerSkip(-1), zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &contextCore{Core: core, fields: ctxFields}
+	})).With(fields...)
+}
+
+// contextCore 写入时补上请求上下文字段（跳过调用方已有的同名字段）
+type contextCore struct {
+	zapcore.Core
+	fields []zapcore.Field
+}
+
+func (c *contextCore) With(fields []zapcore.Field) zapcore.Core {
+	return &contextCore{Core: c.Core.With(fields), fields: withoutKeys(c.fields, fields)}
+}
+
+func (c *contextCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < levels.Load().levelFor(ent.LoggerName) || !c.Core.Enabled(ent.Level) {
+		return ce
+	}
+	return ce.AddCore(ent, c)
+}
+
+func (c *contextCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	merged := make([]zapcore.Field, 0, len(fields)+len(c.fields))
+	merged = append(merged, fields...)
+	return c.Core.Write(ent, append(merged, withoutKeys(c.fields, fields)...))
+}
+
+// withoutKeys 去掉 exclude 中已有的字段
+func withoutKeys(fields, exclude []zapcore.Field) []zapcore.Field {
+	if len(exclude) == 0 {
+		return fields
+	}
+	kept := make([]zapcore.Field, 0, len(fields))
+next:
+	for _, f := range fields {
+		for _, e := range exclude {
+			if e.Key == f.Key {
+				continue next
+			}
+		}
+		kept = append(kept, f)
+	}
+	return kept
 }
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry 所有服务共用的指标注册表（包含 Go 运行时和进程指标）
+var Registry = prometheus.NewRegistry()
+
+// routeKey gin context 中的路由标签（网关按匹配到的路由前缀设置）
+const routeKey = "metrics_route"
+
+// HTTP 请求指标
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mule_http_requests_total",
+		Help: "HTTP 请求数",
+	}, []string{"method", "route", "status", "tenant"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mule_http_request_duration_seconds",
+		Help:    "HTTP 请求耗时（秒）",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"method", "route", "tenant"})
+
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mule_http_requests_in_flight",
+		Help: "正在处理的 HTTP 请求数",
+	})
+)
+
+// 网关指标
+var (
+	// UpstreamErrors 网关转发失败（reason：error / timeout / no_instance / 502 / 503 / 504）
+	UpstreamErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mule_upstream_errors_total",
+		Help: "网关转发到上游失败的次数",
+	}, []string{"service", "instance", "reason"})
+
+	// CircuitFallbacks 熔断降级次数
+	CircuitFallbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mule_circuit_breaker_fallbacks_total",
+		Help: "熔断器降级响应次数",
+	}, []string{"command"})
+)
+
+// 业务指标
+var (
+	// ReportsSubmitted 工序上报
+	ReportsSubmitted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mule_reports_submitted_total",
+		Help: "工序上报次数",
+	}, []string{"tenant"})
+
+	// ReportedPieces 工序上报件数
+	ReportedPieces = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mule_reported_pieces_total",
+		Help: "工序上报件数",
+	}, []string{"tenant"})
+
+	// BundlesCreated 制菲（裁剪批次）
+	BundlesCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mule_bundles_created_total",
+		Help: "制菲创建的扎数",
+	}, []string{"tenant"})
+
+	// OrdersCreated 新建订单（包括复制）
+	OrdersCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mule_orders_created_total",
+		Help: "新建订单数",
+	}, []string{"tenant"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, httpInFlight,
+		UpstreamErrors, CircuitFallbacks,
+		ReportsSubmitted, ReportedPieces, BundlesCreated, OrdersCreated,
+	)
+}
+
+// Handler 指标接口（各服务和网关注册为 GET /metrics）
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+}
+
+// Middleware HTTP 请求指标中间件（放在 Recovery 之前，panic 恢复后的 500 也能统计到）
+//
+// route 取 gin 的路由模板（如 /admin/order/orders/:id），未匹配的请求记为 unmatched，避免标签过多；
+// tenant 取认证后的 tenant_code，匿名请求为空。
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path == "/metrics" {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+
+		c.Next()
+
+		route := c.GetString(routeKey)
+		if route == "" {
+			route = c.FullPath()
+		}
+		if route == "" {
+			route = "unmatched"
+		}
+		tenant := c.GetString("tenant_code")
+		httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status()), tenant).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, tenant).Observe(time.Since(start).Seconds())
+	}
+}
+
+// SetRoute 设置请求的路由标签（网关的业务请求走 NoRoute，没有路由模板）
+func SetRoute(c *gin.Context, route string) {
+	c.Set(routeKey, route)
+}
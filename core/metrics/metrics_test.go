@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestMiddleware 按路由模板和租户统计，网关可覆盖路由标签，/metrics 输出指标
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/metrics", Handler())
+	r.GET("/orders/:id", func(c *gin.Context) {
+		c.Set("tenant_code", "ace")
+		c.Status(http.StatusOK)
+	})
+	r.NoRoute(func(c *gin.Context) {
+		SetRoute(c, "/admin/production")
+		c.Status(http.StatusBadGateway)
+	})
+
+	for _, path := range []string{"/orders/1", "/orders/2", "/admin/production/reports"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/orders/:id", "200", "ace")); got != 2 {
+		t.Errorf("orders requests = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/admin/production", "502", "")); got != 1 {
+		t.Errorf("gateway route requests = %v, want 1", got)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	for _, want := range []string{
+		`mule_http_request_duration_seconds_count{method="GET",route="/orders/:id",tenant="ace"} 2`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics missing %q", want)
+		}
+	}
+	if strings.Contains(body, `route="/metrics"`) {
+		t.Errorf("/metrics should not count itself")
+	}
+}
+
+// TestCircuitBreakers 熔断器状态采集
+func TestCircuitBreakers(t *testing.T) {
+	RegisterCircuitBreakers(func() map[string]bool { return map[string]bool{"order": true, "perms": false} })
+
+	expected := `
+# HELP mule_circuit_breaker_open 熔断器是否打开（1 打开，0 关闭）
+# TYPE mule_circuit_breaker_open gauge
+mule_circuit_breaker_open{command="order"} 1
+mule_circuit_breaker_open{command="perms"} 0
+`
+	if err := testutil.GatherAndCompare(Registry, strings.NewReader(expected), "mule_circuit_breaker_open"); err != nil {
+		t.Error(err)
+	}
+}
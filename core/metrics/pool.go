@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// MongoDB 连接池指标
+var (
+	mongoOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mule_mongo_pool_open_connections",
+		Help: "MongoDB 连接池已建立的连接数",
+	}, []string{"address"})
+
+	mongoInUse = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mule_mongo_pool_in_use_connections",
+		Help: "MongoDB 连接池正在使用的连接数",
+	}, []string{"address"})
+
+	mongoCheckoutFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mule_mongo_pool_checkout_failures_total",
+		Help: "MongoDB 获取连接失败次数",
+	}, []string{"address", "reason"})
+)
+
+func init() {
+	Registry.MustRegister(mongoOpen, mongoInUse, mongoCheckoutFailures)
+}
+
+// MongoPoolMonitor MongoDB 连接池监控（InitMongoDB 时设置到客户端）
+func MongoPoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				mongoOpen.WithLabelValues(e.Address).Inc()
+			case event.ConnectionClosed:
+				mongoOpen.WithLabelValues(e.Address).Dec()
+			case event.ConnectionCheckedOut:
+				mongoInUse.WithLabelValues(e.Address).Inc()
+			case event.ConnectionCheckedIn:
+				mongoInUse.WithLabelValues(e.Address).Dec()
+			case event.ConnectionCheckOutFailed:
+				mongoCheckoutFailures.WithLabelValues(e.Address, e.Reason).Inc()
+			}
+		},
+	}
+}
+
+// redisPoolCollector 采集时读取 Redis 客户端的连接池统计
+type redisPoolCollector struct {
+	stats func() *redis.PoolStats
+
+	hits, misses, timeouts, total, idle, stale *prometheus.Desc
+}
+
+// RegisterRedisPool 注册 Redis 连接池指标（InitRedis 时调用，重复调用忽略）
+func RegisterRedisPool(client *redis.Client) {
+	c := &redisPoolCollector{
+		stats:    client.PoolStats,
+		hits:     prometheus.NewDesc("mule_redis_pool_hits_total", "Redis 连接池命中空闲连接次数", nil, nil),
+		misses:   prometheus.NewDesc("mule_redis_pool_misses_total", "Redis 连接池未命中（新建连接）次数", nil, nil),
+		timeouts: prometheus.NewDesc("mule_redis_pool_timeouts_total", "Redis 获取连接超时次数", nil, nil),
+		total:    prometheus.NewDesc("mule_redis_pool_connections", "Redis 连接池连接总数", nil, nil),
+		idle:     prometheus.NewDesc("mule_redis_pool_idle_connections", "Redis 连接池空闲连接数", nil, nil),
+		stale:    prometheus.NewDesc("mule_redis_pool_stale_connections_total", "Redis 连接池移除的失效连接数", nil, nil),
+	}
+	if err := Registry.Register(c); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if !errors.As(err, &already) {
+			panic(err)
+		}
+	}
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.total
+	ch <- c.idle
+	ch <- c.stale
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(s.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.stale, prometheus.CounterValue, float64(s.StaleConns))
+}
+
+// circuitCollector 采集时读取熔断器状态
+type circuitCollector struct {
+	states func() map[string]bool
+	open   *prometheus.Desc
+}
+
+// RegisterCircuitBreakers 注册熔断器状态指标（1 为打开），states 返回各命令是否打开
+func RegisterCircuitBreakers(states func() map[string]bool) {
+	c := &circuitCollector{
+		states: states,
+		open:   prometheus.NewDesc("mule_circuit_breaker_open", "熔断器是否打开（1 打开，0 关闭）", []string{"command"}, nil),
+	}
+	if err := Registry.Register(c); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if !errors.As(err, &already) {
+			panic(err)
+		}
+	}
+}
+
+func (c *circuitCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.open
+}
+
+func (c *circuitCollector) Collect(ch chan<- prometheus.Metric) {
+	for command, open := range c.states() {
+		value := 0.0
+		if open {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, value, command)
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"errors"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/logger"
+	"mule-cloud/core/quota"
+	"mule-cloud/core/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequireFeature 租户套餐未开通该功能时返回 403（放在认证和租户上下文中间件之后）
+func RequireFeature(feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantCode := tenantCtx.GetTenantCode(c.Request.Context())
+		err := quota.CheckFeature(c.Request.Context(), tenantCode, feature)
+		switch {
+		case err == nil:
+			c.Next()
+		case errors.Is(err, quota.ErrFeatureDisabled):
+			response.ErrorWithCode(c, response.CodeForbidden, err.Error())
+			c.Abort()
+		default:
+			// 查询套餐失败时放行，避免套餐数据异常影响业务
+			logger.WithContext(c.Request.Context()).Warn("检查套餐功能失败", zap.String("tenant_code", tenantCode), zap.String("feature", feature), zap.Error(err))
+			c.Next()
+		}
+	}
+}
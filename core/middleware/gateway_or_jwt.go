@@ -2,11 +2,16 @@ package middleware
 
 import (
 	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/gatewayauth"
 	"mule-cloud/core/jwt"
+	"mule-cloud/core/logger"
 	"mule-cloud/core/response"
+	"mule-cloud/core/session"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // GatewayOrJWTAuth 支持网关转发和直接JWT验证的认证中间件
@@ -15,14 +20,17 @@ import (
 // 1. 通过网关访问：使用网关转发的 X-User-ID, X-Username, X-Tenant-ID, X-Roles headers
 // 2. 直接访问服务：验证 Authorization header 中的 JWT token
 //
+// 配置 gateway_auth.secret 后校验网关签名，开启 require_signed 后拒绝未签名的身份头。
+//
 // 使用示例：
 //
 //	protected := r.Group("/api")
 //	middleware.ApplyGatewayOrJWTMiddlewares(protected, jwtManager)
 func GatewayOrJWTAuth(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var userID, username, tenantID, tenantCode string
+		var userID, username, tenantID, tenantCode, sessionID, jti, apiKeyID, impersonationID string
 		var roles []string
+		var mfa, readOnly bool
 
 		// 优先使用网关传递的用户信息headers
 		xUserID := c.GetHeader("X-User-ID")
@@ -32,11 +40,27 @@ func GatewayOrJWTAuth(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 		xRoles := c.GetHeader("X-Roles")
 
 		if xUserID != "" || xUsername != "" {
-			// 场景1: 使用网关传递的信息（网关已验证过JWT）
+			// 场景1: 使用网关传递的信息（网关已验证过JWT），先校验网关签名防止伪造身份头
+			if err := gatewayauth.Verify(c.Request.Header, c.Request.Method, c.Request.URL.Path, time.Now()); err != nil {
+				logger.WithContext(c.Request.Context()).Warn("拒绝未通过网关签名校验的身份头",
+					zap.String("path", c.Request.URL.Path),
+					zap.String("ip", c.ClientIP()),
+					zap.String("user_id", xUserID),
+					zap.Error(err))
+				response.ErrorWithCode(c, 401, "身份校验失败: "+err.Error())
+				c.Abort()
+				return
+			}
 			userID = xUserID
 			username = xUsername
 			tenantID = xTenantID
 			tenantCode = xTenantCode // ✅ 新增
+			sessionID = c.GetHeader("X-Session-ID")
+			jti = c.GetHeader("X-Token-ID")
+			mfa = c.GetHeader("X-MFA") == "1"
+			apiKeyID = c.GetHeader("X-API-Key-ID") // 使用API密钥调用时的密钥ID
+			impersonationID = c.GetHeader("X-Impersonation-ID")
+			readOnly = c.GetHeader("X-Read-Only") == "1"
 			if xRoles != "" {
 				roles = strings.Split(xRoles, ",")
 			}
@@ -62,12 +86,29 @@ func GatewayOrJWTAuth(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 				c.Abort()
 				return
 			}
+			if session.IsDenied(c.Request.Context(), claims.ID) {
+				response.ErrorWithCode(c, 401, "token已失效，请重新登录")
+				c.Abort()
+				return
+			}
 
 			userID = claims.UserID
 			username = claims.Username
 			tenantID = claims.TenantID
 			tenantCode = claims.TenantCode // ✅ 新增
 			roles = claims.Roles
+			sessionID = claims.SessionID
+			jti = claims.ID
+			mfa = claims.MFA
+			impersonationID = claims.ImpersonationID
+			readOnly = claims.ReadOnly
+		}
+
+		// 只读代管会话只允许读操作
+		if readOnly && !jwt.ReadOnlyAllowed(c.Request.Method) {
+			response.ErrorWithCode(c, 403, "只读代管会话不允许修改数据")
+			c.Abort()
+			return
 		}
 
 		// 将用户信息存入Gin Context（向下兼容）
@@ -76,6 +117,12 @@ func GatewayOrJWTAuth(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 		c.Set("tenant_id", tenantID)     // 保留 ID（兼容）
 		c.Set("tenant_code", tenantCode) // ✅ 新增：租户代码
 		c.Set("roles", roles)
+		c.Set("session_id", sessionID)
+		c.Set("jti", jti)
+		c.Set("mfa", mfa)
+		c.Set("api_key_id", apiKeyID)
+		c.Set("impersonation_id", impersonationID) // 超管代管会话ID（操作日志同时写入系统库和租户库）
+		c.Set("read_only", readOnly)
 
 		// ✅ 将租户信息存入标准Context（使用 TenantCode 进行数据库连接）
 		ctx := c.Request.Context()
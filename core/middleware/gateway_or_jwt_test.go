@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"mule-cloud/core/config"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/gatewayauth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newGatewayAuthRouter 受保护的路由，返回认证后得到的用户和角色
+func newGatewayAuthRouter(t *testing.T, cfg *config.GatewayAuthConfig) *gin.Engine {
+	t.Helper()
+	if err := gatewayauth.Init(cfg); err != nil {
+		t.Fatalf("gatewayauth.Init() error = %v", err)
+	}
+	t.Cleanup(func() { gatewayauth.Init(&config.GatewayAuthConfig{}) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/perms/admins", GatewayOrJWTAuth(nil), func(c *gin.Context) {
+		roles := tenantCtx.GetRoles(c.Request.Context())
+		c.String(http.StatusOK, tenantCtx.GetUserID(c.Request.Context())+"|"+tenantCtx.GetTenantCode(c.Request.Context())+"|"+roles[0])
+	})
+	return r
+}
+
+// forgedRequest 直连服务端口、自带超管身份头的请求
+func forgedRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/perms/admins", nil)
+	req.Header.Set("X-User-ID", "attacker")
+	req.Header.Set("X-Tenant-Code", "acme")
+	req.Header.Set("X-Roles", "super")
+	return req
+}
+
+// TestGatewayOrJWTAuthRejectsForgedHeaders 开启 require_signed 后伪造、篡改、过期的身份头都被拒绝
+func TestGatewayOrJWTAuthRejectsForgedHeaders(t *testing.T) {
+	r := newGatewayAuthRouter(t, &config.GatewayAuthConfig{Secret: "gateway-secret", RequireSigned: true})
+
+	cases := map[string]func(req *http.Request){
+		"unsigned": func(req *http.Request) {},
+		"forged signature": func(req *http.Request) {
+			req.Header.Set(gatewayauth.HeaderTimestamp, strconv.FormatInt(time.Now().Unix(), 10))
+			req.Header.Set(gatewayauth.HeaderSignature, "v1=forged")
+		},
+		"roles changed after signing": func(req *http.Request) {
+			req.Header.Set("X-Roles", "r1")
+			gatewayauth.Sign(req.Header, req.Method, req.URL.Path)
+			req.Header.Set("X-Roles", "super")
+		},
+		"signed for another path": func(req *http.Request) {
+			gatewayauth.Sign(req.Header, req.Method, "/perms/roles")
+		},
+		"expired": func(req *http.Request) {
+			gatewayauth.Sign(req.Header, req.Method, req.URL.Path)
+			req.Header.Set(gatewayauth.HeaderTimestamp, "1700000000")
+		},
+	}
+	for name, forge := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := forgedRequest()
+			forge(req)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401 (body %s)", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestGatewayOrJWTAuthAcceptsSignedHeaders 网关签名的身份头被信任
+func TestGatewayOrJWTAuthAcceptsSignedHeaders(t *testing.T) {
+	r := newGatewayAuthRouter(t, &config.GatewayAuthConfig{Secret: "gateway-secret", RequireSigned: true})
+
+	req := forgedRequest()
+	req.Header.Set("X-Roles", "r1,r2")
+	gatewayauth.Sign(req.Header, req.Method, req.URL.Path)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "attacker|acme|r1" {
+		t.Errorf("got %d %q, want 200 attacker|acme|r1", w.Code, w.Body.String())
+	}
+}
+
+// TestGatewayOrJWTAuthCompatMode 未开启 require_signed 时兼容未签名的旧网关，但带签名的请求仍须校验通过
+func TestGatewayOrJWTAuthCompatMode(t *testing.T) {
+	r := newGatewayAuthRouter(t, &config.GatewayAuthConfig{Secret: "gateway-secret"})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, forgedRequest())
+	if w.Code != http.StatusOK {
+		t.Errorf("unsigned status = %d, want 200 in compat mode", w.Code)
+	}
+
+	req := forgedRequest()
+	gatewayauth.Sign(req.Header, req.Method, req.URL.Path)
+	req.Header.Set("X-Tenant-Code", "other")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("tampered status = %d, want 401", w.Code)
+	}
+}
+
+// TestImpersonationHeaders 只读代管会话拒绝写操作；超管不能再用 X-Tenant-Context 直接切换租户
+func TestImpersonationHeaders(t *testing.T) {
+	if err := gatewayauth.Init(&config.GatewayAuthConfig{Secret: "gateway-secret", RequireSigned: true}); err != nil {
+		t.Fatalf("gatewayauth.Init() error = %v", err)
+	}
+	t.Cleanup(func() { gatewayauth.Init(&config.GatewayAuthConfig{}) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	group := r.Group("/order")
+	ApplyGatewayOrJWTMiddlewares(group, nil)
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, tenantCtx.GetTenantCode(c.Request.Context())+"|"+c.GetString("impersonation_id"))
+	}
+	group.GET("/orders", handler)
+	group.POST("/orders", handler)
+
+	send := func(method, tenantCode string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/order/orders", nil)
+		req.Header.Set("X-User-ID", "admin")
+		req.Header.Set("X-Tenant-Code", tenantCode)
+		req.Header.Set("X-Roles", "super")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		gatewayauth.Sign(req.Header, req.Method, req.URL.Path)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	readOnly := map[string]string{"X-Impersonation-ID": "imp1", "X-Read-Only": "1", "X-Tenant-Context": "acme"}
+	if w := send(http.MethodGet, "acme", readOnly); w.Code != http.StatusOK || w.Body.String() != "acme|imp1" {
+		t.Errorf("read-only GET = %d %q, want 200 acme|imp1", w.Code, w.Body.String())
+	}
+	if w := send(http.MethodPost, "acme", readOnly); w.Code != http.StatusForbidden {
+		t.Errorf("read-only POST status = %d, want 403", w.Code)
+	}
+	if w := send(http.MethodPost, "acme", map[string]string{"X-Impersonation-ID": "imp2"}); w.Code != http.StatusOK {
+		t.Errorf("read-write POST status = %d, want 200", w.Code)
+	}
+	if w := send(http.MethodGet, "system", map[string]string{"X-MFA": "1", "X-Tenant-Context": "acme"}); w.Code != http.StatusForbidden {
+		t.Errorf("X-Tenant-Context switch status = %d, want 403", w.Code)
+	}
+}
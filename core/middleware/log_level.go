@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"mule-cloud/core/jwt"
+	"mule-cloud/core/logger"
+	"mule-cloud/core/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// LogLevelRequest 调整日志级别
+type LogLevelRequest struct {
+	Module string `json:"module"` // 模块名（如 gateway.proxy），为空时调整默认级别
+	Level  string `json:"level"`  // debug/info/warn/error，为空时取消模块的单独设置
+}
+
+// ApplyLogLevels 注册日志级别管理接口（GET/PUT /log-levels，仅超级管理员）
+func ApplyLogLevels(r gin.IRouter, jwtManager *jwt.JWTManager) {
+	group := r.Group("/log-levels")
+	Apply(group, jwtManager, MiddlewareConfig{SupportGateway: true, RequireRole: []string{"super"}})
+	group.GET("", LogLevelsHandler())
+	group.PUT("", SetLogLevelHandler())
+}
+
+// LogLevelsHandler 查询当前日志级别
+func LogLevelsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.Success(c, logger.Levels())
+	}
+}
+
+// SetLogLevelHandler 运行时调整日志级别（只影响当前实例，重启后恢复配置文件的级别）
+func SetLogLevelHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req LogLevelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, "参数错误: "+err.Error())
+			return
+		}
+		if err := logger.SetLevel(req.Module, req.Level); err != nil {
+			response.Error(c, err.Error())
+			return
+		}
+
+		logger.WithContext(c.Request.Context()).Info("日志级别已调整", zap.String("module", req.Module), zap.String("level", req.Level))
+		response.SuccessWithMsg(c, "调整成功", logger.Levels())
+	}
+}
@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"io"
 	"strings"
@@ -10,6 +9,7 @@ import (
 
 	tenantCtx "mule-cloud/core/context"
 	"mule-cloud/core/logger"
+	"mule-cloud/core/tracing"
 	"mule-cloud/internal/models"
 	"mule-cloud/internal/repository"
 
@@ -19,28 +19,32 @@ import (
 
 // OperationLogMiddleware 操作日志中间件
 // 记录用户的 CRUD 操作（POST、PUT、DELETE、PATCH）
-// 不记录 GET、HEAD、OPTIONS 等读操作
+// 不记录 GET、HEAD、OPTIONS 等读操作；超管代管租户期间的请求（包括读操作）全部记录，
+// 同时写入系统库和被代管租户的库
 func OperationLogMiddleware() gin.HandlerFunc {
 	repo := repository.NewOperationLogRepository()
 
 	return func(c *gin.Context) {
-		// 1. 跳过读操作（GET、HEAD、OPTIONS）
+		// 1. 跳过健康检查、静态资源等路径
 		method := c.Request.Method
-		if method == "GET" || method == "HEAD" || method == "OPTIONS" {
-			c.Next()
-			return
-		}
-
-		// 2. 跳过健康检查、静态资源等路径
 		path := c.Request.URL.Path
 		if shouldSkipPath(path) {
 			c.Next()
 			return
 		}
 
-		// 3. 记录开始时间
+		// 2. 记录开始时间
 		startTime := time.Now()
 
+		// 3. 读操作（GET、HEAD、OPTIONS）只在代管期间记录（认证后才知道是否代管）
+		if method == "GET" || method == "HEAD" || method == "OPTIONS" {
+			c.Next()
+			if c.GetString("impersonation_id") != "" {
+				saveOperationLog(repo, c, startTime, "", c.Writer.Status())
+			}
+			return
+		}
+
 		// 4. 读取请求体（需要缓存，因为 Body 只能读一次）
 		var requestBody string
 		if c.Request.Body != nil {
@@ -62,66 +66,75 @@ func OperationLogMiddleware() gin.HandlerFunc {
 		// 6. 执行后续处理器
 		c.Next()
 
-		// 7. 记录操作日志（异步，不阻塞请求）
-		// 注意：需要在 goroutine 外部提取租户信息，避免 Context 被取消
-		tenantCode, _ := c.Get("tenant_code")
-
-		go func() {
-			duration := time.Since(startTime).Milliseconds()
-
-			// 获取用户信息
-			userID, _ := c.Get("user_id")
-			username, _ := c.Get("username")
-
-			// 解析资源和操作类型
-			resource, action := parseResourceAndAction(method, path)
+		// 7. 记录操作日志
+		saveOperationLog(repo, c, startTime, requestBody, writer.statusCode)
+	}
+}
 
-			// 获取错误信息（如果有）
-			var errorMsg string
-			if len(c.Errors) > 0 {
-				errorMsg = c.Errors.String()
-			}
+// saveOperationLog 异步保存操作日志，不阻塞请求
+// 租户日志存到租户库，系统管理员日志存到系统库；代管期间的日志两边各存一份
+func saveOperationLog(repo *repository.OperationLogRepository, c *gin.Context, startTime time.Time, requestBody string, statusCode int) {
+	// 注意：需要在 goroutine 外部提取请求信息，避免 Context 被取消或复用
+	method := c.Request.Method
+	path := c.Request.URL.Path
+	tenantCode := c.GetString("tenant_code")
+	impersonationID := c.GetString("impersonation_id")
+
+	// 解析资源和操作类型
+	resource, action := parseResourceAndAction(method, path)
+
+	// 获取错误信息（如果有）
+	var errorMsg string
+	if len(c.Errors) > 0 {
+		errorMsg = c.Errors.String()
+	}
 
-			// 脱敏请求体（移除密码等敏感信息）
-			sanitizedBody := sanitizeRequestBody(requestBody)
-
-			// 创建操作日志
-			log := &models.OperationLog{
-				UserID:       toString(userID),
-				Username:     toString(username),
-				Method:       method,
-				Path:         path,
-				Resource:     resource,
-				Action:       action,
-				RequestBody:  sanitizedBody,
-				ResponseCode: writer.statusCode,
-				Duration:     duration,
-				IP:           c.ClientIP(),
-				UserAgent:    c.Request.UserAgent(),
-				Error:        errorMsg,
-				CreatedAt:    startTime,
-			}
+	// 创建操作日志（请求体脱敏，移除密码等敏感信息）
+	log := &models.OperationLog{
+		UserID:          c.GetString("user_id"),
+		Username:        c.GetString("username"),
+		APIKeyID:        c.GetString("api_key_id"),
+		ImpersonationID: impersonationID,
+		RequestID:       c.GetString("request_id"),
+		Method:          method,
+		Path:            path,
+		Resource:        resource,
+		Action:          action,
+		RequestBody:     sanitizeRequestBody(requestBody),
+		ResponseCode:    statusCode,
+		Duration:        time.Since(startTime).Milliseconds(),
+		IP:              c.ClientIP(),
+		UserAgent:       c.Request.UserAgent(),
+		Error:           errorMsg,
+		CreatedAt:       startTime,
+	}
 
-			// ✅ 使用独立的 Context（避免主请求 Context 被取消）
-			ctx := context.Background()
-			ctx = tenantCtx.WithTenantCode(ctx, toString(tenantCode))
+	tenantCodes := []string{tenantCode}
+	if impersonationID != "" && !sameTenantCode(tenantCode, "") {
+		tenantCodes = append(tenantCodes, "") // 代管日志同时存到系统库
+	}
 
-			// 保存到数据库（租户日志存到租户库，系统管理员日志存到系统库）
-			if err := repo.Create(ctx, log); err != nil {
-				logger.Error("保存操作日志失败",
-					zap.String("user_id", log.UserID),
-					zap.String("path", log.Path),
+	detached := tracing.Detach(c.Request.Context())
+	go func() {
+		for _, code := range tenantCodes {
+			// ✅ 使用独立的 Context（避免主请求 Context 被取消，保留调用链）
+			ctx := tenantCtx.WithTenantCode(detached, code)
+			entry := *log
+			if err := repo.Create(ctx, &entry); err != nil {
+				logger.WithContext(ctx).Error("保存操作日志失败",
+					zap.String("user_id", entry.UserID),
+					zap.String("path", entry.Path),
 					zap.Error(err))
-			} else {
-				logger.Debug("操作日志已记录",
-					zap.String("user_id", log.UserID),
-					zap.String("resource", log.Resource),
-					zap.String("action", log.Action),
-					zap.Int("status", log.ResponseCode),
-					zap.Int64("duration_ms", log.Duration))
+				continue
 			}
-		}()
-	}
+			logger.WithContext(ctx).Debug("操作日志已记录",
+				zap.String("user_id", entry.UserID),
+				zap.String("resource", entry.Resource),
+				zap.String("action", entry.Action),
+				zap.Int("status", entry.ResponseCode),
+				zap.Int64("duration_ms", entry.Duration))
+		}
+	}()
 }
 
 // shouldSkipPath 判断是否跳过记录
@@ -158,6 +171,8 @@ func parseResourceAndAction(method, path string) (resource, action string) {
 
 	// 根据 HTTP 方法确定操作类型
 	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		action = "read"
 	case "POST":
 		action = "create"
 	case "PUT", "PATCH":
@@ -187,7 +202,7 @@ func sanitizeRequestBody(body string) string {
 	var data map[string]interface{}
 	if err := json.Unmarshal([]byte(body), &data); err == nil {
 		// 移除敏感字段
-		sensitiveFields := []string{"password", "passwd", "token", "secret", "api_key", "apiKey"}
+		sensitiveFields := []string{"password", "passwd", "old_password", "new_password", "token", "refresh_token", "secret", "client_secret", "api_key", "apiKey", "captcha_code"}
 		for _, field := range sensitiveFields {
 			if _, exists := data[field]; exists {
 				data[field] = "***"
@@ -1,67 +1,61 @@
 package middleware
 
 import (
-	"log"
 	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/logger"
+	"mule-cloud/core/response"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
-	// TenantContextMiddleware 租户上下文切换中间件
-// 允许系统管理员通过 X-Tenant-Context header 切换到指定租户的上下文
+// TenantContextMiddleware 租户上下文中间件
+// 系统管理员不能再通过 X-Tenant-Context header 直接切换租户数据库，
+// 需要先申请代管会话（POST /auth/impersonations），用限时的代管令牌访问租户数据，代管期间的请求都记录操作日志
 func TenantContextMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx := c.Request.Context()
-		currentTenantCode := tenantCtx.GetTenantCode(ctx)
+		contextTenantCode := c.GetHeader("X-Tenant-Context")
+		currentTenantCode := tenantCtx.GetTenantCode(c.Request.Context())
 
-		log.Printf("[租户上下文] 当前 tenantCode: '%s'", currentTenantCode)
-
-		// ✅ 只有系统管理员（tenantCode为空或"system"）可以切换租户上下文
-		if currentTenantCode == "" || currentTenantCode == "system" {
-			// ✅ 检查是否请求切换到特定租户（使用 tenant_code）
-			contextTenantCode := c.GetHeader("X-Tenant-Context")
-			log.Printf("[租户上下文] X-Tenant-Context header: '%s'", contextTenantCode)
-
-			if contextTenantCode != "" {
-				// 验证是否为系统管理员
-				rolesValue, exists := c.Get("roles")
-				log.Printf("[租户上下文] roles exist: %v, roles: %v", exists, rolesValue)
-
-				if exists {
-					if roles, ok := rolesValue.([]string); ok {
-						// 检查是否有 super 角色
-						isSuperAdmin := false
-						for _, role := range roles {
-							if role == "super" {
-								isSuperAdmin = true
-								break
-							}
-						}
+		// 没有切换请求，或代管令牌已经属于该租户
+		if contextTenantCode == "" || sameTenantCode(contextTenantCode, currentTenantCode) {
+			c.Next()
+			return
+		}
 
-						log.Printf("[租户上下文] isSuperAdmin: %v", isSuperAdmin)
+		// 非超管的 X-Tenant-Context 一律忽略（保持原有行为）
+		roles, _ := c.Get("roles")
+		if r, ok := roles.([]string); !ok || !hasRole(r, "super") {
+			c.Next()
+			return
+		}
 
-						if isSuperAdmin {
-							// ✅ 允许切换到指定租户上下文（使用 tenant_code）
-							ctx = tenantCtx.WithTenantCode(ctx, contextTenantCode)
-							c.Request = c.Request.WithContext(ctx)
+		logger.WithContext(c.Request.Context()).Warn("拒绝通过 X-Tenant-Context 直接切换租户",
+			zap.String("user_id", c.GetString("user_id")),
+			zap.String("tenant_code", currentTenantCode),
+			zap.String("context_tenant", contextTenantCode),
+			zap.String("path", c.Request.URL.Path))
+		response.ErrorWithCode(c, response.CodeForbidden, "访问租户数据请先申请代管会话（POST /auth/impersonations）")
+		c.Abort()
+	}
+}
 
-							log.Printf("[租户上下文切换] ✅ 系统管理员切换到租户: %s", contextTenantCode)
+// sameTenantCode 系统库的租户代码可能为空或 system
+func sameTenantCode(a, b string) bool {
+	if a == "" {
+		a = "system"
+	}
+	if b == "" {
+		b = "system"
+	}
+	return a == b
+}
 
-							// ✅ 验证切换后的 tenantCode
-							newTenantCode := tenantCtx.GetTenantCode(c.Request.Context())
-							log.Printf("[租户上下文切换] ✅ 切换后验证 tenantCode: '%s'", newTenantCode)
-						} else {
-							log.Printf("[租户上下文切换] ❌ 非系统管理员尝试切换租户，已拒绝")
-						}
-					}
-				}
-			} else {
-				log.Printf("[租户上下文] 没有 X-Tenant-Context header，不切换")
-			}
-		} else {
-			log.Printf("[租户上下文] 当前不是系统管理员（tenantCode=%s），不允许切换", currentTenantCode)
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
 		}
-
-		c.Next()
 	}
+	return false
 }
@@ -0,0 +1,85 @@
+package oidc
+
+import "strings"
+
+// Claims ID Token / UserInfo 声明
+type Claims map[string]interface{}
+
+// lookup 读取声明；名称本身不存在时按 . 逐级访问嵌套声明（如 realm_access.roles）
+func (c Claims) lookup(name string) interface{} {
+	if v, ok := c[name]; ok {
+		return v
+	}
+	var cur interface{} = map[string]interface{}(c)
+	for _, part := range strings.Split(name, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+// String 读取字符串声明
+func (c Claims) String(name string) string {
+	if name == "" {
+		return ""
+	}
+	s, _ := c.lookup(name).(string)
+	return s
+}
+
+// Strings 读取字符串数组声明（单个字符串按一个元素处理）
+func (c Claims) Strings(name string) []string {
+	if name == "" {
+		return nil
+	}
+	switch v := c.lookup(name).(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Bool 读取布尔声明（兼容 "true" 字符串）
+func (c Claims) Bool(name string) bool {
+	switch v := c.lookup(name).(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// Merge 合并 UserInfo 声明（sub 不一致时忽略，已有的声明不覆盖）
+func (c Claims) Merge(other Claims) {
+	if other == nil || other.String("sub") != c.String("sub") {
+		return
+	}
+	for k, v := range other {
+		if _, exists := c[k]; !exists {
+			c[k] = v
+		}
+	}
+}
+
+// MFA 身份提供方是否完成了多因素认证（按 amr 声明判断，RFC 8176）
+func (c Claims) MFA() bool {
+	for _, method := range c.Strings("amr") {
+		switch method {
+		case "mfa", "otp", "hwk":
+			return true
+		}
+	}
+	return false
+}
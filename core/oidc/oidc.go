@@ -0,0 +1,289 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwtPkg "mule-cloud/core/jwt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// discoveryTTL 身份提供方元数据缓存时间
+const discoveryTTL = time.Hour
+
+var (
+	ErrIssuerMismatch = errors.New("身份提供方返回的 issuer 与配置不一致")
+	ErrNonceMismatch  = errors.New("ID Token 的 nonce 不匹配")
+	ErrNoIDToken      = errors.New("身份提供方未返回 ID Token")
+)
+
+// Config 身份提供方客户端配置（授权码模式 + PKCE）
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string // 为空时使用 openid profile email
+}
+
+// Discovery OpenID Provider 元数据（/.well-known/openid-configuration）
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Token 令牌端点响应
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Client 某个身份提供方的 OIDC 客户端
+type Client struct {
+	cfg       Config
+	discovery *Discovery
+	keys      *jwtPkg.RemoteKeySet
+}
+
+type provider struct {
+	discovery *Discovery
+	keys      *jwtPkg.RemoteKeySet
+	fetchedAt time.Time
+}
+
+var (
+	httpClient = &http.Client{Timeout: 10 * time.Second}
+
+	providerMu sync.Mutex
+	providers  = make(map[string]*provider) // issuer -> 元数据和公钥（多个租户使用同一身份提供方时共享）
+)
+
+// NewClient 创建客户端（元数据按 issuer 缓存一小时）
+func NewClient(ctx context.Context, cfg Config) (*Client, error) {
+	if cfg.Issuer == "" || cfg.ClientID == "" {
+		return nil, errors.New("OIDC 配置缺少 issuer 或 client_id")
+	}
+	p, err := discover(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cfg: cfg, discovery: p.discovery, keys: p.keys}, nil
+}
+
+// discover 获取身份提供方元数据
+func discover(ctx context.Context, issuer string) (*provider, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	providerMu.Lock()
+	cached, ok := providers[issuer]
+	providerMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < discoveryTTL {
+		return cached, nil
+	}
+
+	var d Discovery
+	if err := getJSON(ctx, issuer+"/.well-known/openid-configuration", "", &d); err != nil {
+		if ok {
+			return cached, nil // 刷新失败时继续使用旧元数据
+		}
+		return nil, fmt.Errorf("获取身份提供方元数据失败: %w", err)
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != issuer {
+		return nil, ErrIssuerMismatch
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("身份提供方元数据不完整")
+	}
+
+	p := &provider{discovery: &d, fetchedAt: time.Now()}
+	if ok && cached.discovery.JWKSURI == d.JWKSURI {
+		p.keys = cached.keys
+	} else {
+		p.keys = jwtPkg.NewRemoteKeySet(d.JWKSURI, 0)
+	}
+
+	providerMu.Lock()
+	providers[issuer] = p
+	providerMu.Unlock()
+	return p, nil
+}
+
+// AuthCodeURL 生成跳转到身份提供方的授权地址
+func (c *Client) AuthCodeURL(state, nonce, codeChallenge string) string {
+	scopes := c.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	} else if !contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", c.cfg.ClientID)
+	params.Set("redirect_uri", c.cfg.RedirectURL)
+	params.Set("scope", strings.Join(scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(c.discovery.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return c.discovery.AuthorizationEndpoint + sep + params.Encode()
+}
+
+// Exchange 用授权码换取令牌（client_secret_basic）
+func (c *Client) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.cfg.RedirectURL)
+	form.Set("client_id", c.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求令牌端点失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return nil, fmt.Errorf("授权码换取令牌失败: HTTP %d %s %s", resp.StatusCode, e.Error, e.Description)
+	}
+
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("解析令牌响应失败: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, ErrNoIDToken
+	}
+	return &token, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、issuer、audience、有效期和 nonce
+func (c *Client) VerifyIDToken(raw, nonce string) (Claims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.keys.PublicKey(kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(c.discovery.Issuer),
+		jwt.WithAudience(c.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ID Token 校验失败: %w", err)
+	}
+
+	// 多个 audience 时 azp 必须是本客户端
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != c.cfg.ClientID {
+			return nil, errors.New("ID Token 的 azp 不匹配")
+		}
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, ErrNonceMismatch
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("ID Token 缺少 sub")
+	}
+	return Claims(claims), nil
+}
+
+// UserInfo 获取用户信息端点的声明（身份提供方未提供该端点时返回 nil）
+func (c *Client) UserInfo(ctx context.Context, accessToken string) (Claims, error) {
+	if c.discovery.UserinfoEndpoint == "" || accessToken == "" {
+		return nil, nil
+	}
+	claims := Claims{}
+	if err := getJSON(ctx, c.discovery.UserinfoEndpoint, accessToken, &claims); err != nil {
+		return nil, fmt.Errorf("获取用户信息失败: %w", err)
+	}
+	return claims, nil
+}
+
+// NewPKCE 生成 PKCE 的 code_verifier 和 S256 code_challenge
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = RandomToken()
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// RandomToken 生成随机串（用于 state、nonce）
+func RandomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func getJSON(ctx context.Context, endpoint, bearer string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
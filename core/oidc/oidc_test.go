@@ -0,0 +1,273 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockProvider 本地模拟的 OIDC 身份提供方（授权码 + PKCE）
+type mockProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	clientID     string
+	clientSecret string
+
+	// 授权码 -> 授权请求参数
+	codes map[string]url.Values
+	// 签发 ID Token 时附加的声明（用于构造异常场景）
+	override jwt.MapClaims
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	m := &mockProvider{
+		t:            t,
+		key:          key,
+		clientID:     "mule-cloud",
+		clientSecret: "s3cr3t",
+		codes:        make(map[string]url.Values),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"userinfo_endpoint":      m.server.URL + "/userinfo",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "idp-1",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", m.token)
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-"+m.clientID {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"sub": "user-1", "groups": []string{"erp-admins"}})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// authorize 模拟用户在身份提供方登录后带授权码跳回
+func (m *mockProvider) authorize(authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatalf("parse auth url: %v", err)
+	}
+	code := "code-" + u.Query().Get("state")
+	m.codes[code] = u.Query()
+	return code
+}
+
+func (m *mockProvider) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != m.clientID || secret != m.clientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		writeJSON(w, map[string]string{"error": "invalid_client"})
+		return
+	}
+	_ = r.ParseForm()
+	params, ok := m.codes[r.PostForm.Get("code")]
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || params.Get("redirect_uri") != r.PostForm.Get("redirect_uri") ||
+		params.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	delete(m.codes, r.PostForm.Get("code"))
+
+	claims := jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            "user-1",
+		"aud":            m.clientID,
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          params.Get("nonce"),
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"name":           "Alice",
+		"amr":            []string{"pwd", "otp"},
+		"realm_access":   map[string]interface{}{"roles": []string{"manager"}},
+	}
+	for k, v := range m.override {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "idp-1"
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		m.t.Fatalf("sign id token: %v", err)
+	}
+	writeJSON(w, Token{AccessToken: "access-" + m.clientID, TokenType: "Bearer", IDToken: idToken, ExpiresIn: 300})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (m *mockProvider) config() Config {
+	return Config{
+		Issuer:       m.server.URL,
+		ClientID:     m.clientID,
+		ClientSecret: m.clientSecret,
+		RedirectURL:  "https://admin.example.com/sso/callback",
+	}
+}
+
+// login 走完整的授权码流程，返回 ID Token 声明
+func login(t *testing.T, m *mockProvider, client *Client) (Claims, error) {
+	t.Helper()
+	state, _ := RandomToken()
+	nonce, _ := RandomToken()
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE() error = %v", err)
+	}
+	code := m.authorize(client.AuthCodeURL(state, nonce, challenge))
+
+	token, err := client.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	return client.VerifyIDToken(token.IDToken, nonce)
+}
+
+// TestAuthorizationCodeFlow 测试完整授权码流程和声明读取
+func TestAuthorizationCodeFlow(t *testing.T) {
+	m := newMockProvider(t)
+	client, err := NewClient(context.Background(), m.config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	claims, err := login(t, m, client)
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if claims.String("sub") != "user-1" || claims.String("email") != "alice@example.com" || !claims.Bool("email_verified") {
+		t.Errorf("unexpected claims: %v", claims)
+	}
+	if roles := claims.Strings("realm_access.roles"); len(roles) != 1 || roles[0] != "manager" {
+		t.Errorf("Strings(realm_access.roles) = %v", roles)
+	}
+	if !claims.MFA() {
+		t.Error("MFA() should be true for amr [pwd otp]")
+	}
+
+	info, err := client.UserInfo(context.Background(), "access-"+m.clientID)
+	if err != nil {
+		t.Fatalf("UserInfo() error = %v", err)
+	}
+	claims.Merge(info)
+	if groups := claims.Strings("groups"); len(groups) != 1 || groups[0] != "erp-admins" {
+		t.Errorf("Merge() groups = %v", groups)
+	}
+}
+
+// TestVerifyIDTokenRejects 测试 ID Token 校验失败的场景
+func TestVerifyIDTokenRejects(t *testing.T) {
+	m := newMockProvider(t)
+	client, err := NewClient(context.Background(), m.config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		override jwt.MapClaims
+	}{
+		{"wrong audience", jwt.MapClaims{"aud": "other-client"}},
+		{"wrong issuer", jwt.MapClaims{"iss": "https://evil.example.com"}},
+		{"expired", jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}},
+		{"wrong nonce", jwt.MapClaims{"nonce": "replayed"}},
+		{"azp mismatch", jwt.MapClaims{"aud": []string{m.clientID, "other"}, "azp": "other"}},
+	}
+	for _, tt := range tests {
+		m.override = tt.override
+		if _, err := login(t, m, client); err == nil {
+			t.Errorf("%s: VerifyIDToken() should fail", tt.name)
+		}
+	}
+
+	// 其他密钥签名的令牌
+	m.override = nil
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": m.server.URL, "sub": "user-1", "aud": m.clientID, "exp": time.Now().Add(time.Minute).Unix(), "nonce": "n",
+	})
+	token.Header["kid"] = "idp-1"
+	forged, _ := token.SignedString(other)
+	if _, err := client.VerifyIDToken(forged, "n"); err == nil {
+		t.Error("VerifyIDToken() should reject a token signed by another key")
+	}
+}
+
+// TestExchangeRejectsBadVerifier 测试 PKCE 校验失败和客户端密钥错误
+func TestExchangeRejectsBadVerifier(t *testing.T) {
+	m := newMockProvider(t)
+	client, _ := NewClient(context.Background(), m.config())
+
+	_, challenge, _ := NewPKCE()
+	code := m.authorize(client.AuthCodeURL("state-1", "nonce", challenge))
+	if _, err := client.Exchange(context.Background(), code, "wrong-verifier"); err == nil {
+		t.Error("Exchange() should fail with a wrong code_verifier")
+	}
+
+	cfg := m.config()
+	cfg.ClientSecret = "wrong"
+	bad, _ := NewClient(context.Background(), cfg)
+	verifier, challenge, _ := NewPKCE()
+	code = m.authorize(bad.AuthCodeURL("state-2", "nonce", challenge))
+	if _, err := bad.Exchange(context.Background(), code, verifier); err == nil {
+		t.Error("Exchange() should fail with a wrong client secret")
+	}
+}
+
+// TestDiscoveryIssuerMismatch 测试元数据 issuer 与配置不一致
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 "https://other.example.com",
+			"authorization_endpoint": "https://other.example.com/authorize",
+			"token_endpoint":         "https://other.example.com/token",
+			"jwks_uri":               "https://other.example.com/jwks",
+		})
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(context.Background(), Config{Issuer: server.URL, ClientID: "c"}); err != ErrIssuerMismatch {
+		t.Errorf("NewClient() error = %v, want ErrIssuerMismatch", err)
+	}
+}
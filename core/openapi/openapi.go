@@ -0,0 +1,265 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"mule-cloud/core/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPI 文档生成
+//
+//	每个服务注册 GET /openapi.json，按已注册的 gin 路由和 transport 包的 Operations 生成文档，
+//	Operations 由 cmd/openapi-gen 分析处理器生成（请求取绑定的 DTO，响应取 response.Success 的数据），
+//	请求参数按 core/binding 的标签区分：uri → 路径参数，form/query → 查询参数，header → 请求头，json → 请求体
+
+// Source 请求参数的绑定来源
+type Source uint8
+
+const (
+	FromURI Source = 1 << iota
+	FromQuery
+	FromHeader
+	FromBody
+
+	// FromAll binding.BindAll 绑定全部来源（未指定时的默认值）
+	FromAll = FromURI | FromQuery | FromHeader | FromBody
+)
+
+// Operation 处理器的接口描述
+type Operation struct {
+	Summary  string
+	Tag      string   // 分组（transport 的文件名）
+	Request  any      // 请求 DTO 的零值，nil 表示不绑定请求参数
+	Bind     Source   // 请求 DTO 的绑定来源，0 表示 FromAll
+	Files    []string // multipart 上传的文件字段
+	Response any      // 统一响应中 data 的零值，nil 表示未知
+	Raw      bool     // 响应不使用统一响应包装（如 JWKS）
+	Binary   bool     // 响应为文件下载
+}
+
+// Operations 处理器的完整函数名（与 gin 路由的 Handler 一致）→ 接口描述
+type Operations map[string]Operation
+
+const bearerAuth = "bearerAuth"
+
+// Build 按路由生成文档（没有接口描述的路由，如 /metrics，不写入文档）
+func Build(info Info, routes gin.RoutesInfo, ops ...Operations) *Document {
+	all := Operations{}
+	for _, o := range ops {
+		for key, op := range o {
+			all[key] = op
+		}
+	}
+	if info.Version == "" {
+		info.Version = "1.0.0"
+	}
+
+	s := newSchemas()
+	envelope := s.of(reflect.TypeOf(response.Response{}))
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{SecuritySchemes: map[string]*SecurityScheme{
+			bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "登录返回的访问令牌（经网关访问时由网关校验）"},
+		}},
+		Security: []map[string][]string{{bearerAuth: {}}},
+	}
+
+	ids := map[string]bool{}
+	tags := map[string]bool{}
+	for _, route := range routes {
+		key := HandlerKey(route.Handler)
+		op, ok := all[key]
+		if !ok {
+			continue
+		}
+		path, params := convertPath(route.Path)
+		o := s.operation(route.Method, op, params, envelope)
+		o.OperationID = operationID(key, ids)
+		if op.Tag != "" {
+			o.Tags = []string{op.Tag}
+			tags[op.Tag] = true
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = o
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	doc.Components.Schemas = s.defs
+	return doc
+}
+
+// Handler 服务的 OpenAPI 文档（首次请求时按已注册的全部路由生成）
+func Handler(r *gin.Engine, info Info, ops ...Operations) gin.HandlerFunc {
+	var (
+		once sync.Once
+		doc  *Document
+	)
+	return func(c *gin.Context) {
+		once.Do(func() { doc = Build(info, r.Routes(), ops...) })
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// HandlerKey gin 路由的处理器名去掉闭包后缀（.func1）
+func HandlerKey(handler string) string {
+	if i := strings.LastIndex(handler, ".func"); i > 0 {
+		return handler[:i]
+	}
+	return handler
+}
+
+// convertPath gin 路径转换为 OpenAPI 路径：/:id → /{id}，/*path → /{path}
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, seg := range segments {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			params = append(params, seg[1:])
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID 处理器名去掉包路径和 Handler 后缀，重名时加上服务包名
+func operationID(key string, used map[string]bool) string {
+	pkg, name := key, key
+	if i := strings.LastIndexByte(key, '/'); i >= 0 {
+		pkg, name = key[:i], key[i+1:]
+	}
+	_, name, _ = strings.Cut(name, ".")
+	name = strings.TrimSuffix(strings.NewReplacer("(*", "", ")", "").Replace(name), "Handler")
+	id := name
+	if used[id] {
+		id = pkg[strings.LastIndexByte(pkg, '/')+1:] + "." + name
+	}
+	used[id] = true
+	return id
+}
+
+// operation 生成接口的参数、请求体和响应
+func (s *schemas) operation(method string, op Operation, pathParams []string, envelope *Schema) *Op {
+	o := &Op{Summary: op.Summary, Responses: map[string]*Response{}}
+
+	// 路径参数默认为字符串，DTO 中有 uri 字段时使用字段的类型
+	path := map[string]*Parameter{}
+	for _, name := range pathParams {
+		p := &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		path[name] = p
+		o.Parameters = append(o.Parameters, p)
+	}
+	if op.Request != nil {
+		s.request(o, method, op, path)
+	}
+
+	switch {
+	case op.Binary:
+		o.Responses["200"] = &Response{Description: "文件", Content: map[string]*MediaType{
+			"application/octet-stream": {Schema: &Schema{Type: "string", Format: "binary"}},
+		}}
+	case op.Raw:
+		data := &Schema{}
+		if op.Response != nil {
+			data = s.of(reflect.TypeOf(op.Response))
+		}
+		o.Responses["200"] = &Response{Description: "成功", Content: map[string]*MediaType{"application/json": {Schema: data}}}
+	default:
+		schema := envelope
+		if op.Response != nil {
+			schema = &Schema{AllOf: []*Schema{envelope, {
+				Type:       "object",
+				Properties: map[string]*Schema{"data": s.of(reflect.TypeOf(op.Response))},
+			}}}
+		}
+		o.Responses["200"] = &Response{Description: "成功（code 为 0），失败时 code 非 0、msg 为错误信息", Content: map[string]*MediaType{
+			"application/json": {Schema: schema},
+		}}
+	}
+	return o
+}
+
+// request 按绑定来源和标签把 DTO 字段分到路径参数、查询参数、请求头和请求体
+func (s *schemas) request(o *Op, method string, op Operation, path map[string]*Parameter) {
+	t := deref(reflect.TypeOf(op.Request))
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	bind := op.Bind
+	if bind == 0 {
+		bind = FromAll
+	}
+	hasBody := bind&FromBody != 0 && (method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch)
+	multipartForm := hasBody && len(op.Files) > 0
+
+	body := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, f := range fields(t) {
+		uri, form, header := tagName(f, "uri"), tagName(f, "form"), tagName(f, "header")
+		if form == "" {
+			form = tagName(f, "query")
+		}
+		if p := path[uri]; p != nil && bind&FromURI != 0 {
+			p.Schema = s.of(f.Type)
+			continue
+		}
+		switch {
+		case uri != "" && form == "":
+			continue
+		case header != "":
+			if bind&FromHeader != 0 {
+				s.addParameter(o, header, "header", f)
+			}
+			continue
+		case multipartForm:
+			if form != "" {
+				s.addProperty(body, form, f)
+			}
+			continue
+		}
+
+		name, isJSON := jsonName(f)
+		if _, tagged := f.Tag.Lookup("json"); form != "" && !tagged {
+			isJSON = false
+		}
+		switch {
+		case hasBody && isJSON:
+			s.addProperty(body, name, f)
+		case form != "" && bind&FromQuery != 0:
+			s.addParameter(o, form, "query", f)
+		}
+	}
+
+	for _, file := range op.Files {
+		body.Properties[file] = &Schema{Type: "string", Format: "binary"}
+		body.Required = append(body.Required, file)
+	}
+	if len(body.Properties) == 0 {
+		return
+	}
+	contentType := "application/json"
+	if multipartForm {
+		contentType = "multipart/form-data"
+	}
+	o.RequestBody = &RequestBody{
+		Required: len(body.Required) > 0,
+		Content:  map[string]*MediaType{contentType: {Schema: body}},
+	}
+}
+
+// addParameter 查询参数或请求头
+func (s *schemas) addParameter(o *Op, name, in string, f reflect.StructField) {
+	schema := s.of(f.Type)
+	o.Parameters = append(o.Parameters, &Parameter{Name: name, In: in, Required: applyRules(schema, f), Schema: schema})
+}
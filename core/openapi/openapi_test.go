@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type styleRequest struct {
+	ID       string `uri:"id" binding:"required"`
+	TenantID string `header:"X-Tenant-ID"`
+	Page     int    `form:"page" binding:"min=1"`
+	Status   string `form:"status" binding:"omitempty,oneof=draft done"`
+	Name     string `json:"name" binding:"required,max=50"`
+	Qty      int    `json:"qty" binding:"gte=0"`
+}
+
+type styleResponse struct {
+	ID        string         `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	Parent    *styleResponse `json:"parent,omitempty"`
+	Sizes     []string       `json:"sizes"`
+}
+
+type uploadRequest struct {
+	Folder string `form:"folder" binding:"required"`
+}
+
+func styleHandler() gin.HandlerFunc  { return func(c *gin.Context) {} }
+func uploadHandler() gin.HandlerFunc { return func(c *gin.Context) {} }
+func exportHandler() gin.HandlerFunc { return func(c *gin.Context) {} }
+
+const pkg = "mule-cloud/core/openapi."
+
+func buildTestDoc(t *testing.T) *Document {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/styles/:id", styleHandler())
+	r.PUT("/styles/:id", styleHandler())
+	r.POST("/files", uploadHandler())
+	r.GET("/files/*path", exportHandler())
+	r.GET("/metrics", func(c *gin.Context) {})
+
+	doc := Build(Info{Title: "test"}, r.Routes(), Operations{
+		pkg + "styleHandler":  {Summary: "款式", Tag: "style", Request: styleRequest{}, Response: &styleResponse{}},
+		pkg + "uploadHandler": {Summary: "上传", Tag: "file", Request: uploadRequest{}, Files: []string{"file"}, Response: map[string]string{}},
+		pkg + "exportHandler": {Summary: "下载", Tag: "file", Binary: true},
+	})
+	// 文档需要能按 JSON 输出
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return doc
+}
+
+func TestBuildPaths(t *testing.T) {
+	doc := buildTestDoc(t)
+	if doc.Info.Version != "1.0.0" {
+		t.Errorf("version = %q", doc.Info.Version)
+	}
+	if _, ok := doc.Paths["/metrics"]; ok {
+		t.Error("没有接口描述的路由不应写入文档")
+	}
+	if len(doc.Tags) != 2 || doc.Tags[0].Name != "file" || doc.Tags[1].Name != "style" {
+		t.Errorf("tags = %v", doc.Tags)
+	}
+	get := doc.Paths["/styles/{id}"]["get"]
+	put := doc.Paths["/styles/{id}"]["put"]
+	if get == nil || put == nil {
+		t.Fatalf("paths = %v", doc.Paths)
+	}
+	if get.OperationID == put.OperationID {
+		t.Errorf("operationId 重复: %s", get.OperationID)
+	}
+	if download := doc.Paths["/files/{path}"]["get"]; download == nil || download.Responses["200"].Content["application/octet-stream"] == nil {
+		t.Errorf("文件下载应为 octet-stream: %+v", download)
+	}
+}
+
+func TestBuildParameters(t *testing.T) {
+	doc := buildTestDoc(t)
+	params := map[string]*Parameter{}
+	get := doc.Paths["/styles/{id}"]["get"]
+	for _, p := range get.Parameters {
+		params[p.In+":"+p.Name] = p
+	}
+	if p := params["path:id"]; p == nil || !p.Required {
+		t.Errorf("缺少路径参数 id: %v", params)
+	}
+	if p := params["header:X-Tenant-ID"]; p == nil {
+		t.Error("缺少请求头参数")
+	}
+	if p := params["query:page"]; p == nil || p.Schema.Type != "integer" || p.Schema.Minimum == nil || *p.Schema.Minimum != 1 {
+		t.Errorf("page = %+v", p)
+	}
+	if p := params["query:status"]; p == nil || p.Required || !reflect.DeepEqual(p.Schema.Enum, []any{"draft", "done"}) {
+		t.Errorf("status = %+v", p)
+	}
+	if get.RequestBody != nil {
+		t.Error("GET 不应有请求体")
+	}
+
+	// PUT 的 json 字段在请求体中
+	body := doc.Paths["/styles/{id}"]["put"].RequestBody
+	if body == nil || body.Content["application/json"] == nil {
+		t.Fatalf("缺少请求体: %+v", body)
+	}
+	schema := body.Content["application/json"].Schema
+	if !reflect.DeepEqual(schema.Required, []string{"name"}) {
+		t.Errorf("required = %v", schema.Required)
+	}
+	if name := schema.Properties["name"]; name == nil || name.MaxLength == nil || *name.MaxLength != 50 {
+		t.Errorf("name = %+v", name)
+	}
+	if _, ok := schema.Properties["page"]; ok {
+		t.Error("查询参数不应在请求体中")
+	}
+}
+
+func TestBuildMultipart(t *testing.T) {
+	doc := buildTestDoc(t)
+	upload := doc.Paths["/files"]["post"]
+	media := upload.RequestBody.Content["multipart/form-data"]
+	if media == nil {
+		t.Fatalf("上传应为 multipart: %+v", upload.RequestBody)
+	}
+	if f := media.Schema.Properties["file"]; f == nil || f.Format != "binary" {
+		t.Errorf("file = %+v", f)
+	}
+	if media.Schema.Properties["folder"] == nil || !reflect.DeepEqual(media.Schema.Required, []string{"folder", "file"}) {
+		t.Errorf("schema = %+v", media.Schema)
+	}
+}
+
+func TestBuildResponseSchemas(t *testing.T) {
+	doc := buildTestDoc(t)
+	schema := doc.Paths["/styles/{id}"]["get"].Responses["200"].Content["application/json"].Schema
+	if len(schema.AllOf) != 2 || schema.AllOf[0].Ref != "#/components/schemas/core.response.Response" {
+		t.Fatalf("响应应为统一响应包装: %+v", schema)
+	}
+	if data := schema.AllOf[1].Properties["data"]; data == nil || data.Ref != "#/components/schemas/core.openapi.styleResponse" {
+		t.Errorf("data = %+v", data)
+	}
+
+	def := doc.Components.Schemas["core.openapi.styleResponse"]
+	if def == nil {
+		t.Fatalf("schemas = %v", doc.Components.Schemas)
+	}
+	if c := def.Properties["created_at"]; c.Type != "string" || c.Format != "date-time" {
+		t.Errorf("created_at = %+v", c)
+	}
+	// 递归结构引用自身
+	if p := def.Properties["parent"]; p.Ref != "#/components/schemas/core.openapi.styleResponse" {
+		t.Errorf("parent = %+v", p)
+	}
+	if s := def.Properties["sizes"]; s.Type != "array" || s.Items.Type != "string" {
+		t.Errorf("sizes = %+v", s)
+	}
+}
+
+func TestConvertPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   string
+		params []string
+	}{
+		{"/styles", "/styles", nil},
+		{"/styles/:id/colors/:color", "/styles/{id}/colors/{color}", []string{"id", "color"}},
+		{"/files/*path", "/files/{path}", []string{"path"}},
+	}
+	for _, tt := range tests {
+		got, params := convertPath(tt.path)
+		if got != tt.want || !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("convertPath(%q) = %q %v, want %q %v", tt.path, got, params, tt.want, tt.params)
+		}
+	}
+}
+
+func TestOperationID(t *testing.T) {
+	used := map[string]bool{}
+	tests := []struct{ key, want string }{
+		{"mule-cloud/app/order/transport.GetStyleHandler", "GetStyle"},
+		{"mule-cloud/app/common/transport.(*FileTransport).UploadHandler", "FileTransport.Upload"},
+		{"mule-cloud/app/basic/transport.GetStyleHandler", "basic.GetStyle"},
+	}
+	for _, tt := range tests {
+		if got := operationID(tt.key, used); got != tt.want {
+			t.Errorf("operationID(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	if got := HandlerKey("mule-cloud/app/order/transport.GetStyleHandler.func1"); got != "mule-cloud/app/order/transport.GetStyleHandler" {
+		t.Errorf("HandlerKey = %q", got)
+	}
+}
@@ -0,0 +1,259 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const refPrefix = "#/components/schemas/"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	objectIDType   = reflect.TypeOf(bson.ObjectID{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+
+	// modulePrefix 本项目的包路径前缀（结构名去掉该前缀）
+	modulePrefix = strings.SplitN(reflect.TypeOf(Document{}).PkgPath(), "/", 2)[0] + "/"
+
+	unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+)
+
+// schemas 从 Go 类型生成结构（命名结构体放到 components 中引用）
+type schemas struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// of 类型对应的结构（JSON 序列化后的形式）
+func (s *schemas) of(t reflect.Type) *Schema {
+	t = deref(t)
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIDType:
+		return &Schema{Type: "string", Description: "ObjectID"}
+	case rawMessageType:
+		return &Schema{}
+	case fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.ref(t)
+	default:
+		// interface{} 等任意类型
+		return &Schema{}
+	}
+}
+
+// ref 命名结构体的引用（首次遇到时生成定义，递归结构先登记名称）
+func (s *schemas) ref(t reflect.Type) *Schema {
+	name, ok := s.names[t]
+	if !ok {
+		name = s.nameOf(t)
+		s.names[t] = name
+		s.defs[name] = &Schema{}
+		*s.defs[name] = *s.object(t)
+	}
+	return &Schema{Ref: refPrefix + name}
+}
+
+// nameOf 结构名：包路径去掉项目前缀和 app/、internal/ 后加类型名（如 order.dto.StyleResponse、models.Style）
+func (s *schemas) nameOf(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if rel, ok := strings.CutPrefix(pkg, modulePrefix); ok {
+		rel = strings.TrimPrefix(strings.TrimPrefix(rel, "app/"), "internal/")
+		pkg = strings.ReplaceAll(rel, "/", ".")
+	} else {
+		pkg = pkg[strings.LastIndexByte(pkg, '/')+1:]
+	}
+	base := unsafeName.ReplaceAllString(pkg+"."+t.Name(), "_")
+	name := base
+	for i := 2; s.defs[name] != nil; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	return name
+}
+
+// object 结构体按 json 标签生成对象
+func (s *schemas) object(t reflect.Type) *Schema {
+	obj := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, f := range fields(t) {
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		s.addProperty(obj, name, f)
+	}
+	return obj
+}
+
+// addProperty 对象加上字段（binding/validate 规则转换为必填和取值约束）
+func (s *schemas) addProperty(obj *Schema, name string, f reflect.StructField) {
+	prop := s.of(f.Type)
+	if applyRules(prop, f) {
+		obj.Required = append(obj.Required, name)
+	}
+	obj.Properties[name] = prop
+}
+
+// fields 结构体的导出字段（与 encoding/json 一致，提升匿名嵌入结构体的字段）
+func fields(t reflect.Type) []reflect.StructField {
+	var result []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			ft := deref(f.Type)
+			if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name == "" && ft.Kind() == reflect.Struct {
+				result = append(result, fields(ft)...)
+				continue
+			}
+		}
+		if f.IsExported() {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// jsonName 字段的 JSON 名称，json:"-" 时返回 false
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+// tagName 标签中的名称（去掉选项）
+func tagName(f reflect.StructField, key string) string {
+	name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// applyRules 把 binding/validate 标签的规则写入结构，返回是否必填
+func applyRules(prop *Schema, f reflect.StructField) bool {
+	required := false
+	for _, key := range []string{"binding", "validate"} {
+		for _, rule := range strings.Split(f.Tag.Get(key), ",") {
+			name, param, _ := strings.Cut(rule, "=")
+			if name == "dive" {
+				break // 之后的规则作用于元素
+			}
+			switch name {
+			case "required":
+				required = true
+			case "email":
+				prop.Format = "email"
+			case "url":
+				prop.Format = "uri"
+			case "oneof":
+				prop.Enum = enumValues(prop, strings.Fields(param))
+			case "min", "gte", "gt":
+				setBound(prop, param, true, name == "gt")
+			case "max", "lte", "lt":
+				setBound(prop, param, false, name == "lt")
+			case "len":
+				setBound(prop, param, true, false)
+				setBound(prop, param, false, false)
+			}
+		}
+	}
+	return required
+}
+
+// setBound 按类型设置最小/最大值、长度或元素个数
+func setBound(prop *Schema, param string, lower, exclusive bool) {
+	v, err := strconv.ParseFloat(param, 64)
+	if err != nil || prop.Ref != "" {
+		return
+	}
+	n := int(v)
+	switch prop.Type {
+	case "integer", "number":
+		if lower {
+			prop.Minimum, prop.ExclusiveMinimum = &v, exclusive
+		} else {
+			prop.Maximum, prop.ExclusiveMaximum = &v, exclusive
+		}
+	case "string":
+		if lower {
+			prop.MinLength = &n
+		} else {
+			prop.MaxLength = &n
+		}
+	case "array":
+		if lower {
+			prop.MinItems = &n
+		} else {
+			prop.MaxItems = &n
+		}
+	}
+}
+
+// enumValues oneof 的取值（数值类型转换为数字）
+func enumValues(prop *Schema, values []string) []any {
+	enum := make([]any, 0, len(values))
+	for _, v := range values {
+		if prop.Type == "integer" || prop.Type == "number" {
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				enum = append(enum, n)
+				continue
+			}
+		}
+		enum = append(enum, v)
+	}
+	return enum
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
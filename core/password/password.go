@@ -0,0 +1,184 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"mule-cloud/core/config"
+	"mule-cloud/util"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+
+	// legacySalt 旧版本 MD5 哈希使用的全局盐，仅用于校验历史密码
+	legacySalt = "mule-zdm"
+
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+var (
+	ErrInvalidHash = errors.New("无法识别的密码哈希格式")
+
+	mu      sync.RWMutex
+	current = normalize(config.PasswordConfig{})
+)
+
+// Init 使用配置初始化密码哈希参数与密码策略，未配置的项使用默认值
+func Init(cfg *config.PasswordConfig) {
+	if cfg == nil {
+		return
+	}
+	mu.Lock()
+	current = normalize(*cfg)
+	mu.Unlock()
+}
+
+// settings 获取当前配置
+func settings() config.PasswordConfig {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// normalize 填充默认值
+func normalize(cfg config.PasswordConfig) config.PasswordConfig {
+	cfg.Algorithm = strings.ToLower(strings.TrimSpace(cfg.Algorithm))
+	if cfg.Algorithm != AlgorithmBcrypt {
+		cfg.Algorithm = AlgorithmArgon2id
+	}
+	if cfg.Argon2Memory == 0 {
+		cfg.Argon2Memory = 64 * 1024
+	}
+	if cfg.Argon2Time == 0 {
+		cfg.Argon2Time = 3
+	}
+	if cfg.Argon2Threads == 0 {
+		cfg.Argon2Threads = 2
+	}
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		cfg.BcryptCost = 12
+	}
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = 8
+	}
+	if cfg.HistorySize < 0 {
+		cfg.HistorySize = 0
+	}
+	return cfg
+}
+
+// Hash 使用当前配置的算法生成密码哈希（每次随机盐）
+//
+// argon2id: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+// bcrypt:   $2a$12$...
+func Hash(password string) (string, error) {
+	cfg := settings()
+	if cfg.Algorithm == AlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), cfg.BcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("生成密码哈希失败: %w", err)
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("生成密码盐失败: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, cfg.Argon2Time, cfg.Argon2Memory, cfg.Argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, cfg.Argon2Memory, cfg.Argon2Time, cfg.Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify 校验密码，兼容 argon2id、bcrypt 和旧版 MD5 哈希
+//
+// needsRehash 为 true 表示密码正确但哈希是旧格式或参数已变更，调用方应重新哈希并保存。
+func Verify(password, hash string) (ok bool, needsRehash bool) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		params, salt, key, err := parseArgon2id(hash)
+		if err != nil {
+			return false, false
+		}
+		actual := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(actual, key) != 1 {
+			return false, false
+		}
+		cfg := settings()
+		return true, cfg.Algorithm != AlgorithmArgon2id ||
+			params.memory != cfg.Argon2Memory || params.time != cfg.Argon2Time || params.threads != cfg.Argon2Threads
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+			return false, false
+		}
+		cfg := settings()
+		cost, err := bcrypt.Cost([]byte(hash))
+		return true, err != nil || cfg.Algorithm != AlgorithmBcrypt || cost != cfg.BcryptCost
+	case IsLegacy(hash):
+		expected := util.ToolsUtil.Md5(password + legacySalt)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(hash))) != 1 {
+			return false, false
+		}
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// IsLegacy 是否为旧版 MD5 哈希（32位十六进制，无格式前缀）
+func IsLegacy(hash string) bool {
+	if len(hash) != 32 {
+		return false
+	}
+	for _, c := range hash {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+// parseArgon2id 解析 $argon2id$v=19$m=..,t=..,p=..$salt$hash
+func parseArgon2id(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrInvalidHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidHash
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,113 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"mule-cloud/core/config"
+)
+
+// TestHashAndVerify 测试哈希格式、旧版 MD5 兼容和重新哈希判断
+func TestHashAndVerify(t *testing.T) {
+	Init(&config.PasswordConfig{Argon2Memory: 1024, Argon2Time: 1, Argon2Threads: 1, BcryptCost: 4})
+
+	t.Run("argon2id", func(t *testing.T) {
+		hash, err := Hash("Secret123")
+		if err != nil {
+			t.Fatalf("Hash() error = %v", err)
+		}
+		if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+			t.Errorf("Hash() = %s, want argon2id format", hash)
+		}
+		other, _ := Hash("Secret123")
+		if other == hash {
+			t.Error("Hash() should use a random salt per call")
+		}
+		if ok, rehash := Verify("Secret123", hash); !ok || rehash {
+			t.Errorf("Verify() = %v, %v, want true, false", ok, rehash)
+		}
+		if ok, _ := Verify("secret123", hash); ok {
+			t.Error("Verify() should reject a wrong password")
+		}
+	})
+
+	t.Run("legacy md5", func(t *testing.T) {
+		legacy := "0ca89285b2ed406e282a92fd6a8b3c8a" // MD5("123456" + "mule-zdm")
+		if ok, rehash := Verify("123456", legacy); !ok || !rehash {
+			t.Errorf("Verify() = %v, %v, want true, true", ok, rehash)
+		}
+		if ok, _ := Verify("1234567", legacy); ok {
+			t.Error("Verify() should reject a wrong legacy password")
+		}
+	})
+
+	t.Run("algorithm change needs rehash", func(t *testing.T) {
+		hash, _ := Hash("Secret123")
+		Init(&config.PasswordConfig{Algorithm: AlgorithmBcrypt, BcryptCost: 4})
+		defer Init(&config.PasswordConfig{Argon2Memory: 1024, Argon2Time: 1, Argon2Threads: 1, BcryptCost: 4})
+
+		if ok, rehash := Verify("Secret123", hash); !ok || !rehash {
+			t.Errorf("Verify() = %v, %v, want true, true", ok, rehash)
+		}
+		bcryptHash, _ := Hash("Secret123")
+		if !strings.HasPrefix(bcryptHash, "$2a$04$") {
+			t.Errorf("Hash() = %s, want bcrypt format", bcryptHash)
+		}
+		if ok, rehash := Verify("Secret123", bcryptHash); !ok || rehash {
+			t.Errorf("Verify() = %v, %v, want true, false", ok, rehash)
+		}
+	})
+
+	t.Run("invalid hash", func(t *testing.T) {
+		for _, hash := range []string{"", "plain", "$argon2id$v=19$bad", "$argon2id$v=19$m=1,t=1,p=1$!!$!!"} {
+			if ok, _ := Verify("plain", hash); ok {
+				t.Errorf("Verify(%q) should fail", hash)
+			}
+		}
+	})
+}
+
+// TestPolicy 测试密码策略与历史密码
+func TestPolicy(t *testing.T) {
+	Init(&config.PasswordConfig{
+		Argon2Memory: 1024, Argon2Time: 1, Argon2Threads: 1,
+		MinLength: 8, RequireLower: true, RequireDigit: true, HistorySize: 3,
+	})
+
+	tests := []struct {
+		password string
+		wantErr  bool
+	}{
+		{"abc123", true},
+		{"abcdefgh", true},
+		{"12345678", true},
+		{"abcd1234", false},
+		{"密码abcd1234", false},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.password); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%s) error = %v, wantErr %v", tt.password, err, tt.wantErr)
+		}
+	}
+
+	// 依次使用 p1 -> p2 -> p3 -> p4，history_size=3 时 p1 可以再次使用
+	var current string
+	var history []string
+	for _, p := range []string{"pass0001", "pass0002", "pass0003", "pass0004"} {
+		if current != "" {
+			history = PushHistory(history, current)
+		}
+		current, _ = Hash(p)
+	}
+	if len(history) != 2 {
+		t.Fatalf("PushHistory() length = %d, want 2", len(history))
+	}
+	for _, p := range []string{"pass0002", "pass0003", "pass0004"} {
+		if err := CheckReuse(p, current, history); err != ErrPasswordReused {
+			t.Errorf("CheckReuse(%s) = %v, want ErrPasswordReused", p, err)
+		}
+	}
+	if err := CheckReuse("pass0001", current, history); err != nil {
+		t.Errorf("CheckReuse(pass0001) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,89 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrPasswordReused 新密码与最近使用过的密码相同
+var ErrPasswordReused = errors.New("新密码不能与最近使用过的密码相同")
+
+// Validate 按密码策略校验密码强度
+func Validate(password string) error {
+	cfg := settings()
+
+	if len([]rune(password)) < cfg.MinLength {
+		return fmt.Errorf("密码长度不能少于%d位", cfg.MinLength)
+	}
+	if len(password) > 72 && cfg.Algorithm == AlgorithmBcrypt {
+		// bcrypt 只使用前72字节，超出部分会被忽略
+		return fmt.Errorf("密码长度不能超过72个字节")
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			hasUpper = true
+		case unicode.IsLower(c):
+			hasLower = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		case unicode.IsSpace(c):
+		default:
+			hasSymbol = true
+		}
+	}
+
+	var missing []string
+	if cfg.RequireUpper && !hasUpper {
+		missing = append(missing, "大写字母")
+	}
+	if cfg.RequireLower && !hasLower {
+		missing = append(missing, "小写字母")
+	}
+	if cfg.RequireDigit && !hasDigit {
+		missing = append(missing, "数字")
+	}
+	if cfg.RequireSymbol && !hasSymbol {
+		missing = append(missing, "特殊字符")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("密码必须包含%s", strings.Join(missing, "、"))
+	}
+	return nil
+}
+
+// CheckReuse 校验新密码是否与当前密码或最近的历史密码相同
+func CheckReuse(password, currentHash string, history []string) error {
+	size := settings().HistorySize
+	if size == 0 {
+		return nil
+	}
+	hashes := append([]string{currentHash}, history...)
+	if len(hashes) > size {
+		hashes = hashes[:size]
+	}
+	for _, hash := range hashes {
+		if ok, _ := Verify(password, hash); ok {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// PushHistory 把被替换的旧哈希加入历史记录（最新的在前），只保留策略要求的条数
+func PushHistory(history []string, oldHash string) []string {
+	size := settings().HistorySize
+	if size == 0 || oldHash == "" {
+		return []string{}
+	}
+	result := append([]string{oldHash}, history...)
+	// 当前密码本身占一条，历史只需保留 size-1 条
+	if len(result) > size-1 {
+		result = result[:size-1]
+	}
+	return result
+}
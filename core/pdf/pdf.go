@@ -0,0 +1,150 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 页面尺寸（单位：pt）
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Document 极简 PDF 文档（用于对账单等报表导出）
+//
+// 中文使用 PDF 阅读器内置的 STSong-Light 字体（Adobe-GB1），无需嵌入字体文件。
+// 坐标以页面左上角为原点，y 轴向下。
+type Document struct {
+	pages    []*bytes.Buffer
+	current  *bytes.Buffer
+	fontSize float64
+}
+
+// New 创建文档（默认字号 10）
+func New() *Document {
+	return &Document{fontSize: 10}
+}
+
+// AddPage 新增一页
+func (d *Document) AddPage() {
+	d.current = &bytes.Buffer{}
+	d.pages = append(d.pages, d.current)
+}
+
+// PageCount 当前页数
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// SetFontSize 设置字号
+func (d *Document) SetFontSize(size float64) {
+	if size > 0 {
+		d.fontSize = size
+	}
+}
+
+// FontSize 当前字号
+func (d *Document) FontSize() float64 {
+	return d.fontSize
+}
+
+// Text 在 (x, y) 处输出文本，y 为文本基线位置
+func (d *Document) Text(x, y float64, text string) {
+	if text == "" {
+		return
+	}
+	d.ensurePage()
+	fmt.Fprintf(d.current, "BT /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n",
+		d.fontSize, x, PageHeight-y, encodeText(text))
+}
+
+// TextRight 右对齐输出文本，x 为文本右边界
+func (d *Document) TextRight(x, y float64, text string) {
+	d.Text(x-d.TextWidth(text), y, text)
+}
+
+// Line 画线
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	d.ensurePage()
+	fmt.Fprintf(d.current, "0.5 w %.2f %.2f m %.2f %.2f l S\n",
+		x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// TextWidth 估算文本宽度（ASCII 半角，其余全角）
+func (d *Document) TextWidth(text string) float64 {
+	width := 0.0
+	for _, r := range text {
+		if r < 0x80 {
+			width += 0.5
+		} else {
+			width += 1
+		}
+	}
+	return width * d.fontSize
+}
+
+// Bytes 输出 PDF 文件内容
+func (d *Document) Bytes() []byte {
+	d.ensurePage()
+
+	var buf bytes.Buffer
+	offsets := make([]int, 0)
+	writeObj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1-目录 2-页树 3~5-字体，之后每页占两个对象（页面+内容流）
+	const firstPageObj = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+i*2)
+	}
+
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	writeObj("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	writeObj("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> " +
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	writeObj("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 " +
+		"/FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+
+	for i, page := range d.pages {
+		writeObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, firstPageObj+i*2+1))
+		writeObj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+func (d *Document) ensurePage() {
+	if d.current == nil {
+		d.AddPage()
+	}
+}
+
+// encodeText 转为 UCS-2 大端十六进制（超出 BMP 的字符以 ? 代替）
+func encodeText(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if r > 0xFFFF {
+			r = '?'
+		}
+		fmt.Fprintf(&sb, "%04X", r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,288 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"mule-cloud/core/cache"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// 配额项
+const (
+	Members          = "members"   // 在职成员数
+	OrdersPerMonth   = "orders"    // 本月新建订单数
+	StorageBytes     = "storage"   // 文件存储（字节）
+	APICallsPerMonth = "api_calls" // 本月经网关的请求数
+)
+
+// Redis 键
+//
+//	quota:api_calls:<tenant>:<yyyymm> 本月经网关的请求数，保留到下月之后
+const apiCallsKeyPrefix = "quota:api_calls:"
+
+var (
+	ErrQuotaExceeded       = errors.New("已达到套餐配额上限")
+	ErrFeatureDisabled     = errors.New("当前套餐未开通该功能")
+	ErrSubscriptionExpired = errors.New("套餐已过期，请续费后再操作")
+)
+
+// cacheTTL 租户套餐在进程内的缓存时间（修改套餐后各服务最多延迟这么久生效）
+const cacheTTL = 30 * time.Second
+
+// Entitlement 租户当前的套餐权益
+type Entitlement struct {
+	TenantCode   string                    `json:"tenant_code"`
+	Subscription models.TenantSubscription `json:"subscription"`
+	Plan         *models.Plan              `json:"plan,omitempty"` // 未订阅时为 nil（不限制）
+	State        string                    `json:"state"`          // none / active / grace / expired
+}
+
+// Limit 配额项的上限（0 表示不限制）
+func (e *Entitlement) Limit(kind string) int64 {
+	if e.Plan == nil {
+		return 0
+	}
+	switch kind {
+	case Members:
+		return e.Plan.Limits.MaxMembers
+	case OrdersPerMonth:
+		return e.Plan.Limits.MaxOrdersPerMonth
+	case StorageBytes:
+		return e.Plan.Limits.MaxStorageBytes
+	case APICallsPerMonth:
+		return e.Plan.Limits.MaxAPICallsPerMonth
+	}
+	return 0
+}
+
+// HasFeature 是否开通功能（未订阅套餐的租户不限制）
+func (e *Entitlement) HasFeature(feature string) bool {
+	return e.Plan == nil || e.Plan.HasFeature(feature)
+}
+
+type cached struct {
+	entitlement *Entitlement
+	loadedAt    time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+	entries = map[string]cached{}
+)
+
+// Resolve 获取租户的套餐权益（缓存 30 秒）；系统租户和未订阅的租户返回不限制的权益
+func Resolve(ctx context.Context, tenantCode string) (*Entitlement, error) {
+	if tenantCode == "" || tenantCode == "system" {
+		return &Entitlement{TenantCode: tenantCode, State: models.SubscriptionNone}, nil
+	}
+
+	cacheMu.Lock()
+	entry, ok := entries[tenantCode]
+	cacheMu.Unlock()
+	if ok && time.Since(entry.loadedAt) < cacheTTL {
+		return refreshState(entry.entitlement), nil
+	}
+
+	systemCtx := tenantCtx.WithTenantCode(ctx, "")
+	tenant, err := repository.NewTenantRepository().GetByCode(systemCtx, tenantCode)
+	if err != nil {
+		return nil, fmt.Errorf("查询租户失败: %w", err)
+	}
+	e := &Entitlement{TenantCode: tenantCode}
+	if tenant != nil && tenant.Subscription.PlanCode != "" {
+		e.Subscription = tenant.Subscription
+		plan, err := repository.NewPlanRepository().GetByCode(systemCtx, tenant.Subscription.PlanCode)
+		if err != nil {
+			return nil, fmt.Errorf("查询套餐失败: %w", err)
+		}
+		if plan == nil {
+			// 套餐被删除时按未订阅处理，避免租户被锁死
+			e.Subscription = models.TenantSubscription{}
+		}
+		e.Plan = plan
+	}
+
+	cacheMu.Lock()
+	entries[tenantCode] = cached{entitlement: e, loadedAt: time.Now()}
+	cacheMu.Unlock()
+	return refreshState(e), nil
+}
+
+// refreshState 按当前时间计算订阅状态（缓存期间可能跨过到期时间）
+func refreshState(e *Entitlement) *Entitlement {
+	copied := *e
+	copied.State = e.Subscription.State(time.Now().Unix())
+	return &copied
+}
+
+// Invalidate 清除租户的套餐缓存（修改套餐或订阅后调用，只影响当前进程）
+func Invalidate(tenantCode string) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if tenantCode == "" {
+		entries = map[string]cached{}
+		return
+	}
+	delete(entries, tenantCode)
+}
+
+// Check 新增 adding 个单位前检查配额（如绑定成员、新建订单、上传文件）
+func Check(ctx context.Context, tenantCode, kind string, adding int64) error {
+	e, err := Resolve(ctx, tenantCode)
+	if err != nil {
+		return err
+	}
+	if e.State == models.SubscriptionExpired {
+		return ErrSubscriptionExpired
+	}
+	limit := e.Limit(kind)
+	if limit <= 0 {
+		return nil
+	}
+	used, err := Used(ctx, tenantCode, kind)
+	if err != nil {
+		return err
+	}
+	if used+adding > limit {
+		return fmt.Errorf("%w：%s 已使用 %d / %d", ErrQuotaExceeded, kindName(kind), used, limit)
+	}
+	return nil
+}
+
+// CheckFeature 检查功能是否开通
+func CheckFeature(ctx context.Context, tenantCode, feature string) error {
+	e, err := Resolve(ctx, tenantCode)
+	if err != nil {
+		return err
+	}
+	if !e.HasFeature(feature) {
+		return ErrFeatureDisabled
+	}
+	return nil
+}
+
+// Usage 租户的配额使用量
+type Usage struct {
+	Members           int64 `json:"members"`
+	OrdersThisMonth   int64 `json:"orders_this_month"`
+	StorageBytes      int64 `json:"storage_bytes"`
+	APICallsThisMonth int64 `json:"api_calls_this_month"`
+}
+
+// GetUsage 统计租户的全部使用量
+func GetUsage(ctx context.Context, tenantCode string) (*Usage, error) {
+	usage := &Usage{}
+	for kind, target := range map[string]*int64{
+		Members:          &usage.Members,
+		OrdersPerMonth:   &usage.OrdersThisMonth,
+		StorageBytes:     &usage.StorageBytes,
+		APICallsPerMonth: &usage.APICallsThisMonth,
+	} {
+		used, err := Used(ctx, tenantCode, kind)
+		if err != nil {
+			return nil, err
+		}
+		*target = used
+	}
+	return usage, nil
+}
+
+// Used 配额项的当前使用量（成员、订单、存储实时统计，API调用次数从 Redis 计数读取）
+func Used(ctx context.Context, tenantCode, kind string) (int64, error) {
+	ctx = tenantCtx.WithTenantCode(ctx, tenantCode)
+	switch kind {
+	case Members:
+		return repository.NewTenantMemberRepository().Count(ctx, bson.M{"status": "active", "is_deleted": 0})
+	case OrdersPerMonth:
+		return repository.NewOrderRepository().Count(ctx, bson.M{
+			"is_deleted": 0,
+			"created_at": bson.M{"$gte": monthStart(time.Now()).Unix()},
+		})
+	case StorageBytes:
+		return storageUsed(ctx, tenantCode)
+	case APICallsPerMonth:
+		if !cache.RedisEnabled() {
+			return 0, nil
+		}
+		n, err := cache.GetRedis().Get(ctx, apiCallsKey(tenantCode, time.Now())).Int64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return 0, err
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("未知的配额项: %s", kind)
+}
+
+// RecordAPICall 记录一次经网关的请求，返回本月累计次数（未启用 Redis 时不计数，返回0）
+func RecordAPICall(ctx context.Context, tenantCode string) (int64, error) {
+	if !cache.RedisEnabled() {
+		return 0, nil
+	}
+	now := time.Now()
+	key := apiCallsKey(tenantCode, now)
+	n, err := cache.Incr(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		// 保留到下个月结束，便于查看上月用量
+		_ = cache.Expire(ctx, key, monthStart(now).AddDate(0, 2, 0).Sub(now))
+	}
+	return n, nil
+}
+
+// storageUsed 租户文件总大小
+func storageUsed(ctx context.Context, tenantCode string) (int64, error) {
+	collection := database.GetDatabaseManager().GetDatabase(tenantCode).Collection(models.FileInfo{}.TableName())
+	cursor, err := collection.Aggregate(ctx, []bson.M{
+		{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": "$file_size"}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Total int64 `bson:"total"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return 0, nil
+	}
+	return result[0].Total, nil
+}
+
+func apiCallsKey(tenantCode string, now time.Time) string {
+	return apiCallsKeyPrefix + tenantCode + ":" + strconv.Itoa(now.Year()*100+int(now.Month()))
+}
+
+func monthStart(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
+
+func kindName(kind string) string {
+	switch kind {
+	case Members:
+		return "成员数"
+	case OrdersPerMonth:
+		return "本月订单数"
+	case StorageBytes:
+		return "存储空间（字节）"
+	case APICallsPerMonth:
+		return "本月API调用次数"
+	}
+	return kind
+}
@@ -0,0 +1,52 @@
+package quota
+
+import (
+	"testing"
+
+	"mule-cloud/internal/models"
+)
+
+// TestSubscriptionState 到期后进入宽限期，超过宽限期为过期；长期有效和未订阅不会过期
+func TestSubscriptionState(t *testing.T) {
+	const day = 86400
+	sub := models.TenantSubscription{PlanCode: "basic", ExpiresAt: 10 * day, GraceDays: 3}
+	for _, tc := range []struct {
+		now  int64
+		want string
+	}{
+		{9 * day, models.SubscriptionActive},
+		{10 * day, models.SubscriptionGrace},
+		{13*day - 1, models.SubscriptionGrace},
+		{13 * day, models.SubscriptionExpired},
+	} {
+		if got := sub.State(tc.now); got != tc.want {
+			t.Errorf("State(%d) = %s, want %s", tc.now, got, tc.want)
+		}
+	}
+
+	if got := (&models.TenantSubscription{PlanCode: "basic"}).State(1 << 40); got != models.SubscriptionActive {
+		t.Errorf("no expiry State() = %s, want active", got)
+	}
+	if got := (&models.TenantSubscription{ExpiresAt: 1}).State(2); got != models.SubscriptionNone {
+		t.Errorf("no plan State() = %s, want none", got)
+	}
+}
+
+// TestEntitlementLimits 未订阅不限制；0 表示不限制
+func TestEntitlementLimits(t *testing.T) {
+	none := &Entitlement{}
+	if none.Limit(Members) != 0 || !none.HasFeature(models.FeatureQuality) {
+		t.Errorf("no plan should be unlimited")
+	}
+
+	e := &Entitlement{Plan: &models.Plan{
+		Limits:   models.PlanLimits{MaxMembers: 20, MaxStorageBytes: 1 << 30},
+		Features: []string{models.FeatureQuality},
+	}}
+	if e.Limit(Members) != 20 || e.Limit(StorageBytes) != 1<<30 || e.Limit(OrdersPerMonth) != 0 {
+		t.Errorf("Limit() = %d/%d/%d", e.Limit(Members), e.Limit(StorageBytes), e.Limit(OrdersPerMonth))
+	}
+	if !e.HasFeature(models.FeatureQuality) || e.HasFeature(models.FeatureWorkflowDesigner) {
+		t.Errorf("HasFeature() mismatch for features %v", e.Plan.Features)
+	}
+}
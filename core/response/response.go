@@ -2,13 +2,13 @@ package response
 
 import (
 	"fmt"
-	"log"
+	"mule-cloud/core/logger"
 	"net/http"
-	"runtime/debug"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // Response 统一响应结构
@@ -29,6 +29,7 @@ const (
 	CodeNotFound           = 404
 	CodeMethodNotAllowed   = 405
 	CodeConflict           = 409
+	CodeCaptchaRequired    = 428 // 需要验证码（前端据此展示验证码）
 	CodeTooManyRequests    = 429
 	CodeInternalError      = 500
 	CodeServiceUnavailable = 503
@@ -134,6 +135,8 @@ func getHTTPStatus(code int) int {
 		return http.StatusMethodNotAllowed
 	case CodeConflict:
 		return http.StatusConflict
+	case CodeCaptchaRequired:
+		return http.StatusPreconditionRequired
 	case CodeTooManyRequests:
 		return http.StatusTooManyRequests
 	case CodeInternalError:
@@ -173,7 +176,11 @@ func RecoveryMiddleware() gin.HandlerFunc {
 				}
 
 				// 记录错误日志
-				log.Printf("[Panic恢复] 错误: %v\n堆栈:\n%s", err, string(debug.Stack()))
+				logger.WithContext(c.Request.Context()).Error("请求处理发生panic",
+					zap.Any("panic", err),
+					zap.String("method", c.Request.Method),
+					zap.String("path", c.Request.URL.Path),
+					zap.Stack("stack"))
 
 				// 返回统一错误响应
 				InternalError(c, fmt.Sprintf("服务器内部错误: %v", err))
@@ -0,0 +1,128 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"mule-cloud/core/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// challengeKey 登录第二步的挑战（按令牌哈希保存），过期时间 = 挑战有效期
+const challengeKey = "auth:2fa_challenge:"
+
+// challengeAttemptsKey 挑战的验证码错误次数（INCR 原子计数，与挑战同时过期）
+const challengeAttemptsKey = "auth:2fa_attempts:"
+
+// MaxChallengeAttempts 同一个挑战允许输错验证码的次数，超过后需要重新输入密码
+const MaxChallengeAttempts = 5
+
+var (
+	ErrChallengeUnavailable = errors.New("两步验证需要启用Redis")
+	ErrChallengeInvalid     = errors.New("两步验证已过期或错误次数过多，请重新登录")
+)
+
+// Challenge 密码校验通过、等待两步验证的登录
+type Challenge struct {
+	UserID        string `json:"user_id"`
+	Phone         string `json:"phone"`
+	TenantID      string `json:"tenant_id"`
+	TenantCode    string `json:"tenant_code"`
+	Device        string `json:"device"`
+	UserAgent     string `json:"user_agent"`
+	IP            string `json:"ip"`
+	PendingSecret string `json:"pending_secret,omitempty"` // 租户强制两步验证但用户尚未绑定时，本次登录生成的密钥
+	ExpiresAt     int64  `json:"expires_at"`
+}
+
+// CreateChallenge 保存挑战并返回挑战令牌
+func CreateChallenge(ctx context.Context, c *Challenge, ttl time.Duration) (string, error) {
+	if !cache.RedisEnabled() {
+		return "", ErrChallengeUnavailable
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成挑战令牌失败: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	c.ExpiresAt = time.Now().Add(ttl).Unix()
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	if err := cache.GetRedis().Set(ctx, challengeKey+hashChallenge(token), data, ttl).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// GetChallenge 获取挑战
+func GetChallenge(ctx context.Context, token string) (*Challenge, error) {
+	if !cache.RedisEnabled() {
+		return nil, ErrChallengeUnavailable
+	}
+	if token == "" {
+		return nil, ErrChallengeInvalid
+	}
+	data, err := cache.GetRedis().Get(ctx, challengeKey+hashChallenge(token)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrChallengeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Challenge
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ChallengeFailed 记录一次验证码错误，返回剩余次数；次数用完后挑战作废
+//
+// 错误次数单独用 INCR 计数，并发提交错误验证码时不会互相覆盖
+func ChallengeFailed(ctx context.Context, token string, c *Challenge) (int, error) {
+	ttl := time.Until(time.Unix(c.ExpiresAt, 0))
+	if ttl <= 0 {
+		_, err := ConsumeChallenge(ctx, token)
+		return 0, err
+	}
+	attempts, err := incrWithin(ctx, challengeAttemptsKey+hashChallenge(token), ttl)
+	if err != nil {
+		return 0, err
+	}
+	remaining := MaxChallengeAttempts - attempts
+	if remaining <= 0 {
+		_, err := ConsumeChallenge(ctx, token)
+		return 0, err
+	}
+	return remaining, nil
+}
+
+// ConsumeChallenge 删除挑战，返回是否由本次调用删除（并发验证时只有一个请求能完成登录）
+func ConsumeChallenge(ctx context.Context, token string) (bool, error) {
+	if !cache.RedisEnabled() {
+		return false, ErrChallengeUnavailable
+	}
+	hash := hashChallenge(token)
+	n, err := cache.GetRedis().Del(ctx, challengeKey+hash).Result()
+	if err != nil {
+		return false, err
+	}
+	cache.GetRedis().Del(ctx, challengeAttemptsKey+hash)
+	return n > 0, nil
+}
+
+func hashChallenge(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestChallengeFailed 测试并发提交错误验证码时错误次数不丢失，次数用完后挑战作废
+func TestChallengeFailed(t *testing.T) {
+	useMiniredis(t)
+	ctx := context.Background()
+
+	token, err := CreateChallenge(ctx, &Challenge{UserID: "u1"}, time.Minute)
+	if err != nil {
+		t.Fatalf("CreateChallenge() error = %v", err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		remaining []int
+	)
+	for i := 0; i < MaxChallengeAttempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := GetChallenge(ctx, token)
+			if err != nil {
+				t.Errorf("GetChallenge() error = %v", err)
+				return
+			}
+			n, err := ChallengeFailed(ctx, token, c)
+			if err != nil {
+				t.Errorf("ChallengeFailed() error = %v", err)
+				return
+			}
+			mu.Lock()
+			remaining = append(remaining, n)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Ints(remaining)
+	for i, n := range remaining {
+		if n != i {
+			t.Fatalf("remaining = %v, want 0..%d", remaining, MaxChallengeAttempts-1)
+		}
+	}
+	if _, err := GetChallenge(ctx, token); !errors.Is(err, ErrChallengeInvalid) {
+		t.Fatalf("GetChallenge() error = %v, want ErrChallengeInvalid", err)
+	}
+}
@@ -0,0 +1,91 @@
+package security
+
+import (
+	"context"
+	"time"
+
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/logger"
+	"mule-cloud/internal/models"
+	"mule-cloud/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// EventResource 安全事件在操作日志中的资源名称，事件类型记录在 action 字段
+const EventResource = "security"
+
+// 安全事件类型
+const (
+	EventLoginSuccess    = "login_success"    // 登录成功
+	EventLoginFailed     = "login_failed"     // 登录失败（用户不存在或密码错误）
+	EventLoginBlocked    = "login_blocked"    // 账号/IP锁定期间或等待时间内尝试登录
+	EventCaptchaFailed   = "captcha_failed"   // 验证码缺失或错误
+	EventAccountLocked   = "account_locked"   // 账号被锁定
+	EventIPLocked        = "ip_locked"        // IP被锁定
+	EventAccountUnlocked = "account_unlocked" // 管理员解锁账号
+
+	EventTwoFactorFailed   = "two_factor_failed"   // 两步验证码错误
+	EventTwoFactorEnabled  = "two_factor_enabled"  // 启用两步验证
+	EventTwoFactorDisabled = "two_factor_disabled" // 关闭两步验证
+	EventTwoFactorReset    = "two_factor_reset"    // 管理员重置两步验证
+	EventRecoveryCodeUsed  = "recovery_code_used"  // 使用恢复码登录
+
+	EventAPIKeyRejected = "api_key_rejected" // API密钥不在IP白名单内或无权访问接口
+
+	EventSSOFailed      = "sso_failed"      // 单点登录失败（身份提供方校验失败、找不到账号等）
+	EventSSOLinked      = "sso_linked"      // 已有管理员首次通过单点登录关联企业身份
+	EventSSOProvisioned = "sso_provisioned" // 单点登录自动创建管理员
+
+	EventImpersonationStarted = "impersonation_started" // 超管开始代管租户
+	EventImpersonationEnded   = "impersonation_ended"   // 超管结束代管
+)
+
+// Event 安全事件
+type Event struct {
+	Type      string
+	UserID    string
+	Username  string // 登录手机号或操作人
+	Path      string
+	IP        string
+	UserAgent string
+	Detail    string // 失败原因等说明
+}
+
+// RecordEvent 把安全事件写入操作日志（租户事件写租户库，系统用户写系统库），异步执行不阻塞请求
+func RecordEvent(tenantCode string, e Event) {
+	now := time.Now()
+	code := 401
+	switch e.Type {
+	case EventLoginSuccess, EventAccountUnlocked, EventTwoFactorEnabled, EventTwoFactorDisabled,
+		EventTwoFactorReset, EventRecoveryCodeUsed, EventSSOLinked, EventSSOProvisioned,
+		EventImpersonationStarted, EventImpersonationEnded:
+		code = 200
+	case EventLoginBlocked, EventAccountLocked, EventIPLocked:
+		code = 429
+	case EventAPIKeyRejected:
+		code = 403
+	}
+	go func() {
+		ctx := tenantCtx.WithTenantCode(context.Background(), tenantCode)
+		log := &models.OperationLog{
+			UserID:       e.UserID,
+			Username:     e.Username,
+			Method:       "POST",
+			Path:         e.Path,
+			Resource:     EventResource,
+			Action:       e.Type,
+			ResponseCode: code,
+			IP:           e.IP,
+			UserAgent:    e.UserAgent,
+			Error:        e.Detail,
+			CreatedAt:    now,
+		}
+		if err := repository.NewOperationLogRepository().Create(ctx, log); err != nil {
+			logger.Error("保存安全事件失败",
+				zap.String("event", e.Type),
+				zap.String("username", e.Username),
+				zap.Error(err))
+		}
+	}()
+}
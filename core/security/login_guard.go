@@ -0,0 +1,278 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"mule-cloud/core/cache"
+	"mule-cloud/core/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 键（账号维度按 租户代码:手机号 区分）
+const (
+	accountFailKey = "login:fail:acct:"
+	accountWaitKey = "login:wait:acct:"
+	accountLockKey = "login:lock:acct:"
+	ipFailKey      = "login:fail:ip:"
+	ipLockKey      = "login:lock:ip:"
+)
+
+var (
+	ErrCaptchaRequired = errors.New("登录失败次数过多，请输入验证码")
+	ErrCaptchaInvalid  = errors.New("验证码错误或已过期")
+)
+
+// BlockedError 登录被暂时拒绝（账号锁定、IP锁定或需要等待）
+type BlockedError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("%s，请%s后再试", e.Reason, humanize(e.RetryAfter))
+}
+
+// LoginState 登录前检查的结果
+type LoginState struct {
+	Failures        int  // 账号在统计窗口内的失败次数
+	CaptchaRequired bool // 是否需要验证码
+}
+
+// LoginGuard 登录防暴力破解：按账号和IP统计失败次数，递增等待、锁定、要求验证码
+//
+// Redis 未启用时不做任何限制。
+type LoginGuard struct {
+	cfg config.LoginConfig
+}
+
+// NewLoginGuard 创建登录防护，未配置的项使用默认值
+func NewLoginGuard(cfg *config.LoginConfig) *LoginGuard {
+	c := config.LoginConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.FailureWindow <= 0 {
+		c.FailureWindow = 15
+	}
+	if c.MaxFailures <= 0 {
+		c.MaxFailures = 5
+	}
+	if c.LockMinutes <= 0 {
+		c.LockMinutes = 15
+	}
+	if c.IPMaxFailures == 0 {
+		c.IPMaxFailures = 20
+	}
+	if c.IPLockMinutes <= 0 {
+		c.IPLockMinutes = 30
+	}
+	if c.DelaySeconds <= 0 {
+		c.DelaySeconds = 1
+	}
+	if c.MaxDelaySeconds <= 0 {
+		c.MaxDelaySeconds = 30
+	}
+	if c.CaptchaAfter <= 0 {
+		c.CaptchaAfter = 3
+	}
+	return &LoginGuard{cfg: c}
+}
+
+// Check 登录前检查：IP或账号已锁定、等待时间未到时返回 *BlockedError
+func (g *LoginGuard) Check(ctx context.Context, tenantCode, phone, ip string) (*LoginState, error) {
+	state := &LoginState{}
+	if !cache.RedisEnabled() {
+		return state, nil
+	}
+	client := cache.GetRedis()
+	account := accountKey(tenantCode, phone)
+
+	if g.ipLimited() {
+		if ttl := client.PTTL(ctx, ipLockKey+ip).Val(); ttl > 0 {
+			return nil, &BlockedError{Reason: "该IP登录失败次数过多已被暂时禁止", RetryAfter: ttl}
+		}
+	}
+	if ttl := client.PTTL(ctx, accountLockKey+account).Val(); ttl > 0 {
+		return nil, &BlockedError{Reason: "账号已被锁定", RetryAfter: ttl}
+	}
+	if ttl := client.PTTL(ctx, accountWaitKey+account).Val(); ttl > 0 {
+		return nil, &BlockedError{Reason: "登录尝试过于频繁", RetryAfter: ttl}
+	}
+
+	failures, _ := client.Get(ctx, accountFailKey+account).Int()
+	ipFailures := 0
+	if g.ipLimited() {
+		ipFailures, _ = client.Get(ctx, ipFailKey+ip).Int()
+	}
+	state.Failures = failures
+	state.CaptchaRequired = g.cfg.CaptchaEnabled &&
+		(failures >= g.cfg.CaptchaAfter || ipFailures >= g.cfg.CaptchaAfter)
+	return state, nil
+}
+
+// FailResult 记录失败后的结果
+type FailResult struct {
+	Failures      int  // 账号失败次数
+	AccountLocked bool // 本次失败导致账号被锁定
+	IPLocked      bool // 本次失败导致IP被锁定
+}
+
+// Fail 记录一次登录失败（用户不存在也要记录，避免通过差异枚举账号）
+func (g *LoginGuard) Fail(ctx context.Context, tenantCode, phone, ip string) (*FailResult, error) {
+	result := &FailResult{}
+	if !cache.RedisEnabled() {
+		return result, nil
+	}
+	client := cache.GetRedis()
+	account := accountKey(tenantCode, phone)
+	window := time.Duration(g.cfg.FailureWindow) * time.Minute
+
+	accountFailures, err := incrWithin(ctx, accountFailKey+account, window)
+	if err != nil {
+		return result, err
+	}
+	result.Failures = accountFailures
+
+	if g.ipLimited() {
+		ipFailures, err := incrWithin(ctx, ipFailKey+ip, window)
+		if err != nil {
+			return result, err
+		}
+		if ipFailures >= g.cfg.IPMaxFailures {
+			result.IPLocked = true
+			lock := time.Duration(g.cfg.IPLockMinutes) * time.Minute
+			if err := client.Set(ctx, ipLockKey+ip, time.Now().Unix(), lock).Err(); err != nil {
+				return result, err
+			}
+			client.Del(ctx, ipFailKey+ip)
+		}
+	}
+
+	if result.Failures >= g.cfg.MaxFailures {
+		result.AccountLocked = true
+		lock := time.Duration(g.cfg.LockMinutes) * time.Minute
+		if err := client.Set(ctx, accountLockKey+account, time.Now().Unix(), lock).Err(); err != nil {
+			return result, err
+		}
+		client.Del(ctx, accountFailKey+account, accountWaitKey+account)
+		return result, nil
+	}
+
+	// 第2次失败起递增等待：base, 2*base, 4*base ... 不超过上限
+	if delay := g.delay(result.Failures); delay > 0 {
+		if err := client.Set(ctx, accountWaitKey+account, 1, delay).Err(); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// incrWithinScript 计数加一，第一次计数时设置统计窗口（脚本内执行，避免计数键没有过期时间）
+var incrWithinScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 or redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// incrWithin 计数加一，第一次计数时设置统计窗口
+func incrWithin(ctx context.Context, key string, window time.Duration) (int, error) {
+	n, err := incrWithinScript.Run(ctx, cache.GetRedis(), []string{key}, window.Milliseconds()).Int()
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ipLimited 是否按IP计数和锁定（ip_max_failures 为负数时关闭，识别不到真实客户端IP时使用）
+func (g *LoginGuard) ipLimited() bool {
+	return g.cfg.IPMaxFailures > 0
+}
+
+// Succeed 登录成功后清除账号的失败记录（IP计数保留，避免用已知账号重置）
+func (g *LoginGuard) Succeed(ctx context.Context, tenantCode, phone string) {
+	if !cache.RedisEnabled() {
+		return
+	}
+	account := accountKey(tenantCode, phone)
+	cache.GetRedis().Del(ctx, accountFailKey+account, accountWaitKey+account)
+}
+
+// delay 第 n 次失败后需要等待的时间
+func (g *LoginGuard) delay(failures int) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+	seconds := float64(g.cfg.DelaySeconds) * math.Pow(2, float64(failures-2))
+	if seconds > float64(g.cfg.MaxDelaySeconds) {
+		seconds = float64(g.cfg.MaxDelaySeconds)
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// LockStatus 账号锁定状态
+type LockStatus struct {
+	Locked     bool  `json:"locked"`
+	Failures   int   `json:"failures"`    // 统计窗口内的失败次数
+	RetryAfter int64 `json:"retry_after"` // 剩余锁定秒数
+}
+
+// AccountLockStatus 查询账号锁定状态
+func AccountLockStatus(ctx context.Context, tenantCode, phone string) (*LockStatus, error) {
+	status := &LockStatus{}
+	if !cache.RedisEnabled() {
+		return status, nil
+	}
+	client := cache.GetRedis()
+	account := accountKey(tenantCode, phone)
+
+	ttl, err := client.PTTL(ctx, accountLockKey+account).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	if ttl > 0 {
+		status.Locked = true
+		status.RetryAfter = int64(math.Ceil(ttl.Seconds()))
+	}
+	status.Failures, _ = client.Get(ctx, accountFailKey+account).Int()
+	return status, nil
+}
+
+// UnlockAccount 管理员解锁账号（同时清除失败次数和等待时间），返回解锁前是否处于锁定状态
+func UnlockAccount(ctx context.Context, tenantCode, phone string) (bool, error) {
+	if !cache.RedisEnabled() {
+		return false, nil
+	}
+	account := accountKey(tenantCode, phone)
+	client := cache.GetRedis()
+	locked, err := client.Exists(ctx, accountLockKey+account).Result()
+	if err != nil {
+		return false, err
+	}
+	if err := client.Del(ctx, accountLockKey+account, accountFailKey+account, accountWaitKey+account).Err(); err != nil {
+		return false, err
+	}
+	return locked > 0, nil
+}
+
+// accountKey 系统库用户的租户代码可能为空或 system，统一为 system
+func accountKey(tenantCode, phone string) string {
+	if tenantCode == "" {
+		tenantCode = "system"
+	}
+	return tenantCode + ":" + phone
+}
+
+// humanize 把等待时间转换为"N分钟"/"N秒"
+func humanize(d time.Duration) string {
+	if d >= time.Minute {
+		return fmt.Sprintf("%d分钟", int(math.Ceil(d.Minutes())))
+	}
+	return fmt.Sprintf("%d秒", int(math.Ceil(d.Seconds())))
+}
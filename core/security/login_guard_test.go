@@ -0,0 +1,176 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mule-cloud/core/cache"
+	"mule-cloud/core/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// useMiniredis 用内存 Redis 替换全局客户端
+func useMiniredis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	cache.SetRedis(client)
+	t.Cleanup(func() {
+		cache.SetRedis(nil)
+		client.Close()
+	})
+	return mr
+}
+
+// TestIncrWithin 测试计数和统计窗口：第一次计数设置过期时间，之后不延长；缺失过期时间的旧键会补上
+func TestIncrWithin(t *testing.T) {
+	mr := useMiniredis(t)
+	ctx := context.Background()
+
+	for want := 1; want <= 3; want++ {
+		n, err := incrWithin(ctx, "k", time.Minute)
+		if err != nil {
+			t.Fatalf("incrWithin() error = %v", err)
+		}
+		if n != want {
+			t.Fatalf("incrWithin() = %d, want %d", n, want)
+		}
+		mr.FastForward(10 * time.Second)
+	}
+	if ttl := mr.TTL("k"); ttl != 30*time.Second {
+		t.Fatalf("TTL = %v, want 30s (window not extended)", ttl)
+	}
+
+	mr.FastForward(30 * time.Second)
+	if mr.Exists("k") {
+		t.Fatal("counter should expire after the window")
+	}
+
+	mr.Set("stale", "7")
+	n, err := incrWithin(ctx, "stale", time.Minute)
+	if err != nil || n != 8 {
+		t.Fatalf("incrWithin(stale) = %d, %v, want 8", n, err)
+	}
+	if ttl := mr.TTL("stale"); ttl != time.Minute {
+		t.Fatalf("TTL(stale) = %v, want 1m", ttl)
+	}
+}
+
+// TestLoginGuardAccountLock 测试账号失败达到上限后锁定，解锁后恢复
+func TestLoginGuardAccountLock(t *testing.T) {
+	mr := useMiniredis(t)
+	ctx := context.Background()
+	g := NewLoginGuard(&config.LoginConfig{MaxFailures: 3, DelaySeconds: 1, CaptchaEnabled: true, CaptchaAfter: 2})
+
+	for i := 1; i <= 2; i++ {
+		res, err := g.Fail(ctx, "t1", "13800000000", "10.0.0.1")
+		if err != nil {
+			t.Fatalf("Fail() error = %v", err)
+		}
+		if res.Failures != i || res.AccountLocked {
+			t.Fatalf("Fail() #%d = %+v", i, res)
+		}
+	}
+
+	// 第2次失败后需要等待
+	var blocked *BlockedError
+	if _, err := g.Check(ctx, "t1", "13800000000", "10.0.0.1"); !errors.As(err, &blocked) || blocked.Reason != "登录尝试过于频繁" {
+		t.Fatalf("Check() error = %v, want wait", err)
+	}
+	mr.FastForward(time.Second)
+	state, err := g.Check(ctx, "t1", "13800000000", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if state.Failures != 2 || !state.CaptchaRequired {
+		t.Fatalf("Check() = %+v, want 2 failures with captcha", state)
+	}
+
+	res, err := g.Fail(ctx, "t1", "13800000000", "10.0.0.1")
+	if err != nil || !res.AccountLocked {
+		t.Fatalf("Fail() #3 = %+v, %v, want locked", res, err)
+	}
+	if _, err := g.Check(ctx, "t1", "13800000000", "10.0.0.1"); !errors.As(err, &blocked) || blocked.Reason != "账号已被锁定" {
+		t.Fatalf("Check() error = %v, want account locked", err)
+	}
+
+	locked, err := UnlockAccount(ctx, "t1", "13800000000")
+	if err != nil || !locked {
+		t.Fatalf("UnlockAccount() = %v, %v, want true", locked, err)
+	}
+	if _, err := g.Check(ctx, "t1", "13800000000", "10.0.0.1"); err != nil {
+		t.Fatalf("Check() after unlock error = %v", err)
+	}
+}
+
+// TestLoginGuardIPLock 测试同一IP对不同账号的失败累计后锁定IP
+func TestLoginGuardIPLock(t *testing.T) {
+	useMiniredis(t)
+	ctx := context.Background()
+	g := NewLoginGuard(&config.LoginConfig{IPMaxFailures: 3})
+
+	phones := []string{"13800000001", "13800000002", "13800000003"}
+	var res *FailResult
+	for _, phone := range phones {
+		var err error
+		if res, err = g.Fail(ctx, "t1", phone, "10.0.0.9"); err != nil {
+			t.Fatalf("Fail() error = %v", err)
+		}
+	}
+	if !res.IPLocked || res.AccountLocked {
+		t.Fatalf("Fail() = %+v, want IP locked only", res)
+	}
+
+	var blocked *BlockedError
+	if _, err := g.Check(ctx, "t1", "13900000000", "10.0.0.9"); !errors.As(err, &blocked) || blocked.RetryAfter != 30*time.Minute {
+		t.Fatalf("Check() error = %v, want IP locked for 30m", err)
+	}
+	if _, err := g.Check(ctx, "t1", "13900000000", "10.0.0.10"); err != nil {
+		t.Fatalf("Check() other IP error = %v", err)
+	}
+}
+
+// TestLoginGuardDelay 测试递增等待时间
+func TestLoginGuardDelay(t *testing.T) {
+	g := NewLoginGuard(&config.LoginConfig{DelaySeconds: 2, MaxDelaySeconds: 10})
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 0},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := g.delay(tt.failures); got != tt.want {
+			t.Errorf("delay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+// TestLoginGuardIPDisabled 测试关闭按IP限制后，同一IP的失败不计数也不锁定
+func TestLoginGuardIPDisabled(t *testing.T) {
+	mr := useMiniredis(t)
+	ctx := context.Background()
+	g := NewLoginGuard(&config.LoginConfig{IPMaxFailures: -1, CaptchaEnabled: true, CaptchaAfter: 2})
+
+	for _, phone := range []string{"13800000001", "13800000002", "13800000003"} {
+		res, err := g.Fail(ctx, "t1", phone, "10.0.0.9")
+		if err != nil || res.IPLocked {
+			t.Fatalf("Fail() = %+v, %v, want no IP lock", res, err)
+		}
+	}
+	if mr.Exists(ipFailKey + "10.0.0.9") {
+		t.Fatal("IP failures should not be counted")
+	}
+	state, err := g.Check(ctx, "t1", "13900000000", "10.0.0.9")
+	if err != nil || state.CaptchaRequired {
+		t.Fatalf("Check() = %+v, %v, want no captcha for a fresh account", state, err)
+	}
+}
@@ -0,0 +1,64 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"mule-cloud/core/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ssoStateKey 单点登录跳转前保存的状态（按 state 哈希保存，回调时一次性取出）
+const ssoStateKey = "auth:sso_state:"
+
+var (
+	ErrSSOUnavailable  = errors.New("单点登录需要启用Redis")
+	ErrSSOStateInvalid = errors.New("单点登录已过期或重复提交，请重新登录")
+)
+
+// SSOState 跳转到身份提供方前的登录状态
+type SSOState struct {
+	TenantCode   string `json:"tenant_code"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"` // PKCE
+	Device       string `json:"device"`
+	ExpiresAt    int64  `json:"expires_at"`
+}
+
+// SaveSSOState 以 state 为键保存登录状态
+func SaveSSOState(ctx context.Context, state string, s *SSOState, ttl time.Duration) error {
+	if !cache.RedisEnabled() {
+		return ErrSSOUnavailable
+	}
+	s.ExpiresAt = time.Now().Add(ttl).Unix()
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return cache.GetRedis().Set(ctx, ssoStateKey+hashChallenge(state), data, ttl).Err()
+}
+
+// ConsumeSSOState 取出并删除登录状态（每个 state 只能使用一次）
+func ConsumeSSOState(ctx context.Context, state string) (*SSOState, error) {
+	if !cache.RedisEnabled() {
+		return nil, ErrSSOUnavailable
+	}
+	if state == "" {
+		return nil, ErrSSOStateInvalid
+	}
+	data, err := cache.GetRedis().GetDel(ctx, ssoStateKey+hashChallenge(state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrSSOStateInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s SSOState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
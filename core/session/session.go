@@ -0,0 +1,322 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"mule-cloud/core/cache"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 键
+//
+//	auth:session:<sid>                   会话详情（JSON），过期时间 = 刷新令牌有效期
+//	auth:user_sessions:<tenant>:<userID> 用户的会话ID集合
+//	auth:refresh:<sha256>                刷新令牌 -> 会话ID（一次性）
+//	auth:refresh_used:<sha256>           已轮换的刷新令牌 -> 会话ID（用于发现令牌被盗用）
+//	auth:denied_jti:<jti>                已吊销的访问令牌，过期时间 = 令牌剩余有效期
+const (
+	sessionKeyPrefix     = "auth:session:"
+	userSessionsPrefix   = "auth:user_sessions:"
+	refreshKeyPrefix     = "auth:refresh:"
+	refreshUsedKeyPrefix = "auth:refresh_used:"
+	deniedKeyPrefix      = "auth:denied_jti:"
+)
+
+var (
+	ErrUnavailable    = errors.New("会话存储未启用（需要Redis）")
+	ErrInvalidRefresh = errors.New("刷新令牌无效或已过期")
+	ErrRefreshReused  = errors.New("刷新令牌已被使用，会话已注销，请重新登录")
+	ErrNotFound       = errors.New("会话不存在或已过期")
+)
+
+// Session 登录会话（一次登录对应一个会话，刷新令牌轮换时会话ID不变）
+type Session struct {
+	ID              string   `json:"id"`
+	UserID          string   `json:"user_id"`
+	Username        string   `json:"username"`
+	TenantID        string   `json:"tenant_id"`
+	TenantCode      string   `json:"tenant_code"`
+	Roles           []string `json:"roles"`
+	MFA             bool     `json:"mfa,omitempty"`     // 登录时是否通过了两步验证（刷新后保留）
+	Device          string   `json:"device"`            // 设备名称（客户端上报）
+	UserAgent       string   `json:"user_agent"`        // User-Agent
+	IP              string   `json:"ip"`                // 最近一次使用的IP
+	RefreshHash     string   `json:"refresh_hash"`      // 当前刷新令牌的SHA-256
+	AccessJTI       string   `json:"access_jti"`        // 最近签发的访问令牌ID
+	AccessExpiresAt int64    `json:"access_expires_at"` // 最近签发的访问令牌过期时间
+	CreatedAt       int64    `json:"created_at"`
+	LastSeenAt      int64    `json:"last_seen_at"`
+	ExpiresAt       int64    `json:"expires_at"` // 刷新令牌过期时间
+}
+
+// Enabled 会话存储是否可用
+func Enabled() bool {
+	return cache.RedisEnabled()
+}
+
+// NewID 生成会话ID
+func NewID() string {
+	return uuid.NewString()
+}
+
+// Create 保存新会话并返回刷新令牌（明文只返回给客户端，服务端只存哈希）
+func Create(ctx context.Context, s *Session, ttl time.Duration) (string, error) {
+	if !Enabled() {
+		return "", ErrUnavailable
+	}
+	if s.ID == "" {
+		s.ID = NewID()
+	}
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	s.RefreshHash = hashToken(refreshToken)
+	s.CreatedAt = now.Unix()
+	s.LastSeenAt = now.Unix()
+	s.ExpiresAt = now.Add(ttl).Unix()
+
+	client := cache.GetRedis()
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	userKey := userSessionsKey(s.TenantCode, s.UserID)
+	pipe := client.TxPipeline()
+	pipe.Set(ctx, sessionKeyPrefix+s.ID, data, ttl)
+	pipe.Set(ctx, refreshKeyPrefix+s.RefreshHash, s.ID, ttl)
+	pipe.SAdd(ctx, userKey, s.ID)
+	pipe.Expire(ctx, userKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("保存会话失败: %w", err)
+	}
+	return refreshToken, nil
+}
+
+// Rotate 使用刷新令牌换取新的刷新令牌
+//
+// 刷新令牌只能使用一次；已轮换的旧令牌再次出现说明令牌可能被盗用，整个会话会被注销。
+// 调用方签发新的访问令牌后需调用 SetAccess 记录令牌ID。
+func Rotate(ctx context.Context, refreshToken, ip string, ttl time.Duration) (*Session, string, error) {
+	if !Enabled() {
+		return nil, "", ErrUnavailable
+	}
+	client := cache.GetRedis()
+	hash := hashToken(refreshToken)
+
+	// GETDEL 保证并发请求中只有一个能成功轮换
+	sessionID, err := client.GetDel(ctx, refreshKeyPrefix+hash).Result()
+	if errors.Is(err, redis.Nil) {
+		if reusedID, err := client.Get(ctx, refreshUsedKeyPrefix+hash).Result(); err == nil {
+			_ = Revoke(ctx, reusedID)
+			return nil, "", ErrRefreshReused
+		}
+		return nil, "", ErrInvalidRefresh
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	s, err := Get(ctx, sessionID)
+	if err != nil {
+		return nil, "", ErrInvalidRefresh
+	}
+
+	newToken, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	s.RefreshHash = hashToken(newToken)
+	s.LastSeenAt = now.Unix()
+	s.ExpiresAt = now.Add(ttl).Unix()
+	if ip != "" {
+		s.IP = ip
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, "", err
+	}
+	userKey := userSessionsKey(s.TenantCode, s.UserID)
+	pipe := client.TxPipeline()
+	pipe.Set(ctx, sessionKeyPrefix+s.ID, data, ttl)
+	pipe.Set(ctx, refreshKeyPrefix+s.RefreshHash, s.ID, ttl)
+	pipe.Set(ctx, refreshUsedKeyPrefix+hash, s.ID, ttl)
+	pipe.Expire(ctx, userKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, "", fmt.Errorf("保存会话失败: %w", err)
+	}
+	return s, newToken, nil
+}
+
+// SetAccess 记录会话最新签发的访问令牌，之前签发的访问令牌同时吊销
+func SetAccess(ctx context.Context, sessionID, jti string, expiresAt time.Time) error {
+	if !Enabled() {
+		return ErrUnavailable
+	}
+	s, err := Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if s.AccessJTI != "" && s.AccessJTI != jti {
+		if err := Deny(ctx, s.AccessJTI, time.Unix(s.AccessExpiresAt, 0)); err != nil {
+			return err
+		}
+	}
+	s.AccessJTI = jti
+	s.AccessExpiresAt = expiresAt.Unix()
+	return save(ctx, s)
+}
+
+// Get 获取会话
+func Get(ctx context.Context, sessionID string) (*Session, error) {
+	if !Enabled() {
+		return nil, ErrUnavailable
+	}
+	data, err := cache.GetRedis().Get(ctx, sessionKeyPrefix+sessionID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List 获取用户的有效会话（按最近使用时间倒序），顺带清理已过期的会话ID
+func List(ctx context.Context, tenantCode, userID string) ([]*Session, error) {
+	if !Enabled() {
+		return nil, ErrUnavailable
+	}
+	client := cache.GetRedis()
+	userKey := userSessionsKey(tenantCode, userID)
+	ids, err := client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*Session, 0, len(ids))
+	for _, id := range ids {
+		s, err := Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			client.SRem(ctx, userKey, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt > sessions[j].LastSeenAt
+	})
+	return sessions, nil
+}
+
+// Revoke 注销会话：删除刷新令牌并吊销当前访问令牌
+func Revoke(ctx context.Context, sessionID string) error {
+	s, err := Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if s.AccessJTI != "" {
+		if err := Deny(ctx, s.AccessJTI, time.Unix(s.AccessExpiresAt, 0)); err != nil {
+			return err
+		}
+	}
+	pipe := cache.GetRedis().TxPipeline()
+	pipe.Del(ctx, sessionKeyPrefix+s.ID, refreshKeyPrefix+s.RefreshHash)
+	pipe.SRem(ctx, userSessionsKey(s.TenantCode, s.UserID), s.ID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RevokeUser 注销用户的全部会话（禁用、删除用户或重置密码时调用），返回注销的会话数
+func RevokeUser(ctx context.Context, tenantCode, userID string) (int, error) {
+	if !Enabled() {
+		return 0, ErrUnavailable
+	}
+	sessions, err := List(ctx, tenantCode, userID)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, s := range sessions {
+		if err := Revoke(ctx, s.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Deny 吊销访问令牌直到其过期
+func Deny(ctx context.Context, jti string, expiresAt time.Time) error {
+	if !Enabled() {
+		return ErrUnavailable
+	}
+	ttl := time.Until(expiresAt)
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	return cache.GetRedis().Set(ctx, deniedKeyPrefix+jti, 1, ttl).Err()
+}
+
+// IsDenied 访问令牌是否已被吊销（Redis 未启用或不可用时视为未吊销）
+func IsDenied(ctx context.Context, jti string) bool {
+	if jti == "" || !Enabled() {
+		return false
+	}
+	n, err := cache.GetRedis().Exists(ctx, deniedKeyPrefix+jti).Result()
+	return err == nil && n > 0
+}
+
+// save 覆盖保存会话，保留原有过期时间
+func save(ctx context.Context, s *Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return cache.GetRedis().SetArgs(ctx, sessionKeyPrefix+s.ID, data, redis.SetArgs{KeepTTL: true}).Err()
+}
+
+// userSessionsKey 系统库用户的租户代码可能为空或 system，统一为 system
+func userSessionsKey(tenantCode, userID string) string {
+	if tenantCode == "" {
+		tenantCode = "system"
+	}
+	return userSessionsPrefix + tenantCode + ":" + userID
+}
+
+// newRefreshToken 生成256位随机刷新令牌
+func newRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成刷新令牌失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken 刷新令牌只保存哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
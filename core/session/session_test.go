@@ -0,0 +1,117 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mule-cloud/core/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// useMiniredis 用内存 Redis 替换全局客户端
+func useMiniredis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	cache.SetRedis(client)
+	t.Cleanup(func() {
+		cache.SetRedis(nil)
+		client.Close()
+	})
+	return mr
+}
+
+// TestRotate 测试刷新令牌轮换：新令牌可用，旧令牌再次使用时注销整个会话
+func TestRotate(t *testing.T) {
+	useMiniredis(t)
+	ctx := context.Background()
+	ttl := time.Hour
+
+	s := &Session{UserID: "u1", TenantCode: "t1", Roles: []string{"admin"}, MFA: true}
+	first, err := Create(ctx, s, ttl)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	rotated, second, err := Rotate(ctx, first, "10.0.0.2", ttl)
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if rotated.ID != s.ID || second == first || !rotated.MFA || rotated.IP != "10.0.0.2" {
+		t.Fatalf("rotated = %+v, want same session with new token, MFA kept and IP updated", rotated)
+	}
+
+	// 正常轮换链：新令牌只能用一次
+	_, third, err := Rotate(ctx, second, "", ttl)
+	if err != nil {
+		t.Fatalf("Rotate(second) error = %v", err)
+	}
+
+	// 已轮换的旧令牌再次出现：判定为盗用，会话注销，最新令牌也失效
+	if _, _, err := Rotate(ctx, first, "", ttl); !errors.Is(err, ErrRefreshReused) {
+		t.Fatalf("reuse of rotated token: err = %v, want ErrRefreshReused", err)
+	}
+	if _, err := Get(ctx, s.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("session after reuse: err = %v, want ErrNotFound", err)
+	}
+	if _, _, err := Rotate(ctx, third, "", ttl); err == nil {
+		t.Error("latest token still works after reuse was detected")
+	}
+	if sessions, _ := List(ctx, "t1", "u1"); len(sessions) != 0 {
+		t.Errorf("sessions after reuse = %v, want none", sessions)
+	}
+}
+
+// TestRotateInvalid 测试未知或过期的刷新令牌
+func TestRotateInvalid(t *testing.T) {
+	mr := useMiniredis(t)
+	ctx := context.Background()
+
+	if _, _, err := Rotate(ctx, "unknown", "", time.Hour); !errors.Is(err, ErrInvalidRefresh) {
+		t.Errorf("unknown token: err = %v, want ErrInvalidRefresh", err)
+	}
+
+	token, err := Create(ctx, &Session{UserID: "u1"}, time.Minute)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	mr.FastForward(2 * time.Minute)
+	if _, _, err := Rotate(ctx, token, "", time.Minute); !errors.Is(err, ErrInvalidRefresh) {
+		t.Errorf("expired token: err = %v, want ErrInvalidRefresh", err)
+	}
+}
+
+// TestDeny 测试访问令牌吊销和会话注销
+func TestDeny(t *testing.T) {
+	useMiniredis(t)
+	ctx := context.Background()
+
+	s := &Session{UserID: "u1"}
+	if _, err := Create(ctx, s, time.Hour); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := SetAccess(ctx, s.ID, "jti-1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("SetAccess() error = %v", err)
+	}
+	if IsDenied(ctx, "jti-1") {
+		t.Fatal("current access token denied")
+	}
+	// 签发新的访问令牌后旧令牌吊销
+	if err := SetAccess(ctx, s.ID, "jti-2", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("SetAccess() error = %v", err)
+	}
+	if !IsDenied(ctx, "jti-1") || IsDenied(ctx, "jti-2") {
+		t.Error("previous access token should be denied after reissue")
+	}
+
+	if n, err := RevokeUser(ctx, "", "u1"); err != nil || n != 1 {
+		t.Fatalf("RevokeUser() = %d, %v", n, err)
+	}
+	if !IsDenied(ctx, "jti-2") {
+		t.Error("access token of revoked session still accepted")
+	}
+}
@@ -0,0 +1,83 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCode 使用 RFC 6238 附录B的 SHA1 测试向量（取后6位）
+func TestCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := Code(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+// TestValidate 测试时钟偏差和防重放
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	code, _ := Code(secret, now.Add(-Period*time.Second))
+
+	step, ok := Validate(secret, code, now, 0)
+	if !ok || step != now.Unix()/Period-1 {
+		t.Fatalf("Validate() = %d, %v, want previous step", step, ok)
+	}
+	if _, ok := Validate(secret, code, now, step); ok {
+		t.Error("Validate() should reject a replayed code")
+	}
+	old, _ := Code(secret, now.Add(-3*Period*time.Second))
+	if _, ok := Validate(secret, old, now, 0); ok {
+		t.Error("Validate() should reject a code outside the skew window")
+	}
+	if _, ok := Validate(secret, "12345", now, 0); ok {
+		t.Error("Validate() should reject a short code")
+	}
+}
+
+// TestRecoveryCodes 测试恢复码生成与匹配
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("GenerateRecoveryCodes() returned %d codes", len(codes))
+	}
+	if i := MatchRecoveryCode(strings.ToUpper(codes[3]), hashes); i != 3 {
+		t.Errorf("MatchRecoveryCode() = %d, want 3", i)
+	}
+	if i := MatchRecoveryCode("aaaaa-aaaaa", hashes); i != -1 {
+		t.Errorf("MatchRecoveryCode() = %d, want -1", i)
+	}
+}
+
+// TestProvisioningURI 测试绑定地址格式
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Mule Cloud", "13800138000", "JBSWY3DPEHPK3PXP")
+	want := "otpauth://totp/Mule%20Cloud:13800138000?algorithm=SHA1&digits=6&issuer=Mule+Cloud&period=30&secret=JBSWY3DPEHPK3PXP"
+	if uri != want {
+		t.Errorf("ProvisioningURI() = %s, want %s", uri, want)
+	}
+}
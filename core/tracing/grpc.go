@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+
+	tenantCtx "mule-cloud/core/context"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDMetadata gRPC 元数据中的请求ID
+const requestIDMetadata = "x-request-id"
+
+// GRPCServerOptions gRPC 服务端追踪（服务端 span 和请求ID）
+func GRPCServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(requestIDServerInterceptor),
+	}
+}
+
+// GRPCDialOptions gRPC 客户端追踪（注入 traceparent 和请求ID）
+func GRPCDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(requestIDClientInterceptor),
+	}
+}
+
+func requestIDServerInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadata); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = NewRequestID()
+	}
+	return handler(tenantCtx.WithRequestID(ctx, requestID), req)
+}
+
+func requestIDClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if requestID := tenantCtx.GetRequestID(ctx); requestID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, requestIDMetadata, requestID)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
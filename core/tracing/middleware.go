@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	tenantCtx "mule-cloud/core/context"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// routeKey gin context 中的 span 路由名（网关按匹配到的路由前缀设置）
+const routeKey = "tracing_route"
+
+// Middleware 请求ID和服务端 span 中间件（放在最前面，后续中间件和业务代码的 context 都带上调用链）
+//
+// 请求带 X-Request-ID 时沿用（网关生成后各服务原样传递），否则生成新的；响应头返回 X-Request-ID，
+// 响应体的 request_id 也取这个值。请求带 traceparent 时作为父 span。
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = NewRequestID()
+			c.Request.Header.Set(RequestIDHeader, requestID)
+		}
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx = tenantCtx.WithRequestID(ctx, requestID)
+		ctx, span := Tracer().Start(ctx, c.Request.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				attribute.String("request_id", requestID),
+			))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		route := c.GetString(routeKey)
+		if route == "" {
+			route = c.FullPath()
+		}
+		if route != "" {
+			span.SetName(c.Request.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if tenant := c.GetString("tenant_code"); tenant != "" {
+			span.SetAttributes(attribute.String("tenant.code", tenant))
+		}
+		if userID := c.GetString("user_id"); userID != "" {
+			span.SetAttributes(attribute.String("enduser.id", userID))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}
+}
+
+// SetRoute 设置 span 的路由名（网关的业务请求走 NoRoute，没有路由模板）
+func SetRoute(c *gin.Context, route string) {
+	c.Set(routeKey, route)
+}
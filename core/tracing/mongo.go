@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// mongoSpanKey 同一连接上的请求ID唯一
+type mongoSpanKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// MongoMonitor 为 MongoDB 命令创建客户端 span（只记录命令名和集合，不记录命令内容），并调用 next 的回调
+func MongoMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	var spans sync.Map
+	if next == nil {
+		next = &event.CommandMonitor{}
+	}
+	end := func(connectionID string, requestID int64, err error) {
+		value, ok := spans.LoadAndDelete(mongoSpanKey{connectionID, requestID})
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if trace.SpanContextFromContext(ctx).IsValid() {
+				attrs := []attribute.KeyValue{
+					semconv.DBSystemNameMongoDB,
+					semconv.DBNamespace(e.DatabaseName),
+					semconv.DBOperationName(e.CommandName),
+				}
+				name := e.CommandName
+				if collection, ok := e.Command.Lookup(e.CommandName).StringValueOK(); ok {
+					attrs = append(attrs, semconv.DBCollectionName(collection))
+					name += " " + collection
+				}
+				_, span := Tracer().Start(ctx, "mongo "+name,
+					trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+				spans.Store(mongoSpanKey{e.ConnectionID, e.RequestID}, span)
+			}
+			if next.Started != nil {
+				next.Started(ctx, e)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			end(e.ConnectionID, e.RequestID, nil)
+			if next.Succeeded != nil {
+				next.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			end(e.ConnectionID, e.RequestID, e.Failure)
+			if next.Failed != nil {
+				next.Failed(ctx, e)
+			}
+		},
+	}
+}
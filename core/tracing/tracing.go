@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"mule-cloud/core/config"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/logger"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader 请求ID头（网关生成，各服务原样传递）
+const RequestIDHeader = "X-Request-ID"
+
+// tracerName 本项目创建的 span 使用的 tracer
+const tracerName = "mule-cloud"
+
+func init() {
+	// 未调用 Init 时（如测试）也按 W3C traceparent 传递
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Init 初始化链路追踪，返回退出时调用的关闭函数（刷新未导出的 span）
+//
+// 未启用时不创建导出器，span 不记录，但 traceparent 和 X-Request-ID 照常传递，下游服务仍能串起调用链。
+func Init(cfg *config.TracingConfig, serviceName string) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "localhost:4317"
+	}
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("创建OTLP导出器失败: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	logger.Info("链路追踪已启用", zap.String("endpoint", endpoint), zap.Float64("sample_ratio", ratio))
+
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+// Tracer 本项目的 tracer（业务代码需要自定义 span 时使用）
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// NewRequestID 生成请求ID
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// TraceID 当前 span 的 trace ID（没有时返回空）
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// Detach 异步任务使用的 context：不随请求取消，但保留调用链和请求ID（租户等信息由调用方按需设置）
+func Detach(ctx context.Context) context.Context {
+	detached := trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+	if requestID := tenantCtx.GetRequestID(ctx); requestID != "" {
+		detached = tenantCtx.WithRequestID(detached, requestID)
+	}
+	return detached
+}
+
+// Transport 服务间 HTTP 调用的 Transport：创建客户端 span，注入 traceparent 和 X-Request-ID
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(requestIDTransport{base: base},
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "HTTP " + r.Method
+		}))
+}
+
+// requestIDTransport 请求没有 X-Request-ID 时从 context 补上
+type requestIDTransport struct {
+	base http.RoundTripper
+}
+
+func (t requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(RequestIDHeader) == "" {
+		if requestID := tenantCtx.GetRequestID(req.Context()); requestID != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
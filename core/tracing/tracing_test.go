@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tenantCtx "mule-cloud/core/context"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestPropagation 网关 → 服务：沿用 traceparent 和 X-Request-ID，服务间调用继续传递
+func TestPropagation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	// 下游服务：记录收到的 traceparent 和请求ID
+	var gotTraceparent, gotRequestID string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceparent = r.Header.Get("traceparent")
+		gotRequestID = r.Header.Get(RequestIDHeader)
+	}))
+	defer downstream.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/orders/:id", func(c *gin.Context) {
+		if tenantCtx.GetRequestID(c.Request.Context()) != c.GetString("request_id") {
+			t.Errorf("context request id = %q, want %q", tenantCtx.GetRequestID(c.Request.Context()), c.GetString("request_id"))
+		}
+		req, _ := http.NewRequestWithContext(c.Request.Context(), "GET", downstream.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("downstream call: %v", err)
+		}
+		resp.Body.Close()
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/orders/1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Header().Get(RequestIDHeader) != "req-1" || gotRequestID != "req-1" {
+		t.Errorf("request id = %q / downstream %q, want req-1", w.Header().Get(RequestIDHeader), gotRequestID)
+	}
+	if len(gotTraceparent) != 55 || gotTraceparent[3:35] != traceID {
+		t.Errorf("downstream traceparent = %q, want trace %s", gotTraceparent, traceID)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended spans = %d, want server + client", len(spans))
+	}
+	server := spans[len(spans)-1]
+	if server.Name() != "GET /orders/:id" || server.SpanContext().TraceID().String() != traceID {
+		t.Errorf("server span = %s trace %s", server.Name(), server.SpanContext().TraceID())
+	}
+	if spans[0].Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Errorf("client span parent = %s, want server span", spans[0].Parent().SpanID())
+	}
+}
+
+// TestRequestIDGenerated 没有请求ID时生成，异步任务保留请求ID和调用链
+func TestRequestIDGenerated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/ping", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		detached := Detach(ctx)
+		if detached.Done() != nil {
+			t.Errorf("detached context should not be cancelable")
+		}
+		if tenantCtx.GetRequestID(detached) == "" || TraceID(detached) != TraceID(ctx) || TraceID(ctx) == "" {
+			t.Errorf("detached = request %q trace %q, want %q", tenantCtx.GetRequestID(detached), TraceID(detached), TraceID(ctx))
+		}
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	if len(w.Header().Get(RequestIDHeader)) != 36 {
+		t.Errorf("generated request id = %q", w.Header().Get(RequestIDHeader))
+	}
+}
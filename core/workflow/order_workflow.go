@@ -262,12 +262,8 @@ func (w *OrderWorkflow) UpdateProgress(
 		return err
 	}
 
-	// 根据进度自动转换状态
-	if progress >= 1.0 && currentStatus == StatusProduction {
-		return w.TransitionTo(ctx, orderID, EventComplete, operator, "工序全部完成", map[string]interface{}{
-			"progress": progress,
-		})
-	} else if progress > 0 && currentStatus == StatusOrdered {
+	// 根据进度自动转换状态（进度100%不再自动完成，订单在全部发货后完成）
+	if progress > 0 && currentStatus == StatusOrdered {
 		return w.TransitionTo(ctx, orderID, EventStartProduction, operator, "开始生产", map[string]interface{}{
 			"progress": progress,
 		})
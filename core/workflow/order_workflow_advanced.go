@@ -43,31 +43,31 @@ var advancedTransitions = []TransitionRule{
 	{From: StatusOrdered, Event: EventStartProduction, To: StatusProduction, Condition: nil},
 	{From: StatusProduction, Event: EventUpdateProgress, To: StatusProduction, Condition: nil},
 
-	// 完成订单 - 需要进度达到100%
+	// 完成订单 - 需要全部发货
 	{
 		From:  StatusProduction,
 		Event: EventComplete,
 		To:    StatusCompleted,
 		Condition: func(ctx context.Context, orderID string, metadata map[string]interface{}) (bool, string) {
-			// 检查进度是否达到100%
-			if progress, ok := metadata["progress"].(float64); ok {
-				if progress >= 1.0 {
+			// 检查发货进度是否达到100%
+			if shipProgress, ok := metadata["ship_progress"].(float64); ok {
+				if shipProgress >= 1.0 {
 					return true, ""
 				}
-				return false, fmt.Sprintf("进度不足：当前%.1f%%，需要100%%", progress*100)
+				return false, fmt.Sprintf("发货不足：当前%.1f%%，需要全部发货", shipProgress*100)
 			}
 
-			// 如果没有传入进度，从数据库查询
+			// 如果没有传入发货进度，从数据库查询
 			orderRepo := repository.NewOrderRepository()
 			order, err := orderRepo.Get(ctx, orderID)
 			if err != nil {
 				return false, "无法获取订单信息"
 			}
 
-			if order.Progress >= 1.0 {
+			if order.Quantity > 0 && order.ShippedQty >= order.Quantity {
 				return true, ""
 			}
-			return false, fmt.Sprintf("进度不足：当前%.1f%%，需要100%%", order.Progress*100)
+			return false, fmt.Sprintf("发货不足：已发货%d件，订单%d件", order.ShippedQty, order.Quantity)
 		},
 	},
 
@@ -361,7 +361,7 @@ func GenerateMermaidDiagram() string {
 	diagram += "    Ordered -->|开始裁剪| Production\n"
 	diagram += "    Ordered -->|开始生产| Production\n"
 	diagram += "    Production -->|更新进度| Production\n"
-	diagram += "    Production -->|完成<br/>进度>=100%| Completed\n"
+	diagram += "    Production -->|完成<br/>全部发货| Completed\n"
 	diagram += "    Draft -.->|取消<br/>需要admin| Cancelled\n"
 	diagram += "    Ordered -.->|取消<br/>需要admin| Cancelled\n"
 	diagram += "    Production -.->|取消<br/>需要admin| Cancelled\n"
@@ -2,21 +2,24 @@ package models
 
 // Admin 管理员模型
 type Admin struct {
-	ID        string   `json:"id" bson:"_id,omitempty"`
-	Phone     string   `json:"phone" bson:"phone"`           // 手机号
-	Password  string   `json:"-" bson:"password"`            // 密码（不返回给前端）
-	Nickname  string   `json:"nickname" bson:"nickname"`     // 昵称
-	Email     string   `json:"email" bson:"email"`           // 邮箱
-	Avatar    string   `json:"avatar" bson:"avatar"`         // 头像
-	Roles     []string `json:"role" bson:"role"`             // 角色ID数组（注意：数据库字段名为 role 单数）
-	Status    int      `json:"status" bson:"status"`         // 状态：1-启用 0-禁用
-	IsDeleted int      `json:"is_deleted" bson:"is_deleted"` // 是否删除：0-否 1-是
-	Extend    Extend   `json:"extend" bson:"extend"`         // 扩展字段
-	CreatedBy string   `json:"created_by" bson:"created_by"` // 创建人
-	UpdatedBy string   `json:"updated_by" bson:"updated_by"` // 更新人
-	CreatedAt int64    `json:"created_at" bson:"created_at"` // 创建时间
-	UpdatedAt int64    `json:"updated_at" bson:"updated_at"` // 更新时间
-	DeletedAt int64    `json:"deleted_at" bson:"deleted_at"` // 删除时间
+	ID              string    `json:"id" bson:"_id,omitempty"`
+	Phone           string    `json:"phone" bson:"phone"`                  // 手机号
+	Password        string    `json:"-" bson:"password"`                   // 密码哈希（不返回给前端）
+	PasswordHistory []string  `json:"-" bson:"password_history,omitempty"` // 历史密码哈希（最新的在前，用于防止重复使用）
+	Nickname        string    `json:"nickname" bson:"nickname"`            // 昵称
+	Email           string    `json:"email" bson:"email"`                  // 邮箱
+	Avatar          string    `json:"avatar" bson:"avatar"`                // 头像
+	Roles           []string  `json:"role" bson:"role"`                    // 角色ID数组（注意：数据库字段名为 role 单数）
+	Status          int       `json:"status" bson:"status"`                // 状态：1-启用 0-禁用
+	IsDeleted       int       `json:"is_deleted" bson:"is_deleted"`        // 是否删除：0-否 1-是
+	Extend          Extend    `json:"extend" bson:"extend"`                // 扩展字段
+	TwoFactor       TwoFactor `json:"two_factor" bson:"two_factor"`        // 两步验证
+	SSO             *AdminSSO `json:"sso,omitempty" bson:"sso,omitempty"`  // 关联的企业身份（单点登录）
+	CreatedBy       string    `json:"created_by" bson:"created_by"`        // 创建人
+	UpdatedBy       string    `json:"updated_by" bson:"updated_by"`        // 更新人
+	CreatedAt       int64     `json:"created_at" bson:"created_at"`        // 创建时间
+	UpdatedAt       int64     `json:"updated_at" bson:"updated_at"`        // 更新时间
+	DeletedAt       int64     `json:"deleted_at" bson:"deleted_at"`        // 删除时间
 }
 
 type Extend struct {
@@ -25,6 +28,23 @@ type Extend struct {
 	LastLoginIP string `json:"last_login_ip" bson:"last_login_ip"` // 最后登录IP
 }
 
+// TwoFactor 两步验证（TOTP）设置
+type TwoFactor struct {
+	Enabled       bool     `json:"enabled" bson:"enabled"`                           // 是否已启用
+	Secret        string   `json:"-" bson:"secret,omitempty"`                        // TOTP 密钥（Base32）
+	PendingSecret string   `json:"-" bson:"pending_secret,omitempty"`                // 绑定中尚未确认的密钥
+	RecoveryCodes []string `json:"-" bson:"recovery_codes,omitempty"`                // 未使用的恢复码哈希
+	LastStep      int64    `json:"-" bson:"last_step,omitempty"`                     // 最近一次使用的时间步（防重放）
+	EnabledAt     int64    `json:"enabled_at,omitempty" bson:"enabled_at,omitempty"` // 启用时间
+}
+
+// AdminSSO 管理员关联的身份提供方账号
+type AdminSSO struct {
+	Issuer   string `json:"issuer" bson:"issuer"`       // 身份提供方
+	Subject  string `json:"subject" bson:"subject"`     // 身份提供方中的用户标识（sub）
+	LinkedAt int64  `json:"linked_at" bson:"linked_at"` // 关联时间
+}
+
 // TableName 返回表名
 func (Admin) TableName() string {
 	return "admin"
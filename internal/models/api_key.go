@@ -0,0 +1,31 @@
+package models
+
+// APIKey 机器对机器集成使用的API密钥
+// 所有租户的密钥统一存储在系统数据库（网关按密钥哈希查找），密钥明文只在创建时返回一次
+type APIKey struct {
+	ID          string             `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`               // 名称（记录为操作人）
+	Prefix      string             `bson:"prefix" json:"prefix"`           // 密钥前缀（用于识别，如 mk_3f9a1c2b）
+	KeyHash     string             `bson:"key_hash" json:"-"`              // 密钥 SHA-256 哈希
+	TenantID    string             `bson:"tenant_id" json:"tenant_id"`     // 所属租户ID（系统密钥为空）
+	TenantCode  string             `bson:"tenant_code" json:"tenant_code"` // 所属租户代码
+	Permissions []APIKeyPermission `bson:"permissions" json:"permissions"` // 允许访问的接口
+	AllowedIPs  []string           `bson:"allowed_ips" json:"allowed_ips"` // IP白名单（IP或CIDR，为空不限制）
+	ExpiresAt   int64              `bson:"expires_at" json:"expires_at"`   // 过期时间（0表示永不过期）
+	LastUsedAt  int64              `bson:"last_used_at" json:"last_used_at"`
+	LastUsedIP  string             `bson:"last_used_ip" json:"last_used_ip"`
+	Status      int                `bson:"status" json:"status"` // 状态：1-启用 0-禁用
+	Remark      string             `bson:"remark" json:"remark"`
+	IsDeleted   int                `bson:"is_deleted" json:"is_deleted"`
+	CreatedBy   string             `bson:"created_by" json:"created_by"`
+	UpdatedBy   string             `bson:"updated_by" json:"updated_by"`
+	CreatedAt   int64              `bson:"created_at" json:"created_at"`
+	UpdatedAt   int64              `bson:"updated_at" json:"updated_at"`
+}
+
+// APIKeyPermission API密钥权限，与 Casbin 策略的 obj/act 含义相同
+// Resource 按 keyMatch2 匹配（如 /order/orders/*、/order/orders/:id），Action 按正则匹配（如 read|create）
+type APIKeyPermission struct {
+	Resource string `bson:"resource" json:"resource"`
+	Action   string `bson:"action" json:"action"`
+}
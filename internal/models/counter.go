@@ -0,0 +1,12 @@
+package models
+
+// Counter 单据序号计数器（按键原子递增，如 shipment:<订单ID>、invoice:INV20250101）
+type Counter struct {
+	ID  string `json:"id" bson:"_id"`  // 计数器键
+	Seq int64  `json:"seq" bson:"seq"` // 当前序号
+}
+
+// TableName 返回表名
+func (Counter) TableName() string {
+	return "counters"
+}
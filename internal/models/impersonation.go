@@ -0,0 +1,24 @@
+package models
+
+// Impersonation 超管代管租户的会话记录
+// 统一存储在系统数据库（按 tenant_code 区分租户），租户管理员可以查看谁访问过本租户的数据
+type Impersonation struct {
+	ID         string `bson:"_id,omitempty" json:"id"`
+	AdminID    string `bson:"admin_id" json:"admin_id"`       // 发起代管的超管ID
+	AdminName  string `bson:"admin_name" json:"admin_name"`   // 发起代管的超管用户名
+	TenantID   string `bson:"tenant_id" json:"tenant_id"`     // 被代管的租户ID
+	TenantCode string `bson:"tenant_code" json:"tenant_code"` // 被代管的租户代码
+	Reason     string `bson:"reason" json:"reason"`           // 申请原因
+	ReadOnly   bool   `bson:"read_only" json:"read_only"`     // 是否只读（只允许 GET/HEAD/OPTIONS）
+	TokenID    string `bson:"token_id" json:"-"`              // 代管令牌的 jti（结束代管时吊销）
+	IP         string `bson:"ip" json:"ip"`                   // 申请时的客户端IP
+	ExpiresAt  int64  `bson:"expires_at" json:"expires_at"`   // 代管令牌过期时间
+	EndedAt    int64  `bson:"ended_at" json:"ended_at"`       // 主动结束时间（0表示未主动结束）
+	EndedBy    string `bson:"ended_by" json:"ended_by"`       // 结束人
+	CreatedAt  int64  `bson:"created_at" json:"created_at"`
+}
+
+// Active 代管会话是否仍然有效
+func (i *Impersonation) Active(now int64) bool {
+	return i.EndedAt == 0 && i.ExpiresAt > now
+}
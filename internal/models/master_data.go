@@ -0,0 +1,187 @@
+package models
+
+import "math"
+
+// MasterData 基础资料公共字段（各类型独立集合，替代 Basic 的 name/value 存储）
+//
+// JSON 字段与原 Basic 保持一致（id/name/value/remark/is_common/...），旧前端无需改动。
+type MasterData struct {
+	ID        string `json:"id" bson:"_id,omitempty"`
+	Name      string `json:"name" bson:"name"`             // 资料类型（同原 Basic：customer/salesman/color/size/order_type/procedure）
+	Code      string `json:"code" bson:"code"`             // 编码
+	Value     string `json:"value" bson:"value"`           // 名称
+	Remark    string `json:"remark" bson:"remark"`         // 备注
+	IsCommon  bool   `json:"is_common" bson:"is_common"`   // 是否公共数据（沿用原 Basic 的保留字段）
+	Status    int    `json:"status" bson:"status"`         // 状态：1-启用 0-禁用
+	IsDeleted int    `json:"is_deleted" bson:"is_deleted"` // 是否删除：0-否 1-是
+	CreatedBy string `json:"created_by" bson:"created_by"` // 创建人
+	UpdatedBy string `json:"updated_by" bson:"updated_by"` // 更新人
+	CreatedAt int64  `json:"created_at" bson:"created_at"` // 创建时间
+	UpdatedAt int64  `json:"updated_at" bson:"updated_at"` // 更新时间
+	DeletedAt int64  `json:"deleted_at" bson:"deleted_at"` // 删除时间
+}
+
+// Contact 联系人
+type Contact struct {
+	Name      string `json:"name" bson:"name"`             // 姓名
+	Phone     string `json:"phone" bson:"phone"`           // 电话
+	Email     string `json:"email" bson:"email"`           // 邮箱
+	Position  string `json:"position" bson:"position"`     // 职务
+	IsPrimary bool   `json:"is_primary" bson:"is_primary"` // 是否主要联系人
+}
+
+// PriceListItem 客户价目表条目（按款式约定单价）
+type PriceListItem struct {
+	StyleID   string  `json:"style_id" bson:"style_id"`     // 款式ID
+	StyleNo   string  `json:"style_no" bson:"style_no"`     // 款号
+	UnitPrice float64 `json:"unit_price" bson:"unit_price"` // 单价
+	Remark    string  `json:"remark" bson:"remark"`         // 备注
+}
+
+// Customer 客户
+type Customer struct {
+	MasterData   `bson:",inline"`
+	ShortName    string          `json:"short_name" bson:"short_name"`       // 简称
+	Address      string          `json:"address" bson:"address"`             // 地址
+	Contacts     []Contact       `json:"contacts" bson:"contacts"`           // 联系人
+	TaxNo        string          `json:"tax_no" bson:"tax_no"`               // 税号（统一社会信用代码）
+	BankName     string          `json:"bank_name" bson:"bank_name"`         // 开户行
+	BankAccount  string          `json:"bank_account" bson:"bank_account"`   // 银行账号
+	SalesmanID   string          `json:"salesman_id" bson:"salesman_id"`     // 默认业务员ID
+	SalesmanName string          `json:"salesman_name" bson:"salesman_name"` // 默认业务员名称
+	PriceList    []PriceListItem `json:"price_list" bson:"price_list"`       // 默认价目表
+}
+
+// TableName 返回表名
+func (Customer) TableName() string {
+	return "customers"
+}
+
+// PriceOf 获取客户对某款式的约定单价
+func (c *Customer) PriceOf(styleID, styleNo string) (float64, bool) {
+	for _, item := range c.PriceList {
+		if (styleID != "" && item.StyleID == styleID) || (styleNo != "" && item.StyleNo == styleNo) {
+			return item.UnitPrice, true
+		}
+	}
+	return 0, false
+}
+
+// Salesman 业务员
+type Salesman struct {
+	MasterData     `bson:",inline"`
+	Phone          string  `json:"phone" bson:"phone"`                     // 电话
+	Email          string  `json:"email" bson:"email"`                     // 邮箱
+	AdminID        string  `json:"admin_id" bson:"admin_id"`               // 关联的后台账号ID
+	CommissionRate float64 `json:"commission_rate" bson:"commission_rate"` // 提成比例（%）
+}
+
+// TableName 返回表名
+func (Salesman) TableName() string {
+	return "salesmen"
+}
+
+// Color 颜色
+type Color struct {
+	MasterData `bson:",inline"`
+	Hex        string `json:"hex" bson:"hex"`               // 色值，如 #1E90FF
+	PantoneNo  string `json:"pantone_no" bson:"pantone_no"` // 潘通色号
+}
+
+// TableName 返回表名
+func (Color) TableName() string {
+	return "colors"
+}
+
+// Size 尺寸
+type Size struct {
+	MasterData `bson:",inline"`
+	Group      string `json:"group" bson:"group"` // 尺寸组，如 成人/童装
+	Sort       int    `json:"sort" bson:"sort"`   // 排序（从小到大）
+}
+
+// TableName 返回表名
+func (Size) TableName() string {
+	return "sizes"
+}
+
+// OrderType 订单类型
+type OrderType struct {
+	MasterData `bson:",inline"`
+	Prefix     string `json:"prefix" bson:"prefix"` // 合同号前缀
+}
+
+// TableName 返回表名
+func (OrderType) TableName() string {
+	return "order_types"
+}
+
+// Procedure 工序（工序库）
+type Procedure struct {
+	MasterData  `bson:",inline"`
+	Category    string  `json:"category" bson:"category"`         // 工序分类，如 裁剪/车缝/后整
+	SAM         float64 `json:"sam" bson:"sam"`                   // 标准工时（分钟/件）
+	MachineType string  `json:"machine_type" bson:"machine_type"` // 机器类型，如 平车/冚车/锁边
+	BaseRate    float64 `json:"base_rate" bson:"base_rate"`       // 工序费率（元/分钟），0 表示使用租户统一费率
+	UnitPrice   float64 `json:"unit_price" bson:"unit_price"`     // 默认工价（有标准工时时按 SAM×费率 计算）
+}
+
+// TableName 返回表名
+func (Procedure) TableName() string {
+	return "procedures"
+}
+
+// Priced 是否按 SAM×费率 计价（设置了标准工时，且工序费率或租户统一费率大于0）
+func (p *Procedure) Priced(minuteRate float64) bool {
+	return p.SAM > 0 && p.rate(minuteRate) > 0
+}
+
+// rate 生效费率：工序费率优先，否则使用租户统一费率
+func (p *Procedure) rate(minuteRate float64) float64 {
+	if p.BaseRate > 0 {
+		return p.BaseRate
+	}
+	return minuteRate
+}
+
+// PriceAt 按租户统一费率计算工价（未设置标准工时或没有可用费率时返回手工维护的工价）
+func (p *Procedure) PriceAt(minuteRate float64) float64 {
+	if !p.Priced(minuteRate) {
+		return p.UnitPrice
+	}
+	return math.Round(p.SAM*p.rate(minuteRate)*10000) / 10000
+}
+
+// ProcedureTemplateItem 工序模板条目
+type ProcedureTemplateItem struct {
+	Sequence      int    `json:"sequence" bson:"sequence"`             // 顺序
+	ProcedureID   string `json:"procedure_id" bson:"procedure_id"`     // 工序库ID
+	ProcedureName string `json:"procedure_name" bson:"procedure_name"` // 工序名称（冗余）
+	IsSlowest     bool   `json:"is_slowest" bson:"is_slowest"`         // 是否最终工序
+	NoBundle      bool   `json:"no_bundle" bson:"no_bundle"`           // 不分扎上报
+}
+
+// ProcedureTemplate 工序模板（如"T恤基础款"），可一键套用到款式
+type ProcedureTemplate struct {
+	MasterData `bson:",inline"`
+	Category   string                  `json:"category" bson:"category"` // 适用款式分类
+	Items      []ProcedureTemplateItem `json:"items" bson:"items"`       // 工序清单
+}
+
+// TableName 返回表名
+func (ProcedureTemplate) TableName() string {
+	return "procedure_templates"
+}
+
+// PricingSetting 租户工价设置（每个租户库一条）
+type PricingSetting struct {
+	ID         string  `json:"id" bson:"_id"`
+	MinuteRate float64 `json:"minute_rate" bson:"minute_rate"` // 统一费率（元/分钟）
+	UpdatedBy  string  `json:"updated_by" bson:"updated_by"`   // 更新人
+	UpdatedAt  int64   `json:"updated_at" bson:"updated_at"`   // 更新时间
+}
+
+// TableName 返回表名
+func (PricingSetting) TableName() string {
+	return "pricing_settings"
+}
@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+// TestProcedurePriceAt 测试工价计算：没有标准工时或没有可用费率时保留手工工价
+func TestProcedurePriceAt(t *testing.T) {
+	tests := []struct {
+		name       string
+		procedure  Procedure
+		minuteRate float64
+		want       float64
+	}{
+		{"统一费率", Procedure{SAM: 1.5, UnitPrice: 0.2}, 0.4, 0.6},
+		{"工序费率优先", Procedure{SAM: 1.5, BaseRate: 0.5, UnitPrice: 0.2}, 0.4, 0.75},
+		{"工序费率不依赖统一费率", Procedure{SAM: 1.5, BaseRate: 0.5, UnitPrice: 0.2}, 0, 0.75},
+		{"未设置标准工时", Procedure{UnitPrice: 0.35}, 0.4, 0.35},
+		{"未设置费率", Procedure{SAM: 1.5, UnitPrice: 0.35}, 0, 0.35},
+		{"费率为负", Procedure{SAM: 1.5, UnitPrice: 0.35}, -0.1, 0.35},
+		{"四位小数", Procedure{SAM: 0.333}, 0.37, 0.1232},
+	}
+	for _, tt := range tests {
+		if got := tt.procedure.PriceAt(tt.minuteRate); got != tt.want {
+			t.Errorf("%s: PriceAt(%v) = %v, want %v", tt.name, tt.minuteRate, got, tt.want)
+		}
+	}
+}
@@ -15,6 +15,7 @@ type Order struct {
 	OrderTypeID   string  `json:"order_type_id" bson:"order_type_id"`     // 订单类型ID
 	OrderTypeName string  `json:"order_type_name" bson:"order_type_name"` // 订单类型名称
 	Quantity      int     `json:"quantity" bson:"quantity"`               // 总数量
+	ShippedQty    int     `json:"shipped_qty" bson:"shipped_qty"`         // 已发货数量
 	UnitPrice     float64 `json:"unit_price" bson:"unit_price"`           // 单价
 	TotalAmount   float64 `json:"total_amount" bson:"total_amount"`       // 总金额
 	DeliveryDate  string  `json:"delivery_date" bson:"delivery_date"`     // 交货日期
@@ -43,15 +44,18 @@ type Order struct {
 
 // OrderItem 订单明细（颜色+尺码组合的数量）
 type OrderItem struct {
-	Color    string `json:"color" bson:"color"`       // 颜色名称
-	Size     string `json:"size" bson:"size"`         // 尺码名称
-	Quantity int    `json:"quantity" bson:"quantity"` // 数量
+	Color      string `json:"color" bson:"color"`             // 颜色名称
+	Size       string `json:"size" bson:"size"`               // 尺码名称
+	Quantity   int    `json:"quantity" bson:"quantity"`       // 数量
+	ShippedQty int    `json:"shipped_qty" bson:"shipped_qty"` // 已发货数量
 }
 
 // OrderProcedure 订单工序
 type OrderProcedure struct {
 	Sequence       int     `json:"sequence" bson:"sequence"`               // 顺序
+	ProcedureID    string  `json:"procedure_id" bson:"procedure_id"`       // 工序库ID（手工录入时为空）
 	ProcedureName  string  `json:"procedure_name" bson:"procedure_name"`   // 工序名称
+	SAM            float64 `json:"sam" bson:"sam"`                         // 标准工时（分钟/件），用于计算工人效率
 	UnitPrice      float64 `json:"unit_price" bson:"unit_price"`           // 工价
 	AssignedWorker string  `json:"assigned_worker" bson:"assigned_worker"` // 指定工人
 	IsSlowest      bool    `json:"is_slowest" bson:"is_slowest"`           // 是否最终工序
@@ -90,7 +94,9 @@ type Style struct {
 // StyleProcedure 款式工序
 type StyleProcedure struct {
 	Sequence       int     `json:"sequence" bson:"sequence"`               // 顺序
+	ProcedureID    string  `json:"procedure_id" bson:"procedure_id"`       // 工序库ID（手工录入时为空）
 	ProcedureName  string  `json:"procedure_name" bson:"procedure_name"`   // 工序名称
+	SAM            float64 `json:"sam" bson:"sam"`                         // 标准工时（分钟/件）
 	UnitPrice      float64 `json:"unit_price" bson:"unit_price"`           // 工价
 	AssignedWorker string  `json:"assigned_worker" bson:"assigned_worker"` // 指定工人
 	IsSlowest      bool    `json:"is_slowest" bson:"is_slowest"`           // 是否最终工序
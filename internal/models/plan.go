@@ -0,0 +1,85 @@
+package models
+
+// 套餐功能
+const (
+	FeatureWorkflowDesigner = "workflow_designer" // 工作流设计器
+	FeatureQuality          = "quality"           // 质检、返工
+	FeatureOutsourcing      = "outsourcing"       // 外发加工
+)
+
+// 订阅状态
+const (
+	SubscriptionNone    = "none"    // 未订阅套餐（不限制）
+	SubscriptionActive  = "active"  // 有效
+	SubscriptionGrace   = "grace"   // 已到期，宽限期内仍可使用
+	SubscriptionExpired = "expired" // 超过宽限期（只读）
+)
+
+// Plan 订阅套餐（系统库）
+type Plan struct {
+	ID          string     `json:"id" bson:"_id,omitempty"`
+	Code        string     `json:"code" bson:"code"` // 套餐代码（唯一）
+	Name        string     `json:"name" bson:"name"`
+	Description string     `json:"description" bson:"description"`
+	Limits      PlanLimits `json:"limits" bson:"limits"`
+	Features    []string   `json:"features" bson:"features"` // 开通的功能（见 Feature* 常量）
+	Status      int        `json:"status" bson:"status"`     // 状态：1-启用 0-停用（停用后不能分配给新租户）
+	IsDeleted   int        `json:"is_deleted" bson:"is_deleted"`
+	CreatedBy   string     `json:"created_by" bson:"created_by"`
+	UpdatedBy   string     `json:"updated_by" bson:"updated_by"`
+	CreatedAt   int64      `json:"created_at" bson:"created_at"`
+	UpdatedAt   int64      `json:"updated_at" bson:"updated_at"`
+}
+
+// PlanLimits 套餐配额（0 表示不限制）
+type PlanLimits struct {
+	MaxMembers          int64 `json:"max_members" bson:"max_members"`                         // 在职成员数
+	MaxOrdersPerMonth   int64 `json:"max_orders_per_month" bson:"max_orders_per_month"`       // 每月新建订单数
+	MaxStorageBytes     int64 `json:"max_storage_bytes" bson:"max_storage_bytes"`             // 文件存储（字节）
+	MaxAPICallsPerMonth int64 `json:"max_api_calls_per_month" bson:"max_api_calls_per_month"` // 每月经网关的请求数
+}
+
+// TableName 返回表名
+func (Plan) TableName() string {
+	return "plan"
+}
+
+// HasFeature 套餐是否开通功能
+func (p *Plan) HasFeature(feature string) bool {
+	for _, f := range p.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// TenantSubscription 租户订阅
+type TenantSubscription struct {
+	PlanCode  string `json:"plan_code" bson:"plan_code"`   // 为空表示未订阅（不限制）
+	StartedAt int64  `json:"started_at" bson:"started_at"` // 开始时间
+	ExpiresAt int64  `json:"expires_at" bson:"expires_at"` // 到期时间，0 表示长期有效
+	GraceDays int    `json:"grace_days" bson:"grace_days"` // 到期后的宽限天数
+}
+
+// GraceUntil 宽限期结束时间（长期有效返回0）
+func (s *TenantSubscription) GraceUntil() int64 {
+	if s.ExpiresAt == 0 {
+		return 0
+	}
+	return s.ExpiresAt + int64(s.GraceDays)*86400
+}
+
+// State 订阅状态
+func (s *TenantSubscription) State(now int64) string {
+	switch {
+	case s.PlanCode == "":
+		return SubscriptionNone
+	case s.ExpiresAt == 0 || now < s.ExpiresAt:
+		return SubscriptionActive
+	case now < s.GraceUntil():
+		return SubscriptionGrace
+	default:
+		return SubscriptionExpired
+	}
+}
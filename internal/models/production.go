@@ -16,9 +16,15 @@ type ProcedureReport struct {
 	ProcedureName string  `json:"procedure_name" bson:"procedure_name"` // 工序名称
 	UnitPrice     float64 `json:"unit_price" bson:"unit_price"`         // 工价
 	TotalPrice    float64 `json:"total_price" bson:"total_price"`       // 总工资 = 数量 * 工价
+	SAM           float64 `json:"sam" bson:"sam"`                       // 标准工时（分钟/件，上报时快照）
+	EarnedMinutes float64 `json:"earned_minutes" bson:"earned_minutes"` // 产出工时 = 数量 * 标准工时
 	WorkerID      string  `json:"worker_id" bson:"worker_id"`           // 工人ID（从登录信息获取）
 	WorkerName    string  `json:"worker_name" bson:"worker_name"`       // 工人姓名
 	WorkerNo      string  `json:"worker_no" bson:"worker_no"`           // 工号
+	TeamID        string  `json:"team_id" bson:"team_id"`               // 班组ID（上报时快照）
+	Team          string  `json:"team" bson:"team"`                     // 班组
+	WorkshopID    string  `json:"workshop_id" bson:"workshop_id"`       // 车间ID（上报时快照）
+	Workshop      string  `json:"workshop" bson:"workshop"`             // 车间
 	ReportTime    int64   `json:"report_time" bson:"report_time"`       // 上报时间
 	Remark        string  `json:"remark" bson:"remark"`                 // 备注
 	IsDeleted     int     `json:"is_deleted" bson:"is_deleted"`         // 是否删除：0-否 1-是
@@ -71,3 +77,41 @@ func (OrderProcedureProgress) TableName() string {
 	return "order_procedure_progress"
 }
 
+
+// Attendance 出勤记录（工人每天一条，用于计算效率）
+type Attendance struct {
+	ID         string `json:"id" bson:"_id,omitempty"`
+	WorkerID   string `json:"worker_id" bson:"worker_id"`     // 工人ID（与上报记录 worker_id 一致）
+	WorkerName string `json:"worker_name" bson:"worker_name"` // 工人姓名
+	WorkerNo   string `json:"worker_no" bson:"worker_no"`     // 工号
+	TeamID     string `json:"team_id" bson:"team_id"`         // 班组ID
+	Team       string `json:"team" bson:"team"`               // 班组
+	WorkshopID string `json:"workshop_id" bson:"workshop_id"` // 车间ID
+	Workshop   string `json:"workshop" bson:"workshop"`       // 车间
+	WorkDate   string `json:"work_date" bson:"work_date"`     // 出勤日期 YYYY-MM-DD
+	Minutes    int    `json:"minutes" bson:"minutes"`         // 出勤分钟数（扣除休息）
+	Remark     string `json:"remark" bson:"remark"`           // 备注
+	CreatedBy  string `json:"created_by" bson:"created_by"`   // 录入人
+	CreatedAt  int64  `json:"created_at" bson:"created_at"`   // 创建时间
+	UpdatedAt  int64  `json:"updated_at" bson:"updated_at"`   // 更新时间
+}
+
+// TableName 返回表名
+func (Attendance) TableName() string {
+	return "attendances"
+}
+
+// WorkerDayOutput 工人单日产出汇总（聚合结果，不落库）
+type WorkerDayOutput struct {
+	WorkerID      string  `json:"worker_id" bson:"worker_id"`
+	WorkerName    string  `json:"worker_name" bson:"worker_name"`
+	WorkerNo      string  `json:"worker_no" bson:"worker_no"`
+	TeamID        string  `json:"team_id" bson:"team_id"`
+	Team          string  `json:"team" bson:"team"`
+	WorkshopID    string  `json:"workshop_id" bson:"workshop_id"`
+	Workshop      string  `json:"workshop" bson:"workshop"`
+	Date          string  `json:"date" bson:"date"`
+	Quantity      int     `json:"quantity" bson:"quantity"`
+	EarnedMinutes float64 `json:"earned_minutes" bson:"earned_minutes"`
+	Amount        float64 `json:"amount" bson:"amount"`
+}
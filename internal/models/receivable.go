@@ -0,0 +1,121 @@
+package models
+
+// 发票来源类型
+const (
+	InvoiceSourceShipment = "shipment" // 按发货单开票
+	InvoiceSourceOrder    = "order"    // 按订单总额开票
+)
+
+// 发票状态
+const (
+	InvoiceStatusOpen    = 1 // 未收款
+	InvoiceStatusPartial = 2 // 部分收款
+	InvoiceStatusPaid    = 3 // 已结清
+	InvoiceStatusVoid    = 4 // 已作废
+)
+
+// CustomerAccount 客户应收账户（信用条款）
+type CustomerAccount struct {
+	ID             string  `json:"id" bson:"_id,omitempty"`
+	CustomerID     string  `json:"customer_id" bson:"customer_id"`         // 客户ID
+	CustomerName   string  `json:"customer_name" bson:"customer_name"`     // 客户名称
+	CreditDays     int     `json:"credit_days" bson:"credit_days"`         // 账期（天），发票到期日 = 开票日 + 账期
+	CreditLimit    float64 `json:"credit_limit" bson:"credit_limit"`       // 信用额度（0 表示不限）
+	OpeningBalance float64 `json:"opening_balance" bson:"opening_balance"` // 期初应收余额
+	Remark         string  `json:"remark" bson:"remark"`                   // 备注
+	IsDeleted      int     `json:"is_deleted" bson:"is_deleted"`           // 是否删除：0-否 1-是
+	CreatedBy      string  `json:"created_by" bson:"created_by"`           // 创建人
+	UpdatedBy      string  `json:"updated_by" bson:"updated_by"`           // 更新人
+	CreatedAt      int64   `json:"created_at" bson:"created_at"`           // 创建时间
+	UpdatedAt      int64   `json:"updated_at" bson:"updated_at"`           // 更新时间
+}
+
+// TableName 返回表名
+func (CustomerAccount) TableName() string {
+	return "customer_accounts"
+}
+
+// InvoiceLine 发票明细行
+type InvoiceLine struct {
+	Description string  `json:"description" bson:"description"` // 摘要（款号/款名）
+	Color       string  `json:"color" bson:"color"`             // 颜色
+	Size        string  `json:"size" bson:"size"`               // 尺码
+	Quantity    int     `json:"quantity" bson:"quantity"`       // 数量
+	UnitPrice   float64 `json:"unit_price" bson:"unit_price"`   // 单价
+	Amount      float64 `json:"amount" bson:"amount"`           // 金额
+}
+
+// Invoice 应收发票
+type Invoice struct {
+	ID           string        `json:"id" bson:"_id,omitempty"`
+	InvoiceNo    string        `json:"invoice_no" bson:"invoice_no"`       // 发票号
+	SourceType   string        `json:"source_type" bson:"source_type"`     // 来源：shipment-发货单 order-订单
+	SourceID     string        `json:"source_id" bson:"source_id"`         // 来源单据ID
+	SourceNo     string        `json:"source_no" bson:"source_no"`         // 来源单据号（发货单号/合同号）
+	OrderID      string        `json:"order_id" bson:"order_id"`           // 订单ID
+	ContractNo   string        `json:"contract_no" bson:"contract_no"`     // 合同号
+	StyleNo      string        `json:"style_no" bson:"style_no"`           // 款号
+	StyleName    string        `json:"style_name" bson:"style_name"`       // 款名
+	CustomerID   string        `json:"customer_id" bson:"customer_id"`     // 客户ID
+	CustomerName string        `json:"customer_name" bson:"customer_name"` // 客户名称
+	SalesmanID   string        `json:"salesman_id" bson:"salesman_id"`     // 业务员ID
+	SalesmanName string        `json:"salesman_name" bson:"salesman_name"` // 业务员名称
+	Lines        []InvoiceLine `json:"lines" bson:"lines"`                 // 明细
+	Quantity     int           `json:"quantity" bson:"quantity"`           // 总数量
+	Amount       float64       `json:"amount" bson:"amount"`               // 发票金额
+	PaidAmount   float64       `json:"paid_amount" bson:"paid_amount"`     // 已收金额
+	InvoiceDate  int64         `json:"invoice_date" bson:"invoice_date"`   // 开票日期
+	DueDate      int64         `json:"due_date" bson:"due_date"`           // 到期日期
+	Status       int           `json:"status" bson:"status"`               // 状态：1-未收款 2-部分收款 3-已结清 4-已作废
+	Remark       string        `json:"remark" bson:"remark"`               // 备注
+	VoidReason   string        `json:"void_reason" bson:"void_reason"`     // 作废原因
+	IsDeleted    int           `json:"is_deleted" bson:"is_deleted"`       // 是否删除：0-否 1-是
+	CreatedBy    string        `json:"created_by" bson:"created_by"`       // 创建人
+	CreatedAt    int64         `json:"created_at" bson:"created_at"`       // 创建时间
+	UpdatedAt    int64         `json:"updated_at" bson:"updated_at"`       // 更新时间
+}
+
+// TableName 返回表名
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// Balance 未收余额
+func (i *Invoice) Balance() float64 {
+	if i.Status == InvoiceStatusVoid {
+		return 0
+	}
+	return i.Amount - i.PaidAmount
+}
+
+// ReceiptAllocation 收款核销明细
+type ReceiptAllocation struct {
+	InvoiceID string  `json:"invoice_id" bson:"invoice_id"` // 发票ID
+	InvoiceNo string  `json:"invoice_no" bson:"invoice_no"` // 发票号
+	Amount    float64 `json:"amount" bson:"amount"`         // 核销金额
+}
+
+// Receipt 收款单
+type Receipt struct {
+	ID              string              `json:"id" bson:"_id,omitempty"`
+	ReceiptNo       string              `json:"receipt_no" bson:"receipt_no"`             // 收款单号
+	CustomerID      string              `json:"customer_id" bson:"customer_id"`           // 客户ID
+	CustomerName    string              `json:"customer_name" bson:"customer_name"`       // 客户名称
+	Amount          float64             `json:"amount" bson:"amount"`                     // 收款金额
+	Method          string              `json:"method" bson:"method"`                     // 收款方式：bank-银行转账 cash-现金 other-其他
+	Reference       string              `json:"reference" bson:"reference"`               // 银行流水号等
+	ReceivedAt      int64               `json:"received_at" bson:"received_at"`           // 收款日期
+	Allocations     []ReceiptAllocation `json:"allocations" bson:"allocations"`           // 核销明细
+	UnappliedAmount float64             `json:"unapplied_amount" bson:"unapplied_amount"` // 未核销金额（预收）
+	Status          int                 `json:"status" bson:"status"`                     // 状态：1-有效 2-已作废
+	Remark          string              `json:"remark" bson:"remark"`                     // 备注
+	IsDeleted       int                 `json:"is_deleted" bson:"is_deleted"`             // 是否删除：0-否 1-是
+	CreatedBy       string              `json:"created_by" bson:"created_by"`             // 创建人
+	CreatedAt       int64               `json:"created_at" bson:"created_at"`             // 创建时间
+	UpdatedAt       int64               `json:"updated_at" bson:"updated_at"`             // 更新时间
+}
+
+// TableName 返回表名
+func (Receipt) TableName() string {
+	return "receipts"
+}
@@ -1,22 +1,101 @@
 package models
 
-import "go.mongodb.org/mongo-driver/v2/bson"
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
 
 // Role 角色模型
 type Role struct {
-	ID              string              `json:"id" bson:"_id,omitempty"`
-	Name            string              `json:"name" bson:"name"`                                             // 角色名称
-	Code            string              `json:"code" bson:"code"`                                             // 角色代码（唯一标识）
-	Description     string              `json:"description" bson:"description"`                               // 角色描述
-	Menus           []string            `json:"menus" bson:"menus"`                                           // 菜单名称数组（menu.name）
-	MenuPermissions map[string][]string `json:"menu_permissions,omitempty" bson:"menu_permissions,omitempty"` // 菜单权限映射: {"admin": ["read", "create", "update"], "role": ["read"]}
-	Status          int                 `json:"status" bson:"status"`                                         // 状态：1-启用 0-禁用
-	IsDeleted       int                 `json:"is_deleted" bson:"is_deleted"`                                 // 是否删除：0-否 1-是
-	CreatedBy       string              `json:"created_by" bson:"created_by"`                                 // 创建人
-	UpdatedBy       string              `json:"updated_by" bson:"updated_by"`                                 // 更新人
-	CreatedAt       int64               `json:"created_at" bson:"created_at"`                                 // 创建时间
-	UpdatedAt       int64               `json:"updated_at" bson:"updated_at"`                                 // 更新时间
-	DeletedAt       int64               `json:"deleted_at" bson:"deleted_at,omitempty"`                       // 删除时间
+	ID               string              `json:"id" bson:"_id,omitempty"`
+	Name             string              `json:"name" bson:"name"`                                               // 角色名称
+	Code             string              `json:"code" bson:"code"`                                               // 角色代码（唯一标识）
+	Description      string              `json:"description" bson:"description"`                                 // 角色描述
+	Menus            []string            `json:"menus" bson:"menus"`                                             // 菜单名称数组（menu.name）
+	MenuPermissions  map[string][]string `json:"menu_permissions,omitempty" bson:"menu_permissions,omitempty"`   // 菜单权限映射: {"admin": ["read", "create", "update"], "role": ["read"]}
+	DataScope        string              `json:"data_scope" bson:"data_scope,omitempty"`                         // 数据范围：self/team/workshop/dept/all，为空表示全部
+	FieldPermissions map[string]string   `json:"field_permissions,omitempty" bson:"field_permissions,omitempty"` // 敏感字段权限: {"salary": "write", "bank_account": "read"}
+	Status           int                 `json:"status" bson:"status"`                                           // 状态：1-启用 0-禁用
+	IsDeleted        int                 `json:"is_deleted" bson:"is_deleted"`                                   // 是否删除：0-否 1-是
+	CreatedBy        string              `json:"created_by" bson:"created_by"`                                   // 创建人
+	UpdatedBy        string              `json:"updated_by" bson:"updated_by"`                                   // 更新人
+	CreatedAt        int64               `json:"created_at" bson:"created_at"`                                   // 创建时间
+	UpdatedAt        int64               `json:"updated_at" bson:"updated_at"`                                   // 更新时间
+	DeletedAt        int64               `json:"deleted_at" bson:"deleted_at,omitempty"`                         // 删除时间
+}
+
+// 数据范围（多个角色取范围最大的）
+const (
+	DataScopeSelf     = "self"     // 仅本人
+	DataScopeTeam     = "team"     // 本班组
+	DataScopeWorkshop = "workshop" // 本车间
+	DataScopeDept     = "dept"     // 本部门及下级部门
+	DataScopeAll      = "all"      // 全部
+)
+
+// ValidDataScope 是否为合法的数据范围（空值表示全部）
+func ValidDataScope(scope string) bool {
+	switch scope {
+	case "", DataScopeSelf, DataScopeTeam, DataScopeWorkshop, DataScopeDept, DataScopeAll:
+		return true
+	}
+	return false
+}
+
+// 敏感字段权限级别（未授权时读取脱敏或隐藏，不能修改）
+const (
+	FieldRead  = "read"
+	FieldWrite = "write"
+)
+
+// ValidFieldPermissions 校验敏感字段权限配置
+func ValidFieldPermissions(perms map[string]string) error {
+	for group, level := range perms {
+		if _, ok := MemberSensitiveFields[group]; !ok {
+			return fmt.Errorf("未知的敏感字段: %s", group)
+		}
+		if level != FieldRead && level != FieldWrite {
+			return fmt.Errorf("敏感字段 %s 的权限只能是 read 或 write", group)
+		}
+	}
+	return nil
+}
+
+// FieldAccess 当前用户对敏感字段的读写权限（多个角色取最大权限）
+type FieldAccess struct {
+	Unrestricted bool              // 超管、租户管理员不受限制
+	Levels       map[string]string // 字段组 -> read/write
+}
+
+// NewFieldAccess 合并角色的敏感字段权限
+func NewFieldAccess(roles []*Role) *FieldAccess {
+	access := &FieldAccess{Levels: make(map[string]string)}
+	for _, role := range roles {
+		for group, level := range role.FieldPermissions {
+			if access.Levels[group] != FieldWrite {
+				access.Levels[group] = level
+			}
+		}
+	}
+	return access
+}
+
+// CanRead 是否可以查看字段组明文
+func (a *FieldAccess) CanRead(group string) bool {
+	if a == nil {
+		return false
+	}
+	level := a.Levels[group]
+	return a.Unrestricted || level == FieldRead || level == FieldWrite
+}
+
+// CanWrite 是否可以修改字段组
+func (a *FieldAccess) CanWrite(group string) bool {
+	if a == nil {
+		return false
+	}
+	return a.Unrestricted || a.Levels[group] == FieldWrite
 }
 
 // TableName 返回表名
@@ -0,0 +1,81 @@
+package models
+
+// 装箱方式
+const (
+	PackTypeSolid = "solid" // 单色单码
+	PackTypeMixed = "mixed" // 混色混码（按配比装箱）
+)
+
+// PackItem 装箱/发货明细（颜色+尺码+数量）
+type PackItem struct {
+	Color    string `json:"color" bson:"color"`       // 颜色名称
+	Size     string `json:"size" bson:"size"`         // 尺码名称
+	Quantity int    `json:"quantity" bson:"quantity"` // 数量
+}
+
+// PackingCarton 装箱记录（一箱一条）
+type PackingCarton struct {
+	ID           string     `json:"id" bson:"_id,omitempty"`
+	OrderID      string     `json:"order_id" bson:"order_id"`           // 订单ID
+	ContractNo   string     `json:"contract_no" bson:"contract_no"`     // 合同号
+	StyleNo      string     `json:"style_no" bson:"style_no"`           // 款号
+	StyleName    string     `json:"style_name" bson:"style_name"`       // 款名
+	CustomerID   string     `json:"customer_id" bson:"customer_id"`     // 客户ID
+	CustomerName string     `json:"customer_name" bson:"customer_name"` // 客户名称
+	CartonNo     int        `json:"carton_no" bson:"carton_no"`         // 箱号（订单内递增）
+	Barcode      string     `json:"barcode" bson:"barcode"`             // 箱唛条码内容
+	PackType     string     `json:"pack_type" bson:"pack_type"`         // 装箱方式：solid-单色单码 mixed-混色混码
+	Items        []PackItem `json:"items" bson:"items"`                 // 箱内明细
+	TotalQty     int        `json:"total_qty" bson:"total_qty"`         // 箱内总件数
+	GrossWeight  float64    `json:"gross_weight" bson:"gross_weight"`   // 毛重（kg）
+	NetWeight    float64    `json:"net_weight" bson:"net_weight"`       // 净重（kg）
+	Dimensions   string     `json:"dimensions" bson:"dimensions"`       // 外箱尺寸，如 60x40x30
+	ShipmentID   string     `json:"shipment_id" bson:"shipment_id"`     // 发货单ID（未发货为空）
+	Status       int        `json:"status" bson:"status"`               // 状态：0-已装箱 1-已发货
+	PrintCount   int        `json:"print_count" bson:"print_count"`     // 箱唛打印次数
+	PrintedAt    int64      `json:"printed_at" bson:"printed_at"`       // 最后打印时间
+	IsDeleted    int        `json:"is_deleted" bson:"is_deleted"`       // 是否删除：0-否 1-是
+	CreatedBy    string     `json:"created_by" bson:"created_by"`       // 创建人
+	CreatedAt    int64      `json:"created_at" bson:"created_at"`       // 创建时间
+	UpdatedAt    int64      `json:"updated_at" bson:"updated_at"`       // 更新时间
+	DeletedAt    int64      `json:"deleted_at" bson:"deleted_at"`       // 删除时间
+}
+
+// TableName 返回表名
+func (PackingCarton) TableName() string {
+	return "packing_cartons"
+}
+
+// Shipment 发货单
+type Shipment struct {
+	ID              string     `json:"id" bson:"_id,omitempty"`
+	ShipmentNo      string     `json:"shipment_no" bson:"shipment_no"`           // 发货单号
+	OrderID         string     `json:"order_id" bson:"order_id"`                 // 订单ID
+	ContractNo      string     `json:"contract_no" bson:"contract_no"`           // 合同号
+	StyleNo         string     `json:"style_no" bson:"style_no"`                 // 款号
+	StyleName       string     `json:"style_name" bson:"style_name"`             // 款名
+	CustomerID      string     `json:"customer_id" bson:"customer_id"`           // 客户ID
+	CustomerName    string     `json:"customer_name" bson:"customer_name"`       // 客户名称
+	SalesmanID      string     `json:"salesman_id" bson:"salesman_id"`           // 业务员ID
+	SalesmanName    string     `json:"salesman_name" bson:"salesman_name"`       // 业务员名称
+	CartonIDs       []string   `json:"carton_ids" bson:"carton_ids"`             // 发货箱ID列表
+	CartonCount     int        `json:"carton_count" bson:"carton_count"`         // 箱数
+	Items           []PackItem `json:"items" bson:"items"`                       // 发货明细（按颜色尺码汇总）
+	TotalQty        int        `json:"total_qty" bson:"total_qty"`               // 发货总件数
+	GrossWeight     float64    `json:"gross_weight" bson:"gross_weight"`         // 总毛重（kg）
+	Carrier         string     `json:"carrier" bson:"carrier"`                   // 承运商
+	TrackingNo      string     `json:"tracking_no" bson:"tracking_no"`           // 运单号
+	DeliveryAddress string     `json:"delivery_address" bson:"delivery_address"` // 收货地址
+	ShippedAt       int64      `json:"shipped_at" bson:"shipped_at"`             // 发货时间
+	Status          int        `json:"status" bson:"status"`                     // 状态：1-已发货 2-已作废
+	Remark          string     `json:"remark" bson:"remark"`                     // 备注
+	IsDeleted       int        `json:"is_deleted" bson:"is_deleted"`             // 是否删除：0-否 1-是
+	CreatedBy       string     `json:"created_by" bson:"created_by"`             // 创建人
+	CreatedAt       int64      `json:"created_at" bson:"created_at"`             // 创建时间
+	UpdatedAt       int64      `json:"updated_at" bson:"updated_at"`             // 更新时间
+}
+
+// TableName 返回表名
+func (Shipment) TableName() string {
+	return "shipments"
+}
@@ -2,20 +2,52 @@ package models
 
 // Tenant 租户模型
 type Tenant struct {
-	ID        string   `json:"id" bson:"_id,omitempty"`
-	Code      string   `json:"code" bson:"code"`             // 租户代码
-	Name      string   `json:"name" bson:"name"`             // 租户名称
-	Contact   string   `json:"contact" bson:"contact"`       // 联系人
-	Phone     string   `json:"phone" bson:"phone"`           // 联系电话
-	Email     string   `json:"email" bson:"email"`           // 联系邮箱
-	Menus     []string `json:"menus" bson:"menus"`           // 租户拥有的菜单权限（由超管分配）
-	Status    int      `json:"status" bson:"status"`         // 状态：1-启用 0-禁用
-	IsDeleted int      `json:"is_deleted" bson:"is_deleted"` // 是否删除：0-否 1-是
-	CreatedBy string   `json:"created_by" bson:"created_by"` // 创建人
-	UpdatedBy string   `json:"updated_by" bson:"updated_by"` // 更新人
-	CreatedAt int64    `json:"created_at" bson:"created_at"` // 创建时间
-	UpdatedAt int64    `json:"updated_at" bson:"updated_at"` // 更新时间
-	DeletedAt int64    `json:"deleted_at" bson:"deleted_at"` // 删除时间
+	ID           string             `json:"id" bson:"_id,omitempty"`
+	Code         string             `json:"code" bson:"code"`                 // 租户代码
+	Name         string             `json:"name" bson:"name"`                 // 租户名称
+	Contact      string             `json:"contact" bson:"contact"`           // 联系人
+	Phone        string             `json:"phone" bson:"phone"`               // 联系电话
+	Email        string             `json:"email" bson:"email"`               // 联系邮箱
+	Menus        []string           `json:"menus" bson:"menus"`               // 租户拥有的菜单权限（由超管分配）
+	Status       int                `json:"status" bson:"status"`             // 状态：1-启用 0-禁用
+	Require2FA   bool               `json:"require_2fa" bson:"require_2fa"`   // 是否强制租户管理员启用两步验证
+	SSO          TenantSSO          `json:"sso" bson:"sso"`                   // 企业单点登录（OIDC）配置
+	Subscription TenantSubscription `json:"subscription" bson:"subscription"` // 订阅套餐
+	IsDeleted    int                `json:"is_deleted" bson:"is_deleted"`     // 是否删除：0-否 1-是
+	CreatedBy    string             `json:"created_by" bson:"created_by"`     // 创建人
+	UpdatedBy    string             `json:"updated_by" bson:"updated_by"`     // 更新人
+	CreatedAt    int64              `json:"created_at" bson:"created_at"`     // 创建时间
+	UpdatedAt    int64              `json:"updated_at" bson:"updated_at"`     // 更新时间
+	DeletedAt    int64              `json:"deleted_at" bson:"deleted_at"`     // 删除时间
+}
+
+// TenantSSO 租户企业单点登录（OIDC 授权码模式）配置
+type TenantSSO struct {
+	Enabled       bool             `json:"enabled" bson:"enabled"`
+	Issuer        string           `json:"issuer" bson:"issuer"`                 // 身份提供方地址（拼接 /.well-known/openid-configuration）
+	ClientID      string           `json:"client_id" bson:"client_id"`           // 在身份提供方登记的客户端ID
+	ClientSecret  string           `json:"-" bson:"client_secret"`               // 客户端密钥（不返回给前端）
+	RedirectURL   string           `json:"redirect_url" bson:"redirect_url"`     // 前端回调页地址（需在身份提供方登记）
+	Scopes        []string         `json:"scopes" bson:"scopes"`                 // 为空时使用 openid profile email
+	Enforce       bool             `json:"enforce" bson:"enforce"`               // 只允许单点登录（禁用手机号+密码登录）
+	AutoProvision bool             `json:"auto_provision" bson:"auto_provision"` // 首次登录且无法关联已有账号时自动创建管理员
+	ClaimMapping  SSOClaimMapping  `json:"claim_mapping" bson:"claim_mapping"`
+	RoleMappings  []SSORoleMapping `json:"role_mappings" bson:"role_mappings"` // 配置后每次登录按身份提供方的声明同步角色
+	DefaultRoles  []string         `json:"default_roles" bson:"default_roles"` // 自动创建或未命中映射时分配的角色ID
+}
+
+// SSOClaimMapping ID Token 声明到管理员字段的映射（支持用 . 访问嵌套声明），为空时使用标准声明
+type SSOClaimMapping struct {
+	Email    string `json:"email" bson:"email"`       // 默认 email
+	Phone    string `json:"phone" bson:"phone"`       // 默认 phone_number
+	Nickname string `json:"nickname" bson:"nickname"` // 默认 name
+	Roles    string `json:"roles" bson:"roles"`       // 默认 groups
+}
+
+// SSORoleMapping 身份提供方的角色/组到管理员角色的映射
+type SSORoleMapping struct {
+	Value string   `json:"value" bson:"value"` // 声明中的值（如组名 erp-admins）
+	Roles []string `json:"roles" bson:"roles"` // 对应的角色ID
 }
 
 // TableName 返回表名
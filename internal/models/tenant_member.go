@@ -107,6 +107,23 @@ type MemberCertificate struct {
 	FileURL   string `json:"file_url" bson:"file_url"`     // 证书扫描件URL
 }
 
+// 员工档案敏感字段组（角色按字段组授权读写，见 Role.FieldPermissions）
+const (
+	MemberFieldIDCard      = "id_card"      // 身份证号
+	MemberFieldBankAccount = "bank_account" // 银行卡
+	MemberFieldSalary      = "salary"       // 薪资金额
+)
+
+// MemberSensitiveFields 字段组包含的字段（bson 字段名）
+var MemberSensitiveFields = map[string][]string{
+	MemberFieldIDCard:      {"id_card_no"},
+	MemberFieldBankAccount: {"bank_name", "bank_account", "bank_account_name"},
+	MemberFieldSalary:      {"base_salary", "hourly_rate", "piece_rate"},
+}
+
+// MemberEncryptedFields 加密存储的字段（bson 字段名）
+var MemberEncryptedFields = []string{"id_card_no", "bank_account"}
+
 // TableName 返回集合名
 func (TenantMember) TableName() string {
 	return "member"
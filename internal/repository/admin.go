@@ -43,6 +43,9 @@ type AdminRepository interface {
 	// UpdateOne 按条件更新单条记录
 	UpdateOne(ctx context.Context, filter bson.M, update bson.M) error
 
+	// UpdateIf 记录满足 cond 时执行 update（完整的更新文档，可使用 $pull 等操作符），不满足时返回 ErrConflict
+	UpdateIf(ctx context.Context, id string, cond bson.M, update bson.M) error
+
 	// Delete 删除记录
 	Delete(ctx context.Context, id string) error
 
@@ -274,6 +277,27 @@ func (r *adminRepository) UpdateOne(ctx context.Context, filter bson.M, update b
 	return err
 }
 
+// UpdateIf 记录满足 cond 时执行 update，不满足时返回 ErrConflict
+func (r *adminRepository) UpdateIf(ctx context.Context, id string, cond bson.M, update bson.M) error {
+	collection := r.getCollection(ctx)
+	filter := bson.M{"_id": id}
+	for k, v := range cond {
+		filter[k] = v
+	}
+	// 兼容 ObjectID 和字符串格式的 ID
+	if objectID, err := bson.ObjectIDFromHex(id); err == nil {
+		filter["_id"] = objectID
+	}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
 // Delete 软删除记录（设置 is_deleted = 1）
 func (r *adminRepository) Delete(ctx context.Context, id string) error {
 	collection := r.getCollection(ctx)
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type APIKeyRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+// getCollection 获取集合（API密钥固定使用系统数据库，按 tenant_code 区分租户）
+func (r *APIKeyRepository) getCollection() *mongo.Collection {
+	return r.dbManager.GetSystemDatabase().Collection("api_key")
+}
+
+// Create 创建API密钥
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	result, err := r.getCollection().InsertOne(ctx, key)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicate
+		}
+		return err
+	}
+	if oid, ok := result.InsertedID.(bson.ObjectID); ok {
+		key.ID = oid.Hex()
+	}
+	return nil
+}
+
+// Get 获取租户下的API密钥（排除软删除）
+func (r *APIKeyRepository) Get(ctx context.Context, tenantCode, id string) (*models.APIKey, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+	return r.findOne(ctx, bson.M{"_id": objectID, "tenant_code": tenantCode, "is_deleted": 0})
+}
+
+// GetByHash 根据密钥哈希获取API密钥（排除软删除，供网关认证使用）
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	return r.findOne(ctx, bson.M{"key_hash": hash, "is_deleted": 0})
+}
+
+func (r *APIKeyRepository) findOne(ctx context.Context, filter bson.M) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	err := r.getCollection().FindOne(ctx, filter).Decode(key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// List 分页查询（按创建时间倒序）
+func (r *APIKeyRepository) List(ctx context.Context, filter bson.M, page, pageSize int64) ([]*models.APIKey, int64, error) {
+	collection := r.getCollection()
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip((page - 1) * pageSize).
+		SetLimit(pageSize)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []*models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, 0, err
+	}
+	return keys, total, nil
+}
+
+// Update 更新租户下的API密钥
+func (r *APIKeyRepository) Update(ctx context.Context, tenantCode, id string, update bson.M) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	filter := bson.M{"_id": objectID, "tenant_code": tenantCode, "is_deleted": 0}
+	result, err := r.getCollection().UpdateOne(ctx, filter, bson.M{"$set": update})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed 记录最近使用时间和IP
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id, ip string, usedAt int64) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	_, err = r.getCollection().UpdateOne(ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{"last_used_at": usedAt, "last_used_ip": ip}})
+	return err
+}
+
+// CreateIndexes 创建索引
+func (r *APIKeyRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "tenant_code", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+	_, err := r.getCollection().Indexes().CreateMany(ctx, indexes)
+	return err
+}
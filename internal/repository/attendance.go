@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AttendanceRepository 出勤记录仓储接口
+type AttendanceRepository interface {
+	// Save 保存出勤（同一工人同一天只保留一条）
+	Save(ctx context.Context, attendance *models.Attendance) error
+	Find(ctx context.Context, filter bson.M) ([]*models.Attendance, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type attendanceRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+// NewAttendanceRepository 创建出勤记录仓储
+func NewAttendanceRepository() AttendanceRepository {
+	return &attendanceRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+// GetCollectionWithContext 获取集合（支持租户上下文）
+func (r *attendanceRepository) GetCollectionWithContext(ctx context.Context) *mongo.Collection {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	db := r.dbManager.GetDatabase(tenantCode)
+	return db.Collection(models.Attendance{}.TableName())
+}
+
+// Save 保存出勤
+func (r *attendanceRepository) Save(ctx context.Context, attendance *models.Attendance) error {
+	collection := r.GetCollectionWithContext(ctx)
+	now := time.Now().Unix()
+	attendance.UpdatedAt = now
+
+	filter := bson.M{"worker_id": attendance.WorkerID, "work_date": attendance.WorkDate}
+	update := bson.M{
+		"$set": bson.M{
+			"worker_name": attendance.WorkerName,
+			"worker_no":   attendance.WorkerNo,
+			"team_id":     attendance.TeamID,
+			"team":        attendance.Team,
+			"workshop_id": attendance.WorkshopID,
+			"workshop":    attendance.Workshop,
+			"minutes":     attendance.Minutes,
+			"remark":      attendance.Remark,
+			"created_by":  attendance.CreatedBy,
+			"updated_at":  now,
+		},
+		"$setOnInsert": bson.M{"_id": bson.NewObjectID().Hex(), "created_at": now},
+	}
+	_, err := collection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+// Find 查询出勤记录
+func (r *attendanceRepository) Find(ctx context.Context, filter bson.M) ([]*models.Attendance, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	opts := options.Find().SetSort(bson.D{{Key: "work_date", Value: -1}, {Key: "worker_no", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	attendances := []*models.Attendance{}
+	if err = cursor.All(ctx, &attendances); err != nil {
+		return nil, err
+	}
+	return attendances, nil
+}
+
+// Delete 删除出勤记录
+func (r *attendanceRepository) Delete(ctx context.Context, id string) error {
+	collection := r.GetCollectionWithContext(ctx)
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
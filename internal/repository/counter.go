@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CounterRepository 单据序号仓库接口
+type CounterRepository interface {
+	// Next 原子递增并返回键的下一个序号（从1开始，并发请求不会拿到相同序号）
+	Next(ctx context.Context, key string) (int64, error)
+
+	// EnsureAtLeast 序号不小于 seq（从已有单据数量初始化，避免与启用计数器前的单据重号）
+	EnsureAtLeast(ctx context.Context, key string, seq int64) error
+
+	// Add 原子加 delta 并返回加后的值（预留 n 个连续序号时返回最后一个，回滚时传负数）
+	Add(ctx context.Context, key string, delta int64) (int64, error)
+
+	// Seed 计数器不存在时初始化为 seq，已存在时不变（从已有数据初始化累计值）
+	Seed(ctx context.Context, key string, seq int64) error
+
+	// AddIfAtMost 加后不超过 limit 时原子加 delta，返回是否加上（计数器需先 Seed）
+	AddIfAtMost(ctx context.Context, key string, delta, limit int64) (bool, error)
+}
+
+type counterRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+// NewCounterRepository 创建单据序号仓库
+func NewCounterRepository() CounterRepository {
+	return &counterRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+func (r *counterRepository) getCollection(ctx context.Context) *mongo.Collection {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	db := r.dbManager.GetDatabase(tenantCode)
+	return db.Collection(models.Counter{}.TableName())
+}
+
+func (r *counterRepository) Next(ctx context.Context, key string) (int64, error) {
+	return r.Add(ctx, key, 1)
+}
+
+func (r *counterRepository) Add(ctx context.Context, key string, delta int64) (int64, error) {
+	var counter models.Counter
+	err := r.getCollection(ctx).FindOneAndUpdate(ctx,
+		bson.M{"_id": key},
+		bson.M{"$inc": bson.M{"seq": delta}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+	return counter.Seq, nil
+}
+
+func (r *counterRepository) EnsureAtLeast(ctx context.Context, key string, seq int64) error {
+	_, err := r.getCollection(ctx).UpdateOne(ctx,
+		bson.M{"_id": key},
+		bson.M{"$max": bson.M{"seq": seq}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *counterRepository) Seed(ctx context.Context, key string, seq int64) error {
+	_, err := r.getCollection(ctx).UpdateOne(ctx,
+		bson.M{"_id": key},
+		bson.M{"$setOnInsert": bson.M{"seq": seq}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *counterRepository) AddIfAtMost(ctx context.Context, key string, delta, limit int64) (bool, error) {
+	result, err := r.getCollection(ctx).UpdateOne(ctx,
+		bson.M{"_id": key, "seq": bson.M{"$lte": limit - delta}},
+		bson.M{"$inc": bson.M{"seq": delta}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// dataScopeTTL 数据范围缓存时间（角色或组织调整后最迟在此时间后生效）
+const dataScopeTTL = time.Minute
+
+// DataScope 当前用户的数据范围（由角色的 data_scope 计算，列表查询时自动追加过滤条件）
+type DataScope struct {
+	Scope         string   // 生效的数据范围
+	UserID        string   // 当前用户ID
+	OwnerIDs      []string // 范围内成员的用户ID和成员ID（用于按 worker_id、created_by 等归属字段过滤）
+	SalesmanIDs   []string // 关联账号（admin_id）在 OwnerIDs 中的业务员档案ID（订单的 salesman_id 是业务员档案ID）
+	TeamID        string   // 班组ID（team）
+	WorkshopID    string   // 车间ID（workshop）
+	DepartmentIDs []string // 本部门及全部下级部门（dept）
+}
+
+// dataScopeRank 数据范围从小到大
+var dataScopeRank = map[string]int{
+	models.DataScopeSelf:     1,
+	models.DataScopeTeam:     2,
+	models.DataScopeWorkshop: 3,
+	models.DataScopeDept:     4,
+	models.DataScopeAll:      5,
+}
+
+type cachedDataScope struct {
+	scope     *DataScope
+	expiresAt time.Time
+}
+
+var dataScopeCache sync.Map // tenant|user|roles -> *cachedDataScope
+
+// ResolveDataScope 计算当前用户的数据范围
+// 没有用户信息的内部调用、系统库、超管和租户管理员不受限制
+func ResolveDataScope(ctx context.Context) (*DataScope, error) {
+	userID := tenantCtx.GetUserID(ctx)
+	key, unrestricted := accessCacheKey(ctx)
+	if unrestricted {
+		return &DataScope{Scope: models.DataScopeAll, UserID: userID}, nil
+	}
+	if v, ok := dataScopeCache.Load(key); ok {
+		if cached := v.(*cachedDataScope); time.Now().Before(cached.expiresAt) {
+			return cached.scope, nil
+		}
+	}
+
+	scope, err := loadDataScope(ctx, userID, tenantCtx.GetRoles(ctx))
+	if err != nil {
+		return nil, err
+	}
+	dataScopeCache.Store(key, &cachedDataScope{scope: scope, expiresAt: time.Now().Add(dataScopeTTL)})
+	return scope, nil
+}
+
+// accessCacheKey 按租户、用户和角色生成权限缓存键；unrestricted 表示不受数据和字段权限限制
+func accessCacheKey(ctx context.Context) (key string, unrestricted bool) {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	userID := tenantCtx.GetUserID(ctx)
+	roles := tenantCtx.GetRoles(ctx)
+	if userID == "" || tenantCode == "" || tenantCode == "system" {
+		return "", true
+	}
+	for _, role := range roles {
+		if role == "super" || role == "tenant_admin" {
+			return "", true
+		}
+	}
+
+	sorted := append([]string(nil), roles...)
+	sort.Strings(sorted)
+	return tenantCode + "|" + userID + "|" + strings.Join(sorted, ","), false
+}
+
+// loadDataScope 按角色和成员档案（班组、车间、部门）计算数据范围
+func loadDataScope(ctx context.Context, userID string, roleIDs []string) (*DataScope, error) {
+	roles, err := NewRoleRepository().GetRolesByIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	scope := &DataScope{Scope: mergeDataScope(roles), UserID: userID}
+	if scope.Scope == models.DataScopeAll {
+		return scope, nil
+	}
+
+	memberRepo := NewTenantMemberRepository()
+	member, err := memberRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 管理员没有成员档案或档案缺少对应的组织信息时只能看本人数据
+	var filter bson.M
+	switch {
+	case member == nil:
+		scope.Scope = models.DataScopeSelf
+	case scope.Scope == models.DataScopeTeam && member.TeamID != "":
+		scope.TeamID = member.TeamID
+		filter = bson.M{"team_id": member.TeamID}
+	case scope.Scope == models.DataScopeWorkshop && member.WorkshopID != "":
+		scope.WorkshopID = member.WorkshopID
+		filter = bson.M{"workshop_id": member.WorkshopID}
+	case scope.Scope == models.DataScopeDept && member.DepartmentID != "":
+		depts, err := NewDepartmentRepository().Find(ctx, bson.M{"is_deleted": 0})
+		if err != nil {
+			return nil, err
+		}
+		scope.DepartmentIDs = descendantDepartments(member.DepartmentID, depts)
+		filter = bson.M{"department_id": bson.M{"$in": scope.DepartmentIDs}}
+	default:
+		scope.Scope = models.DataScopeSelf
+	}
+
+	scope.OwnerIDs = []string{userID}
+	if member != nil {
+		scope.OwnerIDs = append(scope.OwnerIDs, member.ID)
+	}
+	if filter != nil {
+		filter["is_deleted"] = 0
+		members, err := memberRepo.Find(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			scope.OwnerIDs = append(scope.OwnerIDs, m.ID)
+			if m.UserID != "" {
+				scope.OwnerIDs = append(scope.OwnerIDs, m.UserID)
+			}
+		}
+	}
+
+	salesmen, _, err := NewSalesmanRepository().List(ctx, bson.M{"admin_id": bson.M{"$in": scope.OwnerIDs}}, 0, 0, bson.D{{Key: "_id", Value: 1}})
+	if err != nil {
+		return nil, err
+	}
+	scope.SalesmanIDs = make([]string, 0, len(salesmen))
+	for _, s := range salesmen {
+		scope.SalesmanIDs = append(scope.SalesmanIDs, s.ID)
+	}
+	return scope, nil
+}
+
+// mergeDataScope 多个角色取范围最大的；没有角色或任一角色未配置时为全部
+func mergeDataScope(roles []*models.Role) string {
+	if len(roles) == 0 {
+		return models.DataScopeAll
+	}
+	merged := models.DataScopeSelf
+	for _, role := range roles {
+		rank, ok := dataScopeRank[role.DataScope]
+		if !ok {
+			return models.DataScopeAll
+		}
+		if rank > dataScopeRank[merged] {
+			merged = role.DataScope
+		}
+	}
+	return merged
+}
+
+// descendantDepartments 部门及其全部下级部门（按 ParentID）
+func descendantDepartments(root string, depts []*models.Department) []string {
+	children := make(map[string][]string)
+	for _, d := range depts {
+		children[d.ParentID] = append(children[d.ParentID], d.ID)
+	}
+
+	ids := []string{root}
+	seen := map[string]bool{root: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// Filter 按归属字段（如 worker_id、salesman_id）生成过滤条件，范围为全部时返回 nil
+func (s *DataScope) Filter(ownerFields ...string) bson.M {
+	if s.Scope == models.DataScopeAll || len(ownerFields) == 0 {
+		return nil
+	}
+	or := make(bson.A, 0, len(ownerFields))
+	for _, field := range ownerFields {
+		or = append(or, bson.M{field: bson.M{"$in": s.OwnerIDs}})
+	}
+	if len(or) == 1 {
+		return or[0].(bson.M)
+	}
+	return bson.M{"$or": or}
+}
+
+// MemberFilter 成员集合按组织字段生成过滤条件，范围为全部时返回 nil
+func (s *DataScope) MemberFilter() bson.M {
+	switch s.Scope {
+	case models.DataScopeAll:
+		return nil
+	case models.DataScopeTeam:
+		return bson.M{"team_id": s.TeamID}
+	case models.DataScopeWorkshop:
+		return bson.M{"workshop_id": s.WorkshopID}
+	case models.DataScopeDept:
+		return bson.M{"department_id": bson.M{"$in": s.DepartmentIDs}}
+	}
+	return bson.M{"user_id": s.UserID}
+}
+
+// applyDataScope 把当前用户的数据范围追加到查询条件（与原条件取交集）
+func applyDataScope(ctx context.Context, filter bson.M, scopeFilter func(*DataScope) bson.M) error {
+	scope, err := ResolveDataScope(ctx)
+	if err != nil {
+		return err
+	}
+	cond := scopeFilter(scope)
+	if cond == nil {
+		return nil
+	}
+	and, _ := filter["$and"].(bson.A)
+	filter["$and"] = append(and, cond)
+	return nil
+}
+
+// ownedBySalesman 按业务员（业务员档案ID）或归属字段过滤的数据范围
+func ownedBySalesman(fields ...string) func(*DataScope) bson.M {
+	return func(s *DataScope) bson.M {
+		cond := s.Filter(fields...)
+		if cond == nil {
+			return nil
+		}
+		or, ok := cond["$or"].(bson.A)
+		if !ok {
+			or = bson.A{cond}
+		}
+		return bson.M{"$or": append(or, bson.M{"salesman_id": bson.M{"$in": s.SalesmanIDs}})}
+	}
+}
+
+// ownedBy 按归属字段过滤的数据范围
+func ownedBy(fields ...string) func(*DataScope) bson.M {
+	return func(s *DataScope) bson.M {
+		return s.Filter(fields...)
+	}
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TestMergeDataScope 测试多个角色合并数据范围
+func TestMergeDataScope(t *testing.T) {
+	role := func(scope string) *models.Role { return &models.Role{DataScope: scope} }
+
+	tests := []struct {
+		name  string
+		roles []*models.Role
+		want  string
+	}{
+		{"no roles", nil, models.DataScopeAll},
+		{"unset scope", []*models.Role{role(models.DataScopeSelf), role("")}, models.DataScopeAll},
+		{"single", []*models.Role{role(models.DataScopeTeam)}, models.DataScopeTeam},
+		{"widest wins", []*models.Role{role(models.DataScopeSelf), role(models.DataScopeDept), role(models.DataScopeTeam)}, models.DataScopeDept},
+	}
+	for _, tt := range tests {
+		if got := mergeDataScope(tt.roles); got != tt.want {
+			t.Errorf("%s: mergeDataScope() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestDescendantDepartments 测试按 ParentID 展开下级部门
+func TestDescendantDepartments(t *testing.T) {
+	depts := []*models.Department{
+		{ID: "factory", ParentID: ""},
+		{ID: "sewing", ParentID: "factory"},
+		{ID: "line-1", ParentID: "sewing"},
+		{ID: "line-2", ParentID: "sewing"},
+		{ID: "sales", ParentID: "factory"},
+	}
+
+	got := descendantDepartments("sewing", depts)
+	want := []string{"sewing", "line-1", "line-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("descendantDepartments(sewing) = %v, want %v", got, want)
+	}
+	if got := descendantDepartments("line-1", depts); !reflect.DeepEqual(got, []string{"line-1"}) {
+		t.Errorf("descendantDepartments(line-1) = %v", got)
+	}
+}
+
+// TestDataScopeFilter 测试生成的过滤条件
+func TestDataScopeFilter(t *testing.T) {
+	all := &DataScope{Scope: models.DataScopeAll}
+	if all.Filter("worker_id") != nil || all.MemberFilter() != nil {
+		t.Error("scope all should not add filters")
+	}
+
+	team := &DataScope{Scope: models.DataScopeTeam, UserID: "u1", TeamID: "t1", OwnerIDs: []string{"u1", "m1", "u2"}}
+	if got := team.Filter("worker_id"); !reflect.DeepEqual(got, bson.M{"worker_id": bson.M{"$in": team.OwnerIDs}}) {
+		t.Errorf("Filter(worker_id) = %v", got)
+	}
+	if got := team.Filter("salesman_id", "created_by"); len(got["$or"].(bson.A)) != 2 {
+		t.Errorf("Filter(salesman_id, created_by) = %v", got)
+	}
+	if got := team.MemberFilter(); !reflect.DeepEqual(got, bson.M{"team_id": "t1"}) {
+		t.Errorf("MemberFilter() = %v", got)
+	}
+
+	self := &DataScope{Scope: models.DataScopeSelf, UserID: "u1"}
+	if got := self.MemberFilter(); !reflect.DeepEqual(got, bson.M{"user_id": "u1"}) {
+		t.Errorf("self MemberFilter() = %v", got)
+	}
+}
+
+// TestApplyDataScopeUnrestricted 测试内部调用和租户管理员不追加过滤条件
+func TestApplyDataScopeUnrestricted(t *testing.T) {
+	ctxs := map[string]context.Context{
+		"internal":     tenantCtx.WithTenantCode(context.Background(), "ace"),
+		"system":       tenantCtx.WithUserInfo(context.Background(), "system", "u1", "admin", []string{"r1"}),
+		"tenant_admin": tenantCtx.WithUserInfo(context.Background(), "ace", "u1", "admin", []string{"tenant_admin"}),
+	}
+	for name, ctx := range ctxs {
+		filter := bson.M{"is_deleted": 0}
+		if err := applyDataScope(ctx, filter, ownedBy("worker_id")); err != nil {
+			t.Fatalf("%s: applyDataScope() error = %v", name, err)
+		}
+		if _, ok := filter["$and"]; ok {
+			t.Errorf("%s: applyDataScope() should not restrict, got %v", name, filter)
+		}
+	}
+}
+
+// TestApplyDataScopeOrderSalesman 测试订单按业务员档案ID或创建人过滤（salesman_id 不是账号ID）
+func TestApplyDataScopeOrderSalesman(t *testing.T) {
+	ctx := tenantCtx.WithUserInfo(context.Background(), "ace", "u1", "sales", []string{"r-sales"})
+	key, _ := accessCacheKey(ctx)
+	dataScopeCache.Store(key, &cachedDataScope{
+		scope: &DataScope{
+			Scope:       models.DataScopeTeam,
+			UserID:      "u1",
+			OwnerIDs:    []string{"u1", "m1", "u2"},
+			SalesmanIDs: []string{"s1", "s2"},
+		},
+		expiresAt: time.Now().Add(time.Minute),
+	})
+	t.Cleanup(func() { dataScopeCache.Delete(key) })
+
+	filter := bson.M{"is_deleted": 0}
+	if err := applyDataScope(ctx, filter, ownedBySalesman("created_by")); err != nil {
+		t.Fatalf("applyDataScope() error = %v", err)
+	}
+	want := bson.M{
+		"is_deleted": 0,
+		"$and": bson.A{bson.M{"$or": bson.A{
+			bson.M{"created_by": bson.M{"$in": []string{"u1", "m1", "u2"}}},
+			bson.M{"salesman_id": bson.M{"$in": []string{"s1", "s2"}}},
+		}}},
+	}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("filter = %v, want %v", filter, want)
+	}
+
+	all := &DataScope{Scope: models.DataScopeAll}
+	if got := ownedBySalesman("created_by")(all); got != nil {
+		t.Errorf("scope all should not add filters, got %v", got)
+	}
+}
@@ -8,4 +8,7 @@ var (
 
 	// ErrDuplicate 记录重复
 	ErrDuplicate = errors.New("record already exists")
+
+	// ErrConflict 记录已被并发修改（条件更新未命中预期数量）
+	ErrConflict = errors.New("record modified concurrently")
 )
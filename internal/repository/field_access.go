@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/internal/models"
+)
+
+type cachedFieldAccess struct {
+	access    *models.FieldAccess
+	expiresAt time.Time
+}
+
+var fieldAccessCache sync.Map // tenant|user|roles -> *cachedFieldAccess
+
+// ResolveFieldAccess 计算当前用户对敏感字段的读写权限（与数据范围使用相同的缓存时间）
+func ResolveFieldAccess(ctx context.Context) (*models.FieldAccess, error) {
+	key, unrestricted := accessCacheKey(ctx)
+	if unrestricted {
+		return &models.FieldAccess{Unrestricted: true}, nil
+	}
+	if v, ok := fieldAccessCache.Load(key); ok {
+		if cached := v.(*cachedFieldAccess); time.Now().Before(cached.expiresAt) {
+			return cached.access, nil
+		}
+	}
+
+	roles, err := NewRoleRepository().GetRolesByIDs(ctx, tenantCtx.GetRoles(ctx))
+	if err != nil {
+		return nil, err
+	}
+	access := models.NewFieldAccess(roles)
+	fieldAccessCache.Store(key, &cachedFieldAccess{access: access, expiresAt: time.Now().Add(dataScopeTTL)})
+	return access, nil
+}
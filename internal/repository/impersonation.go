@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type ImpersonationRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+func NewImpersonationRepository() *ImpersonationRepository {
+	return &ImpersonationRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+// getCollection 获取集合（代管记录固定使用系统数据库，按 tenant_code 区分租户）
+func (r *ImpersonationRepository) getCollection() *mongo.Collection {
+	return r.dbManager.GetSystemDatabase().Collection("impersonation")
+}
+
+// Create 创建代管记录
+func (r *ImpersonationRepository) Create(ctx context.Context, imp *models.Impersonation) error {
+	result, err := r.getCollection().InsertOne(ctx, imp)
+	if err != nil {
+		return err
+	}
+	if oid, ok := result.InsertedID.(bson.ObjectID); ok {
+		imp.ID = oid.Hex()
+	}
+	return nil
+}
+
+// Get 获取代管记录
+func (r *ImpersonationRepository) Get(ctx context.Context, id string) (*models.Impersonation, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+	imp := &models.Impersonation{}
+	err = r.getCollection().FindOne(ctx, bson.M{"_id": objectID}).Decode(imp)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return imp, nil
+}
+
+// List 分页查询（按创建时间倒序）
+func (r *ImpersonationRepository) List(ctx context.Context, filter bson.M, page, pageSize int64) ([]*models.Impersonation, int64, error) {
+	collection := r.getCollection()
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip((page - 1) * pageSize).
+		SetLimit(pageSize)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []*models.Impersonation{}
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// Update 更新代管记录
+func (r *ImpersonationRepository) Update(ctx context.Context, id string, update bson.M) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	result, err := r.getCollection().UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": update})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// End 结束代管（只更新尚未结束的记录）
+func (r *ImpersonationRepository) End(ctx context.Context, id, endedBy string, endedAt int64) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	result, err := r.getCollection().UpdateOne(ctx,
+		bson.M{"_id": objectID, "ended_at": 0},
+		bson.M{"$set": bson.M{"ended_at": endedAt, "ended_by": endedBy}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateIndexes 创建索引
+func (r *ImpersonationRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_code", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "admin_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+	_, err := r.getCollection().Indexes().CreateMany(ctx, indexes)
+	return err
+}
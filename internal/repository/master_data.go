@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MasterDataRepository 基础资料仓库接口（客户、业务员、颜色、尺码、订单类型、工序共用）
+type MasterDataRepository[T any] interface {
+	// Get 根据ID获取（排除软删除）
+	Get(ctx context.Context, id string) (*T, error)
+
+	// FindOne 按条件查询单条（排除软删除）
+	FindOne(ctx context.Context, filter bson.M) (*T, error)
+
+	// List 查询列表，page<=0 时不分页
+	List(ctx context.Context, filter bson.M, page, pageSize int64, sort bson.D) ([]*T, int64, error)
+
+	// Count 统计记录数（排除软删除）
+	Count(ctx context.Context, filter bson.M) (int64, error)
+
+	// Create 创建（编码重复时返回 ErrDuplicate）
+	Create(ctx context.Context, entity *T) error
+
+	// Update 更新（编码重复时返回 ErrDuplicate）
+	Update(ctx context.Context, id string, update bson.M) error
+
+	// Delete 软删除
+	Delete(ctx context.Context, id string) error
+
+	// GetCollectionWithContext 根据Context获取MongoDB集合（支持租户隔离）
+	GetCollectionWithContext(ctx context.Context) *mongo.Collection
+}
+
+type masterDataRepository[T any] struct {
+	dbManager  *database.DatabaseManager
+	collection string
+}
+
+func newMasterDataRepository[T any](collection string) MasterDataRepository[T] {
+	return &masterDataRepository[T]{
+		dbManager:  database.GetDatabaseManager(),
+		collection: collection,
+	}
+}
+
+// NewCustomerRepository 创建客户仓库
+func NewCustomerRepository() MasterDataRepository[models.Customer] {
+	return newMasterDataRepository[models.Customer](models.Customer{}.TableName())
+}
+
+// NewSalesmanRepository 创建业务员仓库
+func NewSalesmanRepository() MasterDataRepository[models.Salesman] {
+	return newMasterDataRepository[models.Salesman](models.Salesman{}.TableName())
+}
+
+// NewColorRepository 创建颜色仓库
+func NewColorRepository() MasterDataRepository[models.Color] {
+	return newMasterDataRepository[models.Color](models.Color{}.TableName())
+}
+
+// NewSizeRepository 创建尺码仓库
+func NewSizeRepository() MasterDataRepository[models.Size] {
+	return newMasterDataRepository[models.Size](models.Size{}.TableName())
+}
+
+// NewOrderTypeRepository 创建订单类型仓库
+func NewOrderTypeRepository() MasterDataRepository[models.OrderType] {
+	return newMasterDataRepository[models.OrderType](models.OrderType{}.TableName())
+}
+
+// NewProcedureRepository 创建工序仓库
+func NewProcedureRepository() MasterDataRepository[models.Procedure] {
+	return newMasterDataRepository[models.Procedure](models.Procedure{}.TableName())
+}
+
+// NewProcedureTemplateRepository 创建工序模板仓库
+func NewProcedureTemplateRepository() MasterDataRepository[models.ProcedureTemplate] {
+	return newMasterDataRepository[models.ProcedureTemplate](models.ProcedureTemplate{}.TableName())
+}
+
+// GetCollectionWithContext 获取集合（支持租户上下文）
+func (r *masterDataRepository[T]) GetCollectionWithContext(ctx context.Context) *mongo.Collection {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	db := r.dbManager.GetDatabase(tenantCode)
+	return db.Collection(r.collection)
+}
+
+func (r *masterDataRepository[T]) Get(ctx context.Context, id string) (*T, error) {
+	return r.FindOne(ctx, bson.M{"_id": id})
+}
+
+func (r *masterDataRepository[T]) FindOne(ctx context.Context, filter bson.M) (*T, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	filter["is_deleted"] = 0
+
+	var entity T
+	err := collection.FindOne(ctx, filter).Decode(&entity)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (r *masterDataRepository[T]) List(ctx context.Context, filter bson.M, page, pageSize int64, sort bson.D) ([]*T, int64, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	filter["is_deleted"] = 0
+
+	opts := options.Find().SetSort(sort)
+	var total int64
+	if page > 0 && pageSize > 0 {
+		count, err := collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		total = count
+		opts.SetSkip((page - 1) * pageSize).SetLimit(pageSize)
+	}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	entities := []*T{}
+	if err = cursor.All(ctx, &entities); err != nil {
+		return nil, 0, err
+	}
+	if page <= 0 || pageSize <= 0 {
+		total = int64(len(entities))
+	}
+	return entities, total, nil
+}
+
+func (r *masterDataRepository[T]) Count(ctx context.Context, filter bson.M) (int64, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	filter["is_deleted"] = 0
+	return collection.CountDocuments(ctx, filter)
+}
+
+func (r *masterDataRepository[T]) Create(ctx context.Context, entity *T) error {
+	collection := r.GetCollectionWithContext(ctx)
+	_, err := collection.InsertOne(ctx, entity)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *masterDataRepository[T]) Update(ctx context.Context, id string, update bson.M) error {
+	collection := r.GetCollectionWithContext(ctx)
+	update["updated_at"] = time.Now().Unix()
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id, "is_deleted": 0}, bson.M{"$set": update})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *masterDataRepository[T]) Delete(ctx context.Context, id string) error {
+	collection := r.GetCollectionWithContext(ctx)
+	now := time.Now().Unix()
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "is_deleted": 0},
+		bson.M{"$set": bson.M{"is_deleted": 1, "deleted_at": now, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
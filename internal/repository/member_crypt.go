@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+
+	"mule-cloud/core/fieldcrypt"
+	"mule-cloud/core/logger"
+	"mule-cloud/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.uber.org/zap"
+)
+
+// encryptMemberUpdate 加密更新内容中的身份证号、银行卡号
+func encryptMemberUpdate(update bson.M) error {
+	for _, field := range models.MemberEncryptedFields {
+		value, ok := update[field].(string)
+		if !ok {
+			continue
+		}
+		enc, err := fieldcrypt.Encrypt(value)
+		if err != nil {
+			return err
+		}
+		update[field] = enc
+	}
+	return nil
+}
+
+// encryptMember 写入前加密成员的敏感字段，返回恢复明文的函数
+func encryptMember(member *models.TenantMember) (restore func(), err error) {
+	idCardNo, bankAccount := member.IDCardNo, member.BankAccount
+	if member.IDCardNo, err = fieldcrypt.Encrypt(idCardNo); err != nil {
+		return nil, err
+	}
+	if member.BankAccount, err = fieldcrypt.Encrypt(bankAccount); err != nil {
+		member.IDCardNo = idCardNo
+		return nil, err
+	}
+	return func() {
+		member.IDCardNo, member.BankAccount = idCardNo, bankAccount
+	}, nil
+}
+
+// decryptMembers 读取后解密敏感字段（历史明文原样返回，无法解密时置空，避免把密文返回给前端）
+func decryptMembers(members ...*models.TenantMember) {
+	for _, m := range members {
+		if m == nil {
+			continue
+		}
+		m.IDCardNo = decryptField(m.ID, "id_card_no", m.IDCardNo)
+		m.BankAccount = decryptField(m.ID, "bank_account", m.BankAccount)
+	}
+}
+
+func decryptField(memberID, field, value string) string {
+	plain, err := fieldcrypt.Decrypt(value)
+	if err != nil {
+		logger.Warn("解密员工敏感字段失败", zap.String("member_id", memberID), zap.String("field", field), zap.Error(err))
+		return ""
+	}
+	return plain
+}
+
+// EncryptSensitiveFields 加密租户库中的明文或旧密钥密文（配置或轮换密钥后执行），返回处理的成员数
+func (r *tenantMemberRepository) EncryptSensitiveFields(ctx context.Context) (int64, error) {
+	if !fieldcrypt.Enabled() {
+		return 0, nil
+	}
+	collection := r.getCollection(ctx)
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var count int64
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID          interface{} `bson:"_id"`
+			IDCardNo    string      `bson:"id_card_no"`
+			BankAccount string      `bson:"bank_account"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return count, err
+		}
+
+		update := bson.M{}
+		for field, value := range map[string]string{"id_card_no": doc.IDCardNo, "bank_account": doc.BankAccount} {
+			if !fieldcrypt.NeedsRotation(value) {
+				continue
+			}
+			plain, err := fieldcrypt.Decrypt(value)
+			if err != nil {
+				return count, err
+			}
+			update[field] = plain
+		}
+		if len(update) == 0 {
+			continue
+		}
+		if err := encryptMemberUpdate(update); err != nil {
+			return count, err
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": doc.ID}, bson.M{"$set": update}); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, cursor.Err()
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"mule-cloud/core/config"
+	"mule-cloud/core/fieldcrypt"
+	"mule-cloud/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TestMemberEncryption 测试员工敏感字段写入加密、读取解密
+func TestMemberEncryption(t *testing.T) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	if err := fieldcrypt.Init(&config.EncryptionConfig{Key: base64.StdEncoding.EncodeToString(key)}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	member := &models.TenantMember{IDCardNo: "110101199001011234", BankAccount: "6222020200001234", Name: "张三"}
+	restore, err := encryptMember(member)
+	if err != nil {
+		t.Fatalf("encryptMember() error = %v", err)
+	}
+	if !fieldcrypt.IsEncrypted(member.IDCardNo) || !fieldcrypt.IsEncrypted(member.BankAccount) {
+		t.Fatalf("encryptMember() should encrypt id card and bank account, got %+v", member)
+	}
+	stored := *member
+	restore()
+	if member.IDCardNo != "110101199001011234" {
+		t.Errorf("restore() IDCardNo = %q", member.IDCardNo)
+	}
+
+	decryptMembers(&stored)
+	if stored.IDCardNo != "110101199001011234" || stored.BankAccount != "6222020200001234" {
+		t.Errorf("decryptMembers() = %q, %q", stored.IDCardNo, stored.BankAccount)
+	}
+
+	update := bson.M{"bank_account": "6222020200009999", "name": "李四"}
+	if err := encryptMemberUpdate(update); err != nil {
+		t.Fatalf("encryptMemberUpdate() error = %v", err)
+	}
+	if !fieldcrypt.IsEncrypted(update["bank_account"].(string)) || update["name"] != "李四" {
+		t.Errorf("encryptMemberUpdate() = %v", update)
+	}
+}
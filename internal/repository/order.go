@@ -9,6 +9,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // OrderRepository 订单数据仓库接口
@@ -18,6 +19,7 @@ type OrderRepository interface {
 	Update(ctx context.Context, id string, update bson.M) error
 	Delete(ctx context.Context, id string) error
 	Count(ctx context.Context, filter bson.M) (int64, error)
+	List(ctx context.Context, filter bson.M, page, pageSize int64) ([]models.Order, int64, error)
 	GetCollectionWithContext(ctx context.Context) *mongo.Collection
 	GetByContractNo(ctx context.Context, contractNo string) (*models.Order, error)
 }
@@ -138,3 +140,32 @@ func (r *orderRepository) Count(ctx context.Context, filter bson.M) (int64, erro
 	collection := r.GetCollectionWithContext(ctx)
 	return collection.CountDocuments(ctx, filter)
 }
+
+// List 分页查询订单（按当前用户的数据范围过滤：业务员或创建人）
+func (r *orderRepository) List(ctx context.Context, filter bson.M, page, pageSize int64) ([]models.Order, int64, error) {
+	if err := applyDataScope(ctx, filter, ownedBySalesman("created_by")); err != nil {
+		return nil, 0, err
+	}
+
+	collection := r.GetCollectionWithContext(ctx)
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSkip((page - 1) * pageSize).
+		SetLimit(pageSize).
+		SetSort(bson.M{"created_at": -1})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	orders := []models.Order{}
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
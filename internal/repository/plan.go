@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type PlanRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+func NewPlanRepository() *PlanRepository {
+	return &PlanRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+// getCollection 获取集合（套餐固定使用系统数据库）
+func (r *PlanRepository) getCollection() *mongo.Collection {
+	return r.dbManager.GetSystemDatabase().Collection(models.Plan{}.TableName())
+}
+
+// Create 创建套餐
+func (r *PlanRepository) Create(ctx context.Context, plan *models.Plan) error {
+	result, err := r.getCollection().InsertOne(ctx, plan)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicate
+		}
+		return err
+	}
+	if oid, ok := result.InsertedID.(bson.ObjectID); ok {
+		plan.ID = oid.Hex()
+	}
+	return nil
+}
+
+// Get 获取套餐（排除已删除）
+func (r *PlanRepository) Get(ctx context.Context, id string) (*models.Plan, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+	return r.findOne(ctx, bson.M{"_id": objectID, "is_deleted": 0})
+}
+
+// GetByCode 按套餐代码获取（排除已删除）
+func (r *PlanRepository) GetByCode(ctx context.Context, code string) (*models.Plan, error) {
+	return r.findOne(ctx, bson.M{"code": code, "is_deleted": 0})
+}
+
+func (r *PlanRepository) findOne(ctx context.Context, filter bson.M) (*models.Plan, error) {
+	plan := &models.Plan{}
+	err := r.getCollection().FindOne(ctx, filter).Decode(plan)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return plan, nil
+}
+
+// List 全部套餐（排除已删除，按创建时间排序）
+func (r *PlanRepository) List(ctx context.Context) ([]*models.Plan, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.getCollection().Find(ctx, bson.M{"is_deleted": 0}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []*models.Plan{}
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Update 更新套餐
+func (r *PlanRepository) Update(ctx context.Context, id string, update bson.M) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	result, err := r.getCollection().UpdateOne(ctx, bson.M{"_id": objectID, "is_deleted": 0}, bson.M{"$set": update})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateIndexes 创建索引（未删除的套餐代码唯一）
+func (r *PlanRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "code", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"is_deleted": 0}),
+		},
+	}
+	_, err := r.getCollection().Indexes().CreateMany(ctx, indexes)
+	return err
+}
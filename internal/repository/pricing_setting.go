@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// pricingSettingID 每个租户库只保存一条工价设置
+const pricingSettingID = "default"
+
+// PricingSettingRepository 工价设置仓库接口
+type PricingSettingRepository interface {
+	// Get 获取当前租户的工价设置（未设置时返回费率为0的默认值）
+	Get(ctx context.Context) (*models.PricingSetting, error)
+
+	// Save 保存工价设置
+	Save(ctx context.Context, setting *models.PricingSetting) error
+}
+
+type pricingSettingRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+// NewPricingSettingRepository 创建工价设置仓库
+func NewPricingSettingRepository() PricingSettingRepository {
+	return &pricingSettingRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+func (r *pricingSettingRepository) getCollection(ctx context.Context) *mongo.Collection {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	db := r.dbManager.GetDatabase(tenantCode)
+	return db.Collection(models.PricingSetting{}.TableName())
+}
+
+func (r *pricingSettingRepository) Get(ctx context.Context) (*models.PricingSetting, error) {
+	var setting models.PricingSetting
+	err := r.getCollection(ctx).FindOne(ctx, bson.M{"_id": pricingSettingID}).Decode(&setting)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &models.PricingSetting{ID: pricingSettingID}, nil
+		}
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *pricingSettingRepository) Save(ctx context.Context, setting *models.PricingSetting) error {
+	setting.ID = pricingSettingID
+	_, err := r.getCollection(ctx).ReplaceOne(ctx, bson.M{"_id": pricingSettingID}, setting, options.Replace().SetUpsert(true))
+	return err
+}
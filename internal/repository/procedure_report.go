@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	tenantCtx "mule-cloud/core/context"
@@ -21,6 +24,8 @@ type ProcedureReportRepository interface {
 	GetStatistics(ctx context.Context, workerID, startDate, endDate string) (totalQuantity int, totalAmount float64, err error)
 	GetSalaryDetails(ctx context.Context, workerID, startDate, endDate string) ([]map[string]interface{}, error)
 	Delete(ctx context.Context, id string) error
+	SumByWorkerDay(ctx context.Context, filter bson.M) ([]*models.WorkerDayOutput, error)
+	UpdateSAM(ctx context.Context, orderID string, procedureSeq int, sam float64) (int64, error)
 }
 
 type procedureReportRepository struct {
@@ -98,6 +103,11 @@ func (r *procedureReportRepository) List(ctx context.Context, page, pageSize int
 		filter["report_time"] = dateFilter
 	}
 
+	// 按当前用户的数据范围过滤
+	if err := applyDataScope(ctx, filter, ownedBy("worker_id")); err != nil {
+		return nil, 0, err
+	}
+
 	// 计算总数
 	total, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
@@ -152,6 +162,10 @@ func (r *procedureReportRepository) GetStatistics(ctx context.Context, workerID,
 		filter["report_time"] = dateFilter
 	}
 
+	if err := applyDataScope(ctx, filter, ownedBy("worker_id")); err != nil {
+		return 0, 0, err
+	}
+
 	// 聚合统计
 	pipeline := []bson.D{
 		{{Key: "$match", Value: filter}},
@@ -262,3 +276,92 @@ func (r *procedureReportRepository) Delete(ctx context.Context, id string) error
 	_, err = collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
 	return err
 }
+
+// SumByWorkerDay 按工人、日期汇总产量、产出工时和工资（效率报表使用）
+func (r *procedureReportRepository) SumByWorkerDay(ctx context.Context, filter bson.M) ([]*models.WorkerDayOutput, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	filter["is_deleted"] = 0
+
+	day := bson.M{"$dateToString": bson.M{
+		"format":   "%Y-%m-%d",
+		"date":     bson.M{"$toDate": bson.M{"$multiply": bson.A{"$report_time", 1000}}},
+		"timezone": localTimezone(),
+	}}
+	pipeline := []bson.D{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":            bson.M{"worker_id": "$worker_id", "date": day},
+			"worker_name":    bson.M{"$last": "$worker_name"},
+			"worker_no":      bson.M{"$last": "$worker_no"},
+			"team_id":        bson.M{"$last": "$team_id"},
+			"team":           bson.M{"$last": "$team"},
+			"workshop_id":    bson.M{"$last": "$workshop_id"},
+			"workshop":       bson.M{"$last": "$workshop"},
+			"quantity":       bson.M{"$sum": "$quantity"},
+			"earned_minutes": bson.M{"$sum": "$earned_minutes"},
+			"amount":         bson.M{"$sum": "$total_price"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":            0,
+			"worker_id":      "$_id.worker_id",
+			"date":           "$_id.date",
+			"worker_name":    1,
+			"worker_no":      1,
+			"team_id":        1,
+			"team":           1,
+			"workshop_id":    1,
+			"workshop":       1,
+			"quantity":       1,
+			"earned_minutes": 1,
+			"amount":         1,
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := []*models.WorkerDayOutput{}
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateSAM 订单工序标准工时调整后，重算已有上报记录的产出工时
+func (r *procedureReportRepository) UpdateSAM(ctx context.Context, orderID string, procedureSeq int, sam float64) (int64, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	filter := bson.M{"order_id": orderID, "procedure_seq": procedureSeq, "is_deleted": 0}
+	update := bson.A{bson.M{"$set": bson.M{
+		"sam":            sam,
+		"earned_minutes": bson.M{"$multiply": bson.A{"$quantity", sam}},
+		"updated_at":     time.Now().Unix(),
+	}}}
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// localTimezone 本地时区的 IANA 名称（按日期分组时 MongoDB 按每条记录当天的偏移换算，跨夏令时也正确）
+//
+// 未设置 TZ 环境变量时 time.Local 名为 "Local"，此时从 /etc/localtime 链接取时区名，仍取不到时退回当前 UTC 偏移
+var localTimezone = sync.OnceValue(func() string {
+	return timezoneName(time.Local.String(), "/etc/localtime")
+})
+
+// timezoneName 解析时区名
+func timezoneName(name, localtime string) string {
+	if name != "" && name != "Local" {
+		return name
+	}
+	if target, err := os.Readlink(localtime); err == nil {
+		if _, zone, ok := strings.Cut(target, "zoneinfo/"); ok && zone != "" {
+			return zone
+		}
+	}
+	return time.Now().Format("-07:00")
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestTimezoneName 测试汇总用时区名：优先 TZ 名称，其次 /etc/localtime 链接，最后退回当前偏移
+func TestTimezoneName(t *testing.T) {
+	dir := t.TempDir()
+	linked := filepath.Join(dir, "localtime")
+	if err := os.Symlink("/usr/share/zoneinfo/America/New_York", linked); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		tzName    string
+		localtime string
+		want      string
+	}{
+		{"TZ 名称", "Europe/Berlin", linked, "Europe/Berlin"},
+		{"localtime 链接", "Local", linked, "America/New_York"},
+		{"无法解析", "Local", filepath.Join(dir, "missing"), time.Now().Format("-07:00")},
+	}
+	for _, tt := range tests {
+		if got := timezoneName(tt.tzName, tt.localtime); got != tt.want {
+			t.Errorf("%s: timezoneName() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -85,6 +85,11 @@ func (r *qualityInspectionRepository) List(ctx context.Context, page, pageSize i
 		}
 	}
 
+	// 按当前用户的数据范围过滤
+	if err := applyDataScope(ctx, filter, ownedBy("inspector_id")); err != nil {
+		return nil, 0, err
+	}
+
 	// 获取总数
 	total, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
@@ -143,6 +148,10 @@ func (r *qualityInspectionRepository) GetStatistics(ctx context.Context, inspect
 		}
 	}
 
+	if err := applyDataScope(ctx, filter, ownedBy("inspector_id")); err != nil {
+		return 0, 0, 0, err
+	}
+
 	// 聚合统计
 	pipeline := []bson.M{
 		{"$match": filter},
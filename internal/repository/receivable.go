@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"context"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CustomerAccountRepository 客户应收账户仓库接口
+type CustomerAccountRepository interface {
+	GetByCustomer(ctx context.Context, customerID string) (*models.CustomerAccount, error)
+	Save(ctx context.Context, account *models.CustomerAccount) error
+	Find(ctx context.Context, filter bson.M) ([]*models.CustomerAccount, error)
+}
+
+// InvoiceRepository 应收发票仓库接口
+type InvoiceRepository interface {
+	Create(ctx context.Context, invoice *models.Invoice) error
+	Update(ctx context.Context, id string, update bson.M) error
+	Purge(ctx context.Context, id string) error
+	ApplyPayment(ctx context.Context, id string, amount float64) (*models.Invoice, error)
+	SetStatusIfPaid(ctx context.Context, id string, paid float64, status int) error
+	VoidUnpaid(ctx context.Context, id, reason string) error
+	GetByID(ctx context.Context, id string) (*models.Invoice, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*models.Invoice, error)
+	Find(ctx context.Context, filter bson.M) ([]*models.Invoice, error)
+	List(ctx context.Context, filter bson.M, page, pageSize int) ([]*models.Invoice, int64, error)
+	Count(ctx context.Context, filter bson.M) (int64, error)
+}
+
+// ReceiptRepository 收款单仓库接口
+type ReceiptRepository interface {
+	Create(ctx context.Context, receipt *models.Receipt) error
+	Update(ctx context.Context, id string, update bson.M) error
+	UpdateIfStatus(ctx context.Context, id string, status int, update bson.M) error
+	GetByID(ctx context.Context, id string) (*models.Receipt, error)
+	Find(ctx context.Context, filter bson.M) ([]*models.Receipt, error)
+	List(ctx context.Context, filter bson.M, page, pageSize int) ([]*models.Receipt, int64, error)
+	Count(ctx context.Context, filter bson.M) (int64, error)
+}
+
+// ==================== 客户应收账户仓库实现 ====================
+
+type customerAccountRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+// NewCustomerAccountRepository 创建客户应收账户仓库
+func NewCustomerAccountRepository() CustomerAccountRepository {
+	return &customerAccountRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+// GetCollectionWithContext 获取集合（支持租户上下文）
+func (r *customerAccountRepository) GetCollectionWithContext(ctx context.Context) *mongo.Collection {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	db := r.dbManager.GetDatabase(tenantCode)
+	return db.Collection(models.CustomerAccount{}.TableName())
+}
+
+func (r *customerAccountRepository) GetByCustomer(ctx context.Context, customerID string) (*models.CustomerAccount, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	var account models.CustomerAccount
+	err := collection.FindOne(ctx, bson.M{"customer_id": customerID, "is_deleted": 0}).Decode(&account)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Save 按客户ID新增或更新账户
+func (r *customerAccountRepository) Save(ctx context.Context, account *models.CustomerAccount) error {
+	collection := r.GetCollectionWithContext(ctx)
+	opts := options.UpdateOne().SetUpsert(true)
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"customer_id": account.CustomerID, "is_deleted": 0},
+		bson.M{
+			"$set": bson.M{
+				"customer_name":   account.CustomerName,
+				"credit_days":     account.CreditDays,
+				"credit_limit":    account.CreditLimit,
+				"opening_balance": account.OpeningBalance,
+				"remark":          account.Remark,
+				"updated_by":      account.UpdatedBy,
+				"updated_at":      account.UpdatedAt,
+			},
+			"$setOnInsert": bson.M{
+				"_id":        account.ID,
+				"created_by": account.CreatedBy,
+				"created_at": account.CreatedAt,
+			},
+		},
+		opts,
+	)
+	return err
+}
+
+func (r *customerAccountRepository) Find(ctx context.Context, filter bson.M) ([]*models.CustomerAccount, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	if filter == nil {
+		filter = bson.M{}
+	}
+	filter["is_deleted"] = 0
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var accounts []*models.CustomerAccount
+	if err = cursor.All(ctx, &accounts); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// ==================== 应收发票仓库实现 ====================
+
+type invoiceRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+// NewInvoiceRepository 创建应收发票仓库
+func NewInvoiceRepository() InvoiceRepository {
+	return &invoiceRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+// GetCollectionWithContext 获取集合（支持租户上下文）
+func (r *invoiceRepository) GetCollectionWithContext(ctx context.Context) *mongo.Collection {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	db := r.dbManager.GetDatabase(tenantCode)
+	return db.Collection(models.Invoice{}.TableName())
+}
+
+// Create 创建发票（来源单据已有有效发票时返回 ErrDuplicate）
+func (r *invoiceRepository) Create(ctx context.Context, invoice *models.Invoice) error {
+	collection := r.GetCollectionWithContext(ctx)
+	_, err := collection.InsertOne(ctx, invoice)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Purge 物理删除发票（仅用于撤销刚创建但与其他发票冲突的发票）
+func (r *invoiceRepository) Purge(ctx context.Context, id string) error {
+	_, err := r.GetCollectionWithContext(ctx).DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *invoiceRepository) Update(ctx context.Context, id string, update bson.M) error {
+	collection := r.GetCollectionWithContext(ctx)
+	update["updated_at"] = time.Now().Unix()
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id, "is_deleted": 0}, bson.M{"$set": update})
+	return err
+}
+
+// amountTolerance 金额比较的容差（金额保留两位小数）
+const amountTolerance = 0.005
+
+// ApplyPayment 原子调整发票已收金额（amount 为负时反核销），返回调整后的发票
+//
+// 核销要求发票未结清、未作废且调整后不超过发票金额，反核销要求已收金额足够，
+// 条件不满足（如并发收款已核销了余额）时返回 ErrConflict
+func (r *invoiceRepository) ApplyPayment(ctx context.Context, id string, amount float64) (*models.Invoice, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	filter := bson.M{"_id": id, "is_deleted": 0}
+	if amount > 0 {
+		filter["status"] = bson.M{"$in": []int{models.InvoiceStatusOpen, models.InvoiceStatusPartial}}
+		filter["$expr"] = bson.M{"$lte": bson.A{bson.M{"$add": bson.A{"$paid_amount", amount}}, bson.M{"$add": bson.A{"$amount", amountTolerance}}}}
+	} else {
+		filter["paid_amount"] = bson.M{"$gte": -amount - amountTolerance}
+	}
+
+	var invoice models.Invoice
+	err := collection.FindOneAndUpdate(ctx, filter,
+		bson.M{"$inc": bson.M{"paid_amount": amount}, "$set": bson.M{"updated_at": time.Now().Unix()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&invoice)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrConflict
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// SetStatusIfPaid 已收金额仍为 paid 时更新发票状态（并发核销时以最后一次调整计算的状态为准）
+func (r *invoiceRepository) SetStatusIfPaid(ctx context.Context, id string, paid float64, status int) error {
+	collection := r.GetCollectionWithContext(ctx)
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "is_deleted": 0, "paid_amount": paid, "status": bson.M{"$ne": models.InvoiceStatusVoid}},
+		bson.M{"$set": bson.M{"status": status}},
+	)
+	return err
+}
+
+// VoidUnpaid 作废没有收款核销的发票，已作废或已有核销时返回 ErrConflict
+func (r *invoiceRepository) VoidUnpaid(ctx context.Context, id, reason string) error {
+	collection := r.GetCollectionWithContext(ctx)
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "is_deleted": 0, "status": bson.M{"$ne": models.InvoiceStatusVoid}, "paid_amount": bson.M{"$lte": amountTolerance}},
+		bson.M{"$set": bson.M{"status": models.InvoiceStatusVoid, "void_reason": reason, "updated_at": time.Now().Unix()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *invoiceRepository) GetByID(ctx context.Context, id string) (*models.Invoice, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	var invoice models.Invoice
+	err := collection.FindOne(ctx, bson.M{"_id": id, "is_deleted": 0}).Decode(&invoice)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.Invoice, error) {
+	return r.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+// Find 查询发票（按到期日、开票日升序，便于先到期先核销）
+func (r *invoiceRepository) Find(ctx context.Context, filter bson.M) ([]*models.Invoice, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	if filter == nil {
+		filter = bson.M{}
+	}
+	filter["is_deleted"] = 0
+
+	opts := options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}, {Key: "invoice_date", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var invoices []*models.Invoice
+	if err = cursor.All(ctx, &invoices); err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+func (r *invoiceRepository) List(ctx context.Context, filter bson.M, page, pageSize int) ([]*models.Invoice, int64, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	filter["is_deleted"] = 0
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * pageSize)
+	limit := int64(pageSize)
+	opts := options.Find().SetSkip(skip).SetLimit(limit).SetSort(bson.D{{Key: "invoice_date", Value: -1}, {Key: "created_at", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var invoices []*models.Invoice
+	if err = cursor.All(ctx, &invoices); err != nil {
+		return nil, 0, err
+	}
+
+	return invoices, total, nil
+}
+
+func (r *invoiceRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	return collection.CountDocuments(ctx, filter)
+}
+
+// ==================== 收款单仓库实现 ====================
+
+type receiptRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+// NewReceiptRepository 创建收款单仓库
+func NewReceiptRepository() ReceiptRepository {
+	return &receiptRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+// GetCollectionWithContext 获取集合（支持租户上下文）
+func (r *receiptRepository) GetCollectionWithContext(ctx context.Context) *mongo.Collection {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	db := r.dbManager.GetDatabase(tenantCode)
+	return db.Collection(models.Receipt{}.TableName())
+}
+
+func (r *receiptRepository) Create(ctx context.Context, receipt *models.Receipt) error {
+	collection := r.GetCollectionWithContext(ctx)
+	_, err := collection.InsertOne(ctx, receipt)
+	return err
+}
+
+func (r *receiptRepository) Update(ctx context.Context, id string, update bson.M) error {
+	collection := r.GetCollectionWithContext(ctx)
+	update["updated_at"] = time.Now().Unix()
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id, "is_deleted": 0}, bson.M{"$set": update})
+	return err
+}
+
+// UpdateIfStatus 仅在收款单当前状态为 status 时更新，状态已变化时返回 ErrConflict
+func (r *receiptRepository) UpdateIfStatus(ctx context.Context, id string, status int, update bson.M) error {
+	collection := r.GetCollectionWithContext(ctx)
+	update["updated_at"] = time.Now().Unix()
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id, "is_deleted": 0, "status": status}, bson.M{"$set": update})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *receiptRepository) GetByID(ctx context.Context, id string) (*models.Receipt, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	var receipt models.Receipt
+	err := collection.FindOne(ctx, bson.M{"_id": id, "is_deleted": 0}).Decode(&receipt)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+func (r *receiptRepository) Find(ctx context.Context, filter bson.M) ([]*models.Receipt, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	if filter == nil {
+		filter = bson.M{}
+	}
+	filter["is_deleted"] = 0
+
+	opts := options.Find().SetSort(bson.D{{Key: "received_at", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var receipts []*models.Receipt
+	if err = cursor.All(ctx, &receipts); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+func (r *receiptRepository) List(ctx context.Context, filter bson.M, page, pageSize int) ([]*models.Receipt, int64, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	filter["is_deleted"] = 0
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * pageSize)
+	limit := int64(pageSize)
+	opts := options.Find().SetSkip(skip).SetLimit(limit).SetSort(bson.D{{Key: "received_at", Value: -1}, {Key: "created_at", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var receipts []*models.Receipt
+	if err = cursor.All(ctx, &receipts); err != nil {
+		return nil, 0, err
+	}
+
+	return receipts, total, nil
+}
+
+func (r *receiptRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	return collection.CountDocuments(ctx, filter)
+}
@@ -0,0 +1,401 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	tenantCtx "mule-cloud/core/context"
+	"mule-cloud/core/database"
+	"mule-cloud/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// PackingCartonRepository 装箱仓库接口
+type PackingCartonRepository interface {
+	Create(ctx context.Context, carton *models.PackingCarton) error
+	CreateMany(ctx context.Context, cartons []*models.PackingCarton) error
+	Update(ctx context.Context, id string, update bson.M) error
+	Delete(ctx context.Context, id string) error
+	Purge(ctx context.Context, ids []string) error
+	GetByID(ctx context.Context, id string) (*models.PackingCarton, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*models.PackingCarton, error)
+	GetByBarcode(ctx context.Context, barcode string) (*models.PackingCarton, error)
+	ListByOrder(ctx context.Context, orderID string) ([]*models.PackingCarton, error)
+	List(ctx context.Context, page, pageSize int, orderID, contractNo string, status *int) ([]*models.PackingCarton, int64, error)
+	MaxCartonNo(ctx context.Context, orderID string) (int, error)
+	MarkShipped(ctx context.Context, ids []string, shipmentID string) error
+	ResetShipped(ctx context.Context, shipmentID string, count int) error
+}
+
+// ShipmentRepository 发货单仓库接口
+type ShipmentRepository interface {
+	Create(ctx context.Context, shipment *models.Shipment) error
+	Update(ctx context.Context, id string, update bson.M) error
+	UpdateIfStatus(ctx context.Context, id string, status int, update bson.M) error
+	GetByID(ctx context.Context, id string) (*models.Shipment, error)
+	ListShippedByOrder(ctx context.Context, orderID string) ([]*models.Shipment, error)
+	List(ctx context.Context, page, pageSize int, orderID, customerID, contractNo string, status *int) ([]*models.Shipment, int64, error)
+	Count(ctx context.Context, filter bson.M) (int64, error)
+}
+
+// ==================== 装箱仓库实现 ====================
+
+type packingCartonRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+// NewPackingCartonRepository 创建装箱仓库
+func NewPackingCartonRepository() PackingCartonRepository {
+	return &packingCartonRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+// GetCollectionWithContext 获取集合（支持租户上下文）
+func (r *packingCartonRepository) GetCollectionWithContext(ctx context.Context) *mongo.Collection {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	db := r.dbManager.GetDatabase(tenantCode)
+	return db.Collection(models.PackingCarton{}.TableName())
+}
+
+func (r *packingCartonRepository) Create(ctx context.Context, carton *models.PackingCarton) error {
+	collection := r.GetCollectionWithContext(ctx)
+	_, err := collection.InsertOne(ctx, carton)
+	return err
+}
+
+// CreateMany 批量创建装箱（箱号或条码重复时返回 ErrDuplicate，已插入的箱由调用方清理）
+func (r *packingCartonRepository) CreateMany(ctx context.Context, cartons []*models.PackingCarton) error {
+	if len(cartons) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(cartons))
+	for i, carton := range cartons {
+		docs[i] = carton
+	}
+	_, err := r.GetCollectionWithContext(ctx).InsertMany(ctx, docs)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *packingCartonRepository) Update(ctx context.Context, id string, update bson.M) error {
+	collection := r.GetCollectionWithContext(ctx)
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id, "is_deleted": 0}, bson.M{"$set": update})
+	return err
+}
+
+// Delete 删除已装箱（未发货）的箱，箱已发货或已删除时返回 ErrConflict
+func (r *packingCartonRepository) Delete(ctx context.Context, id string) error {
+	collection := r.GetCollectionWithContext(ctx)
+	now := time.Now().Unix()
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "is_deleted": 0, "status": 0},
+		bson.M{"$set": bson.M{"is_deleted": 1, "deleted_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// Purge 物理删除装箱（仅用于清理批量创建失败时已插入的箱）
+func (r *packingCartonRepository) Purge(ctx context.Context, ids []string) error {
+	_, err := r.GetCollectionWithContext(ctx).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+func (r *packingCartonRepository) GetByID(ctx context.Context, id string) (*models.PackingCarton, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	var carton models.PackingCarton
+	err := collection.FindOne(ctx, bson.M{"_id": id, "is_deleted": 0}).Decode(&carton)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &carton, nil
+}
+
+func (r *packingCartonRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.PackingCarton, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	opts := options.Find().SetSort(bson.D{{Key: "carton_no", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "is_deleted": 0}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var cartons []*models.PackingCarton
+	if err = cursor.All(ctx, &cartons); err != nil {
+		return nil, err
+	}
+	return cartons, nil
+}
+
+func (r *packingCartonRepository) GetByBarcode(ctx context.Context, barcode string) (*models.PackingCarton, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	var carton models.PackingCarton
+	err := collection.FindOne(ctx, bson.M{"barcode": barcode, "is_deleted": 0}).Decode(&carton)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &carton, nil
+}
+
+func (r *packingCartonRepository) ListByOrder(ctx context.Context, orderID string) ([]*models.PackingCarton, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	opts := options.Find().SetSort(bson.D{{Key: "carton_no", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"order_id": orderID, "is_deleted": 0}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var cartons []*models.PackingCarton
+	if err = cursor.All(ctx, &cartons); err != nil {
+		return nil, err
+	}
+	return cartons, nil
+}
+
+func (r *packingCartonRepository) List(ctx context.Context, page, pageSize int, orderID, contractNo string, status *int) ([]*models.PackingCarton, int64, error) {
+	collection := r.GetCollectionWithContext(ctx)
+
+	filter := bson.M{"is_deleted": 0}
+	if orderID != "" {
+		filter["order_id"] = orderID
+	}
+	if contractNo != "" {
+		filter["contract_no"] = bson.M{"$regex": contractNo, "$options": "i"}
+	}
+	if status != nil {
+		filter["status"] = *status
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * pageSize)
+	limit := int64(pageSize)
+	opts := options.Find().SetSkip(skip).SetLimit(limit).SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "carton_no", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var cartons []*models.PackingCarton
+	if err = cursor.All(ctx, &cartons); err != nil {
+		return nil, 0, err
+	}
+
+	return cartons, total, nil
+}
+
+// MaxCartonNo 获取订单当前最大箱号（包含已删除的箱，避免箱号复用）
+func (r *packingCartonRepository) MaxCartonNo(ctx context.Context, orderID string) (int, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	opts := options.FindOne().SetSort(bson.D{{Key: "carton_no", Value: -1}})
+
+	var carton models.PackingCarton
+	err := collection.FindOne(ctx, bson.M{"order_id": orderID}, opts).Decode(&carton)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return carton.CartonNo, nil
+}
+
+// MarkShipped 将装箱标记为已发货
+//
+// 只更新已装箱状态的箱，更新数量与 ids 不一致说明有箱已被其他发货单发出，
+// 此时恢复本次更新的箱并返回 ErrConflict
+func (r *packingCartonRepository) MarkShipped(ctx context.Context, ids []string, shipmentID string) error {
+	collection := r.GetCollectionWithContext(ctx)
+	result, err := collection.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "is_deleted": 0, "status": 0},
+		bson.M{"$set": bson.M{"status": 1, "shipment_id": shipmentID, "updated_at": time.Now().Unix()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount != int64(len(ids)) {
+		_, err := collection.UpdateMany(ctx,
+			bson.M{"shipment_id": shipmentID, "status": 1},
+			bson.M{"$set": bson.M{"status": 0, "shipment_id": "", "updated_at": time.Now().Unix()}},
+		)
+		if err != nil {
+			return fmt.Errorf("恢复装箱状态失败: %w", err)
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+// ResetShipped 发货单作废时将装箱恢复为已装箱
+//
+// 先只改状态、保留发货单ID，更新数量与 count 不一致时按发货单ID恢复并返回 ErrConflict，
+// 一致时再清空发货单ID
+func (r *packingCartonRepository) ResetShipped(ctx context.Context, shipmentID string, count int) error {
+	collection := r.GetCollectionWithContext(ctx)
+	now := time.Now().Unix()
+	result, err := collection.UpdateMany(ctx,
+		bson.M{"shipment_id": shipmentID, "is_deleted": 0, "status": 1},
+		bson.M{"$set": bson.M{"status": 0, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount != int64(count) {
+		_, err := collection.UpdateMany(ctx,
+			bson.M{"shipment_id": shipmentID, "status": 0},
+			bson.M{"$set": bson.M{"status": 1, "updated_at": now}},
+		)
+		if err != nil {
+			return fmt.Errorf("恢复装箱状态失败: %w", err)
+		}
+		return ErrConflict
+	}
+	_, err = collection.UpdateMany(ctx,
+		bson.M{"shipment_id": shipmentID, "status": 0},
+		bson.M{"$set": bson.M{"shipment_id": ""}},
+	)
+	return err
+}
+
+// ==================== 发货单仓库实现 ====================
+
+type shipmentRepository struct {
+	dbManager *database.DatabaseManager
+}
+
+// NewShipmentRepository 创建发货单仓库
+func NewShipmentRepository() ShipmentRepository {
+	return &shipmentRepository{
+		dbManager: database.GetDatabaseManager(),
+	}
+}
+
+// GetCollectionWithContext 获取集合（支持租户上下文）
+func (r *shipmentRepository) GetCollectionWithContext(ctx context.Context) *mongo.Collection {
+	tenantCode := tenantCtx.GetTenantCode(ctx)
+	db := r.dbManager.GetDatabase(tenantCode)
+	return db.Collection(models.Shipment{}.TableName())
+}
+
+func (r *shipmentRepository) Create(ctx context.Context, shipment *models.Shipment) error {
+	collection := r.GetCollectionWithContext(ctx)
+	_, err := collection.InsertOne(ctx, shipment)
+	return err
+}
+
+func (r *shipmentRepository) Update(ctx context.Context, id string, update bson.M) error {
+	collection := r.GetCollectionWithContext(ctx)
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id, "is_deleted": 0}, bson.M{"$set": update})
+	return err
+}
+
+// UpdateIfStatus 仅在发货单当前状态为 status 时更新，状态已变化时返回 ErrConflict
+func (r *shipmentRepository) UpdateIfStatus(ctx context.Context, id string, status int, update bson.M) error {
+	collection := r.GetCollectionWithContext(ctx)
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id, "is_deleted": 0, "status": status}, bson.M{"$set": update})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *shipmentRepository) GetByID(ctx context.Context, id string) (*models.Shipment, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	var shipment models.Shipment
+	err := collection.FindOne(ctx, bson.M{"_id": id, "is_deleted": 0}).Decode(&shipment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// ListShippedByOrder 获取订单所有有效（已发货）的发货单
+func (r *shipmentRepository) ListShippedByOrder(ctx context.Context, orderID string) ([]*models.Shipment, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	opts := options.Find().SetSort(bson.D{{Key: "shipped_at", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"order_id": orderID, "status": 1, "is_deleted": 0}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var shipments []*models.Shipment
+	if err = cursor.All(ctx, &shipments); err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+func (r *shipmentRepository) List(ctx context.Context, page, pageSize int, orderID, customerID, contractNo string, status *int) ([]*models.Shipment, int64, error) {
+	collection := r.GetCollectionWithContext(ctx)
+
+	filter := bson.M{"is_deleted": 0}
+	if orderID != "" {
+		filter["order_id"] = orderID
+	}
+	if customerID != "" {
+		filter["customer_id"] = customerID
+	}
+	if contractNo != "" {
+		filter["contract_no"] = bson.M{"$regex": contractNo, "$options": "i"}
+	}
+	if status != nil {
+		filter["status"] = *status
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * pageSize)
+	limit := int64(pageSize)
+	opts := options.Find().SetSkip(skip).SetLimit(limit).SetSort(bson.D{{Key: "shipped_at", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var shipments []*models.Shipment
+	if err = cursor.All(ctx, &shipments); err != nil {
+		return nil, 0, err
+	}
+
+	return shipments, total, nil
+}
+
+func (r *shipmentRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	collection := r.GetCollectionWithContext(ctx)
+	return collection.CountDocuments(ctx, filter)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"mule-cloud/core/database"
 	"mule-cloud/internal/models"
 	"time"
@@ -507,3 +508,27 @@ func (r *tenantRepository) HardDeleteMany(ctx context.Context, ids []string) (in
 func (r *tenantRepository) GetCollection() *mongo.Collection {
 	return r.dbManager.GetSystemDatabase().Collection("tenant")
 }
+
+// EnsureTenantIndexes 为系统库和所有租户库补建业务唯一索引（服务启动时调用，幂等）
+func EnsureTenantIndexes(ctx context.Context) error {
+	dbManager := database.GetDatabaseManager()
+	tenants, err := NewTenantRepository().Find(ctx, bson.M{"is_deleted": 0})
+	if err != nil {
+		return fmt.Errorf("查询租户列表失败: %w", err)
+	}
+
+	tenantCodes := []string{"system"}
+	for _, tenant := range tenants {
+		if tenant.Code != "" {
+			tenantCodes = append(tenantCodes, tenant.Code)
+		}
+	}
+
+	var firstErr error
+	for _, code := range tenantCodes {
+		if err := dbManager.EnsureTenantIndexes(ctx, code); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("租户[%s]: %w", code, err)
+		}
+	}
+	return firstErr
+}
@@ -9,6 +9,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // TenantMemberRepository 租户成员数据仓库接口
@@ -37,6 +38,9 @@ type TenantMemberRepository interface {
 	// Count 统计记录数
 	Count(ctx context.Context, filter bson.M) (int64, error)
 
+	// List 按当前用户的数据范围分页查询成员，返回列表和总数
+	List(ctx context.Context, filter bson.M, page, pageSize int64) ([]*models.TenantMember, int64, error)
+
 	// Create 创建记录
 	Create(ctx context.Context, member *models.TenantMember) error
 
@@ -57,6 +61,9 @@ type TenantMemberRepository interface {
 
 	// HardDelete 物理删除记录
 	HardDelete(ctx context.Context, id string) error
+
+	// EncryptSensitiveFields 加密明文或旧密钥加密的敏感字段，返回处理的成员数
+	EncryptSensitiveFields(ctx context.Context) (int64, error)
 }
 
 // tenantMemberRepository 租户成员数据仓库实现
@@ -93,6 +100,7 @@ func (r *tenantMemberRepository) Get(ctx context.Context, id string) (*models.Te
 			}
 			return nil, err
 		}
+		decryptMembers(member)
 		return member, nil
 	}
 
@@ -105,6 +113,7 @@ func (r *tenantMemberRepository) Get(ctx context.Context, id string) (*models.Te
 		}
 		return nil, err
 	}
+	decryptMembers(member)
 	return member, nil
 }
 
@@ -120,6 +129,7 @@ func (r *tenantMemberRepository) GetByUnionID(ctx context.Context, unionID strin
 		}
 		return nil, err
 	}
+	decryptMembers(member)
 	return member, nil
 }
 
@@ -135,6 +145,7 @@ func (r *tenantMemberRepository) GetByUserID(ctx context.Context, userID string)
 		}
 		return nil, err
 	}
+	decryptMembers(member)
 	return member, nil
 }
 
@@ -150,6 +161,7 @@ func (r *tenantMemberRepository) GetByJobNumber(ctx context.Context, jobNumber s
 		}
 		return nil, err
 	}
+	decryptMembers(member)
 	return member, nil
 }
 
@@ -167,6 +179,7 @@ func (r *tenantMemberRepository) Find(ctx context.Context, filter bson.M) ([]*mo
 	if err != nil {
 		return nil, err
 	}
+	decryptMembers(members...)
 	return members, nil
 }
 
@@ -181,6 +194,7 @@ func (r *tenantMemberRepository) FindOne(ctx context.Context, filter bson.M) (*m
 		}
 		return nil, err
 	}
+	decryptMembers(member)
 	return member, nil
 }
 
@@ -198,6 +212,7 @@ func (r *tenantMemberRepository) FindWithPage(ctx context.Context, filter bson.M
 	if err != nil {
 		return nil, err
 	}
+	decryptMembers(members...)
 	return members, nil
 }
 
@@ -211,10 +226,45 @@ func (r *tenantMemberRepository) Count(ctx context.Context, filter bson.M) (int6
 	return count, nil
 }
 
+// List 按当前用户的数据范围分页查询成员（班组长只能看到本班组成员等）
+func (r *tenantMemberRepository) List(ctx context.Context, filter bson.M, page, pageSize int64) ([]*models.TenantMember, int64, error) {
+	if err := applyDataScope(ctx, filter, (*DataScope).MemberFilter); err != nil {
+		return nil, 0, err
+	}
+
+	collection := r.getCollection(ctx)
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if page > 0 && pageSize > 0 {
+		opts.SetSkip((page - 1) * pageSize).SetLimit(pageSize)
+	}
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	members := []*models.TenantMember{}
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, 0, err
+	}
+	decryptMembers(members...)
+	return members, total, nil
+}
+
 // Create 创建记录
 func (r *tenantMemberRepository) Create(ctx context.Context, member *models.TenantMember) error {
 	collection := r.getCollection(ctx)
+	restore, err := encryptMember(member)
+	if err != nil {
+		return err
+	}
 	result, err := collection.InsertOne(ctx, member)
+	restore()
 	if err != nil {
 		return err
 	}
@@ -229,6 +279,9 @@ func (r *tenantMemberRepository) Create(ctx context.Context, member *models.Tena
 // Update 更新记录
 func (r *tenantMemberRepository) Update(ctx context.Context, id string, update bson.M) error {
 	collection := r.getCollection(ctx)
+	if err := encryptMemberUpdate(update); err != nil {
+		return err
+	}
 
 	objectID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
@@ -261,6 +314,9 @@ func (r *tenantMemberRepository) Update(ctx context.Context, id string, update b
 // UpdateOne 按条件更新单条记录
 func (r *tenantMemberRepository) UpdateOne(ctx context.Context, filter bson.M, update bson.M) error {
 	collection := r.getCollection(ctx)
+	if err := encryptMemberUpdate(update); err != nil {
+		return err
+	}
 	updateDoc := bson.M{"$set": update}
 	_, err := collection.UpdateOne(ctx, filter, updateDoc)
 	return err